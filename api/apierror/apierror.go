@@ -0,0 +1,213 @@
+// Package apierror writes API errors in one JSON envelope:
+//
+//	{"code":"not_found","message":"transaction not found","request_id":"...","error":"transaction not found"}
+//
+// Codes are stable identifiers clients can branch on; messages are for
+// people. "error" repeats the message for clients of the original
+// {"error":"..."} responses.
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+// Code identifies a kind of error
+type Code string
+
+// Generic codes, one per HTTP status
+const (
+	CodeInvalidRequest   Code = "invalid_request"
+	CodeUnauthorized     Code = "unauthorized"
+	CodePaymentRequired  Code = "payment_required"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodeGone             Code = "gone"
+	CodeTooLarge         Code = "payload_too_large"
+	CodeUnprocessable    Code = "unprocessable"
+	CodeRateLimited      Code = "rate_limited"
+	CodeInternal         Code = "internal_error"
+	CodeNotImplemented   Code = "not_implemented"
+	CodeBadGateway       Code = "bad_gateway"
+	CodeUnavailable      Code = "service_unavailable"
+	CodeTimeout          Code = "timeout"
+)
+
+// Specific codes for errors clients are expected to handle
+const (
+	CodeValidationFailed     Code = "validation_failed"      // details lists the invalid fields
+	CodeLimitExceeded        Code = "limit_exceeded"         // A spending limit would be exceeded
+	CodeQuoteExpired         Code = "quote_expired"          // Request a new quote
+	CodeIdempotencyKeyReused Code = "idempotency_key_reused" // The key was used for a different request
+	CodeTokenExpired         Code = "token_expired"          // Log in again
+)
+
+// statuses maps codes to HTTP statuses
+var statuses = map[Code]int{
+	CodeInvalidRequest:   http.StatusBadRequest,
+	CodeUnauthorized:     http.StatusUnauthorized,
+	CodePaymentRequired:  http.StatusPaymentRequired,
+	CodeForbidden:        http.StatusForbidden,
+	CodeNotFound:         http.StatusNotFound,
+	CodeMethodNotAllowed: http.StatusMethodNotAllowed,
+	CodeConflict:         http.StatusConflict,
+	CodeGone:             http.StatusGone,
+	CodeTooLarge:         http.StatusRequestEntityTooLarge,
+	CodeUnprocessable:    http.StatusUnprocessableEntity,
+	CodeRateLimited:      http.StatusTooManyRequests,
+	CodeInternal:         http.StatusInternalServerError,
+	CodeNotImplemented:   http.StatusNotImplemented,
+	CodeBadGateway:       http.StatusBadGateway,
+	CodeUnavailable:      http.StatusServiceUnavailable,
+	CodeTimeout:          http.StatusGatewayTimeout,
+
+	CodeValidationFailed:     http.StatusBadRequest,
+	CodeLimitExceeded:        http.StatusForbidden,
+	CodeQuoteExpired:         http.StatusGone,
+	CodeIdempotencyKeyReused: http.StatusUnprocessableEntity,
+	CodeTokenExpired:         http.StatusUnauthorized,
+}
+
+// Status returns the HTTP status of the code (500 for unknown codes)
+func (c Code) Status() int {
+	if status, ok := statuses[c]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// CodeFor returns the generic code of an HTTP status
+func CodeFor(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusPaymentRequired:
+		return CodePaymentRequired
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeGone
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusUnprocessableEntity:
+		return CodeUnprocessable
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusBadGateway:
+		return CodeBadGateway
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status >= 400 && status < 500 {
+		return CodeInvalidRequest
+	}
+	return CodeInternal
+}
+
+// Error is an error with a code, safe to show to API clients
+type Error struct {
+	Code    Code
+	Message string
+	Details interface{} // Optional structured context, e.g. field errors
+	status  int         // Overrides Code.Status when set
+}
+
+// New creates an error
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Newf creates an error with a formatted message
+func Newf(code Code, format string, args ...interface{}) *Error {
+	return New(code, fmt.Sprintf(format, args...))
+}
+
+// FromStatus creates an error with the generic code of an HTTP status
+func FromStatus(status int, message string) *Error {
+	return &Error{Code: CodeFor(status), Message: message, status: status}
+}
+
+// WithDetails returns a copy of the error carrying details
+func (e *Error) WithDetails(details interface{}) *Error {
+	cp := *e
+	cp.Details = details
+	return &cp
+}
+
+// Status returns the HTTP status of the error
+func (e *Error) Status() int {
+	if e.status != 0 {
+		return e.status
+	}
+	return e.Code.Status()
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// ErrorBody is the JSON body of an error response
+type ErrorBody struct {
+	Code      Code        `json:"code"`
+	Message   string      `json:"message"`
+	Details   interface{} `json:"details,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
+	Error     string      `json:"error"` // Same as Message, for clients of the original error body
+}
+
+// Write writes err as an error response. Errors that are not *Error are
+// logged and reported as internal errors without their text.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		slog.ErrorContext(r.Context(), "request failed", "error", err)
+		apiErr = New(CodeInternal, "internal error")
+	}
+	write(w, apiErr)
+}
+
+// Respond writes an error response with the generic code of status. It is
+// the drop-in replacement for http.Error.
+func Respond(w http.ResponseWriter, status int, message string) {
+	write(w, FromStatus(status, message))
+}
+
+// RespondCode writes an error response with a specific code
+func RespondCode(w http.ResponseWriter, code Code, message string) {
+	write(w, New(code, message))
+}
+
+func write(w http.ResponseWriter, e *Error) {
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(e.Status())
+	json.NewEncoder(w).Encode(ErrorBody{
+		Code:    e.Code,
+		Message: e.Message,
+		Details: e.Details,
+		// Set on the response by the RequestID middleware before any handler runs
+		RequestID: h.Get(logging.RequestIDHeader),
+		Error:     e.Message,
+	})
+}
@@ -0,0 +1,104 @@
+package apierror
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) ErrorBody {
+	t.Helper()
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var body ErrorBody
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("body %q is not JSON: %v", rec.Body.String(), err)
+	}
+	return body
+}
+
+func TestRespondEscapesMessageAndIncludesRequestID(t *testing.T) {
+	rec := httptest.NewRecorder()
+	rec.Header().Set(logging.RequestIDHeader, "req-1")
+	Respond(rec, http.StatusBadRequest, `invalid character '"' in "amount"`)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d", rec.Code)
+	}
+	body := decode(t, rec)
+	want := ErrorBody{Code: CodeInvalidRequest, Message: `invalid character '"' in "amount"`, RequestID: "req-1", Error: `invalid character '"' in "amount"`}
+	if body != want {
+		t.Errorf("body = %+v, want %+v", body, want)
+	}
+}
+
+func TestRespondKeepsUnmappedStatus(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Respond(rec, http.StatusTeapot, "short and stout")
+	if rec.Code != http.StatusTeapot || decode(t, rec).Code != CodeInvalidRequest {
+		t.Errorf("got %d %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    Code
+		message string
+	}{
+		{"coded", New(CodeQuoteExpired, "quote expired"), http.StatusGone, CodeQuoteExpired, "quote expired"},
+		{"wrapped", fmt.Errorf("creating payment: %w", New(CodeLimitExceeded, "daily limit exceeded")), http.StatusForbidden, CodeLimitExceeded, "daily limit exceeded"},
+		{"internal", errors.New("pq: connection refused"), http.StatusInternalServerError, CodeInternal, "internal error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			Write(rec, httptest.NewRequest(http.MethodGet, "/", nil), tt.err)
+			body := decode(t, rec)
+			if rec.Code != tt.status || body.Code != tt.code || body.Message != tt.message {
+				t.Errorf("got %d %+v, want %d %s %q", rec.Code, body, tt.status, tt.code, tt.message)
+			}
+		})
+	}
+}
+
+func TestWithDetails(t *testing.T) {
+	base := New(CodeValidationFailed, "invalid request")
+	rec := httptest.NewRecorder()
+	Write(rec, httptest.NewRequest(http.MethodPost, "/", nil), base.WithDetails(map[string]string{"currency": "unknown currency"}))
+
+	var body struct {
+		Details map[string]string `json:"details"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Details["currency"] != "unknown currency" {
+		t.Errorf("details = %v", body.Details)
+	}
+	if base.Details != nil {
+		t.Error("WithDetails should not modify the original error")
+	}
+}
+
+func TestCodeStatusRoundTrip(t *testing.T) {
+	for code, status := range statuses {
+		if code.Status() != status {
+			t.Errorf("%s.Status() = %d, want %d", code, code.Status(), status)
+		}
+	}
+	for _, code := range []Code{CodeInvalidRequest, CodeNotFound, CodeRateLimited, CodeUnavailable} {
+		if got := CodeFor(code.Status()); got != code {
+			t.Errorf("CodeFor(%d) = %s, want %s", code.Status(), got, code)
+		}
+	}
+	if Code("bogus").Status() != http.StatusInternalServerError {
+		t.Error("unknown codes should be internal errors")
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+)
+
+// Limits for the admin audit log listing
+const (
+	defaultAuditLimit = 100
+	maxAuditLimit     = 1000
+)
+
+// AuditHandler serves the admin audit trail
+type AuditHandler struct {
+	store audit.Store
+}
+
+// NewAuditHandler creates a new audit log handler
+func NewAuditHandler(store audit.Store) *AuditHandler {
+	return &AuditHandler{store: store}
+}
+
+// HandleListAudit handles GET /api/v1/admin/audit
+// Query params: actor (ID or email), action (exact, or a prefix ending in "." such as chaos.),
+// resource_type, resource_id, since, until (RFC 3339), limit (default 100, max 1000)
+func (h *AuditHandler) HandleListAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := audit.Filter{
+		Actor:        q.Get("actor"),
+		Action:       q.Get("action"),
+		ResourceType: q.Get("resource_type"),
+		ResourceID:   q.Get("resource_id"),
+		Limit:        parsePositiveInt(q.Get("limit"), defaultAuditLimit),
+	}
+	if filter.Limit > maxAuditLimit {
+		filter.Limit = maxAuditLimit
+	}
+	for param, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := q.Get(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				apierror.Respond(w, http.StatusBadRequest, param+" must be an RFC 3339 timestamp")
+				return
+			}
+			*dst = t
+		}
+	}
+
+	entries, err := h.store.List(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read audit log", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to read audit log")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
+
+// recordAudit records a successful admin mutation of a resource, with its
+// state before and after the change (nil when it did not exist)
+func recordAudit(store audit.Store, r *http.Request, status int, action, resourceType, resourceID string, before, after interface{}) {
+	if store == nil {
+		return
+	}
+	entry := middleware.NewAuditEntry(r, action)
+	entry.ResourceType = resourceType
+	entry.ResourceID = resourceID
+	entry.Before = audit.State(before)
+	entry.After = audit.State(after)
+	entry.Status = status
+	audit.Log(r.Context(), store, entry)
+}
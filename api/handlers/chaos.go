@@ -6,12 +6,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/engine/router"
 	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
 	"github.com/plm/predictive-liquidity-mesh/websocket"
@@ -55,7 +56,7 @@ type KillNodeResponse struct {
 // Instantly triggers the Redis circuit breaker for the node
 func (h *ChaosHandler) HandleKillNode(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost && r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -64,41 +65,14 @@ func (h *ChaosHandler) HandleKillNode(w http.ResponseWriter, r *http.Request) {
 	nodeID := strings.TrimSuffix(path, "/")
 
 	if nodeID == "" {
-		http.Error(w, "Node ID required", http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "node ID required")
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	log.Printf("🔥 CHAOS: Killing node %s", nodeID)
-
-	// 1. Force open the circuit breaker in Redis
-	if h.redis != nil {
-		cfg := redisClient.DefaultCircuitBreakerConfig(nodeID)
-		if err := h.redis.CircuitBreaker().ForceOpen(ctx, cfg); err != nil {
-			log.Printf("Failed to force open circuit breaker: %v", err)
-		}
-	}
-
-	// 2. Mark node as killed
-	h.mu.Lock()
-	h.killedNodes[nodeID] = true
-	h.mu.Unlock()
-
-	// 3. Update graph to mark node as inactive
-	if h.graph != nil {
-		h.graph.SetNodeInactive(nodeID)
-	}
-
-	// 4. Broadcast circuit breaker event to all WebSocket clients
-	if h.wsHub != nil {
-		h.wsHub.BroadcastCircuitBreaker(&websocket.CircuitBreakerEvent{
-			NodeID:    nodeID,
-			State:     "open",
-			PrevState: "closed",
-		})
-	}
+	h.KillNode(ctx, nodeID)
 
 	// Send response
 	resp := KillNodeResponse{
@@ -111,7 +85,7 @@ func (h *ChaosHandler) HandleKillNode(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 
-	log.Printf("✅ Node %s killed successfully", nodeID)
+	slog.InfoContext(ctx, "chaos: node killed", "node", nodeID)
 }
 
 // HandleReviveNode handles POST /debug/revive/{node_id}
@@ -121,39 +95,14 @@ func (h *ChaosHandler) HandleReviveNode(w http.ResponseWriter, r *http.Request)
 	nodeID := strings.TrimSuffix(path, "/")
 
 	if nodeID == "" {
-		http.Error(w, "Node ID required", http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "node ID required")
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	log.Printf("💚 REVIVE: Bringing back node %s", nodeID)
-
-	// 1. Reset circuit breaker
-	if h.redis != nil {
-		cfg := redisClient.DefaultCircuitBreakerConfig(nodeID)
-		h.redis.CircuitBreaker().Reset(ctx, cfg)
-	}
-
-	// 2. Remove from killed list
-	h.mu.Lock()
-	delete(h.killedNodes, nodeID)
-	h.mu.Unlock()
-
-	// 3. Mark node as active in graph
-	if h.graph != nil {
-		h.graph.SetNodeActive(nodeID)
-	}
-
-	// 4. Broadcast update
-	if h.wsHub != nil {
-		h.wsHub.BroadcastCircuitBreaker(&websocket.CircuitBreakerEvent{
-			NodeID:    nodeID,
-			State:     "closed",
-			PrevState: "open",
-		})
-	}
+	h.ReviveNode(ctx, nodeID)
 
 	resp := KillNodeResponse{
 		Success:   true,
@@ -188,3 +137,69 @@ func (h *ChaosHandler) IsNodeKilled(nodeID string) bool {
 	defer h.mu.RUnlock()
 	return h.killedNodes[nodeID]
 }
+
+// KillNode opens the node's circuit breaker, takes it out of the routing
+// graph and broadcasts the change
+func (h *ChaosHandler) KillNode(ctx context.Context, nodeID string) error {
+	slog.WarnContext(ctx, "chaos: killing node", "node", nodeID)
+
+	// 1. Force open the circuit breaker in Redis
+	if h.redis != nil {
+		cfg := redisClient.DefaultCircuitBreakerConfig(nodeID)
+		if err := h.redis.CircuitBreaker().ForceOpen(ctx, cfg); err != nil {
+			slog.ErrorContext(ctx, "failed to force open circuit breaker", "node", nodeID, "error", err)
+		}
+	}
+
+	// 2. Mark node as killed
+	h.mu.Lock()
+	h.killedNodes[nodeID] = true
+	h.mu.Unlock()
+
+	// 3. Update graph to mark node as inactive
+	if h.graph != nil {
+		h.graph.SetNodeInactive(nodeID)
+	}
+
+	// 4. Broadcast circuit breaker event to all WebSocket clients
+	if h.wsHub != nil {
+		h.wsHub.BroadcastCircuitBreaker(&websocket.CircuitBreakerEvent{
+			NodeID:    nodeID,
+			State:     "open",
+			PrevState: "closed",
+		})
+	}
+	return nil
+}
+
+// ReviveNode resets the node's circuit breaker, returns it to the routing
+// graph and broadcasts the change
+func (h *ChaosHandler) ReviveNode(ctx context.Context, nodeID string) error {
+	slog.InfoContext(ctx, "chaos: reviving node", "node", nodeID)
+
+	// 1. Reset circuit breaker
+	if h.redis != nil {
+		cfg := redisClient.DefaultCircuitBreakerConfig(nodeID)
+		h.redis.CircuitBreaker().Reset(ctx, cfg)
+	}
+
+	// 2. Remove from killed list
+	h.mu.Lock()
+	delete(h.killedNodes, nodeID)
+	h.mu.Unlock()
+
+	// 3. Mark node as active in graph
+	if h.graph != nil {
+		h.graph.SetNodeActive(nodeID)
+	}
+
+	// 4. Broadcast update
+	if h.wsHub != nil {
+		h.wsHub.BroadcastCircuitBreaker(&websocket.CircuitBreakerEvent{
+			NodeID:    nodeID,
+			State:     "closed",
+			PrevState: "open",
+		})
+	}
+	return nil
+}
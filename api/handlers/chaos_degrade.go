@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// DegradeEdgeRequest is the body of POST /debug/degrade/{source}/{target}
+type DegradeEdgeRequest struct {
+	router.Degradation
+	BothDirections bool `json:"both_directions"` // Also degrade target->source
+}
+
+// DegradeEdgeResponse reports a degradation change and how it moved the
+// best route between the edge's endpoints
+type DegradeEdgeResponse struct {
+	Success     bool                     `json:"success"`
+	Edges       []router.EdgeDegradation `json:"edges"`
+	RouteBefore []string                 `json:"route_before,omitempty"`
+	RouteAfter  []string                 `json:"route_after,omitempty"`
+	Message     string                   `json:"message"`
+	Timestamp   int64                    `json:"timestamp"`
+}
+
+// HandleDegradeEdge handles /debug/degrade/{source}/{target}: POST injects
+// extra latency and/or withholds liquidity on the edge, DELETE restores it.
+// ?both_directions=true on DELETE restores the reverse edge too.
+func (h *ChaosHandler) HandleDegradeEdge(w http.ResponseWriter, r *http.Request) {
+	source, target, ok := strings.Cut(strings.Trim(strings.TrimPrefix(r.URL.Path, "/debug/degrade/"), "/"), "/")
+	if !ok || source == "" || target == "" || strings.Contains(target, "/") {
+		apierror.Respond(w, http.StatusBadRequest, "path must be /debug/degrade/{source}/{target}")
+		return
+	}
+
+	var req DegradeEdgeRequest
+	switch r.Method {
+	case http.MethodPost:
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if req.IsZero() {
+			apierror.Respond(w, http.StatusBadRequest, "extra_latency_ms or liquidity_reduction is required")
+			return
+		}
+	case http.MethodDelete:
+		req.BothDirections = r.URL.Query().Get("both_directions") == "true"
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	edges := [][2]string{{source, target}}
+	if req.BothDirections {
+		edges = append(edges, [2]string{target, source})
+	}
+	before := h.bestRoute(ctx, source, target)
+	resp, err := h.degradeEdges(edges, req.Degradation)
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, router.ErrUnknownEdge) {
+			status = http.StatusNotFound
+		}
+		apierror.Respond(w, status, err.Error())
+		return
+	}
+	resp.RouteBefore, resp.RouteAfter = before, h.bestRoute(ctx, source, target)
+	if req.IsZero() {
+		resp.Message = fmt.Sprintf("Edge %s -> %s restored", source, target)
+	} else {
+		resp.Message = fmt.Sprintf("Edge %s -> %s degraded", source, target)
+	}
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "EDGE_DEGRADATION",
+			"data": resp.Edges,
+		})
+	}
+	slog.InfoContext(ctx, "chaos: edge degradation changed", "source", source, "target", target,
+		"extra_latency_ms", req.ExtraLatencyMs, "liquidity_reduction", req.LiquidityReduction,
+		"both_directions", req.BothDirections)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleGetDegradedEdges returns every degraded edge
+func (h *ChaosHandler) HandleGetDegradedEdges(w http.ResponseWriter, r *http.Request) {
+	degraded := h.graph.Degradations()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"degraded_edges": degraded,
+		"count":          len(degraded),
+	})
+}
+
+// degradeEdges applies d to every edge, or none of them if one fails
+func (h *ChaosHandler) degradeEdges(edges [][2]string, d router.Degradation) (*DegradeEdgeResponse, error) {
+	applied := make([]router.Degradation, 0, len(edges))
+	for _, edge := range edges {
+		prev, err := h.graph.DegradeEdge(edge[0], edge[1], d)
+		if err != nil {
+			for i := range applied {
+				h.graph.DegradeEdge(edges[i][0], edges[i][1], applied[i])
+			}
+			return nil, err
+		}
+		applied = append(applied, prev)
+	}
+
+	resp := &DegradeEdgeResponse{Success: true, Timestamp: time.Now().UnixMilli()}
+	for _, edge := range edges {
+		state, _ := h.graph.EdgeDegradation(edge[0], edge[1])
+		resp.Edges = append(resp.Edges, state)
+	}
+	return resp, nil
+}
+
+// bestRoute returns the nodes of the best route from source to target, nil if there is none
+func (h *ChaosHandler) bestRoute(ctx context.Context, source, target string) []string {
+	if h.router == nil {
+		return nil
+	}
+	paths, err := h.router.FindKShortestPaths(ctx, source, target, 0)
+	if err != nil || len(paths) == 0 {
+		return nil
+	}
+	return paths[0].Nodes
+}
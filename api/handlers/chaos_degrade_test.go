@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+func TestDegradeEdgeReroutes(t *testing.T) {
+	g := router.NewGraph()
+	for _, id := range []string{"a", "b", "c"} {
+		g.AddNode(&router.Node{ID: id, IsActive: true})
+	}
+	g.AddBidirectionalEdge(&router.Edge{SourceID: "a", TargetID: "c", BaseFee: 0.002, Latency: 20, IsActive: true})
+	g.AddBidirectionalEdge(&router.Edge{SourceID: "a", TargetID: "b", BaseFee: 0.0015, Latency: 20, IsActive: true})
+	g.AddBidirectionalEdge(&router.Edge{SourceID: "b", TargetID: "c", BaseFee: 0.0015, Latency: 20, IsActive: true})
+	h := NewChaosHandler(nil, router.NewRouter(g, 3), g, nil)
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.HandleDegradeEdge(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/debug/degrade/a/c", `{"extra_latency_ms":1000,"both_directions":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var resp DegradeEdgeResponse
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if strings.Join(resp.RouteBefore, ",") != "a,c" || strings.Join(resp.RouteAfter, ",") != "a,b,c" {
+		t.Errorf("expected route to move off a->c, got %v -> %v", resp.RouteBefore, resp.RouteAfter)
+	}
+	if len(resp.Edges) != 2 || resp.Edges[0].EffectiveLatencyMs != 1020 || len(g.Degradations()) != 2 {
+		t.Errorf("unexpected degraded edges: %+v", resp.Edges)
+	}
+
+	rec = do(http.MethodDelete, "/debug/degrade/a/c?both_directions=true", "")
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if rec.Code != http.StatusOK || strings.Join(resp.RouteAfter, ",") != "a,c" || len(g.Degradations()) != 0 {
+		t.Errorf("restore: %d route %v, degraded %+v", rec.Code, resp.RouteAfter, g.Degradations())
+	}
+
+	for target, want := range map[string]int{
+		"/debug/degrade/a/x": http.StatusNotFound,
+		"/debug/degrade/a":   http.StatusBadRequest,
+	} {
+		if rec := do(http.MethodPost, target, `{"extra_latency_ms":10}`); rec.Code != want {
+			t.Errorf("%s: expected %d, got %d", target, want, rec.Code)
+		}
+	}
+	if rec := do(http.MethodPost, "/debug/degrade/a/c", `{"liquidity_reduction":2}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an out of range reduction, got %d", rec.Code)
+	}
+}
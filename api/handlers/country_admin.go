@@ -4,19 +4,32 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+	"github.com/plm/predictive-liquidity-mesh/websocket"
 )
 
 // CountryHandler handles country node API endpoints
 type CountryHandler struct {
 	driver   neo4j.DriverWithContext
 	database string
+	graph     *router.CountryGraph
+	refresher *router.CountryGraphRefresher
+	wsHub     *websocket.Hub
+	audit     audit.Store
+	halts     HaltTracker
+	routes    RouteInvalidator
 }
 
 // NewCountryHandler creates a new country handler
@@ -27,6 +40,26 @@ func NewCountryHandler(driver neo4j.DriverWithContext, database string) *Country
 	}
 }
 
+// SetCountryGraph sets the routing graph kept in sync with edge changes
+func (h *CountryHandler) SetCountryGraph(graph *router.CountryGraph) {
+	h.graph = graph
+}
+
+// SetRefresher sets the refresher used by HandleRefresh
+func (h *CountryHandler) SetRefresher(refresher *router.CountryGraphRefresher) {
+	h.refresher = refresher
+}
+
+// SetHub sets the WebSocket hub used to broadcast edge changes
+func (h *CountryHandler) SetHub(hub *websocket.Hub) {
+	h.wsHub = hub
+}
+
+// SetAuditStore records country and trade corridor changes in store
+func (h *CountryHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
 // Country represents a country node
 type Country struct {
 	Code            string  `json:"code"`
@@ -51,7 +84,7 @@ type CreateCountryRequest struct {
 func (h *CountryHandler) HandleListCountries(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, `{"error":"authentication required"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -74,7 +107,7 @@ func (h *CountryHandler) HandleListCountries(w http.ResponseWriter, r *http.Requ
 
 	result, err := session.Run(ctx, query, nil)
 	if err != nil {
-		http.Error(w, `{"error":"failed to fetch countries"}`, http.StatusInternalServerError)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to fetch countries")
 		return
 	}
 
@@ -118,24 +151,31 @@ func (h *CountryHandler) HandleListCountries(w http.ResponseWriter, r *http.Requ
 // HandleCreateCountry handles POST /api/v1/admin/countries
 func (h *CountryHandler) HandleCreateCountry(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r.Context())
-	if user == nil || !user.IsAdmin() {
-		http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+	if user == nil || !middleware.Can(r.Context(), auth.PermCountriesWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 		return
 	}
 
 	var req CreateCountryRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	if req.Code == "" || req.Name == "" || req.Currency == "" {
-		http.Error(w, `{"error":"code, name, and currency are required"}`, http.StatusBadRequest)
+	req.Code = strings.ToUpper(strings.TrimSpace(req.Code))
+	req.Currency = strings.ToUpper(strings.TrimSpace(req.Currency))
+	var v validate.Validator
+	v.Country("code", req.Code, nil)
+	v.Required("name", req.Name)
+	v.Required("currency", req.Currency)
+	v.Currency("currency", req.Currency)
+	if err := v.Err(); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -159,6 +199,7 @@ func (h *CountryHandler) HandleCreateCountry(w http.ResponseWriter, r *http.Requ
 			c.name = $name,
 			c.currency = $currency,
 			c.base_credibility = $baseCredibility,
+			c.credibility_baseline = $baseCredibility,
 			c.success_rate = $successRate,
 			c.created_at = datetime(),
 			c.created_by = $createdBy
@@ -166,6 +207,7 @@ func (h *CountryHandler) HandleCreateCountry(w http.ResponseWriter, r *http.Requ
 			c.name = $name,
 			c.currency = $currency,
 			c.base_credibility = $baseCredibility,
+			c.credibility_baseline = $baseCredibility,
 			c.success_rate = $successRate,
 			c.updated_at = datetime()
 		RETURN c
@@ -181,8 +223,8 @@ func (h *CountryHandler) HandleCreateCountry(w http.ResponseWriter, r *http.Requ
 	})
 
 	if err != nil {
-		log.Printf("❌ Failed to create country: %v", err)
-		http.Error(w, `{"error":"failed to create country"}`, http.StatusInternalServerError)
+		slog.ErrorContext(ctx, "failed to create country", "code", req.Code, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to create country")
 		return
 	}
 
@@ -194,22 +236,31 @@ func (h *CountryHandler) HandleCreateCountry(w http.ResponseWriter, r *http.Requ
 		edgeQuery := `
 			MATCH (a:Country {code: $source})
 			MATCH (b:Country {code: $target})
-			MERGE (a)-[r:TRADE]->(b)
-			ON CREATE SET r.base_cost = 0.01, r.active = true, r.created_at = datetime()
-			MERGE (b)-[r2:TRADE]->(a)
-			ON CREATE SET r2.base_cost = 0.01, r2.active = true, r2.created_at = datetime()
+			MERGE (a)-[r:TRADES_WITH]->(b)
+			ON CREATE SET r.base_cost = $baseCost, r.active = true, r.created_at = datetime()
+			MERGE (b)-[r2:TRADES_WITH]->(a)
+			ON CREATE SET r2.base_cost = $baseCost, r2.active = true, r2.created_at = datetime()
 			RETURN count(*) as created
 		`
 		_, edgeErr := session.Run(ctx, edgeQuery, map[string]interface{}{
-			"source": strings.ToUpper(req.Code),
-			"target": targetCode,
+			"source":   strings.ToUpper(req.Code),
+			"target":   targetCode,
+			"baseCost": router.DefaultTradeBaseCost,
 		})
 		if edgeErr == nil {
 			edgesCreated++
 		}
 	}
 
-	log.Printf("✅ Admin %s created country: %s (%s) with %d edge connections", user.Username, req.Code, req.Name, edgesCreated)
+	slog.InfoContext(ctx, "country created", "admin", user.Username, "code", req.Code, "name", req.Name, "edges", edgesCreated)
+	recordAudit(h.audit, r, http.StatusCreated, "country.create", "country", strings.ToUpper(req.Code), nil, map[string]interface{}{
+		"code":             strings.ToUpper(req.Code),
+		"name":             req.Name,
+		"currency":         strings.ToUpper(req.Currency),
+		"base_credibility": req.BaseCredibility,
+		"success_rate":     req.SuccessRate,
+		"edges_created":    edgesCreated,
+	})
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -295,20 +346,19 @@ func getRegionalConnections(code string) []string {
 // HandleDeleteCountry handles DELETE /api/v1/admin/countries/{code}
 func (h *CountryHandler) HandleDeleteCountry(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r.Context())
-	if user == nil || !user.IsAdmin() {
-		http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+	if user == nil || !middleware.Can(r.Context(), auth.PermCountriesWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 		return
 	}
 
-	// Extract code from path: /api/v1/admin/countries/{code}
-	code := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/countries/")
-	if code == "" {
-		http.Error(w, `{"error":"country code required"}`, http.StatusBadRequest)
+	code := strings.ToUpper(r.PathValue("code"))
+	if !refdata.IsCountry(code) {
+		apierror.Respond(w, http.StatusBadRequest, "country code must be ISO 3166-1 alpha-3")
 		return
 	}
 
@@ -323,8 +373,9 @@ func (h *CountryHandler) HandleDeleteCountry(w http.ResponseWriter, r *http.Requ
 
 	query := `
 		MATCH (c:Country {code: $code})
+		WITH c, properties(c) AS props
 		DETACH DELETE c
-		RETURN count(c) as deleted
+		RETURN props
 	`
 
 	result, err := session.Run(ctx, query, map[string]interface{}{
@@ -332,25 +383,24 @@ func (h *CountryHandler) HandleDeleteCountry(w http.ResponseWriter, r *http.Requ
 	})
 
 	if err != nil {
-		log.Printf("❌ Failed to delete country: %v", err)
-		http.Error(w, `{"error":"failed to delete country"}`, http.StatusInternalServerError)
+		slog.ErrorContext(ctx, "failed to delete country", "code", code, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to delete country")
 		return
 	}
 
-	var deleted int64
-	if result.Next(ctx) {
-		record := result.Record()
-		if v, ok := record.Get("deleted"); ok {
-			deleted = v.(int64)
+	if !result.Next(ctx) {
+		if err := result.Err(); err != nil {
+			slog.ErrorContext(ctx, "failed to delete country", "code", code, "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to delete country")
+			return
 		}
-	}
-
-	if deleted == 0 {
-		http.Error(w, `{"error":"country not found"}`, http.StatusNotFound)
+		apierror.Respond(w, http.StatusNotFound, "country not found")
 		return
 	}
+	before, _ := result.Record().Get("props")
 
-	log.Printf("🗑️ Admin %s deleted country: %s", user.Username, code)
+	slog.InfoContext(ctx, "country deleted", "admin", user.Username, "code", code)
+	recordAudit(h.audit, r, http.StatusOK, "country.delete", "country", strings.ToUpper(code), before, nil)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -359,3 +409,43 @@ func (h *CountryHandler) HandleDeleteCountry(w http.ResponseWriter, r *http.Requ
 		"message": "Country deleted successfully",
 	})
 }
+
+// HandleRefresh handles POST /api/v1/admin/countries/refresh, reloading the
+// routing graph from Neo4j without waiting for the next periodic refresh
+func (h *CountryHandler) HandleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.refresher == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "graph refresh not available")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := h.refresher.Refresh(ctx); err != nil {
+		slog.ErrorContext(ctx, "failed to refresh country graph", "error", err)
+		apierror.Respond(w, http.StatusBadGateway, "failed to refresh country graph")
+		return
+	}
+
+	countries := h.graph.NodeCount()
+	refreshedAt := h.refresher.LastRefresh()
+	slog.InfoContext(ctx, "country graph refreshed", "countries", countries)
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "COUNTRY_GRAPH_REFRESHED",
+			"data": map[string]interface{}{"countries": countries, "refreshed_at": refreshedAt},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":      true,
+		"countries":    countries,
+		"refreshed_at": refreshedAt,
+	})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	neo4jstore "github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+)
+
+// RouteInvalidator tells route clients to request their routes again.
+// Implemented by RouteHandler.
+type RouteInvalidator interface {
+	InvalidateRoutes(reason string) int
+}
+
+// SetRouteInvalidator sets who is told when the block list changes routing
+func (h *CountryHandler) SetRouteInvalidator(routes RouteInvalidator) {
+	h.routes = routes
+}
+
+// BlockedCountriesRequest is the request body for replacing the block list
+type BlockedCountriesRequest struct {
+	Blocked []string `json:"blocked"`
+}
+
+// normalize upper-cases, de-duplicates and sorts the codes and validates them
+func (req *BlockedCountriesRequest) normalize() error {
+	var v validate.Validator
+	v.Check(req.Blocked != nil, "blocked", "is required")
+	seen := make(map[string]bool, len(req.Blocked))
+	codes := make([]string, 0, len(req.Blocked))
+	for _, code := range req.Blocked {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	v.Countries("blocked", codes, nil)
+	sort.Strings(codes)
+	req.Blocked = codes
+	return v.Err()
+}
+
+// BlockedCountriesResponse is the block list routing currently applies
+type BlockedCountriesResponse struct {
+	Blocked []string `json:"blocked"` // Sorted
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// HandleBlocked handles GET and PUT /api/v1/admin/countries/blocked. PUT
+// replaces the list of countries excluded from routing, persists it and tells
+// route clients to re-query.
+func (h *CountryHandler) HandleBlocked(w http.ResponseWriter, r *http.Request) {
+	if h.graph == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "country graph not available")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(BlockedCountriesResponse{Blocked: h.graph.BlockedCodes()})
+	case http.MethodPut:
+		h.handleSetBlocked(w, r)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleSetBlocked saves the block list in Neo4j, applies it to the routing
+// graph and broadcasts the change
+func (h *CountryHandler) handleSetBlocked(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	var req BlockedCountriesRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := req.normalize(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	previous, err := neo4jstore.SaveBlockedCountries(ctx, h.driver, h.database, req.Blocked, user.Username)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to save blocked countries", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to save blocked countries")
+		return
+	}
+	h.graph.SetBlocked(req.Blocked)
+
+	resp := BlockedCountriesResponse{
+		Blocked: req.Blocked,
+		Added:   codesMissing(req.Blocked, previous),
+		Removed: codesMissing(previous, req.Blocked),
+	}
+	slog.InfoContext(ctx, "blocked countries changed", "admin", user.Username, "added", resp.Added, "removed", resp.Removed)
+	recordAudit(h.audit, r, http.StatusOK, "country.block_list", "country", "blocked",
+		map[string]interface{}{"blocked": previous},
+		map[string]interface{}{"blocked": req.Blocked},
+	)
+
+	if len(resp.Added) > 0 || len(resp.Removed) > 0 {
+		if h.wsHub != nil {
+			h.wsHub.BroadcastJSON(map[string]interface{}{
+				"type": "BLOCKED_COUNTRIES",
+				"data": resp,
+			})
+		}
+		if h.routes != nil {
+			h.routes.InvalidateRoutes("blocked_countries")
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// codesMissing returns the codes of a that are not in b
+func codesMissing(a, b []string) []string {
+	in := make(map[string]bool, len(b))
+	for _, code := range b {
+		in[code] = true
+	}
+	var missing []string
+	for _, code := range a {
+		if !in[code] {
+			missing = append(missing, code)
+		}
+	}
+	return missing
+}
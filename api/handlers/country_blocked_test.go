@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBlockedCountriesRequestNormalize(t *testing.T) {
+	req := BlockedCountriesRequest{Blocked: []string{" rus", "PRK", "RUS", "irn"}}
+	if err := req.normalize(); err != nil {
+		t.Fatalf("normalize: %v", err)
+	}
+	if want := []string{"IRN", "PRK", "RUS"}; !slices.Equal(req.Blocked, want) {
+		t.Errorf("blocked = %v, want %v", req.Blocked, want)
+	}
+
+	for _, bad := range []BlockedCountriesRequest{{}, {Blocked: []string{"RU"}}} {
+		if err := bad.normalize(); err == nil {
+			t.Errorf("%v accepted", bad.Blocked)
+		}
+	}
+	// An empty list unblocks every country
+	if err := (&BlockedCountriesRequest{Blocked: []string{}}).normalize(); err != nil {
+		t.Errorf("empty list rejected: %v", err)
+	}
+
+	if added := codesMissing([]string{"IRN", "PRK"}, []string{"PRK", "RUS"}); !slices.Equal(added, []string{"IRN"}) {
+		t.Errorf("codesMissing = %v, want [IRN]", added)
+	}
+}
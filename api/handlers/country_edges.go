@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// CountryEdgeRequest is the request body for creating or deleting a trade corridor
+type CountryEdgeRequest struct {
+	Source    string  `json:"source"`
+	Target    string  `json:"target"`
+	BaseCost  float64 `json:"base_cost,omitempty"`
+	LatencyMs int64   `json:"latency_ms,omitempty"` // 0 uses router.DefaultTradeLatencyMs
+}
+
+// normalize upper-cases the country codes and validates the request
+func (req *CountryEdgeRequest) normalize() error {
+	req.Source = strings.ToUpper(strings.TrimSpace(req.Source))
+	req.Target = strings.ToUpper(strings.TrimSpace(req.Target))
+	var v validate.Validator
+	v.Country("source", req.Source, nil)
+	v.Country("target", req.Target, nil)
+	v.Check(req.Source == "" || req.Source != req.Target, "target", "must differ from source")
+	v.Check(req.BaseCost >= 0 && req.BaseCost <= 1, "base_cost", "must be between 0 and 1")
+	v.Check(req.LatencyMs >= 0, "latency_ms", "must not be negative")
+	return v.Err()
+}
+
+// HandleEdges handles POST and DELETE /api/v1/admin/countries/edges
+func (h *CountryHandler) HandleEdges(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.handleCreateEdge(w, r)
+	case http.MethodDelete:
+		h.handleDeleteEdge(w, r)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleCreateEdge creates (or updates the base cost of) a trade corridor in both directions
+func (h *CountryHandler) handleCreateEdge(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermCountriesWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	var req CountryEdgeRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := req.normalize(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.BaseCost == 0 {
+		req.BaseCost = router.DefaultTradeBaseCost
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	session := h.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: h.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (a:Country {code: $source})
+		MATCH (b:Country {code: $target})
+		MERGE (a)-[r1:TRADES_WITH]->(b)
+		ON CREATE SET r1.created_at = datetime(), r1.created_by = $createdBy
+		SET r1.base_cost = $baseCost, r1.latency_ms = $latencyMs, r1.active = true, r1.updated_at = datetime()
+		MERGE (b)-[r2:TRADES_WITH]->(a)
+		ON CREATE SET r2.created_at = datetime(), r2.created_by = $createdBy
+		SET r2.base_cost = $baseCost, r2.latency_ms = $latencyMs, r2.active = true, r2.updated_at = datetime()
+		RETURN a, b
+	`
+
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"source":    req.Source,
+		"target":    req.Target,
+		"baseCost":  req.BaseCost,
+		"latencyMs": req.LatencyMs,
+		"createdBy": user.Username,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to create country edge", "source", req.Source, "target", req.Target, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to create edge")
+		return
+	}
+	if !result.Next(ctx) {
+		if err := result.Err(); err != nil {
+			slog.ErrorContext(ctx, "failed to create country edge", "source", req.Source, "target", req.Target, "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to create edge")
+			return
+		}
+		apierror.Respond(w, http.StatusNotFound, "country not found")
+		return
+	}
+
+	var before *router.CountryEdge
+	if h.graph != nil {
+		before = h.graph.Edge(req.Source, req.Target)
+		record := result.Record()
+		for _, key := range []string{"a", "b"} {
+			if v, ok := record.Get(key); ok {
+				if node, ok := v.(neo4j.Node); ok {
+					h.ensureGraphNode(node.Props)
+				}
+			}
+		}
+		h.graph.AddEdge(&router.CountryEdge{
+			SourceCode: req.Source,
+			TargetCode: req.Target,
+			BaseCost:   req.BaseCost,
+			LatencyMs:  req.LatencyMs,
+			IsActive:   true,
+		})
+	}
+
+	slog.InfoContext(ctx, "country edge created", "admin", user.Username, "source", req.Source, "target", req.Target, "base_cost", req.BaseCost)
+	recordAudit(h.audit, r, http.StatusCreated, "country_edge.create", "country_edge", req.Source+"->"+req.Target, before, req)
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "COUNTRY_EDGE_CREATED",
+			"data": req,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":    true,
+		"source":     req.Source,
+		"target":     req.Target,
+		"base_cost":  req.BaseCost,
+		"latency_ms": req.LatencyMs,
+		"message":    "Edge created successfully",
+	})
+}
+
+// handleDeleteEdge removes a trade corridor in both directions.
+// source and target come from the JSON body or the query string.
+func (h *CountryHandler) handleDeleteEdge(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermCountriesWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	req := CountryEdgeRequest{
+		Source: r.URL.Query().Get("source"),
+		Target: r.URL.Query().Get("target"),
+	}
+	if req.Source == "" && req.Target == "" {
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	req.BaseCost, req.LatencyMs = 0, 0
+	if err := req.normalize(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	session := h.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: h.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (a:Country {code: $source})-[r:TRADES_WITH]-(b:Country {code: $target})
+		DELETE r
+		RETURN count(r) as deleted
+	`
+
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"source": req.Source,
+		"target": req.Target,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to delete country edge", "source", req.Source, "target", req.Target, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to delete edge")
+		return
+	}
+
+	var deleted int64
+	if result.Next(ctx) {
+		if v, ok := result.Record().Get("deleted"); ok {
+			deleted, _ = v.(int64)
+		}
+	}
+
+	removed := false
+	var before *router.CountryEdge
+	if h.graph != nil {
+		before = h.graph.Edge(req.Source, req.Target)
+		removed = h.graph.RemoveEdge(req.Source, req.Target)
+	}
+
+	if deleted == 0 && !removed {
+		apierror.Respond(w, http.StatusNotFound, "edge not found")
+		return
+	}
+
+	slog.InfoContext(ctx, "country edge deleted", "admin", user.Username, "source", req.Source, "target", req.Target)
+	recordAudit(h.audit, r, http.StatusOK, "country_edge.delete", "country_edge", req.Source+"->"+req.Target, before, nil)
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "COUNTRY_EDGE_DELETED",
+			"data": map[string]string{"source": req.Source, "target": req.Target},
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"source":  req.Source,
+		"target":  req.Target,
+		"message": "Edge deleted successfully",
+	})
+}
+
+// ensureGraphNode adds a country created after startup to the routing graph
+func (h *CountryHandler) ensureGraphNode(props map[string]any) {
+	code, _ := props["code"].(string)
+	if code == "" || h.graph.HasNode(code) {
+		return
+	}
+	name, _ := props["name"].(string)
+	currency, _ := props["currency"].(string)
+	credibility, _ := props["base_credibility"].(float64)
+	successRate, _ := props["success_rate"].(float64)
+	fxRate, _ := props["fx_rate"].(float64)
+	if fxRate == 0 {
+		fxRate = 1
+	}
+	h.graph.AddNode(&router.CountryNode{
+		Code:        code,
+		Name:        name,
+		Currency:    currency,
+		Credibility: credibility,
+		SuccessRate: successRate,
+		FXRate:      fxRate,
+		IsActive:    true,
+	})
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+)
+
+// HaltTracker tracks halted countries so payments routed through them pay
+// halt fines. Implemented by PaymentHandler.
+type HaltTracker interface {
+	SetHalted(code string, halted bool)
+}
+
+// SetHaltTracker sets the tracker updated when a country is halted or resumed
+func (h *CountryHandler) SetHaltTracker(tracker HaltTracker) {
+	h.halts = tracker
+}
+
+// HandleCountry handles /api/v1/admin/countries/{code}: DELETE removes the
+// country, POST .../{code}/halt and .../{code}/resume halt or resume it, and
+// .../{code}/hours manages its settlement window. It is registered as
+// /admin/countries/{code} and /admin/countries/{code}/{action}.
+func (h *CountryHandler) HandleCountry(w http.ResponseWriter, r *http.Request) {
+	code := r.PathValue("code")
+	switch r.PathValue("action") {
+	case "halt":
+		h.handleSetHalted(w, r, code, true)
+	case "resume":
+		h.handleSetHalted(w, r, code, false)
+	case "hours":
+		h.handleCountryHours(w, r, code)
+	case "":
+		h.HandleDeleteCountry(w, r)
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleSetHalted halts or resumes a country in Neo4j, the routing graph and
+// the payment fee calculation, then broadcasts the change
+func (h *CountryHandler) handleSetHalted(w http.ResponseWriter, r *http.Request, code string, halted bool) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermCountriesWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	code = strings.ToUpper(code)
+	if !refdata.IsCountry(code) {
+		apierror.Respond(w, http.StatusBadRequest, "country code must be ISO 3166-1 alpha-3")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	session := h.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: h.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (c:Country {code: $code})
+		WITH c, coalesce(c.is_active, true) AS wasActive
+		SET c.is_active = $active, c.status_changed_at = datetime(), c.status_changed_by = $changedBy
+		RETURN wasActive
+	`
+
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"code":      code,
+		"active":    !halted,
+		"changedBy": user.Username,
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update country status", "code", code, "halted", halted, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to update country status")
+		return
+	}
+	if !result.Next(ctx) {
+		if err := result.Err(); err != nil {
+			slog.ErrorContext(ctx, "failed to update country status", "code", code, "halted", halted, "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to update country status")
+			return
+		}
+		apierror.Respond(w, http.StatusNotFound, "country not found")
+		return
+	}
+	wasActive, _ := result.Record().Get("wasActive")
+
+	if h.graph != nil {
+		h.graph.SetNodeActive(code, !halted)
+	}
+	if h.halts != nil {
+		h.halts.SetHalted(code, halted)
+	}
+
+	action := "country.resume"
+	if halted {
+		action = "country.halt"
+	}
+	slog.InfoContext(ctx, "country status changed", "admin", user.Username, "code", code, "halted", halted)
+	recordAudit(h.audit, r, http.StatusOK, action, "country", code,
+		map[string]interface{}{"code": code, "is_active": wasActive},
+		map[string]interface{}{"code": code, "is_active": !halted},
+	)
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "COUNTRY_STATUS",
+			"data": map[string]interface{}{"code": code, "halted": halted, "is_active": !halted},
+		})
+	}
+
+	message := "Country resumed"
+	if halted {
+		message = "Country halted - routes through it pay halt fines"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"code":    code,
+		"halted":  halted,
+		"message": message,
+	})
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+)
+
+// CountryHoursResponse is a country's settlement window and whether it accepts payments now
+type CountryHoursResponse struct {
+	Code       string                   `json:"code"`
+	Hours      *router.SettlementWindow `json:"hours"` // null = always accepting
+	Open       bool                     `json:"open"`
+	NextOpenAt *time.Time               `json:"next_open_at,omitempty"` // Set while closed
+}
+
+// handleCountryHours handles /api/v1/admin/countries/{code}/hours: GET returns
+// the settlement window, PUT replaces it and DELETE removes it
+func (h *CountryHandler) handleCountryHours(w http.ResponseWriter, r *http.Request, code string) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermCountriesWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	code = strings.ToUpper(code)
+	if !refdata.IsCountry(code) {
+		apierror.Respond(w, http.StatusBadRequest, "country code must be ISO 3166-1 alpha-3")
+		return
+	}
+	if h.graph == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "routing graph not available")
+		return
+	}
+	before, ok := h.countryHours(code)
+	if !ok {
+		apierror.Respond(w, http.StatusNotFound, "country not found")
+		return
+	}
+
+	var hours *router.SettlementWindow
+	switch r.Method {
+	case http.MethodGet:
+		h.writeCountryHours(w, code, before)
+		return
+	case http.MethodPut:
+		hours = &router.SettlementWindow{}
+		if err := validate.Decode(r, hours); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if err := hours.Validate(); err != nil {
+			apierror.RespondCode(w, apierror.CodeValidationFailed, err.Error())
+			return
+		}
+	case http.MethodDelete:
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	session := h.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: h.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (c:Country {code: $code})
+		SET c += $hours
+		RETURN c.code AS code
+	`, map[string]interface{}{"code": code, "hours": hours.Props()})
+	if err == nil && !result.Next(ctx) {
+		err = result.Err()
+		if err == nil {
+			apierror.Respond(w, http.StatusNotFound, "country not found")
+			return
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to update country hours", "code", code, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to update country hours")
+		return
+	}
+
+	if err := h.graph.SetCountryHours(code, hours); err != nil {
+		apierror.Respond(w, http.StatusNotFound, "country not found")
+		return
+	}
+
+	slog.InfoContext(ctx, "country hours changed", "admin", user.Username, "code", code)
+	recordAudit(h.audit, r, http.StatusOK, "country.hours", "country", code,
+		map[string]interface{}{"code": code, "hours": before},
+		map[string]interface{}{"code": code, "hours": hours},
+	)
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "COUNTRY_HOURS",
+			"data": map[string]interface{}{"code": code, "hours": hours},
+		})
+	}
+	h.writeCountryHours(w, code, hours)
+}
+
+// countryHours returns the settlement window of a country in the routing graph
+func (h *CountryHandler) countryHours(code string) (*router.SettlementWindow, bool) {
+	for _, c := range h.graph.Export().Nodes {
+		if c.Code == code {
+			return c.Hours, true
+		}
+	}
+	return nil, false
+}
+
+func (h *CountryHandler) writeCountryHours(w http.ResponseWriter, code string, hours *router.SettlementWindow) {
+	resp := CountryHoursResponse{Code: code, Hours: hours, Open: true}
+	if hours != nil {
+		now := time.Now()
+		if next := hours.NextAccept(now); next.After(now) {
+			next = next.UTC()
+			resp.Open, resp.NextOpenAt = false, &next
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
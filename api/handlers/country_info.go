@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+	neo4jstore "github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+)
+
+// maxCredibilityHistoryRange bounds the credibility history a request may read
+const maxCredibilityHistoryRange = 90 * 24 * time.Hour
+
+// countryListTTL is how long the public country list is served from memory
+const countryListTTL = 30 * time.Second
+
+// countryListTimeout bounds reading the country list from Neo4j
+const countryListTimeout = 5 * time.Second
+
+// CountryInfoHandler serves read-only country data under /api/v1/countries/
+type CountryInfoHandler struct {
+	graph       *router.CountryGraph
+	txns        payments.TransactionStorer
+	credibility *neo4jstore.CredibilityBatcher
+	history     neo4jstore.CredibilityHistory
+	neo4j       atomic.Pointer[neo4jstore.Client]
+
+	listMu        sync.Mutex // Serializes rebuilding the cached list
+	list          *countryList
+	listCountries func(ctx context.Context, client *neo4jstore.Client) ([]neo4jstore.Country, error) // Stubbed in tests
+	now           func() time.Time
+}
+
+// countryList is an encoded country list response
+type countryList struct {
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+// NewCountryInfoHandler creates a country info handler
+func NewCountryInfoHandler() *CountryInfoHandler {
+	return &CountryInfoHandler{
+		listCountries: func(ctx context.Context, client *neo4jstore.Client) ([]neo4jstore.Country, error) {
+			return neo4jstore.ListActiveCountries(ctx, client.Driver(), client.Database())
+		},
+		now: time.Now,
+	}
+}
+
+// SetNeo4jClient lists countries from Neo4j instead of the country graph
+func (h *CountryInfoHandler) SetNeo4jClient(client *neo4jstore.Client) {
+	h.neo4j.Store(client)
+}
+
+// SetGraph sets the country graph countries are described from
+func (h *CountryInfoHandler) SetGraph(graph *router.CountryGraph) {
+	h.graph = graph
+}
+
+// SetTransactions sets the store recent hop outcomes are read from
+func (h *CountryInfoHandler) SetTransactions(txns payments.TransactionStorer) {
+	h.txns = txns
+}
+
+// SetCredibility sets where current credibility and its history are read from
+func (h *CountryInfoHandler) SetCredibility(batcher *neo4jstore.CredibilityBatcher, history neo4jstore.CredibilityHistory) {
+	h.credibility = batcher
+	h.history = history
+}
+
+// PublicCountry is a country payments can be routed through
+type PublicCountry struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Currency    string  `json:"currency"`
+	FXRate      float64 `json:"fx_rate"` // Currency per USD
+	SuccessRate float64 `json:"success_rate"`
+	GDPRank     int     `json:"gdp_rank,omitempty"` // Not known to the country graph
+}
+
+// CountryListResponse lists the countries that are not halted
+type CountryListResponse struct {
+	Countries []PublicCountry `json:"countries"`
+	Count     int             `json:"count"`
+	Source    string          `json:"source"` // "neo4j", or "graph" while Neo4j is unavailable
+}
+
+// CountryPartner is a trading partner of a country
+type CountryPartner struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name,omitempty"`
+	BaseCost  float64 `json:"base_cost"`
+	LatencyMs int64   `json:"latency_ms"`
+	Weight    float64 `json:"weight"` // Routing weight of the corridor toward the partner
+	IsActive  bool    `json:"is_active"`
+	Halted    bool    `json:"halted"`
+	Blocked   bool    `json:"blocked"`
+}
+
+// CountryDetailResponse describes a country as routing currently sees it
+type CountryDetailResponse struct {
+	Code              string                   `json:"code"`
+	Name              string                   `json:"name"`
+	Currency          string                   `json:"currency"`
+	FXRate            float64                  `json:"fx_rate"`
+	Credibility       float64                  `json:"credibility"`         // As of the last write, else as loaded into the graph
+	SuccessRate       float64                  `json:"success_rate"`        // Routing success rate from the graph
+	RecentSuccessRate *float64                 `json:"recent_success_rate"` // Of recent hops into the country; null without any
+	RecentHops        payments.HopOutcomes     `json:"recent_hops"`
+	RecentWindow      string                   `json:"recent_window"`
+	Halted            bool                     `json:"halted"`
+	Blocked           bool                     `json:"blocked"`
+	Hours             *router.SettlementWindow `json:"hours,omitempty"`
+	Partners          []CountryPartner         `json:"partners"` // Sorted by code
+}
+
+// CredibilityHistoryResponse is a country's credibility changes over a range
+type CredibilityHistoryResponse struct {
+	Code     string                        `json:"code"`
+	Current  *float64                      `json:"current,omitempty"` // As of the last write; omitted before one
+	Baseline float64                       `json:"baseline"`          // Default credibility decays toward
+	Range    string                        `json:"range"`
+	Since    time.Time                     `json:"since"`
+	Points   []neo4jstore.CredibilityPoint `json:"points"` // Oldest first
+}
+
+// HandleListCountries handles GET /api/v1/countries. Authentication is not
+// required. The list is rebuilt at most every countryListTTL and carries an
+// ETag, so clients revalidating with If-None-Match get 304 until it changes.
+func (h *CountryInfoHandler) HandleListCountries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	list, err := h.countryList(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list countries", "error", err)
+		apierror.Respond(w, http.StatusServiceUnavailable, "countries not available")
+		return
+	}
+
+	w.Header().Set("ETag", list.etag)
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(countryListTTL.Seconds())))
+	if etagMatches(r.Header.Get("If-None-Match"), list.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(list.body)
+}
+
+// countryList returns the cached country list, rebuilding it once expired
+func (h *CountryInfoHandler) countryList(ctx context.Context) (*countryList, error) {
+	h.listMu.Lock()
+	defer h.listMu.Unlock()
+	if h.list != nil && h.now().Before(h.list.expires) {
+		return h.list, nil
+	}
+
+	resp, err := h.listFromNeo4j(ctx)
+	if resp == nil {
+		if err != nil {
+			slog.WarnContext(ctx, "failed to list countries from Neo4j, using the country graph", "error", err)
+		}
+		if h.graph == nil {
+			return nil, errors.New("no country source available")
+		}
+		resp = h.listFromGraph()
+	}
+	resp.Count = len(resp.Countries)
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	h.list = &countryList{
+		body:    append(body, '\n'),
+		etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+		expires: h.now().Add(countryListTTL),
+	}
+	return h.list, nil
+}
+
+// listFromNeo4j lists the countries stored in Neo4j. Returns nil without a
+// connected client.
+func (h *CountryInfoHandler) listFromNeo4j(ctx context.Context) (*CountryListResponse, error) {
+	client := h.neo4j.Load()
+	if client == nil {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, countryListTimeout)
+	defer cancel()
+	countries, err := h.listCountries(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &CountryListResponse{Countries: make([]PublicCountry, len(countries)), Source: "neo4j"}
+	for i, c := range countries {
+		resp.Countries[i] = PublicCountry{
+			Code:        c.Code,
+			Name:        c.Name,
+			Currency:    c.Currency,
+			FXRate:      c.FXRate,
+			SuccessRate: c.SuccessRate,
+			GDPRank:     c.GDPRank,
+		}
+	}
+	return resp, nil
+}
+
+// listFromGraph lists the countries routing knows about, by code
+func (h *CountryInfoHandler) listFromGraph() *CountryListResponse {
+	resp := &CountryListResponse{Countries: []PublicCountry{}, Source: "graph"}
+	for _, node := range h.graph.Export().Nodes {
+		if !node.IsActive {
+			continue
+		}
+		resp.Countries = append(resp.Countries, PublicCountry{
+			Code:        node.Code,
+			Name:        node.Name,
+			Currency:    node.Currency,
+			FXRate:      node.FXRate,
+			SuccessRate: node.SuccessRate,
+		})
+	}
+	return resp
+}
+
+// HandleCountry routes /api/v1/countries/{code} and /api/v1/countries/{code}/...
+func (h *CountryInfoHandler) HandleCountry(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/countries/")
+	switch {
+	case strings.HasSuffix(path, "/credibility/history"):
+		h.handleCredibilityHistory(w, r, strings.TrimSuffix(path, "/credibility/history"))
+	case path != "" && !strings.Contains(path, "/"):
+		h.handleCountryDetail(w, r, path)
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+	}
+}
+
+// handleCountryDetail handles GET /api/v1/countries/{code}
+func (h *CountryInfoHandler) handleCountryDetail(w http.ResponseWriter, r *http.Request, code string) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.graph == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "country graph not available")
+		return
+	}
+
+	code = strings.ToUpper(code)
+	if !refdata.IsCountry(code) {
+		apierror.Respond(w, http.StatusBadRequest, "country code must be ISO 3166-1 alpha-3")
+		return
+	}
+	node, edges, ok := h.graph.Country(code)
+	if !ok {
+		apierror.Respond(w, http.StatusNotFound, "country not found")
+		return
+	}
+
+	resp := CountryDetailResponse{
+		Code:         node.Code,
+		Name:         node.Name,
+		Currency:     node.Currency,
+		FXRate:       node.FXRate,
+		Credibility:  node.Credibility,
+		SuccessRate:  node.SuccessRate,
+		RecentWindow: payments.HopOutcomeWindow.String(),
+		Halted:       !node.IsActive,
+		Blocked:      h.graph.IsBlocked(code),
+		Hours:        node.Hours,
+		Partners:     make([]CountryPartner, 0, len(edges)),
+	}
+	if h.credibility != nil {
+		if cred, ok := h.credibility.Credibility(code); ok {
+			resp.Credibility = cred
+		}
+	}
+	if h.txns != nil {
+		resp.RecentHops = h.txns.CountryOutcomes(code, payments.HopOutcomeWindow)
+		if rate, ok := resp.RecentHops.SuccessRate(); ok {
+			resp.RecentSuccessRate = &rate
+		}
+	}
+
+	for _, edge := range edges {
+		partner := CountryPartner{
+			Code:      edge.TargetCode,
+			BaseCost:  edge.BaseCost,
+			LatencyMs: edge.LatencyMs,
+			IsActive:  edge.IsActive,
+			Blocked:   h.graph.IsBlocked(edge.TargetCode),
+		}
+		if p, _, ok := h.graph.Country(edge.TargetCode); ok {
+			partner.Name = p.Name
+			partner.Halted = !p.IsActive
+		}
+		partner.Weight, _ = h.graph.EdgeWeightBetween(code, edge.TargetCode)
+		resp.Partners = append(resp.Partners, partner)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// handleCredibilityHistory handles GET /api/v1/countries/{code}/credibility/history
+// Query params: range (e.g. 24h, 7d, 4w; default 7d, max 90d)
+func (h *CountryInfoHandler) handleCredibilityHistory(w http.ResponseWriter, r *http.Request, code string) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.history == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "credibility history not available")
+		return
+	}
+
+	code = strings.ToUpper(code)
+	if !refdata.IsCountry(code) {
+		apierror.Respond(w, http.StatusBadRequest, "country code must be ISO 3166-1 alpha-3")
+		return
+	}
+
+	rangeParam := r.URL.Query().Get("range")
+	if rangeParam == "" {
+		rangeParam = "7d"
+	}
+	window, err := parseHistoryRange(rangeParam)
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, "invalid range, use e.g. 24h, 7d or 4w")
+		return
+	}
+	if window > maxCredibilityHistoryRange {
+		window = maxCredibilityHistoryRange
+	}
+
+	since := time.Now().UTC().Add(-window)
+	points, err := h.history.History(r.Context(), code, since)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read credibility history", "code", code, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to read credibility history")
+		return
+	}
+
+	resp := CredibilityHistoryResponse{
+		Code:     code,
+		Baseline: neo4jstore.CredibilityBaseline,
+		Range:    rangeParam,
+		Since:    since,
+		Points:   points,
+	}
+	if h.credibility != nil {
+		if cred, ok := h.credibility.Credibility(code); ok {
+			resp.Current = &cred
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
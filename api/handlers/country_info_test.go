@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	neo4jstore "github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+)
+
+func TestCredibilityHistory(t *testing.T) {
+	history := neo4jstore.NewMemoryCredibilityHistory(0)
+	now := time.Now().UTC()
+	history.Append(context.Background(), []neo4jstore.CredibilityPoint{
+		{Code: "USA", Credibility: 0.851, Change: 0.001, Reason: neo4jstore.CredibilityReasonHops, Successes: 10, At: now.Add(-48 * time.Hour)},
+		{Code: "USA", Credibility: 0.8505, Change: -0.0005, Reason: neo4jstore.CredibilityReasonDecay, At: now.Add(-time.Hour)},
+	})
+	h := NewCountryInfoHandler()
+	h.SetCredibility(neo4jstore.NewCredibilityBatcher(nil), history)
+
+	rec := httptest.NewRecorder()
+	h.HandleCountry(rec, httptest.NewRequest(http.MethodGet, "/api/v1/countries/usa/credibility/history?range=24h", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp CredibilityHistoryResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.Code != "USA" || len(resp.Points) != 1 || resp.Points[0].Reason != neo4jstore.CredibilityReasonDecay {
+		t.Errorf("response = %+v, want USA with the change within 24h", resp)
+	}
+	if resp.Current != nil || resp.Baseline != neo4jstore.CredibilityBaseline {
+		t.Errorf("current = %v, baseline = %v; want no current before a write", resp.Current, resp.Baseline)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/countries/XXX/credibility/history":           http.StatusBadRequest,
+		"/api/v1/countries/USA/credibility/history?range=abc": http.StatusBadRequest,
+		"/api/v1/countries/USA/credibility":                   http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		h.HandleCountry(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestCountryDetail(t *testing.T) {
+	graph := router.NewCountryGraph()
+	graph.AddNode(&router.CountryNode{Code: "USA", Name: "United States", Currency: "USD", Credibility: 0.9, SuccessRate: 0.95, FXRate: 1, IsActive: true})
+	graph.AddNode(&router.CountryNode{Code: "GBR", Name: "United Kingdom", Currency: "GBP", Credibility: 0.9, SuccessRate: 0.95, FXRate: 0.79, IsActive: true})
+	graph.AddNode(&router.CountryNode{Code: "DEU", Name: "Germany", Currency: "EUR", Credibility: 0.9, SuccessRate: 0.95, FXRate: 0.92, IsActive: false})
+	graph.AddEdge(&router.CountryEdge{SourceCode: "USA", TargetCode: "GBR", BaseCost: 0.01, IsActive: true})
+	graph.AddEdge(&router.CountryEdge{SourceCode: "GBR", TargetCode: "DEU", BaseCost: 0.02, IsActive: true})
+	graph.SetBlocked([]string{"USA"})
+
+	store := payments.NewTransactionStore()
+	store.SetSimulator(payments.NewSimulator(1, &payments.FailureProfile{Name: "instant", Countries: map[string]payments.CountryProfile{}}))
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	store.ProcessTransaction(context.Background(), txn.ID, nil, 0)
+
+	h := NewCountryInfoHandler()
+	h.SetGraph(graph)
+	h.SetTransactions(store)
+
+	rec := httptest.NewRecorder()
+	h.HandleCountry(rec, httptest.NewRequest(http.MethodGet, "/api/v1/countries/gbr", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var resp CountryDetailResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.Code != "GBR" || resp.FXRate != 0.79 || resp.Credibility != 0.9 || resp.Halted || resp.Blocked {
+		t.Errorf("response = %+v", resp)
+	}
+	if resp.RecentSuccessRate == nil || *resp.RecentSuccessRate != 1 || resp.RecentHops.Successes != 1 {
+		t.Errorf("recent = %v, %+v; want the one successful hop into GBR", resp.RecentSuccessRate, resp.RecentHops)
+	}
+	if len(resp.Partners) != 2 {
+		t.Fatalf("partners = %+v, want DEU and USA", resp.Partners)
+	}
+	deu, usa := resp.Partners[0], resp.Partners[1]
+	if deu.Code != "DEU" || !deu.Halted || deu.Blocked || deu.Weight <= 0 {
+		t.Errorf("DEU partner = %+v, want halted with a weight", deu)
+	}
+	if usa.Code != "USA" || !usa.Blocked || usa.Name != "United States" {
+		t.Errorf("USA partner = %+v, want blocked", usa)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/countries/XXX": http.StatusBadRequest,
+		"/api/v1/countries/FRA": http.StatusNotFound,
+	} {
+		rec := httptest.NewRecorder()
+		h.HandleCountry(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("GET %s = %d, want %d", path, rec.Code, want)
+		}
+	}
+}
+
+func TestListCountries(t *testing.T) {
+	graph := router.NewCountryGraph()
+	graph.AddNode(&router.CountryNode{Code: "USA", Name: "United States", Currency: "USD", FXRate: 1, SuccessRate: 0.95, IsActive: true})
+	graph.AddNode(&router.CountryNode{Code: "DEU", Name: "Germany", Currency: "EUR", FXRate: 0.92, SuccessRate: 0.94, IsActive: false})
+
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var neo4jErr error
+	neo4jCalls := 0
+	h := NewCountryInfoHandler()
+	h.SetGraph(graph)
+	h.now = func() time.Time { return now }
+	h.listCountries = func(ctx context.Context, client *neo4jstore.Client) ([]neo4jstore.Country, error) {
+		neo4jCalls++
+		if neo4jErr != nil {
+			return nil, neo4jErr
+		}
+		return []neo4jstore.Country{{Code: "USA", Name: "United States", Currency: "USD", FXRate: 1, GDPRank: 1}}, nil
+	}
+
+	list := func(header string) (*httptest.ResponseRecorder, CountryListResponse) {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/countries", nil)
+		if header != "" {
+			req.Header.Set("If-None-Match", header)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleListCountries(rec, req)
+		var resp CountryListResponse
+		if rec.Code == http.StatusOK {
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid JSON: %v", err)
+			}
+		}
+		return rec, resp
+	}
+
+	// Without Neo4j the graph's active countries are served
+	rec, resp := list("")
+	if rec.Code != http.StatusOK || resp.Source != "graph" || resp.Count != 1 || resp.Countries[0].Code != "USA" {
+		t.Fatalf("graph list = %d %+v, want USA from the graph", rec.Code, resp)
+	}
+
+	// Neo4j is used once connected, after the cached list expires
+	h.SetNeo4jClient(&neo4jstore.Client{})
+	if _, resp := list(""); resp.Source != "graph" || neo4jCalls != 0 {
+		t.Errorf("source = %s after %d Neo4j calls, want the cached graph list", resp.Source, neo4jCalls)
+	}
+	now = now.Add(countryListTTL)
+	rec, resp = list("")
+	if resp.Source != "neo4j" || resp.Countries[0].GDPRank != 1 {
+		t.Errorf("list = %+v, want the Neo4j countries", resp)
+	}
+	etag := rec.Header().Get("ETag")
+	if rec, _ := list(etag); rec.Code != http.StatusNotModified {
+		t.Errorf("revalidation = %d, want 304", rec.Code)
+	}
+
+	// A failing Neo4j falls back to the graph
+	neo4jErr = errors.New("connection refused")
+	now = now.Add(countryListTTL)
+	if _, resp := list(""); resp.Source != "graph" || neo4jCalls != 2 {
+		t.Errorf("source = %s after %d Neo4j calls, want the graph after one failed call", resp.Source, neo4jCalls)
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// DeadLetterQueue is the dead letter stream. Satisfied by messaging/nats.Client.
+type DeadLetterQueue interface {
+	DeadLetters(ctx context.Context, consumer string, limit int) ([]*natsClient.DeadLetter, error)
+	DeadLetterDepth(ctx context.Context) (uint64, error)
+	ReplayDeadLetter(ctx context.Context, id uint64) (*natsClient.DeadLetter, error)
+	DiscardDeadLetter(ctx context.Context, id uint64) (*natsClient.DeadLetter, error)
+}
+
+// DeadLetterHandler serves the dead letter queue admin API
+type DeadLetterHandler struct {
+	queue DeadLetterQueue
+	audit audit.Store
+}
+
+// NewDeadLetterHandler creates a new dead letter handler
+func NewDeadLetterHandler(queue DeadLetterQueue) *DeadLetterHandler {
+	return &DeadLetterHandler{queue: queue}
+}
+
+// SetAuditStore enables audit logging of replays and discards
+func (h *DeadLetterHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// HandleDeadLetters handles the dead letter queue API:
+//
+//	GET    /api/v1/admin/dlq?consumer=&limit=50 - dead letters, oldest first
+//	POST   /api/v1/admin/dlq/{id}/replay        - republish for its consumer
+//	DELETE /api/v1/admin/dlq/{id}               - discard
+func (h *DeadLetterHandler) HandleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/dlq"), "/")
+	if path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	idStr, action, _ := strings.Cut(path, "/")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		apierror.Respond(w, http.StatusBadRequest, "invalid dead letter id")
+		return
+	}
+
+	var letter *natsClient.DeadLetter
+	switch {
+	case action == "replay" && r.Method == http.MethodPost:
+		letter, err = h.queue.ReplayDeadLetter(r.Context(), id)
+		action = "dlq.replay"
+	case action == "" && r.Method == http.MethodDelete:
+		letter, err = h.queue.DiscardDeadLetter(r.Context(), id)
+		action = "dlq.discard"
+	case action == "replay" || action == "":
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+		return
+	}
+	if errors.Is(err, natsClient.ErrDeadLetterNotFound) {
+		apierror.Respond(w, http.StatusNotFound, "dead letter not found")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "dead letter action failed", "action", action, "id", id, "error", err)
+		apierror.Respond(w, http.StatusServiceUnavailable, "dead letter queue unavailable")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "dead letter handled", "action", action, "id", id, "subject", letter.Subject, "consumer", letter.Consumer)
+	recordAudit(h.audit, r, http.StatusOK, action, "dead_letter", idStr, letter, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(letter)
+}
+
+func (h *DeadLetterHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	limit := 50
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 500 {
+			apierror.Respond(w, http.StatusBadRequest, "limit must be between 1 and 500")
+			return
+		}
+		limit = n
+	}
+
+	letters, err := h.queue.DeadLetters(r.Context(), r.URL.Query().Get("consumer"), limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list dead letters", "error", err)
+		apierror.Respond(w, http.StatusServiceUnavailable, "dead letter queue unavailable")
+		return
+	}
+	depth, err := h.queue.DeadLetterDepth(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read dead letter depth", "error", err)
+		apierror.Respond(w, http.StatusServiceUnavailable, "dead letter queue unavailable")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"dead_letters": letters,
+		"count":        len(letters),
+		"depth":        depth,
+	})
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// memoryDLQ is an in-memory DeadLetterQueue
+type memoryDLQ struct {
+	letters  map[uint64]*natsClient.DeadLetter
+	replayed []uint64
+}
+
+func (q *memoryDLQ) DeadLetters(ctx context.Context, consumer string, limit int) ([]*natsClient.DeadLetter, error) {
+	out := make([]*natsClient.DeadLetter, 0)
+	for id := uint64(1); id <= 10 && len(out) < limit; id++ {
+		if l, ok := q.letters[id]; ok && (consumer == "" || l.Consumer == consumer) {
+			out = append(out, l)
+		}
+	}
+	return out, nil
+}
+
+func (q *memoryDLQ) DeadLetterDepth(ctx context.Context) (uint64, error) {
+	return uint64(len(q.letters)), nil
+}
+
+func (q *memoryDLQ) ReplayDeadLetter(ctx context.Context, id uint64) (*natsClient.DeadLetter, error) {
+	l, err := q.DiscardDeadLetter(ctx, id)
+	if err == nil {
+		q.replayed = append(q.replayed, id)
+	}
+	return l, err
+}
+
+func (q *memoryDLQ) DiscardDeadLetter(ctx context.Context, id uint64) (*natsClient.DeadLetter, error) {
+	l, ok := q.letters[id]
+	if !ok {
+		return nil, natsClient.ErrDeadLetterNotFound
+	}
+	delete(q.letters, id)
+	return l, nil
+}
+
+func TestDeadLetterAPI(t *testing.T) {
+	queue := &memoryDLQ{letters: map[uint64]*natsClient.DeadLetter{
+		1: {ID: 1, Subject: "liquidity.updates.USA", Consumer: "graph-sync-consumer", Deliveries: 3},
+		2: {ID: 2, Subject: "liquidity.updates.GBR", Consumer: "graph-sync-consumer", Deliveries: 1},
+		3: {ID: 3, Subject: "other.subject", Consumer: "other-consumer", Deliveries: 3},
+	}}
+	h := NewDeadLetterHandler(queue)
+
+	do := func(method, target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		h.HandleDeadLetters(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+
+	rec := do(http.MethodGet, "/api/v1/admin/dlq?consumer=graph-sync-consumer&limit=10")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list: status %d", rec.Code)
+	}
+	var list struct {
+		DeadLetters []*natsClient.DeadLetter `json:"dead_letters"`
+		Count       int                      `json:"count"`
+		Depth       uint64                   `json:"depth"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if list.Count != 2 || list.Depth != 3 || list.DeadLetters[0].ID != 1 {
+		t.Fatalf("unexpected list: %+v", list)
+	}
+
+	if rec := do(http.MethodPost, "/api/v1/admin/dlq/1/replay"); rec.Code != http.StatusOK {
+		t.Fatalf("replay: status %d", rec.Code)
+	}
+	if len(queue.replayed) != 1 || queue.replayed[0] != 1 {
+		t.Errorf("expected letter 1 replayed, got %v", queue.replayed)
+	}
+	if rec := do(http.MethodPost, "/api/v1/admin/dlq/1/replay"); rec.Code != http.StatusNotFound {
+		t.Errorf("replaying twice: status %d, want 404", rec.Code)
+	}
+	if rec := do(http.MethodDelete, "/api/v1/admin/dlq/2"); rec.Code != http.StatusOK {
+		t.Fatalf("discard: status %d", rec.Code)
+	}
+	if len(queue.letters) != 1 {
+		t.Errorf("expected one letter left, got %d", len(queue.letters))
+	}
+
+	for _, tc := range []struct {
+		method, target string
+		code           int
+	}{
+		{http.MethodGet, "/api/v1/admin/dlq/abc", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/admin/dlq/3/replay", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/v1/admin/dlq/3/retry", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/admin/dlq?limit=0", http.StatusBadRequest},
+	} {
+		if rec := do(tc.method, tc.target); rec.Code != tc.code {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.target, rec.Code, tc.code)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// maxFeeScheduleHistory is the number of past schedules GET /api/v1/admin/fees returns
+const maxFeeScheduleHistory = 50
+
+// FeeScheduleApplier applies a published fee schedule to new transactions.
+// Implemented by the transaction stores.
+type FeeScheduleApplier interface {
+	SetFeeSchedule(schedule *payments.FeeSchedule)
+}
+
+// FeeHandler lets admins change the fee schedule at runtime
+type FeeHandler struct {
+	schedules payments.FeeScheduleStore
+	applier   FeeScheduleApplier
+	audit     audit.Store
+}
+
+// NewFeeHandler creates a new fee schedule handler
+func NewFeeHandler(schedules payments.FeeScheduleStore, applier FeeScheduleApplier) *FeeHandler {
+	return &FeeHandler{schedules: schedules, applier: applier}
+}
+
+// SetAuditStore records fee schedule changes in store
+func (h *FeeHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// FeeScheduleResponse is the active fee schedule and its predecessors
+type FeeScheduleResponse struct {
+	Current *payments.FeeSchedule   `json:"current"`
+	History []*payments.FeeSchedule `json:"history"` // Newest first, including the current version
+}
+
+// HandleFees handles GET and PUT /api/v1/admin/fees.
+// PUT accepts any subset of base_fee_percent, hop_fee_percent and
+// halt_fine_percent (fractions, 0.015 = 1.5%) and publishes them as a new
+// version. Transactions keep the version active when they were created.
+func (h *FeeHandler) HandleFees(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.handleGet(w, r)
+	case http.MethodPut:
+		h.handleUpdate(w, r)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *FeeHandler) handleGet(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	history, err := h.schedules.List(ctx, maxFeeScheduleHistory)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list fee schedules", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to read fee schedules")
+		return
+	}
+	response := FeeScheduleResponse{History: history}
+	if len(history) > 0 {
+		response.Current = history[0]
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *FeeHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	before, err := h.schedules.Current(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to read fee schedule", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to read fee schedule")
+		return
+	}
+	fees := payments.DefaultFeeConfig()
+	if before != nil {
+		fees = before.FeeConfig
+	}
+	if err := validate.Decode(r, &fees); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := fees.Validate(); err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	admin := ""
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		admin = user.Username
+	}
+	schedule, err := h.schedules.Publish(ctx, fees, admin)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to publish fee schedule", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to publish fee schedule")
+		return
+	}
+	h.applier.SetFeeSchedule(schedule)
+
+	slog.InfoContext(ctx, "fee schedule published", "admin", admin, "version", schedule.Version,
+		"base", fees.BaseFeePercent, "hop", fees.HopFeePercent, "halt", fees.HaltFinePercent)
+	recordAudit(h.audit, r, http.StatusOK, "fees.update", "fee_schedule", "current", before, schedule)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(schedule)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/workers/fxrates"
+)
+
+// Limits for FX history ranges
+const (
+	defaultFXHistoryRange = 30 * 24 * time.Hour
+	maxFXHistoryRange     = 365 * 24 * time.Hour
+)
+
+// FXHandler serves the latest exchange rates fetched by the FX worker and
+// their history
+type FXHandler struct {
+	worker  *fxrates.Worker
+	history fxrates.HistoryStore
+}
+
+// NewFXHandler creates a new FX handler
+func NewFXHandler(worker *fxrates.Worker) *FXHandler {
+	return &FXHandler{worker: worker}
+}
+
+// SetHistoryStore sets the store GET /api/v1/fx/history reads from
+func (h *FXHandler) SetHistoryStore(store fxrates.HistoryStore) {
+	h.history = store
+}
+
+// FXRatesResponse is the latest rate for each currency
+type FXRatesResponse struct {
+	Base      string                  `json:"base"`
+	Rates     map[string]fxrates.Rate `json:"rates"`                // Keyed by currency
+	UpdatedAt *time.Time              `json:"updated_at,omitempty"` // Most recent rate update
+}
+
+// HandleRates handles GET /api/v1/fx/rates
+func (h *FXHandler) HandleRates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	resp := FXRatesResponse{Base: fxrates.BaseCurrency, Rates: h.worker.Rates()}
+	for _, rate := range resp.Rates {
+		if resp.UpdatedAt == nil || rate.UpdatedAt.After(*resp.UpdatedAt) {
+			updatedAt := rate.UpdatedAt
+			resp.UpdatedAt = &updatedAt
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// FXHistoryResponse is a currency's rate series with summary statistics
+type FXHistoryResponse struct {
+	Currency string          `json:"currency"`
+	Base     string          `json:"base"`
+	Range    string          `json:"range"`
+	Since    time.Time       `json:"since"`
+	Points   []fxrates.Point `json:"points"` // Oldest first
+	Stats    fxrates.Stats   `json:"stats"`
+}
+
+// HandleHistory handles GET /api/v1/fx/history
+// Query params: currency (required, e.g. EUR), range (e.g. 24h, 7d, 4w; default 30d, max 365d)
+func (h *FXHandler) HandleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if h.history == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "fx history not available")
+		return
+	}
+
+	q := r.URL.Query()
+	currency := strings.ToUpper(strings.TrimSpace(q.Get("currency")))
+	if len(currency) != 3 {
+		apierror.Respond(w, http.StatusBadRequest, "currency must be a 3-letter code")
+		return
+	}
+
+	rangeParam := q.Get("range")
+	if rangeParam == "" {
+		rangeParam = "30d"
+	}
+	window, err := parseHistoryRange(rangeParam)
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, "invalid range, use e.g. 24h, 7d or 4w")
+		return
+	}
+	if window > maxFXHistoryRange {
+		window = maxFXHistoryRange
+	}
+
+	since := time.Now().UTC().Add(-window)
+	points, err := h.history.History(r.Context(), currency, since)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read fx history", "currency", currency, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to read fx history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(FXHistoryResponse{
+		Currency: currency,
+		Base:     fxrates.BaseCurrency,
+		Range:    rangeParam,
+		Since:    since,
+		Points:   points,
+		Stats:    fxrates.Summarize(points),
+	})
+}
+
+// parseHistoryRange parses a positive duration, accepting d (days) and
+// w (weeks) suffixes in addition to Go durations
+func parseHistoryRange(s string) (time.Duration, error) {
+	unit := time.Duration(0)
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+
+	var d time.Duration
+	if unit > 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil {
+			return 0, err
+		}
+		d = time.Duration(n) * unit
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, err
+		}
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("range must be positive")
+	}
+	return d, nil
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+)
+
+// GraphSnapshot is a backup of the mesh and country routing graphs
+type GraphSnapshot struct {
+	ExportedAt time.Time             `json:"exported_at"`
+	Mesh       *router.MeshExport    `json:"mesh,omitempty"`
+	Countries  *router.CountryExport `json:"countries,omitempty"`
+}
+
+// SetCountryGraph sets the country graph included in graph export and import
+func (h *AdminHandler) SetCountryGraph(graph *router.CountryGraph) {
+	h.countryGraph = graph
+}
+
+// HandleExportGraph handles GET /api/v1/admin/graph/export
+func (h *AdminHandler) HandleExportGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphRead) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	snapshot := GraphSnapshot{
+		ExportedAt: time.Now().UTC(),
+		Mesh:       h.graph.Export(),
+	}
+	if h.countryGraph != nil {
+		snapshot.Countries = h.countryGraph.Export()
+	}
+
+	slog.InfoContext(r.Context(), "graph exported", "admin", user.Username, "mesh_nodes", len(snapshot.Mesh.Nodes))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="plm-graph-`+snapshot.ExportedAt.Format("20060102-150405")+`.json"`)
+	json.NewEncoder(w).Encode(snapshot)
+}
+
+// HandleImportGraph handles POST /api/v1/admin/graph/import.
+// Each graph present in the body replaces the in-memory graph; ?persist=true
+// also writes it to Neo4j first. Nothing changes unless every graph is valid.
+// Without persist, an imported country graph lasts until the next refresh from Neo4j.
+func (h *AdminHandler) HandleImportGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	var snapshot GraphSnapshot
+	if err := validate.Decode(r, &snapshot); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if snapshot.Mesh == nil && snapshot.Countries == nil {
+		apierror.Respond(w, http.StatusBadRequest, "mesh or countries is required")
+		return
+	}
+	persist := r.URL.Query().Get("persist") == "true"
+
+	// Validate everything before changing anything
+	if snapshot.Mesh != nil {
+		if err := snapshot.Mesh.Validate(); err != nil {
+			apierror.Respond(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	var countryGraph *router.CountryGraph
+	if snapshot.Countries != nil {
+		if h.countryGraph == nil {
+			apierror.Respond(w, http.StatusBadRequest, "country routing is not enabled")
+			return
+		}
+		var err error
+		if countryGraph, err = snapshot.Countries.Graph(); err != nil {
+			apierror.Respond(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	if persist {
+		if h.neo4j.Load() == nil {
+			apierror.Respond(w, http.StatusServiceUnavailable, "neo4j is not available")
+			return
+		}
+		if err := h.persistGraph(r.Context(), &snapshot); err != nil {
+			slog.ErrorContext(r.Context(), "failed to persist graph import", "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to write graph to neo4j")
+			return
+		}
+	}
+
+	before := map[string]interface{}{
+		"mesh_nodes": len(h.graph.GetAllNodes()),
+		"mesh_edges": len(h.graph.GetAllEdges()),
+	}
+	if h.countryGraph != nil {
+		before["countries"] = h.countryGraph.NodeCount()
+	}
+	if snapshot.Mesh != nil {
+		if err := h.graph.Import(snapshot.Mesh); err != nil {
+			apierror.Respond(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if countryGraph != nil {
+		h.countryGraph.Replace(countryGraph)
+	}
+
+	summary := map[string]interface{}{"persisted": persist}
+	if snapshot.Mesh != nil {
+		summary["mesh_nodes"] = len(snapshot.Mesh.Nodes)
+		summary["mesh_edges"] = len(snapshot.Mesh.Edges)
+	}
+	if snapshot.Countries != nil {
+		summary["countries"] = len(snapshot.Countries.Nodes)
+		summary["country_edges"] = len(snapshot.Countries.Edges)
+	}
+
+	slog.InfoContext(r.Context(), "graph imported", "admin", user.Username, "persisted", persist)
+	recordAudit(h.audit, r, http.StatusOK, "graph.import", "graph", "", before, summary)
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "GRAPH_IMPORTED",
+			"data": summary,
+		})
+	}
+
+	summary["success"] = true
+	summary["message"] = "Graph imported successfully"
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(summary)
+}
+
+// persistGraph writes the imported graphs to Neo4j
+func (h *AdminHandler) persistGraph(ctx context.Context, snapshot *GraphSnapshot) error {
+	client := h.neo4j.Load()
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if snapshot.Mesh != nil {
+		nodes := make([]neo4j.Node, len(snapshot.Mesh.Nodes))
+		for i, n := range snapshot.Mesh.Nodes {
+			nodes[i] = neo4j.Node{ID: n.ID, Type: n.Type, Region: n.Region, IsActive: n.IsActive, Props: n.StoredProps()}
+		}
+		edges := make([]neo4j.Edge, len(snapshot.Mesh.Edges))
+		for i, e := range snapshot.Mesh.Edges {
+			edges[i] = neo4j.Edge{
+				SourceID:        e.SourceID,
+				TargetID:        e.TargetID,
+				BaseFee:         e.BaseFee,
+				Latency:         e.Latency,
+				LiquidityVolume: e.LiquidityVolume,
+				IsActive:        e.IsActive,
+			}
+		}
+		if err := client.ReplaceMesh(ctx, nodes, edges); err != nil {
+			return err
+		}
+	}
+	if snapshot.Countries != nil {
+		if err := router.SaveCountryGraph(ctx, client.Driver(), client.Database(), snapshot.Countries); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+// GraphView is the mesh and country state drawn by the dashboard graph
+type GraphView struct {
+	Nodes     []GraphNodeView    `json:"nodes"`
+	Edges     []GraphEdgeView    `json:"edges"`
+	Countries []GraphCountryView `json:"countries"`
+	Blocked   []string           `json:"blocked_countries"` // Excluded from routing by policy
+	Halted    []string           `json:"halted_countries"`  // Paused by an operator
+}
+
+// GraphNodeView is a mesh node with its live status
+type GraphNodeView struct {
+	ID       string   `json:"id"`
+	Type     string   `json:"type"`
+	Region   string   `json:"region,omitempty"`
+	IsActive bool     `json:"is_active"`
+	Entropy  *float64 `json:"entropy,omitempty"` // Liquidity distribution entropy, when measured
+}
+
+// GraphEdgeView is a mesh edge with its live fees, latency and liquidity
+type GraphEdgeView struct {
+	SourceID           string  `json:"source_id"`
+	TargetID           string  `json:"target_id"`
+	BaseFee            float64 `json:"base_fee"`
+	LatencyMs          int64   `json:"latency_ms"`
+	EffectiveLatencyMs int64   `json:"effective_latency_ms"` // Including injected degradation
+	LiquidityVolume    int64   `json:"liquidity_volume"`
+	AvailableLiquidity int64   `json:"available_liquidity"`
+	IsActive           bool    `json:"is_active"`
+}
+
+// GraphCountryView is a country with its routing status
+type GraphCountryView struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Currency    string  `json:"currency"`
+	Credibility float64 `json:"credibility"`
+	Blocked     bool    `json:"blocked"`
+	Halted      bool    `json:"halted"`
+}
+
+// graphView builds the dashboard graph from the routing graphs
+func (h *AdminHandler) graphView() *GraphView {
+	mesh := h.graph.Export()
+	entropies := h.graph.NodeEntropies()
+
+	view := &GraphView{
+		Nodes:     make([]GraphNodeView, len(mesh.Nodes)),
+		Edges:     make([]GraphEdgeView, len(mesh.Edges)),
+		Countries: []GraphCountryView{},
+		Blocked:   []string{},
+		Halted:    []string{},
+	}
+	for i, n := range mesh.Nodes {
+		view.Nodes[i] = GraphNodeView{ID: n.ID, Type: n.Type, Region: n.Region, IsActive: n.IsActive}
+		if e, ok := entropies[n.ID]; ok {
+			view.Nodes[i].Entropy = &e
+		}
+	}
+	for i, e := range mesh.Edges {
+		view.Edges[i] = GraphEdgeView{
+			SourceID:           e.SourceID,
+			TargetID:           e.TargetID,
+			BaseFee:            e.BaseFee,
+			LatencyMs:          e.Latency,
+			EffectiveLatencyMs: e.EffectiveLatency(),
+			LiquidityVolume:    e.LiquidityVolume,
+			AvailableLiquidity: e.Available(),
+			IsActive:           e.IsActive,
+		}
+	}
+
+	if h.countryGraph == nil {
+		return view
+	}
+	// Blocked countries need not be in the graph, so they are listed from the policy
+	view.Blocked = append(view.Blocked, h.countryGraph.BlockedCodes()...)
+	for _, c := range h.countryGraph.Export().Nodes {
+		country := GraphCountryView{
+			Code:        c.Code,
+			Name:        c.Name,
+			Currency:    c.Currency,
+			Credibility: c.Credibility,
+			Blocked:     h.countryGraph.IsBlocked(c.Code),
+			Halted:      !c.IsActive,
+		}
+		if country.Halted {
+			view.Halted = append(view.Halted, c.Code)
+		}
+		view.Countries = append(view.Countries, country)
+	}
+	return view
+}
+
+// HandleGraph handles GET /api/v1/graph. The response carries an ETag of its
+// content, so dashboards polling with If-None-Match get 304 until the graph changes.
+func (h *AdminHandler) HandleGraph(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphRead) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	body, err := json.Marshal(h.graphView())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to encode graph", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to encode graph")
+		return
+	}
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "private, no-cache") // Always revalidate
+	if etagMatches(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method == http.MethodHead {
+		return
+	}
+	w.Write(append(body, '\n'))
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison RFC 9110 requires for If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+func TestGraphViewRevalidatesWithETag(t *testing.T) {
+	graph := router.NewGraph()
+	graph.AddNode(&router.Node{ID: "lp_1", Type: "LiquidityProvider", IsActive: true})
+	graph.AddNode(&router.Node{ID: "hub_1", Type: "Hub", IsActive: true})
+	graph.AddEdge(&router.Edge{SourceID: "lp_1", TargetID: "hub_1", BaseFee: 0.001, Latency: 12, LiquidityVolume: 1000, IsActive: true})
+	graph.UpdateNodeEntropy("lp_1", map[string]float64{"hub_1": 1})
+
+	countries := router.NewCountryGraph()
+	countries.AddNode(&router.CountryNode{Code: "USA", Name: "United States", Currency: "USD", IsActive: true})
+	countries.AddNode(&router.CountryNode{Code: "DEU", Name: "Germany", Currency: "EUR", IsActive: false})
+	countries.SetBlocked([]string{"PRK"})
+
+	h := NewAdminHandler(graph, nil, nil)
+	h.SetCountryGraph(countries)
+	auditor := &auth.User{ID: "auditor-1", Username: "auditor", Role: auth.RoleAuditor}
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/graph", nil)
+		ctx := context.WithValue(req.Context(), middleware.UserContextKey, auditor)
+		req = req.WithContext(context.WithValue(ctx, middleware.PolicyContextKey, auth.DefaultPolicy()))
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleGraph(rec, req)
+		return rec
+	}
+
+	rec := get("")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var view GraphView
+	if err := json.NewDecoder(rec.Body).Decode(&view); err != nil {
+		t.Fatal(err)
+	}
+	if len(view.Nodes) != 2 || view.Nodes[1].ID != "lp_1" || view.Nodes[1].Entropy == nil || view.Nodes[0].Entropy != nil {
+		t.Errorf("nodes = %+v", view.Nodes)
+	}
+	if len(view.Edges) != 1 || view.Edges[0].AvailableLiquidity != 1000 {
+		t.Errorf("edges = %+v", view.Edges)
+	}
+	if !reflect.DeepEqual(view.Blocked, []string{"PRK"}) || !reflect.DeepEqual(view.Halted, []string{"DEU"}) {
+		t.Errorf("blocked = %v, halted = %v", view.Blocked, view.Halted)
+	}
+
+	etag := rec.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+	if rec := get(`"stale", W/` + etag); rec.Code != http.StatusNotModified || rec.Body.Len() != 0 {
+		t.Fatalf("unchanged graph: status %d, want 304", rec.Code)
+	}
+
+	graph.SetNodeInactive("hub_1")
+	rec = get(etag)
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") == etag {
+		t.Fatalf("changed graph: status %d, ETag %s", rec.Code, rec.Header().Get("ETag"))
+	}
+}
@@ -0,0 +1,1091 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	hub "github.com/plm/predictive-liquidity-mesh/websocket"
+)
+
+// GraphQL limits
+const (
+	graphQLMaxDepth          = 8
+	graphQLDefaultTxnLimit   = 50
+	graphQLMaxTxnLimit       = 200
+	graphQLQueryTimeout      = 10 * time.Second
+	graphQLInitTimeout       = 10 * time.Second
+	graphQLSubscriptionQueue = 64
+)
+
+var (
+	errGraphQLUnauthorized = errors.New("unauthorized")
+	errGraphQLForbidden    = errors.New("insufficient permissions")
+	errGraphQLTooDeep      = errors.New("query is nested too deeply")
+)
+
+// GraphQLHandler serves the mesh graph, country graph, transactions and routes
+// over GraphQL, with queries on /api/graphql and subscriptions on /ws/graphql.
+// Field names match the JSON of the REST API.
+type GraphQLHandler struct {
+	graph     *router.Graph
+	countries *router.CountryGraph
+	router    *router.CountryRouter
+	txnStore  payments.TransactionStorer
+	hub       *hub.Hub // nil disables the events subscription
+	auth      *middleware.AuthMiddleware
+	audit     audit.Store // nil disables auditing of admin transaction views
+	upgrader  websocket.Upgrader
+	schema    graphql.Schema
+
+	mu      sync.Mutex
+	txnSubs map[chan *TransactionEvent]string // Subscriber -> user ID ("" = every user)
+}
+
+// graphQLRequestKey holds the HTTP request an operation came in on (the
+// upgrade request for subscriptions), for audit entries
+type graphQLRequestKey struct{}
+
+// TransactionEvent is a transaction status change delivered to subscribers
+type TransactionEvent struct {
+	Event       payments.StatusEvent  `json:"event"`
+	Transaction *payments.Transaction `json:"transaction"`
+}
+
+// GraphQLRequest is a GraphQL operation, as POSTed to /api/graphql or sent
+// in a subscribe message
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// NewGraphQLHandler creates a GraphQL handler
+func NewGraphQLHandler(graph *router.Graph, countries *router.CountryGraph, txnStore payments.TransactionStorer, wsHub *hub.Hub) *GraphQLHandler {
+	h := &GraphQLHandler{
+		graph:     graph,
+		countries: countries,
+		router:    router.NewCountryRouter(countries, 3),
+		txnStore:  txnStore,
+		hub:       wsHub,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			Subprotocols:    []string{"graphql-transport-ws"},
+			CheckOrigin: func(r *http.Request) bool {
+				return middleware.IsOriginAllowed(r.Header.Get("Origin"), r.Host)
+			},
+		},
+		txnSubs: make(map[chan *TransactionEvent]string),
+	}
+	schema, err := h.buildSchema()
+	if err != nil {
+		panic("graphql schema: " + err.Error()) // The schema is static; this is a programming error
+	}
+	h.schema = schema
+	return h
+}
+
+// SetAuthMiddleware sets how WebSocket clients are authenticated. Without it
+// /ws/graphql refuses every connection.
+func (h *GraphQLHandler) SetAuthMiddleware(m *middleware.AuthMiddleware) {
+	h.auth = m
+}
+
+// SetAuditStore sets where admin views of other users' transactions are
+// recorded
+func (h *GraphQLHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// PublishTransaction delivers a transaction status change to the
+// transactionUpdated subscribers allowed to see it. Subscribers that fall
+// behind miss events rather than blocking the payment pipeline.
+func (h *GraphQLHandler) PublishTransaction(event payments.StatusEvent, txn *payments.Transaction) {
+	msg := &TransactionEvent{Event: event, Transaction: txn}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch, userID := range h.txnSubs {
+		if userID != "" && userID != txn.UserID {
+			continue
+		}
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// HandleGraphQL handles GET and POST /api/graphql. POST takes a JSON
+// {"query","operationName","variables"} body; GET takes the same as query
+// parameters, with variables JSON-encoded.
+func (h *GraphQLHandler) HandleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+	switch r.Method {
+	case http.MethodPost:
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if v := q.Get("variables"); v != "" {
+			if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+				apierror.Respond(w, http.StatusBadRequest, "invalid variables")
+				return
+			}
+		}
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	if req.Query == "" {
+		apierror.Respond(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), graphQLQueryTimeout)
+	defer cancel()
+
+	result := h.execute(context.WithValue(ctx, graphQLRequestKey{}, r), req)
+	w.Header().Set("Content-Type", "application/json")
+	if result.Data == nil && result.HasErrors() {
+		// The request could not be executed at all
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	json.NewEncoder(w).Encode(result)
+}
+
+// parse parses and validates a request, rejecting queries nested deeper than
+// graphQLMaxDepth
+func (h *GraphQLHandler) parse(req GraphQLRequest) (*ast.Document, []gqlerrors.FormattedError) {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return nil, gqlerrors.FormatErrors(err)
+	}
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		return nil, validation.Errors
+	}
+	// After validation, which rejects fragment cycles
+	if queryDepth(doc) > graphQLMaxDepth {
+		return nil, gqlerrors.FormatErrors(errGraphQLTooDeep)
+	}
+	return doc, nil
+}
+
+// execute runs a query
+func (h *GraphQLHandler) execute(ctx context.Context, req GraphQLRequest) *graphql.Result {
+	doc, errs := h.parse(req)
+	if errs != nil {
+		return &graphql.Result{Errors: errs}
+	}
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// subscribe starts a subscription. The first result carries the errors of a
+// subscription that could not start, after which the channel is closed.
+func (h *GraphQLHandler) subscribe(ctx context.Context, req GraphQLRequest) chan *graphql.Result {
+	doc, errs := h.parse(req)
+	if errs != nil {
+		results := make(chan *graphql.Result, 1)
+		results <- &graphql.Result{Errors: errs}
+		close(results)
+		return results
+	}
+	return graphql.ExecuteSubscription(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// queryDepth returns how deeply the operations of doc nest their selections
+func queryDepth(doc *ast.Document) int {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, def := range doc.Definitions {
+		if f, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[f.Name.Value] = f
+		}
+	}
+	var depth func(set *ast.SelectionSet) int
+	depth = func(set *ast.SelectionSet) int {
+		if set == nil {
+			return 0
+		}
+		deepest := 0
+		for _, sel := range set.Selections {
+			switch sel := sel.(type) {
+			case *ast.Field:
+				if sel.SelectionSet != nil {
+					deepest = max(deepest, 1+depth(sel.SelectionSet))
+				}
+			case *ast.InlineFragment:
+				deepest = max(deepest, depth(sel.SelectionSet))
+			case *ast.FragmentSpread:
+				if f, ok := fragments[sel.Name.Value]; ok {
+					deepest = max(deepest, depth(f.SelectionSet))
+				}
+			}
+		}
+		return deepest
+	}
+	deepest := 0
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			deepest = max(deepest, 1+depth(op.SelectionSet))
+		}
+	}
+	return deepest
+}
+
+// graphQLWSMessage is a graphql-transport-ws protocol message
+type graphQLWSMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// graphql-transport-ws close codes
+const (
+	wsCloseBadRequest   = 4400
+	wsCloseUnauthorized = 4401
+	wsCloseInitTimeout  = 4408
+	wsCloseDuplicateID  = 4409
+	wsCloseTooManyInits = 4429
+)
+
+// graphQLConn is a GraphQL WebSocket session
+type graphQLConn struct {
+	conn    *websocket.Conn
+	writeMu sync.Mutex
+}
+
+// send writes a protocol message; payload is marshalled unless nil
+func (c *graphQLConn) send(id, typ string, payload interface{}) error {
+	msg := graphQLWSMessage{ID: id, Type: typ}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		msg.Payload = data
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.conn.WriteJSON(msg)
+}
+
+// close ends the session with a protocol close code
+func (c *graphQLConn) close(code int, reason string) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(time.Second))
+}
+
+// HandleGraphQLWS handles /ws/graphql with the graphql-transport-ws protocol.
+// The token is taken from the upgrade request (see middleware.TokenFromRequest)
+// or from the connection_init payload as {"token":"..."}.
+func (h *GraphQLHandler) HandleGraphQLWS(w http.ResponseWriter, r *http.Request) {
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		slog.WarnContext(r.Context(), "graphql websocket upgrade failed", "error", err)
+		return
+	}
+	defer ws.Close()
+	conn := &graphQLConn{conn: ws}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx, err = h.initGraphQLWS(ctx, conn, middleware.TokenFromRequest(r))
+	if err != nil {
+		slog.WarnContext(r.Context(), "graphql websocket init failed", "error", err)
+		return
+	}
+	if err := conn.send("", "connection_ack", nil); err != nil {
+		return
+	}
+	ctx = context.WithValue(ctx, graphQLRequestKey{}, r)
+
+	var mu sync.Mutex
+	operations := make(map[string]context.CancelFunc)
+	stop := func(id string) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		cancelOp, ok := operations[id]
+		if ok {
+			cancelOp()
+			delete(operations, id)
+		}
+		return ok
+	}
+
+	for {
+		var msg graphQLWSMessage
+		if err := ws.ReadJSON(&msg); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				slog.WarnContext(ctx, "graphql websocket error", "error", err)
+			}
+			return
+		}
+
+		switch msg.Type {
+		case "ping":
+			conn.send("", "pong", nil)
+		case "pong":
+		case "connection_init":
+			conn.close(wsCloseTooManyInits, "Too many initialisation requests")
+			return
+		case "complete":
+			stop(msg.ID)
+		case "subscribe":
+			var req GraphQLRequest
+			if msg.ID == "" || json.Unmarshal(msg.Payload, &req) != nil {
+				conn.close(wsCloseBadRequest, "Invalid subscribe message")
+				return
+			}
+			mu.Lock()
+			if _, exists := operations[msg.ID]; exists {
+				mu.Unlock()
+				conn.close(wsCloseDuplicateID, "Subscriber for "+msg.ID+" already exists")
+				return
+			}
+			opCtx, cancelOp := context.WithCancel(ctx)
+			operations[msg.ID] = cancelOp
+			mu.Unlock()
+
+			go func(id string, results chan *graphql.Result) {
+				first := true
+				for result := range results {
+					if first && result.Data == nil && result.HasErrors() {
+						// The subscription could not start
+						stop(id)
+						conn.send(id, "error", result.Errors)
+						continue
+					}
+					first = false
+					if conn.send(id, "next", result) != nil {
+						stop(id)
+					}
+				}
+				if stop(id) {
+					// The stream ended on its own rather than by the client's complete
+					conn.send(id, "complete", nil)
+				}
+			}(msg.ID, h.subscribe(opCtx, req))
+		default:
+			conn.close(wsCloseBadRequest, "Unexpected message type "+msg.Type)
+			return
+		}
+	}
+}
+
+// initGraphQLWS waits for connection_init and authenticates the session
+func (h *GraphQLHandler) initGraphQLWS(ctx context.Context, conn *graphQLConn, token string) (context.Context, error) {
+	conn.conn.SetReadDeadline(time.Now().Add(graphQLInitTimeout))
+	defer conn.conn.SetReadDeadline(time.Time{})
+
+	var msg graphQLWSMessage
+	if err := conn.conn.ReadJSON(&msg); err != nil {
+		conn.close(wsCloseInitTimeout, "Connection initialisation timeout")
+		return ctx, err
+	}
+	if msg.Type != "connection_init" {
+		conn.close(wsCloseUnauthorized, "Unauthorized")
+		return ctx, errors.New("expected connection_init, got " + msg.Type)
+	}
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if len(msg.Payload) > 0 {
+		json.Unmarshal(msg.Payload, &payload)
+	}
+	if payload.Token != "" {
+		token = payload.Token
+	}
+	if h.auth == nil || token == "" {
+		conn.close(wsCloseUnauthorized, "Unauthorized")
+		return ctx, errGraphQLUnauthorized
+	}
+	ctx, err := h.auth.AuthenticateToken(ctx, token)
+	if err != nil {
+		conn.close(wsCloseUnauthorized, "Unauthorized")
+		return ctx, err
+	}
+	return ctx, nil
+}
+
+// graphQLJSON is a scalar served as its JSON encoding, for nested values
+// such as hop results and event data
+var graphQLJSON = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Any JSON value",
+	Serialize:   func(value interface{}) interface{} { return value },
+})
+
+// buildSchema defines the GraphQL types and their resolvers. Fields without a
+// resolver are read from the source by their JSON name.
+func (h *GraphQLHandler) buildSchema() (graphql.Schema, error) {
+	var meshNode, meshEdge, country, corridor *graphql.Object
+
+	meshNode = graphql.NewObject(graphql.ObjectConfig{Name: "MeshNode", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"id":        {Type: graphql.String},
+			"type":      {Type: graphql.String},
+			"region":    {Type: graphql.String},
+			"is_active": {Type: graphql.Boolean},
+			"props":     {Type: graphQLJSON},
+			"edges": {Type: graphql.NewList(meshEdge), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				n := p.Source.(*meshNodeView)
+				return n.mesh.edgesFrom(n.ID), nil
+			}},
+		}
+	})})
+	meshEdge = graphql.NewObject(graphql.ObjectConfig{Name: "MeshEdge", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"source_id":            {Type: graphql.String},
+			"target_id":            {Type: graphql.String},
+			"base_fee":             {Type: graphql.Float},
+			"latency_ms":           {Type: graphql.Int},
+			"effective_latency_ms": {Type: graphql.Int},
+			"liquidity_volume":     {Type: graphql.Float}, // May exceed a 32-bit Int
+			"available_liquidity":  {Type: graphql.Float},
+			"is_active":            {Type: graphql.Boolean},
+			"source": {Type: meshNode, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				e := p.Source.(*meshEdgeView)
+				return e.mesh.node(e.SourceID), nil
+			}},
+			"target": {Type: meshNode, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				e := p.Source.(*meshEdgeView)
+				return e.mesh.node(e.TargetID), nil
+			}},
+		}
+	})})
+
+	country = graphql.NewObject(graphql.ObjectConfig{Name: "Country", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"code":         {Type: graphql.String},
+			"name":         {Type: graphql.String},
+			"currency":     {Type: graphql.String},
+			"credibility":  {Type: graphql.Float},
+			"success_rate": {Type: graphql.Float},
+			"fx_rate":      {Type: graphql.Float},
+			"is_active":    {Type: graphql.Boolean},
+			"blocked":      {Type: graphql.Boolean},
+			"corridors": {Type: graphql.NewList(corridor), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				c := p.Source.(*countryView)
+				return c.countries.corridorsFrom(c.Code), nil
+			}},
+		}
+	})})
+	corridor = graphql.NewObject(graphql.ObjectConfig{Name: "Corridor", Fields: graphql.FieldsThunk(func() graphql.Fields {
+		return graphql.Fields{
+			"source_code": {Type: graphql.String},
+			"target_code": {Type: graphql.String},
+			"base_cost":   {Type: graphql.Float},
+			"latency_ms":  {Type: graphql.Int},
+			"is_active":   {Type: graphql.Boolean},
+			"source": {Type: country, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				c := p.Source.(*corridorView)
+				return c.countries.country(c.SourceCode), nil
+			}},
+			"target": {Type: country, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				c := p.Source.(*corridorView)
+				return c.countries.country(c.TargetCode), nil
+			}},
+		}
+	})})
+
+	routeCountries := func(codes []string) []*countryView {
+		countries := h.countryIndex()
+		out := make([]*countryView, 0, len(codes))
+		for _, code := range codes {
+			if c := countries.country(code); c != nil {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+	routePath := graphql.NewObject(graphql.ObjectConfig{Name: "RoutePath", Fields: graphql.Fields{
+		"rank":                    {Type: graphql.Int},
+		"nodes":                   {Type: graphql.NewList(graphql.String)},
+		"hop_count":               {Type: graphql.Int},
+		"total_weight":            {Type: graphql.Float},
+		"total_fee_percent":       {Type: graphql.Float},
+		"final_amount":            {Type: graphql.Float},
+		"calculated_fee":          {Type: graphql.Float},
+		"total_latency_ms":        {Type: graphql.Int},
+		"reliability":             {Type: graphql.Float},
+		"fee_delta_percent":       {Type: graphql.Float},
+		"latency_delta_ms":        {Type: graphql.Int},
+		"reliability_delta":       {Type: graphql.Float},
+		"estimated_completion_at": {Type: graphql.DateTime},
+		"estimated_duration_ms":   {Type: graphql.Int},
+		"wait_ms":                 {Type: graphql.Int},
+		"within_hours":            {Type: graphql.Boolean},
+		"countries": {Type: graphql.NewList(country), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return routeCountries(p.Source.(*RoutePathInfo).Nodes), nil
+		}},
+	}})
+
+	transaction := graphql.NewObject(graphql.ObjectConfig{Name: "Transaction", Fields: graphql.Fields{
+		"id":               {Type: graphql.String},
+		"user_id":          {Type: graphql.String},
+		"org_id":           {Type: graphql.String},
+		"amount":           {Type: graphql.Float},
+		"currency":         {Type: graphql.String},
+		"target_currency":  {Type: graphql.String},
+		"route":            {Type: graphql.NewList(graphql.String)},
+		"status":           {Type: graphql.String},
+		"base_fee":         {Type: graphql.Float},
+		"hop_fees":         {Type: graphql.Float},
+		"halt_fines":       {Type: graphql.Float},
+		"total_fees":       {Type: graphql.Float},
+		"final_amount":     {Type: graphql.Float},
+		"hop_results":      {Type: graphQLJSON},
+		"hops_completed":   {Type: graphql.Int},
+		"failed_at":        {Type: graphql.String},
+		"candidate_routes": {Type: graphQLJSON},
+		"attempts":         {Type: graphQLJSON},
+		"created_at":       {Type: graphql.DateTime},
+		"processed_at":     {Type: graphql.DateTime},
+		"completed_at":     {Type: graphql.DateTime},
+		"route_countries": {Type: graphql.NewList(country), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return routeCountries(p.Source.(*payments.Transaction).Route), nil
+		}},
+	}})
+	transactionEvent := graphql.NewObject(graphql.ObjectConfig{Name: "TransactionEvent", Fields: graphql.Fields{
+		"event":       {Type: graphql.String},
+		"transaction": {Type: transaction},
+	}})
+	event := graphql.NewObject(graphql.ObjectConfig{Name: "Event", Fields: graphql.Fields{
+		"type":      {Type: graphql.String},
+		"timestamp": {Type: graphql.Float}, // Unix milliseconds exceed a 32-bit Int
+		"data":      {Type: graphQLJSON},
+	}})
+
+	str := func(names ...string) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{}
+		for _, name := range names {
+			args[name] = &graphql.ArgumentConfig{Type: graphql.String}
+		}
+		return args
+	}
+	strList := graphql.NewList(graphql.String)
+	event0 := func(p graphql.ResolveParams) (interface{}, error) { return p.Source, nil }
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.Fields{
+			"meshNodes": {Type: graphql.NewList(meshNode), Resolve: h.resolveMeshNodes},
+			"meshNode":  {Type: meshNode, Args: str("id"), Resolve: h.resolveMeshNode},
+			"meshEdges": {Type: graphql.NewList(meshEdge), Resolve: h.resolveMeshEdges},
+			"countries": {Type: graphql.NewList(country), Resolve: h.resolveCountries},
+			"country":   {Type: country, Args: str("code"), Resolve: h.resolveCountry},
+			"transactions": {Type: graphql.NewList(transaction), Resolve: h.resolveTransactions, Args: graphql.FieldConfigArgument{
+				"status":  {Type: strList},
+				"limit":   {Type: graphql.Int},
+				"user_id": {Type: graphql.String},
+				"admin":   {Type: graphql.Boolean},
+			}},
+			"transaction": {Type: transaction, Resolve: h.resolveTransaction, Args: graphql.FieldConfigArgument{
+				"id":    {Type: graphql.String},
+				"admin": {Type: graphql.Boolean},
+			}},
+			"route": {Type: graphql.NewList(routePath), Resolve: h.resolveRoute, Args: graphql.FieldConfigArgument{
+				"source":        {Type: graphql.String},
+				"target":        {Type: graphql.String},
+				"blocked_codes": {Type: strList},
+				"preference":    {Type: graphql.String},
+				"amount":        {Type: graphql.Float},
+				"prefer_open":   {Type: graphql.Boolean},
+			}},
+		}}),
+		Subscription: graphql.NewObject(graphql.ObjectConfig{Name: "Subscription", Fields: graphql.Fields{
+			"transactionUpdated": {Type: transactionEvent, Subscribe: h.subscribeTransactions, Resolve: event0, Args: graphql.FieldConfigArgument{
+				"id":    {Type: graphql.String},
+				"admin": {Type: graphql.Boolean},
+			}},
+			"events": {Type: event, Subscribe: h.subscribeEvents, Resolve: event0, Args: graphql.FieldConfigArgument{
+				"types": {Type: strList},
+			}},
+		}}),
+	})
+}
+
+// Argument accessors; absent arguments are zero
+func argString(p graphql.ResolveParams, name string) string {
+	s, _ := p.Args[name].(string)
+	return s
+}
+
+func argStrings(p graphql.ResolveParams, name string) []string {
+	list, _ := p.Args[name].([]interface{})
+	out := make([]string, 0, len(list))
+	for _, v := range list {
+		if s, ok := v.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func argBool(p graphql.ResolveParams, name string) bool {
+	b, _ := p.Args[name].(bool)
+	return b
+}
+
+func argFloat(p graphql.ResolveParams, name string) float64 {
+	switch v := p.Args[name].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	}
+	return 0
+}
+
+// requireUser returns the authenticated user, and checks perm unless it is empty
+func requireUser(ctx context.Context, perm auth.Permission) (*auth.User, error) {
+	user := middleware.GetUserFromContext(ctx)
+	if user == nil {
+		return nil, errGraphQLUnauthorized
+	}
+	if perm != "" && !middleware.Can(ctx, perm) {
+		return nil, errGraphQLForbidden
+	}
+	return user, nil
+}
+
+// adminView reports whether an operation asked for other users' transactions
+// with admin: true, which needs payments:read, as ?admin=true does on
+// GET /api/v1/transactions/{id}
+func adminView(p graphql.ResolveParams) (bool, error) {
+	if !argBool(p, "admin") {
+		return false, nil
+	}
+	if !middleware.Can(p.Context, auth.PermPaymentsRead) {
+		return false, errGraphQLForbidden
+	}
+	return true, nil
+}
+
+// auditTransactionView records an admin viewing another user's transaction
+func (h *GraphQLHandler) auditTransactionView(ctx context.Context, txnID string) {
+	r, ok := ctx.Value(graphQLRequestKey{}).(*http.Request)
+	if !ok {
+		return
+	}
+	recordAudit(h.audit, r.WithContext(ctx), http.StatusOK, "transaction.view", "transaction", txnID, nil, nil)
+}
+
+// meshNodeView is a mesh node as served over GraphQL
+type meshNodeView struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Region   string                 `json:"region"`
+	IsActive bool                   `json:"is_active"`
+	Props    map[string]interface{} `json:"props"`
+
+	mesh *meshIndex
+}
+
+// meshEdgeView is a mesh edge as served over GraphQL
+type meshEdgeView struct {
+	SourceID           string  `json:"source_id"`
+	TargetID           string  `json:"target_id"`
+	BaseFee            float64 `json:"base_fee"`
+	LatencyMs          int64   `json:"latency_ms"`
+	EffectiveLatencyMs int64   `json:"effective_latency_ms"` // Including injected degradation
+	LiquidityVolume    int64   `json:"liquidity_volume"`
+	AvailableLiquidity int64   `json:"available_liquidity"`
+	IsActive           bool    `json:"is_active"`
+
+	mesh *meshIndex
+}
+
+// meshIndex is a consistent copy of the mesh for resolving one request
+type meshIndex struct {
+	nodes []*meshNodeView
+	edges []*meshEdgeView
+}
+
+// meshIndex copies the mesh graph
+func (h *GraphQLHandler) meshIndex() *meshIndex {
+	export := h.graph.Export()
+	idx := &meshIndex{
+		nodes: make([]*meshNodeView, len(export.Nodes)),
+		edges: make([]*meshEdgeView, len(export.Edges)),
+	}
+	for i, n := range export.Nodes {
+		idx.nodes[i] = &meshNodeView{ID: n.ID, Type: n.Type, Region: n.Region, IsActive: n.IsActive, Props: n.Props, mesh: idx}
+	}
+	for i, e := range export.Edges {
+		idx.edges[i] = &meshEdgeView{
+			SourceID:           e.SourceID,
+			TargetID:           e.TargetID,
+			BaseFee:            e.BaseFee,
+			LatencyMs:          e.Latency,
+			EffectiveLatencyMs: e.EffectiveLatency(),
+			LiquidityVolume:    e.LiquidityVolume,
+			AvailableLiquidity: e.Available(),
+			IsActive:           e.IsActive,
+			mesh:               idx,
+		}
+	}
+	return idx
+}
+
+// node returns the node with id, or nil
+func (m *meshIndex) node(id string) *meshNodeView {
+	i, found := sort.Find(len(m.nodes), func(i int) int { return strings.Compare(id, m.nodes[i].ID) })
+	if !found {
+		return nil
+	}
+	return m.nodes[i]
+}
+
+// edgesFrom returns the outgoing edges of a node
+func (m *meshIndex) edgesFrom(id string) []*meshEdgeView {
+	var out []*meshEdgeView
+	for _, e := range m.edges {
+		if e.SourceID == id {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// countryView is a country as served over GraphQL
+type countryView struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Currency    string  `json:"currency"`
+	Credibility float64 `json:"credibility"`
+	SuccessRate float64 `json:"success_rate"`
+	FXRate      float64 `json:"fx_rate"`
+	IsActive    bool    `json:"is_active"`
+	Blocked     bool    `json:"blocked"` // Excluded from routing
+
+	countries *countryIndex
+}
+
+// corridorView is a corridor from one country, as served over GraphQL
+type corridorView struct {
+	SourceCode string  `json:"source_code"`
+	TargetCode string  `json:"target_code"`
+	BaseCost   float64 `json:"base_cost"`
+	LatencyMs  int64   `json:"latency_ms"`
+	IsActive   bool    `json:"is_active"`
+
+	countries *countryIndex
+}
+
+// countryIndex is a consistent copy of the country graph for resolving one request
+type countryIndex struct {
+	countries []*countryView
+	byCode    map[string]*countryView
+	corridors map[string][]*corridorView
+}
+
+// countryIndex copies the country graph
+func (h *GraphQLHandler) countryIndex() *countryIndex {
+	export := h.countries.Export()
+	idx := &countryIndex{
+		countries: make([]*countryView, len(export.Nodes)),
+		byCode:    make(map[string]*countryView, len(export.Nodes)),
+		corridors: make(map[string][]*corridorView),
+	}
+	for i, n := range export.Nodes {
+		c := &countryView{
+			Code:        n.Code,
+			Name:        n.Name,
+			Currency:    n.Currency,
+			Credibility: n.Credibility,
+			SuccessRate: n.SuccessRate,
+			FXRate:      n.FXRate,
+			IsActive:    n.IsActive,
+			Blocked:     h.countries.IsBlocked(n.Code),
+			countries:   idx,
+		}
+		idx.countries[i] = c
+		idx.byCode[n.Code] = c
+	}
+	// Export lists each corridor once; it is served from both ends
+	for _, e := range export.Edges {
+		for _, ends := range [][2]string{{e.SourceCode, e.TargetCode}, {e.TargetCode, e.SourceCode}} {
+			idx.corridors[ends[0]] = append(idx.corridors[ends[0]], &corridorView{
+				SourceCode: ends[0],
+				TargetCode: ends[1],
+				BaseCost:   e.BaseCost,
+				LatencyMs:  e.LatencyMs,
+				IsActive:   e.IsActive,
+				countries:  idx,
+			})
+		}
+	}
+	for _, corridors := range idx.corridors {
+		sort.Slice(corridors, func(i, j int) bool { return corridors[i].TargetCode < corridors[j].TargetCode })
+	}
+	return idx
+}
+
+// country returns the country with code, or nil
+func (c *countryIndex) country(code string) *countryView {
+	return c.byCode[code]
+}
+
+// corridorsFrom returns the corridors of a country, sorted by destination
+func (c *countryIndex) corridorsFrom(code string) []*corridorView {
+	return c.corridors[code]
+}
+
+func (h *GraphQLHandler) resolveMeshNodes(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireUser(p.Context, auth.PermGraphRead); err != nil {
+		return nil, err
+	}
+	return h.meshIndex().nodes, nil
+}
+
+func (h *GraphQLHandler) resolveMeshNode(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireUser(p.Context, auth.PermGraphRead); err != nil {
+		return nil, err
+	}
+	return h.meshIndex().node(argString(p, "id")), nil
+}
+
+func (h *GraphQLHandler) resolveMeshEdges(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireUser(p.Context, auth.PermGraphRead); err != nil {
+		return nil, err
+	}
+	return h.meshIndex().edges, nil
+}
+
+func (h *GraphQLHandler) resolveCountries(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireUser(p.Context, ""); err != nil {
+		return nil, err
+	}
+	return h.countryIndex().countries, nil
+}
+
+func (h *GraphQLHandler) resolveCountry(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireUser(p.Context, ""); err != nil {
+		return nil, err
+	}
+	return h.countryIndex().country(argString(p, "code")), nil
+}
+
+// resolveTransactions returns the caller's transactions, newest first. With
+// admin: true it lists every user's, or those of user_id, auditing each other
+// user's transaction returned.
+func (h *GraphQLHandler) resolveTransactions(p graphql.ResolveParams) (interface{}, error) {
+	user, err := requireUser(p.Context, "")
+	if err != nil {
+		return nil, err
+	}
+	admin, err := adminView(p)
+	if err != nil {
+		return nil, err
+	}
+	limit := graphQLDefaultTxnLimit
+	if l, ok := p.Args["limit"].(int); ok {
+		limit = l
+	}
+	if limit < 1 {
+		return nil, errors.New("limit must be a positive integer")
+	}
+	limit = min(limit, graphQLMaxTxnLimit)
+
+	var statuses []payments.TransactionStatus
+	for _, s := range argStrings(p, "status") {
+		statuses = append(statuses, payments.TransactionStatus(s))
+	}
+
+	userID := argString(p, "user_id")
+	if userID != "" && userID != user.ID && !admin {
+		return nil, errGraphQLForbidden
+	}
+	if userID == "" && !admin {
+		userID = user.ID
+	}
+
+	var txns []*payments.Transaction
+	if userID != "" {
+		page, err := h.txnStore.QueryUserTransactions(userID, payments.HistoryQuery{Limit: limit, Statuses: statuses, Descending: true})
+		if err != nil {
+			return nil, err
+		}
+		txns = page.Transactions
+	} else {
+		for _, txn := range h.txnStore.GetAllTransactions() {
+			if len(statuses) == 0 || slices.Contains(statuses, txn.Status) {
+				txns = append(txns, txn)
+			}
+		}
+		sort.Slice(txns, func(i, j int) bool { return txns[i].CreatedAt.After(txns[j].CreatedAt) })
+		if len(txns) > limit {
+			txns = txns[:limit]
+		}
+	}
+	for _, txn := range txns {
+		if txn.UserID != user.ID {
+			h.auditTransactionView(p.Context, txn.ID)
+		}
+	}
+	return txns, nil
+}
+
+// resolveTransaction returns one transaction; another user's is reported as
+// missing unless asked for with admin: true, and that view is audited
+func (h *GraphQLHandler) resolveTransaction(p graphql.ResolveParams) (interface{}, error) {
+	user, err := requireUser(p.Context, "")
+	if err != nil {
+		return nil, err
+	}
+	admin, err := adminView(p)
+	if err != nil {
+		return nil, err
+	}
+	txn, err := h.txnStore.GetTransaction(argString(p, "id"))
+	if err != nil || (txn.UserID != user.ID && !admin) {
+		return nil, nil
+	}
+	if txn.UserID != user.ID {
+		h.auditTransactionView(p.Context, txn.ID)
+	}
+	return txn, nil
+}
+
+// resolveRoute ranks the routes between two countries, as POST /api/v1/route does
+func (h *GraphQLHandler) resolveRoute(p graphql.ResolveParams) (interface{}, error) {
+	if _, err := requireUser(p.Context, ""); err != nil {
+		return nil, err
+	}
+	source, target := argString(p, "source"), argString(p, "target")
+	if source == "" || target == "" {
+		return nil, errors.New("source and target are required")
+	}
+	pref, err := router.ParsePreference(argString(p, "preference"))
+	if err != nil {
+		return nil, err
+	}
+	paths, err := h.router.WithPreference(pref).
+		WithConstraints(router.RouteConstraints{PreferOpen: argBool(p, "prefer_open")}).
+		FindKShortestPaths(p.Context, source, target, argStrings(p, "blocked_codes"))
+	if err != nil {
+		return nil, err
+	}
+	return buildRoutePaths(paths, argFloat(p, "amount")), nil
+}
+
+// subscribeTransactions streams status changes of the caller's transactions
+// (every user's with admin: true), optionally only those of one transaction.
+// Each other user's transaction delivered is audited as a view.
+func (h *GraphQLHandler) subscribeTransactions(p graphql.ResolveParams) (interface{}, error) {
+	user, err := requireUser(p.Context, "")
+	if err != nil {
+		return nil, err
+	}
+	admin, err := adminView(p)
+	if err != nil {
+		return nil, err
+	}
+	userID := user.ID
+	if admin {
+		userID = ""
+	}
+	txnID := argString(p, "id")
+
+	events := make(chan *TransactionEvent, graphQLSubscriptionQueue)
+	h.mu.Lock()
+	h.txnSubs[events] = userID
+	h.mu.Unlock()
+
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		defer func() {
+			h.mu.Lock()
+			delete(h.txnSubs, events)
+			h.mu.Unlock()
+		}()
+		for {
+			select {
+			case <-p.Context.Done():
+				return
+			case ev := <-events:
+				if txnID != "" && ev.Transaction.ID != txnID {
+					continue
+				}
+				if ev.Transaction.UserID != user.ID {
+					h.auditTransactionView(p.Context, ev.Transaction.ID)
+				}
+				select {
+				case out <- ev:
+				case <-p.Context.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// subscribeEvents streams the dashboard WebSocket broadcasts the caller may
+// receive, optionally only those of the given types
+func (h *GraphQLHandler) subscribeEvents(p graphql.ResolveParams) (interface{}, error) {
+	user, err := requireUser(p.Context, "")
+	if err != nil {
+		return nil, err
+	}
+	if h.hub == nil {
+		return nil, errors.New("events are not available")
+	}
+	types := argStrings(p, "types")
+
+	messages := h.hub.Listen(p.Context, graphQLSubscriptionQueue)
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for msg := range messages {
+			if !msg.VisibleTo(user) || (len(types) > 0 && !slices.Contains(types, string(msg.Type))) {
+				continue
+			}
+			select {
+			case out <- msg:
+			case <-p.Context.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func newTestGraphQLHandler(t *testing.T) (*GraphQLHandler, *payments.TransactionStore) {
+	t.Helper()
+	mesh := router.NewGraph()
+	mesh.AddNode(&router.Node{ID: "hub-eu", Type: "Hub", Region: "EU", IsActive: true})
+	mesh.AddNode(&router.Node{ID: "sme-1", Type: "SME", Region: "EU", IsActive: true})
+	mesh.AddEdge(&router.Edge{SourceID: "sme-1", TargetID: "hub-eu", BaseFee: 0.001, Latency: 20, IsActive: true})
+
+	store := payments.NewTransactionStore()
+	return NewGraphQLHandler(mesh, router.BuildCountryGraphWithDefaults(), store, nil), store
+}
+
+// graphQLResult is a response with the data as encoded, keys sorted
+type graphQLResult struct {
+	Data   json.RawMessage            `json:"data"`
+	Errors []gqlerrors.FormattedError `json:"errors"`
+}
+
+func graphQLQuery(h *GraphQLHandler, user *auth.User, query string) (int, graphQLResult) {
+	body, _ := json.Marshal(GraphQLRequest{Query: query})
+	req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(string(body)))
+	if user != nil {
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+	}
+	rec := httptest.NewRecorder()
+	h.HandleGraphQL(rec, req)
+	var resp graphQLResult
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	return rec.Code, resp
+}
+
+func TestGraphQLNestedQueries(t *testing.T) {
+	h, _ := newTestGraphQLHandler(t)
+	admin := &auth.User{ID: "admin-1", Role: auth.RoleAdmin}
+
+	code, resp := graphQLQuery(h, admin, `{
+		country(code: "AUT") { name corridors { target_code target { name } } }
+		meshNode(id: "sme-1") { type edges { target { id region } } }
+		route(source: "USA", target: "AUT") { rank countries { code } }
+	}`)
+	if code != http.StatusOK || resp.Errors != nil {
+		t.Fatalf("expected 200 without errors, got %d: %v", code, resp.Errors)
+	}
+	data := resp.Data
+	for _, want := range []string{
+		`"country":{"corridors":[{"target":{"name":"Switzerland"},"target_code":"CHE"}`,
+		`"name":"Austria"}`,
+		`"meshNode":{"edges":[{"target":{"id":"hub-eu","region":"EU"}}],"type":"SME"}`,
+		`"route":[{"countries":[{"code":"USA"}`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("response %s\nmissing %s", data, want)
+		}
+	}
+
+	// The mesh needs graph:read; the rest of the query still runs
+	_, resp = graphQLQuery(h, &auth.User{ID: "user-1", Role: auth.RoleUser}, `{ meshNodes { id } countries { code } }`)
+	if !strings.HasPrefix(string(resp.Data), `{"countries":[`) || !strings.HasSuffix(string(resp.Data), `],"meshNodes":null}`) ||
+		!strings.Contains(string(resp.Data), `{"code":"AUT"}`) ||
+		len(resp.Errors) != 1 || resp.Errors[0].Message != "insufficient permissions" {
+		t.Errorf("unexpected response %s %v", resp.Data, resp.Errors)
+	}
+
+	if code, _ := graphQLQuery(h, admin, `{ country(code: "AUT") { capital } }`); code != http.StatusBadRequest {
+		t.Errorf("expected 400 for an invalid query, got %d", code)
+	}
+	deep := `{ country(code: "AUT") { corridors { target { corridors { target { corridors { target { corridors { target { name } } } } } } } } }`
+	if code, resp := graphQLQuery(h, admin, deep); code != http.StatusBadRequest || len(resp.Errors) != 1 {
+		t.Errorf("expected 400 for a query nested too deeply, got %d: %v", code, resp.Errors)
+	}
+}
+
+func TestGraphQLTransactionsAreScopedToTheCaller(t *testing.T) {
+	h, store := newTestGraphQLHandler(t)
+	mine, err := store.CreateTransaction("user-1", 100, "USD", "EUR", []string{"USA", "DEU"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	theirs, _ := store.CreateTransaction("user-2", 200, "USD", "EUR", []string{"USA", "DEU"}, nil)
+	user := &auth.User{ID: "user-1", Role: auth.RoleUser}
+
+	_, resp := graphQLQuery(h, user, `{ transactions { id route_countries { code } } other: transaction(id: "`+theirs.ID+`") { id } }`)
+	data := resp.Data
+	want := `{"other":null,"transactions":[{"id":"` + mine.ID + `","route_countries":[{"code":"USA"},{"code":"DEU"}]}]}`
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+
+	admin := &auth.User{ID: "admin-1", Role: auth.RoleAdmin}
+	_, resp = graphQLQuery(h, admin, `{ transactions { user_id } other: transaction(id: "`+theirs.ID+`") { id } }`)
+	if want := `{"other":null,"transactions":[]}`; string(resp.Data) != want {
+		t.Errorf("expected an admin to see only their own transactions without admin: true, got %s", resp.Data)
+	}
+	_, resp = graphQLQuery(h, admin, `{ transactions(admin: true) { user_id } }`)
+	if n := strings.Count(string(resp.Data), "user_id"); n != 2 {
+		t.Errorf("expected an admin to see both transactions with admin: true, got %s", resp.Data)
+	}
+
+	if _, resp := graphQLQuery(h, nil, `{ transactions { id } }`); resp.Errors == nil {
+		t.Error("expected an error without a user")
+	}
+}
+
+func TestGraphQLAdminTransactionViewIsAudited(t *testing.T) {
+	h, store := newTestGraphQLHandler(t)
+	trail := audit.NewMemoryStore(10)
+	h.SetAuditStore(trail)
+	theirs, _ := store.CreateTransaction("user-2", 200, "USD", "EUR", []string{"USA", "DEU"}, nil)
+	query := `{ transaction(id: "` + theirs.ID + `", admin: true) { id } }`
+
+	_, resp := graphQLQuery(h, &auth.User{ID: "user-1", Role: auth.RoleUser}, query)
+	if resp.Errors == nil || string(resp.Data) != `{"transaction":null}` {
+		t.Errorf("expected a non-owner without payments:read to be refused, got %s %v", resp.Data, resp.Errors)
+	}
+	_, resp = graphQLQuery(h, &auth.User{ID: "user-1", Role: auth.RoleUser}, `{ transactions(user_id: "user-2") { id } }`)
+	if resp.Errors == nil {
+		t.Errorf("expected listing another user's transactions to be refused, got %s", resp.Data)
+	}
+
+	_, resp = graphQLQuery(h, &auth.User{ID: "admin-1", Role: auth.RoleAdmin}, query)
+	if want := `{"transaction":{"id":"` + theirs.ID + `"}}`; string(resp.Data) != want {
+		t.Fatalf("got  %s\nwant %s", resp.Data, want)
+	}
+	entries, err := trail.List(context.Background(), audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one audit entry, got %d", len(entries))
+	}
+	if e := entries[0]; e.Action != "transaction.view" || e.ResourceID != theirs.ID || e.ActorID != "admin-1" {
+		t.Errorf("unexpected audit entry %+v", e)
+	}
+}
+
+func TestGraphQLTransactionSubscription(t *testing.T) {
+	h, store := newTestGraphQLHandler(t)
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), middleware.UserContextKey, &auth.User{ID: "user-1", Role: auth.RoleUser}))
+	defer cancel()
+
+	stream := h.subscribe(ctx, GraphQLRequest{Query: `subscription { transactionUpdated { event transaction { id } } }`})
+	// The subscription starts in the background
+	for subscribed := false; !subscribed; time.Sleep(time.Millisecond) {
+		h.mu.Lock()
+		subscribed = len(h.txnSubs) == 1
+		h.mu.Unlock()
+	}
+	theirs, _ := store.CreateTransaction("user-2", 200, "USD", "EUR", []string{"USA", "DEU"}, nil)
+	mine, _ := store.CreateTransaction("user-1", 100, "USD", "EUR", []string{"USA", "DEU"}, nil)
+	h.PublishTransaction(payments.EventPaymentSucceeded, theirs)
+	h.PublishTransaction(payments.EventPaymentSucceeded, mine)
+
+	select {
+	case resp := <-stream:
+		if resp.HasErrors() {
+			t.Fatalf("subscription failed: %v", resp.Errors)
+		}
+		data, _ := json.Marshal(resp.Data)
+		if want := `{"transactionUpdated":{"event":"payment.succeeded","transaction":{"id":"` + mine.ID + `"}}}`; string(data) != want {
+			t.Errorf("got  %s\nwant %s", data, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no event for the caller's transaction")
+	}
+}
@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+)
+
+// ErrNotConnected is reported for a dependency the server failed to connect to
+var ErrNotConnected = errors.New("not connected")
+
+// HealthCheck returns an error if a dependency is unreachable
+type HealthCheck func(ctx context.Context) error
+
+// dependency is an external service checked for readiness
+type dependency struct {
+	name     string
+	required bool
+	check    HealthCheck
+}
+
+// DependencyStatus is the result of checking one dependency
+type DependencyStatus struct {
+	Status    string  `json:"status"` // "up" or "down"
+	Required  bool    `json:"required"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// ReadinessResponse is the body of /readyz
+type ReadinessResponse struct {
+	Status       string                      `json:"status"` // "ready" or "not_ready"
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+	CheckedAt    time.Time                   `json:"checked_at"`
+}
+
+// HealthHandler serves liveness and readiness probes
+type HealthHandler struct {
+	timeout time.Duration
+	started time.Time
+
+	mu   sync.RWMutex
+	deps []dependency
+}
+
+// NewHealthHandler creates a health handler; timeout bounds each dependency check
+func NewHealthHandler(timeout time.Duration) *HealthHandler {
+	return &HealthHandler{timeout: timeout, started: time.Now()}
+}
+
+// AddDependency registers a dependency checked by /readyz. The server is not
+// ready while a required dependency is down; optional ones are only reported.
+func (h *HealthHandler) AddDependency(name string, required bool, check HealthCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.deps = append(h.deps, dependency{name: name, required: required, check: check})
+}
+
+// HandleLiveness handles GET /healthz: 200 while the process is serving
+func (h *HealthHandler) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":         "ok",
+		"uptime_seconds": int64(time.Since(h.started).Seconds()),
+	})
+}
+
+// HandleReadiness handles GET /readyz: checks every dependency concurrently
+// and returns 503 until the required ones are reachable
+func (h *HealthHandler) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	resp := h.Check(r.Context())
+	code := http.StatusOK
+	if resp.Status != "ready" {
+		code = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Check runs the dependency checks
+func (h *HealthHandler) Check(ctx context.Context) *ReadinessResponse {
+	h.mu.RLock()
+	deps := append([]dependency(nil), h.deps...)
+	h.mu.RUnlock()
+
+	statuses := make([]DependencyStatus, len(deps))
+	var wg sync.WaitGroup
+	for i, dep := range deps {
+		wg.Add(1)
+		go func(i int, dep dependency) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, h.timeout)
+			defer cancel()
+
+			start := time.Now()
+			err := dep.check(checkCtx)
+			status := DependencyStatus{
+				Status:    "up",
+				Required:  dep.required,
+				LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				status.Status = "down"
+				status.Error = err.Error()
+			}
+			statuses[i] = status
+		}(i, dep)
+	}
+	wg.Wait()
+
+	resp := &ReadinessResponse{
+		Status:       "ready",
+		Dependencies: make(map[string]DependencyStatus, len(deps)),
+		CheckedAt:    time.Now().UTC(),
+	}
+	for i, dep := range deps {
+		resp.Dependencies[dep.name] = statuses[i]
+		if dep.required && statuses[i].Status != "up" {
+			resp.Status = "not_ready"
+		}
+	}
+	return resp
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadinessWaitsForRequiredDependencies(t *testing.T) {
+	h := NewHealthHandler(50 * time.Millisecond)
+	postgresUp := false
+	h.AddDependency("postgres", true, func(ctx context.Context) error {
+		if !postgresUp {
+			return errors.New("connection refused")
+		}
+		return nil
+	})
+	h.AddDependency("nats", false, func(ctx context.Context) error { return ErrNotConnected })
+	h.AddDependency("neo4j", true, func(ctx context.Context) error {
+		<-ctx.Done() // Hangs until the check times out
+		return ctx.Err()
+	})
+
+	ready := func() (int, ReadinessResponse) {
+		rec := httptest.NewRecorder()
+		h.HandleReadiness(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var resp ReadinessResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("bad body %q: %v", rec.Body, err)
+		}
+		return rec.Code, resp
+	}
+
+	code, resp := ready()
+	if code != http.StatusServiceUnavailable || resp.Status != "not_ready" {
+		t.Fatalf("expected 503 not_ready, got %d %s", code, resp.Status)
+	}
+	if pg := resp.Dependencies["postgres"]; pg.Status != "down" || !pg.Required || pg.Error != "connection refused" {
+		t.Errorf("unexpected postgres status: %+v", pg)
+	}
+	if neo := resp.Dependencies["neo4j"]; neo.Status != "down" || neo.LatencyMs < 50 {
+		t.Errorf("hanging check should time out after the check timeout: %+v", neo)
+	}
+
+	// Optional dependencies are reported but don't hold readiness back
+	h = NewHealthHandler(time.Second)
+	h.AddDependency("postgres", true, func(ctx context.Context) error { return nil })
+	h.AddDependency("nats", false, func(ctx context.Context) error { return ErrNotConnected })
+	code, resp = ready()
+	if code != http.StatusOK || resp.Status != "ready" {
+		t.Fatalf("expected 200 ready, got %d %s", code, resp.Status)
+	}
+	if n := resp.Dependencies["nats"]; n.Status != "down" || n.Required || n.Error != "not connected" {
+		t.Errorf("unexpected nats status: %+v", n)
+	}
+}
+
+func TestLivenessIgnoresDependencies(t *testing.T) {
+	h := NewHealthHandler(time.Second)
+	h.AddDependency("postgres", true, func(ctx context.Context) error { return ErrNotConnected })
+
+	rec := httptest.NewRecorder()
+	h.HandleLiveness(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.HandleLiveness(rec, httptest.NewRequest(http.MethodPost, "/healthz", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for POST, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+// SubmitKYCRequest is the body of POST /api/v1/kyc
+type SubmitKYCRequest struct {
+	Documents []users.KYCDocument `json:"documents"`
+}
+
+// ReviewKYCRequest is the body of POST /api/v1/admin/kyc/{id}/{approve|reject}
+type ReviewKYCRequest struct {
+	Note string `json:"note"`
+}
+
+// KYCRecord is a user's verification state as seen by admins
+type KYCRecord struct {
+	UserID   string     `json:"user_id"`
+	Email    string     `json:"email"`
+	Username string     `json:"username"`
+	KYC      *users.KYC `json:"kyc"`
+}
+
+// KYCListResponse is returned by GET /api/v1/admin/kyc
+type KYCListResponse struct {
+	Users []*KYCRecord `json:"users"` // Longest waiting first
+	Count int          `json:"count"`
+}
+
+// KYCHandler lets users submit identity documents and admins review them
+type KYCHandler struct {
+	store users.Storer
+	audit audit.Store
+}
+
+// NewKYCHandler creates a new KYC handler
+func NewKYCHandler(store users.Storer) *KYCHandler {
+	return &KYCHandler{store: store}
+}
+
+// SetAuditStore records KYC submissions and decisions in store
+func (h *KYCHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// HandleMyKYC handles the caller's identity verification:
+//
+//	GET  /api/v1/kyc - status, documents and any rejection note
+//	POST /api/v1/kyc - submit document metadata for review
+func (h *KYCHandler) HandleMyKYC(w http.ResponseWriter, r *http.Request) {
+	caller := middleware.GetUserFromContext(r.Context())
+	if caller == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+	userID := caller.ID
+
+	switch r.Method {
+	case http.MethodGet:
+		user, err := h.store.GetByID(userID)
+		if err != nil {
+			apierror.Respond(w, http.StatusNotFound, "user not found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user.KYC)
+	case http.MethodPost:
+		h.handleSubmit(w, r, userID)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *KYCHandler) handleSubmit(w http.ResponseWriter, r *http.Request, userID string) {
+	var req SubmitKYCRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	kyc, err := h.store.SubmitKYC(userID, req.Documents)
+	switch {
+	case errors.Is(err, users.ErrInvalidDocument):
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, users.ErrKYCAlreadyVerified):
+		apierror.Respond(w, http.StatusConflict, "identity is already verified")
+		return
+	case errors.Is(err, users.ErrUserNotFound):
+		apierror.Respond(w, http.StatusNotFound, "user not found")
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "failed to submit KYC documents", "user_id", userID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to submit documents")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "KYC documents submitted", "user_id", userID, "documents", len(req.Documents))
+	recordAudit(h.audit, r, http.StatusAccepted, "user.kyc_submit", "user", userID, nil, req.Documents)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(kyc)
+}
+
+// HandleAdminKYC handles the KYC review API:
+//
+//	GET  /api/v1/admin/kyc?status=pending   - users by KYC status (default pending)
+//	GET  /api/v1/admin/kyc/{id}             - one user's KYC record
+//	POST /api/v1/admin/kyc/{id}/approve     - mark the identity verified
+//	POST /api/v1/admin/kyc/{id}/reject      - reject the submission (note required)
+func (h *KYCHandler) HandleAdminKYC(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/kyc"), "/")
+	if path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	userID, action, _ := strings.Cut(path, "/")
+	if action == "" {
+		if r.Method != http.MethodGet {
+			apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		user, err := h.store.GetByID(userID)
+		if err != nil {
+			apierror.Respond(w, http.StatusNotFound, "user not found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(kycRecord(user))
+		return
+	}
+
+	var decision users.KYCStatus
+	switch action {
+	case "approve":
+		decision = users.KYCVerified
+	case "reject":
+		decision = users.KYCRejected
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	h.handleReview(w, r, userID, action, decision)
+}
+
+func (h *KYCHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	status := users.KYCPending
+	if v := r.URL.Query().Get("status"); v != "" {
+		status = users.KYCStatus(strings.ToLower(v))
+	}
+
+	records := make([]*KYCRecord, 0)
+	for _, u := range h.store.ListUsers() {
+		if u.KYCStatus != string(status) {
+			continue
+		}
+		user, err := h.store.GetByID(u.ID)
+		if err != nil {
+			slog.WarnContext(r.Context(), "failed to load KYC record", "user_id", u.ID, "error", err)
+			continue
+		}
+		records = append(records, kycRecord(user))
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := submittedAt(records[i]), submittedAt(records[j])
+		if a.Equal(b) {
+			return records[i].UserID < records[j].UserID
+		}
+		return a.Before(b)
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(KYCListResponse{Users: records, Count: len(records)})
+}
+
+func (h *KYCHandler) handleReview(w http.ResponseWriter, r *http.Request, userID, action string, decision users.KYCStatus) {
+	var req ReviewKYCRequest
+	if r.ContentLength != 0 {
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if decision == users.KYCRejected && strings.TrimSpace(req.Note) == "" {
+		apierror.Respond(w, http.StatusBadRequest, "a note is required to reject a submission")
+		return
+	}
+
+	before, err := h.store.GetByID(userID)
+	if err != nil {
+		apierror.Respond(w, http.StatusNotFound, "user not found")
+		return
+	}
+	reviewer := actorName(r)
+	kyc, err := h.store.ReviewKYC(userID, decision, reviewer, req.Note)
+	switch {
+	case errors.Is(err, users.ErrKYCNotPending):
+		apierror.Respond(w, http.StatusConflict, "no KYC submission is awaiting review")
+		return
+	case errors.Is(err, users.ErrUserNotFound):
+		apierror.Respond(w, http.StatusNotFound, "user not found")
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "failed to review KYC submission", "user_id", userID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to review submission")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "KYC submission reviewed", "user_id", userID, "status", kyc.Status, "reviewer", reviewer)
+	recordAudit(h.audit, r, http.StatusOK, "user.kyc_"+action, "user", userID, before.KYC, kyc)
+
+	w.Header().Set("Content-Type", "application/json")
+	record := kycRecord(before)
+	record.KYC = kyc
+	json.NewEncoder(w).Encode(record)
+}
+
+func kycRecord(user *users.StoredUser) *KYCRecord {
+	kyc := user.KYC
+	return &KYCRecord{UserID: user.ID, Email: user.Email, Username: user.Username, KYC: &kyc}
+}
+
+// submittedAt returns when the record's documents were submitted (zero if never)
+func submittedAt(record *KYCRecord) time.Time {
+	if record.KYC.SubmittedAt == nil {
+		return time.Time{}
+	}
+	return *record.KYC.SubmittedAt
+}
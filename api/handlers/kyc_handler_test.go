@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+func TestKYCSubmitAndReview(t *testing.T) {
+	store := users.NewStore()
+	created, err := store.CreateUser("erin@example.com", "Password123!", "erin", auth.RoleUser)
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	erin := created.ToUser()
+	admin, _ := store.GetByEmail("admin@plm.local")
+
+	h := NewKYCHandler(store)
+	trail := audit.NewMemoryStore(10)
+	h.SetAuditStore(trail)
+
+	do := func(handler http.HandlerFunc, caller *auth.User, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, caller))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := do(h.HandleMyKYC, erin, http.MethodPost, "/api/v1/kyc", `{"documents":[{"type":"selfie","reference":"s3://kyc/1"}]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown document type: status %d, want 400", rec.Code)
+	}
+	rec = do(h.HandleMyKYC, erin, http.MethodPost, "/api/v1/kyc", `{"documents":[{"type":"Passport","reference":"s3://kyc/1","file_name":"passport.jpg","size":20480}]}`)
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("submit: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(h.HandleAdminKYC, admin.ToUser(), http.MethodGet, "/api/v1/admin/kyc", "")
+	var queue KYCListResponse
+	json.NewDecoder(rec.Body).Decode(&queue)
+	if queue.Count != 1 || queue.Users[0].UserID != erin.ID || queue.Users[0].KYC.Documents[0].Type != "passport" {
+		t.Fatalf("review queue = %+v, want erin's passport", queue)
+	}
+
+	rec = do(h.HandleAdminKYC, admin.ToUser(), http.MethodPost, "/api/v1/admin/kyc/"+erin.ID+"/reject", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("reject without a note: status %d, want 400", rec.Code)
+	}
+	rec = do(h.HandleAdminKYC, admin.ToUser(), http.MethodPost, "/api/v1/admin/kyc/"+erin.ID+"/approve", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("approve: status %d: %s", rec.Code, rec.Body)
+	}
+	if verified, _ := store.KYCVerified(erin.ID); !verified {
+		t.Fatal("erin should be verified after approval")
+	}
+	rec = do(h.HandleAdminKYC, admin.ToUser(), http.MethodPost, "/api/v1/admin/kyc/"+erin.ID+"/approve", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("approving twice: status %d, want 409", rec.Code)
+	}
+	rec = do(h.HandleMyKYC, erin, http.MethodPost, "/api/v1/kyc", `{"documents":[{"type":"national_id","reference":"s3://kyc/2"}]}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("submitting once verified: status %d, want 409", rec.Code)
+	}
+
+	entries, _ := trail.List(context.Background(), audit.Filter{Action: "user.kyc_approve"})
+	if len(entries) != 1 || entries[0].ResourceID != erin.ID || entries[0].ActorEmail != "admin@plm.local" {
+		t.Fatalf("audit entries = %+v, want one approval of erin by admin", entries)
+	}
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/storage/postgres"
+	"github.com/plm/predictive-liquidity-mesh/workers/ledgeraudit"
+)
+
+// Limits for the admin ledger listing
+const (
+	defaultLedgerLimit = 50
+	maxLedgerLimit     = 500
+)
+
+// LedgerHandler serves the hash-chained settlement ledger and its audits to admins
+type LedgerHandler struct {
+	client  *postgres.Client
+	auditor *ledgeraudit.Auditor
+}
+
+// NewLedgerHandler creates a new ledger handler
+func NewLedgerHandler(client *postgres.Client, auditor *ledgeraudit.Auditor) *LedgerHandler {
+	return &LedgerHandler{client: client, auditor: auditor}
+}
+
+// LedgerResponse is the most recent ledger entries with the chain's integrity
+type LedgerResponse struct {
+	Entries   []postgres.LedgerEntry `json:"entries"`
+	Integrity *ledgeraudit.Result    `json:"integrity"`
+}
+
+// HandleLedger handles GET /api/v1/admin/ledger
+// Query params: limit (most recent entries, default 50, max 500)
+func (h *LedgerHandler) HandleLedger(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	limit := parsePositiveInt(r.URL.Query().Get("limit"), defaultLedgerLimit)
+	if limit > maxLedgerLimit {
+		limit = maxLedgerLimit
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	entries, err := h.client.GetLatestLedgerEntries(ctx, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to read ledger", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to read ledger")
+		return
+	}
+	integrity := h.auditor.Run(ctx, false)
+	if integrity.Error != "" {
+		apierror.Respond(w, http.StatusInternalServerError, "failed to verify ledger")
+		return
+	}
+	if entries == nil {
+		entries = []postgres.LedgerEntry{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LedgerResponse{
+		Entries:   entries,
+		Integrity: integrity,
+	})
+}
+
+// HandleVerify handles GET /api/v1/admin/ledger/verify.
+// Verifies the whole hash chain and returns any broken segments.
+// Query params: alert=true (broadcast a WebSocket alert if the chain is broken),
+// cached=true (return the last scheduled or manual audit without verifying)
+func (h *LedgerHandler) HandleVerify(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	if q.Get("cached") == "true" {
+		last := h.auditor.LastResult()
+		if last == nil {
+			apierror.Respond(w, http.StatusNotFound, "no ledger audit has run yet")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(last)
+		return
+	}
+
+	result := h.auditor.Run(r.Context(), q.Get("alert") == "true")
+	if result.Error != "" {
+		apierror.Respond(w, http.StatusInternalServerError, "failed to verify ledger")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/limits"
+)
+
+// LimitListResponse is returned by GET /api/v1/admin/limits
+type LimitListResponse struct {
+	Defaults limits.Limits  `json:"defaults"` // Server config fallback, used when no default rule is stored
+	Rules    []*limits.Rule `json:"rules"`
+}
+
+// LimitHandler lets admins configure spending limits and users check their remaining quota
+type LimitHandler struct {
+	engine *limits.Engine
+	store  limits.Store
+	audit  audit.Store
+}
+
+// NewLimitHandler creates a new limit handler
+func NewLimitHandler(engine *limits.Engine, store limits.Store) *LimitHandler {
+	return &LimitHandler{engine: engine, store: store}
+}
+
+// SetAuditStore records limit changes in store
+func (h *LimitHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// HandleQuota handles GET /api/v1/payments/limits: the caller's limits and
+// how much they can still transfer today and this month
+func (h *LimitHandler) HandleQuota(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	quota, err := h.engine.Quota(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to compute spending quota", "user_id", userID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to load spending limits")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(quota)
+}
+
+// HandleAdminLimits handles the limit admin API:
+//
+//	GET    /api/v1/admin/limits                     - list rules
+//	GET    /api/v1/admin/limits/default             - default rule for users
+//	GET    /api/v1/admin/limits/{user|org}/{id}     - one subject's rule
+//	PUT    (same paths)                             - create or replace a rule
+//	DELETE (same paths)                             - remove a rule
+func (h *LimitHandler) HandleAdminLimits(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/limits"), "/")
+	if path == "" {
+		h.handleList(w, r)
+		return
+	}
+
+	scope, subjectID, _ := strings.Cut(path, "/")
+	key := &limits.Rule{Scope: limits.Scope(scope), SubjectID: subjectID}
+	if err := key.Validate(); err != nil {
+		apierror.Respond(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		rule, err := h.store.Get(r.Context(), key.Scope, key.SubjectID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to read spending limit", "scope", key.Scope, "subject_id", key.SubjectID, "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to read spending limit")
+			return
+		}
+		if rule == nil {
+			apierror.Respond(w, http.StatusNotFound, "no limits configured")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rule)
+	case http.MethodPut:
+		h.handleSet(w, r, key)
+	case http.MethodDelete:
+		h.handleDelete(w, r, key)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *LimitHandler) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	rules, err := h.store.List(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to list spending limits", "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to list spending limits")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(LimitListResponse{Defaults: h.engine.Defaults(), Rules: rules})
+}
+
+func (h *LimitHandler) handleSet(w http.ResponseWriter, r *http.Request, key *limits.Rule) {
+	var req limits.Limits
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := req.Validate(); err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	before, err := h.store.Get(r.Context(), key.Scope, key.SubjectID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to read spending limit", "scope", key.Scope, "subject_id", key.SubjectID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to save spending limit")
+		return
+	}
+
+	rule := &limits.Rule{Scope: key.Scope, SubjectID: key.SubjectID, Limits: req, UpdatedBy: actorName(r)}
+	if err := h.store.Set(r.Context(), rule); err != nil {
+		if errors.Is(err, limits.ErrInvalidLimits) {
+			apierror.Respond(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "failed to save spending limit", "scope", key.Scope, "subject_id", key.SubjectID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to save spending limit")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "spending limit updated", "admin", rule.UpdatedBy, "scope", rule.Scope,
+		"subject_id", rule.SubjectID, "per_transaction", rule.PerTransaction, "daily", rule.Daily, "monthly", rule.Monthly)
+	recordAudit(h.audit, r, http.StatusOK, "limit.update", "spending_limit", limitResourceID(key), before, rule)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *LimitHandler) handleDelete(w http.ResponseWriter, r *http.Request, key *limits.Rule) {
+	before, err := h.store.Get(r.Context(), key.Scope, key.SubjectID)
+	if err == nil && before == nil {
+		apierror.Respond(w, http.StatusNotFound, "no limits configured")
+		return
+	}
+	if err == nil {
+		err = h.store.Delete(r.Context(), key.Scope, key.SubjectID)
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to delete spending limit", "scope", key.Scope, "subject_id", key.SubjectID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to delete spending limit")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "spending limit removed", "admin", actorName(r), "scope", key.Scope, "subject_id", key.SubjectID)
+	recordAudit(h.audit, r, http.StatusNoContent, "limit.delete", "spending_limit", limitResourceID(key), before, nil)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// limitResourceID identifies a rule in the audit trail ("user/usr_123", "default")
+func limitResourceID(key *limits.Rule) string {
+	if key.SubjectID == "" {
+		return string(key.Scope)
+	}
+	return string(key.Scope) + "/" + key.SubjectID
+}
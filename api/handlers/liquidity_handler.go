@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/engine/analytics"
+)
+
+// LiquidityHandler serves edge utilization and rebalancing recommendations
+type LiquidityHandler struct {
+	advisor *analytics.Advisor
+}
+
+// NewLiquidityHandler creates a new liquidity handler
+func NewLiquidityHandler(advisor *analytics.Advisor) *LiquidityHandler {
+	return &LiquidityHandler{advisor: advisor}
+}
+
+// HandleRecommendations handles GET /api/v1/admin/liquidity/recommendations:
+// utilization of every liquidity-tracking edge and suggested transfers for
+// those over the threshold
+func (h *LiquidityHandler) HandleRecommendations(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.advisor.Report())
+}
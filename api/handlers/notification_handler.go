@@ -0,0 +1,82 @@
+// Package handlers provides notification preference endpoints
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/notifications"
+)
+
+// NotificationHandler serves the caller's notification preferences
+type NotificationHandler struct {
+	prefs notifications.PreferenceStore
+}
+
+// NewNotificationHandler creates a new notification handler
+func NewNotificationHandler(prefs notifications.PreferenceStore) *NotificationHandler {
+	return &NotificationHandler{prefs: prefs}
+}
+
+// UpdatePreferencesRequest changes notification preferences; omitted fields are left unchanged
+type UpdatePreferencesRequest struct {
+	Email            *bool `json:"email,omitempty"`
+	PaymentSucceeded *bool `json:"payment_succeeded,omitempty"`
+	PaymentRefunded  *bool `json:"payment_refunded,omitempty"`
+	AttachReceipts   *bool `json:"attach_receipts,omitempty"`
+}
+
+// HandlePreferences handles GET and PUT /api/v1/notifications/preferences
+func (h *NotificationHandler) HandlePreferences(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	prefs, err := h.prefs.Get(r.Context(), user.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to load notification preferences", "user_id", user.ID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to load preferences")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPatch:
+		var req UpdatePreferencesRequest
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		for _, field := range []struct {
+			value *bool
+			dst   *bool
+		}{
+			{req.Email, &prefs.Email},
+			{req.PaymentSucceeded, &prefs.PaymentSucceeded},
+			{req.PaymentRefunded, &prefs.PaymentRefunded},
+			{req.AttachReceipts, &prefs.AttachReceipts},
+		} {
+			if field.value != nil {
+				*field.dst = *field.value
+			}
+		}
+		if err := h.prefs.Save(r.Context(), prefs); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save notification preferences", "user_id", user.ID, "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to save preferences")
+			return
+		}
+		slog.InfoContext(r.Context(), "notification preferences updated", "user_id", user.ID)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
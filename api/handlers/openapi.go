@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/pkg/openapi"
+)
+
+// APIVersion is the version of the public REST API in the OpenAPI document
+const APIVersion = "1.0.0"
+
+// idempotencyKeyParam is the header that makes payment creation safe to retry
+var idempotencyKeyParam = &openapi.Parameter{
+	Name:        "Idempotency-Key",
+	In:          "header",
+	Description: "Retries with the same key return the original response instead of creating another payment",
+	Schema:      &openapi.Schema{Type: "string"},
+}
+
+// queryParam describes a query parameter
+func queryParam(name, typ, description string, required bool) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description, Required: required, Schema: &openapi.Schema{Type: typ}}
+}
+
+// APIEndpoints are the public payments and routing operations, described by
+// the request and response types their handlers decode and encode
+func APIEndpoints() []openapi.Endpoint {
+	return []openapi.Endpoint{
+		{
+			Method: http.MethodPost, Path: "/api/v1/auth/login", ID: "login", Tag: "auth",
+			Summary: "Exchange credentials for a bearer token",
+			Request: LoginRequest{}, Response: LoginResponse{}, Errors: []int{400, 401, 429},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/route", ID: "findRoutes", Tag: "routing", Auth: true,
+			Summary:     "Rank the routes between two countries",
+			Description: "An unreachable target is reported with success false rather than an error status.",
+			Request:     RouteRequest{}, Response: RouteResponse{}, Errors: []int{400, 429},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/route/simulate", ID: "simulateRoute", Tag: "routing", Auth: true,
+			Summary: "Compare the current routes with those under hypothetical conditions",
+			Request: RouteSimulationRequest{}, Response: RouteSimulationResponse{}, Errors: []int{400, 429},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/payments/quote", ID: "createQuote", Tag: "payments", Auth: true,
+			Summary: "Quote a payment, locking its exchange rates until the quote expires",
+			Request: QuoteRequest{}, Response: QuoteResponse{}, Errors: []int{400, 403, 429},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/payments/create", ID: "createPayment", Tag: "payments", Auth: true,
+			Summary: "Create a pending payment",
+			Params:  []*openapi.Parameter{idempotencyKeyParam},
+			Request: CreatePaymentRequest{}, Response: CreatePaymentResponse{}, Errors: []int{400, 403, 422, 429, 503},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/payments/confirm", ID: "confirmPayment", Tag: "payments", Auth: true,
+			Summary: "Pay for a pending payment and settle it through the mesh",
+			Request: ConfirmPaymentRequest{}, Response: ConfirmPaymentResponse{}, Errors: []int{400, 404, 429, 503},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/payments/batch", ID: "createBatch", Tag: "payments", Auth: true,
+			Summary: "Create and settle several payments at once",
+			Params:  []*openapi.Parameter{idempotencyKeyParam},
+			Request: CreateBatchRequest{}, Response: CreateBatchResponse{}, Errors: []int{400, 403, 422, 429, 503},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/payments/batch/{id}", ID: "getBatch", Tag: "payments", Auth: true,
+			Summary:  "Get the state of a batch",
+			Params:   []*openapi.Parameter{{Name: "id", In: "path", Required: true, Schema: &openapi.Schema{Type: "string"}}},
+			Response: BatchResponse{}, Errors: []int{404, 429},
+		},
+		{
+			Method: http.MethodPost, Path: "/api/v1/payments/cancel", ID: "cancelPayment", Tag: "payments", Auth: true,
+			Summary: "Cancel a payment waiting to be netted and refund it under the refund policy",
+			Request: CancelPaymentRequest{}, Response: CancelPaymentResponse{}, Errors: []int{400, 404, 409, 429, 502},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/payments/transaction", ID: "getTransaction", Tag: "payments", Auth: true,
+			Summary: "Get one of the caller's transactions",
+			Params: []*openapi.Parameter{
+				queryParam("id", "string", "Transaction ID", true),
+				queryParam("admin", "boolean", "Fetch any user's transaction (requires payments:read; audited)", false),
+			},
+			Response: payments.Transaction{}, Errors: []int{400, 401, 403, 404, 429},
+		},
+		{
+			Method: http.MethodGet, Path: "/api/v1/payments/history", ID: "getHistory", Tag: "payments", Auth: true,
+			Summary: "List the caller's transactions, newest first by default",
+			Params: []*openapi.Parameter{
+				queryParam("limit", "integer", "Page size (default 50, at most 200)", false),
+				queryParam("offset", "integer", "Transactions to skip", false),
+				queryParam("from", "string", "Created at or after (RFC 3339 or YYYY-MM-DD)", false),
+				queryParam("to", "string", "Created before, inclusive for dates (RFC 3339 or YYYY-MM-DD)", false),
+				queryParam("status", "string", "Comma-separated statuses", false),
+				queryParam("sort", "string", "created_at, amount or final_amount", false),
+				queryParam("order", "string", "asc or desc", false),
+			},
+			Response: HistoryResponse{}, Errors: []int{400, 429},
+		},
+	}
+}
+
+// OpenAPIHandler serves the OpenAPI document of the public API
+type OpenAPIHandler struct {
+	once sync.Once
+	doc  []byte
+}
+
+// NewOpenAPIHandler creates an OpenAPI handler
+func NewOpenAPIHandler() *OpenAPIHandler {
+	return &OpenAPIHandler{}
+}
+
+// Document builds the OpenAPI document from APIEndpoints
+func (h *OpenAPIHandler) Document() *openapi.Document {
+	doc := openapi.New("Predictive Liquidity Mesh API", APIVersion,
+		"Cross-border payments routed through the liquidity mesh. Errors carry a stable code, a message and the request ID.")
+	doc.SetErrorBody(apierror.ErrorBody{})
+	for _, e := range APIEndpoints() {
+		doc.Add(e)
+	}
+	return doc
+}
+
+// HandleOpenAPI handles GET /api/v1/openapi.json
+func (h *OpenAPIHandler) HandleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	// The document only depends on the types, so it is built once
+	h.once.Do(func() {
+		h.doc, _ = json.MarshalIndent(h.Document(), "", "  ")
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.doc)
+}
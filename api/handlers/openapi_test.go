@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAPIDocumentDescribesHandlerTypes(t *testing.T) {
+	h := NewOpenAPIHandler()
+	rec := httptest.NewRecorder()
+	h.HandleOpenAPI(rec, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+
+	var doc struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]interface{} `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	for _, e := range APIEndpoints() {
+		if doc.Paths[e.Path][map[string]string{http.MethodGet: "get", http.MethodPost: "post"}[e.Method]] == nil {
+			t.Errorf("missing %s %s", e.Method, e.Path)
+		}
+	}
+
+	// Properties follow the JSON tags, including embedded structs
+	for schema, field := range map[string]string{
+		"CreatePaymentRequest": "target_currency",
+		"RouteRequest":         "max_hops",
+		"QuoteResponse":        "quote_id",
+		"HistoryResponse":      "has_more",
+	} {
+		if _, ok := doc.Components.Schemas[schema].Properties[field]; !ok {
+			t.Errorf("%s is missing %s", schema, field)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleOpenAPI(rec, httptest.NewRequest(http.MethodPost, "/api/v1/openapi.json", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405, got %d", rec.Code)
+	}
+}
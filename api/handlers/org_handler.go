@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/organizations"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+// OrgDirectory looks up the users added to organizations.
+// Implemented by users.Store and users.PostgresStore.
+type OrgDirectory interface {
+	GetByEmail(email string) (users.UserWithToUser, error)
+	GetByID(id string) (*users.StoredUser, error)
+}
+
+// OrgTransactions reads an organization's transactions.
+// Implemented by the transaction stores.
+type OrgTransactions interface {
+	QueryOrgTransactions(orgID string, q payments.HistoryQuery) (*payments.HistoryPage, error)
+	OrgVolume(orgID string, since time.Time) float64
+}
+
+// OrgHandler serves organization management for platform staff
+// (/api/v1/admin/orgs) and for organization members (/api/v1/org)
+type OrgHandler struct {
+	orgs      organizations.Store
+	directory OrgDirectory
+	txns      OrgTransactions
+	audit     audit.Store
+}
+
+// NewOrgHandler creates a new organization handler
+func NewOrgHandler(orgs organizations.Store, directory OrgDirectory, txns OrgTransactions) *OrgHandler {
+	return &OrgHandler{orgs: orgs, directory: directory, txns: txns}
+}
+
+// SetAuditStore records organization and membership changes in store
+func (h *OrgHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// OrgSummary is an organization with its spending this calendar month
+type OrgSummary struct {
+	*organizations.Organization
+	MonthSpend     float64  `json:"month_spend"`
+	MonthRemaining *float64 `json:"month_remaining,omitempty"` // Omitted when unlimited
+}
+
+// OrgDetail is an organization with its members
+type OrgDetail struct {
+	OrgSummary
+	Role    organizations.Role `json:"role,omitempty"` // Caller's role (GET /api/v1/org)
+	Members []OrgMember        `json:"members,omitempty"`
+}
+
+// OrgMember is a membership with the member's account details
+type OrgMember struct {
+	*organizations.Member
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+}
+
+// CreateOrgRequest creates an organization, optionally with its first admin
+type CreateOrgRequest struct {
+	Name              string              `json:"name"`
+	FeeOverride       *payments.FeeConfig `json:"fee_override,omitempty"`
+	MonthlySpendLimit float64             `json:"monthly_spend_limit"`
+	AdminEmail        string              `json:"admin_email,omitempty"`
+}
+
+// UpdateOrgRequest changes an organization's settings. Omitted fields keep
+// their value; "fee_override": null removes the override.
+type UpdateOrgRequest struct {
+	Name              *string         `json:"name,omitempty"`
+	FeeOverride       json.RawMessage `json:"fee_override,omitempty"`
+	MonthlySpendLimit *float64        `json:"monthly_spend_limit,omitempty"`
+}
+
+// AddMemberRequest adds a user to an organization, or changes their role
+type AddMemberRequest struct {
+	Email string             `json:"email"`
+	Role  organizations.Role `json:"role"`
+}
+
+// HandleAdminOrgs handles the staff organization endpoints:
+//
+//	GET/POST   /api/v1/admin/orgs
+//	GET/PATCH  /api/v1/admin/orgs/{id}
+//	GET/POST   /api/v1/admin/orgs/{id}/members
+//	DELETE     /api/v1/admin/orgs/{id}/members/{user_id}
+//	GET        /api/v1/admin/orgs/{id}/transactions
+func (h *OrgHandler) HandleAdminOrgs(w http.ResponseWriter, r *http.Request) {
+	parts := splitOrgPath(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/orgs"))
+	switch {
+	case len(parts) == 0 && r.Method == http.MethodGet:
+		h.listOrgs(w, r)
+	case len(parts) == 0 && r.Method == http.MethodPost:
+		h.createOrg(w, r)
+	case len(parts) == 0:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		h.getOrg(w, r, parts[0], "")
+	case len(parts) == 1 && r.Method == http.MethodPatch:
+		h.updateOrg(w, r, parts[0])
+	case len(parts) == 1:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		h.serveOrgResource(w, r, parts[0], parts[1:])
+	}
+}
+
+// HandleMyOrg handles the caller's own organization:
+//
+//	GET        /api/v1/org
+//	GET/POST   /api/v1/org/members              (org admins)
+//	DELETE     /api/v1/org/members/{user_id}    (org admins)
+//	GET        /api/v1/org/transactions         (org admins)
+func (h *OrgHandler) HandleMyOrg(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	membership, err := h.orgs.MembershipOf(r.Context(), user.ID)
+	if err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	if membership == nil {
+		apierror.Respond(w, http.StatusNotFound, "you are not a member of an organization")
+		return
+	}
+
+	parts := splitOrgPath(strings.TrimPrefix(r.URL.Path, "/api/v1/org"))
+	if len(parts) == 0 {
+		if r.Method != http.MethodGet {
+			apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		h.getOrg(w, r, membership.OrgID, membership.Role)
+		return
+	}
+	if membership.Role != organizations.RoleAdmin {
+		apierror.Respond(w, http.StatusForbidden, "organization admin access required")
+		return
+	}
+	h.serveOrgResource(w, r, membership.OrgID, parts)
+}
+
+// serveOrgResource serves the members and transactions of an organization
+func (h *OrgHandler) serveOrgResource(w http.ResponseWriter, r *http.Request, orgID string, parts []string) {
+	switch {
+	case len(parts) == 1 && parts[0] == "members" && r.Method == http.MethodGet:
+		members, err := h.members(r, orgID)
+		if err != nil {
+			writeOrgError(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"members": members, "count": len(members)})
+	case len(parts) == 1 && parts[0] == "members" && r.Method == http.MethodPost:
+		h.addMember(w, r, orgID)
+	case len(parts) == 2 && parts[0] == "members" && r.Method == http.MethodDelete:
+		h.removeMember(w, r, orgID, parts[1])
+	case len(parts) == 1 && parts[0] == "transactions" && r.Method == http.MethodGet:
+		h.listTransactions(w, r, orgID)
+	case len(parts) <= 2 && (parts[0] == "members" || parts[0] == "transactions"):
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+	}
+}
+
+func (h *OrgHandler) listOrgs(w http.ResponseWriter, r *http.Request) {
+	orgs, err := h.orgs.List(r.Context())
+	if err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	summaries := make([]OrgSummary, 0, len(orgs))
+	for _, org := range orgs {
+		summaries = append(summaries, h.summarize(org))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"organizations": summaries, "count": len(summaries)})
+}
+
+func (h *OrgHandler) getOrg(w http.ResponseWriter, r *http.Request, orgID string, callerRole organizations.Role) {
+	org, err := h.orgs.Get(r.Context(), orgID)
+	if err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	detail := OrgDetail{OrgSummary: h.summarize(org), Role: callerRole}
+	if callerRole == "" || callerRole == organizations.RoleAdmin {
+		if detail.Members, err = h.members(r, orgID); err != nil {
+			writeOrgError(w, r, err)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(detail)
+}
+
+func (h *OrgHandler) createOrg(w http.ResponseWriter, r *http.Request) {
+	var req CreateOrgRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	org := &organizations.Organization{
+		Name:              strings.TrimSpace(req.Name),
+		FeeOverride:       req.FeeOverride,
+		MonthlySpendLimit: req.MonthlySpendLimit,
+	}
+	if err := org.Validate(); err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var admin *users.StoredUser
+	if req.AdminEmail != "" {
+		var ok bool
+		if admin, ok = h.lookupMember(w, r, req.AdminEmail); !ok {
+			return
+		}
+	}
+
+	if err := h.orgs.Create(r.Context(), org); err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	if admin != nil {
+		m := &organizations.Member{OrgID: org.ID, UserID: admin.ID, Role: organizations.RoleAdmin}
+		if err := h.orgs.AddMember(r.Context(), m); err != nil {
+			writeOrgError(w, r, err)
+			return
+		}
+	}
+
+	slog.InfoContext(r.Context(), "organization created", "org_id", org.ID, "name", org.Name, "actor", actorName(r))
+	recordAudit(h.audit, r, http.StatusCreated, "org.create", "organization", org.ID, nil, org)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(org)
+}
+
+func (h *OrgHandler) updateOrg(w http.ResponseWriter, r *http.Request, orgID string) {
+	var req UpdateOrgRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	before, err := h.orgs.Get(r.Context(), orgID)
+	if err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	org := *before
+	if req.Name != nil {
+		org.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.MonthlySpendLimit != nil {
+		org.MonthlySpendLimit = *req.MonthlySpendLimit
+	}
+	if req.FeeOverride != nil {
+		org.FeeOverride = nil
+		if !bytes.Equal(req.FeeOverride, []byte("null")) {
+			if err := json.Unmarshal(req.FeeOverride, &org.FeeOverride); err != nil {
+				apierror.Respond(w, http.StatusBadRequest, "invalid fee_override")
+				return
+			}
+		}
+	}
+	if err := org.Validate(); err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.orgs.Update(r.Context(), &org); err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "organization updated", "org_id", org.ID, "actor", actorName(r))
+	recordAudit(h.audit, r, http.StatusOK, "org.update", "organization", org.ID, before, org)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.summarize(&org))
+}
+
+func (h *OrgHandler) addMember(w http.ResponseWriter, r *http.Request, orgID string) {
+	var req AddMemberRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Role == "" {
+		req.Role = organizations.RoleMember
+	}
+	req.Role = organizations.Role(strings.ToLower(string(req.Role)))
+	if !req.Role.Valid() {
+		apierror.Respond(w, http.StatusBadRequest, organizations.ErrInvalidRole.Error())
+		return
+	}
+
+	user, ok := h.lookupMember(w, r, req.Email)
+	if !ok {
+		return
+	}
+
+	before, err := h.orgs.MembershipOf(r.Context(), user.ID)
+	if err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	m := &organizations.Member{OrgID: orgID, UserID: user.ID, Role: req.Role}
+	if err := h.orgs.AddMember(r.Context(), m); err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "organization member added", "org_id", orgID, "user_id", user.ID, "role", m.Role, "actor", actorName(r))
+	recordAudit(h.audit, r, http.StatusOK, "org.member_add", "organization", orgID, before, m)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(OrgMember{Member: m, Email: user.Email, Username: user.Username})
+}
+
+func (h *OrgHandler) removeMember(w http.ResponseWriter, r *http.Request, orgID, userID string) {
+	before, err := h.orgs.MembershipOf(r.Context(), userID)
+	if err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	if err := h.orgs.RemoveMember(r.Context(), orgID, userID); err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "organization member removed", "org_id", orgID, "user_id", userID, "actor", actorName(r))
+	recordAudit(h.audit, r, http.StatusOK, "org.member_remove", "organization", orgID, before, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "member removed"})
+}
+
+func (h *OrgHandler) listTransactions(w http.ResponseWriter, r *http.Request, orgID string) {
+	if _, err := h.orgs.Get(r.Context(), orgID); err != nil {
+		writeOrgError(w, r, err)
+		return
+	}
+	query, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page, err := h.txns.QueryOrgTransactions(orgID, query)
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeHistoryPage(w, page)
+}
+
+// lookupMember finds an active, non-staff account by email, writing an error if there is none
+func (h *OrgHandler) lookupMember(w http.ResponseWriter, r *http.Request, email string) (*users.StoredUser, bool) {
+	email = strings.TrimSpace(email)
+	if email == "" {
+		apierror.Respond(w, http.StatusBadRequest, "email is required")
+		return nil, false
+	}
+	found, err := h.directory.GetByEmail(email)
+	if err != nil {
+		apierror.Respond(w, http.StatusNotFound, "user not found")
+		return nil, false
+	}
+	user, err := h.directory.GetByID(found.ToUser().ID)
+	if err != nil || !user.IsActive {
+		apierror.Respond(w, http.StatusNotFound, "user not found")
+		return nil, false
+	}
+	if user.Role.IsStaff() {
+		apierror.Respond(w, http.StatusBadRequest, "staff accounts cannot join organizations")
+		return nil, false
+	}
+	return user, true
+}
+
+// members returns orgID's members with their account details
+func (h *OrgHandler) members(r *http.Request, orgID string) ([]OrgMember, error) {
+	members, err := h.orgs.Members(r.Context(), orgID)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]OrgMember, 0, len(members))
+	for _, m := range members {
+		member := OrgMember{Member: m}
+		if user, err := h.directory.GetByID(m.UserID); err == nil {
+			member.Email, member.Username = user.Email, user.Username
+		}
+		result = append(result, member)
+	}
+	return result, nil
+}
+
+// summarize adds the organization's spending this month
+func (h *OrgHandler) summarize(org *organizations.Organization) OrgSummary {
+	summary := OrgSummary{Organization: org, MonthSpend: h.txns.OrgVolume(org.ID, payments.MonthStart(time.Now()))}
+	if org.MonthlySpendLimit > 0 {
+		remaining := math.Max(org.MonthlySpendLimit-summary.MonthSpend, 0)
+		summary.MonthRemaining = &remaining
+	}
+	return summary
+}
+
+// writeOrgError maps organization store errors to HTTP responses
+func writeOrgError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, organizations.ErrNotFound), errors.Is(err, organizations.ErrNotMember):
+		apierror.Respond(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, organizations.ErrAlreadyMember), errors.Is(err, organizations.ErrDuplicateName),
+		errors.Is(err, organizations.ErrLastAdminLeave):
+		apierror.Respond(w, http.StatusConflict, err.Error())
+	case errors.Is(err, organizations.ErrInvalidRole), errors.Is(err, organizations.ErrNameRequired),
+		errors.Is(err, organizations.ErrInvalidLimit):
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+	default:
+		apierror.Write(w, r, err)
+	}
+}
+
+// splitOrgPath splits the path after an organization route prefix into segments
+func splitOrgPath(rest string) []string {
+	rest = strings.Trim(rest, "/")
+	if rest == "" {
+		return nil
+	}
+	return strings.Split(rest, "/")
+}
+
+// actorName returns the authenticated user's name for logs
+func actorName(r *http.Request) string {
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		return user.Username
+	}
+	return ""
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+// ForgotPasswordRequest asks for a password reset link
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest sets a new password with a reset token
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+// HandleForgotPassword handles POST /api/v1/auth/forgot-password.
+// The response is the same whether or not the email belongs to an account,
+// so it cannot be used to discover registered addresses.
+func (h *AuthHandler) HandleForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.userStore == nil || h.notifier == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "password reset not available")
+		return
+	}
+
+	var req ForgotPasswordRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if strings.TrimSpace(req.Email) == "" {
+		apierror.Respond(w, http.StatusBadRequest, "email is required")
+		return
+	}
+
+	if found, err := h.userStore.GetByEmail(strings.TrimSpace(req.Email)); err == nil {
+		if user, err := h.userStore.GetByID(found.ToUser().ID); err == nil && user.IsActive {
+			token, expiresAt := h.resetTokens.Issue(user.ID, user.PasswordHash, time.Now())
+			h.notifier.NotifyPasswordReset(user.ID, token, expiresAt)
+			slog.InfoContext(r.Context(), "password reset requested", "user_id", user.ID, "expires_at", expiresAt)
+		}
+	} else {
+		slog.InfoContext(r.Context(), "password reset requested for unknown email")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":            "If an account exists for that email, a reset link has been sent",
+		"expires_in_seconds": int(h.resetTokens.TTL().Seconds()),
+	})
+}
+
+// HandleResetPassword handles POST /api/v1/auth/reset-password.
+// A token works once: the new password hash invalidates it.
+func (h *AuthHandler) HandleResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if h.userStore == nil {
+		apierror.Respond(w, http.StatusServiceUnavailable, "password reset not available")
+		return
+	}
+
+	var req ResetPasswordRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Token == "" || req.Password == "" {
+		apierror.Respond(w, http.StatusBadRequest, "token and password are required")
+		return
+	}
+	if len(req.Password) < 6 {
+		apierror.Respond(w, http.StatusBadRequest, "password must be at least 6 characters")
+		return
+	}
+
+	userID, err := h.resetTokens.UserID(req.Token)
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, auth.ErrInvalidResetToken.Error())
+		return
+	}
+	h.resetMu.Lock()
+	defer h.resetMu.Unlock()
+	user, err := h.userStore.GetByID(userID)
+	if err != nil || !user.IsActive || h.resetTokens.Verify(req.Token, user.PasswordHash, time.Now()) != nil {
+		slog.WarnContext(r.Context(), "invalid password reset token", "user_id", userID)
+		apierror.Respond(w, http.StatusBadRequest, auth.ErrInvalidResetToken.Error())
+		return
+	}
+
+	if err := h.userStore.SetPassword(user.ID, req.Password); err != nil {
+		slog.ErrorContext(r.Context(), "password reset failed", "user_id", user.ID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to update password")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "password reset", "user_id", user.ID)
+	if h.notifier != nil {
+		h.notifier.NotifyPasswordChanged(user.ID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password updated, you can now log in"})
+}
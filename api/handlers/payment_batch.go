@@ -0,0 +1,211 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/compliance"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+// DefaultBatchWorkers is the number of batch payments processed concurrently
+const DefaultBatchWorkers = 4
+
+// CreateBatchRequest represents a batch payment request
+type CreateBatchRequest struct {
+	Payments []payments.BatchItem `json:"payments"`
+}
+
+// validate checks the size of the batch and the fields of each payment
+func (req *CreateBatchRequest) validate(known func(string) bool) error {
+	var v validate.Validator
+	switch {
+	case len(req.Payments) == 0:
+		v.Add("payments", "must contain at least one payment")
+	case len(req.Payments) > payments.MaxBatchSize:
+		v.Add("payments", "must contain at most %d payments", payments.MaxBatchSize)
+	}
+	for i, item := range req.Payments {
+		field := fmt.Sprintf("payments[%d].", i)
+		v.Amount(field+"amount", item.Amount, item.Currency)
+		v.Currency(field+"currency", item.Currency)
+		v.Currency(field+"target_currency", item.TargetCurrency)
+		v.Route(field+"route", item.Route, known)
+	}
+	return v.Err()
+}
+
+// CreateBatchResponse represents the batch creation response
+type CreateBatchResponse struct {
+	BatchID      string                  `json:"batch_id"`
+	Transactions []*payments.Transaction `json:"transactions"`
+	Summary      payments.BatchSummary   `json:"summary"`
+}
+
+// BatchResponse is the current state of a batch
+type BatchResponse struct {
+	BatchID      string                  `json:"batch_id"`
+	CreatedAt    time.Time               `json:"created_at"`
+	Transactions []*payments.Transaction `json:"transactions"`
+	Summary      payments.BatchSummary   `json:"summary"`
+}
+
+// SetBatchWorkers sets how many batch payments are processed concurrently
+func (h *PaymentHandler) SetBatchWorkers(n int) {
+	if n > 0 {
+		h.batchWorkers = n
+	}
+}
+
+// HandleCreateBatch handles POST /api/v1/payments/batch. All payments are
+// created (or none is) and then processed in the background; poll
+// GET /api/v1/payments/batch/{id} for progress.
+func (h *PaymentHandler) HandleCreateBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req CreateBatchRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if err := req.validate(h.knownCountry()); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	h.runIdempotent(w, r, userID, req, func() (string, interface{}, error) {
+		amounts := make([]float64, len(req.Payments))
+		for i, item := range req.Payments {
+			amounts[i] = h.txnStore.InVolumeCurrency(item.Amount, item.Currency)
+		}
+		release, err := h.reserveLimits(r.Context(), userID, amounts...)
+		if err != nil {
+			return "", nil, err
+		}
+		defer release()
+
+		// Any denied payment rejects the whole batch; flagged ones are held
+		screenings := make([]*compliance.Result, len(req.Payments))
+		for i, item := range req.Payments {
+			screening, err := h.screen(r.Context(), &compliance.Request{UserID: userID, Amount: item.Amount, Currency: item.Currency, Route: item.Route})
+			if err != nil {
+				return "", nil, err
+			}
+			screenings[i] = screening
+		}
+
+		batch, err := h.txnStore.CreateBatch(userID, req.Payments, h.currentHaltedNodes())
+		if err != nil {
+			return "", nil, creationError(err)
+		}
+
+		slog.InfoContext(r.Context(), "batch created", "batch_id", batch.ID, "payments", len(batch.Transactions))
+
+		txnIDs := make([]string, 0, len(batch.Transactions))
+		for i, txn := range batch.Transactions {
+			if err := h.holdIfFlagged(r.Context(), txn, screenings[i]); err != nil {
+				return "", nil, err
+			}
+			if txn.Status != payments.StatusPendingReview {
+				txnIDs = append(txnIDs, txn.ID)
+			}
+		}
+		go h.processBatch(logging.Detach(r.Context()), batch.ID, txnIDs)
+
+		return batch.ID, CreateBatchResponse{
+			BatchID:      batch.ID,
+			Transactions: batch.Transactions,
+			Summary:      batch.Summary(),
+		}, nil
+	})
+}
+
+// processBatch processes the batch's transactions with a pool of workers
+func (h *PaymentHandler) processBatch(ctx context.Context, batchID string, txnIDs []string) {
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	workers := min(h.batchWorkers, len(txnIDs))
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for txnID := range jobs {
+				txnCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+				if err := h.settle(txnCtx, txnID); err != nil {
+					slog.WarnContext(txnCtx, "batch payment failed", "batch_id", batchID, "transaction_id", txnID, "error", err)
+				}
+				cancel()
+			}
+		}()
+	}
+
+	for _, txnID := range txnIDs {
+		jobs <- txnID
+	}
+	close(jobs)
+	wg.Wait()
+
+	if batch, err := h.txnStore.GetBatch(batchID); err == nil {
+		summary := batch.Summary()
+		slog.InfoContext(ctx, "batch processed", "batch_id", batchID, "succeeded", summary.Succeeded, "failed", summary.Failed)
+	}
+}
+
+// HandleGetBatch handles GET /api/v1/payments/batch/{id}
+func (h *PaymentHandler) HandleGetBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	batchID := strings.TrimPrefix(r.URL.Path, "/api/v1/payments/batch/")
+	if batchID == "" {
+		apierror.Respond(w, http.StatusBadRequest, "batch id required")
+		return
+	}
+
+	batch, err := h.txnStore.GetBatch(batchID)
+	if errors.Is(err, payments.ErrBatchNotFound) || (err == nil && batch.UserID != userID) {
+		apierror.Respond(w, http.StatusNotFound, "batch not found")
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(BatchResponse{
+		BatchID:      batch.ID,
+		CreatedAt:    batch.CreatedAt,
+		Transactions: batch.Transactions,
+		Summary:      batch.Summary(),
+	})
+}
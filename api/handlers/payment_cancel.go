@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/netting"
+)
+
+// CancelPaymentRequest is the body of POST /api/v1/payments/cancel
+type CancelPaymentRequest struct {
+	TransactionID string `json:"transaction_id"`
+}
+
+// CancelPaymentResponse is the cancelled payment and what was refunded
+type CancelPaymentResponse struct {
+	Transaction *payments.Transaction `json:"transaction"`
+	Refund      payments.Refund       `json:"refund"`
+}
+
+// SetRefundPolicy sets how much of a payment refunds return (defaults to
+// payments.DefaultRefundPolicy)
+func (h *PaymentHandler) SetRefundPolicy(policy payments.RefundPolicy) {
+	h.refundPolicy = policy
+}
+
+// HandleCancelPayment handles POST /api/v1/payments/cancel. A payer can
+// cancel a payment still waiting in the netting window; it is refunded under
+// the refund policy, which may keep the platform fee.
+func (h *PaymentHandler) HandleCancelPayment(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req CancelPaymentRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	var v validate.Validator
+	v.Required("transaction_id", req.TransactionID)
+	if err := v.Err(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	txn, err := h.txnStore.GetTransaction(req.TransactionID)
+	if err != nil || txn.UserID != userID {
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	before := *txn // The store updates txn in place
+	if h.netter == nil || before.Status != payments.StatusNetting {
+		apierror.Respond(w, http.StatusConflict, "only payments waiting to be netted can be cancelled")
+		return
+	}
+	if before.PaymentIntentID == "" && !h.stripeClient.IsMockMode() {
+		apierror.Respond(w, http.StatusConflict, "payment was not made through Stripe and must be refunded manually")
+		return
+	}
+
+	var refund payments.Refund
+	err = h.netter.Cancel(before.ID, func() error {
+		var err error
+		refund, err = h.refund(&before, before.PaymentIntentID, payments.RefundUserCancelled, "requested_by_customer")
+		return err
+	})
+	switch {
+	case errors.Is(err, netting.ErrNotQueued):
+		apierror.Respond(w, http.StatusConflict, "payment is already being settled")
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "cancellation refund failed", "transaction_id", before.ID, "error", err)
+		apierror.Respond(w, http.StatusBadGateway, "refund failed; the payment is still queued")
+		return
+	}
+
+	h.txnStore.MarkAsRefunded(before.ID, refund)
+	if txn, err = h.txnStore.GetTransaction(before.ID); err != nil {
+		apierror.Respond(w, http.StatusInternalServerError, "failed to load transaction")
+		return
+	}
+	slog.InfoContext(r.Context(), "payment cancelled", "transaction_id", txn.ID, "user_id", userID,
+		"refund_id", refund.ID, "refunded", refund.Amount, "retained", refund.Retained)
+	recordAudit(h.audit, r, http.StatusOK, "transaction.cancel", "transaction", txn.ID, &before, txn)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(CancelPaymentResponse{Transaction: txn, Refund: refund})
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/netting"
+)
+
+// TestCancelPaymentRefundsUnderPolicy verifies a payer can cancel a payment
+// waiting to be netted, keeping the base fee, and only their own
+func TestCancelPaymentRefundsUnderPolicy(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	netter := netting.NewNetter(txns, &netting.Config{Window: time.Minute})
+	txn, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	txns.SetPaymentIntent(txn.ID, "pi_1")
+	if err := netter.Submit(txn.ID); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	h := NewPaymentHandler(txns, nil)
+	h.SetProviders(payments.NewProviders(&scaProvider{}))
+	h.SetNetter(netter)
+
+	cancel := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/cancel", strings.NewReader(`{"transaction_id": "`+txn.ID+`"}`))
+		req = withUser(req, &auth.User{ID: userID, Role: auth.RoleUser})
+		rec := httptest.NewRecorder()
+		h.HandleCancelPayment(rec, req)
+		return rec
+	}
+
+	if rec := cancel("user2"); rec.Code != http.StatusNotFound {
+		t.Errorf("another user's payment: status = %d, want 404", rec.Code)
+	}
+
+	rec := cancel("user1")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var resp CancelPaymentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Refund.Reason != payments.RefundUserCancelled || resp.Refund.Retained != txn.BaseFee || resp.Refund.ID != "re_pi_1" {
+		t.Errorf("refund = %+v, want the base fee %.2f kept", resp.Refund, txn.BaseFee)
+	}
+	if got, _ := txns.GetTransaction(txn.ID); got.Status != payments.StatusFailed || got.Refund == nil || netter.Pending() != 0 {
+		t.Errorf("status = %s, refund = %v, pending = %d; want refunded and dequeued", got.Status, got.Refund, netter.Pending())
+	}
+
+	if rec := cancel("user1"); rec.Code != http.StatusConflict {
+		t.Errorf("second cancel: status = %d, want 409", rec.Code)
+	}
+}
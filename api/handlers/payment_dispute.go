@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/payouts"
+)
+
+// OpenDisputeRequest is the body of POST /api/v1/payments/disputes
+type OpenDisputeRequest struct {
+	TransactionID string                 `json:"transaction_id"`
+	Reason        payments.DisputeReason `json:"reason"`
+	Details       string                 `json:"details"`
+}
+
+// ResolveDisputeRequest is the body of POST /api/v1/admin/disputes/{id}/{accept|reject}
+type ResolveDisputeRequest struct {
+	Note string `json:"note"`
+}
+
+// DisputeListResponse lists disputed transactions
+type DisputeListResponse struct {
+	Transactions []*payments.Transaction `json:"transactions"` // Oldest dispute first
+	Count        int                     `json:"count"`
+}
+
+// maxDisputeDetails bounds the payer's free-text explanation
+const maxDisputeDetails = 2000
+
+// SetPayer holds the payouts of disputed payments: an accepted dispute
+// reverses the payout before refunding, a rejected one releases it
+func (h *PaymentHandler) SetPayer(p *payouts.Payer) {
+	h.payer = p
+}
+
+// HandleDisputes handles the payer's disputes:
+//
+//	GET  /api/v1/payments/disputes - the caller's disputed payments
+//	POST /api/v1/payments/disputes - dispute a completed payment
+func (h *PaymentHandler) HandleDisputes(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		mine := make([]*payments.Transaction, 0)
+		for _, txn := range h.txnStore.Disputes("") {
+			if txn.UserID == userID {
+				mine = append(mine, txn)
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DisputeListResponse{Transactions: mine, Count: len(mine)})
+	case http.MethodPost:
+		h.handleOpenDispute(w, r, userID)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *PaymentHandler) handleOpenDispute(w http.ResponseWriter, r *http.Request, userID string) {
+	var req OpenDisputeRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	req.Details = strings.TrimSpace(req.Details)
+	if req.TransactionID == "" {
+		apierror.Respond(w, http.StatusBadRequest, "transaction_id is required")
+		return
+	}
+	if len(req.Details) > maxDisputeDetails {
+		apierror.Respond(w, http.StatusBadRequest, "details must be at most 2000 characters")
+		return
+	}
+
+	txn, err := h.txnStore.OpenDispute(req.TransactionID, userID, req.Reason, req.Details)
+	switch {
+	case errors.Is(err, payments.ErrInvalidDisputeReason):
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	case errors.Is(err, payments.ErrNotDisputable), errors.Is(err, payments.ErrDisputeExists):
+		apierror.Respond(w, http.StatusConflict, err.Error())
+		return
+	case err != nil:
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "payment disputed", "transaction_id", txn.ID, "user_id", userID, "reason", req.Reason)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(txn)
+}
+
+// HandleAdminDisputes handles the dispute queue, registered as
+// /admin/disputes and /admin/disputes/{id}/{action}:
+//
+//	GET  /api/v1/admin/disputes?status=open  - disputed payments (default open, "all" for every status)
+//	POST /api/v1/admin/disputes/{id}/accept  - reverse the payout and refund the payment through Stripe
+//	POST /api/v1/admin/disputes/{id}/reject  - close the dispute without a refund (note required) and pay out
+func (h *PaymentHandler) HandleAdminDisputes(w http.ResponseWriter, r *http.Request) {
+	txnID, action := r.PathValue("id"), r.PathValue("action")
+	if txnID == "" {
+		if r.Method != http.MethodGet {
+			apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		status := payments.DisputeOpen
+		switch v := r.URL.Query().Get("status"); v {
+		case "":
+		case "all":
+			status = ""
+		default:
+			status = payments.DisputeStatus(v)
+		}
+		disputed := h.txnStore.Disputes(status)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(DisputeListResponse{Transactions: disputed, Count: len(disputed)})
+		return
+	}
+
+	var decision payments.DisputeStatus
+	switch action {
+	case "accept":
+		decision = payments.DisputeAccepted
+	case "reject":
+		decision = payments.DisputeRejected
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req ResolveDisputeRequest
+	if r.ContentLength != 0 {
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if decision == payments.DisputeRejected && strings.TrimSpace(req.Note) == "" {
+		apierror.Respond(w, http.StatusBadRequest, "a note is required to reject a dispute")
+		return
+	}
+
+	// Serialize with settlement and other reviewers so a payment is refunded at most once
+	_, unlock, err := h.txnStore.LockTransaction(r.Context(), txnID)
+	if errors.Is(err, payments.ErrTransactionLocked) {
+		apierror.Respond(w, http.StatusConflict, "payment is being processed; try again later")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to lock transaction", "transaction_id", txnID, "error", err)
+		apierror.Respond(w, http.StatusServiceUnavailable, "failed to lock transaction")
+		return
+	}
+	defer unlock()
+
+	before, err := h.txnStore.GetTransaction(txnID)
+	if err != nil {
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	if before.Dispute == nil || before.Dispute.Status != payments.DisputeOpen {
+		apierror.Respond(w, http.StatusConflict, "payment has no open dispute")
+		return
+	}
+	opened := before.Dispute
+
+	var refund *payments.Refund
+	var refundID string
+	if decision == payments.DisputeAccepted {
+		if before.PaymentIntentID == "" && !h.stripeClient.IsMockMode() {
+			apierror.Respond(w, http.StatusConflict, "payment was not made through Stripe and must be refunded manually")
+			return
+		}
+		// Take the money back from the recipient first; a retried accept skips a payout already reversed
+		if h.payer != nil {
+			_, err := h.payer.Reverse(r.Context(), txnID)
+			if errors.Is(err, payouts.ErrPayoutInProgress) {
+				apierror.Respond(w, http.StatusConflict, err.Error())
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(r.Context(), "payout reversal failed", "transaction_id", txnID, "error", err)
+				apierror.Respond(w, http.StatusBadGateway, "payout reversal failed; the dispute is still open")
+				return
+			}
+		}
+		issued, err := h.refund(before, before.PaymentIntentID, payments.RefundDisputed, "dispute_accepted")
+		if err != nil {
+			slog.ErrorContext(r.Context(), "dispute refund failed", "transaction_id", txnID, "error", err)
+			apierror.Respond(w, http.StatusBadGateway, "refund failed; the dispute is still open")
+			return
+		}
+		refund, refundID = &issued, issued.ID
+	}
+
+	reviewer := actorName(r)
+	txn, err := h.txnStore.ResolveDispute(txnID, decision, reviewer, req.Note, refund)
+	if errors.Is(err, payments.ErrNoOpenDispute) {
+		apierror.Respond(w, http.StatusConflict, "payment has no open dispute")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to resolve dispute", "transaction_id", txnID, "refund_id", refundID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to resolve dispute")
+		return
+	}
+
+	if decision == payments.DisputeRejected && h.payer != nil && txn.PayoutAccount != "" {
+		h.payer.Queue(txnID) // Held while the dispute was open
+	}
+	slog.InfoContext(r.Context(), "dispute resolved", "transaction_id", txnID, "decision", decision, "reviewer", reviewer, "refund_id", refundID)
+	recordAudit(h.audit, r, http.StatusOK, "transaction.dispute_"+action, "transaction", txnID, opened, txn.Dispute)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(txn)
+}
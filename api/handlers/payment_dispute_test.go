@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/api/versioning"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/payouts"
+)
+
+// TestAcceptedDisputeReversesPayout verifies a disputed payment's payout is
+// held while the dispute is open and reversed when the dispute is accepted,
+// before the payer is refunded
+func TestAcceptedDisputeReversesPayout(t *testing.T) {
+	ctx := context.Background()
+	txns := payments.NewTransactionStore()
+	txn, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	txns.SetPaymentIntent(txn.ID, "pi_1")
+	if err := txns.SetPayoutAccount(txn.ID, "acct_recipient"); err != nil {
+		t.Fatalf("SetPayoutAccount: %v", err)
+	}
+	if err := txns.ProcessTransaction(ctx, txn.ID, nil, 0); err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	stripe := payments.NewStripeClientWithKeys("", "", "")
+	payer := payouts.NewPayer(txns, payments.NewProviders(stripe), stripe, nil)
+	if _, err := payer.Pay(ctx, txn.ID); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if _, err := txns.OpenDispute(txn.ID, "user1", payments.DisputeNotReceived, ""); err != nil {
+		t.Fatalf("OpenDispute: %v", err)
+	}
+
+	h := NewPaymentHandler(txns, nil)
+	h.SetProviders(payments.NewProviders(&scaProvider{}))
+	h.SetPayer(payer)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/disputes/"+txn.ID+"/accept", strings.NewReader(`{"note": "confirmed"}`))
+	req.SetPathValue("id", txn.ID)
+	req.SetPathValue("action", "accept")
+	req = withUser(req, &auth.User{ID: "admin1", Username: "treasurer", Role: auth.RoleAdmin})
+	rec := httptest.NewRecorder()
+	h.HandleAdminDisputes(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+
+	got, _ := txns.GetTransaction(txn.ID)
+	if got.Payout == nil || got.Payout.Status != payments.PayoutReversed || got.Payout.ReversalID == "" {
+		t.Errorf("payout = %+v, want reversed", got.Payout)
+	}
+	if got.Refund == nil || got.Refund.ID != "re_pi_1" || got.Dispute.Status != payments.DisputeAccepted {
+		t.Errorf("refund = %+v, dispute = %+v, want refunded and accepted", got.Refund, got.Dispute)
+	}
+}
+
+// TestMockConfirmedPaymentIsNotPaidOut verifies a payment started with a
+// provider cannot be settled with the mock card, and a payment whose funds
+// no provider collected is never paid out
+func TestMockConfirmedPaymentIsNotPaidOut(t *testing.T) {
+	ctx := context.Background()
+	txns := payments.NewTransactionStore()
+	stripe := payments.NewStripeClientWithKeys("", "", "")
+	payer := payouts.NewPayer(txns, payments.NewProviders(stripe), stripe, nil)
+
+	// As /stripe/initiate leaves it: a payout account and an unpaid PaymentIntent
+	txn, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if err := txns.SetPayoutAccount(txn.ID, "acct_recipient"); err != nil {
+		t.Fatalf("SetPayoutAccount: %v", err)
+	}
+	txns.SetPaymentProvider(txn.ID, payments.ProviderStripe)
+	txns.SetPaymentIntent(txn.ID, "pi_1")
+
+	h := NewPaymentHandler(txns, nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/confirm",
+		strings.NewReader(`{"transaction_id": "`+txn.ID+`", "card_number": "4242424242424242"}`))
+	req = withUser(req, &auth.User{ID: "user1", Role: auth.RoleUser})
+	rec := httptest.NewRecorder()
+	h.HandleConfirmPayment(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("status = %d, want 409: %s", rec.Code, rec.Body)
+	}
+	if got, _ := txns.GetTransaction(txn.ID); got.Status != payments.StatusPending {
+		t.Errorf("status = %s, want the payment left pending", got.Status)
+	}
+
+	// Settled without any provider collecting the funds
+	unpaid, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if err := txns.SetPayoutAccount(unpaid.ID, "acct_recipient"); err != nil {
+		t.Fatalf("SetPayoutAccount: %v", err)
+	}
+	if err := txns.ProcessTransaction(ctx, unpaid.ID, nil, 0); err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	if _, err := payer.Pay(ctx, unpaid.ID); !errors.Is(err, payouts.ErrNotCollected) {
+		t.Errorf("Pay: err = %v, want ErrNotCollected", err)
+	}
+	if got, _ := txns.GetTransaction(unpaid.ID); got.Payout != nil {
+		t.Errorf("payout = %+v, want none", got.Payout)
+	}
+}
+
+// TestAdminPaymentHandlersServeUnderAnyVersion verifies the dispute queue
+// and transaction trace read their IDs from the route, not a /api/v1 path
+func TestAdminPaymentHandlersServeUnderAnyVersion(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	txn, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	h := NewPaymentHandler(txns, nil)
+
+	mux := http.NewServeMux()
+	v2 := versioning.NewRouter(mux).Version("v2")
+	v2.HandleFunc("/admin/disputes", h.HandleAdminDisputes)
+	v2.HandleFunc("/admin/disputes/{id}/{action}", h.HandleAdminDisputes)
+	v2.HandleFunc("/admin/transactions/{id}/trace", h.HandleTransactionTrace)
+	admin := &auth.User{ID: "admin1", Role: auth.RoleAdmin}
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, withUser(httptest.NewRequest(method, path, nil), admin))
+		return rec
+	}
+
+	if rec := serve(http.MethodGet, "/api/v2/admin/transactions/"+txn.ID+"/trace"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), txn.ID) {
+		t.Errorf("trace: status = %d, want 200 for %s: %s", rec.Code, txn.ID, rec.Body)
+	}
+	if rec := serve(http.MethodGet, "/api/v2/admin/disputes"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"count":0`) {
+		t.Errorf("dispute queue: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if rec := serve(http.MethodPost, "/api/v2/admin/disputes/"+txn.ID+"/escalate"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown dispute action: status = %d, want 404", rec.Code)
+	}
+	if rec := serve(http.MethodPost, "/api/v2/admin/disputes/"+txn.ID+"/reject"); rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "note is required") {
+		t.Errorf("reject without a note: status = %d, want 400: %s", rec.Code, rec.Body)
+	}
+}
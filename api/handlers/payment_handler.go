@@ -3,43 +3,155 @@ package handlers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"log"
+	"errors"
+	"io"
+	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/compliance"
 	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/limits"
 	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/netting"
+	"github.com/plm/predictive-liquidity-mesh/payments/payouts"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
 )
 
 // PaymentHandler handles payment API endpoints
 type PaymentHandler struct {
-	txnStore     *payments.TransactionStore
+	txnStore     payments.TransactionStorer
 	countryGraph *router.CountryGraph
 	stripeClient *payments.StripeClient
+	providers    *payments.Providers
+	refundPolicy payments.RefundPolicy
+	fxMu         sync.RWMutex
 	fxRates      map[string]float64
+	haltMu       sync.RWMutex
 	haltedNodes  map[string]bool
+	batchWorkers int
+	quotes       *payments.QuoteSigner
+	limits       *limits.Engine
+	screener     compliance.Screener
+	audit        audit.Store
+	netter       *netting.Netter
+	payer        *payouts.Payer
 }
 
 // NewPaymentHandler creates a new payment handler
-func NewPaymentHandler(txnStore *payments.TransactionStore, countryGraph *router.CountryGraph) *PaymentHandler {
+func NewPaymentHandler(txnStore payments.TransactionStorer, countryGraph *router.CountryGraph) *PaymentHandler {
+	stripeClient := payments.NewStripeClient()
 	return &PaymentHandler{
 		txnStore:     txnStore,
 		countryGraph: countryGraph,
-		stripeClient: payments.NewStripeClient(),
+		stripeClient: stripeClient,
+		providers:    payments.NewProviders(stripeClient),
+		refundPolicy: payments.DefaultRefundPolicy(),
 		fxRates:      make(map[string]float64),
 		haltedNodes:  make(map[string]bool),
+		batchWorkers: DefaultBatchWorkers,
+		quotes:       payments.NewQuoteSigner(payments.DefaultQuoteConfig()),
 	}
 }
 
-// SetFXRates updates the FX rates map
+// SetFXRates updates the FX rates map. The map must not be modified afterwards.
 func (h *PaymentHandler) SetFXRates(rates map[string]float64) {
+	h.fxMu.Lock()
+	defer h.fxMu.Unlock()
 	h.fxRates = rates
 }
 
-// SetHaltedNodes updates the halted nodes map
+// currentFXRates returns the FX rates in effect
+func (h *PaymentHandler) currentFXRates() map[string]float64 {
+	h.fxMu.RLock()
+	defer h.fxMu.RUnlock()
+	return h.fxRates
+}
+
+// SetStripeClient replaces the Stripe client (defaults to STRIPE_* environment keys)
+func (h *PaymentHandler) SetStripeClient(client *payments.StripeClient) {
+	h.stripeClient = client
+	h.providers.Register(client)
+}
+
+// SetNetter queues confirmed payments for netting instead of settling them
+// one by one. Members of failed netting sets are refunded like payments that
+// failed on every route.
+func (h *PaymentHandler) SetNetter(n *netting.Netter) {
+	h.netter = n
+	n.SetRefunder(h.refundNetted)
+}
+
+// refundNetted refunds a payment whose netting set failed
+func (h *PaymentHandler) refundNetted(ctx context.Context, txn *payments.Transaction) error {
+	refund, err := h.refund(txn, txn.PaymentIntentID, payments.RefundMeshFailure, "netting_set_failed")
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "netted payment refunded", "transaction_id", txn.ID, "refund_id", refund.ID, "amount", refund.Amount)
+	h.txnStore.MarkAsRefunded(txn.ID, refund)
+	return nil
+}
+
+// settle processes a confirmed payment through the mesh, or queues it for
+// netting when netting is enabled
+func (h *PaymentHandler) settle(ctx context.Context, txnID string) error {
+	if h.netter != nil {
+		return h.netter.Submit(txnID)
+	}
+	return h.txnStore.ProcessTransaction(ctx, txnID, h.currentFXRates(), 0.05)
+}
+
+// SetLimits checks new payments against the payer's spending limits
+func (h *PaymentHandler) SetLimits(engine *limits.Engine) {
+	h.limits = engine
+}
+
+// SetHaltedNodes replaces the set of halted countries (routes through them pay halt fines)
 func (h *PaymentHandler) SetHaltedNodes(halted map[string]bool) {
-	h.haltedNodes = halted
+	h.haltMu.Lock()
+	defer h.haltMu.Unlock()
+	h.haltedNodes = make(map[string]bool, len(halted))
+	for code, ok := range halted {
+		if ok {
+			h.haltedNodes[code] = true
+		}
+	}
+}
+
+// SetHalted halts or resumes a single country
+func (h *PaymentHandler) SetHalted(code string, halted bool) {
+	h.haltMu.Lock()
+	defer h.haltMu.Unlock()
+	if halted {
+		h.haltedNodes[code] = true
+	} else {
+		delete(h.haltedNodes, code)
+	}
+}
+
+// currentHaltedNodes returns a copy of the halted countries
+func (h *PaymentHandler) currentHaltedNodes() map[string]bool {
+	h.haltMu.RLock()
+	defer h.haltMu.RUnlock()
+	halted := make(map[string]bool, len(h.haltedNodes))
+	for code := range h.haltedNodes {
+		halted[code] = true
+	}
+	return halted
 }
 
 // CreatePaymentRequest represents a payment creation request
@@ -48,6 +160,19 @@ type CreatePaymentRequest struct {
 	Currency       string   `json:"currency"`
 	TargetCurrency string   `json:"target_currency"`
 	Route          []string `json:"route"`
+	QuoteID        string   `json:"quote_id,omitempty"` // From POST /api/v1/payments/quote; locks its exchange rates
+}
+
+// validate checks the request fields (a quote supplies the amount and route)
+func (req *CreatePaymentRequest) validate(known func(string) bool) error {
+	var v validate.Validator
+	v.Currency("currency", req.Currency)
+	v.Currency("target_currency", req.TargetCurrency)
+	if req.QuoteID == "" {
+		v.Amount("amount", req.Amount, req.Currency)
+		v.Route("route", req.Route, known)
+	}
+	return v.Err()
 }
 
 // CreatePaymentResponse represents the payment creation response
@@ -61,76 +186,202 @@ type FeeBreakdown struct {
 	BaseFee     float64 `json:"base_fee"`
 	BaseFeeRate string  `json:"base_fee_rate"`
 	HopFees     float64 `json:"hop_fees"`
-	HopFeeRate  string  `json:"hop_fee_rate"`
+	HopFeeRate  string  `json:"hop_fee_rate"` // Average per hop
 	HopCount    int     `json:"hop_count"`
 	HaltFines   float64 `json:"halt_fines"`
 	HaltCount   int     `json:"halt_count"`
 	TotalFees   float64 `json:"total_fees"`
 	FinalAmount float64 `json:"final_amount"`
+
+	Hops []payments.HopFee `json:"hops,omitempty"` // Per-hop fee priced from destination credibility
+}
+
+// newFeeBreakdown summarizes a new transaction's fees
+func newFeeBreakdown(txn *payments.Transaction, halted map[string]bool) FeeBreakdown {
+	haltCount := 0
+	for _, code := range txn.Route {
+		if halted[code] {
+			haltCount++
+		}
+	}
+	hopCount := len(txn.Route) - 1
+	return FeeBreakdown{
+		BaseFee:     txn.BaseFee,
+		BaseFeeRate: formatRate(txn.BaseFee / txn.Amount),
+		HopFees:     txn.HopFees,
+		HopFeeRate:  formatRate(txn.HopFees / txn.Amount / float64(hopCount)),
+		HopCount:    hopCount,
+		HaltFines:   txn.HaltFines,
+		HaltCount:   haltCount,
+		TotalFees:   txn.TotalFees,
+		FinalAmount: txn.FinalAmount,
+		Hops:        txn.HopFeeBreakdown,
+	}
 }
 
 // HandleCreatePayment creates a new payment transaction
 func (h *PaymentHandler) HandleCreatePayment(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	// Get user from context (set by auth middleware)
 	userID := getUserIDFromContext(r)
 	if userID == "" {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	var req CreatePaymentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// Validate
-	if req.Amount <= 0 {
-		http.Error(w, `{"error":"amount must be positive"}`, http.StatusBadRequest)
+	if err := req.validate(h.knownCountry()); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
-	if len(req.Route) < 2 {
-		http.Error(w, `{"error":"route must have at least 2 countries"}`, http.StatusBadRequest)
+
+	// Create transaction (at most once per Idempotency-Key)
+	h.runIdempotent(w, r, userID, req, func() (string, interface{}, error) {
+		halted := h.currentHaltedNodes()
+		txn, err := h.newTransaction(r.Context(), userID, req.QuoteID, req.Amount, req.Currency, req.TargetCurrency, req.Route, halted)
+		if err != nil {
+			return "", nil, err
+		}
+
+		response := CreatePaymentResponse{
+			Transaction:  txn,
+			FeeBreakdown: newFeeBreakdown(txn, halted),
+		}
+		return txn.ID, response, nil
+	})
+}
+
+// newTransaction creates a transaction from the request, or from its quote when quoteID is set
+func (h *PaymentHandler) newTransaction(ctx context.Context, userID, quoteID string, amount float64, currency, targetCurrency string, route []string, halted map[string]bool) (*payments.Transaction, error) {
+	if quoteID != "" {
+		return h.createQuotedTransaction(ctx, userID, quoteID, amount, currency, targetCurrency, route, halted)
+	}
+	release, err := h.reserveLimits(ctx, userID, h.txnStore.InVolumeCurrency(amount, currency))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	screening, err := h.screen(ctx, &compliance.Request{UserID: userID, Amount: amount, Currency: currency, Route: route})
+	if err != nil {
+		return nil, err
+	}
+	txn, err := h.txnStore.CreateTransaction(userID, amount, currency, targetCurrency, route, halted)
+	if err != nil {
+		return nil, creationError(err)
+	}
+	if err := h.holdIfFlagged(ctx, txn, screening); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// CreateScreenedTransaction creates a transaction on behalf of a payer who
+// is not making the request, such as a scheduled payment, under the same
+// spending limits and compliance screening as the API. Flagged payments are
+// returned held for review.
+func (h *PaymentHandler) CreateScreenedTransaction(ctx context.Context, userID string, amount float64, currency, targetCurrency string, route []string) (*payments.Transaction, error) {
+	return h.newTransaction(ctx, userID, "", amount, currency, targetCurrency, route, h.currentHaltedNodes())
+}
+
+// reserveLimits rejects transfers of amounts, in payments.VolumeCurrency,
+// that would exceed the payer's spending limits, and holds the rest against
+// them until release is called once the transactions are stored
+func (h *PaymentHandler) reserveLimits(ctx context.Context, userID string, amounts ...float64) (release func(), err error) {
+	if h.limits == nil {
+		return func() {}, nil
+	}
+	release, err = h.limits.Reserve(ctx, userID, amounts...)
+	if errors.Is(err, limits.ErrLimitExceeded) {
+		return nil, creationError(err)
+	}
+	return release, err
+}
+
+// formatRate formats a fee fraction as a percentage (0.015 -> "1.5%")
+func formatRate(fraction float64) string {
+	return strconv.FormatFloat(math.Round(fraction*1e6)/1e4, 'f', -1, 64) + "%"
+}
+
+// creationError maps a transaction store error to the HTTP error returned to the payer
+func creationError(err error) error {
+	if errors.Is(err, payments.ErrSpendingLimitExceeded) || errors.Is(err, limits.ErrLimitExceeded) {
+		return apierror.New(apierror.CodeLimitExceeded, err.Error())
+	}
+	return apierror.New(apierror.CodeInvalidRequest, err.Error())
+}
+
+// knownCountry returns the check for countries of the mesh, nil without a graph
+func (h *PaymentHandler) knownCountry() func(string) bool {
+	if h.countryGraph == nil {
+		return nil
+	}
+	return h.countryGraph.HasNode
+}
+
+// runIdempotent executes create at most once per Idempotency-Key header and
+// writes the original response on retries. Without the header it simply runs create.
+func (h *PaymentHandler) runIdempotent(w http.ResponseWriter, r *http.Request, userID string, req interface{}, create func() (string, interface{}, error)) {
+	key := r.Header.Get("Idempotency-Key")
+	if key == "" {
+		_, body, err := create()
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(body)
 		return
 	}
 
-	// Create transaction
-	txn, err := h.txnStore.CreateTransaction(userID, req.Amount, req.Currency, req.TargetCurrency, req.Route, h.haltedNodes)
-	if err != nil {
-		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+	if len(key) > 255 {
+		apierror.Respond(w, http.StatusBadRequest, "idempotency key too long")
 		return
 	}
 
-	// Count halted nodes in route
-	haltCount := 0
-	for _, code := range req.Route {
-		if h.haltedNodes[code] {
-			haltCount++
+	// Fingerprint the endpoint + body so a key can't be reused for a different request
+	reqJSON, _ := json.Marshal(req)
+	sum := sha256.Sum256(append([]byte(r.URL.Path+":"), reqJSON...))
+
+	rec, replayed, err := h.txnStore.Idempotent(r.Context(), userID, key, hex.EncodeToString(sum[:]), func() (*payments.IdempotencyRecord, error) {
+		txnID, body, err := create()
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
 		}
+		return &payments.IdempotencyRecord{TransactionID: txnID, StatusCode: http.StatusOK, Response: data}, nil
+	})
+	if errors.Is(err, payments.ErrIdempotencyKeyReused) {
+		apierror.RespondCode(w, apierror.CodeIdempotencyKeyReused, "idempotency key already used for a different request")
+		return
 	}
-
-	response := CreatePaymentResponse{
-		Transaction: txn,
-		FeeBreakdown: FeeBreakdown{
-			BaseFee:     txn.BaseFee,
-			BaseFeeRate: "1.5%",
-			HopFees:     txn.HopFees,
-			HopFeeRate:  "0.02%",
-			HopCount:    len(req.Route) - 1,
-			HaltFines:   txn.HaltFines,
-			HaltCount:   haltCount,
-			TotalFees:   txn.TotalFees,
-			FinalAmount: txn.FinalAmount,
-		},
+	if errors.Is(err, payments.ErrIdempotencyKeyInProgress) {
+		apierror.Respond(w, http.StatusConflict, "a request with this idempotency key is still in progress; retry later")
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
 
+	if replayed {
+		slog.InfoContext(r.Context(), "idempotent replay", "idempotency_key", key, "transaction_id", rec.TransactionID)
+		w.Header().Set("Idempotent-Replayed", "true")
+	}
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	w.WriteHeader(rec.StatusCode)
+	w.Write(rec.Response)
 }
 
 // ConfirmPaymentRequest represents a payment confirmation request
@@ -142,39 +393,52 @@ type ConfirmPaymentRequest struct {
 	ExpiryYear    string `json:"expiry_year"`
 }
 
+// ConfirmPaymentResponse is the outcome of a confirmed payment
+type ConfirmPaymentResponse struct {
+	Transaction *payments.Transaction `json:"transaction"`
+	Success     bool                  `json:"success"`
+	Message     string                `json:"message"`
+}
+
 // HandleConfirmPayment confirms and processes a payment
 func (h *PaymentHandler) HandleConfirmPayment(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	userID := getUserIDFromContext(r)
 	if userID == "" {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	var req ConfirmPaymentRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
 	// Verify transaction exists and belongs to user
 	txn, err := h.txnStore.GetTransaction(req.TransactionID)
 	if err != nil {
-		http.Error(w, `{"error":"transaction not found"}`, http.StatusNotFound)
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
 		return
 	}
 	if txn.UserID != userID {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	// The mock card collects nothing, so a payment started with a provider
+	// (or to be paid out) must be completed through that provider
+	if txn.PaymentIntentID != "" || txn.PaymentProvider != "" || txn.PayoutAccount != "" {
+		apierror.Respond(w, http.StatusConflict, "payment must be completed through its payment provider")
 		return
 	}
 
 	// Mock card validation (accept any 16-digit number for demo)
 	if len(req.CardNumber) < 13 || len(req.CardNumber) > 19 {
-		http.Error(w, `{"error":"invalid card number"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "invalid card number")
 		return
 	}
 
@@ -182,153 +446,251 @@ func (h *PaymentHandler) HandleConfirmPayment(w http.ResponseWriter, r *http.Req
 	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
 	defer cancel()
 
-	log.Printf("💳 Processing payment %s: $%.2f through %v", txn.ID, txn.Amount, txn.Route)
+	slog.InfoContext(ctx, "processing payment", "transaction_id", txn.ID, "amount", txn.Amount, "route", txn.Route)
 
-	err = h.txnStore.ProcessTransaction(ctx, req.TransactionID, h.fxRates, 0.05)
+	err = h.settle(ctx, req.TransactionID)
 	
 	// Get updated transaction
 	txn, _ = h.txnStore.GetTransaction(req.TransactionID)
 
 	if err != nil {
-		log.Printf("❌ Payment %s failed: %v", txn.ID, err)
+		slog.WarnContext(ctx, "payment failed", "transaction_id", txn.ID, "error", err)
+	} else if txn.Status == payments.StatusNetting {
+		slog.InfoContext(ctx, "payment queued for netting", "transaction_id", txn.ID)
 	} else {
-		log.Printf("✅ Payment %s completed: Admin profit $%.2f", txn.ID, txn.AdminProfit)
+		slog.InfoContext(ctx, "payment completed", "transaction_id", txn.ID, "admin_profit", txn.AdminProfit)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"transaction": txn,
-		"success":     txn.Status == payments.StatusSuccess,
-		"message":     getStatusMessage(txn.Status, txn.FailedAt),
+	json.NewEncoder(w).Encode(ConfirmPaymentResponse{
+		Transaction: txn,
+		Success:     txn.Status == payments.StatusSuccess,
+		Message:     getStatusMessage(txn.Status, txn.FailedAt),
 	})
 }
 
-// HandleGetTransaction returns a single transaction
+// HandleGetTransaction returns one of the caller's transactions. With
+// ?admin=true, staff holding payments:read can fetch anyone's; each such
+// lookup is audited.
 func (h *PaymentHandler) HandleGetTransaction(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	txnID := r.URL.Query().Get("id")
 	if txnID == "" {
-		http.Error(w, `{"error":"transaction id required"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "transaction id required")
+		return
+	}
+	adminView := false
+	if v := r.URL.Query().Get("admin"); v != "" {
+		var err error
+		if adminView, err = strconv.ParseBool(v); err != nil {
+			apierror.Respond(w, http.StatusBadRequest, "admin must be true or false")
+			return
+		}
+	}
+	if adminView && !middleware.Can(r.Context(), auth.PermPaymentsRead) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 		return
 	}
 
+	// Other users' payments are hidden as if they did not exist, unless
+	// staff ask for them explicitly
 	txn, err := h.txnStore.GetTransaction(txnID)
-	if err != nil {
-		http.Error(w, `{"error":"transaction not found"}`, http.StatusNotFound)
+	if err != nil || (!adminView && txn.UserID != userID) {
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
 		return
 	}
+	if adminView {
+		recordAudit(h.audit, r, http.StatusOK, "transaction.view", "transaction", txn.ID, nil, nil)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(txn)
 }
 
+// Pagination limits for transaction history
+const (
+	defaultHistoryLimit = 50
+	maxHistoryLimit     = 200
+)
+
 // HandleGetHistory returns user's transaction history
+// Query params: limit, offset, from/to (RFC3339 or YYYY-MM-DD, to is inclusive),
+// status (comma-separated), sort (created_at, amount, final_amount), order (asc/desc)
 func (h *PaymentHandler) HandleGetHistory(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	userID := getUserIDFromContext(r)
 	if userID == "" {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
-	transactions := h.txnStore.GetUserTransactions(userID)
+	query, err := parseHistoryQuery(r.URL.Query())
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := h.txnStore.QueryUserTransactions(userID, query)
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	writeHistoryPage(w, page)
+}
+
+// HistoryResponse is a page of transaction history
+type HistoryResponse struct {
+	*payments.HistoryPage
+	Count int `json:"count"` // Transactions on this page
+}
 
+// writeHistoryPage writes a page of transaction history as JSON
+func writeHistoryPage(w http.ResponseWriter, page *payments.HistoryPage) {
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"transactions": transactions,
-		"count":        len(transactions),
-	})
+	json.NewEncoder(w).Encode(HistoryResponse{HistoryPage: page, Count: len(page.Transactions)})
 }
 
-// HandleAdminStats returns admin analytics with all transactions (admin only)
-func (h *PaymentHandler) HandleAdminStats(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
-		return
+// parseHistoryQuery builds a history query from request parameters (newest first by default)
+func parseHistoryQuery(values url.Values) (payments.HistoryQuery, error) {
+	q := payments.HistoryQuery{
+		Limit:      defaultHistoryLimit,
+		SortBy:     values.Get("sort"),
+		Descending: true,
 	}
 
-	stats := h.txnStore.GetAdminStats()
-	allTransactions := h.txnStore.GetAllTransactions()
-
-	// Build enhanced analytics
-	var totalVolume float64
-	var totalFees float64
-	var successCount, failedCount, pendingCount int
-	var dailyVolume = make(map[string]float64)
-	var dailyFees = make(map[string]float64)
+	if v := values.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return q, errors.New("limit must be a positive integer")
+		}
+		q.Limit = min(n, maxHistoryLimit)
+	}
+	if v := values.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return q, errors.New("offset must be a non-negative integer")
+		}
+		q.Offset = n
+	}
 
-	for _, txn := range allTransactions {
-		totalVolume += txn.Amount
-		totalFees += txn.TotalFees
-		
-		day := txn.CreatedAt.Format("2006-01-02")
-		dailyVolume[day] += txn.Amount
-		dailyFees[day] += txn.TotalFees
+	var err error
+	if v := values.Get("from"); v != "" {
+		if q.From, err = parseHistoryTime(v, false); err != nil {
+			return q, errors.New("from must be RFC3339 or YYYY-MM-DD")
+		}
+	}
+	if v := values.Get("to"); v != "" {
+		if q.To, err = parseHistoryTime(v, true); err != nil {
+			return q, errors.New("to must be RFC3339 or YYYY-MM-DD")
+		}
+	}
 
-		switch txn.Status {
-		case payments.StatusSuccess:
-			successCount++
-		case payments.StatusFailed:
-			failedCount++
-		default:
-			pendingCount++
+	if v := values.Get("status"); v != "" {
+		for _, status := range strings.Split(v, ",") {
+			switch s := payments.TransactionStatus(strings.ToLower(strings.TrimSpace(status))); s {
+			case payments.StatusPending, payments.StatusPendingReview, payments.StatusProcessing, payments.StatusNetting, payments.StatusSuccess, payments.StatusFailed, payments.StatusInterrupted:
+				q.Statuses = append(q.Statuses, s)
+			default:
+				return q, errors.New("unknown status filter")
+			}
 		}
 	}
 
+	switch strings.ToLower(values.Get("order")) {
+	case "", "desc":
+	case "asc":
+		q.Descending = false
+	default:
+		return q, errors.New("order must be asc or desc")
+	}
+
+	return q, q.Validate()
+}
+
+// parseHistoryTime parses an RFC3339 timestamp or a date. A date used as an
+// upper bound covers the whole day.
+func parseHistoryTime(v string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", v)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// Recent transactions listed by HandleAdminStats
+const (
+	defaultAdminStatsLimit = 100
+	maxAdminStatsLimit     = 1000
+)
+
+// HandleAdminStats returns admin analytics and the most recent transactions
+// (admin only). ?org={id} restricts both to one organization's transactions,
+// ?limit= sets how many transactions are listed. The analytics come from
+// totals the store keeps as transactions change state, so they cost the same
+// however many transactions there are.
+func (h *PaymentHandler) HandleAdminStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	orgID := r.URL.Query().Get("org")
+	limit := parsePositiveInt(r.URL.Query().Get("limit"), defaultAdminStatsLimit)
+	if limit > maxAdminStatsLimit {
+		limit = maxAdminStatsLimit
+	}
+
+	stats := h.txnStore.PlatformStats(orgID)
+	successCount := stats.Count(payments.StatusSuccess)
+	failedCount := stats.Count(payments.StatusFailed)
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"stats":            stats,
-		"all_transactions": allTransactions,
+		"org_id":              orgID,
+		"stats":               stats.Summary(),
+		"recent_transactions": h.txnStore.RecentTransactions(orgID, limit),
 		"analytics": map[string]interface{}{
-			"total_volume":       totalVolume,
-			"total_platform_fee": totalFees,
-			"total_transactions": len(allTransactions),
+			"total_volume":       stats.TotalVolume,
+			"total_platform_fee": stats.TotalFees,
+			"total_transactions": stats.Transactions,
 			"success_count":      successCount,
 			"failed_count":       failedCount,
-			"pending_count":      pendingCount,
-			"success_rate":       float64(successCount) / float64(max(len(allTransactions), 1)) * 100,
-			"daily_volume":       dailyVolume,
-			"daily_fees":         dailyFees,
+			"pending_count":      stats.Transactions - successCount - failedCount,
+			"success_rate":       float64(successCount) / float64(max(stats.Transactions, 1)) * 100,
+			"daily_volume":       stats.DailyVolume,
+			"daily_fees":         stats.DailyFees,
 		},
 	})
 }
 
-// Helper functions
+// getUserIDFromContext returns the ID of the user Authenticate added to the
+// request, or "" when there is none
 func getUserIDFromContext(r *http.Request) string {
-	// Try middleware context key (typed key)
-	type contextKey string
-	const userContextKey contextKey = "user"
-	
-	// Try typed context key first
-	if user := r.Context().Value(userContextKey); user != nil {
-		// Try GetID method
-		if u, ok := user.(interface{ GetID() string }); ok {
-			return u.GetID()
-		}
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		return user.ID
 	}
-	
-	// Try string key (fallback)
-	if user := r.Context().Value("user"); user != nil {
-		if u, ok := user.(interface{ GetID() string }); ok {
-			return u.GetID()
-		}
-	}
-	
-	// For demo: accept X-User-ID header
-	if id := r.Header.Get("X-User-ID"); id != "" {
-		return id
-	}
-	
-	// Default demo user for testing
-	return "demo-user"
+	return ""
 }
 
 func getStatusMessage(status payments.TransactionStatus, failedAt string) string {
@@ -341,6 +703,12 @@ func getStatusMessage(status payments.TransactionStatus, failedAt string) string
 		return "Payment is being processed"
 	case payments.StatusPending:
 		return "Payment is pending confirmation"
+	case payments.StatusPendingReview:
+		return "Payment is held for compliance review"
+	case payments.StatusNetting:
+		return "Payment is queued for netting and settles at the end of the window"
+	case payments.StatusInterrupted:
+		return "Payment was interrupted by maintenance and resumes shortly"
 	default:
 		return "Unknown status"
 	}
@@ -354,108 +722,142 @@ type StripeInitRequest struct {
 	Currency       string   `json:"currency"`
 	TargetCurrency string   `json:"target_currency"`
 	Route          []string `json:"route"`
+	QuoteID        string   `json:"quote_id,omitempty"` // From POST /api/v1/payments/quote; locks its exchange rates
+	TransactionID  string   `json:"transaction_id,omitempty"` // Pay for a transaction approved after a compliance hold instead
+	PayoutAccount  string   `json:"payout_account,omitempty"` // Recipient's Stripe Connect account, paid the final amount once settled
+	Provider       string   `json:"provider,omitempty"` // stripe | bank_transfer (default: the provider configured for the currency)
 }
 
 // StripeInitResponse represents response from Endpoint A
 type StripeInitResponse struct {
 	TransactionID   string                `json:"transaction_id"`
 	StripeClientSecret string             `json:"stripe_client_secret"`
-	StripePaymentID string                `json:"stripe_payment_id"`
+	StripePaymentID string                `json:"stripe_payment_id"` // The provider's payment ID, e.g. the bank transfer reference
+	Provider        string                `json:"provider"`
 	Transaction     *payments.Transaction `json:"transaction"`
 	FeeBreakdown    FeeBreakdown          `json:"fee_breakdown"`
 	PublishableKey  string                `json:"publishable_key"`
 	IsMockMode      bool                  `json:"is_mock_mode"`
+	HeldForReview   bool                  `json:"held_for_review,omitempty"` // No PaymentIntent is created until the hold is approved
 }
 
 // HandleStripeInitiate handles Endpoint A - Initiate Payment
 // User enters amount, selects route, gets Stripe client secret
 func (h *PaymentHandler) HandleStripeInitiate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	userID := getUserIDFromContext(r)
 	if userID == "" {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	var req StripeInitRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// Validate
-	if req.Amount <= 0 {
-		http.Error(w, `{"error":"amount must be positive"}`, http.StatusBadRequest)
+	// Validate (a quote or approved transaction supplies the amount and route)
+	if req.QuoteID == "" && req.TransactionID == "" && req.Amount <= 0 {
+		apierror.Respond(w, http.StatusBadRequest, "amount must be positive")
 		return
 	}
-	if len(req.Route) < 2 {
-		http.Error(w, `{"error":"route must have at least 2 countries"}`, http.StatusBadRequest)
+	if req.QuoteID == "" && req.TransactionID == "" && len(req.Route) < 2 {
+		apierror.Respond(w, http.StatusBadRequest, "route must have at least 2 countries")
 		return
 	}
-
-	// Create internal transaction
-	txn, err := h.txnStore.CreateTransaction(userID, req.Amount, req.Currency, req.TargetCurrency, req.Route, h.haltedNodes)
-	if err != nil {
-		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+	if req.PayoutAccount != "" && !payments.ValidPayoutAccount(req.PayoutAccount) {
+		apierror.Respond(w, http.StatusBadRequest, "payout_account must be a Stripe Connect account ID (acct_...)")
 		return
 	}
-
-	// Create Stripe PaymentIntent
-	amountCents := int64(req.Amount * 100) // Convert to cents
-	stripeReq := &payments.PaymentIntentRequest{
-		Amount:      amountCents,
-		Currency:    req.Currency,
-		Description: "PLM Transfer: " + req.Route[0] + " → " + req.Route[len(req.Route)-1],
-		Metadata: map[string]string{
-			"transaction_id": txn.ID,
-			"route":          req.Route[0] + "_to_" + req.Route[len(req.Route)-1],
-			"hops":           string(rune(len(req.Route) - 1)),
-		},
+	if req.Provider != "" {
+		if _, err := h.providers.Get(req.Provider); err != nil {
+			apierror.Respond(w, http.StatusBadRequest, "provider must be one of "+strings.Join(h.providers.Names(), ", "))
+			return
+		}
 	}
 
-	stripeResp, err := h.stripeClient.CreatePaymentIntent(stripeReq)
-	if err != nil {
-		log.Printf("Stripe error: %v", err)
-		http.Error(w, `{"error":"payment service unavailable"}`, http.StatusServiceUnavailable)
-		return
-	}
+	idempotencyKey := r.Header.Get("Idempotency-Key")
 
-	// Count halted nodes
-	haltCount := 0
-	for _, code := range req.Route {
-		if h.haltedNodes[code] {
-			haltCount++
+	h.runIdempotent(w, r, userID, req, func() (string, interface{}, error) {
+		// Create internal transaction
+		halted := h.currentHaltedNodes()
+		var txn *payments.Transaction
+		var err error
+		if req.TransactionID != "" {
+			txn, err = h.approvedTransaction(userID, req.TransactionID)
+		} else {
+			txn, err = h.newTransaction(r.Context(), userID, req.QuoteID, req.Amount, req.Currency, req.TargetCurrency, req.Route, halted)
+		}
+		if err != nil {
+			return "", nil, err
+		}
+		if req.PayoutAccount != "" {
+			if err := h.txnStore.SetPayoutAccount(txn.ID, req.PayoutAccount); err != nil {
+				return "", nil, apierror.New(apierror.CodeConflict, err.Error())
+			}
 		}
-	}
 
-	log.Printf("💳 [Endpoint A] Payment initiated: %s for $%.2f (Stripe: %s)", txn.ID, req.Amount, stripeResp.ID)
-
-	response := StripeInitResponse{
-		TransactionID:      txn.ID,
-		StripeClientSecret: stripeResp.ClientSecret,
-		StripePaymentID:    stripeResp.ID,
-		Transaction:        txn,
-		FeeBreakdown: FeeBreakdown{
-			BaseFee:     txn.BaseFee,
-			BaseFeeRate: "1.5%",
-			HopFees:     txn.HopFees,
-			HopFeeRate:  "0.02%",
-			HopCount:    len(req.Route) - 1,
-			HaltFines:   txn.HaltFines,
-			HaltCount:   haltCount,
-			TotalFees:   txn.TotalFees,
-			FinalAmount: txn.FinalAmount,
-		},
-		PublishableKey: h.stripeClient.GetPublishableKey(),
-		IsMockMode:     h.stripeClient.IsMockMode(),
-	}
+		provider, err := h.providers.Select(req.Provider, txn.Currency)
+		if err != nil {
+			return "", nil, apierror.New(apierror.CodeInvalidRequest, err.Error())
+		}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+		// Nothing is charged for a transfer that may still be rejected
+		if txn.Status == payments.StatusPendingReview {
+			slog.InfoContext(r.Context(), "stripe payment held for compliance review", "transaction_id", txn.ID, "reasons", txn.Review.Reasons)
+			return txn.ID, StripeInitResponse{
+				TransactionID:  txn.ID,
+				Provider:       provider.Name(),
+				Transaction:    txn,
+				FeeBreakdown:   newFeeBreakdown(txn, halted),
+				PublishableKey: provider.GetPublishableKey(),
+				IsMockMode:     provider.IsMockMode(),
+				HeldForReview:  true,
+			}, nil
+		}
+
+		// Create Stripe PaymentIntent
+		stripeReq := &payments.PaymentIntentRequest{
+			Amount:      payments.MinorUnits(txn.Amount, txn.Currency),
+			Currency:    txn.Currency,
+			Description: "PLM Transfer: " + txn.Route[0] + " → " + txn.Route[len(txn.Route)-1],
+			Metadata: map[string]string{
+				"transaction_id": txn.ID,
+				"route":          txn.Route[0] + "_to_" + txn.Route[len(txn.Route)-1],
+				"hops":           string(rune(len(txn.Route) - 1)),
+			},
+		}
+		if idempotencyKey != "" {
+			stripeReq.IdempotencyKey = userID + ":" + idempotencyKey
+		}
+
+		stripeResp, err := provider.CreatePaymentIntent(stripeReq)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "payment intent failed", "transaction_id", txn.ID, "provider", provider.Name(), "error", err)
+			return "", nil, apierror.New(apierror.CodeUnavailable, "payment service unavailable")
+		}
+
+		slog.InfoContext(r.Context(), "stripe payment initiated", "transaction_id", txn.ID, "amount", txn.Amount, "provider", provider.Name(), "payment_intent", stripeResp.ID)
+		h.txnStore.SetPaymentProvider(txn.ID, provider.Name())
+		h.txnStore.SetPaymentIntent(txn.ID, stripeResp.ID) // Lets the reconciler check an abandoned or unsettled payment
+
+		response := StripeInitResponse{
+			TransactionID:      txn.ID,
+			StripeClientSecret: stripeResp.ClientSecret,
+			StripePaymentID:    stripeResp.ID,
+			Provider:           provider.Name(),
+			Transaction:        txn,
+			FeeBreakdown:       newFeeBreakdown(txn, halted),
+			PublishableKey: provider.GetPublishableKey(),
+			IsMockMode:     provider.IsMockMode(),
+		}
+		return txn.ID, response, nil
+	})
 }
 
 // StripeCompleteRequest represents request to complete Stripe payment (Endpoint B)
@@ -470,53 +872,118 @@ type StripeCompleteResponse struct {
 	Transaction *payments.Transaction `json:"transaction"`
 	Message     string                `json:"message"`
 	ReceiptURL  string                `json:"receipt_url"`
+
+	// Set when the payer must authenticate (3-D Secure) before the payment
+	// is settled; call /api/v1/stripe/resume once they have
+	RequiresAction bool                 `json:"requires_action,omitempty"`
+	NextAction     *payments.NextAction `json:"next_action,omitempty"`
+	ClientSecret   string               `json:"client_secret,omitempty"` // For Stripe.js handleNextAction
 }
 
 // HandleStripeComplete handles Endpoint B - Complete Payment
 // Called after Stripe payment succeeds, processes through mesh
 func (h *PaymentHandler) HandleStripeComplete(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	userID := getUserIDFromContext(r)
 	if userID == "" {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
 	var req StripeCompleteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
+	h.completeStripe(w, r, userID, req)
+}
 
+// completeStripe settles a transaction through the mesh once its payment has
+// succeeded, or tells the payer what to do first
+func (h *PaymentHandler) completeStripe(w http.ResponseWriter, r *http.Request, userID string, req StripeCompleteRequest) {
 	// Verify transaction
 	txn, err := h.txnStore.GetTransaction(req.TransactionID)
 	if err != nil {
-		http.Error(w, `{"error":"transaction not found"}`, http.StatusNotFound)
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
 		return
 	}
-	if txn.UserID != userID && userID != "demo-user" {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+	if txn.UserID != userID {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	if txn.PaymentIntentID != "" && txn.PaymentIntentID != req.StripePaymentID {
+		apierror.Respond(w, http.StatusBadRequest, "stripe_payment_id does not belong to the transaction")
 		return
 	}
 
-	// Verify Stripe payment (in mock mode, this always succeeds)
-	stripeStatus, err := h.stripeClient.ConfirmPaymentIntent(req.StripePaymentID)
+	// Verify the payment with the provider that collected it (in mock mode, this always succeeds)
+	provider, err := h.providers.Get(txn.CollectedBy())
+	if err != nil {
+		apierror.Respond(w, http.StatusConflict, err.Error())
+		return
+	}
+	stripeStatus, err := provider.ConfirmPaymentIntent(req.StripePaymentID)
 	if err != nil {
-		http.Error(w, `{"error":"payment verification failed"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "payment verification failed")
 		return
 	}
 
-	// Check if payment succeeded
-	if stripeStatus.Status != "succeeded" && !h.stripeClient.IsMockMode() {
-		http.Error(w, `{"error":"payment not completed: `+stripeStatus.Status+`"}`, http.StatusPaymentRequired)
+	// Only a payment that succeeded is settled
+	if stripeStatus.Status != payments.IntentSucceeded && !provider.IsMockMode() {
+		h.respondPaymentPending(w, r, txn, stripeStatus)
 		return
 	}
 
-	log.Printf("💳 [Endpoint B] Processing payment %s through mesh...", txn.ID)
+	if settled := h.settleStripePayment(r.Context(), txn.ID, req.StripePaymentID); settled != nil {
+		txn = settled
+	}
+
+	response := StripeCompleteResponse{
+		Success:     txn.Status == payments.StatusSuccess,
+		Transaction: txn,
+		Message:     getStatusMessage(txn.Status, txn.FailedAt),
+		ReceiptURL:  "/api/v1/receipts/" + txn.ID,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// settleStripePayment runs a paid Stripe transaction through the mesh with
+// anti-fragility re-routing, refunding the card if every route fails.
+// Safe to call from both Endpoint B and the Stripe webhook: a transaction
+// that has already been processed is returned as-is.
+func (h *PaymentHandler) settleStripePayment(ctx context.Context, txnID, stripePaymentID string) *payments.Transaction {
+	ctx, unlock, err := h.txnStore.LockTransaction(ctx, txnID)
+	if err != nil {
+		// Still being settled by the other caller (possibly on another instance)
+		slog.WarnContext(ctx, "stripe payment not settled here", "transaction_id", txnID, "error", err)
+		txn, _ := h.txnStore.GetTransaction(txnID)
+		return txn
+	}
+	defer unlock()
+
+	txn, err := h.txnStore.GetTransaction(txnID)
+	if err != nil {
+		return nil
+	}
+	if txn.Status != payments.StatusPending || len(txn.Attempts) > 0 {
+		return txn
+	}
+	h.txnStore.SetPaymentIntent(txnID, stripePaymentID)
+	return h.settleStripeRoutes(ctx, txn, stripePaymentID)
+}
+
+// settleStripeRoutes settles a paid transaction, retrying on alternative
+// routes and refunding the payment if every route fails. The caller holds
+// the transaction's processing lock.
+func (h *PaymentHandler) settleStripeRoutes(ctx context.Context, txn *payments.Transaction, stripePaymentID string) *payments.Transaction {
+	txnID := txn.ID
+	slog.InfoContext(ctx, "settling stripe payment through mesh", "transaction_id", txn.ID)
 
 	// ANTI-FRAGILITY: Try up to 3 alternative routes
 	const maxRetries = 3
@@ -525,6 +992,18 @@ func (h *PaymentHandler) HandleStripeComplete(w http.ResponseWriter, r *http.Req
 	
 	// Get alternative routes from country graph (Yen's algorithm paths)
 	alternativeRoutes := h.getAlternativeRoutes(txn.Route)
+
+	// Skip alternatives through nodes whose circuit breaker is open
+	available := alternativeRoutes[:0]
+	for _, route := range alternativeRoutes {
+		if blocked := h.txnStore.OpenCircuit(ctx, route); blocked != "" {
+			slog.InfoContext(ctx, "skipping route with open circuit", "transaction_id", txn.ID, "route", route, "node", blocked)
+			continue
+		}
+		available = append(available, route)
+	}
+	alternativeRoutes = available
+	h.txnStore.SetCandidateRoutes(txn.ID, append([][]string{txn.Route}, alternativeRoutes...))
 	
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		// Select route for this attempt
@@ -532,66 +1011,206 @@ func (h *PaymentHandler) HandleStripeComplete(w http.ResponseWriter, r *http.Req
 			usedRoute = txn.Route // Original path
 		} else if attempt-1 < len(alternativeRoutes) {
 			usedRoute = alternativeRoutes[attempt-1]
-			log.Printf("🔄 [Anti-Fragility] Attempt %d: Re-routing via alternative path: %v", attempt, usedRoute)
+			slog.InfoContext(ctx, "re-routing via alternative path", "transaction_id", txn.ID, "attempt", attempt, "route", usedRoute)
 		} else {
-			log.Printf("⚠️ [Anti-Fragility] No more alternative routes available")
+			slog.WarnContext(ctx, "no more alternative routes", "transaction_id", txn.ID)
 			break
 		}
 		
 		// Process through mesh
-		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
-		lastError = h.txnStore.ProcessTransactionWithRoute(ctx, req.TransactionID, usedRoute, h.fxRates, 0.15) // 85% success per attempt
+		attemptCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		lastError = h.txnStore.ProcessTransactionWithRoute(attemptCtx, txnID, usedRoute, h.currentFXRates(), 0.15) // 85% success per attempt
 		cancel()
 		
 		// Get updated transaction
-		txn, _ = h.txnStore.GetTransaction(req.TransactionID)
+		txn, _ = h.txnStore.GetTransaction(txnID)
+		
+		if errors.Is(lastError, payments.ErrShuttingDown) {
+			// Resumed (or refunded) on the next startup
+			slog.WarnContext(ctx, "settlement interrupted by shutdown", "transaction_id", txnID, "attempt", attempt)
+			return txn
+		}
 		
 		if lastError == nil && txn.Status == payments.StatusSuccess {
-			log.Printf("✅ [Endpoint B] Payment %s completed on attempt %d: Admin profit $%.2f", txn.ID, attempt, txn.AdminProfit)
+			slog.InfoContext(ctx, "payment completed", "transaction_id", txn.ID, "attempt", attempt, "admin_profit", txn.AdminProfit)
 			break
 		}
 		
-		log.Printf("⚠️ [Anti-Fragility] Attempt %d failed: %v - notifying user of delay", attempt, lastError)
+		slog.WarnContext(ctx, "settlement attempt failed", "transaction_id", txnID, "attempt", attempt, "error", lastError)
 		
 		// Reset transaction status for retry if not final attempt
 		if attempt < maxRetries {
-			h.txnStore.ResetTransactionForRetry(req.TransactionID)
+			h.txnStore.ResetTransactionForRetry(txnID)
 		}
 	}
 	
 	// If all retries failed, trigger Stripe refund
 	if txn.Status != payments.StatusSuccess {
-		log.Printf("❌ [Anti-Fragility] All %d attempts failed for payment %s - initiating refund", maxRetries, txn.ID)
+		slog.ErrorContext(ctx, "all settlement attempts failed, refunding", "transaction_id", txn.ID, "attempts", maxRetries)
 		
-		refund, refundErr := h.stripeClient.RefundPayment(
-			req.StripePaymentID,
-			int64(txn.Amount*100),
-			"anti_fragility_all_routes_failed",
-		)
+		refund, refundErr := h.refund(txn, stripePaymentID, payments.RefundMeshFailure, "anti_fragility_all_routes_failed")
 		
 		if refundErr != nil {
-			log.Printf("❌ [Refund] Failed to process refund: %v", refundErr)
+			slog.ErrorContext(ctx, "refund failed", "transaction_id", txnID, "error", refundErr)
 		} else {
-			log.Printf("💰 [Refund] Refund processed: %s - Amount: $%.2f", refund.ID, float64(refund.Amount)/100)
-			h.txnStore.MarkAsRefunded(req.TransactionID, refund.ID)
+			slog.InfoContext(ctx, "refund processed", "transaction_id", txnID, "refund_id", refund.ID, "amount", refund.Amount)
+			h.txnStore.MarkAsRefunded(txnID, refund)
 		}
 	}
 
-	response := StripeCompleteResponse{
-		Success:     txn.Status == payments.StatusSuccess,
-		Transaction: txn,
-		Message:     getStatusMessage(txn.Status, txn.FailedAt),
-		ReceiptURL:  "/api/v1/receipts/" + txn.ID,
+	return txn
+}
+
+// ResumeInterrupted settles, in the background, the transactions a previous
+// shutdown interrupted: the hops they settled are reversed and they are
+// processed again, and Stripe payments that then fail on every route are
+// refunded. Returns how many are being resumed.
+func (h *PaymentHandler) ResumeInterrupted(ctx context.Context) int {
+	interrupted := h.txnStore.InterruptedTransactions()
+	for _, txn := range interrupted {
+		go h.resumeInterrupted(ctx, txn.ID)
+	}
+	return len(interrupted)
+}
+
+func (h *PaymentHandler) resumeInterrupted(ctx context.Context, txnID string) {
+	ctx, unlock, err := h.txnStore.LockTransaction(ctx, txnID)
+	if err != nil {
+		slog.WarnContext(ctx, "interrupted transaction not resumed here", "transaction_id", txnID, "error", err)
+		return
+	}
+	defer unlock()
+
+	if err := h.txnStore.ResumeInterrupted(ctx, txnID); err != nil {
+		slog.ErrorContext(ctx, "failed to resume interrupted transaction", "transaction_id", txnID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "resuming interrupted transaction", "transaction_id", txnID)
+	h.resettle(ctx, txnID)
+}
+
+// resettle settles a requeued transaction again, through the Stripe path
+// (refunding if every route fails) when it was paid by card. The caller
+// holds the transaction's processing lock.
+func (h *PaymentHandler) resettle(ctx context.Context, txnID string) {
+	txn, err := h.txnStore.GetTransaction(txnID)
+	if err != nil {
+		return
+	}
+	if txn.PaymentIntentID != "" {
+		h.settleStripeRoutes(ctx, txn, txn.PaymentIntentID)
+		return
+	}
+	if err := h.txnStore.ProcessTransaction(ctx, txnID, h.currentFXRates(), 0.05); err != nil {
+		slog.WarnContext(ctx, "resumed transaction failed", "transaction_id", txnID, "error", err)
+	}
+}
+
+// maxStripeWebhookBytes bounds the webhook body read (Stripe events are well under this)
+const maxStripeWebhookBytes = 65536
+
+// HandleStripeWebhook handles POST /api/v1/stripe/webhook
+// Verifies the Stripe-Signature header and updates the matching transaction:
+// payment_intent.succeeded settles it through the mesh, payment_intent.payment_failed
+// marks it failed and charge.refunded marks it refunded.
+func (h *PaymentHandler) HandleStripeWebhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(r.Body, maxStripeWebhookBytes))
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, "failed to read body")
+		return
+	}
+
+	event, err := h.stripeClient.ParseWebhookEvent(payload, r.Header.Get("Stripe-Signature"))
+	if errors.Is(err, payments.ErrWebhookNotConfigured) {
+		apierror.Respond(w, http.StatusServiceUnavailable, "stripe webhook not configured")
+		return
+	}
+	if err != nil {
+		slog.WarnContext(r.Context(), "stripe webhook rejected", "error", err)
+		apierror.Respond(w, http.StatusBadRequest, "invalid signature")
+		return
+	}
+
+	// Acknowledge events we don't act on so Stripe stops retrying them
+	if event.TransactionID == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	txn, err := h.txnStore.GetTransaction(event.TransactionID)
+	if err != nil {
+		slog.WarnContext(r.Context(), "stripe webhook for unknown transaction", "event_type", event.Type, "transaction_id", event.TransactionID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "stripe webhook received", "event_type", event.Type, "transaction_id", txn.ID, "event_id", event.ID)
+
+	switch event.Type {
+	case payments.StripeEventPaymentSucceeded:
+		// Settle in the background; Stripe expects a prompt 2xx
+		go h.settleStripePayment(logging.Detach(r.Context()), txn.ID, event.PaymentIntentID)
+
+	case payments.StripeEventPaymentFailed:
+		reason := event.FailureMessage
+		if reason == "" {
+			reason = "card payment failed"
+		}
+		h.txnStore.MarkPaymentFailed(txn.ID, reason)
+
+	case payments.StripeEventChargeRefunded:
+		if txn.RefundID() == "" {
+			refund := payments.Refund{ID: event.RefundID, Reason: payments.RefundExternal, Amount: txn.Amount, Currency: txn.Currency}
+			if event.RefundAmount > 0 {
+				refund.Amount = float64(event.RefundAmount) / 100
+				refund.Retained = math.Max(txn.Amount-refund.Amount, 0)
+			}
+			h.txnStore.MarkAsRefunded(txn.ID, refund)
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleTransactionTrace handles GET /api/{version}/admin/transactions/{id}/trace,
+// registered with the {id} wildcard.
+// Returns the candidate routes, chosen route with edge weights, hop results and retry timeline
+func (h *PaymentHandler) HandleTransactionTrace(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	txnID := r.PathValue("id")
+	if txnID == "" {
+		apierror.Respond(w, http.StatusBadRequest, "transaction id required")
+		return
+	}
+
+	var weightFn payments.EdgeWeightFunc
+	if h.countryGraph != nil {
+		weightFn = h.countryGraph.EdgeWeightBetween
+	}
+
+	trace, err := h.txnStore.GetTrace(txnID, weightFn)
+	if err != nil {
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(trace)
 }
 
 // HandleStripeConfig returns Stripe configuration for frontend
 func (h *PaymentHandler) HandleStripeConfig(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -600,19 +1219,20 @@ func (h *PaymentHandler) HandleStripeConfig(w http.ResponseWriter, r *http.Reque
 		"publishable_key": h.stripeClient.GetPublishableKey(),
 		"is_test_mode":    h.stripeClient.IsTestMode(),
 		"is_mock_mode":    h.stripeClient.IsMockMode(),
+		"providers":       h.providers.Names(),
 	})
 }
 
 // HandleChartData returns transaction data formatted for Chart.js
 func (h *PaymentHandler) HandleChartData(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	userID := getUserIDFromContext(r)
 	if userID == "" {
-		http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 		return
 	}
 
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/compliance"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/scheduler"
+)
+
+// withUser returns req as Authenticate passes it on for user
+func withUser(req *http.Request, user *auth.User) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+}
+
+// TestGetTransactionOnlyOwnerOrAdminView verifies a transaction is shown to
+// its owner, and to staff only when they ask for the audited admin view
+func TestGetTransactionOnlyOwnerOrAdminView(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	txn, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	h := NewPaymentHandler(txns, nil)
+	trail := audit.NewMemoryStore(10)
+	h.SetAuditStore(trail)
+
+	owner := &auth.User{ID: "user1", Username: "owner", Role: auth.RoleUser}
+	other := &auth.User{ID: "user2", Username: "other", Role: auth.RoleUser}
+	admin := &auth.User{ID: "admin1", Username: "admin", Role: auth.RoleAdmin}
+
+	get := func(user *auth.User, query string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/transaction?id="+txn.ID+query, nil)
+		if user != nil {
+			req = withUser(req, user)
+		}
+		rec := httptest.NewRecorder()
+		h.HandleGetTransaction(rec, req)
+		return rec.Code
+	}
+
+	for _, tc := range []struct {
+		name  string
+		user  *auth.User
+		query string
+		want  int
+	}{
+		{"unauthenticated", nil, "", http.StatusUnauthorized},
+		{"owner", owner, "", http.StatusOK},
+		{"other user", other, "", http.StatusNotFound},
+		{"other user as admin", other, "&admin=true", http.StatusForbidden},
+		{"admin without admin view", admin, "", http.StatusNotFound},
+		{"malformed admin view", admin, "&admin=yes", http.StatusBadRequest},
+		{"admin view", admin, "&admin=true", http.StatusOK},
+	} {
+		if got := get(tc.user, tc.query); got != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, got, tc.want)
+		}
+	}
+
+	entries, err := trail.List(context.Background(), audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Action != "transaction.view" || entries[0].ResourceID != txn.ID || entries[0].ActorID != "admin1" {
+		t.Errorf("audit = %+v, want one transaction.view by admin", entries)
+	}
+}
+
+// TestScheduledPaymentsAreScreened verifies scheduled payments go through
+// compliance screening: denied ones are not created, flagged ones are held
+func TestScheduledPaymentsAreScreened(t *testing.T) {
+	ctx := context.Background()
+	txns := payments.NewTransactionStore()
+	h := NewPaymentHandler(txns, nil)
+	h.SetScreener(compliance.NewRuleScreener(compliance.Config{DeniedCountries: []string{"RUS"}, ReviewThreshold: 1000}))
+
+	route := func(ctx context.Context, source, target string) ([]string, error) {
+		return []string{source, target}, nil
+	}
+	s := scheduler.NewScheduler(scheduler.NewMemoryStore(), txns, route, nil)
+	s.SetCreator(h.CreateScreenedTransaction)
+
+	denied := &scheduler.Schedule{UserID: "user1", Source: "USA", Target: "RUS", Amount: 100, Frequency: scheduler.FrequencyOnce, StartAt: time.Now()}
+	large := &scheduler.Schedule{UserID: "user1", Source: "USA", Target: "GBR", Amount: 5000, Frequency: scheduler.FrequencyOnce, StartAt: time.Now()}
+	for _, sched := range []*scheduler.Schedule{denied, large} {
+		if err := s.Create(ctx, sched); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+	s.RunDue(ctx)
+
+	got, _ := s.Get(ctx, "user1", denied.ID)
+	if got.Status != scheduler.StatusFailed || !strings.Contains(got.LastError, "deny list") || got.LastTransactionID != "" {
+		t.Errorf("denied schedule = %s (%q), want failed on the deny list without a payment", got.Status, got.LastError)
+	}
+	got, _ = s.Get(ctx, "user1", large.ID)
+	txn, err := txns.GetTransaction(got.LastTransactionID)
+	if err != nil || txn.Status != payments.StatusPendingReview || len(txn.Attempts) != 0 {
+		t.Errorf("large scheduled payment = %+v (%v), want held for review and not settled", txn, err)
+	}
+	if n := len(txns.GetAllTransactions()); n != 1 {
+		t.Errorf("transactions = %d, want only the held one", n)
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// SetProviders sets the providers payments can be collected through
+// (defaults to the Stripe client alone)
+func (h *PaymentHandler) SetProviders(providers *payments.Providers) {
+	h.providers = providers
+}
+
+// refund returns a payment's funds through the provider that collected it,
+// as much of them as the refund policy allows for reason
+func (h *PaymentHandler) refund(txn *payments.Transaction, paymentIntentID string, reason payments.RefundReason, note string) (payments.Refund, error) {
+	refund := h.refundPolicy.Refund(txn, reason)
+	provider, err := h.providers.Get(txn.CollectedBy())
+	if err != nil {
+		return refund, err
+	}
+	resp, err := provider.RefundPayment(paymentIntentID, refund.MinorAmount(), note)
+	if err != nil {
+		return refund, err
+	}
+	refund.ID = resp.ID
+	return refund, nil
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/compliance"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// SetQuoteSigner replaces the quote signer (defaults to a random key and
+// payments.DefaultQuoteTTL)
+func (h *PaymentHandler) SetQuoteSigner(signer *payments.QuoteSigner) {
+	h.quotes = signer
+}
+
+// QuoteRequest asks for a quote. Without a route, the best route from
+// source to target is used.
+type QuoteRequest struct {
+	Amount         float64  `json:"amount"`
+	Currency       string   `json:"currency"`
+	TargetCurrency string   `json:"target_currency"`
+	Source         string   `json:"source,omitempty"` // Country codes (ignored when route is set)
+	Target         string   `json:"target,omitempty"`
+	Route          []string `json:"route,omitempty"`
+}
+
+// validate checks the request fields: a route, or source and target to route between
+func (req *QuoteRequest) validate(known func(string) bool) error {
+	var v validate.Validator
+	v.Amount("amount", req.Amount, req.Currency)
+	v.Currency("currency", req.Currency)
+	v.Currency("target_currency", req.TargetCurrency)
+	if len(req.Route) > 0 {
+		v.Route("route", req.Route, known)
+	} else {
+		v.Country("source", strings.ToUpper(req.Source), known)
+		v.Country("target", strings.ToUpper(req.Target), known)
+	}
+	return v.Err()
+}
+
+// QuoteResponse is a signed quote with its fee breakdown
+type QuoteResponse struct {
+	*payments.Quote
+	TTLSeconds   int          `json:"ttl_seconds"`
+	FeeBreakdown FeeBreakdown `json:"fee_breakdown"`
+}
+
+// HandleQuote handles POST /api/v1/payments/quote.
+// Pass the returned quote_id to /api/v1/payments/create or
+// /api/v1/stripe/initiate to settle at the quoted exchange rates until
+// expires_at; after that the payment settles at current rates.
+func (h *PaymentHandler) HandleQuote(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req QuoteRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := req.validate(h.knownCountry()); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	route := req.Route
+	if len(route) == 0 {
+		best, err := router.NewCountryRouter(h.countryGraph, 1).BestRoute(r.Context(), strings.ToUpper(req.Source), strings.ToUpper(req.Target))
+		if err != nil {
+			apierror.Respond(w, http.StatusNotFound, "no route found")
+			return
+		}
+		route = best
+	}
+
+	halted := h.currentHaltedNodes()
+	preview, err := h.txnStore.PreviewTransaction(userID, req.Amount, req.Currency, req.TargetCurrency, route, halted)
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rates := h.currentFXRates()
+	finalAmount, fxRate := payments.EstimateSettlement(preview, rates)
+	quote := &payments.Quote{
+		UserID:         userID,
+		Amount:         req.Amount,
+		Currency:       req.Currency,
+		TargetCurrency: req.TargetCurrency,
+		Route:          route,
+		FXRates:        payments.LockedRates(route, rates),
+		FXRate:         fxRate,
+		TotalFees:      preview.TotalFees,
+		FinalAmount:    finalAmount,
+	}
+	h.quotes.Issue(quote, time.Now())
+
+	slog.InfoContext(r.Context(), "payment quoted", "route", route, "amount", req.Amount,
+		"fx_rate", fxRate, "final_amount", finalAmount, "expires_at", quote.ExpiresAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(QuoteResponse{
+		Quote:        quote,
+		TTLSeconds:   int(h.quotes.TTL().Seconds()),
+		FeeBreakdown: newFeeBreakdown(preview, halted),
+	})
+}
+
+// createQuotedTransaction creates a transaction from a quote ID. Fields set
+// on the request must match the quote.
+func (h *PaymentHandler) createQuotedTransaction(ctx context.Context, userID, quoteID string, amount float64, currency, targetCurrency string, route []string, halted map[string]bool) (*payments.Transaction, error) {
+	quote, err := h.quotes.Verify(quoteID, time.Now())
+	if errors.Is(err, payments.ErrQuoteExpired) {
+		return nil, apierror.New(apierror.CodeQuoteExpired, "quote expired")
+	}
+	if err != nil {
+		return nil, apierror.New(apierror.CodeInvalidRequest, "invalid quote")
+	}
+	if quote.UserID != userID ||
+		(amount != 0 && amount != quote.Amount) ||
+		(currency != "" && currency != quote.Currency) ||
+		(targetCurrency != "" && targetCurrency != quote.TargetCurrency) ||
+		(len(route) > 0 && !slices.Equal(route, quote.Route)) {
+		return nil, apierror.New(apierror.CodeInvalidRequest, "quote does not match request")
+	}
+	release, err := h.reserveLimits(ctx, userID, h.txnStore.InVolumeCurrency(quote.Amount, quote.Currency))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	screening, err := h.screen(ctx, &compliance.Request{UserID: userID, Amount: quote.Amount, Currency: quote.Currency, Route: quote.Route})
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := h.txnStore.CreateQuotedTransaction(userID, quote, halted)
+	if err != nil {
+		return nil, creationError(err)
+	}
+	if err := h.holdIfFlagged(ctx, txn, screening); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// ResumeStuck requeues a stuck transaction, compensating the hops its
+// stalled run settled, and settles it again
+func (h *PaymentHandler) ResumeStuck(ctx context.Context, txnID string) error {
+	ctx, unlock, err := h.txnStore.LockTransaction(ctx, txnID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := h.txnStore.RequeueStuck(ctx, txnID); err != nil {
+		return err
+	}
+	h.resettle(ctx, txnID)
+	return nil
+}
+
+// FailStuck fails a stuck transaction, refunding its Stripe payment when
+// refund is set
+func (h *PaymentHandler) FailStuck(ctx context.Context, txnID, reason string, refund bool) error {
+	ctx, unlock, err := h.txnStore.LockTransaction(ctx, txnID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := h.txnStore.RequeueStuck(ctx, txnID); err != nil {
+		return err
+	}
+	h.txnStore.MarkPaymentFailed(txnID, reason)
+	if !refund {
+		return nil
+	}
+	txn, err := h.txnStore.GetTransaction(txnID)
+	if err != nil {
+		return err
+	}
+	return h.refundStuck(ctx, txn, payments.RefundMeshFailure, "stuck_payment_not_settled")
+}
+
+// EscalateStuck holds a stuck transaction in the admin review queue
+func (h *PaymentHandler) EscalateStuck(ctx context.Context, txnID string, reasons []string) error {
+	return h.txnStore.EscalateStuck(ctx, txnID, reasons)
+}
+
+// refundStuck refunds the Stripe payment of a stuck transaction that won't be settled
+func (h *PaymentHandler) refundStuck(ctx context.Context, txn *payments.Transaction, reason payments.RefundReason, note string) error {
+	refund, err := h.refund(txn, txn.PaymentIntentID, reason, note)
+	if err != nil {
+		return fmt.Errorf("refund failed: %w", err)
+	}
+	slog.InfoContext(ctx, "stuck payment refunded", "transaction_id", txn.ID, "refund_id", refund.ID, "amount", refund.Amount)
+	h.txnStore.MarkAsRefunded(txn.ID, refund)
+	return nil
+}
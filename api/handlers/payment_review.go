@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/compliance"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+// ReviewListResponse is returned by GET /api/v1/admin/reviews
+type ReviewListResponse struct {
+	Transactions []*payments.Transaction `json:"transactions"` // Oldest first
+	Count        int                     `json:"count"`
+}
+
+// ResolveReviewRequest is the body of POST /api/v1/admin/reviews/{id}/{approve|reject}
+type ResolveReviewRequest struct {
+	Note string `json:"note"`
+}
+
+// SetScreener screens new payments before they are created. Denied payments
+// are refused; payments flagged for review are created but held.
+func (h *PaymentHandler) SetScreener(screener compliance.Screener) {
+	h.screener = screener
+}
+
+// SetAuditStore records compliance review decisions in store
+func (h *PaymentHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// screen runs the compliance screener, returning a 403 apierror.Error for
+// denied payments. The result is nil when no screener is configured.
+func (h *PaymentHandler) screen(ctx context.Context, req *compliance.Request) (*compliance.Result, error) {
+	if h.screener == nil {
+		return nil, nil
+	}
+	result, err := h.screener.Screen(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := result.Err(); err != nil {
+		slog.WarnContext(ctx, "payment denied by compliance screening", "user_id", req.UserID, "route", req.Route, "reasons", result.Reasons)
+		return nil, apierror.New(apierror.CodeForbidden, err.Error())
+	}
+	return result, nil
+}
+
+// holdIfFlagged holds a newly created transaction whose screening asked for review
+func (h *PaymentHandler) holdIfFlagged(ctx context.Context, txn *payments.Transaction, screening *compliance.Result) error {
+	if screening == nil || screening.Decision != compliance.Review {
+		return nil
+	}
+	if err := h.txnStore.HoldForReview(txn.ID, screening.Reasons); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "payment held for compliance review", "transaction_id", txn.ID, "reasons", screening.Reasons)
+	return nil
+}
+
+// approvedTransaction returns the caller's transaction that was approved
+// after a compliance hold and has not been paid yet
+func (h *PaymentHandler) approvedTransaction(userID, txnID string) (*payments.Transaction, error) {
+	txn, err := h.txnStore.GetTransaction(txnID)
+	if err != nil || txn.UserID != userID {
+		return nil, apierror.New(apierror.CodeNotFound, "transaction not found")
+	}
+	if txn.Review == nil || txn.Review.Decision != payments.ReviewApproved || txn.Status != payments.StatusPending {
+		return nil, apierror.New(apierror.CodeConflict, "transaction is not awaiting payment after approval")
+	}
+	return txn, nil
+}
+
+// HandleReviews handles the compliance review queue:
+//
+//	GET  /api/v1/admin/reviews                - transactions held for review
+//	POST /api/v1/admin/reviews/{id}/approve   - release to pending
+//	POST /api/v1/admin/reviews/{id}/reject    - fail the transaction
+//
+// Approved payments are completed by the payer as usual (confirm, or Stripe
+// initiate with transaction_id); approved batch payments are processed
+// straight away since batches have no confirmation step.
+func (h *PaymentHandler) HandleReviews(w http.ResponseWriter, r *http.Request) {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/reviews"), "/")
+	if path == "" {
+		if r.Method != http.MethodGet {
+			apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+		held := h.txnStore.HeldTransactions()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ReviewListResponse{Transactions: held, Count: len(held)})
+		return
+	}
+
+	txnID, action, _ := strings.Cut(path, "/")
+	var decision payments.ReviewDecision
+	switch action {
+	case "approve":
+		decision = payments.ReviewApproved
+	case "reject":
+		decision = payments.ReviewRejected
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+		return
+	}
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req ResolveReviewRequest
+	if r.ContentLength != 0 {
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if decision == payments.ReviewRejected && strings.TrimSpace(req.Note) == "" {
+		apierror.Respond(w, http.StatusBadRequest, "a note is required to reject a payment")
+		return
+	}
+
+	before, err := h.txnStore.GetTransaction(txnID)
+	if err != nil {
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
+		return
+	}
+	held := before.Review
+	reviewer := actorName(r)
+	txn, err := h.txnStore.ResolveReview(txnID, decision, reviewer, req.Note)
+	if errors.Is(err, payments.ErrNotHeld) {
+		apierror.Respond(w, http.StatusConflict, "transaction is not held for review")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to resolve compliance review", "transaction_id", txnID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to resolve review")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "compliance review resolved", "transaction_id", txnID, "decision", decision, "reviewer", reviewer)
+	recordAudit(h.audit, r, http.StatusOK, "transaction.review_"+action, "transaction", txnID, held, txn.Review)
+
+	if held.Escalated {
+		// Stuck payments an admin approved are settled again; rejected card
+		// payments are refunded
+		go func(ctx context.Context) {
+			var err error
+			if decision == payments.ReviewApproved {
+				err = h.ResumeStuck(ctx, txnID)
+			} else if txn.PaymentIntentID != "" {
+				err = h.refundStuck(ctx, txn, payments.RefundRejected, "stuck_payment_rejected")
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "escalated payment not recovered", "transaction_id", txnID, "decision", decision, "error", err)
+			}
+		}(logging.Detach(r.Context()))
+	} else if decision == payments.ReviewApproved && txn.BatchID != "" {
+		go func(ctx context.Context) {
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			if err := h.settle(ctx, txnID); err != nil {
+				slog.WarnContext(ctx, "approved batch payment failed", "batch_id", txn.BatchID, "transaction_id", txnID, "error", err)
+			}
+		}(logging.Detach(r.Context()))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(txn)
+}
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// HandleStripeResume handles POST /api/v1/stripe/resume, called when the
+// payer returns from authenticating a payment (3-D Secure) that Endpoint B
+// answered with requires_action. The payment is settled through the mesh
+// once Stripe reports it succeeded.
+func (h *PaymentHandler) HandleStripeResume(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	var req StripeCompleteRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	var v validate.Validator
+	v.Required("transaction_id", req.TransactionID)
+	v.Required("stripe_payment_id", req.StripePaymentID)
+	if err := v.Err(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "resuming payment after authentication", "transaction_id", req.TransactionID, "payment_intent", req.StripePaymentID)
+	h.completeStripe(w, r, userID, req)
+}
+
+// respondPaymentPending answers Endpoint B or the resume endpoint for a
+// payment that has not succeeded, telling the payer what happens next
+func (h *PaymentHandler) respondPaymentPending(w http.ResponseWriter, r *http.Request, txn *payments.Transaction, intent *payments.PaymentIntentResponse) {
+	var response StripeCompleteResponse
+	switch {
+	case intent.RequiresAction():
+		slog.InfoContext(r.Context(), "payment awaiting authentication", "transaction_id", txn.ID, "payment_intent", intent.ID, "status", intent.Status)
+		response = StripeCompleteResponse{
+			Transaction:    txn,
+			Message:        "authentication required",
+			RequiresAction: true,
+			NextAction:     intent.NextAction,
+			ClientSecret:   intent.ClientSecret,
+		}
+	case intent.Status == payments.IntentProcessing:
+		response = StripeCompleteResponse{
+			Transaction: txn,
+			Message:     "payment is processing; it is settled once Stripe confirms it",
+		}
+	case intent.Status == payments.IntentRequiresPaymentMethod:
+		apierror.Respond(w, http.StatusPaymentRequired, "payment was declined or authentication failed; try another payment method")
+		return
+	default:
+		apierror.Respond(w, http.StatusPaymentRequired, "payment not completed: "+intent.Status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
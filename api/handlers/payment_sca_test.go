@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// scaProvider is a real-mode Stripe stand-in whose intents report status
+type scaProvider struct {
+	status     string
+	nextAction *payments.NextAction
+}
+
+func (p *scaProvider) Name() string              { return payments.ProviderStripe }
+func (p *scaProvider) GetPublishableKey() string { return "pk_test" }
+func (p *scaProvider) IsMockMode() bool          { return false }
+
+func (p *scaProvider) CreatePaymentIntent(req *payments.PaymentIntentRequest) (*payments.PaymentIntentResponse, error) {
+	return &payments.PaymentIntentResponse{ID: "pi_1", Status: "requires_payment_method"}, nil
+}
+
+func (p *scaProvider) ConfirmPaymentIntent(id string) (*payments.PaymentIntentResponse, error) {
+	return &payments.PaymentIntentResponse{ID: id, ClientSecret: id + "_secret", Status: p.status, NextAction: p.nextAction}, nil
+}
+
+func (p *scaProvider) GetPaymentIntent(id string) (*payments.PaymentIntentResponse, error) {
+	return &payments.PaymentIntentResponse{ID: id, Status: p.status}, nil
+}
+
+func (p *scaProvider) RefundPayment(id string, amount int64, reason string) (*payments.RefundResponse, error) {
+	return &payments.RefundResponse{ID: "re_" + id, PaymentIntentID: id, Amount: amount, Status: "succeeded"}, nil
+}
+
+// TestStripeCompleteRequiresAuthentication verifies a payment waiting for
+// 3-D Secure is not settled, and the payer is sent to authenticate
+func TestStripeCompleteRequiresAuthentication(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	txn, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	txns.SetPaymentIntent(txn.ID, "pi_1")
+	provider := &scaProvider{
+		status:     payments.IntentRequiresAction,
+		nextAction: &payments.NextAction{Type: "redirect_to_url", RedirectURL: "https://hooks.stripe.com/3ds"},
+	}
+	h := NewPaymentHandler(txns, nil)
+	h.SetProviders(payments.NewProviders(provider))
+
+	post := func(handler http.HandlerFunc, paymentID string) *httptest.ResponseRecorder {
+		body := `{"transaction_id": "` + txn.ID + `", "stripe_payment_id": "` + paymentID + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/stripe/complete", strings.NewReader(body))
+		req = withUser(req, &auth.User{ID: "user1", Role: auth.RoleUser})
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := post(h.HandleStripeComplete, "pi_1")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want 202: %s", rec.Code, rec.Body)
+	}
+	var resp StripeCompleteResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Success || !resp.RequiresAction || resp.NextAction == nil || resp.NextAction.RedirectURL == "" || resp.ClientSecret != "pi_1_secret" {
+		t.Errorf("response = %+v, want requires_action with a redirect", resp)
+	}
+	if got, _ := txns.GetTransaction(txn.ID); got.Status != payments.StatusPending || len(got.Attempts) != 0 {
+		t.Errorf("status = %s with %d attempts, want pending and unsettled", got.Status, len(got.Attempts))
+	}
+
+	// Failed authentication asks for another payment method
+	provider.status, provider.nextAction = payments.IntentRequiresPaymentMethod, nil
+	if rec := post(h.HandleStripeResume, "pi_1"); rec.Code != http.StatusPaymentRequired {
+		t.Errorf("failed authentication: status = %d, want 402", rec.Code)
+	}
+
+	// Another transaction's intent is refused
+	if rec := post(h.HandleStripeResume, "pi_other"); rec.Code != http.StatusBadRequest {
+		t.Errorf("foreign intent: status = %d, want 400", rec.Code)
+	}
+
+	// Once authenticated the payment is settled
+	provider.status = payments.IntentSucceeded
+	if rec := post(h.HandleStripeResume, "pi_1"); rec.Code != http.StatusOK {
+		t.Fatalf("resume: status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	if got, _ := txns.GetTransaction(txn.ID); len(got.Attempts) == 0 {
+		t.Error("expected the authenticated payment to be settled through the mesh")
+	}
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// PricingHandler lets admins inspect and tune the dynamic hop fee curve
+type PricingHandler struct {
+	pricer *payments.Pricer
+	audit  audit.Store
+}
+
+// NewPricingHandler creates a new pricing handler
+func NewPricingHandler(pricer *payments.Pricer) *PricingHandler {
+	return &PricingHandler{pricer: pricer}
+}
+
+// SetAuditStore records pricing curve changes in store
+func (h *PricingHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// HandlePricing handles GET and PUT /api/v1/admin/pricing.
+// PUT accepts any subset of the curve's fields; omitted fields keep their
+// current value. New coefficients apply to transactions created afterwards.
+func (h *PricingHandler) HandlePricing(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h.pricer.Curve())
+	case http.MethodPut:
+		h.handleUpdate(w, r)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+func (h *PricingHandler) handleUpdate(w http.ResponseWriter, r *http.Request) {
+	before := h.pricer.Curve()
+	curve := before
+	if err := validate.Decode(r, &curve); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := h.pricer.SetCurve(curve); err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	admin := ""
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		admin = user.Username
+	}
+	slog.InfoContext(r.Context(), "pricing curve updated", "admin", admin, "curve", curve)
+	recordAudit(h.audit, r, http.StatusOK, "pricing.update", "pricing", "hop_fees", before, curve)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(curve)
+}
@@ -4,13 +4,19 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 	"regexp"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
 	"github.com/plm/predictive-liquidity-mesh/auth"
 	"github.com/plm/predictive-liquidity-mesh/engine/router"
 	"github.com/plm/predictive-liquidity-mesh/storage/neo4j"
@@ -20,18 +26,41 @@ import (
 
 // AdminHandler handles admin-only API endpoints
 type AdminHandler struct {
-	graph *router.Graph
-	neo4j *neo4j.Client
-	wsHub *websocket.Hub
+	graph        *router.Graph
+	countryGraph *router.CountryGraph
+	neo4j        atomic.Pointer[neo4j.Client]
+	wsHub        *websocket.Hub
+	audit        audit.Store
 }
 
 // NewAdminHandler creates a new admin handler
 func NewAdminHandler(graph *router.Graph, neo4jClient *neo4j.Client, wsHub *websocket.Hub) *AdminHandler {
-	return &AdminHandler{
+	h := &AdminHandler{
 		graph: graph,
-		neo4j: neo4jClient,
 		wsHub: wsHub,
 	}
+	h.neo4j.Store(neo4jClient)
+	return h
+}
+
+// SetNeo4jClient sets the Neo4j client, for a connection made after boot
+func (h *AdminHandler) SetNeo4jClient(client *neo4j.Client) {
+	h.neo4j.Store(client)
+}
+
+// SetAuditStore records node, edge and graph changes in store
+func (h *AdminHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// nodeState is the audited state of a mesh node (nil if it does not exist)
+func nodeState(node *router.Node) map[string]interface{} {
+	if node == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"id": node.ID, "type": node.Type, "region": node.Region, "is_active": node.IsActive, "capacity": node.Capacity,
+	}
 }
 
 // CreateNodeRequest is the request body for creating a node
@@ -42,6 +71,7 @@ type CreateNodeRequest struct {
 	FullName     string                 `json:"full_name,omitempty"`
 	Organization string                 `json:"organization,omitempty"`
 	Properties   map[string]interface{} `json:"properties,omitempty"`
+	Capacity     *router.NodeCapacity   `json:"capacity,omitempty"` // Unlimited and always open if omitted
 }
 
 // NodeResponse is the response for node operations
@@ -57,51 +87,64 @@ type NodeResponse struct {
 // HandleCreateNode handles POST /api/v1/admin/nodes
 func (h *AdminHandler) HandleCreateNode(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r.Context())
-	if user == nil || !user.IsAdmin() {
-		http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 		return
 	}
 
 	var req CreateNodeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
 	if req.ID == "" || req.Type == "" {
-		http.Error(w, `{"error":"node id and type are required"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "node id and type are required")
 		return
 	}
 
 	validTypes := map[string]bool{"SME": true, "LiquidityProvider": true, "Hub": true}
 	if !validTypes[req.Type] {
-		http.Error(w, `{"error":"invalid node type"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "invalid node type")
 		return
 	}
+	var capacity router.NodeCapacity
+	if req.Capacity != nil {
+		capacity = *req.Capacity
+		if err := capacity.Validate(); err != nil {
+			apierror.RespondCode(w, apierror.CodeValidationFailed, "capacity: "+err.Error())
+			return
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
+	before := nodeState(h.graph.GetNode(req.ID))
 	node := &router.Node{
 		ID:       req.ID,
 		Type:     req.Type,
 		Region:   req.Region,
 		IsActive: true,
 		Props:    req.Properties,
+		Capacity: capacity,
 	}
 	h.graph.AddNode(node)
 
-	if h.neo4j != nil {
-		props := map[string]interface{}{
+	if client := h.neo4j.Load(); client != nil {
+		props := (&router.Node{Capacity: capacity}).StoredProps()
+		for k, v := range map[string]interface{}{
 			"id": req.ID, "type": req.Type, "region": req.Region,
 			"is_active": true, "created_by": user.Username,
+		} {
+			props[k] = v
 		}
-		h.neo4j.CreateNode(ctx, req.Type, props)
+		client.CreateNode(ctx, req.Type, props)
 	}
 
 	// Broadcast to all WebSocket clients for UI sync
@@ -114,7 +157,8 @@ func (h *AdminHandler) HandleCreateNode(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
-	log.Printf("✅ Admin %s created node: %s (%s)", user.Username, req.ID, req.Type)
+	slog.InfoContext(r.Context(), "node created", "admin", user.Username, "node", req.ID, "type", req.Type)
+	recordAudit(h.audit, r, http.StatusCreated, "node.create", "node", req.ID, before, nodeState(node))
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -126,13 +170,13 @@ func (h *AdminHandler) HandleCreateNode(w http.ResponseWriter, r *http.Request)
 // HandleDeleteNode handles DELETE /api/v1/admin/nodes/{id}
 func (h *AdminHandler) HandleDeleteNode(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodDelete && r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r.Context())
-	if user == nil || !user.IsAdmin() {
-		http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 		return
 	}
 
@@ -140,11 +184,12 @@ func (h *AdminHandler) HandleDeleteNode(w http.ResponseWriter, r *http.Request)
 	nodeID := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/nodes/")
 	nodeID = strings.TrimSuffix(nodeID, "/delete")
 	if nodeID == "" {
-		http.Error(w, `{"error":"node id required"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "node id required")
 		return
 	}
 
 	// Remove from graph
+	before := nodeState(h.graph.GetNode(nodeID))
 	h.graph.RemoveNode(nodeID)
 
 	// Broadcast deletion
@@ -155,7 +200,8 @@ func (h *AdminHandler) HandleDeleteNode(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
-	log.Printf("🗑️ Admin %s deleted node: %s", user.Username, nodeID)
+	slog.InfoContext(r.Context(), "node deleted", "admin", user.Username, "node", nodeID)
+	recordAudit(h.audit, r, http.StatusOK, "node.delete", "node", nodeID, before, nil)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(NodeResponse{
@@ -163,6 +209,104 @@ func (h *AdminHandler) HandleDeleteNode(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// HandleNode handles /api/v1/admin/nodes/{id} and /api/v1/admin/nodes/{id}/capacity
+func (h *AdminHandler) HandleNode(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/capacity"):
+		h.HandleNodeCapacity(w, r)
+	case r.Method == http.MethodPut || r.Method == http.MethodPatch:
+		h.HandleUpdateNode(w, r)
+	case r.Method == http.MethodDelete || strings.HasSuffix(r.URL.Path, "/delete"):
+		h.HandleDeleteNode(w, r)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// NodeCapacityResponse is a node's capacity and whether it can take settlements now
+type NodeCapacityResponse struct {
+	NodeID    string              `json:"node_id"`
+	Capacity  router.NodeCapacity `json:"capacity"`
+	InFlight  int64               `json:"in_flight"`
+	Available bool                `json:"available"` // Open and below its limits
+}
+
+// HandleNodeCapacity handles GET and PUT /api/v1/admin/nodes/{id}/capacity.
+// PUT replaces the capacity; an empty object removes every limit.
+func (h *AdminHandler) HandleNodeCapacity(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	nodeID := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/nodes/"), "/capacity")
+	node := h.graph.GetNode(nodeID)
+	if node == nil {
+		apierror.Respond(w, http.StatusNotFound, "node not found")
+		return
+	}
+	before := nodeState(node)
+
+	switch r.Method {
+	case http.MethodGet:
+		if !middleware.Can(r.Context(), auth.PermGraphRead) {
+			apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+			return
+		}
+	case http.MethodPut:
+		if !middleware.Can(r.Context(), auth.PermGraphWrite) {
+			apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+			return
+		}
+		var capacity router.NodeCapacity
+		if err := validate.Decode(r, &capacity); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if err := capacity.Validate(); err != nil {
+			apierror.RespondCode(w, apierror.CodeValidationFailed, err.Error())
+			return
+		}
+
+		if client := h.neo4j.Load(); client != nil {
+			ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+			defer cancel()
+			if err := client.UpdateNode(ctx, nodeID, capacity.Props()); err != nil {
+				writeGraphError(w, r, "failed to update node", err)
+				return
+			}
+		}
+		if err := h.graph.SetNodeCapacity(nodeID, capacity); err != nil {
+			apierror.Respond(w, http.StatusNotFound, "node not found")
+			return
+		}
+
+		if h.wsHub != nil {
+			h.wsHub.BroadcastJSON(map[string]interface{}{
+				"type": "NODE_UPDATED",
+				"data": map[string]interface{}{"id": nodeID, "capacity": capacity},
+			})
+		}
+		slog.InfoContext(r.Context(), "node capacity updated", "admin", user.Username, "node", nodeID)
+		recordAudit(h.audit, r, http.StatusOK, "node.capacity", "node", nodeID, before, nodeState(h.graph.GetNode(nodeID)))
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	resp := NodeCapacityResponse{
+		NodeID:    nodeID,
+		Capacity:  h.graph.GetNode(nodeID).Capacity,
+		Available: h.graph.NodeAvailable(nodeID),
+	}
+	if lt := h.graph.LoadTracker(); lt != nil {
+		resp.InFlight = lt.Load(nodeID)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
 // UpdateNodeRequest is the request for updating a node
 type UpdateNodeRequest struct {
 	Region   string `json:"region,omitempty"`
@@ -172,24 +316,25 @@ type UpdateNodeRequest struct {
 // HandleUpdateNode handles PUT/PATCH /api/v1/admin/nodes/{id}
 func (h *AdminHandler) HandleUpdateNode(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUserFromContext(r.Context())
-	if user == nil || !user.IsAdmin() {
-		http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 		return
 	}
 
 	nodeID := strings.TrimPrefix(r.URL.Path, "/api/v1/admin/nodes/")
 	if nodeID == "" {
-		http.Error(w, `{"error":"node id required"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "node id required")
 		return
 	}
 
 	var req UpdateNodeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
 	// Update in graph
+	before := nodeState(h.graph.GetNode(nodeID))
 	if req.IsActive != nil {
 		if *req.IsActive {
 			h.graph.SetNodeActive(nodeID)
@@ -208,7 +353,8 @@ func (h *AdminHandler) HandleUpdateNode(w http.ResponseWriter, r *http.Request)
 		})
 	}
 
-	log.Printf("✏️ Admin %s updated node: %s", user.Username, nodeID)
+	slog.InfoContext(r.Context(), "node updated", "admin", user.Username, "node", nodeID)
+	recordAudit(h.audit, r, http.StatusOK, "node.update", "node", nodeID, before, nodeState(h.graph.GetNode(nodeID)))
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(NodeResponse{
@@ -220,7 +366,7 @@ func (h *AdminHandler) HandleUpdateNode(w http.ResponseWriter, r *http.Request)
 func (h *AdminHandler) HandleGetNodes(w http.ResponseWriter, r *http.Request) {
 	user := middleware.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, `{"error":"authentication required"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -237,35 +383,77 @@ func (h *AdminHandler) HandleGetNodes(w http.ResponseWriter, r *http.Request) {
 type CreateEdgeRequest struct {
 	SourceID        string  `json:"source_id"`
 	TargetID        string  `json:"target_id"`
+	Type            string  `json:"type,omitempty"` // Neo4j relationship type, derived from the node types if empty
 	BaseFee         float64 `json:"base_fee"`
 	Latency         int64   `json:"latency_ms"`
 	LiquidityVolume int64   `json:"liquidity_volume,omitempty"`
+	Bidirectional   bool    `json:"bidirectional,omitempty"` // Also create the reverse edge
+}
+
+// HandleEdges handles POST and DELETE /api/v1/admin/edges
+func (h *AdminHandler) HandleEdges(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.HandleCreateEdge(w, r)
+	case http.MethodDelete:
+		h.handleDeleteEdge(w, r)
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
 }
 
 // HandleCreateEdge handles POST /api/v1/admin/edges
 func (h *AdminHandler) HandleCreateEdge(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	user := middleware.GetUserFromContext(r.Context())
-	if user == nil || !user.IsAdmin() {
-		http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 		return
 	}
 
 	var req CreateEdgeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	if req.SourceID == "" || req.TargetID == "" {
-		http.Error(w, `{"error":"source_id and target_id are required"}`, http.StatusBadRequest)
+	var v validate.Validator
+	v.Required("source_id", req.SourceID)
+	v.Required("target_id", req.TargetID)
+	v.Check(req.Type == "" || neo4j.IsRelType(req.Type), "type", "unknown relationship type")
+	if err := v.Err(); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
+	// Persist first so the in-memory graph never has edges Neo4j lost
+	persisted := false
+	if client := h.neo4j.Load(); client != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		props := map[string]interface{}{
+			"base_fee":         req.BaseFee,
+			"latency":          req.Latency,
+			"liquidity_volume": req.LiquidityVolume,
+			"is_active":        true,
+			"created_by":       user.Username,
+		}
+		err := client.CreateEdge(ctx, req.SourceID, req.TargetID, h.edgeType(req.Type, req.SourceID, req.TargetID), props)
+		if err == nil && req.Bidirectional {
+			err = client.CreateEdge(ctx, req.TargetID, req.SourceID, h.edgeType(req.Type, req.TargetID, req.SourceID), props)
+		}
+		if err != nil {
+			writeGraphError(w, r, "failed to persist edge", err)
+			return
+		}
+		persisted = true
+	}
+
 	// Add edge to graph
 	edge := &router.Edge{
 		SourceID:        req.SourceID,
@@ -275,20 +463,261 @@ func (h *AdminHandler) HandleCreateEdge(w http.ResponseWriter, r *http.Request)
 		LiquidityVolume: req.LiquidityVolume,
 		IsActive:        true,
 	}
-	h.graph.AddEdge(edge)
-
-	log.Printf("✅ Admin %s created edge: %s -> %s", user.Username, req.SourceID, req.TargetID)
+	if req.Bidirectional {
+		h.graph.AddBidirectionalEdge(edge)
+	} else {
+		h.graph.AddEdge(edge)
+	}
+	slog.InfoContext(r.Context(), "edge created", "admin", user.Username, "source", req.SourceID, "target", req.TargetID,
+		"bidirectional", req.Bidirectional, "persisted", persisted)
+	recordAudit(h.audit, r, http.StatusCreated, "edge.create", "edge", req.SourceID+"->"+req.TargetID, nil, req)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success":   true,
-		"source_id": req.SourceID,
-		"target_id": req.TargetID,
-		"message":   "Edge created successfully",
+		"success":       true,
+		"source_id":     req.SourceID,
+		"target_id":     req.TargetID,
+		"bidirectional": req.Bidirectional,
+		"persisted":     persisted,
+		"message":       "Edge created successfully",
+	})
+}
+
+// handleDeleteEdge handles DELETE /api/v1/admin/edges?source_id=&target_id=
+func (h *AdminHandler) handleDeleteEdge(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	req := CreateEdgeRequest{
+		SourceID:      query.Get("source_id"),
+		TargetID:      query.Get("target_id"),
+		Type:          query.Get("type"),
+		Bidirectional: query.Get("bidirectional") == "true",
+	}
+	if req.SourceID == "" && req.TargetID == "" {
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	h.deleteEdge(w, r, req)
+}
+
+// UpdateEdgeRequest is the request for updating an edge; omitted fields are unchanged
+type UpdateEdgeRequest struct {
+	BaseFee         *float64 `json:"base_fee,omitempty"`
+	Latency         *int64   `json:"latency_ms,omitempty"`
+	LiquidityVolume *int64   `json:"liquidity_volume,omitempty"`
+	IsActive        *bool    `json:"is_active,omitempty"`
+}
+
+// validate checks the fields that are set
+func (req *UpdateEdgeRequest) validate() error {
+	if req.BaseFee == nil && req.Latency == nil && req.LiquidityVolume == nil && req.IsActive == nil {
+		return apierror.New(apierror.CodeInvalidRequest, "set at least one of base_fee, latency_ms, liquidity_volume and is_active")
+	}
+	var v validate.Validator
+	v.Check(req.BaseFee == nil || (*req.BaseFee >= 0 && *req.BaseFee <= 1), "base_fee", "must be between 0 and 1")
+	v.Check(req.Latency == nil || *req.Latency >= 0, "latency_ms", "must not be negative")
+	v.Check(req.LiquidityVolume == nil || *req.LiquidityVolume >= 0, "liquidity_volume", "must not be negative")
+	return v.Err()
+}
+
+// HandleEdge handles PUT/PATCH and DELETE /api/v1/admin/edges/{source}/{target}
+func (h *AdminHandler) HandleEdge(w http.ResponseWriter, r *http.Request) {
+	ids := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/edges/"), "/"), "/")
+	if len(ids) != 2 || ids[0] == "" || ids[1] == "" {
+		apierror.Respond(w, http.StatusNotFound, "expected /api/v1/admin/edges/{source}/{target}")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut, http.MethodPatch:
+		h.handleUpdateEdge(w, r, ids[0], ids[1])
+	case http.MethodDelete:
+		h.deleteEdge(w, r, CreateEdgeRequest{
+			SourceID:      ids[0],
+			TargetID:      ids[1],
+			Type:          r.URL.Query().Get("type"),
+			Bidirectional: r.URL.Query().Get("bidirectional") == "true",
+		})
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// handleUpdateEdge changes the fee, latency, liquidity or status of an edge
+// in Neo4j and the routing graph
+func (h *AdminHandler) handleUpdateEdge(w http.ResponseWriter, r *http.Request, sourceID, targetID string) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	var req UpdateEdgeRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := req.validate(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	before := h.graph.Edge(sourceID, targetID)
+	if before == nil {
+		apierror.Respond(w, http.StatusNotFound, "edge not found")
+		return
+	}
+
+	if client := h.neo4j.Load(); client != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		updates := map[string]interface{}{"updated_by": user.Username}
+		if req.BaseFee != nil {
+			updates["base_fee"] = *req.BaseFee
+		}
+		if req.Latency != nil {
+			updates["latency"] = *req.Latency
+		}
+		if req.LiquidityVolume != nil {
+			updates["liquidity_volume"] = *req.LiquidityVolume
+		}
+		if req.IsActive != nil {
+			updates["is_active"] = *req.IsActive
+		}
+		if err := client.UpdateEdge(ctx, sourceID, targetID, updates); err != nil {
+			writeGraphError(w, r, "failed to update edge", err)
+			return
+		}
+	}
+
+	after, err := h.graph.PatchEdge(sourceID, targetID, router.EdgePatch{
+		BaseFee:         req.BaseFee,
+		Latency:         req.Latency,
+		LiquidityVolume: req.LiquidityVolume,
+		IsActive:        req.IsActive,
+	})
+	if err != nil {
+		// Removed concurrently
+		apierror.Respond(w, http.StatusNotFound, "edge not found")
+		return
+	}
+
+	data := map[string]interface{}{
+		"source_id":        after.SourceID,
+		"target_id":        after.TargetID,
+		"base_fee":         after.BaseFee,
+		"latency_ms":       after.Latency,
+		"liquidity_volume": after.LiquidityVolume,
+		"is_active":        after.IsActive,
+	}
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "EDGE_UPDATED",
+			"data": data,
+		})
+	}
+
+	slog.InfoContext(r.Context(), "edge updated", "admin", user.Username, "source", sourceID, "target", targetID)
+	recordAudit(h.audit, r, http.StatusOK, "edge.update", "edge", sourceID+"->"+targetID, before, after)
+
+	data["success"] = true
+	data["message"] = "Edge updated successfully"
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(data)
+}
+
+// deleteEdge removes an edge, and its reverse if req.Bidirectional, from
+// Neo4j and the routing graph
+func (h *AdminHandler) deleteEdge(w http.ResponseWriter, r *http.Request, req CreateEdgeRequest) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil || !middleware.Can(r.Context(), auth.PermGraphWrite) {
+		apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+		return
+	}
+
+	var v validate.Validator
+	v.Required("source_id", req.SourceID)
+	v.Required("target_id", req.TargetID)
+	v.Check(req.Type == "" || neo4j.IsRelType(req.Type), "type", "unknown relationship type")
+	if err := v.Err(); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if client := h.neo4j.Load(); client != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+		defer cancel()
+
+		err := client.DeleteEdge(ctx, req.SourceID, req.TargetID, req.Type)
+		if err == nil && req.Bidirectional {
+			err = client.DeleteEdge(ctx, req.TargetID, req.SourceID, req.Type)
+		}
+		if err != nil {
+			writeGraphError(w, r, "failed to delete edge", err)
+			return
+		}
+	}
+
+	before := h.graph.Edge(req.SourceID, req.TargetID)
+	h.graph.RemoveEdge(req.SourceID, req.TargetID)
+	if req.Bidirectional {
+		h.graph.RemoveEdge(req.TargetID, req.SourceID)
+	}
+
+	if h.wsHub != nil {
+		h.wsHub.BroadcastJSON(map[string]interface{}{
+			"type": "EDGE_DELETED",
+			"data": map[string]interface{}{
+				"source_id": req.SourceID, "target_id": req.TargetID, "bidirectional": req.Bidirectional,
+			},
+		})
+	}
+
+	slog.InfoContext(r.Context(), "edge deleted", "admin", user.Username, "source", req.SourceID, "target", req.TargetID, "bidirectional", req.Bidirectional)
+	recordAudit(h.audit, r, http.StatusOK, "edge.delete", "edge", req.SourceID+"->"+req.TargetID, before, nil)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":       true,
+		"source_id":     req.SourceID,
+		"target_id":     req.TargetID,
+		"bidirectional": req.Bidirectional,
+		"message":       "Edge deleted successfully",
 	})
 }
 
+// edgeType returns relType, or the relationship the seed data uses between
+// the types of the two nodes
+func (h *AdminHandler) edgeType(relType, sourceID, targetID string) string {
+	if relType != "" {
+		return relType
+	}
+	var sourceType, targetType string
+	if node := h.graph.GetNode(sourceID); node != nil {
+		sourceType = node.Type
+	}
+	if node := h.graph.GetNode(targetID); node != nil {
+		targetType = node.Type
+	}
+	return neo4j.MeshRelationship(sourceType, targetType)
+}
+
+// writeGraphError maps a Neo4j mesh error to a response
+func writeGraphError(w http.ResponseWriter, r *http.Request, message string, err error) {
+	switch {
+	case errors.Is(err, neo4j.ErrNodeNotFound), errors.Is(err, neo4j.ErrEdgeNotFound):
+		apierror.Respond(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, neo4j.ErrInvalidRelType):
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), message, "error", err)
+		apierror.Respond(w, http.StatusBadGateway, message)
+	}
+}
+
 // UserHandler handles user-level API endpoints
 type UserHandler struct {
 	router *router.Router
@@ -319,6 +748,7 @@ type PathPreview struct {
 	TotalWeight  float64  `json:"total_weight"`
 	HopCount     int      `json:"hop_count"`
 	EstimatedCost float64 `json:"estimated_cost,omitempty"`
+	EstimatedCompletionAt time.Time `json:"estimated_completion_at"` // Including waits for nodes outside their operating hours
 }
 
 // SettlePreviewResponse is the response with top paths
@@ -343,27 +773,27 @@ func (h *UserHandler) HandleSettlePreview(w http.ResponseWriter, r *http.Request
 		destination = r.URL.Query().Get("destination")
 	} else if r.Method == http.MethodPost {
 		var req SettlePreviewRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
 			return
 		}
 		source = req.Source
 		destination = req.Destination
 		amount = req.Amount
 	} else {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	if source == "" || destination == "" {
-		http.Error(w, `{"error":"source and destination are required"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "source and destination are required")
 		return
 	}
 
 	// Get authenticated user
 	user := middleware.GetUserFromContext(r.Context())
 	if user == nil {
-		http.Error(w, `{"error":"authentication required"}`, http.StatusUnauthorized)
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
 		return
 	}
 
@@ -373,13 +803,14 @@ func (h *UserHandler) HandleSettlePreview(w http.ResponseWriter, r *http.Request
 	start := time.Now()
 
 	// Find K shortest paths using Yen's algorithm
-	paths, err := h.router.FindKShortestPaths(ctx, source, destination)
+	paths, err := h.router.FindKShortestPaths(ctx, source, destination, amount)
 	if err != nil {
-		http.Error(w, `{"error":"failed to find paths: `+err.Error()+`"}`, http.StatusInternalServerError)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to find paths: "+err.Error())
 		return
 	}
 
 	computeTime := time.Since(start)
+	now := time.Now()
 
 	// Convert to preview format
 	previews := make([]*PathPreview, 0, len(paths))
@@ -391,6 +822,7 @@ func (h *UserHandler) HandleSettlePreview(w http.ResponseWriter, r *http.Request
 			TotalLatency: p.TotalLatency,
 			TotalWeight:  p.TotalWeight,
 			HopCount:     len(p.Nodes) - 1,
+			EstimatedCompletionAt: h.graph.EstimateCompletion(p, now),
 		}
 		if amount > 0 {
 			preview.EstimatedCost = float64(amount) * p.TotalFee
@@ -407,8 +839,8 @@ func (h *UserHandler) HandleSettlePreview(w http.ResponseWriter, r *http.Request
 		ComputeTime: computeTime.String(),
 	}
 
-	log.Printf("📊 User %s previewed settlement: %s -> %s (%d paths in %v)",
-		user.Username, source, destination, len(paths), computeTime)
+	slog.InfoContext(r.Context(), "settlement previewed", "user", user.Username, "source", source,
+		"destination", destination, "paths", len(paths), "compute_time", computeTime)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
@@ -418,11 +850,14 @@ func (h *UserHandler) HandleSettlePreview(w http.ResponseWriter, r *http.Request
 type AuthHandler struct {
 	tokenManager *auth.TokenManager
 	userStore    UserStorer
+	notifier     AccountNotifier
+	resetTokens  *auth.ResetTokens
+	resetMu      sync.Mutex // Serializes token checks with password updates (single use)
 }
 
 // NewAuthHandler creates a new auth handler
 func NewAuthHandler(tm *auth.TokenManager) *AuthHandler {
-	return &AuthHandler{tokenManager: tm}
+	return &AuthHandler{tokenManager: tm, resetTokens: tm.ResetTokens(auth.DefaultResetTokenTTL)}
 }
 
 // LoginRequest is the login request body
@@ -438,11 +873,13 @@ type LoginResponse struct {
 	User      *auth.User `json:"user"`
 }
 
-// UserStorer interface for user operations - implemented by users.Store
+// UserStorer interface for user operations - implemented by users.Store and users.PostgresStore
 type UserStorer interface {
 	Authenticate(email, password string) (users.UserWithToUser, error)
 	CreateUser(email, password, username string, role auth.Role) (users.UserWithToUser, error)
 	GetByEmail(email string) (users.UserWithToUser, error)
+	GetByID(id string) (*users.StoredUser, error)
+	SetPassword(id, password string) error
 }
 
 // SetUserStore sets the user store for authentication
@@ -450,6 +887,18 @@ func (h *AuthHandler) SetUserStore(store UserStorer) {
 	h.userStore = store
 }
 
+// AccountNotifier emails users about account events - implemented by notifications.Notifier
+type AccountNotifier interface {
+	NotifyRegistration(userID string)
+	NotifyPasswordReset(userID, token string, expiresAt time.Time)
+	NotifyPasswordChanged(userID string)
+}
+
+// SetNotifier enables account emails (welcome on registration, password reset links)
+func (h *AuthHandler) SetNotifier(n AccountNotifier) {
+	h.notifier = n
+}
+
 // RegisterRequest is the registration request body
 type RegisterRequest struct {
 	Email    string `json:"email"`
@@ -460,13 +909,13 @@ type RegisterRequest struct {
 // HandleLogin handles POST /api/v1/auth/login
 func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	var req LoginRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -476,7 +925,7 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	if h.userStore != nil {
 		storedUser, err := h.userStore.Authenticate(req.Email, req.Password)
 		if err != nil {
-			http.Error(w, `{"error":"invalid email or password"}`, http.StatusUnauthorized)
+			apierror.Respond(w, http.StatusUnauthorized, "invalid email or password")
 			return
 		}
 		user = storedUser.ToUser()
@@ -501,11 +950,11 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 	// Generate token
 	token, claims, err := h.tokenManager.GenerateToken(user)
 	if err != nil {
-		http.Error(w, `{"error":"failed to generate token"}`, http.StatusInternalServerError)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to generate token")
 		return
 	}
 
-	log.Printf("🔐 User logged in: %s (role: %s)", user.Email, user.Role)
+	slog.InfoContext(r.Context(), "user logged in", "user_id", user.ID, "role", user.Role)
 
 	resp := LoginResponse{
 		Token:     token,
@@ -520,48 +969,48 @@ func (h *AuthHandler) HandleLogin(w http.ResponseWriter, r *http.Request) {
 // HandleRegister handles POST /api/v1/auth/register
 func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	if h.userStore == nil {
-		http.Error(w, `{"error":"registration not available"}`, http.StatusServiceUnavailable)
+		apierror.Respond(w, http.StatusServiceUnavailable, "registration not available")
 		return
 	}
 
 	var req RegisterRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request body"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
 	if req.Email == "" || req.Password == "" || req.Username == "" {
-		http.Error(w, `{"error":"email, password, and username are required"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "email, password, and username are required")
 		return
 	}
 
 	if len(req.Password) < 6 {
-		http.Error(w, `{"error":"password must be at least 6 characters"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "password must be at least 6 characters")
 		return
 	}
 
 	// Validate username format: only letters, numbers, and underscores
 	usernameRegex := regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
 	if !usernameRegex.MatchString(req.Username) {
-		http.Error(w, `{"error":"username can only contain letters, numbers, and underscores"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "username can only contain letters, numbers, and underscores")
 		return
 	}
 
 	if len(req.Username) < 3 || len(req.Username) > 30 {
-		http.Error(w, `{"error":"username must be between 3 and 30 characters"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "username must be between 3 and 30 characters")
 		return
 	}
 
 	// Create user with USER role by default
 	storedUser, err := h.userStore.CreateUser(req.Email, req.Password, req.Username, auth.RoleUser)
 	if err != nil {
-		log.Printf("❌ Registration failed: %v", err)
-		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusConflict)
+		slog.WarnContext(r.Context(), "registration failed", "error", err)
+		apierror.Respond(w, http.StatusConflict, err.Error())
 		return
 	}
 
@@ -570,11 +1019,14 @@ func (h *AuthHandler) HandleRegister(w http.ResponseWriter, r *http.Request) {
 	// Generate token
 	token, claims, err := h.tokenManager.GenerateToken(user)
 	if err != nil {
-		http.Error(w, `{"error":"failed to generate token"}`, http.StatusInternalServerError)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to generate token")
 		return
 	}
 
-	log.Printf("🆕 User registered: %s (%s)", user.Email, user.Username)
+	slog.InfoContext(r.Context(), "user registered", "user_id", user.ID, "username", user.Username)
+	if h.notifier != nil {
+		h.notifier.NotifyRegistration(user.ID)
+	}
 
 	resp := LoginResponse{
 		Token:     token,
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+func TestAdminEdgesWithoutNeo4j(t *testing.T) {
+	graph := router.NewGraph()
+	graph.AddNode(&router.Node{ID: "sme_1", Type: "SME", IsActive: true})
+	graph.AddNode(&router.Node{ID: "lp_1", Type: "LiquidityProvider", IsActive: true})
+	h := NewAdminHandler(graph, nil, nil)
+	admin := &auth.User{ID: "admin-1", Username: "admin", Role: auth.RoleAdmin}
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, admin))
+		rec := httptest.NewRecorder()
+		h.HandleEdges(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPost, "/api/v1/admin/edges", `{"source_id":"sme_1","target_id":"lp_1","type":"OWNS"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "type: unknown relationship type") {
+		t.Fatalf("unknown type: status %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = do(http.MethodPost, "/api/v1/admin/edges", `{"source_id":"sme_1","target_id":"lp_1","base_fee":0.01,"latency_ms":20,"bidirectional":true}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d: %s", rec.Code, rec.Body)
+	}
+	var created map[string]interface{}
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatal(err)
+	}
+	if created["persisted"] != false {
+		t.Errorf("persisted = %v without a Neo4j client", created["persisted"])
+	}
+	if edges := graph.GetAllEdges(); len(edges) != 2 {
+		t.Fatalf("graph has %d edges, want 2", len(edges))
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/admin/edges?source_id=sme_1&target_id=lp_1", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: status %d: %s", rec.Code, rec.Body)
+	}
+	if edges := graph.GetAllEdges(); len(edges) != 1 || edges[0].SourceID != "lp_1" {
+		t.Fatalf("graph edges after delete = %+v", edges)
+	}
+
+	if rec := do(http.MethodPut, "/api/v1/admin/edges", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT: status %d, want 405", rec.Code)
+	}
+}
+
+func TestAdminEdgeUpdateAndDelete(t *testing.T) {
+	graph := router.NewGraph()
+	graph.AddNode(&router.Node{ID: "lp_1", Type: "LiquidityProvider", IsActive: true})
+	graph.AddNode(&router.Node{ID: "hub_1", Type: "Hub", IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "lp_1", TargetID: "hub_1", BaseFee: 0.002, Latency: 30, LiquidityVolume: 500, IsActive: true})
+	h := NewAdminHandler(graph, nil, nil)
+	admin := &auth.User{ID: "admin-1", Username: "admin", Role: auth.RoleAdmin}
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, admin))
+		rec := httptest.NewRecorder()
+		h.HandleEdge(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPatch, "/api/v1/admin/edges/lp_1/hub_1", `{"base_fee":0.001,"is_active":false}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("patch: status %d: %s", rec.Code, rec.Body)
+	}
+	edge := graph.Edge("lp_1", "hub_1")
+	if edge.BaseFee != 0.001 || edge.IsActive || edge.Latency != 30 || edge.LiquidityVolume != 500 {
+		t.Errorf("patched edge = %+v", edge)
+	}
+	if reverse := graph.Edge("hub_1", "lp_1"); reverse.BaseFee != 0.002 || !reverse.IsActive {
+		t.Errorf("reverse edge must be unchanged: %+v", reverse)
+	}
+
+	for _, tt := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPut, "/api/v1/admin/edges/lp_1/hub_1", `{"latency_ms":-1}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/admin/edges/lp_1/hub_1", `{}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/admin/edges/lp_1/sme_9", `{"latency_ms":5}`, http.StatusNotFound},
+		{http.MethodPut, "/api/v1/admin/edges/lp_1", `{"latency_ms":5}`, http.StatusNotFound},
+		{http.MethodPost, "/api/v1/admin/edges/lp_1/hub_1", ``, http.StatusMethodNotAllowed},
+	} {
+		if rec := do(tt.method, tt.target, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s %s: status %d, want %d", tt.method, tt.target, tt.body, rec.Code, tt.want)
+		}
+	}
+
+	if rec := do(http.MethodDelete, "/api/v1/admin/edges/lp_1/hub_1?bidirectional=true", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete: status %d: %s", rec.Code, rec.Body)
+	}
+	if edges := graph.GetAllEdges(); len(edges) != 0 {
+		t.Errorf("graph edges after delete = %+v", edges)
+	}
+}
+
+func TestAdminNodeCapacity(t *testing.T) {
+	graph := router.NewGraph()
+	graph.AddNode(&router.Node{ID: "hub_1", Type: "Hub", IsActive: true})
+	graph.SetLoadTracker(router.NewLoadTracker(), 0)
+	h := NewAdminHandler(graph, nil, nil)
+	admin := &auth.User{ID: "admin-1", Username: "admin", Role: auth.RoleAdmin}
+
+	do := func(method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, admin))
+		rec := httptest.NewRecorder()
+		h.HandleNode(rec, req)
+		return rec
+	}
+
+	rec := do(http.MethodPut, "/api/v1/admin/nodes/hub_1/capacity", `{"max_concurrent":1,"operating_hours":{"open":"00:00","close":"00:00"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("put: status %d: %s", rec.Code, rec.Body)
+	}
+	graph.LoadTracker().Acquire("hub_1")
+
+	rec = do(http.MethodGet, "/api/v1/admin/nodes/hub_1/capacity", "")
+	var resp NodeCapacityResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil || rec.Code != http.StatusOK {
+		t.Fatalf("get: status %d: %v", rec.Code, err)
+	}
+	if resp.Capacity.MaxConcurrent != 1 || resp.InFlight != 1 || resp.Available {
+		t.Errorf("capacity = %+v, want a full node", resp)
+	}
+
+	for _, tt := range []struct {
+		method, target, body string
+		want                 int
+	}{
+		{http.MethodPut, "/api/v1/admin/nodes/hub_1/capacity", `{"operating_hours":{"open":"09:00","close":"17:00","timezone":"Nowhere/City"}}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/admin/nodes/hub_1/capacity", `{"max_per_minute":-5}`, http.StatusBadRequest},
+		{http.MethodPut, "/api/v1/admin/nodes/sme_9/capacity", `{}`, http.StatusNotFound},
+		{http.MethodPost, "/api/v1/admin/nodes/hub_1/capacity", `{}`, http.StatusMethodNotAllowed},
+	} {
+		if rec := do(tt.method, tt.target, tt.body); rec.Code != tt.want {
+			t.Errorf("%s %s %s: status %d, want %d", tt.method, tt.target, tt.body, rec.Code, tt.want)
+		}
+	}
+	if got := graph.GetNode("hub_1").Capacity.MaxConcurrent; got != 1 {
+		t.Errorf("rejected updates must not change the capacity, max_concurrent = %d", got)
+	}
+}
@@ -2,32 +2,38 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/payments"
 	"github.com/plm/predictive-liquidity-mesh/receipts"
 )
 
 // ReceiptHandler handles receipt download requests
 type ReceiptHandler struct {
-	txnStore  *payments.TransactionStore
+	txnStore  payments.TransactionStorer
 	generator *receipts.Generator
 }
 
 // NewReceiptHandler creates a new receipt handler
-func NewReceiptHandler(txnStore *payments.TransactionStore) *ReceiptHandler {
+func NewReceiptHandler(txnStore payments.TransactionStorer) *ReceiptHandler {
 	return &ReceiptHandler{
 		txnStore:  txnStore,
 		generator: receipts.NewGenerator("Predictive Liquidity Mesh"),
 	}
 }
 
-// HandleDownloadReceipt generates and downloads a PDF receipt
+// HandleDownloadReceipt generates and downloads a receipt.
+// Query params: type=payment (default) or type=refund (failed and refunded
+// transactions: refund details, failure hop and routing attempts), and
+// format=pdf|json|html|csv, which overrides the Accept header
+// (application/pdf, application/json, text/html or text/csv; PDF by default)
 func (h *ReceiptHandler) HandleDownloadReceipt(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
@@ -42,36 +48,73 @@ func (h *ReceiptHandler) HandleDownloadReceipt(w http.ResponseWriter, r *http.Re
 	}
 
 	if txnID == "" {
-		http.Error(w, `{"error":"transaction id required"}`, http.StatusBadRequest)
+		apierror.Respond(w, http.StatusBadRequest, "transaction id required")
 		return
 	}
 
-	log.Printf("📄 Generating receipt for transaction: %s", txnID)
+	kind := receipts.Kind(r.URL.Query().Get("type"))
+	if kind == "" {
+		kind = receipts.KindPayment
+	}
+	if kind != receipts.KindPayment && kind != receipts.KindRefund {
+		apierror.Respond(w, http.StatusBadRequest, "type must be payment or refund")
+		return
+	}
+
+	format, ok := receipts.Negotiate(r.Header.Get("Accept"))
+	if name := r.URL.Query().Get("format"); name != "" {
+		if format, ok = receipts.ParseFormat(name); !ok {
+			apierror.Respond(w, http.StatusBadRequest, "format must be pdf, json, html or csv")
+			return
+		}
+	}
+	if !ok {
+		apierror.Respond(w, http.StatusNotAcceptable, "receipts are available as application/pdf, application/json, text/html or text/csv")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "generating receipt", "transaction_id", txnID, "type", kind, "format", format)
 
 	// Get transaction
 	txn, err := h.txnStore.GetTransaction(txnID)
 	if err != nil {
-		log.Printf("❌ Receipt error: transaction not found: %s", txnID)
-		http.Error(w, `{"error":"transaction not found"}`, http.StatusNotFound)
+		slog.WarnContext(r.Context(), "receipt for unknown transaction", "transaction_id", txnID)
+		apierror.Respond(w, http.StatusNotFound, "transaction not found")
 		return
 	}
 
-	// Generate PDF
-	pdfBytes, err := h.generator.GeneratePDF(txn)
+	// Generate receipt
+	body, err := h.generator.Render(txn, kind, format)
+	if errors.Is(err, receipts.ErrNotRefundable) {
+		apierror.Respond(w, http.StatusConflict, err.Error())
+		return
+	}
 	if err != nil {
-		log.Printf("❌ Receipt PDF generation error: %v", err)
-		http.Error(w, `{"error":"failed to generate receipt: `+err.Error()+`"}`, http.StatusInternalServerError)
+		slog.ErrorContext(r.Context(), "receipt generation failed", "transaction_id", txnID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to generate receipt: "+err.Error())
 		return
 	}
 
-	log.Printf("✅ Receipt generated: %d bytes for txn %s", len(pdfBytes), txnID)
+	slog.InfoContext(r.Context(), "receipt generated", "transaction_id", txnID, "format", format, "bytes", len(body))
 
-	// Set headers for PDF download
-	w.Header().Set("Content-Type", "application/pdf")
-	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=receipt_%s.pdf", txnID))
-	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+	// PDF and CSV download as files; JSON and HTML display inline
+	disposition := "inline"
+	if format == receipts.FormatPDF || format == receipts.FormatCSV {
+		disposition = "attachment"
+	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%s_%s.%s", disposition, receiptFilePrefix(kind), txnID, format))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(body)))
+	w.Header().Set("Vary", "Accept")
 	w.Header().Set("Access-Control-Allow-Origin", "*")
 
-	w.Write(pdfBytes)
+	w.Write(body)
 }
 
+// receiptFilePrefix names downloaded receipts by kind
+func receiptFilePrefix(kind receipts.Kind) string {
+	if kind == receipts.KindRefund {
+		return "refund_receipt"
+	}
+	return "receipt"
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/engine/registry"
+)
+
+// RegistryHandler serves the node registry
+type RegistryHandler struct {
+	registry *registry.Registry
+}
+
+// NewRegistryHandler creates a new registry handler
+func NewRegistryHandler(r *registry.Registry) *RegistryHandler {
+	return &RegistryHandler{registry: r}
+}
+
+// HandleNodes handles GET /api/v1/admin/registry/nodes: every registered
+// node with its instance, gRPC address, certificate fingerprint and health
+func (h *RegistryHandler) HandleNodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	nodes := h.registry.Nodes()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"nodes": nodes,
+		"count": len(nodes),
+	})
+}
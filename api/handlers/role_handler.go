@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+// RoleGrant is the permission set of one role
+type RoleGrant struct {
+	Role        auth.Role         `json:"role"`
+	Permissions []auth.Permission `json:"permissions"`
+	Editable    bool              `json:"editable"`
+}
+
+// RoleListResponse is returned by GET /api/v1/admin/roles
+type RoleListResponse struct {
+	Roles       []RoleGrant       `json:"roles"`
+	Permissions []auth.Permission `json:"permissions"` // Every permission that can be granted
+}
+
+// UpdateRoleRequest replaces a role's permissions
+type UpdateRoleRequest struct {
+	Permissions []auth.Permission `json:"permissions"`
+}
+
+// RoleHandler lets admins change which permissions each role holds at runtime
+type RoleHandler struct {
+	policy *auth.Policy
+	store  auth.PolicyStore
+	audit  audit.Store
+	mu     sync.Mutex // Serializes updates so the store and policy agree
+}
+
+// NewRoleHandler creates a new role handler editing policy
+func NewRoleHandler(policy *auth.Policy) *RoleHandler {
+	return &RoleHandler{policy: policy}
+}
+
+// SetPolicyStore persists role changes in store
+func (h *RoleHandler) SetPolicyStore(store auth.PolicyStore) {
+	h.store = store
+}
+
+// SetAuditStore records role changes in store
+func (h *RoleHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// HandleListRoles handles GET /api/v1/admin/roles
+func (h *RoleHandler) HandleListRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	resp := RoleListResponse{Permissions: auth.Permissions}
+	for _, role := range auth.Roles {
+		resp.Roles = append(resp.Roles, RoleGrant{
+			Role:        role,
+			Permissions: h.policy.Permissions(role),
+			Editable:    role != auth.RoleAdmin,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// HandleUpdateRole handles PUT /api/v1/admin/roles/{role}.
+// The new permissions apply to the role's next request; tokens need not be reissued.
+func (h *RoleHandler) HandleUpdateRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	role := auth.Role(strings.ToUpper(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/roles/")))
+	if role == "" {
+		apierror.Respond(w, http.StatusBadRequest, "role required")
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.Permissions == nil {
+		req.Permissions = []auth.Permission{}
+	}
+
+	if err := auth.ValidateGrant(role, req.Permissions); err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, auth.ErrUnknownRole) {
+			status = http.StatusNotFound
+		}
+		apierror.Respond(w, status, err.Error())
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	before := RoleGrant{Role: role, Permissions: h.policy.Permissions(role), Editable: true}
+
+	admin := ""
+	if user := middleware.GetUserFromContext(r.Context()); user != nil {
+		admin = user.Username
+	}
+	if h.store != nil {
+		if err := h.store.SaveGrants(r.Context(), role, req.Permissions, admin); err != nil {
+			slog.ErrorContext(r.Context(), "failed to save role permissions", "role", role, "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to save role permissions")
+			return
+		}
+	}
+	h.policy.SetPermissions(role, req.Permissions)
+
+	after := RoleGrant{Role: role, Permissions: h.policy.Permissions(role), Editable: true}
+	slog.InfoContext(r.Context(), "role permissions updated", "admin", admin, "role", role, "permissions", after.Permissions)
+	recordAudit(h.audit, r, http.StatusOK, "role.update", "role", string(role), before, after)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(after)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+func TestRolePermissionsEditableAtRuntime(t *testing.T) {
+	policy := auth.DefaultPolicy()
+	authMiddleware := middleware.NewAuthMiddleware(nil)
+	authMiddleware.SetPolicy(policy)
+
+	h := NewRoleHandler(policy)
+	trail := audit.NewMemoryStore(10)
+	h.SetAuditStore(trail)
+
+	admin := &auth.User{ID: "admin-1", Username: "admin", Role: auth.RoleAdmin}
+	auditor := &auth.User{ID: "auditor-1", Username: "auditor", Role: auth.RoleAuditor}
+	fees := authMiddleware.RequireMethodPermission(auth.PermFeesRead, auth.PermFeesWrite)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	do := func(handler http.Handler, user *auth.User, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, user))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := do(fees, auditor, http.MethodGet, "/api/v1/admin/fees", ""); rec.Code != http.StatusOK {
+		t.Fatalf("auditor GET fees: status %d", rec.Code)
+	}
+	if rec := do(fees, auditor, http.MethodPut, "/api/v1/admin/fees", ""); rec.Code != http.StatusForbidden {
+		t.Fatalf("auditor PUT fees: status %d, want 403", rec.Code)
+	}
+
+	update := http.HandlerFunc(h.HandleUpdateRole)
+	rec := do(update, admin, http.MethodPut, "/api/v1/admin/roles/auditor", `{"permissions":["fees:read","fees:write"]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PUT role: status %d: %s", rec.Code, rec.Body)
+	}
+	var grant RoleGrant
+	if err := json.NewDecoder(rec.Body).Decode(&grant); err != nil {
+		t.Fatal(err)
+	}
+	if grant.Role != auth.RoleAuditor || len(grant.Permissions) != 2 {
+		t.Fatalf("unexpected grant: %+v", grant)
+	}
+	if rec := do(fees, auditor, http.MethodPut, "/api/v1/admin/fees", ""); rec.Code != http.StatusOK {
+		t.Fatalf("auditor PUT fees after grant: status %d", rec.Code)
+	}
+	if policy.Allows(auth.RoleAuditor, auth.PermAuditRead) {
+		t.Fatal("PUT must replace the role's permissions")
+	}
+
+	entries, _ := trail.List(context.Background(), audit.Filter{ResourceID: "AUDITOR"})
+	if len(entries) != 1 || entries[0].Action != "role.update" {
+		t.Fatalf("unexpected audit trail: %+v", entries)
+	}
+
+	for target, want := range map[string]int{
+		"/api/v1/admin/roles/admin": http.StatusBadRequest,
+		"/api/v1/admin/roles/ghost": http.StatusNotFound,
+	} {
+		if rec := do(update, admin, http.MethodPut, target, `{"permissions":[]}`); rec.Code != want {
+			t.Errorf("PUT %s: status %d, want %d", target, rec.Code, want)
+		}
+	}
+	if rec := do(update, admin, http.MethodPut, "/api/v1/admin/roles/operator", `{"permissions":["nodes:nuke"]}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("unknown permission: status %d, want 400", rec.Code)
+	}
+}
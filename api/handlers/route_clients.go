@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// RoutesInvalidated tells route WebSocket clients that routing changed and
+// routes they were sent may no longer hold, so they should request them again
+type RoutesInvalidated struct {
+	Type   string `json:"type"`   // Always "routes_invalidated"
+	Reason string `json:"reason"` // What changed, e.g. "blocked_countries"
+}
+
+// routeClients tracks the connected route WebSocket clients. Writes to a
+// connection are serialized, since responses and invalidations are sent from
+// different goroutines.
+type routeClients struct {
+	mu    sync.Mutex
+	conns map[*websocket.Conn]*sync.Mutex
+}
+
+func (c *routeClients) add(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conns == nil {
+		c.conns = make(map[*websocket.Conn]*sync.Mutex)
+	}
+	c.conns[conn] = &sync.Mutex{}
+}
+
+func (c *routeClients) remove(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.conns, conn)
+}
+
+// write sends data to conn, serialized with other writes to a tracked connection
+func (c *routeClients) write(conn *websocket.Conn, data []byte) error {
+	c.mu.Lock()
+	writeMu := c.conns[conn]
+	c.mu.Unlock()
+	if writeMu != nil {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+	}
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// broadcast sends data to every tracked connection and returns how many received it
+func (c *routeClients) broadcast(data []byte) int {
+	c.mu.Lock()
+	conns := make([]*websocket.Conn, 0, len(c.conns))
+	for conn := range c.conns {
+		conns = append(conns, conn)
+	}
+	c.mu.Unlock()
+
+	sent := 0
+	for _, conn := range conns {
+		if err := c.write(conn, data); err != nil {
+			slog.Warn("failed to notify route client", "error", err)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// InvalidateRoutes tells connected route WebSocket clients to request their
+// routes again and returns how many were told
+func (h *RouteHandler) InvalidateRoutes(reason string) int {
+	data, _ := json.Marshal(RoutesInvalidated{Type: "routes_invalidated", Reason: reason})
+	return h.clients.broadcast(data)
+}
@@ -4,12 +4,16 @@ package handlers
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/auth"
 	"github.com/plm/predictive-liquidity-mesh/engine/router"
 )
 
@@ -20,6 +24,37 @@ type RouteRequest struct {
 	Target       string   `json:"target"`        // Target country code
 	BlockedCodes []string `json:"blocked_codes"` // Countries to avoid
 	Amount       float64  `json:"amount"`        // Optional: amount to transfer
+
+	// Optional: what to optimize for. Preference is balanced (default), cheapest,
+	// fastest or most_reliable; Weights sets custom coefficients instead.
+	Preference string             `json:"preference,omitempty"`
+	Weights    *router.Preference `json:"weights,omitempty"`
+
+	// Optional: max_hops, avoid (on top of blocked_codes) and via waypoints
+	router.RouteConstraints
+}
+
+// validate checks the endpoints, blocked countries and amount
+func (req *RouteRequest) validate(known func(string) bool) error {
+	var v validate.Validator
+	v.Country("source", req.Source, known)
+	v.Country("target", req.Target, known)
+	v.Countries("blocked_codes", req.BlockedCodes, nil) // Blocking a country outside the mesh is harmless
+	if req.Amount != 0 {
+		v.Amount("amount", req.Amount, "")
+	}
+	return v.Err()
+}
+
+// preference resolves the requested routing preference
+func (req *RouteRequest) preference() (router.Preference, error) {
+	if req.Weights != nil {
+		if err := req.Weights.Validate(); err != nil {
+			return router.Preference{}, err
+		}
+		return *req.Weights, nil
+	}
+	return router.ParsePreference(req.Preference)
 }
 
 // RouteResponse represents the routing response
@@ -40,6 +75,50 @@ type RoutePathInfo struct {
 	TotalFeePercent float64 `json:"total_fee_percent"` // Fee as percentage
 	FinalAmount    float64  `json:"final_amount"`      // Amount after fees (per 1.0)
 	CalculatedFee  float64  `json:"calculated_fee,omitempty"` // Actual fee if amount provided
+	TotalLatencyMs int64    `json:"total_latency_ms"`
+	Reliability    float64  `json:"reliability"` // Probability every hop succeeds
+
+	// When the payment settles: historical hop processing times, plus waits
+	// for countries outside their settlement hours or between batches
+	EstimatedCompletionAt time.Time `json:"estimated_completion_at"`
+	EstimatedDurationMs   int64     `json:"estimated_duration_ms"`
+	WaitMs                int64     `json:"wait_ms"`
+	WithinHours           bool      `json:"within_hours"` // No country on the path is closed
+
+	// Trade-offs against the rank 1 path (positive = this path is worse)
+	FeeDeltaPercent  float64 `json:"fee_delta_percent"`
+	LatencyDeltaMs   int64   `json:"latency_delta_ms"`
+	ReliabilityDelta float64 `json:"reliability_delta"` // Negative = less reliable
+}
+
+// buildRoutePaths converts router paths to response paths annotated with their
+// trade-offs against the best path
+func buildRoutePaths(paths []*router.CountryPath, amount float64) []*RoutePathInfo {
+	infos := make([]*RoutePathInfo, len(paths))
+	for i, path := range paths {
+		infos[i] = &RoutePathInfo{
+			Rank:             i + 1,
+			Nodes:            path.Nodes,
+			HopCount:         path.HopCount,
+			TotalWeight:      path.TotalWeight,
+			TotalFeePercent:  path.TotalFeePercent,
+			FinalAmount:      path.FinalAmount,
+			TotalLatencyMs:   path.TotalLatencyMs,
+			Reliability:      path.Reliability,
+			FeeDeltaPercent:  path.TotalFeePercent - paths[0].TotalFeePercent,
+			LatencyDeltaMs:   path.TotalLatencyMs - paths[0].TotalLatencyMs,
+			ReliabilityDelta: path.Reliability - paths[0].Reliability,
+
+			EstimatedCompletionAt: path.EstimatedCompletionAt,
+			EstimatedDurationMs:   path.EstimatedDurationMs,
+			WaitMs:                path.WaitMs,
+			WithinHours:           path.WithinHours,
+		}
+		if amount > 0 {
+			infos[i].CalculatedFee = amount * (1 - path.FinalAmount)
+		}
+	}
+	return infos
 }
 
 // RouteHandler handles WebSocket connections for route calculation
@@ -47,6 +126,8 @@ type RouteHandler struct {
 	router   *router.CountryRouter
 	graph    *router.CountryGraph
 	upgrader websocket.Upgrader
+	tokens   *auth.TokenManager // nil = WebSocket authentication disabled
+	clients  routeClients       // Connected WebSocket clients, told when routes go stale
 }
 
 // NewRouteHandler creates a new route handler
@@ -67,23 +148,59 @@ func NewRouteHandler(graph *router.CountryGraph) *RouteHandler {
 	}
 }
 
+// SetCache enables caching of route results (see router.CountryRouter.SetCache)
+func (h *RouteHandler) SetCache(size int, ttl time.Duration) {
+	h.router.SetCache(size, ttl)
+}
+
+// SetHopTimes sets the historical hop processing times used for completion estimates
+func (h *RouteHandler) SetHopTimes(times *router.HopTimes) {
+	h.router.SetHopTimes(times)
+}
+
+// SetTokenManager requires WebSocket clients to authenticate, either with a
+// token on upgrade or with {"type":"auth","token":"..."} as their first message
+func (h *RouteHandler) SetTokenManager(tm *auth.TokenManager) {
+	h.tokens = tm
+}
+
 // HandleRouteWS handles WebSocket connections for routing
 func (h *RouteHandler) HandleRouteWS(w http.ResponseWriter, r *http.Request) {
+	authenticated := h.tokens == nil
+	if token := middleware.TokenFromRequest(r); !authenticated && token != "" {
+		if _, err := h.tokens.VerifyToken(token); err != nil {
+			apierror.Respond(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		authenticated = true
+	}
+
 	conn, err := h.upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade failed: %v", err)
+		slog.WarnContext(r.Context(), "route websocket upgrade failed", "error", err)
 		return
 	}
 	defer conn.Close()
 
-	log.Println("Route WebSocket client connected")
+	slog.InfoContext(r.Context(), "route websocket client connected")
+
+	if !authenticated {
+		if err := h.authenticateWS(conn); err != nil {
+			slog.WarnContext(r.Context(), "route websocket authentication failed", "error", err)
+			h.sendError(conn, "authentication required")
+			return
+		}
+	}
+
+	h.clients.add(conn)
+	defer h.clients.remove(conn)
 
 	for {
 		// Read request
 		_, message, err := conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				log.Printf("Route WebSocket error: %v", err)
+				slog.WarnContext(r.Context(), "route websocket error", "error", err)
 			}
 			break
 		}
@@ -97,18 +214,40 @@ func (h *RouteHandler) HandleRouteWS(w http.ResponseWriter, r *http.Request) {
 
 		// Handle route request
 		if req.Type == "route_request" {
-			h.handleRouteRequest(conn, &req)
+			h.handleRouteRequest(r.Context(), conn, &req)
 		}
 	}
 }
 
+// authenticateWS reads the auth message a client must send first when it did
+// not pass a token on upgrade
+func (h *RouteHandler) authenticateWS(conn *websocket.Conn) error {
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	var req struct {
+		Type  string `json:"type"`
+		Token string `json:"token"`
+	}
+	if err := conn.ReadJSON(&req); err != nil {
+		return err
+	}
+	if req.Type != "auth" || req.Token == "" {
+		return errors.New("first message must be an auth request")
+	}
+	if _, err := h.tokens.VerifyToken(req.Token); err != nil {
+		return err
+	}
+	return conn.WriteJSON(map[string]interface{}{"type": "auth_response", "success": true})
+}
+
 // handleRouteRequest processes a routing request and sends response
-func (h *RouteHandler) handleRouteRequest(conn *websocket.Conn, req *RouteRequest) {
+func (h *RouteHandler) handleRouteRequest(ctx context.Context, conn *websocket.Conn, req *RouteRequest) {
 	start := time.Now()
 
 	// Validate request
-	if req.Source == "" || req.Target == "" {
-		h.sendError(conn, "source and target are required")
+	if err := req.validate(h.graph.HasNode); err != nil {
+		h.sendError(conn, err.Error())
 		return
 	}
 
@@ -117,12 +256,21 @@ func (h *RouteHandler) handleRouteRequest(conn *websocket.Conn, req *RouteReques
 		return
 	}
 
+	pref, err := req.preference()
+	if err == nil {
+		err = req.RouteConstraints.Validate()
+	}
+	if err != nil {
+		h.sendError(conn, err.Error())
+		return
+	}
+
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	// Find paths
-	paths, err := h.router.FindKShortestPaths(ctx, req.Source, req.Target, req.BlockedCodes)
+	paths, err := h.router.WithPreference(pref).WithConstraints(req.RouteConstraints).FindKShortestPaths(ctx, req.Source, req.Target, req.BlockedCodes)
 	
 	response := &RouteResponse{
 		Type:     "route_response",
@@ -134,31 +282,13 @@ func (h *RouteHandler) handleRouteRequest(conn *websocket.Conn, req *RouteReques
 		response.Error = err.Error()
 	} else {
 		response.Success = true
-		response.Paths = make([]*RoutePathInfo, len(paths))
-		
-		for i, path := range paths {
-			pathInfo := &RoutePathInfo{
-				Rank:            i + 1,
-				Nodes:           path.Nodes,
-				HopCount:        path.HopCount,
-				TotalWeight:     path.TotalWeight,
-				TotalFeePercent: path.TotalFeePercent,
-				FinalAmount:     path.FinalAmount,
-			}
-			
-			// Calculate actual fee if amount provided
-			if req.Amount > 0 {
-				pathInfo.CalculatedFee = req.Amount * (1 - path.FinalAmount)
-			}
-			
-			response.Paths[i] = pathInfo
-		}
+		response.Paths = buildRoutePaths(paths, req.Amount)
 	}
 
 	// Send response
 	data, _ := json.Marshal(response)
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		log.Printf("Failed to send route response: %v", err)
+	if err := h.clients.write(conn, data); err != nil {
+		slog.WarnContext(ctx, "failed to send route response", "error", err)
 	}
 }
 
@@ -170,25 +300,33 @@ func (h *RouteHandler) sendError(conn *websocket.Conn, errorMsg string) {
 		Error:   errorMsg,
 	}
 	data, _ := json.Marshal(response)
-	conn.WriteMessage(websocket.TextMessage, data)
+	h.clients.write(conn, data)
 }
 
 // HandleRouteHTTP handles HTTP POST requests for routing (non-WebSocket)
 func (h *RouteHandler) HandleRouteHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	var req RouteRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, `{"error":"invalid request"}`, http.StatusBadRequest)
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// Validate
-	if req.Source == "" || req.Target == "" {
-		http.Error(w, `{"error":"source and target are required"}`, http.StatusBadRequest)
+	if err := req.validate(h.graph.HasNode); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	pref, err := req.preference()
+	if err == nil {
+		err = req.RouteConstraints.Validate()
+	}
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -196,7 +334,7 @@ func (h *RouteHandler) HandleRouteHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	paths, err := h.router.FindKShortestPaths(ctx, req.Source, req.Target, req.BlockedCodes)
+	paths, err := h.router.WithPreference(pref).WithConstraints(req.RouteConstraints).FindKShortestPaths(ctx, req.Source, req.Target, req.BlockedCodes)
 
 	w.Header().Set("Content-Type", "application/json")
 
@@ -211,21 +349,7 @@ func (h *RouteHandler) HandleRouteHTTP(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK) // Still 200, error in response
 	} else {
 		response.Success = true
-		response.Paths = make([]*RoutePathInfo, len(paths))
-		
-		for i, path := range paths {
-			response.Paths[i] = &RoutePathInfo{
-				Rank:            i + 1,
-				Nodes:           path.Nodes,
-				HopCount:        path.HopCount,
-				TotalWeight:     path.TotalWeight,
-				TotalFeePercent: path.TotalFeePercent,
-				FinalAmount:     path.FinalAmount,
-			}
-			if req.Amount > 0 {
-				response.Paths[i].CalculatedFee = req.Amount * (1 - path.FinalAmount)
-			}
-		}
+		response.Paths = buildRoutePaths(paths, req.Amount)
 	}
 
 	json.NewEncoder(w).Encode(response)
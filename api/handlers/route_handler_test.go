@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+func TestRouteReportsInvalidFields(t *testing.T) {
+	h := NewRouteHandler(router.BuildCountryGraphWithDefaults())
+
+	rec := httptest.NewRecorder()
+	h.HandleRouteHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/route",
+		strings.NewReader(`{"source":"usa","target":"XYZ","blocked_codes":["DEU","D"],"amount":10.505}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body.String())
+	}
+
+	var body struct {
+		Code    apierror.Code         `json:"code"`
+		Details []validate.FieldError `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if body.Code != apierror.CodeValidationFailed {
+		t.Errorf("code = %s", body.Code)
+	}
+	var fields []string
+	for _, f := range body.Details {
+		fields = append(fields, f.Field)
+	}
+	if got := strings.Join(fields, ","); got != "source,target,blocked_codes[1],amount" {
+		t.Errorf("invalid fields = %s", got)
+	}
+
+	rec = httptest.NewRecorder()
+	h.HandleRouteHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/route", strings.NewReader(`{"source":"USA","target":"DEU","amount":1000}`)))
+	if rec.Code != http.StatusOK {
+		t.Errorf("valid request: status = %d, body %s", rec.Code, rec.Body.String())
+	}
+	var resp RouteResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || len(resp.Paths) == 0 {
+		t.Fatalf("decode: %v, body %s", err, rec.Body.String())
+	}
+	if eta := resp.Paths[0].EstimatedCompletionAt; eta.IsZero() || resp.Paths[0].EstimatedDurationMs < resp.Paths[0].TotalLatencyMs {
+		t.Errorf("estimated completion = %s after %dms, want at least the path latency", eta, resp.Paths[0].EstimatedDurationMs)
+	}
+}
+
+func TestRouteWSInvalidation(t *testing.T) {
+	h := NewRouteHandler(router.BuildCountryGraphWithDefaults())
+	srv := httptest.NewServer(http.HandlerFunc(h.HandleRouteWS))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	// A route request round trip guarantees the client is tracked
+	conn.WriteJSON(map[string]interface{}{"type": "route_request", "source": "USA", "target": "DEU", "amount": 100})
+	var resp RouteResponse
+	if err := conn.ReadJSON(&resp); err != nil || !resp.Success {
+		t.Fatalf("route response = %+v, %v", resp, err)
+	}
+
+	if sent := h.InvalidateRoutes("blocked_countries"); sent != 1 {
+		t.Fatalf("invalidation sent to %d clients, want 1", sent)
+	}
+	var msg RoutesInvalidated
+	if err := conn.ReadJSON(&msg); err != nil || msg.Type != "routes_invalidated" || msg.Reason != "blocked_countries" {
+		t.Errorf("message = %+v, %v; want a routes_invalidated for blocked_countries", msg, err)
+	}
+
+	conn.Close()
+	deadline := time.Now().Add(5 * time.Second)
+	for h.InvalidateRoutes("blocked_countries") != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("closed client still tracked")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
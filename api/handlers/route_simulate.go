@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// RouteSimulationRequest is a route request evaluated under hypothetical conditions
+type RouteSimulationRequest struct {
+	RouteRequest
+	WhatIf router.Hypothesis `json:"what_if"`
+}
+
+// RouteOutcome is the routing result under one set of conditions
+type RouteOutcome struct {
+	Paths []*RoutePathInfo `json:"paths,omitempty"`
+	Error string           `json:"error,omitempty"`
+}
+
+// RouteComparison compares the simulated best route against the current one
+// (positive deltas = the simulated route is worse)
+type RouteComparison struct {
+	Reachable        bool    `json:"reachable"` // The simulated conditions still leave a route
+	RouteChanged     bool    `json:"route_changed"`
+	HopDelta         int     `json:"hop_delta"`
+	FeeDeltaPercent  float64 `json:"fee_delta_percent"`
+	LatencyDeltaMs   int64   `json:"latency_delta_ms"`
+	ReliabilityDelta float64 `json:"reliability_delta"`   // Negative = less reliable
+	FeeDelta         float64 `json:"fee_delta,omitempty"` // On amount, if provided
+}
+
+// RouteSimulationResponse is the response of POST /api/v1/route/simulate
+type RouteSimulationResponse struct {
+	Success    bool             `json:"success"`
+	Current    *RouteOutcome    `json:"current"`
+	Simulated  *RouteOutcome    `json:"simulated"`
+	Comparison *RouteComparison `json:"comparison,omitempty"` // Omitted when there is no current route
+	Duration   int64            `json:"duration_ms"`
+}
+
+// HandleRouteSimulate handles POST /api/v1/route/simulate: routes a request
+// both on the current graph and on a copy with the what-if conditions
+// applied, leaving the shared graph untouched
+func (h *RouteHandler) HandleRouteSimulate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	var req RouteSimulationRequest
+	if err := validate.Decode(r, &req); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err := req.validate(h.graph.HasNode); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if req.WhatIf.IsZero() {
+		apierror.Respond(w, http.StatusBadRequest, "what_if is required")
+		return
+	}
+	pref, err := req.preference()
+	if err == nil {
+		err = req.RouteConstraints.Validate()
+	}
+	var sim *router.CountryGraph
+	if err == nil {
+		sim, err = h.graph.Hypothetical(req.WhatIf)
+	}
+	if err != nil {
+		apierror.Respond(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	current := routeOutcome(ctx, h.router.WithPreference(pref).WithConstraints(req.RouteConstraints), &req.RouteRequest)
+	// A throwaway router without the shared cache: its graph is the copy
+	simRouter := router.NewCountryRouter(sim, 3).WithPreference(pref).WithConstraints(req.RouteConstraints)
+	simulated := routeOutcome(ctx, simRouter, &req.RouteRequest)
+
+	resp := &RouteSimulationResponse{
+		Success:   true,
+		Current:   current,
+		Simulated: simulated,
+	}
+	if len(current.Paths) > 0 {
+		resp.Comparison = compareRoutes(current.Paths[0], simulated.Paths, req.Amount)
+	}
+	resp.Duration = time.Since(start).Milliseconds()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// routeOutcome routes req, recording a routing failure in the outcome
+func routeOutcome(ctx context.Context, cr *router.CountryRouter, req *RouteRequest) *RouteOutcome {
+	paths, err := cr.FindKShortestPaths(ctx, req.Source, req.Target, req.BlockedCodes)
+	if err != nil {
+		return &RouteOutcome{Error: err.Error()}
+	}
+	return &RouteOutcome{Paths: buildRoutePaths(paths, req.Amount)}
+}
+
+// compareRoutes compares the best simulated path against the current best
+func compareRoutes(current *RoutePathInfo, simulated []*RoutePathInfo, amount float64) *RouteComparison {
+	if len(simulated) == 0 {
+		return &RouteComparison{RouteChanged: true}
+	}
+	best := simulated[0]
+	c := &RouteComparison{
+		Reachable:        true,
+		RouteChanged:     !slices.Equal(current.Nodes, best.Nodes),
+		HopDelta:         best.HopCount - current.HopCount,
+		FeeDeltaPercent:  best.TotalFeePercent - current.TotalFeePercent,
+		LatencyDeltaMs:   best.TotalLatencyMs - current.TotalLatencyMs,
+		ReliabilityDelta: best.Reliability - current.Reliability,
+	}
+	if amount > 0 {
+		c.FeeDelta = best.CalculatedFee - current.CalculatedFee
+	}
+	return c
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+func TestRouteSimulateComparesWithoutMutating(t *testing.T) {
+	graph := router.BuildCountryGraphWithDefaults()
+	h := NewRouteHandler(graph)
+
+	simulate := func(body string) (*httptest.ResponseRecorder, RouteSimulationResponse) {
+		rec := httptest.NewRecorder()
+		h.HandleRouteSimulate(rec, httptest.NewRequest(http.MethodPost, "/api/v1/route/simulate", strings.NewReader(body)))
+		var resp RouteSimulationResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec, resp
+	}
+
+	rec, resp := simulate(`{"source":"USA","target":"AUT","amount":1000,
+		"what_if":{"blocked":["CHE"],"countries":{"DEU":{"credibility":0.7}}}}`)
+	if rec.Code != http.StatusOK || !resp.Success {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if !slices.Contains(resp.Current.Paths[0].Nodes, "CHE") {
+		t.Fatalf("expected the current route via CHE, got %v", resp.Current.Paths[0].Nodes)
+	}
+	for _, p := range resp.Simulated.Paths {
+		if slices.Contains(p.Nodes, "CHE") {
+			t.Errorf("simulated path through blocked CHE: %v", p.Nodes)
+		}
+	}
+	if c := resp.Comparison; c == nil || !c.Reachable || !c.RouteChanged || c.FeeDelta == 0 {
+		t.Errorf("unexpected comparison: %+v", c)
+	}
+	if c, _, _ := graph.CountryScore("DEU"); graph.IsBlocked("CHE") || c != 0.96 {
+		t.Error("simulation changed the shared graph")
+	}
+
+	// Unreachable under the hypothesis
+	_, resp = simulate(`{"source":"FRA","target":"POL","what_if":{"blocked":["POL"]}}`)
+	if resp.Simulated.Error == "" || resp.Comparison == nil || resp.Comparison.Reachable {
+		t.Errorf("expected an unreachable simulation, got %+v %+v", resp.Simulated, resp.Comparison)
+	}
+
+	for name, body := range map[string]string{
+		"no what_if":      `{"source":"FRA","target":"POL"}`,
+		"unknown country": `{"source":"FRA","target":"POL","what_if":{"blocked":["XXX"]}}`,
+		"no target":       `{"source":"FRA","what_if":{"blocked":["DEU"]}}`,
+	} {
+		if rec, _ := simulate(body); rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, rec.Code)
+		}
+	}
+}
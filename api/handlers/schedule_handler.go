@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/payments/scheduler"
+)
+
+// ScheduleHandler handles scheduled payment endpoints
+type ScheduleHandler struct {
+	scheduler *scheduler.Scheduler
+}
+
+// NewScheduleHandler creates a new schedule handler
+func NewScheduleHandler(s *scheduler.Scheduler) *ScheduleHandler {
+	return &ScheduleHandler{scheduler: s}
+}
+
+// CreateScheduleRequest is the request body for creating a scheduled payment
+type CreateScheduleRequest struct {
+	Source         string              `json:"source"`
+	Target         string              `json:"target"`
+	Amount         float64             `json:"amount"`
+	Currency       string              `json:"currency"`
+	TargetCurrency string              `json:"target_currency"`
+	Frequency      scheduler.Frequency `json:"frequency"`          // once, daily, weekly or monthly
+	StartAt        *time.Time          `json:"start_at,omitempty"` // RFC 3339; omitted = now
+	EndAt          *time.Time          `json:"end_at,omitempty"`
+}
+
+// HandleSchedules handles GET (list) and POST (create) /api/v1/payments/schedules
+func (h *ScheduleHandler) HandleSchedules(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		schedules, err := h.scheduler.List(r.Context(), userID)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "failed to list schedules", "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to list schedules")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"schedules": schedules,
+			"count":     len(schedules),
+		})
+
+	case http.MethodPost:
+		var req CreateScheduleRequest
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		sched := &scheduler.Schedule{
+			UserID:         userID,
+			Source:         strings.ToUpper(req.Source),
+			Target:         strings.ToUpper(req.Target),
+			Amount:         req.Amount,
+			Currency:       strings.ToUpper(req.Currency),
+			TargetCurrency: strings.ToUpper(req.TargetCurrency),
+			Frequency:      req.Frequency,
+			StartAt:        time.Now(),
+			EndAt:          req.EndAt,
+		}
+		if req.StartAt != nil {
+			sched.StartAt = *req.StartAt
+		}
+
+		var v validate.Validator
+		v.Country("source", sched.Source, nil)
+		v.Country("target", sched.Target, nil)
+		v.Currency("currency", sched.Currency)
+		v.Currency("target_currency", sched.TargetCurrency)
+		if err := v.Err(); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if err := sched.Validate(time.Now()); err != nil {
+			apierror.Respond(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err := h.scheduler.Create(r.Context(), sched); err != nil {
+			slog.ErrorContext(r.Context(), "failed to create schedule", "error", err)
+			apierror.Respond(w, http.StatusInternalServerError, "failed to create schedule")
+			return
+		}
+		slog.InfoContext(r.Context(), "payment scheduled", "schedule_id", sched.ID, "frequency", sched.Frequency, "next_run_at", sched.NextRunAt)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(sched)
+
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// HandleSchedule handles GET and DELETE (cancel) /api/v1/payments/schedules/{id}
+func (h *ScheduleHandler) HandleSchedule(w http.ResponseWriter, r *http.Request) {
+	userID := getUserIDFromContext(r)
+	if userID == "" {
+		apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+
+	id := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/payments/schedules/"), "/")
+	if id == "" {
+		apierror.Respond(w, http.StatusBadRequest, "schedule id required")
+		return
+	}
+
+	var sched *scheduler.Schedule
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		sched, err = h.scheduler.Get(r.Context(), userID, id)
+	case http.MethodDelete:
+		sched, err = h.scheduler.Cancel(r.Context(), userID, id)
+		if err == nil {
+			slog.InfoContext(r.Context(), "schedule cancelled", "schedule_id", id)
+		}
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if errors.Is(err, scheduler.ErrNotFound) {
+		apierror.Respond(w, http.StatusNotFound, "schedule not found")
+		return
+	}
+	if errors.Is(err, scheduler.ErrNotActive) {
+		apierror.Respond(w, http.StatusConflict, "schedule is not active")
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(r.Context(), "schedule request failed", "schedule_id", id, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sched)
+}
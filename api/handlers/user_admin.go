@@ -0,0 +1,245 @@
+// Package handlers provides admin user management endpoints
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+// Pagination limits for the admin user list
+const (
+	defaultUserPageSize = 50
+	maxUserPageSize     = 500
+)
+
+// UserAdminHandler handles admin user management
+type UserAdminHandler struct {
+	store users.Storer
+	audit audit.Store
+}
+
+// NewUserAdminHandler creates a new user admin handler
+func NewUserAdminHandler(store users.Storer) *UserAdminHandler {
+	return &UserAdminHandler{store: store}
+}
+
+// SetAuditStore records account and role changes in store
+func (h *UserAdminHandler) SetAuditStore(store audit.Store) {
+	h.audit = store
+}
+
+// UserListResponse is a page of users
+type UserListResponse struct {
+	Users      []*auth.User `json:"users"`
+	Total      int          `json:"total"`
+	Page       int          `json:"page"`
+	PageSize   int          `json:"page_size"`
+	TotalPages int          `json:"total_pages"`
+}
+
+// HandleListUsers handles GET /api/v1/admin/users
+// Query params: page, page_size, role, active (true/false), format=csv (exports every match)
+func (h *UserAdminHandler) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	q := r.URL.Query()
+	var role auth.Role
+	if v := q.Get("role"); v != "" {
+		role = auth.Role(strings.ToUpper(v))
+	}
+	var active *bool
+	if v := q.Get("active"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			apierror.Respond(w, http.StatusBadRequest, "active must be true or false")
+			return
+		}
+		active = &b
+	}
+
+	matched := filterUsers(h.store.ListUsers(), role, active)
+
+	if q.Get("format") == "csv" {
+		writeUsersCSV(w, matched)
+		return
+	}
+
+	page := parsePositiveInt(q.Get("page"), 1)
+	pageSize := parsePositiveInt(q.Get("page_size"), defaultUserPageSize)
+	if pageSize > maxUserPageSize {
+		pageSize = maxUserPageSize
+	}
+
+	start := (page - 1) * pageSize
+	if start > len(matched) {
+		start = len(matched)
+	}
+	end := start + pageSize
+	if end > len(matched) {
+		end = len(matched)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserListResponse{
+		Users:      matched[start:end],
+		Total:      len(matched),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: (len(matched) + pageSize - 1) / pageSize,
+	})
+}
+
+// HandleUpdateUser handles PATCH /api/v1/admin/users/{id}
+// Body: {"is_active": false} to deactivate, {"role": "ADMIN"} to change role
+func (h *UserAdminHandler) HandleUpdateUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	admin := middleware.GetUserFromContext(r.Context())
+	if admin == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	userID := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/admin/users/"), "/")
+	if userID == "" || strings.Contains(userID, "/") {
+		apierror.Respond(w, http.StatusBadRequest, "user id required")
+		return
+	}
+
+	var update users.UserUpdate
+	if err := validate.Decode(r, &update); err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if update.IsActive == nil && update.Role == nil {
+		apierror.Respond(w, http.StatusBadRequest, "nothing to update")
+		return
+	}
+	if update.Role != nil {
+		role := auth.Role(strings.ToUpper(string(*update.Role)))
+		update.Role = &role
+	}
+
+	// Admins cannot lock themselves out
+	if userID == admin.ID {
+		if (update.IsActive != nil && !*update.IsActive) || (update.Role != nil && *update.Role != auth.RoleAdmin) {
+			apierror.Respond(w, http.StatusForbidden, "cannot deactivate or demote yourself")
+			return
+		}
+	}
+
+	before := findUser(h.store.ListUsers(), userID)
+	user, err := h.store.UpdateUser(userID, update)
+	switch {
+	case errors.Is(err, users.ErrUserNotFound):
+		apierror.Respond(w, http.StatusNotFound, "user not found")
+		return
+	case errors.Is(err, users.ErrInvalidRole):
+		apierror.Respond(w, http.StatusBadRequest, "invalid role")
+		return
+	case err != nil:
+		slog.ErrorContext(r.Context(), "failed to update user", "user_id", userID, "error", err)
+		apierror.Respond(w, http.StatusInternalServerError, "failed to update user")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "user updated", "user_id", user.ID, "admin", admin.Username, "role", user.Role, "active", user.IsActive)
+	action := "user.update"
+	if before != nil && before.Role != user.Role {
+		action = "user.role_change"
+	}
+	recordAudit(h.audit, r, http.StatusOK, action, "user", user.ID, before, user)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// findUser returns the user with the given ID, or nil
+func findUser(all []*auth.User, id string) *auth.User {
+	for _, u := range all {
+		if u.ID == id {
+			return u
+		}
+	}
+	return nil
+}
+
+// filterUsers returns users matching role and active, oldest first
+func filterUsers(all []*auth.User, role auth.Role, active *bool) []*auth.User {
+	result := make([]*auth.User, 0, len(all))
+	for _, u := range all {
+		if role != "" && u.Role != role {
+			continue
+		}
+		if active != nil && u.IsActive != *active {
+			continue
+		}
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].ID < result[j].ID
+		}
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// writeUsersCSV streams users as a CSV attachment
+func writeUsersCSV(w http.ResponseWriter, list []*auth.User) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename=users.csv")
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "email", "username", "role", "full_name", "organization", "is_active", "created_at"})
+	for _, u := range list {
+		cw.Write([]string{
+			u.ID,
+			csvSafe(u.Email),
+			csvSafe(u.Username),
+			string(u.Role),
+			csvSafe(u.FullName),
+			csvSafe(u.Organization),
+			strconv.FormatBool(u.IsActive),
+			u.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	cw.Flush()
+}
+
+// csvSafe stops spreadsheet apps from evaluating user-supplied cells as formulas
+func csvSafe(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// parsePositiveInt parses s, falling back to def for missing or invalid values
+func parsePositiveInt(s string, def int) int {
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
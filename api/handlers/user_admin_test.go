@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+func TestAdminUsersListFilterAndUpdate(t *testing.T) {
+	store := users.NewStore()
+	for _, name := range []string{"carol", "dave", "=evil"} {
+		if _, err := store.CreateUser(strings.Trim(name, "=")+"@example.com", "Password123!", name, auth.RoleUser); err != nil {
+			t.Fatalf("CreateUser(%s) failed: %v", name, err)
+		}
+	}
+	h := NewUserAdminHandler(store)
+	trail := audit.NewMemoryStore(10)
+	h.SetAuditStore(trail)
+
+	var admin *auth.User
+	for _, u := range store.ListUsers() {
+		if u.Role == auth.RoleAdmin {
+			admin = u
+		}
+	}
+
+	do := func(handler http.HandlerFunc, method, target, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserContextKey, admin))
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	rec := do(h.HandleListUsers, http.MethodGet, "/api/v1/admin/users?role=user&page=2&page_size=2", "")
+	var page UserListResponse
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatalf("decode failed: %v", err)
+	}
+	// Default "user" account plus the three created above
+	if page.Total != 4 || page.TotalPages != 2 || len(page.Users) != 2 {
+		t.Fatalf("unexpected page: total=%d pages=%d len=%d", page.Total, page.TotalPages, len(page.Users))
+	}
+
+	dave, _ := store.GetByEmail("dave@example.com")
+	daveID := dave.ToUser().ID
+	rec = do(h.HandleUpdateUser, http.MethodPatch, "/api/v1/admin/users/"+daveID, `{"is_active":false,"role":"service"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("PATCH status %d: %s", rec.Code, rec.Body)
+	}
+	if _, err := store.Authenticate("dave@example.com", "Password123!"); err == nil {
+		t.Fatal("deactivated user must not be able to log in")
+	}
+
+	entries, _ := trail.List(context.Background(), audit.Filter{ResourceID: daveID})
+	if len(entries) != 1 || entries[0].Action != "user.role_change" || entries[0].ActorID != admin.ID {
+		t.Fatalf("unexpected audit trail: %+v", entries)
+	}
+	var before, after auth.User
+	if err := json.Unmarshal(entries[0].Before, &before); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(entries[0].After, &after); err != nil {
+		t.Fatal(err)
+	}
+	if before.Role != auth.RoleUser || !before.IsActive || after.Role != "SERVICE" || after.IsActive {
+		t.Fatalf("audit state before=%+v after=%+v", before, after)
+	}
+
+	rec = do(h.HandleListUsers, http.MethodGet, "/api/v1/admin/users?active=false&format=csv", "")
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV parse failed: %v", err)
+	}
+	if len(rows) != 2 || rows[1][0] != daveID || rows[1][3] != "SERVICE" || rows[1][6] != "false" {
+		t.Fatalf("unexpected CSV: %v", rows)
+	}
+
+	rec = do(h.HandleListUsers, http.MethodGet, "/api/v1/admin/users?format=csv", "")
+	if !strings.Contains(rec.Body.String(), ",'=evil,") {
+		t.Fatalf("formula cell was not escaped: %s", rec.Body)
+	}
+
+	rec = do(h.HandleUpdateUser, http.MethodPatch, "/api/v1/admin/users/"+admin.ID, `{"role":"USER"}`)
+	if rec.Code != http.StatusForbidden {
+		t.Fatalf("self-demotion status %d, want 403", rec.Code)
+	}
+	rec = do(h.HandleUpdateUser, http.MethodPatch, "/api/v1/admin/users/"+daveID, `{"role":"ROOT"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid role status %d, want 400", rec.Code)
+	}
+}
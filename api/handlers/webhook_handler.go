@@ -0,0 +1,150 @@
+// Package handlers provides webhook registration endpoints
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/validate"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/scheduler"
+	"github.com/plm/predictive-liquidity-mesh/webhooks"
+)
+
+// webhookEvents are the event types integrators can subscribe to
+var webhookEvents = map[string]bool{
+	string(payments.EventPaymentSucceeded): true,
+	string(payments.EventPaymentFailed):    true,
+	string(payments.EventPaymentRefunded):  true,
+	string(scheduler.EventExecuted):        true,
+	string(scheduler.EventFailed):          true,
+}
+
+// WebhookHandler handles webhook endpoint registration
+type WebhookHandler struct {
+	dispatcher *webhooks.Dispatcher
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(dispatcher *webhooks.Dispatcher) *WebhookHandler {
+	return &WebhookHandler{dispatcher: dispatcher}
+}
+
+// RegisterWebhookRequest is the request for registering a webhook endpoint
+type RegisterWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // Empty = all events
+}
+
+// RegisterWebhookResponse includes the signing secret, which is only shown once
+type RegisterWebhookResponse struct {
+	*webhooks.Endpoint
+	Secret string `json:"secret"`
+}
+
+// HandleWebhooks handles GET (list) and POST (register) /api/v1/webhooks
+func (h *WebhookHandler) HandleWebhooks(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		endpoints := h.dispatcher.List(user.ID)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"webhooks": endpoints,
+			"count":    len(endpoints),
+		})
+
+	case http.MethodPost:
+		var req RegisterWebhookRequest
+		if err := validate.Decode(r, &req); err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if err := middleware.ValidateExternalURL(req.URL); err != nil {
+			apierror.Respond(w, http.StatusBadRequest, "invalid url: "+err.Error())
+			return
+		}
+		for _, event := range req.Events {
+			if !webhookEvents[event] {
+				apierror.Respond(w, http.StatusBadRequest, "unknown event type")
+				return
+			}
+		}
+
+		// Admin endpoints receive events for every user's transactions
+		ep, err := h.dispatcher.Register(user.ID, req.URL, req.Events, user.IsAdmin())
+		if err != nil {
+			apierror.Respond(w, http.StatusInternalServerError, "failed to register webhook")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(RegisterWebhookResponse{Endpoint: ep, Secret: ep.Secret})
+
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
+
+// HandleWebhook handles DELETE /api/v1/webhooks/{id} and GET /api/v1/webhooks/{id}/deliveries
+func (h *WebhookHandler) HandleWebhook(w http.ResponseWriter, r *http.Request) {
+	user := middleware.GetUserFromContext(r.Context())
+	if user == nil {
+		apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+		return
+	}
+
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/webhooks/"), "/")
+	parts := strings.Split(path, "/")
+	if parts[0] == "" {
+		apierror.Respond(w, http.StatusBadRequest, "webhook id required")
+		return
+	}
+	endpointID := parts[0]
+
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodDelete:
+		if err := h.dispatcher.Remove(user.ID, endpointID); err != nil {
+			apierror.Respond(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		slog.InfoContext(r.Context(), "webhook removed", "webhook_id", endpointID, "user", user.Username)
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"id":      endpointID,
+		})
+
+	case len(parts) == 2 && parts[1] == "deliveries" && r.Method == http.MethodGet:
+		deliveries, err := h.dispatcher.Deliveries(user.ID, endpointID)
+		if errors.Is(err, webhooks.ErrEndpointNotFound) {
+			apierror.Respond(w, http.StatusNotFound, "webhook not found")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"deliveries": deliveries,
+			"count":      len(deliveries),
+		})
+
+	default:
+		apierror.Respond(w, http.StatusNotFound, "not found")
+	}
+}
+
+// DispatchTransactionEvent forwards a transaction lifecycle event to webhooks.
+// Use as the TransactionStore status callback.
+func (h *WebhookHandler) DispatchTransactionEvent(event payments.StatusEvent, txn *payments.Transaction) {
+	h.dispatcher.Dispatch(string(event), txn.UserID, txn)
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+// Audit records each request to the wrapped handler as action in store,
+// with the authenticated user as the actor. Place it after Authenticate.
+func Audit(store audit.Store, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := NewStatusRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			entry := NewAuditEntry(r, action)
+			entry.Status = rec.status
+			audit.Log(r.Context(), store, entry)
+		})
+	}
+}
+
+// NewAuditEntry starts an audit entry for action taken by the request's
+// authenticated user. Callers fill in the resource, its state and the status.
+func NewAuditEntry(r *http.Request, action string) *audit.Entry {
+	entry := &audit.Entry{
+		Timestamp:  time.Now().UTC(),
+		Action:     action,
+		Target:     r.URL.Path,
+		RequestID:  logging.RequestID(r.Context()),
+		RemoteAddr: r.RemoteAddr,
+		UserAgent:  r.UserAgent(),
+	}
+	if user := GetUserFromContext(r.Context()); user != nil {
+		entry.ActorID = user.ID
+		entry.ActorEmail = user.Email
+	}
+	return entry
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/audit"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+func TestAuditRecordsActorAndOutcome(t *testing.T) {
+	store := audit.NewMemoryStore(2)
+	handler := Audit(store, "chaos.kill_node")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/debug/kill/" {
+			http.Error(w, "Node ID required", http.StatusBadRequest)
+		}
+	}))
+
+	admin := &auth.User{ID: "admin-1", Email: "ops@example.com", Role: auth.RoleAdmin}
+	for _, path := range []string{"/debug/kill/lp_alpha", "/debug/kill/", "/debug/kill/hub_primary"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, admin))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	entries, err := store.List(context.Background(), audit.Filter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("entries = %d, want the 2 most recent", len(entries))
+	}
+	latest, rejected := entries[0], entries[1]
+	if latest.Target != "/debug/kill/hub_primary" || latest.Status != http.StatusOK {
+		t.Errorf("latest = %+v, want successful kill of hub_primary", latest)
+	}
+	if latest.ActorID != "admin-1" || latest.ActorEmail != "ops@example.com" || latest.Action != "chaos.kill_node" {
+		t.Errorf("latest actor/action = %s/%s/%s", latest.ActorID, latest.ActorEmail, latest.Action)
+	}
+	if rejected.Status != http.StatusBadRequest {
+		t.Errorf("rejected status = %d, want %d", rejected.Status, http.StatusBadRequest)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/auth"
 )
 
@@ -18,16 +19,24 @@ const (
 	UserContextKey ContextKey = "user"
 	// ClaimsContextKey is the context key for token claims
 	ClaimsContextKey ContextKey = "claims"
+	// PolicyContextKey is the context key for the role permission policy
+	PolicyContextKey ContextKey = "policy"
 )
 
 // AuthMiddleware provides authentication middleware
 type AuthMiddleware struct {
 	tokenManager *auth.TokenManager
+	policy       *auth.Policy
 }
 
-// NewAuthMiddleware creates a new auth middleware
+// NewAuthMiddleware creates a new auth middleware using the default role policy
 func NewAuthMiddleware(tm *auth.TokenManager) *AuthMiddleware {
-	return &AuthMiddleware{tokenManager: tm}
+	return &AuthMiddleware{tokenManager: tm, policy: auth.DefaultPolicy()}
+}
+
+// SetPolicy sets the role permission policy checked by RequirePermission
+func (m *AuthMiddleware) SetPolicy(policy *auth.Policy) {
+	m.policy = policy
 }
 
 // Authenticate validates the PASETO token and adds user to context
@@ -36,14 +45,14 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		// Extract token from Authorization header
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			http.Error(w, `{"error":"missing authorization header"}`, http.StatusUnauthorized)
+			apierror.Respond(w, http.StatusUnauthorized, "missing authorization header")
 			return
 		}
 
 		// Expect "Bearer <token>"
 		parts := strings.SplitN(authHeader, " ", 2)
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			http.Error(w, `{"error":"invalid authorization header format"}`, http.StatusUnauthorized)
+			apierror.Respond(w, http.StatusUnauthorized, "invalid authorization header format")
 			return
 		}
 
@@ -53,28 +62,33 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		claims, err := m.tokenManager.VerifyToken(token)
 		if err != nil {
 			if err == auth.ErrExpiredToken {
-				http.Error(w, `{"error":"token has expired"}`, http.StatusUnauthorized)
+				apierror.RespondCode(w, apierror.CodeTokenExpired, "token has expired")
 				return
 			}
-			http.Error(w, `{"error":"invalid token"}`, http.StatusUnauthorized)
+			apierror.Respond(w, http.StatusUnauthorized, "invalid token")
 			return
 		}
 
-		// Create user from claims
-		user := &auth.User{
-			ID:       claims.UserID,
-			Email:    claims.Email,
-			Username: claims.Username,
-			Role:     claims.Role,
-			IsActive: true,
-		}
+		next.ServeHTTP(w, r.WithContext(m.withClaims(r.Context(), claims)))
+	})
+}
 
-		// Add user and claims to context
-		ctx := context.WithValue(r.Context(), UserContextKey, user)
-		ctx = context.WithValue(ctx, ClaimsContextKey, claims)
+// AuthenticateToken verifies token and returns ctx carrying the user, claims
+// and policy, as Authenticate does for requests. It is for connections that
+// authenticate after the handshake, such as WebSocket sessions.
+func (m *AuthMiddleware) AuthenticateToken(ctx context.Context, token string) (context.Context, error) {
+	claims, err := m.tokenManager.VerifyToken(token)
+	if err != nil {
+		return ctx, err
+	}
+	return m.withClaims(ctx, claims), nil
+}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
-	})
+// withClaims adds the user, claims and policy to ctx
+func (m *AuthMiddleware) withClaims(ctx context.Context, claims *auth.TokenClaims) context.Context {
+	ctx = context.WithValue(ctx, UserContextKey, UserFromClaims(claims))
+	ctx = context.WithValue(ctx, ClaimsContextKey, claims)
+	return context.WithValue(ctx, PolicyContextKey, m.policy)
 }
 
 // RequireRole creates middleware that requires a specific role
@@ -83,12 +97,42 @@ func (m *AuthMiddleware) RequireRole(role auth.Role) func(http.Handler) http.Han
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			user := GetUserFromContext(r.Context())
 			if user == nil {
-				http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+				apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 				return
 			}
 
 			if !user.HasPermission(role) {
-				http.Error(w, `{"error":"insufficient permissions"}`, http.StatusForbidden)
+				apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequirePermission creates middleware that requires the user's role to hold perm
+func (m *AuthMiddleware) RequirePermission(perm auth.Permission) func(http.Handler) http.Handler {
+	return m.RequireMethodPermission(perm, perm)
+}
+
+// RequireMethodPermission creates middleware that requires read for GET and
+// HEAD requests and write for every other method
+func (m *AuthMiddleware) RequireMethodPermission(read, write auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user := GetUserFromContext(r.Context())
+			if user == nil {
+				apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
+				return
+			}
+
+			perm := write
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				perm = read
+			}
+			if !m.policy.Allows(user.Role, perm) {
+				apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
 				return
 			}
 
@@ -97,22 +141,35 @@ func (m *AuthMiddleware) RequireRole(role auth.Role) func(http.Handler) http.Han
 	}
 }
 
+// Can reports whether the authenticated user in ctx holds perm. Without a
+// policy in ctx (Authenticate did not run) only admins are allowed.
+func Can(ctx context.Context, perm auth.Permission) bool {
+	user := GetUserFromContext(ctx)
+	if user == nil {
+		return false
+	}
+	if policy, ok := ctx.Value(PolicyContextKey).(*auth.Policy); ok && policy != nil {
+		return policy.Allows(user.Role, perm)
+	}
+	return user.IsAdmin()
+}
+
 // RequireAdmin is shorthand for RequireRole(RoleAdmin)
 func (m *AuthMiddleware) RequireAdmin(next http.Handler) http.Handler {
 	return m.RequireRole(auth.RoleAdmin)(next)
 }
 
-// RequireUser ensures only regular users (not admins) can access
+// RequireUser ensures only regular users (not admins or staff) can access
 func (m *AuthMiddleware) RequireUser(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		user := GetUserFromContext(r.Context())
 		if user == nil {
-			http.Error(w, `{"error":"unauthorized"}`, http.StatusUnauthorized)
+			apierror.Respond(w, http.StatusUnauthorized, "unauthorized")
 			return
 		}
 
-		if user.Role == auth.RoleAdmin {
-			http.Error(w, `{"error":"admins cannot make payments - use a regular user account"}`, http.StatusForbidden)
+		if user.Role.IsStaff() {
+			apierror.Respond(w, http.StatusForbidden, "admin and staff accounts cannot make payments - use a regular user account")
 			return
 		}
 
@@ -147,3 +204,23 @@ func Chain(middlewares ...func(http.Handler) http.Handler) func(http.Handler) ht
 		return final
 	}
 }
+
+// UserFromClaims builds the authenticated user from verified token claims
+func UserFromClaims(claims *auth.TokenClaims) *auth.User {
+	return &auth.User{
+		ID:       claims.UserID,
+		Email:    claims.Email,
+		Username: claims.Username,
+		Role:     claims.Role,
+		IsActive: true,
+	}
+}
+
+// TokenFromRequest returns the bearer token from the Authorization header, or
+// the token query parameter since browsers cannot set headers on WebSocket upgrades
+func TokenFromRequest(r *http.Request) string {
+	if parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2); len(parts) == 2 && strings.ToLower(parts[0]) == "bearer" {
+		return parts[1]
+	}
+	return r.URL.Query().Get("token")
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+)
+
+// RejectWhileDraining refuses requests that would start new work (anything
+// but GET, HEAD and OPTIONS) with 503 once draining reports true, so
+// clients retry against another instance or after the restart.
+func RejectWhileDraining(draining func() bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if draining() {
+					w.Header().Set("Retry-After", "5")
+					apierror.Respond(w, http.StatusServiceUnavailable, "server is shutting down")
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
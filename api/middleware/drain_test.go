@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRejectWhileDrainingOnlyRefusesNewWork(t *testing.T) {
+	draining := false
+	handler := RejectWhileDraining(func() bool { return draining })(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	serve := func(method string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/api/v1/payments/create", nil))
+		return rec
+	}
+
+	if rec := serve(http.MethodPost); rec.Code != http.StatusAccepted {
+		t.Fatalf("expected POST through before draining, got %d", rec.Code)
+	}
+	draining = true
+	rec := serve(http.MethodPost)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Errorf("expected 503 with Retry-After while draining, got %d", rec.Code)
+	}
+	if rec := serve(http.MethodGet); rec.Code != http.StatusAccepted {
+		t.Errorf("expected reads served while draining, got %d", rec.Code)
+	}
+}
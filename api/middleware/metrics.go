@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+// Metrics records request latency by method, route pattern and status.
+// Wrap the ServeMux directly: the mux sets r.Pattern on the request it is given,
+// which keeps the route label bounded (IDs in paths don't create new series).
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := NewStatusRecorder(w)
+		next.ServeHTTP(rec, r)
+
+		route := r.Pattern
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequestDuration.ObserveSince(start, r.Method, route, strconv.Itoa(rec.status))
+	})
+}
+
+// StatusRecorder is a ResponseWriter that captures the response status code
+// for logging and metrics. It passes Flush, Hijack and Unwrap through.
+type StatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// NewStatusRecorder wraps w; the status is 200 until a header is written
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status returns the status code written, or 200 if none has been
+func (rec *StatusRecorder) Status() int {
+	return rec.status
+}
+
+func (rec *StatusRecorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.status = code
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+func (rec *StatusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Flush supports streaming responses
+func (rec *StatusRecorder) Flush() {
+	if f, ok := rec.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack supports WebSocket upgrades, recorded as 101 Switching Protocols
+func (rec *StatusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	rec.status = http.StatusSwitchingProtocols
+	rec.wroteHeader = true
+	return h.Hijack()
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rec *StatusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,217 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+)
+
+// Limiter checks and records a request against a rate limit bucket.
+// Satisfied by *redis.RateLimiter.
+type Limiter interface {
+	Allow(ctx context.Context, cfg *redisClient.RateLimitConfig) (*redisClient.RateLimitResult, error)
+}
+
+// RateLimitRule is the limit applied to one group of endpoints
+type RateLimitRule struct {
+	Name   string // Bucket name, e.g. "login"
+	Limit  int64
+	Window time.Duration
+}
+
+// RateLimitRules holds the rules for each protected endpoint group
+type RateLimitRules struct {
+	Login    RateLimitRule
+	Payments RateLimitRule
+	Route    RateLimitRule
+
+	// TrustedProxies may set X-Forwarded-For for anonymous requests, which
+	// are otherwise keyed by the connecting address
+	TrustedProxies TrustedProxies
+}
+
+// TrustedProxies are the addresses of the reverse proxies in front of the server
+type TrustedProxies []netip.Prefix
+
+// ParseTrustedProxies parses a comma-separated list of IPs and CIDR ranges,
+// e.g. "10.0.0.0/8, 192.0.2.10"
+func ParseTrustedProxies(raw string) (TrustedProxies, error) {
+	var proxies TrustedProxies
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(field); err == nil {
+			proxies = append(proxies, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(field)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", field)
+		}
+		proxies = append(proxies, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return proxies, nil
+}
+
+// trusts reports whether addr is one of the proxies
+func (p TrustedProxies) trusts(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, prefix := range p {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultRateLimitRules returns the default per-user limits
+func DefaultRateLimitRules() *RateLimitRules {
+	return &RateLimitRules{
+		Login:    RateLimitRule{Name: "login", Limit: 10, Window: time.Minute},
+		Payments: RateLimitRule{Name: "payments", Limit: 30, Window: time.Minute},
+		Route:    RateLimitRule{Name: "route", Limit: 60, Window: time.Minute},
+	}
+}
+
+// RateLimitRulesFromEnv returns the default rules overridden by
+// RATE_LIMIT_LOGIN, RATE_LIMIT_PAYMENTS and RATE_LIMIT_ROUTE ("<limit>/<window>", e.g. "10/1m"),
+// trusting X-Forwarded-For from TRUSTED_PROXIES
+func RateLimitRulesFromEnv() (*RateLimitRules, error) {
+	rules := DefaultRateLimitRules()
+
+	proxies, err := ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+	rules.TrustedProxies = proxies
+
+	for envVar, rule := range map[string]*RateLimitRule{
+		"RATE_LIMIT_LOGIN":    &rules.Login,
+		"RATE_LIMIT_PAYMENTS": &rules.Payments,
+		"RATE_LIMIT_ROUTE":    &rules.Route,
+	} {
+		raw := os.Getenv(envVar)
+		if raw == "" {
+			continue
+		}
+		limit, window, err := parseRateLimit(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", envVar, err)
+		}
+		rule.Limit = limit
+		rule.Window = window
+	}
+
+	return rules, nil
+}
+
+// parseRateLimit parses "<limit>/<window>"
+func parseRateLimit(raw string) (int64, time.Duration, error) {
+	parts := strings.SplitN(raw, "/", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected <limit>/<window>, got %q", raw)
+	}
+	limit, err := strconv.ParseInt(strings.TrimSpace(parts[0]), 10, 64)
+	if err != nil || limit <= 0 {
+		return 0, 0, fmt.Errorf("limit must be a positive integer")
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+	if err != nil || window <= 0 {
+		return 0, 0, fmt.Errorf("window must be a positive duration")
+	}
+	return limit, window, nil
+}
+
+// RateLimit limits requests per authenticated user, or per client IP for
+// anonymous requests. Place it after Authenticate so the user is known.
+// A nil limiter disables rate limiting (e.g. when Redis is unavailable).
+// The client IP comes from X-Forwarded-For only when proxies sent the request.
+func RateLimit(limiter Limiter, rule RateLimitRule, proxies TrustedProxies) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cfg := &redisClient.RateLimitConfig{
+				Key:    rateLimitKey(r, rule.Name, proxies),
+				Limit:  rule.Limit,
+				Window: rule.Window,
+			}
+
+			result, err := limiter.Allow(r.Context(), cfg)
+			if err != nil {
+				// Fail open: a Redis outage should not take the API down
+				slog.WarnContext(r.Context(), "rate limit check failed", "bucket", rule.Name, "error", err)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("X-RateLimit-Limit", strconv.FormatInt(rule.Limit, 10))
+			w.Header().Set("X-RateLimit-Remaining", strconv.FormatInt(max(result.Remaining, 0), 10))
+
+			if !result.Allowed {
+				retryAfter := int64(math.Ceil(result.RetryAfter.Seconds()))
+				if retryAfter < 1 {
+					retryAfter = 1
+				}
+				w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+				apierror.Respond(w, http.StatusTooManyRequests, "rate limit exceeded")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// rateLimitKey builds the bucket key for a request
+func rateLimitKey(r *http.Request, name string, proxies TrustedProxies) string {
+	if user := GetUserFromContext(r.Context()); user != nil {
+		return "ratelimit:" + name + ":user:" + user.ID
+	}
+	return "ratelimit:" + name + ":ip:" + clientIP(r, proxies)
+}
+
+// clientIP returns the originating client IP. X-Forwarded-For is honoured
+// only when the connecting address is a trusted proxy, and is read from the
+// right: the client is the last address not added by one of the proxies, so
+// entries a client prepends itself cannot pick its bucket.
+func clientIP(r *http.Request, proxies TrustedProxies) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil || !proxies.trusts(remote) {
+		return host
+	}
+
+	client := host
+	hops := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = addr.Unmap().String()
+		if !proxies.trusts(addr) {
+			break
+		}
+	}
+	return client
+}
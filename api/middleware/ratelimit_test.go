@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+)
+
+// fakeLimiter is an in-memory fixed bucket limiter keyed like the Redis one
+type fakeLimiter struct {
+	counts map[string]int64
+}
+
+func (f *fakeLimiter) Allow(ctx context.Context, cfg *redisClient.RateLimitConfig) (*redisClient.RateLimitResult, error) {
+	if f.counts[cfg.Key] >= cfg.Limit {
+		return &redisClient.RateLimitResult{Allowed: false, RetryAfter: 1500 * time.Millisecond}, nil
+	}
+	f.counts[cfg.Key]++
+	return &redisClient.RateLimitResult{Allowed: true, Remaining: cfg.Limit - f.counts[cfg.Key]}, nil
+}
+
+func TestRateLimitPerUserReturns429WithRetryAfter(t *testing.T) {
+	limiter := &fakeLimiter{counts: make(map[string]int64)}
+	handler := RateLimit(limiter, RateLimitRule{Name: "payments", Limit: 2, Window: time.Minute}, nil)(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }),
+	)
+
+	request := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/history", nil)
+		req = req.WithContext(context.WithValue(req.Context(), UserContextKey, &auth.User{ID: userID}))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := request("alice"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status %d, want 200", i+1, rec.Code)
+		}
+	}
+
+	rec := request("alice")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Fatalf("Retry-After = %q, want 2", got)
+	}
+
+	// Other users have their own bucket
+	if rec := request("bob"); rec.Code != http.StatusOK {
+		t.Fatalf("other user: status %d, want 200", rec.Code)
+	}
+}
+
+func TestRateLimitAnonymousKeyedByIP(t *testing.T) {
+	proxies, err := ParseTrustedProxies("10.0.0.0/8, 192.0.2.10")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", nil)
+	req.RemoteAddr = "203.0.113.7:51234"
+	if got := rateLimitKey(req, "login", proxies); got != "ratelimit:login:ip:203.0.113.7" {
+		t.Fatalf("key = %q", got)
+	}
+
+	// Clients connecting directly cannot choose their bucket
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	if got := rateLimitKey(req, "login", proxies); got != "ratelimit:login:ip:203.0.113.7" {
+		t.Fatalf("untrusted X-Forwarded-For: key = %q", got)
+	}
+
+	// Behind the proxies the client is the last address they did not add
+	req.RemoteAddr = "10.0.0.2:443"
+	req.Header.Set("X-Forwarded-For", "198.51.100.99, 198.51.100.1, 192.0.2.10")
+	if got := rateLimitKey(req, "login", proxies); got != "ratelimit:login:ip:198.51.100.1" {
+		t.Fatalf("trusted X-Forwarded-For: key = %q", got)
+	}
+	req.Header.Del("X-Forwarded-For")
+	if got := rateLimitKey(req, "login", proxies); got != "ratelimit:login:ip:10.0.0.2" {
+		t.Fatalf("proxy without X-Forwarded-For: key = %q", got)
+	}
+
+	if _, err := ParseTrustedProxies("10.0.0.0/8, proxy.internal"); err == nil {
+		t.Error("expected an error for a hostname")
+	}
+}
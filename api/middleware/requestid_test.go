@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+func TestRequestIDPropagatesToLogsAndResponse(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "json", "info")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prev := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestID(r.Context())
+		slog.InfoContext(r.Context(), "handling")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	// Client-supplied ID is kept
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/history", nil)
+	req.Header.Set(logging.RequestIDHeader, "trace-123")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if seen != "trace-123" || rec.Header().Get(logging.RequestIDHeader) != "trace-123" {
+		t.Fatalf("expected trace-123 in context and header, got %q / %q", seen, rec.Header().Get(logging.RequestIDHeader))
+	}
+	logs := buf.String()
+	if strings.Count(logs, `"request_id":"trace-123"`) != 2 || !strings.Contains(logs, `"status":202`) {
+		t.Fatalf("expected handler and access logs tagged with the request ID:\n%s", logs)
+	}
+
+	// Invalid IDs are replaced
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(logging.RequestIDHeader, "bad id\nwith newline")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get(logging.RequestIDHeader); got == "" || strings.ContainsAny(got, " \n") || got != seen {
+		t.Fatalf("expected a generated request ID, got %q (context %q)", got, seen)
+	}
+}
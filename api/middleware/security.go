@@ -9,6 +9,8 @@ import (
 	"net/url"
 	"strings"
 	"unicode"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 )
 
 // AllowedOrigins defines the list of allowed origins for CSRF protection
@@ -51,7 +53,7 @@ func CSRFMiddleware(next http.Handler) http.Handler {
 		// Check Origin header
 		origin := r.Header.Get("Origin")
 		if !IsOriginAllowed(origin, r.Host) {
-			http.Error(w, `{"error":"CSRF validation failed: invalid origin"}`, http.StatusForbidden)
+			apierror.Respond(w, http.StatusForbidden, "CSRF validation failed: invalid origin")
 			return
 		}
 
@@ -69,7 +71,7 @@ func CSRFMiddleware(next http.Handler) http.Handler {
 					}
 				}
 				if !allowed && !strings.Contains(refURL.Host, r.Host) {
-					http.Error(w, `{"error":"CSRF validation failed: invalid referer"}`, http.StatusForbidden)
+					apierror.Respond(w, http.StatusForbidden, "CSRF validation failed: invalid referer")
 					return
 				}
 			}
@@ -0,0 +1,190 @@
+// Package validate checks decoded API requests and reports every invalid
+// field at once, as a validation_failed error whose details list the fields:
+//
+//	{"code":"validation_failed","message":"amount: must be positive","details":[{"field":"amount","message":"must be positive"}],...}
+//
+// Handlers decode with Decode, then collect checks on a Validator:
+//
+//	var v validate.Validator
+//	v.Amount("amount", req.Amount, req.Currency)
+//	v.Currency("currency", req.Currency)
+//	v.Route("route", req.Route, graph.HasNode)
+//	if err := v.Err(); err != nil {
+//		apierror.Write(w, r, err)
+//		return
+//	}
+package validate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+)
+
+// defaultMinorUnits bounds the precision of amounts without a known currency
+const defaultMinorUnits = 2
+
+// FieldError is an invalid field
+type FieldError struct {
+	Field   string `json:"field"` // JSON path, e.g. payments[1].route[0]
+	Message string `json:"message"`
+}
+
+// Validator collects field errors. The zero value is ready to use.
+type Validator struct {
+	errs []FieldError
+}
+
+// Add records an invalid field
+func (v *Validator) Add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Check records message for field unless ok
+func (v *Validator) Check(ok bool, field, message string) {
+	if !ok {
+		v.Add(field, "%s", message)
+	}
+}
+
+// Required checks that a string field is set
+func (v *Validator) Required(field, value string) {
+	v.Check(strings.TrimSpace(value) != "", field, "is required")
+}
+
+// Amount checks that an amount is positive and has no more decimal places
+// than its currency allows (two when the currency is empty or unknown)
+func (v *Validator) Amount(field string, amount float64, currency string) {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0 {
+		v.Add(field, "must be positive")
+		return
+	}
+	units, ok := refdata.MinorUnits(currency)
+	if !ok {
+		units = defaultMinorUnits
+	}
+	scaled := amount * math.Pow10(units)
+	if math.Abs(scaled-math.Round(scaled)) > 1e-9*math.Max(1, scaled) {
+		if units == 0 {
+			v.Add(field, "must be a whole number of %s", currency)
+		} else {
+			v.Add(field, "must have at most %d decimal places", units)
+		}
+	}
+}
+
+// Currency checks that a currency, if set, is an ISO 4217 code
+func (v *Validator) Currency(field, code string) {
+	if code == "" {
+		return
+	}
+	if !refdata.IsCurrency(code) {
+		v.Add(field, "must be an ISO 4217 currency code, e.g. USD")
+	}
+}
+
+// Country checks that code is an ISO 3166-1 alpha-3 code and, when known is
+// not nil, a country of the mesh
+func (v *Validator) Country(field, code string, known func(string) bool) {
+	switch {
+	case code == "":
+		v.Add(field, "is required")
+	case !refdata.IsCountry(code):
+		v.Add(field, "must be an ISO 3166-1 alpha-3 country code, e.g. USA")
+	case known != nil && !known(code):
+		v.Add(field, "unknown country %s", code)
+	}
+}
+
+// Countries checks each code of a list, as Country does
+func (v *Validator) Countries(field string, codes []string, known func(string) bool) {
+	for i, code := range codes {
+		v.Country(fmt.Sprintf("%s[%d]", field, i), code, known)
+	}
+}
+
+// Route checks that a route has at least two countries, each valid
+func (v *Validator) Route(field string, route []string, known func(string) bool) {
+	if len(route) < 2 {
+		v.Add(field, "must have at least 2 countries")
+		return
+	}
+	v.Countries(field, route, known)
+}
+
+// Valid reports whether no field errors were recorded
+func (v *Validator) Valid() bool {
+	return len(v.errs) == 0
+}
+
+// Errors returns the recorded field errors
+func (v *Validator) Errors() []FieldError {
+	return v.errs
+}
+
+// Err returns the recorded field errors as a validation_failed error, or nil
+func (v *Validator) Err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return fieldsError(v.errs)
+}
+
+func fieldsError(errs []FieldError) *apierror.Error {
+	// The message names the first field for clients that only show messages
+	message := errs[0].Field + ": " + errs[0].Message
+	if len(errs) > 1 {
+		message += fmt.Sprintf(" (and %d more)", len(errs)-1)
+	}
+	return apierror.New(apierror.CodeValidationFailed, message).WithDetails(errs)
+}
+
+// Decode decodes a JSON request body into v. Malformed bodies are reported
+// as an *apierror.Error naming the offending field where there is one.
+func Decode(r *http.Request, v interface{}) error {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == nil {
+		return nil
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var maxBytesErr *http.MaxBytesError
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fieldsError([]FieldError{{Field: typeErr.Field, Message: "must be " + describe(typeErr.Type)}})
+	case errors.As(err, &syntaxErr):
+		return apierror.Newf(apierror.CodeInvalidRequest, "invalid JSON at offset %d", syntaxErr.Offset)
+	case errors.As(err, &maxBytesErr):
+		return apierror.Newf(apierror.CodeTooLarge, "request body exceeds %d bytes", maxBytesErr.Limit)
+	case errors.Is(err, io.EOF):
+		return apierror.New(apierror.CodeInvalidRequest, "request body is empty")
+	}
+	return apierror.New(apierror.CodeInvalidRequest, "invalid request body")
+}
+
+// describe names the JSON type expected for a Go type
+func describe(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	}
+	return "an object"
+}
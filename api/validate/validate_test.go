@@ -0,0 +1,117 @@
+package validate
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+)
+
+func known(code string) bool {
+	return code == "USA" || code == "DEU" || code == "JPN"
+}
+
+func TestAmountPrecision(t *testing.T) {
+	tests := []struct {
+		amount   float64
+		currency string
+		want     string // Expected message, "" = valid
+	}{
+		{100, "USD", ""},
+		{19.99, "USD", ""},
+		{0.1 + 0.2, "USD", ""}, // Binary rounding noise is not extra precision
+		{0.001, "", "must have at most 2 decimal places"},
+		{10.005, "USD", "must have at most 2 decimal places"},
+		{1500, "JPY", ""},
+		{1500.5, "JPY", "must be a whole number of JPY"},
+		{1.125, "KWD", ""},
+		{0, "USD", "must be positive"},
+		{-5, "EUR", "must be positive"},
+	}
+	for _, tt := range tests {
+		var v Validator
+		v.Amount("amount", tt.amount, tt.currency)
+		got := ""
+		if errs := v.Errors(); len(errs) > 0 {
+			got = errs[0].Message
+		}
+		if got != tt.want {
+			t.Errorf("Amount(%v %s) = %q, want %q", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestCollectsEveryFieldError(t *testing.T) {
+	var v Validator
+	v.Amount("amount", 10, "USD")
+	v.Currency("currency", "usd")
+	v.Currency("target_currency", "")
+	v.Route("route", []string{"USA", "DE", "FRA"}, known)
+	v.Route("fallback", []string{"USA"}, known)
+
+	want := []FieldError{
+		{Field: "currency", Message: "must be an ISO 4217 currency code, e.g. USD"},
+		{Field: "route[1]", Message: "must be an ISO 3166-1 alpha-3 country code, e.g. USA"},
+		{Field: "route[2]", Message: "unknown country FRA"},
+		{Field: "fallback", Message: "must have at least 2 countries"},
+	}
+	if !reflect.DeepEqual(v.Errors(), want) {
+		t.Fatalf("errors = %+v, want %+v", v.Errors(), want)
+	}
+
+	var apiErr *apierror.Error
+	if !errors.As(v.Err(), &apiErr) {
+		t.Fatalf("Err() = %v, want an *apierror.Error", v.Err())
+	}
+	if apiErr.Code != apierror.CodeValidationFailed || apiErr.Status() != http.StatusBadRequest {
+		t.Errorf("got %s %d", apiErr.Code, apiErr.Status())
+	}
+	if apiErr.Message != "currency: must be an ISO 4217 currency code, e.g. USD (and 3 more)" {
+		t.Errorf("message = %q", apiErr.Message)
+	}
+}
+
+func TestValidRequestHasNoError(t *testing.T) {
+	var v Validator
+	v.Amount("amount", 250.5, "EUR")
+	v.Currency("currency", "EUR")
+	v.Route("route", []string{"DEU", "USA"}, known)
+	v.Country("source", "JPN", nil)
+	if err := v.Err(); err != nil || !v.Valid() {
+		t.Errorf("Err() = %v", err)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	type request struct {
+		Amount float64  `json:"amount"`
+		Route  []string `json:"route"`
+	}
+	tests := []struct {
+		body    string
+		code    apierror.Code
+		message string
+	}{
+		{`{"amount":"100"}`, apierror.CodeValidationFailed, "amount: must be a number"},
+		{`{"route":"USA"}`, apierror.CodeValidationFailed, "route: must be an array"},
+		{`{"amount":100,}`, apierror.CodeInvalidRequest, "invalid JSON at offset 15"},
+		{``, apierror.CodeInvalidRequest, "request body is empty"},
+	}
+	for _, tt := range tests {
+		var req request
+		err := Decode(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body)), &req)
+		var apiErr *apierror.Error
+		if !errors.As(err, &apiErr) || apiErr.Code != tt.code || apiErr.Message != tt.message {
+			t.Errorf("Decode(%q) = %v, want %s %q", tt.body, err, tt.code, tt.message)
+		}
+	}
+
+	var req request
+	if err := Decode(httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"amount":5,"route":["USA","DEU"]}`)), &req); err != nil || req.Amount != 5 {
+		t.Errorf("Decode = %+v, %v", req, err)
+	}
+}
@@ -0,0 +1,210 @@
+// Package versioning mounts API handlers under /api/{version} so versions
+// with breaking changes can be served side by side. Deprecated versions and
+// endpoints announce it with the Deprecation (RFC 9745), Sunset (RFC 8594)
+// and Link headers, and requests are counted per version.
+package versioning
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+// Deprecation announces that a version or endpoint is going away
+type Deprecation struct {
+	Since     time.Time // When it was deprecated (zero = not deprecated)
+	Sunset    time.Time // When it stops being served, answering 410 Gone (zero = not yet scheduled)
+	Successor string    // Path of the replacement, if any
+	Docs      string    // URL of migration notes, if any
+}
+
+// Active reports whether the deprecation applies at t
+func (d Deprecation) Active(t time.Time) bool {
+	return !d.Since.IsZero() && !t.Before(d.Since)
+}
+
+// setHeaders adds the deprecation headers to h
+func (d Deprecation) setHeaders(h http.Header) {
+	h.Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+	if !d.Sunset.IsZero() {
+		h.Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if d.Successor != "" {
+		h.Add("Link", "<"+d.Successor+`>; rel="successor-version"`)
+	}
+	if d.Docs != "" {
+		h.Add("Link", "<"+d.Docs+`>; rel="deprecation"`)
+	}
+}
+
+// Router registers versioned API handlers on a ServeMux
+type Router struct {
+	mux *http.ServeMux
+	now func() time.Time
+
+	mu       sync.RWMutex
+	versions map[string]*Version
+}
+
+// NewRouter creates a router that registers on mux
+func NewRouter(mux *http.ServeMux) *Router {
+	return &Router{mux: mux, now: time.Now, versions: make(map[string]*Version)}
+}
+
+// Version returns the version name (e.g. "v2") mounted at /api/{name},
+// creating it on first use
+func (r *Router) Version(name string) *Version {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if v, ok := r.versions[name]; ok {
+		return v
+	}
+	v := &Version{name: name, prefix: "/api/" + name, router: r, endpoints: make(map[string]*Deprecation)}
+	r.versions[name] = v
+	return v
+}
+
+// Version is one API version. Handlers are registered with paths relative
+// to its prefix.
+type Version struct {
+	name   string
+	prefix string
+	router *Router
+
+	deprecation Deprecation
+	endpoints   map[string]*Deprecation // Route pattern -> endpoint deprecation
+}
+
+// Name returns the version name
+func (v *Version) Name() string {
+	return v.name
+}
+
+// Prefix returns the path prefix, e.g. /api/v1
+func (v *Version) Prefix() string {
+	return v.prefix
+}
+
+// Deprecate deprecates the whole version. Register handlers after calling it
+// or before; it applies to all of them.
+func (v *Version) Deprecate(d Deprecation) *Version {
+	v.router.mu.Lock()
+	defer v.router.mu.Unlock()
+	v.deprecation = d
+	return v
+}
+
+// DeprecateEndpoint deprecates a single endpoint of the version, e.g. one
+// replaced by a differently shaped endpoint in the next version
+func (v *Version) DeprecateEndpoint(pattern string, d Deprecation) {
+	v.router.mu.Lock()
+	defer v.router.mu.Unlock()
+	v.endpoints[v.route(pattern)] = &d
+}
+
+// Handle registers handler for pattern relative to the version prefix, e.g.
+// "/route" or "GET /payments/history". The mux sees the full pattern.
+func (v *Version) Handle(pattern string, handler http.Handler) {
+	route := v.route(pattern)
+	v.router.mux.Handle(route, v.wrap(route, handler))
+}
+
+// HandleFunc registers a handler function, as Handle does
+func (v *Version) HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	v.Handle(pattern, http.HandlerFunc(handler))
+}
+
+// route returns the mux pattern of a relative pattern
+func (v *Version) route(pattern string) string {
+	if method, path, ok := strings.Cut(pattern, " "); ok {
+		return method + " " + v.prefix + path
+	}
+	return v.prefix + pattern
+}
+
+// deprecationFor returns the deprecation in force for a route, endpoint first
+func (v *Version) deprecationFor(route string, now time.Time) (Deprecation, bool) {
+	v.router.mu.RLock()
+	defer v.router.mu.RUnlock()
+	if d, ok := v.endpoints[route]; ok && d.Active(now) {
+		return *d, true
+	}
+	if v.deprecation.Active(now) {
+		return v.deprecation, true
+	}
+	return Deprecation{}, false
+}
+
+// wrap adds the version headers and metrics to a handler
+func (v *Version) wrap(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", v.name)
+		now := v.router.now()
+		if d, ok := v.deprecationFor(route, now); ok {
+			d.setHeaders(w.Header())
+			metrics.APIDeprecatedRequests.Inc(v.name, route)
+			if !d.Sunset.IsZero() && !now.Before(d.Sunset) {
+				metrics.APIRequests.Inc(v.name, "4xx")
+				apierror.Respond(w, http.StatusGone, "this endpoint has been retired")
+				return
+			}
+		}
+
+		rec := middleware.NewStatusRecorder(w)
+		next.ServeHTTP(rec, r)
+		metrics.APIRequests.Inc(v.name, strconv.Itoa(rec.Status()/100)+"xx")
+	})
+}
+
+// VersionInfo describes a version in the discovery document
+type VersionInfo struct {
+	Version    string     `json:"version"`
+	Prefix     string     `json:"prefix"`
+	Status     string     `json:"status"` // current or deprecated
+	Deprecated *time.Time `json:"deprecated,omitempty"`
+	Sunset     *time.Time `json:"sunset,omitempty"`
+	Successor  string     `json:"successor,omitempty"`
+}
+
+// Versions lists the versions, sorted by name
+func (r *Router) Versions() []VersionInfo {
+	now := r.now()
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]VersionInfo, 0, len(r.versions))
+	for _, v := range r.versions {
+		info := VersionInfo{Version: v.name, Prefix: v.prefix, Status: "current"}
+		if d := v.deprecation; d.Active(now) {
+			since := d.Since
+			info.Status = "deprecated"
+			info.Deprecated = &since
+			info.Successor = d.Successor
+		}
+		if d := v.deprecation; !d.Sunset.IsZero() {
+			sunset := d.Sunset
+			info.Sunset = &sunset
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Version < infos[j].Version })
+	return infos
+}
+
+// HandleVersions handles GET /api/versions
+func (r *Router) HandleVersions(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"versions": r.Versions()})
+}
@@ -0,0 +1,101 @@
+package versioning
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+func TestVersionsServeSideBySide(t *testing.T) {
+	mux := http.NewServeMux()
+	router := NewRouter(mux)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+
+	v1 := router.Version("v1")
+	v2 := router.Version("v2")
+	ok := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(body)) }
+	}
+	v1.HandleFunc("/route", ok("v1 route"))
+	v1.HandleFunc("GET /history", ok("v1 history"))
+	v2.HandleFunc("/route", ok("v2 route"))
+	v1.DeprecateEndpoint("/route", Deprecation{
+		Since:     now.Add(-time.Hour),
+		Sunset:    now.Add(30 * 24 * time.Hour),
+		Successor: "/api/v2/route",
+	})
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+		return rec
+	}
+
+	before := metrics.APIDeprecatedRequests.Value("v1", "/api/v1/route")
+	rec := serve(http.MethodPost, "/api/v1/route")
+	if rec.Body.String() != "v1 route" || rec.Header().Get("API-Version") != "v1" {
+		t.Fatalf("unexpected v1 response %q %v", rec.Body, rec.Header())
+	}
+	if got := rec.Header().Get("Deprecation"); got != "@1767222000" {
+		t.Errorf("Deprecation = %q", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != "Sat, 31 Jan 2026 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if got := rec.Header().Get("Link"); got != `</api/v2/route>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+	if metrics.APIDeprecatedRequests.Value("v1", "/api/v1/route") != before+1 {
+		t.Error("deprecated request not counted")
+	}
+
+	// Other endpoints and versions are unaffected
+	for _, path := range []string{"/api/v1/history", "/api/v2/route"} {
+		rec := serve(http.MethodGet, path)
+		if rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "" {
+			t.Errorf("%s: expected 200 without deprecation, got %d %v", path, rec.Code, rec.Header())
+		}
+	}
+	if rec := serve(http.MethodPost, "/api/v1/history"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected the method pattern to be kept, got %d", rec.Code)
+	}
+
+	// After the sunset the endpoint is gone
+	now = now.Add(31 * 24 * time.Hour)
+	if rec := serve(http.MethodPost, "/api/v1/route"); rec.Code != http.StatusGone {
+		t.Errorf("expected 410 after the sunset, got %d", rec.Code)
+	}
+}
+
+func TestVersionsDiscovery(t *testing.T) {
+	router := NewRouter(http.NewServeMux())
+	now := time.Now()
+	router.Version("v2")
+	router.Version("v1").Deprecate(Deprecation{Since: now.Add(-time.Minute), Successor: "/api/v2"})
+	router.Version("v3").Deprecate(Deprecation{Since: now.Add(time.Hour)}) // Not yet in force
+
+	rec := httptest.NewRecorder()
+	router.HandleVersions(rec, httptest.NewRequest(http.MethodGet, "/api/versions", nil))
+	var resp struct {
+		Versions []VersionInfo `json:"versions"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Versions) != 3 {
+		t.Fatalf("expected 3 versions, got %+v", resp.Versions)
+	}
+	for i, want := range []string{"deprecated", "current", "current"} {
+		if resp.Versions[i].Status != want {
+			t.Errorf("%s: status %s, want %s", resp.Versions[i].Version, resp.Versions[i].Status, want)
+		}
+	}
+	if resp.Versions[0].Successor != "/api/v2" {
+		t.Errorf("successor = %q", resp.Versions[0].Successor)
+	}
+}
@@ -0,0 +1,161 @@
+// Package audit records admin mutations (topology, user and chaos changes)
+// with the acting admin and the state of the resource before and after.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultCapacity is the number of entries kept by a MemoryStore
+const DefaultCapacity = 1000
+
+// Entry is one recorded admin action
+type Entry struct {
+	ID           string          `json:"id"`
+	Timestamp    time.Time       `json:"timestamp"`
+	ActorID      string          `json:"actor_id"`
+	ActorEmail   string          `json:"actor_email"`
+	Action       string          `json:"action"`                  // e.g. node.create, user.update, chaos.kill_node
+	ResourceType string          `json:"resource_type,omitempty"` // node, edge, country, country_edge, user, graph
+	ResourceID   string          `json:"resource_id,omitempty"`
+	Before       json.RawMessage `json:"before,omitempty"` // Resource state before the change (absent on create)
+	After        json.RawMessage `json:"after,omitempty"`  // Resource state after the change (absent on delete)
+	Target       string          `json:"target"`           // Request path of the action
+	Status       int             `json:"status"`           // HTTP status the action returned
+	RequestID    string          `json:"request_id,omitempty"`
+	RemoteAddr   string          `json:"remote_addr,omitempty"`
+	UserAgent    string          `json:"user_agent,omitempty"`
+}
+
+// State encodes a resource for Entry.Before or Entry.After; nil (including a
+// nil pointer or map) stays absent
+func State(v interface{}) json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	switch rv := reflect.ValueOf(v); rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		if rv.IsNil() {
+			return nil
+		}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// Filter selects audit entries. Zero fields match everything.
+type Filter struct {
+	Actor        string // Actor ID or email
+	Action       string // Exact action, or a prefix ending in "." (e.g. "chaos.")
+	ResourceType string
+	ResourceID   string
+	Since        time.Time // Inclusive
+	Until        time.Time // Exclusive
+	Limit        int       // Maximum entries returned (0 = DefaultCapacity)
+}
+
+// Matches reports whether e satisfies the filter (ignoring Limit)
+func (f *Filter) Matches(e *Entry) bool {
+	switch {
+	case f.Actor != "" && e.ActorID != f.Actor && !strings.EqualFold(e.ActorEmail, f.Actor):
+		return false
+	case f.Action != "" && !f.matchesAction(e.Action):
+		return false
+	case f.ResourceType != "" && e.ResourceType != f.ResourceType:
+		return false
+	case f.ResourceID != "" && e.ResourceID != f.ResourceID:
+		return false
+	case !f.Since.IsZero() && e.Timestamp.Before(f.Since):
+		return false
+	case !f.Until.IsZero() && !e.Timestamp.Before(f.Until):
+		return false
+	}
+	return true
+}
+
+func (f *Filter) matchesAction(action string) bool {
+	if strings.HasSuffix(f.Action, ".") {
+		return strings.HasPrefix(action, f.Action)
+	}
+	return action == f.Action
+}
+
+// Store persists audit entries. Implemented by MemoryStore and the
+// Postgres-backed store in storage/postgres.
+type Store interface {
+	// Record saves e, assigning its ID
+	Record(ctx context.Context, e *Entry) error
+	// List returns entries matching f, newest first
+	List(ctx context.Context, f Filter) ([]*Entry, error)
+}
+
+// Log records e in store, logging rather than returning failures so an audit
+// outage never fails the admin action itself. A nil store records nothing.
+func Log(ctx context.Context, store Store, e *Entry) {
+	if store == nil {
+		return
+	}
+	if err := store.Record(ctx, e); err != nil {
+		slog.ErrorContext(ctx, "failed to record audit entry", "action", e.Action, "error", err)
+	}
+}
+
+// MemoryStore is an in-memory Store that keeps the most recent entries
+type MemoryStore struct {
+	mu       sync.RWMutex
+	entries  []*Entry
+	capacity int
+	nextID   int64
+}
+
+// NewMemoryStore creates a store holding at most capacity entries
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &MemoryStore{capacity: capacity}
+}
+
+// Record appends an entry, discarding the oldest once the store is full
+func (m *MemoryStore) Record(ctx context.Context, e *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	e.ID = strconv.FormatInt(m.nextID, 10)
+	cp := *e
+	m.entries = append(m.entries, &cp)
+	if len(m.entries) > m.capacity {
+		m.entries = m.entries[len(m.entries)-m.capacity:]
+	}
+	return nil
+}
+
+// List returns entries matching f, newest first
+func (m *MemoryStore) List(ctx context.Context, f Filter) ([]*Entry, error) {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultCapacity
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*Entry, 0)
+	for i := len(m.entries) - 1; i >= 0 && len(result) < limit; i-- {
+		if f.Matches(m.entries[i]) {
+			cp := *m.entries[i]
+			result = append(result, &cp)
+		}
+	}
+	return result, nil
+}
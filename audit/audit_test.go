@@ -0,0 +1,64 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreFilters(t *testing.T) {
+	store := NewMemoryStore(0)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, e := range []*Entry{
+		{ActorID: "a1", ActorEmail: "ops@example.com", Action: "chaos.kill_node"},
+		{ActorID: "a1", ActorEmail: "ops@example.com", Action: "node.create", ResourceType: "node", ResourceID: "lp_alpha"},
+		{ActorID: "a2", ActorEmail: "sec@example.com", Action: "user.role_change", ResourceType: "user", ResourceID: "u1"},
+		{ActorID: "a2", ActorEmail: "sec@example.com", Action: "chaos.revive_node"},
+	} {
+		e.Timestamp = start.Add(time.Duration(i) * time.Hour)
+		if err := store.Record(context.Background(), e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string // Actions, newest first
+	}{
+		{"all", Filter{}, []string{"chaos.revive_node", "user.role_change", "node.create", "chaos.kill_node"}},
+		{"action prefix", Filter{Action: "chaos."}, []string{"chaos.revive_node", "chaos.kill_node"}},
+		{"exact action", Filter{Action: "chaos"}, nil},
+		{"actor email", Filter{Actor: "OPS@example.com"}, []string{"node.create", "chaos.kill_node"}},
+		{"resource", Filter{ResourceType: "node", ResourceID: "lp_alpha"}, []string{"node.create"}},
+		{"time window", Filter{Since: start.Add(time.Hour), Until: start.Add(3 * time.Hour)}, []string{"user.role_change", "node.create"}},
+		{"limit", Filter{Limit: 1}, []string{"chaos.revive_node"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := store.List(context.Background(), tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.want) {
+				t.Fatalf("got %d entries, want %v", len(entries), tt.want)
+			}
+			for i, e := range entries {
+				if e.Action != tt.want[i] {
+					t.Errorf("entry %d = %s, want %s", i, e.Action, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStateOmitsNil(t *testing.T) {
+	var node *struct{ ID string }
+	var props map[string]interface{}
+	if State(nil) != nil || State(node) != nil || State(props) != nil {
+		t.Error("nil state should be absent")
+	}
+	if got := string(State(map[string]string{"id": "lp_alpha"})); got != `{"id":"lp_alpha"}` {
+		t.Errorf("State = %s", got)
+	}
+}
@@ -133,6 +133,7 @@ type User struct {
 	FullName     string    `json:"full_name,omitempty"`
 	Organization string    `json:"organization,omitempty"`
 	IsActive     bool      `json:"is_active"`
+	KYCStatus    string    `json:"kyc_status,omitempty"` // unverified, pending, verified or rejected
 	CreatedAt    time.Time `json:"created_at"`
 }
 
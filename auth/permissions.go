@@ -0,0 +1,207 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Staff roles with a subset of admin access, granted through the Policy
+const (
+	RoleOperator Role = "OPERATOR" // Runs the mesh: graph, countries, chaos drills
+	RoleAuditor  Role = "AUDITOR"  // Read-only access to audit trail, ledger and payments
+	RoleTreasury Role = "TREASURY" // Manages fees and pricing
+)
+
+// Roles lists every role, in display order
+var Roles = []Role{RoleAdmin, RoleOperator, RoleAuditor, RoleTreasury, RoleUser, RoleService}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	for _, role := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStaff reports whether r is an admin or one of the staff roles.
+// Staff accounts operate the platform and cannot make payments.
+func (r Role) IsStaff() bool {
+	switch r {
+	case RoleAdmin, RoleOperator, RoleAuditor, RoleTreasury:
+		return true
+	}
+	return false
+}
+
+// Permission is an action on an admin resource, written "resource:action"
+type Permission string
+
+const (
+	PermGraphRead       Permission = "graph:read"
+	PermGraphWrite      Permission = "graph:write"
+	PermCountriesWrite  Permission = "countries:write"
+	PermUsersRead       Permission = "users:read"
+	PermUsersWrite      Permission = "users:write"
+	PermPaymentsRead    Permission = "payments:read"
+	PermOrgsRead        Permission = "orgs:read"
+	PermOrgsWrite       Permission = "orgs:write"
+	PermLimitsRead      Permission = "limits:read"
+	PermLimitsWrite     Permission = "limits:write"
+	PermComplianceRead  Permission = "compliance:read"
+	PermComplianceWrite Permission = "compliance:write"
+	PermDisputesRead    Permission = "disputes:read"
+	PermDisputesWrite   Permission = "disputes:write"
+	PermFeesRead        Permission = "fees:read"
+	PermFeesWrite       Permission = "fees:write"
+	PermPricingRead     Permission = "pricing:read"
+	PermPricingWrite    Permission = "pricing:write"
+	PermLedgerRead      Permission = "ledger:read"
+	PermChaosExecute    Permission = "chaos:execute"
+	PermAuditRead       Permission = "audit:read"
+	PermRolesRead       Permission = "roles:read"
+	PermRolesWrite      Permission = "roles:write"
+)
+
+// Permissions lists every permission, in display order
+var Permissions = []Permission{
+	PermGraphRead, PermGraphWrite, PermCountriesWrite,
+	PermUsersRead, PermUsersWrite, PermPaymentsRead, PermOrgsRead, PermOrgsWrite,
+	PermLimitsRead, PermLimitsWrite, PermComplianceRead, PermComplianceWrite,
+	PermDisputesRead, PermDisputesWrite,
+	PermFeesRead, PermFeesWrite, PermPricingRead, PermPricingWrite,
+	PermLedgerRead, PermChaosExecute, PermAuditRead,
+	PermRolesRead, PermRolesWrite,
+}
+
+// Valid reports whether p is a known permission
+func (p Permission) Valid() bool {
+	for _, perm := range Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	ErrUnknownRole       = errors.New("unknown role")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrImmutableRole     = errors.New("admin permissions cannot be changed")
+)
+
+// DefaultGrants returns the permissions each non-admin role starts with
+func DefaultGrants() map[Role][]Permission {
+	return map[Role][]Permission{
+		RoleOperator: {PermGraphRead, PermGraphWrite, PermCountriesWrite, PermPaymentsRead, PermChaosExecute},
+		RoleAuditor:  {PermGraphRead, PermUsersRead, PermPaymentsRead, PermOrgsRead, PermLimitsRead, PermComplianceRead, PermDisputesRead, PermFeesRead, PermPricingRead, PermLedgerRead, PermAuditRead, PermRolesRead},
+		RoleTreasury: {PermPaymentsRead, PermOrgsRead, PermOrgsWrite, PermLimitsRead, PermLimitsWrite, PermComplianceRead, PermComplianceWrite, PermDisputesRead, PermDisputesWrite, PermFeesRead, PermFeesWrite, PermPricingRead, PermPricingWrite, PermLedgerRead},
+		RoleUser:     {},
+		RoleService:  {},
+	}
+}
+
+// PolicyStore persists role grants changed at runtime
+type PolicyStore interface {
+	// LoadGrants returns the saved grants; roles never saved are absent
+	LoadGrants(ctx context.Context) (map[Role][]Permission, error)
+	SaveGrants(ctx context.Context, role Role, perms []Permission, updatedBy string) error
+}
+
+// Policy maps roles to permissions. Admins hold every permission; the
+// grants of all other roles can be changed while the server runs.
+type Policy struct {
+	mu     sync.RWMutex
+	grants map[Role]map[Permission]bool
+}
+
+// DefaultPolicy returns a policy with DefaultGrants
+func DefaultPolicy() *Policy {
+	p := &Policy{grants: make(map[Role]map[Permission]bool)}
+	for role, perms := range DefaultGrants() {
+		p.set(role, perms)
+	}
+	return p
+}
+
+// Allows reports whether role holds perm
+func (p *Policy) Allows(role Role, perm Permission) bool {
+	if role == RoleAdmin {
+		return true
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.grants[role][perm]
+}
+
+// Permissions returns the permissions role holds, in display order
+func (p *Policy) Permissions(role Role) []Permission {
+	perms := make([]Permission, 0)
+	for _, perm := range Permissions {
+		if p.Allows(role, perm) {
+			perms = append(perms, perm)
+		}
+	}
+	return perms
+}
+
+// Grants returns the permissions of every role
+func (p *Policy) Grants() map[Role][]Permission {
+	grants := make(map[Role][]Permission, len(Roles))
+	for _, role := range Roles {
+		grants[role] = p.Permissions(role)
+	}
+	return grants
+}
+
+// SetPermissions replaces the permissions of role
+func (p *Policy) SetPermissions(role Role, perms []Permission) error {
+	if err := ValidateGrant(role, perms); err != nil {
+		return err
+	}
+	p.set(role, perms)
+	return nil
+}
+
+// Load applies grants saved in store over the current ones, skipping
+// entries that are no longer valid
+func (p *Policy) Load(ctx context.Context, store PolicyStore) error {
+	grants, err := store.LoadGrants(ctx)
+	if err != nil {
+		return err
+	}
+	for role, perms := range grants {
+		if ValidateGrant(role, perms) == nil {
+			p.set(role, perms)
+		}
+	}
+	return nil
+}
+
+func (p *Policy) set(role Role, perms []Permission) {
+	set := make(map[Permission]bool, len(perms))
+	for _, perm := range perms {
+		set[perm] = true
+	}
+	p.mu.Lock()
+	p.grants[role] = set
+	p.mu.Unlock()
+}
+
+// ValidateGrant checks that perms may be granted to role
+func ValidateGrant(role Role, perms []Permission) error {
+	if role == RoleAdmin {
+		return ErrImmutableRole
+	}
+	if !role.Valid() {
+		return ErrUnknownRole
+	}
+	for _, perm := range perms {
+		if !perm.Valid() {
+			return ErrUnknownPermission
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultResetTokenTTL is how long a password reset token is valid
+const DefaultResetTokenTTL = 30 * time.Minute
+
+// ErrInvalidResetToken is returned for forged, expired or already used reset tokens
+var ErrInvalidResetToken = errors.New("invalid or expired reset token")
+
+// ResetTokens issues password reset tokens. A token is an HMAC over the user
+// ID, its expiry and the user's current password hash, so it needs no storage
+// and stops verifying once it expires or the password changes (single use).
+type ResetTokens struct {
+	key []byte
+	ttl time.Duration
+}
+
+// ResetTokens returns a reset token issuer keyed from the token secret, so
+// every replica sharing TOKEN_SECRET accepts the same reset tokens
+func (tm *TokenManager) ResetTokens(ttl time.Duration) *ResetTokens {
+	mac := hmac.New(sha256.New, tm.symmetricKey)
+	mac.Write([]byte("plm password reset"))
+	return NewResetTokens(mac.Sum(nil), ttl)
+}
+
+// NewResetTokens creates a reset token issuer
+func NewResetTokens(key []byte, ttl time.Duration) *ResetTokens {
+	if ttl <= 0 {
+		ttl = DefaultResetTokenTTL
+	}
+	return &ResetTokens{key: key, ttl: ttl}
+}
+
+// TTL returns how long issued tokens are valid
+func (r *ResetTokens) TTL() time.Duration {
+	return r.ttl
+}
+
+// Issue returns a reset token for userID, bound to their current password hash
+func (r *ResetTokens) Issue(userID, passwordHash string, now time.Time) (token string, expiresAt time.Time) {
+	expiresAt = now.Add(r.ttl).UTC().Truncate(time.Second)
+	encodedID := base64.RawURLEncoding.EncodeToString([]byte(userID))
+	expiry := strconv.FormatInt(expiresAt.Unix(), 10)
+	sig := r.sign(encodedID, expiry, passwordHash)
+	return encodedID + "." + expiry + "." + base64.RawURLEncoding.EncodeToString(sig), expiresAt
+}
+
+// UserID returns the user a token was issued for, without verifying it
+func (r *ResetTokens) UserID(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidResetToken
+	}
+	id, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || len(id) == 0 {
+		return "", ErrInvalidResetToken
+	}
+	return string(id), nil
+}
+
+// Verify checks a token against the user's current password hash
+func (r *ResetTokens) Verify(token, passwordHash string, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return ErrInvalidResetToken
+	}
+	expiry, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !now.Before(time.Unix(expiry, 0)) {
+		return ErrInvalidResetToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(sig, r.sign(parts[0], parts[1], passwordHash)) {
+		return ErrInvalidResetToken
+	}
+	return nil
+}
+
+func (r *ResetTokens) sign(encodedID, expiry, passwordHash string) []byte {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(encodedID + "." + expiry + "."))
+	mac.Write([]byte(passwordHash))
+	return mac.Sum(nil)
+}
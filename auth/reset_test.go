@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResetTokens(t *testing.T) {
+	rt := NewResetTokens([]byte("test-key"), time.Minute)
+	now := time.Unix(1_700_000_000, 0)
+
+	token, expiresAt := rt.Issue("user-1", "hash-1", now)
+	if !expiresAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expiresAt = %v, want %v", expiresAt, now.Add(time.Minute))
+	}
+	if id, err := rt.UserID(token); err != nil || id != "user-1" {
+		t.Fatalf("UserID = %q, %v", id, err)
+	}
+	if err := rt.Verify(token, "hash-1", now); err != nil {
+		t.Fatalf("Verify fresh token: %v", err)
+	}
+	if err := rt.Verify(token, "hash-1", expiresAt); err != ErrInvalidResetToken {
+		t.Errorf("expired token: err = %v", err)
+	}
+	if err := rt.Verify(token, "hash-2", now); err != ErrInvalidResetToken {
+		t.Errorf("token after password change: err = %v", err)
+	}
+	if err := NewResetTokens([]byte("other-key"), time.Minute).Verify(token, "hash-1", now); err != ErrInvalidResetToken {
+		t.Errorf("token from another key: err = %v", err)
+	}
+
+	forged, _ := rt.Issue("user-2", "hash-1", now)
+	if err := rt.Verify(token[:len(token)-4]+forged[len(forged)-4:], "hash-1", now); err != ErrInvalidResetToken {
+		t.Errorf("tampered token: err = %v", err)
+	}
+	if _, err := rt.UserID("not-a-token"); err != ErrInvalidResetToken {
+		t.Errorf("malformed token: err = %v", err)
+	}
+}
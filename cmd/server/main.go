@@ -4,41 +4,84 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/api/handlers"
 	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/api/versioning"
+	"github.com/plm/predictive-liquidity-mesh/audit"
 	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/compliance"
+	"github.com/plm/predictive-liquidity-mesh/config"
 	"github.com/plm/predictive-liquidity-mesh/demo"
+	"github.com/plm/predictive-liquidity-mesh/engine/analytics"
+	plmgrpc "github.com/plm/predictive-liquidity-mesh/engine/grpc"
+	"github.com/plm/predictive-liquidity-mesh/engine/liveness"
+	"github.com/plm/predictive-liquidity-mesh/engine/registry"
 	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/limits"
+	"github.com/plm/predictive-liquidity-mesh/messaging/consumers"
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+	"github.com/plm/predictive-liquidity-mesh/notifications"
+	"github.com/plm/predictive-liquidity-mesh/organizations"
 	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/netting"
+	"github.com/plm/predictive-liquidity-mesh/payments/payouts"
+	"github.com/plm/predictive-liquidity-mesh/payments/scheduler"
+	"github.com/plm/predictive-liquidity-mesh/pkg/boot"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+	"github.com/plm/predictive-liquidity-mesh/receipts"
 	neo4jstore "github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/storage/postgres"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
 	"github.com/plm/predictive-liquidity-mesh/storage/users"
+	"github.com/plm/predictive-liquidity-mesh/webhooks"
 	"github.com/plm/predictive-liquidity-mesh/websocket"
+	"github.com/plm/predictive-liquidity-mesh/workers/adminstats"
+	"github.com/plm/predictive-liquidity-mesh/workers/entropyfeed"
 	"github.com/plm/predictive-liquidity-mesh/workers/fxrates"
+	"github.com/plm/predictive-liquidity-mesh/workers/ledgeraudit"
+	"github.com/plm/predictive-liquidity-mesh/workers/recovery"
 )
 
 func main() {
 	log.Println("🚀 Starting Predictive Liquidity Mesh Server...")
 
+	// Load configuration (defaults < JSON config file < environment)
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to JSON config file")
+	flag.Parse()
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if err := logging.Setup(os.Stderr, cfg.Log.Format, cfg.Log.Level); err != nil {
+		log.Fatalf("Failed to set up logging: %v", err)
+	}
+	if *configPath != "" {
+		log.Printf("✅ Loaded config from %s", *configPath)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	// Initialize the mesh graph with sample topology
 	graph := initializeMeshGraph()
-	meshRouter := router.NewRouter(graph, 3)
-
-	// Initialize WebSocket hub
-	wsServer := websocket.NewServer(":8080")
-	wsHub := wsServer.Hub()
-
-	// Start WebSocket hub
-	go wsHub.Run(ctx)
+	graph.SetLoadTracker(router.NewLoadTracker(), cfg.Routing.LoadPenalty) // Load-avoidance per in-flight settlement
+	meshRouter := router.NewRouter(graph, cfg.Routing.K).WithBidirectional(cfg.Routing.Bidirectional)
 
 	// Initialize PASETO token manager
 	tokenConfig, err := auth.DefaultTokenConfig()
@@ -50,61 +93,234 @@ func main() {
 		log.Fatalf("Failed to create token manager: %v", err)
 	}
 
-	// Initialize user store with default admin/user accounts
-	userStore := users.NewStore()
-	log.Println("✅ User store initialized with default accounts")
+	// Initialize WebSocket hub; clients must authenticate with an access token
+	wsServer := websocket.NewServer(cfg.Server.Addr, cfg.WebSocketHubConfig())
+	wsHub := wsServer.Hub()
+	wsHub.SetTokenManager(tokenManager)
 
-	// Initialize auth middleware
-	authMiddleware := middleware.NewAuthMiddleware(tokenManager)
+	// Start WebSocket hub
+	go wsHub.Run(ctx)
+
+	// Push edge liquidity changes from settlement reservations to the dashboard
+	graph.SetLiquidityCallback(func(c router.LiquidityChange) {
+		var change float64
+		if c.Old != 0 {
+			change = float64(c.New-c.Old) / float64(c.Old) * 100
+		}
+		wsHub.BroadcastLiquidity(&websocket.LiquidityUpdate{
+			SourceID:  c.SourceID,
+			TargetID:  c.TargetID,
+			OldVolume: c.Old,
+			NewVolume: c.New,
+			Change:    change,
+		})
+	})
+
+	// Heartbeat liveness tracker (deactivates nodes that stop heartbeating),
+	// started once Neo4j and Redis are connected
+	livenessTracker := liveness.NewTracker(graph, wsHub, cfg.LivenessTrackerConfig())
+
+	// Watch edge utilization and alert dashboards when corridors need rebalancing
+	liquidityAdvisor := analytics.NewAdvisor(graph, wsHub, analytics.DefaultConfig())
+	go liquidityAdvisor.Start(ctx)
 
-	// Try to connect to Neo4j (non-blocking)
-	var neo4jClient *neo4jstore.Client
-	var neo4jDriver interface {
-		Close(context.Context) error
+	// Wait for the configured dependencies before wiring components: required
+	// ones must come up within STARTUP_TIMEOUT, optional ones (HEALTH_OPTIONAL)
+	// are skipped with the features they back disabled
+	var (
+		pgClient *postgres.Client   // Any durable store set to postgres
+		rdb      *redisClient.Client // REDIS_URL set
+		eventBus *natsClient.Client  // NATS_URL set
+	)
+	neo4jCfg := cfg.Neo4jClientConfig()
+	neo4jSupervisor := neo4jstore.NewSupervisor(neo4jCfg, nil)
+	orchestrator := boot.NewOrchestrator(cfg.BootConfig())
+	if cfg.DependencyConfigured("neo4j") {
+		orchestrator.Add(boot.Dependency{
+			Name:     "neo4j",
+			Required: cfg.DependencyRequired("neo4j"),
+			Features: []string{"Neo4j country graph (retried in background)", "country admin", "credibility tracking"},
+			Connect:  neo4jSupervisor.Connect,
+		})
+	}
+	if cfg.DependencyConfigured("postgres") {
+		orchestrator.Add(boot.Dependency{
+			Name:     "postgres",
+			Required: cfg.DependencyRequired("postgres"),
+			Features: []string{"durable stores (using in-memory)", "audit persistence"},
+			Connect: func(ctx context.Context) (err error) {
+				pgClient, err = postgres.NewClient(ctx, cfg.PostgresClientConfig())
+				return err
+			},
+		})
 	}
-	neo4jCfg := neo4jstore.DefaultConfig()
-	neo4jClient, err = neo4jstore.NewClient(ctx, neo4jCfg)
+	if redisCfg, err := cfg.RedisClientConfig(); err != nil {
+		log.Printf("⚠️  %v (continuing without Redis)", err)
+	} else if redisCfg != nil {
+		orchestrator.Add(boot.Dependency{
+			Name:     "redis",
+			Required: cfg.DependencyRequired("redis"),
+			Features: []string{"rate limiting", "shared idempotency keys", "cross-replica transaction locks", "circuit breakers"},
+			Connect: func(ctx context.Context) (err error) {
+				rdb, err = redisClient.NewClient(ctx, redisCfg)
+				return err
+			},
+		})
+	}
+	if cfg.DependencyConfigured("nats") {
+		orchestrator.Add(boot.Dependency{
+			Name:     "nats",
+			Required: cfg.DependencyRequired("nats"),
+			Features: []string{"settlement events", "graph sync", "dead letter queue"},
+			Connect: func(ctx context.Context) error {
+				nc, err := natsClient.NewClient(ctx, cfg.NATSClientConfig())
+				if err != nil {
+					return err
+				}
+				if err := nc.SetupStreams(ctx); err != nil {
+					nc.Close()
+					return err
+				}
+				eventBus = nc
+				return nil
+			},
+		})
+	}
+	startup, err := orchestrator.Wait(ctx)
 	if err != nil {
-		log.Printf("⚠️  Neo4j not available: %v (continuing without Neo4j)", err)
+		log.Fatalf("Startup failed: %v", err)
+	}
+	if disabled := startup.Disabled(); len(disabled) > 0 {
+		log.Printf("🚩 Running without: %s", strings.Join(disabled, ", "))
+	}
+	if pgClient != nil {
+		defer pgClient.Close()
+	}
+	if rdb != nil {
+		defer rdb.Close()
+	}
+	if eventBus != nil {
+		defer eventBus.Close()
+	}
+
+	// Initialize user store with default admin/user accounts (USER_STORE=postgres for durable storage)
+	var userStore users.Storer
+	if pgClient != nil && cfg.Storage.UserStore == "postgres" {
+		pgUsers, err := users.NewPostgresStore(ctx, pgClient.DB())
+		if err != nil {
+			log.Fatalf("Failed to initialize PostgreSQL user store: %v", err)
+		}
+		userStore = pgUsers
+		log.Println("✅ User store backed by PostgreSQL")
 	} else {
-		log.Println("✅ Connected to Neo4j")
-		neo4jDriver = neo4jClient
+		userStore = users.NewStore()
+		log.Println("✅ User store initialized with default accounts")
+	}
 
+	// Role permission policy: admins hold every permission, the other roles'
+	// grants are editable at runtime and persisted when PostgreSQL is available
+	rolePolicy := auth.DefaultPolicy()
+	var rolePermissions auth.PolicyStore
+	if pgClient != nil {
+		rolePermissions = postgres.NewRolePermissionStore(pgClient)
+		if err := rolePolicy.Load(ctx, rolePermissions); err != nil {
+			log.Printf("⚠️  Failed to load role permissions: %v (using defaults)", err)
+		}
+	}
+
+	// Initialize auth middleware
+	authMiddleware := middleware.NewAuthMiddleware(tokenManager)
+	authMiddleware.SetPolicy(rolePolicy)
+
+	// Per-user rate limits (RATE_LIMIT_* overrides, TRUSTED_PROXIES; disabled without Redis)
+	rateLimits, err := middleware.RateLimitRulesFromEnv()
+	if err != nil {
+		log.Fatalf("Failed to load rate limits: %v", err)
+	}
+	var limiter middleware.Limiter
+	if rdb != nil {
+		limiter = rdb.RateLimiter()
+		log.Println("✅ Rate limiting enabled")
+	}
+	loginLimit := middleware.RateLimit(limiter, rateLimits.Login, rateLimits.TrustedProxies)
+	paymentsLimit := middleware.RateLimit(limiter, rateLimits.Payments, rateLimits.TrustedProxies)
+	routeLimit := middleware.RateLimit(limiter, rateLimits.Route, rateLimits.TrustedProxies)
+
+	// Without Neo4j (optional and down at startup) the supervisor keeps
+	// retrying and upgrades the server once it comes up (see OnConnect below)
+	neo4jClient := neo4jSupervisor.Client()
+	bootstrapNeo4j := func(client *neo4jstore.Client) {
+		bootstrapCtx, bootstrapCancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer bootstrapCancel()
+		if err := neo4jstore.BootstrapCountries(bootstrapCtx, client.Driver(), neo4jCfg.Database); err != nil {
+			log.Printf("⚠️  Failed to bootstrap countries: %v", err)
+			return
+		}
+		if err := router.SeedTradeConnections(bootstrapCtx, client.Driver(), neo4jCfg.Database); err != nil {
+			log.Printf("⚠️  Failed to seed trade connections: %v", err)
+		}
+	}
+	if neo4jClient != nil {
 		// Bootstrap countries in Neo4j
-		go func() {
-			bootstrapCtx, bootstrapCancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer bootstrapCancel()
-			if err := neo4jstore.BootstrapCountries(bootstrapCtx, neo4jClient.Driver(), neo4jCfg.Database); err != nil {
-				log.Printf("⚠️  Failed to bootstrap countries: %v", err)
-			}
-		}()
+		go bootstrapNeo4j(neo4jClient)
 
-		// Start FX rate worker
-		fxConfig := fxrates.DefaultConfig()
-		fxConfig.Driver = neo4jClient.Driver()
-		fxConfig.Database = neo4jCfg.Database
-		fxConfig.Currencies = neo4jstore.GetAllCurrencies()
-		fxWorker := fxrates.NewWorker(fxConfig)
-		go fxWorker.Start(ctx)
+		// Serve the stored mesh from the first request, so nodes and edges
+		// created through the admin API survive restarts
+		loadCtx, loadCancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := loadMeshGraph(loadCtx, neo4jClient, graph); err != nil {
+			log.Printf("⚠️  Failed to load mesh from Neo4j: %v (using the default graph)", err)
+		}
+		loadCancel()
+	}
+
+	// Silent nodes are also marked inactive in Neo4j and have their circuit opened
+	if neo4jClient != nil {
+		livenessTracker.SetNodeStore(neo4jClient)
+	}
+	if rdb != nil {
+		livenessTracker.SetCircuitBreaker(rdb.CircuitBreaker())
+	}
+	go livenessTracker.Start(ctx)
+
+	// Admin audit trail (persisted when PostgreSQL is available)
+	var auditStore audit.Store = audit.NewMemoryStore(audit.DefaultCapacity)
+	if pgClient != nil {
+		auditStore = postgres.NewAuditStore(pgClient)
 	}
 
 	// Initialize handlers
-	chaosHandler := handlers.NewChaosHandler(nil, meshRouter, graph, wsHub)
+	chaosHandler := handlers.NewChaosHandler(rdb, meshRouter, graph, wsHub)
 	chaosDemo := demo.NewChaosDemo(meshRouter, graph, wsHub, func(nodeID string) error {
 		graph.SetNodeInactive(nodeID)
 		return nil
 	})
+	scenarioRunner := demo.NewScenarioRunner(graph, chaosHandler, wsHub)
 	authHandler := handlers.NewAuthHandler(tokenManager)
 	authHandler.SetUserStore(userStore)
+
+	// Email notifications (welcome, payment receipts, refunds), filtered by each user's preferences
+	var notificationPrefs notifications.PreferenceStore = notifications.NewMemoryPreferences()
+	if pgClient != nil {
+		notificationPrefs = postgres.NewNotificationPreferenceStore(pgClient)
+	}
+	notificationHandler := handlers.NewNotificationHandler(notificationPrefs)
+	var notifier *notifications.Notifier
+	if sender, err := notifications.NewSender(cfg.NotifierConfig()); err != nil {
+		log.Printf("⚠️  Email notifications disabled: %v", err)
+	} else if sender != nil {
+		notifier = notifications.NewNotifier(cfg.NotifierConfig(), sender, notificationPrefs, userStore)
+		go notifier.Start(ctx)
+		authHandler.SetNotifier(notifier)
+	}
 	adminHandler := handlers.NewAdminHandler(graph, neo4jClient, wsHub)
+	adminHandler.SetAuditStore(auditStore)
 	userHandler := handlers.NewUserHandler(meshRouter, graph)
 
-	// Initialize country handler only if Neo4j is available
-	var countryHandler *handlers.CountryHandler
+	// Build the country routing graph from Neo4j if available. The country
+	// admin handler and graph refresher are attached once the payment handler
+	// exists (see attachCountryAdmin below).
 	var countryGraph *router.CountryGraph
 	if neo4jClient != nil {
-		countryHandler = handlers.NewCountryHandler(neo4jClient.Driver(), neo4jCfg.Database)
-
 		// Build country routing graph from Neo4j
 		var err error
 		countryGraph, err = router.BuildCountryGraphFromNeo4j(ctx, neo4jClient.Driver(), neo4jCfg.Database)
@@ -123,37 +339,541 @@ func main() {
 
 	// Initialize route handler
 	routeHandler := handlers.NewRouteHandler(countryGraph)
+	adminHandler.SetCountryGraph(countryGraph)
+	routeHandler.SetCache(cfg.Routing.CacheSize, time.Duration(cfg.Routing.CacheTTL))
+	routeHandler.SetTokenManager(tokenManager)
+	hopTimes := router.NewHopTimes() // Learned from settled payments below
+	routeHandler.SetHopTimes(hopTimes)
+
+	// Initialize payment system (TRANSACTION_STORE=postgres for durable storage)
+	// Hop fees scale with each destination's credibility and success rate
+	pricer := payments.NewPricer(countryGraph, cfg.PricingCurve())
+	memTxnStore := payments.NewTransactionStore()
+	memTxnStore.SetFeeConfig(cfg.FeeConfig())
+	memTxnStore.SetPricer(pricer)
+	var txnStore payments.TransactionStorer = memTxnStore
+	if pgClient != nil && cfg.Storage.TransactionStore == "postgres" {
+		pgStore, err := postgres.NewTransactionStore(ctx, pgClient)
+		if err != nil {
+			log.Printf("⚠️  Failed to load transactions from PostgreSQL: %v (using in-memory transaction store)", err)
+		} else {
+			pgStore.SetFeeConfig(cfg.FeeConfig())
+			pgStore.SetPricer(pricer)
+			pgStore.SetLedgerSigner(receipts.Sign) // Successful settlements are appended to the ledger
+			txnStore = pgStore
+			log.Println("✅ Transaction store backed by PostgreSQL")
+		}
+	}
+
+	// Versioned fee schedule: the config rates seed version 1, admins publish later versions
+	var feeSchedules payments.FeeScheduleStore = payments.NewMemoryFeeSchedules()
+	if pgClient != nil {
+		feeSchedules = postgres.NewFeeScheduleStore(pgClient)
+	}
+	if schedule, err := payments.ActiveFeeSchedule(ctx, feeSchedules, cfg.FeeConfig()); err != nil {
+		log.Printf("⚠️  Failed to load fee schedule: %v (using configured fees)", err)
+	} else {
+		txnStore.SetFeeSchedule(schedule)
+		log.Printf("💵 Fee schedule v%d active (base %.4g, hop %.4g, halt %.4g)",
+			schedule.Version, schedule.BaseFeePercent, schedule.HopFeePercent, schedule.HaltFinePercent)
+	}
+
+	// Organizations: members transact under their org's fee override and monthly spending limit
+	var orgStore organizations.Store = organizations.NewMemoryStore()
+	if pgClient != nil {
+		pgOrgs, err := postgres.NewOrganizationStore(ctx, pgClient)
+		if err != nil {
+			log.Printf("⚠️  Failed to load organizations from PostgreSQL: %v (using in-memory organization store)", err)
+		} else {
+			orgStore = pgOrgs
+		}
+	}
+	txnStore.SetOrgResolver(orgStore)
+
+	// Simulated hop latencies and failures; the logged seed replays a run
+	simulator, err := cfg.Simulator()
+	if err != nil {
+		log.Fatalf("Invalid simulation config: %v", err)
+	}
+	txnStore.SetSimulator(simulator)
+	log.Printf("🎲 Hop simulation: profile %q, seed %d", simulator.Profile().Name, simulator.Seed())
+
+	// Spending limits: per-transaction caps and daily/monthly volume per user and organization
+	var limitStore limits.Store = limits.NewMemoryStore()
+	if pgClient != nil {
+		limitStore = postgres.NewSpendingLimitStore(pgClient)
+	}
+	// Volume and limits are in USD, converted at the graph's current rates
+	txnStore.SetCurrencyRates(countryGraph.CurrencyRates)
+	limitEngine := limits.NewEngine(limitStore, txnStore)
+	limitEngine.SetDefaults(cfg.SpendingLimits())
+	limitEngine.SetOrgResolver(orgStore)
 
-	// Initialize payment system
-	txnStore := payments.NewTransactionStore()
+	if rdb != nil {
+		txnStore.SetIdempotencyBackend(rdb.Idempotency())
+
+		// Processing locks are shared so replicas never settle the same payment at once
+		txnStore.SetLocker(rdb.Locker())
+
+		// Failed hops open per-node circuits; open circuits block routes until probed
+		txnStore.SetCircuitBreaker(rdb.CircuitBreaker())
+		txnStore.SetCircuitCallback(func(nodeID string, prev, state redisClient.State) {
+			wsHub.BroadcastCircuitBreaker(&websocket.CircuitBreakerEvent{
+				NodeID:    nodeID,
+				State:     strings.ToLower(state.String()),
+				PrevState: strings.ToLower(prev.String()),
+			})
+		})
+	}
 	
-	// Set up credibility callback if Neo4j is available
+	// Hop outcomes move country credibility; the changes are summed per country
+	// and written to Neo4j in batches once it is available
+	credBatcher := neo4jstore.NewCredibilityBatcher(cfg.CredibilityBatchConfig())
+	txnStore.SetCredibilityCallback(credBatcher.Record)
+	// Credibility changes are kept for charts (persisted when PostgreSQL is available)
+	var credHistory neo4jstore.CredibilityHistory = neo4jstore.NewMemoryCredibilityHistory(neo4jstore.DefaultCredibilityRetention)
+	if pgClient != nil {
+		credHistory = postgres.NewCredibilityHistoryStore(pgClient)
+	}
+	credBatcher.SetHistory(credHistory)
+	go credBatcher.Start(ctx)
+	countryInfoHandler := handlers.NewCountryInfoHandler()
+	countryInfoHandler.SetCredibility(credBatcher, credHistory)
+	countryInfoHandler.SetGraph(countryGraph)
+	countryInfoHandler.SetTransactions(txnStore)
 	if neo4jClient != nil {
-		credUpdater := neo4jstore.NewCredibilityUpdater(neo4jClient.Driver(), neo4jCfg.Database)
-		txnStore.SetCredibilityCallback(func(countryCode string, success bool) {
-			go func() {
-				updateCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-				defer cancel()
-				credUpdater.UpdateCredibility(updateCtx, countryCode, success)
-			}()
-		})
+		credBatcher.SetWriter(neo4jstore.NewCredibilityUpdater(neo4jClient.Driver(), neo4jCfg.Database))
+		countryInfoHandler.SetNeo4jClient(neo4jClient)
 		log.Println("✅ Payment system initialized with credibility tracking")
 	} else {
 		log.Println("📊 Payment system initialized (no credibility tracking)")
 	}
 	
+	// Deliver payment lifecycle events to registered webhooks
+	webhookDispatcher := webhooks.NewDispatcher(webhooks.DefaultConfig())
+	go webhookDispatcher.Start(ctx)
+	webhookHandler := handlers.NewWebhookHandler(webhookDispatcher)
+
+	// Mesh node entropy follows settled flows: from NATS when connected (every
+	// replica's settlements), otherwise from this server's transaction store
+	entropyUpdater := entropyfeed.NewUpdater(graph, cfg.EntropyFeedConfig())
+	go entropyUpdater.Start(ctx)
+	entropyFromNATS := false
+
+	// Aggregated stats pushed to admin dashboards over /ws/admin
+	statsPublisher := adminstats.NewPublisher(txnStore, wsHub, cfg.AdminStatsConfig())
+	if rdb != nil {
+		breaker := rdb.CircuitBreaker()
+		statsPublisher.SetCircuitSource(func(ctx context.Context) (map[string]string, error) {
+			circuits, err := breaker.GetAllCircuits(ctx)
+			if err != nil {
+				return nil, err
+			}
+			states := make(map[string]string, len(circuits))
+			for name, state := range circuits {
+				states[name] = strings.ToLower(state.State.String())
+			}
+			return states, nil
+		})
+	}
+	if eventBus != nil {
+		statsPublisher.SetLagSource(func(ctx context.Context) (uint64, error) {
+			return eventBus.ConsumerLag(ctx, natsClient.LiquidityUpdatesStream, consumers.GraphSyncConsumerName)
+		})
+	}
+	go statsPublisher.Start(ctx)
+	var dlqHandler *handlers.DeadLetterHandler
+
+	// Liquidity updates from NATS are applied to Neo4j
+	startGraphSync := func(client *neo4jstore.Client) *consumers.GraphSyncConsumer {
+		graphSync, err := consumers.NewGraphSyncConsumer(ctx, eventBus, client, nil)
+		if err != nil {
+			log.Printf("⚠️  Graph sync consumer not started: %v", err)
+			return nil
+		}
+		graphSync.Start()
+		return graphSync
+	}
+
+	// Publish settlement lifecycle events to NATS; every replica forwards them to its WebSocket clients
+	if nc := eventBus; nc != nil {
+		txnStore.SetEventPublisher(nc)
+		if forwarder, err := consumers.NewSettlementEventForwarder(ctx, nc, wsHub); err != nil {
+			log.Printf("⚠️  Settlement event forwarder not started: %v", err)
+		} else if err := forwarder.Start(); err != nil {
+			log.Printf("⚠️  Settlement event forwarder not started: %v", err)
+		} else {
+			defer forwarder.Stop()
+		}
+		if flows, err := consumers.NewSettlementFlowFeed(ctx, nc, entropyUpdater.Observe); err != nil {
+			log.Printf("⚠️  Settlement flow feed not started: %v", err)
+		} else if err := flows.Start(); err != nil {
+			log.Printf("⚠️  Settlement flow feed not started: %v", err)
+		} else {
+			defer flows.Stop()
+			entropyFromNATS = true
+		}
+
+		// Liquidity updates that keep failing are dead-lettered for inspection and replay
+		dlqHandler = handlers.NewDeadLetterHandler(nc)
+		dlqHandler.SetAuditStore(auditStore)
+		metrics.RegisterDeadLetterDepth(func() float64 {
+			depthCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+			depth, err := nc.DeadLetterDepth(depthCtx)
+			if err != nil {
+				return math.NaN()
+			}
+			return float64(depth)
+		})
+		if neo4jClient != nil {
+			if graphSync := startGraphSync(neo4jClient); graphSync != nil {
+				defer graphSync.Stop()
+			}
+		}
+		log.Println("✅ Publishing settlement events to NATS")
+	}
+	// GraphQL queries and subscriptions over the graphs, transactions and routes
+	graphqlHandler := handlers.NewGraphQLHandler(graph, countryGraph, txnStore, wsHub)
+	graphqlHandler.SetAuthMiddleware(authMiddleware)
+	graphqlHandler.SetAuditStore(auditStore)
+
+	// Settled payments with a payout account are transferred to the
+	// recipient's Stripe Connect account; unpaid ones are retried on startup
+	stripeClient := payments.NewStripeClientWithKeys(cfg.Stripe.SecretKey, cfg.Stripe.PublishableKey, cfg.Stripe.WebhookSecret)
+	stripeClient.SetReturnURL(cfg.StripeReturnURL())
+	paymentProviders, err := cfg.PaymentProviders(stripeClient)
+	if err != nil {
+		log.Fatalf("❌ Invalid payment providers: %v", err)
+	}
+	payer := payouts.NewPayer(txnStore, paymentProviders, stripeClient, nil) // Pays out only what a provider collected
+	go payer.Start(ctx)
+
+	// Webhooks, emails, and the entropy updater when NATS is not feeding it, follow payment lifecycle events
+	txnStore.SetStatusCallback(func(event payments.StatusEvent, txn *payments.Transaction) {
+		webhookHandler.DispatchTransactionEvent(event, txn)
+		payer.Observe(event, txn)
+		graphqlHandler.PublishTransaction(event, txn)
+		if notifier != nil {
+			notifier.NotifyTransaction(event, txn)
+		}
+		if !entropyFromNATS {
+			entropyUpdater.ObserveTransaction(event, txn)
+		}
+		if event == payments.EventPaymentSucceeded {
+			for _, hop := range txn.HopResults {
+				hopTimes.Observe(hop.FromCountry, hop.ToCountry, time.Duration(hop.Latency)*time.Millisecond)
+			}
+		}
+	})
+
 	paymentHandler := handlers.NewPaymentHandler(txnStore, countryGraph)
+	paymentHandler.SetStripeClient(stripeClient)
+	paymentHandler.SetPayer(payer) // Disputed payouts are held, and reversed when the dispute is accepted
+	paymentHandler.SetProviders(paymentProviders)
+	paymentHandler.SetRefundPolicy(cfg.RefundPolicy())
+	paymentHandler.SetQuoteSigner(payments.NewQuoteSigner(cfg.QuoteConfig()))
+	paymentHandler.SetLimits(limitEngine)
+	paymentHandler.SetScreener(compliance.Chain{
+		compliance.NewRuleScreener(cfg.ScreeningConfig()),
+		compliance.NewKYCScreener(userStore, cfg.Compliance.KYCThreshold),
+	})
+	paymentHandler.SetAuditStore(auditStore)
+	if cfg.Quotes.SigningKey == "" {
+		log.Println("⚠️  QUOTE_SIGNING_KEY not set - quotes are only valid on this instance until restart")
+	}
+	paymentHandler.SetFXRates(countryGraph.FXRates())
+	paymentHandler.SetHaltedNodes(countryGraph.InactiveNodes()) // Halts persist in Neo4j across restarts
+
+	// Country admin endpoints and the country graph refresher need Neo4j;
+	// until it is available the endpoints answer 503
+	var countryHandler atomic.Pointer[handlers.CountryHandler]
+	attachCountryAdmin := func(client *neo4jstore.Client) *router.CountryGraphRefresher {
+		h := handlers.NewCountryHandler(client.Driver(), neo4jCfg.Database)
+		h.SetAuditStore(auditStore)
+		h.SetCountryGraph(countryGraph)
+		h.SetHub(wsHub)
+		h.SetHaltTracker(paymentHandler)
+		h.SetRouteInvalidator(routeHandler)
+
+		// Apply the persisted block list, telling route clients when it changed
+		applyBlocked := func() {
+			blockCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+			codes, err := neo4jstore.LoadBlockedCountries(blockCtx, client.Driver(), neo4jCfg.Database)
+			if err != nil {
+				log.Printf("⚠️  Failed to load blocked countries: %v", err)
+				return
+			}
+			if !slices.Equal(codes, countryGraph.BlockedCodes()) {
+				countryGraph.SetBlocked(codes)
+				routeHandler.InvalidateRoutes("blocked_countries")
+			}
+		}
+		applyBlocked()
+
+		// Reload credibility, success rates and FX rates from Neo4j periodically
+		refresher := router.NewCountryGraphRefresher(countryGraph, client.Driver(), neo4jCfg.Database, time.Duration(cfg.Routing.GraphRefresh))
+		refresher.OnRefresh(func(g *router.CountryGraph) {
+			paymentHandler.SetFXRates(g.FXRates())
+			paymentHandler.SetHaltedNodes(g.InactiveNodes()) // Picks up halts made on other replicas
+			applyBlocked()                                   // and block list changes
+		})
+		h.SetRefresher(refresher)
+		countryHandler.Store(h)
+		go refresher.Start(ctx)
+		return refresher
+	}
+	if neo4jClient != nil {
+		attachCountryAdmin(neo4jClient)
+	}
+
+	// Settlement netting (netting.enabled / NETTING_ENABLED=true): confirmed
+	// payments wait for the window, then each corridor settles its net amount
+	if cfg.Netting.Enabled {
+		netter := netting.NewNetter(txnStore, cfg.NetterConfig())
+		netter.SetFXRates(countryGraph.FXRates)
+		if n := netter.Recover(); n > 0 {
+			log.Printf("⚖️  Re-queued %d payments for netting", n)
+		}
+		paymentHandler.SetNetter(netter)
+		go netter.Start(ctx)
+		log.Printf("⚖️  Settlement netting enabled (window: %v)", time.Duration(cfg.Netting.Window))
+	}
+
+	// Settlements the last shutdown interrupted are compensated and retried
+	if n := paymentHandler.ResumeInterrupted(ctx); n > 0 {
+		log.Printf("🔁 Resuming %d transactions interrupted by the last shutdown", n)
+	}
+
+	// Payments stuck in pending/processing are resumed, failed (and refunded)
+	// or escalated to the review queue
+	reconciler := recovery.NewReconciler(txnStore, paymentHandler, cfg.RecoveryConfig())
+	if !stripeClient.IsMockMode() {
+		reconciler.SetPaymentIntents(stripeClient) // Mock intents always report succeeded
+	}
+	go reconciler.Start(ctx)
+
+	// FX rate worker: fetched rates update Neo4j, the routing graph, payment
+	// conversions and connected clients
+	fxConfig := cfg.FXWorkerConfig()
+	fxConfig.Currencies = neo4jstore.GetAllCurrencies()
+	if neo4jClient != nil {
+		fxConfig.Driver = neo4jClient.Driver()
+		fxConfig.Database = neo4jCfg.Database
+	}
+	fxWorker := fxrates.NewWorker(fxConfig)
+	// Switch from the default graph to Neo4j when it becomes reachable after boot
+	neo4jSupervisor.OnConnect(func(client *neo4jstore.Client) {
+		log.Println("🔄 Neo4j available, switching from the default graph")
+		bootstrapNeo4j(client)
+		meshCtx, meshCancel := context.WithTimeout(ctx, 30*time.Second)
+		if err := loadMeshGraph(meshCtx, client, graph); err != nil {
+			log.Printf("⚠️  Failed to load mesh from Neo4j: %v (keeping the default graph)", err)
+		}
+		meshCancel()
+
+		livenessTracker.SetNodeStore(client)
+		adminHandler.SetNeo4jClient(client)
+		credBatcher.SetWriter(neo4jstore.NewCredibilityUpdater(client.Driver(), neo4jCfg.Database))
+		countryInfoHandler.SetNeo4jClient(client)
+		fxWorker.SetDriver(client.Driver(), neo4jCfg.Database)
+
+		refresher := attachCountryAdmin(client)
+		refreshCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		if err := refresher.Refresh(refreshCtx); err != nil {
+			log.Printf("⚠️  Failed to load country graph from Neo4j: %v (retrying on refresh)", err)
+		} else {
+			log.Println("✅ Country routing graph loaded from Neo4j")
+		}
+
+		if eventBus != nil {
+			if graphSync := startGraphSync(client); graphSync != nil {
+				go func() {
+					<-ctx.Done()
+					graphSync.Stop()
+				}()
+			}
+		}
+	})
+	go neo4jSupervisor.Start(ctx)
+	fxWorker.Seed(countryGraph.CurrencyRates(), time.Now().UTC()) // Serve the graph's rates until the first fetch
+	fxWorker.OnUpdate(func(u *fxrates.Update) {
+		paymentHandler.SetFXRates(countryGraph.ApplyFXRates(u.Rates))
+		wsHub.BroadcastFXRate(&websocket.FXRateUpdate{
+			Base:      u.Base,
+			Rates:     u.Rates,
+			Provider:  u.Provider,
+			UpdatedAt: u.FetchedAt,
+		})
+	})
+
+	// FX rate history for trend charts and volatility (persisted when PostgreSQL is available)
+	var fxHistory fxrates.HistoryStore = fxrates.NewMemoryHistory(fxrates.DefaultHistoryRetention)
+	if pgClient != nil {
+		fxHistory = postgres.NewFXHistoryStore(pgClient)
+	}
+	fxWorker.OnUpdate(func(u *fxrates.Update) {
+		if err := fxHistory.Append(ctx, u); err != nil {
+			log.Printf("⚠️  Failed to record FX history: %v", err)
+		}
+	})
+	go fxWorker.Start(ctx)
+	fxHandler := handlers.NewFXHandler(fxWorker)
+	fxHandler.SetHistoryStore(fxHistory)
 	receiptHandler := handlers.NewReceiptHandler(txnStore)
 
+	// Recurring and future-dated payments, routed over the best path at execution time
+	var scheduleStore scheduler.Store = scheduler.NewMemoryStore()
+	if pgClient != nil && cfg.Storage.TransactionStore == "postgres" {
+		scheduleStore = postgres.NewScheduleStore(pgClient)
+	}
+	schedulerCfg := scheduler.DefaultConfig()
+	schedulerCfg.Interval = time.Duration(cfg.Scheduler.Interval)
+	paymentScheduler := scheduler.NewScheduler(scheduleStore, txnStore, router.NewCountryRouter(countryGraph, 1).BestRoute, schedulerCfg)
+	paymentScheduler.SetFXRates(countryGraph.FXRates)
+	paymentScheduler.SetCreator(paymentHandler.CreateScreenedTransaction) // Screened and limited like API payments
+	paymentScheduler.SetNotifier(func(event scheduler.Event, s *scheduler.Schedule, txn *payments.Transaction) {
+		webhookDispatcher.Dispatch(string(event), s.UserID, map[string]interface{}{
+			"schedule":    s,
+			"transaction": txn,
+		})
+		wsHub.BroadcastToUser(s.UserID, map[string]interface{}{
+			"type": "SCHEDULED_PAYMENT",
+			"data": map[string]interface{}{
+				"event":       event,
+				"schedule_id": s.ID,
+				"status":      s.Status,
+				"next_run_at": s.NextRunAt,
+			},
+		})
+	})
+	go paymentScheduler.Start(ctx)
+	scheduleHandler := handlers.NewScheduleHandler(paymentScheduler)
+
+	// Node-to-node settlement over gRPC (grpc.enabled / GRPC_ENABLED=true)
+	var settlementServer *plmgrpc.Server
+	var settlementService *plmgrpc.SettlementService
+	// One forwarder per peer instance, shared by the nodes it serves
+	forwarders := make(map[string]*plmgrpc.Forwarder)
+	var heartbeater *plmgrpc.Heartbeater
+	dialPeer := func(addr string) (*plmgrpc.Forwarder, error) {
+		if f, ok := forwarders[addr]; ok {
+			return f, nil
+		}
+		f, err := plmgrpc.DialForwarder(ctx, cfg.GRPCClientConfig(addr))
+		if err != nil {
+			return nil, err
+		}
+		forwarders[addr] = f
+		heartbeater.AddPeer(f)
+		return f, nil
+	}
+	if cfg.GRPC.Enabled {
+		grpcCfg := cfg.GRPCServerConfig()
+		settlementServer, err = plmgrpc.NewServer(grpcCfg)
+		if err != nil {
+			log.Fatalf("Failed to create gRPC server: %v", err)
+		}
+
+		settlementService = plmgrpc.NewSettlementService(graph, livenessTracker)
+		settlementService.SetRouter(meshRouter)
+		settlementService.SetTransactionStore(txnStore)
+		if rdb != nil {
+			settlementService.SetCircuitBreaker(rdb.CircuitBreaker())
+		}
+
+		// Peers deactivate this instance's nodes when its heartbeats stop
+		heartbeater = plmgrpc.NewHeartbeater(settlementService.LocalNodes, time.Duration(cfg.Liveness.HeartbeatInterval))
+		go heartbeater.Start(ctx)
+
+		for nodeID, addr := range cfg.GRPC.Peers {
+			f, err := dialPeer(addr)
+			if err != nil {
+				log.Fatalf("Failed to connect to settlement peer %s: %v", addr, err)
+			}
+			settlementService.SetPeer(nodeID, f)
+		}
+		if len(cfg.GRPC.Peers) > 0 {
+			log.Printf("🔗 Forwarding settlement hops for %d nodes to %d peer instances", len(cfg.GRPC.Peers), len(forwarders))
+		}
+		plmgrpc.RegisterSettlementServiceServer(settlementServer.GRPCServer(), settlementService)
+
+		go func() {
+			log.Printf("🔗 gRPC settlement service listening on %s", grpcCfg.Address)
+			if err := settlementServer.Start(); err != nil {
+				log.Printf("⚠️  gRPC server error: %v", err)
+			}
+		}()
+	}
+
+	// Node registry for multi-instance deployments (registry.enabled / REGISTRY_ENABLED=true):
+	// nodes are routed through only while registered healthy, and hops from
+	// nodes served by other instances are forwarded to them
+	var registryHandler *handlers.RegistryHandler
+	if cfg.Registry.Enabled {
+		regCfg := cfg.NodeRegistryConfig()
+		if cfg.GRPC.CertFile != "" {
+			if regCfg.CertFingerprint, err = registry.CertFingerprint(cfg.GRPC.CertFile); err != nil {
+				log.Fatalf("Failed to fingerprint gRPC certificate: %v", err)
+			}
+		}
+
+		var backend registry.Backend = registry.NewMemoryBackend()
+		if rdb != nil {
+			backend = rdb.NodeRegistry()
+		} else {
+			log.Println("⚠️  Node registry without Redis: only this instance's nodes are visible")
+		}
+		nodeRegistry := registry.NewRegistry(backend, graph, regCfg)
+		if settlementService != nil {
+			nodeRegistry.SetPeerFunc(func(nodeID, addr string) {
+				if addr == "" {
+					settlementService.SetPeer(nodeID, nil)
+					log.Printf("📒 Node %s is no longer served by a peer", nodeID)
+					return
+				}
+				f, err := dialPeer(addr)
+				if err != nil {
+					log.Printf("⚠️  Failed to connect to settlement peer %s for %s: %v", addr, nodeID, err)
+					return
+				}
+				settlementService.SetPeer(nodeID, f)
+				log.Printf("📒 Node %s is served by peer %s", nodeID, addr)
+			})
+		}
+		go nodeRegistry.Start(ctx)
+		registryHandler = handlers.NewRegistryHandler(nodeRegistry)
+	}
+
 	// Setup HTTP routes
 	mux := http.NewServeMux()
 
-	// CORS middleware for Next.js frontend
+	// Versioned API: handlers register relative to /api/{version}, so /api/v2
+	// can serve breaking changes next to /api/v1 while v1 is deprecated
+	apiVersions := versioning.NewRouter(mux)
+	v1 := apiVersions.Version("v1")
+	mux.HandleFunc("/api/versions", apiVersions.HandleVersions)
+
+	// CORS middleware for Next.js frontend (server.cors_origins / CORS_ORIGINS)
+	allowedOrigins := make(map[string]bool)
+	for _, origin := range cfg.Server.CORSOrigins {
+		allowedOrigins[origin] = true
+	}
 	corsHandler := func(h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			w.Header().Set("Access-Control-Allow-Origin", "*")
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+			if cfg.AllowsAnyOrigin() {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Add("Vary", "Origin")
+				if origin := r.Header.Get("Origin"); allowedOrigins[origin] {
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+				}
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Request-ID")
+			w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, Idempotent-Replayed, API-Version, Deprecation, Sunset, Link")
 			if r.Method == "OPTIONS" {
 				w.WriteHeader(http.StatusOK)
 				return
@@ -165,110 +885,465 @@ func main() {
 	// Public endpoints
 	mux.HandleFunc("/ws", wsHub.ServeWS)
 	mux.HandleFunc("/ws/route", routeHandler.HandleRouteWS) // WebSocket for route calculation
+	mux.HandleFunc("/ws/graphql", graphqlHandler.HandleGraphQLWS) // GraphQL subscriptions (graphql-transport-ws)
+	mux.HandleFunc("/ws/admin", wsHub.ServeAdminWS)                // Admin dashboard stats, admin token required
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte("OK"))
+		// Always 200 while serving: the server degrades to the default graph without Neo4j
+		neo4jHealth := neo4jSupervisor.Health()
+		status := "ok"
+		if !neo4jHealth.Connected {
+			status = "degraded"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": status,
+			"neo4j":  neo4jHealth,
+		})
 	})
+	mux.Handle("/metrics", metrics.Handler())
+	metrics.RegisterWebSocketClients(wsHub.ClientCount)
+
+	// Liveness and readiness probes. /readyz returns 503 until the configured
+	// dependencies are reachable (HEALTH_OPTIONAL lists those not waited for)
+	healthHandler := handlers.NewHealthHandler(time.Duration(cfg.Health.CheckTimeout))
+	if cfg.DependencyConfigured("neo4j") {
+		healthHandler.AddDependency("neo4j", cfg.DependencyRequired("neo4j"), func(ctx context.Context) error {
+			client := neo4jSupervisor.Client()
+			if client == nil {
+				return handlers.ErrNotConnected
+			}
+			return client.Driver().VerifyConnectivity(ctx)
+		})
+	}
+	if cfg.DependencyConfigured("postgres") {
+		healthHandler.AddDependency("postgres", cfg.DependencyRequired("postgres"), func(ctx context.Context) error {
+			if pgClient == nil {
+				return handlers.ErrNotConnected
+			}
+			return pgClient.DB().PingContext(ctx)
+		})
+	}
+	if cfg.DependencyConfigured("redis") {
+		healthHandler.AddDependency("redis", cfg.DependencyRequired("redis"), func(ctx context.Context) error {
+			if rdb == nil {
+				return handlers.ErrNotConnected
+			}
+			return rdb.Redis().Ping(ctx).Err()
+		})
+	}
+	if cfg.DependencyConfigured("nats") {
+		healthHandler.AddDependency("nats", cfg.DependencyRequired("nats"), func(ctx context.Context) error {
+			if eventBus == nil {
+				return handlers.ErrNotConnected
+			}
+			return eventBus.Connection().FlushWithContext(ctx)
+		})
+	}
+	mux.HandleFunc("/healthz", healthHandler.HandleLiveness)
+	mux.HandleFunc("/readyz", healthHandler.HandleReadiness)
 
 	// Auth endpoints (public)
-	mux.HandleFunc("/api/v1/auth/login", authHandler.HandleLogin)
-	mux.HandleFunc("/api/v1/auth/register", authHandler.HandleRegister)
+	v1.Handle("/auth/login", loginLimit(http.HandlerFunc(authHandler.HandleLogin)))
+	v1.HandleFunc("/auth/register", authHandler.HandleRegister)
+	v1.HandleFunc("/openapi.json", handlers.NewOpenAPIHandler().HandleOpenAPI) // Public: API description for client generators
+	v1.Handle("/auth/forgot-password", loginLimit(http.HandlerFunc(authHandler.HandleForgotPassword)))
+	v1.Handle("/auth/reset-password", loginLimit(http.HandlerFunc(authHandler.HandleResetPassword)))
 
 	// Protected User endpoints (require auth)
-	mux.Handle("/api/v1/settle/preview", authMiddleware.Authenticate(http.HandlerFunc(userHandler.HandleSettlePreview)))
-	mux.Handle("/api/v1/route", authMiddleware.Authenticate(http.HandlerFunc(routeHandler.HandleRouteHTTP)))
+	v1.Handle("/settle/preview", authMiddleware.Authenticate(http.HandlerFunc(userHandler.HandleSettlePreview)))
+	v1.Handle("/route", middleware.Chain(
+		authMiddleware.Authenticate,
+		routeLimit,
+	)(http.HandlerFunc(routeHandler.HandleRouteHTTP)))
+	v1.Handle("/route/simulate", middleware.Chain(
+		authMiddleware.Authenticate,
+		routeLimit,
+	)(http.HandlerFunc(routeHandler.HandleRouteSimulate)))
+	mux.Handle("/api/graphql", middleware.Chain(
+		authMiddleware.Authenticate,
+		routeLimit,
+	)(http.HandlerFunc(graphqlHandler.HandleGraphQL)))
 	
-	// Payment endpoints (require auth + regular user only - admins cannot make payments)
-	mux.Handle("/api/v1/payments/create", middleware.Chain(
+	// Payment endpoints (require auth + regular user only - admins cannot make payments).
+	// New payments are refused while in-flight ones drain on shutdown.
+	acceptPayments := middleware.RejectWhileDraining(txnStore.Draining)
+	v1.Handle("/payments/create", middleware.Chain(
 		authMiddleware.Authenticate,
 		authMiddleware.RequireUser,
+		paymentsLimit,
+		acceptPayments,
 	)(http.HandlerFunc(paymentHandler.HandleCreatePayment)))
-	mux.Handle("/api/v1/payments/confirm", middleware.Chain(
+	v1.Handle("/payments/quote", middleware.Chain(
 		authMiddleware.Authenticate,
 		authMiddleware.RequireUser,
+		paymentsLimit,
+	)(http.HandlerFunc(paymentHandler.HandleQuote)))
+	v1.Handle("/payments/confirm", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireUser,
+		paymentsLimit,
+		acceptPayments,
 	)(http.HandlerFunc(paymentHandler.HandleConfirmPayment)))
-	mux.Handle("/api/v1/payments/history", authMiddleware.Authenticate(http.HandlerFunc(paymentHandler.HandleGetHistory)))
-	mux.Handle("/api/v1/payments/transaction", authMiddleware.Authenticate(http.HandlerFunc(paymentHandler.HandleGetTransaction)))
-	mux.Handle("/api/v1/payments/charts", authMiddleware.Authenticate(http.HandlerFunc(paymentHandler.HandleChartData)))
-	mux.HandleFunc("/api/v1/receipts/", receiptHandler.HandleDownloadReceipt) // Public: allow receipt downloads
+	v1.Handle("/payments/batch", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireUser,
+		paymentsLimit,
+		acceptPayments,
+	)(http.HandlerFunc(paymentHandler.HandleCreateBatch)))
+	v1.Handle("/payments/cancel", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireUser,
+		paymentsLimit,
+	)(http.HandlerFunc(paymentHandler.HandleCancelPayment)))
+	v1.Handle("/payments/batch/", middleware.Chain(
+		authMiddleware.Authenticate,
+		paymentsLimit,
+	)(http.HandlerFunc(paymentHandler.HandleGetBatch)))
+	v1.Handle("/payments/schedules", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireUser,
+		paymentsLimit,
+	)(http.HandlerFunc(scheduleHandler.HandleSchedules)))
+	v1.Handle("/payments/schedules/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireUser,
+		paymentsLimit,
+	)(http.HandlerFunc(scheduleHandler.HandleSchedule)))
+	v1.Handle("/payments/history", middleware.Chain(
+		authMiddleware.Authenticate,
+		paymentsLimit,
+	)(http.HandlerFunc(paymentHandler.HandleGetHistory)))
+	v1.Handle("/payments/transaction", middleware.Chain(
+		authMiddleware.Authenticate,
+		paymentsLimit,
+	)(http.HandlerFunc(paymentHandler.HandleGetTransaction)))
+	v1.Handle("/payments/charts", middleware.Chain(
+		authMiddleware.Authenticate,
+		paymentsLimit,
+	)(http.HandlerFunc(paymentHandler.HandleChartData)))
+	limitHandler := handlers.NewLimitHandler(limitEngine, limitStore)
+	limitHandler.SetAuditStore(auditStore)
+	v1.Handle("/payments/limits", middleware.Chain(
+		authMiddleware.Authenticate,
+		paymentsLimit,
+	)(http.HandlerFunc(limitHandler.HandleQuota)))
+	v1.Handle("/payments/disputes", middleware.Chain(
+		authMiddleware.Authenticate,
+		paymentsLimit,
+	)(http.HandlerFunc(paymentHandler.HandleDisputes)))
+	v1.HandleFunc("/receipts/", receiptHandler.HandleDownloadReceipt) // Public: allow receipt downloads
 	
 	// Stripe payment endpoints (Endpoint A and B - regular users only)
-	mux.Handle("/api/v1/stripe/initiate", middleware.Chain(
+	v1.Handle("/stripe/initiate", middleware.Chain(
 		authMiddleware.Authenticate,
 		authMiddleware.RequireUser,
+		acceptPayments,
 	)(http.HandlerFunc(paymentHandler.HandleStripeInitiate)))
-	mux.Handle("/api/v1/stripe/complete", middleware.Chain(
+	v1.Handle("/stripe/complete", middleware.Chain(
 		authMiddleware.Authenticate,
 		authMiddleware.RequireUser,
+		acceptPayments,
 	)(http.HandlerFunc(paymentHandler.HandleStripeComplete)))
-	mux.HandleFunc("/api/v1/stripe/config", paymentHandler.HandleStripeConfig) // Public: returns publishable key
+	v1.Handle("/stripe/resume", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireUser,
+		acceptPayments,
+	)(http.HandlerFunc(paymentHandler.HandleStripeResume))) // After 3-D Secure authentication
+	v1.HandleFunc("/stripe/config", paymentHandler.HandleStripeConfig) // Public: returns publishable key
+	v1.Handle("/stripe/webhook", acceptPayments(http.HandlerFunc(paymentHandler.HandleStripeWebhook))) // Public: authenticated by Stripe-Signature; Stripe redelivers refused events
+
+	// FX endpoints (public market data)
+	v1.HandleFunc("/fx/rates", fxHandler.HandleRates)
+	v1.HandleFunc("/fx/history", fxHandler.HandleHistory)
+	v1.HandleFunc("/countries", countryInfoHandler.HandleListCountries) // Public, no authentication
+	v1.HandleFunc("/countries/", countryInfoHandler.HandleCountry)      // {code}, {code}/credibility/history
+
+	// Webhook endpoints (require auth)
+	v1.Handle("/webhooks", authMiddleware.Authenticate(http.HandlerFunc(webhookHandler.HandleWebhooks)))
+	v1.Handle("/webhooks/", authMiddleware.Authenticate(http.HandlerFunc(webhookHandler.HandleWebhook)))
+
+	// Notification preferences (require auth)
+	v1.Handle("/notifications/preferences", authMiddleware.Authenticate(http.HandlerFunc(notificationHandler.HandlePreferences)))
 
-	// Protected Admin endpoints (require auth + admin role)
-	mux.Handle("/api/v1/admin/nodes", middleware.Chain(
+	// Identity verification: users submit document metadata, compliance staff review it
+	kycHandler := handlers.NewKYCHandler(userStore)
+	kycHandler.SetAuditStore(auditStore)
+	v1.Handle("/kyc", authMiddleware.Authenticate(http.HandlerFunc(kycHandler.HandleMyKYC)))
+
+	// Caller's organization: summary for members, members and org history for org admins
+	orgHandler := handlers.NewOrgHandler(orgStore, userStore, txnStore)
+	orgHandler.SetAuditStore(auditStore)
+	v1.Handle("/org", authMiddleware.Authenticate(http.HandlerFunc(orgHandler.HandleMyOrg)))
+	v1.Handle("/org/", authMiddleware.Authenticate(http.HandlerFunc(orgHandler.HandleMyOrg)))
+
+	// Protected Admin endpoints (require auth + the route's permission, see auth.DefaultGrants)
+	v1.Handle("/admin/nodes", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
+		authMiddleware.RequirePermission(auth.PermGraphWrite),
 	)(http.HandlerFunc(adminHandler.HandleCreateNode)))
-	mux.Handle("/api/v1/admin/edges", middleware.Chain(
+	v1.Handle("/admin/nodes/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermGraphRead, auth.PermGraphWrite),
+	)(http.HandlerFunc(adminHandler.HandleNode)))
+	v1.Handle("/admin/edges", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermGraphWrite),
+	)(http.HandlerFunc(adminHandler.HandleEdges)))
+	v1.Handle("/admin/edges/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermGraphWrite),
+	)(http.HandlerFunc(adminHandler.HandleEdge)))
+
+	// Mesh and country state for the dashboard graph, revalidated with ETags
+	v1.Handle("/graph", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
-	)(http.HandlerFunc(adminHandler.HandleCreateEdge)))
+		authMiddleware.RequirePermission(auth.PermGraphRead),
+	)(http.HandlerFunc(adminHandler.HandleGraph)))
 
-	// Country admin endpoints (if Neo4j available)
-	if countryHandler != nil {
-		mux.Handle("/api/v1/admin/countries", middleware.Chain(
+	// Graph backup and restore (mesh + country graphs)
+	v1.Handle("/admin/graph/export", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermGraphRead),
+	)(http.HandlerFunc(adminHandler.HandleExportGraph)))
+	v1.Handle("/admin/graph/import", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermGraphWrite),
+	)(http.HandlerFunc(adminHandler.HandleImportGraph)))
+
+	// Edge utilization and rebalancing recommendations
+	liquidityHandler := handlers.NewLiquidityHandler(liquidityAdvisor)
+	v1.Handle("/admin/liquidity/recommendations", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermGraphRead),
+	)(http.HandlerFunc(liquidityHandler.HandleRecommendations)))
+
+	// Admin user management (list/CSV export, activate/deactivate, role changes)
+	userAdminHandler := handlers.NewUserAdminHandler(userStore)
+	userAdminHandler.SetAuditStore(auditStore)
+	v1.Handle("/admin/users", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermUsersRead),
+	)(http.HandlerFunc(userAdminHandler.HandleListUsers)))
+	v1.Handle("/admin/users/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermUsersWrite),
+	)(http.HandlerFunc(userAdminHandler.HandleUpdateUser)))
+
+	// Organization management (orgs:read/orgs:write, audited)
+	v1.Handle("/admin/orgs", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermOrgsRead, auth.PermOrgsWrite),
+	)(http.HandlerFunc(orgHandler.HandleAdminOrgs)))
+	v1.Handle("/admin/orgs/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermOrgsRead, auth.PermOrgsWrite),
+	)(http.HandlerFunc(orgHandler.HandleAdminOrgs)))
+
+	// Compliance review queue (compliance:read/compliance:write, audited)
+	v1.Handle("/admin/reviews", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermComplianceRead, auth.PermComplianceWrite),
+	)(http.HandlerFunc(paymentHandler.HandleReviews)))
+	v1.Handle("/admin/reviews/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermComplianceRead, auth.PermComplianceWrite),
+	)(http.HandlerFunc(paymentHandler.HandleReviews)))
+
+	// KYC review queue (compliance:read/compliance:write, audited)
+	v1.Handle("/admin/kyc", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermComplianceRead, auth.PermComplianceWrite),
+	)(http.HandlerFunc(kycHandler.HandleAdminKYC)))
+	v1.Handle("/admin/kyc/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermComplianceRead, auth.PermComplianceWrite),
+	)(http.HandlerFunc(kycHandler.HandleAdminKYC)))
+
+	// Dispute queue (disputes:read/disputes:write, audited; accepting refunds through Stripe)
+	v1.Handle("/admin/disputes", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermDisputesRead, auth.PermDisputesWrite),
+	)(http.HandlerFunc(paymentHandler.HandleAdminDisputes)))
+	v1.Handle("/admin/disputes/{id}/{action}", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermDisputesRead, auth.PermDisputesWrite),
+	)(http.HandlerFunc(paymentHandler.HandleAdminDisputes)))
+
+	// Spending limits (limits:read/limits:write, audited)
+	v1.Handle("/admin/limits", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermLimitsRead, auth.PermLimitsWrite),
+	)(http.HandlerFunc(limitHandler.HandleAdminLimits)))
+	v1.Handle("/admin/limits/", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermLimitsRead, auth.PermLimitsWrite),
+	)(http.HandlerFunc(limitHandler.HandleAdminLimits)))
+
+	// Node registry (if enabled)
+	if registryHandler != nil {
+		v1.Handle("/admin/registry/nodes", middleware.Chain(
 			authMiddleware.Authenticate,
-		)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			switch r.Method {
-			case http.MethodGet:
-				countryHandler.HandleListCountries(w, r)
-			case http.MethodPost:
-				countryHandler.HandleCreateCountry(w, r)
-			default:
-				http.Error(w, `{"error":"method not allowed"}`, http.StatusMethodNotAllowed)
-			}
-		})))
-		mux.Handle("/api/v1/admin/countries/", middleware.Chain(
+			authMiddleware.RequirePermission(auth.PermGraphRead),
+		)(http.HandlerFunc(registryHandler.HandleNodes)))
+	}
+
+	// Dead letter queue (if NATS connected; graph:read to inspect, graph:write to replay or discard)
+	if dlqHandler != nil {
+		v1.Handle("/admin/dlq", middleware.Chain(
+			authMiddleware.Authenticate,
+			authMiddleware.RequireMethodPermission(auth.PermGraphRead, auth.PermGraphWrite),
+		)(http.HandlerFunc(dlqHandler.HandleDeadLetters)))
+		v1.Handle("/admin/dlq/", middleware.Chain(
 			authMiddleware.Authenticate,
-			authMiddleware.RequireAdmin,
-		)(http.HandlerFunc(countryHandler.HandleDeleteCountry)))
+			authMiddleware.RequireMethodPermission(auth.PermGraphRead, auth.PermGraphWrite),
+		)(http.HandlerFunc(dlqHandler.HandleDeadLetters)))
+	}
+
+	// Country admin endpoints (503 until Neo4j is available)
+	withCountryHandler := func(serve func(*handlers.CountryHandler, http.ResponseWriter, *http.Request)) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := countryHandler.Load()
+			if h == nil {
+				apierror.Respond(w, http.StatusServiceUnavailable, "Neo4j not available")
+				return
+			}
+			serve(h, w, r)
+		})
 	}
+	v1.Handle("/admin/countries", middleware.Chain(
+		authMiddleware.Authenticate,
+	)(withCountryHandler(func(h *handlers.CountryHandler, w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			h.HandleListCountries(w, r)
+		case http.MethodPost:
+			h.HandleCreateCountry(w, r)
+		default:
+			apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	})))
+	v1.Handle("/admin/countries/refresh", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermCountriesWrite),
+	)(withCountryHandler((*handlers.CountryHandler).HandleRefresh)))
+	v1.Handle("/admin/countries/blocked", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequireMethodPermission(auth.PermComplianceRead, auth.PermComplianceWrite),
+	)(withCountryHandler((*handlers.CountryHandler).HandleBlocked)))
+	v1.Handle("/admin/countries/edges", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermCountriesWrite),
+	)(withCountryHandler((*handlers.CountryHandler).HandleEdges)))
+	v1.Handle("/admin/countries/{code}", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermCountriesWrite),
+	)(withCountryHandler((*handlers.CountryHandler).HandleCountry))) // DELETE {code}
+	v1.Handle("/admin/countries/{code}/{action}", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermCountriesWrite),
+	)(withCountryHandler((*handlers.CountryHandler).HandleCountry))) // POST {code}/halt, POST {code}/resume, GET/PUT/DELETE {code}/hours
 
-	// Admin payment stats (admin only)
-	mux.Handle("/api/v1/admin/payments/stats", middleware.Chain(
+	// Admin payment stats (payments:read)
+	v1.Handle("/admin/payments/stats", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
+		authMiddleware.RequirePermission(auth.PermPaymentsRead),
 	)(http.HandlerFunc(paymentHandler.HandleAdminStats)))
+	v1.Handle("/admin/transactions/{id}/trace", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermPaymentsRead),
+	)(http.HandlerFunc(paymentHandler.HandleTransactionTrace)))
 
-	// Debug/Chaos endpoints (admin only)
-	mux.Handle("/debug/kill/", middleware.Chain(
+	// Fee schedule (fees:read/fees:write, audited)
+	feeHandler := handlers.NewFeeHandler(feeSchedules, txnStore)
+	feeHandler.SetAuditStore(auditStore)
+	v1.Handle("/admin/fees", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
-	)(http.HandlerFunc(chaosHandler.HandleKillNode)))
-	mux.Handle("/debug/revive/", middleware.Chain(
+		authMiddleware.RequireMethodPermission(auth.PermFeesRead, auth.PermFeesWrite),
+	)(http.HandlerFunc(feeHandler.HandleFees)))
+
+	// Dynamic hop fee curve (pricing:read/pricing:write, audited)
+	pricingHandler := handlers.NewPricingHandler(pricer)
+	pricingHandler.SetAuditStore(auditStore)
+	v1.Handle("/admin/pricing", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
-	)(http.HandlerFunc(chaosHandler.HandleReviveNode)))
+		authMiddleware.RequireMethodPermission(auth.PermPricingRead, auth.PermPricingWrite),
+	)(http.HandlerFunc(pricingHandler.HandlePricing)))
+
+	// Hash-chained settlement ledger (ledger:read, requires PostgreSQL)
+	if pgClient != nil {
+		ledgerAuditor := ledgeraudit.NewAuditor(pgClient, cfg.LedgerAuditConfig())
+		ledgerAuditor.SetAlertCallback(func(result *ledgeraudit.Result) {
+			wsHub.BroadcastJSON(map[string]interface{}{
+				"type": "LEDGER_INTEGRITY_ALERT",
+				"data": result,
+			})
+		})
+		go ledgerAuditor.Start(ctx)
+
+		ledgerHandler := handlers.NewLedgerHandler(pgClient, ledgerAuditor)
+		v1.Handle("/admin/ledger", middleware.Chain(
+			authMiddleware.Authenticate,
+			authMiddleware.RequirePermission(auth.PermLedgerRead),
+		)(http.HandlerFunc(ledgerHandler.HandleLedger)))
+		v1.Handle("/admin/ledger/verify", middleware.Chain(
+			authMiddleware.Authenticate,
+			authMiddleware.RequirePermission(auth.PermLedgerRead),
+		)(http.HandlerFunc(ledgerHandler.HandleVerify)))
+	}
+
+	// Debug/Chaos endpoints (chaos:execute, audited)
+	chaosAction := func(action string) func(http.Handler) http.Handler {
+		return middleware.Chain(
+			authMiddleware.Authenticate,
+			authMiddleware.RequirePermission(auth.PermChaosExecute),
+			middleware.Audit(auditStore, action),
+		)
+	}
+	mux.Handle("/debug/kill/", chaosAction("chaos.kill_node")(http.HandlerFunc(chaosHandler.HandleKillNode)))
+	mux.Handle("/debug/revive/", chaosAction("chaos.revive_node")(http.HandlerFunc(chaosHandler.HandleReviveNode)))
 	mux.Handle("/debug/killed", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
+		authMiddleware.RequirePermission(auth.PermChaosExecute),
 	)(http.HandlerFunc(chaosHandler.HandleGetKilledNodes)))
+	mux.Handle("/debug/degrade/", chaosAction("chaos.degrade_edge")(http.HandlerFunc(chaosHandler.HandleDegradeEdge)))
+	mux.Handle("/debug/degraded", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermChaosExecute),
+	)(http.HandlerFunc(chaosHandler.HandleGetDegradedEdges)))
+
+	// Demo endpoints (chaos:execute, audited)
+	mux.Handle("/demo/attack", chaosAction("chaos.attack_demo")(http.HandlerFunc(chaosDemo.HandleAttackDemo)))
+	mux.Handle("/demo/reset", chaosAction("chaos.reset_demo")(http.HandlerFunc(chaosDemo.HandleResetDemo)))
+	mux.Handle("/demo/scenario", chaosAction("chaos.scenario")(http.HandlerFunc(scenarioRunner.HandleScenario)))
 
-	// Demo endpoints (admin only)
-	mux.Handle("/demo/attack", middleware.Chain(
+	// Role permission grants (audited; ADMIN always holds every permission)
+	roleHandler := handlers.NewRoleHandler(rolePolicy)
+	roleHandler.SetAuditStore(auditStore)
+	if rolePermissions != nil {
+		roleHandler.SetPolicyStore(rolePermissions)
+	}
+	v1.Handle("/admin/roles", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
-	)(http.HandlerFunc(chaosDemo.HandleAttackDemo)))
-	mux.Handle("/demo/reset", middleware.Chain(
+		authMiddleware.RequirePermission(auth.PermRolesRead),
+	)(http.HandlerFunc(roleHandler.HandleListRoles)))
+	v1.Handle("/admin/roles/", middleware.Chain(
 		authMiddleware.Authenticate,
-		authMiddleware.RequireAdmin,
-	)(http.HandlerFunc(chaosDemo.HandleResetDemo)))
+		authMiddleware.RequirePermission(auth.PermRolesWrite),
+	)(http.HandlerFunc(roleHandler.HandleUpdateRole)))
+
+	// Audit trail of admin mutations and chaos actions
+	auditHandler := handlers.NewAuditHandler(auditStore)
+	v1.Handle("/admin/audit", middleware.Chain(
+		authMiddleware.Authenticate,
+		authMiddleware.RequirePermission(auth.PermAuditRead),
+	)(http.HandlerFunc(auditHandler.HandleListAudit)))
 
 	// Static files for frontend (now points to Next.js build output)
-	fs := http.FileServer(http.Dir("./frontend-next/out"))
+	fs := http.FileServer(http.Dir(cfg.Server.StaticDir))
 	mux.Handle("/", fs)
 
 	// Create server with CORS and security middleware
-	// Security middleware chain: InputValidation -> SecurityHeaders -> CSRFMiddleware -> corsHandler
+	// Middleware chain: RequestID -> InputValidation -> SecurityHeaders -> CSRFMiddleware -> corsHandler -> Metrics
 	securityHandler := func(h http.Handler) http.Handler {
 		return middleware.InputValidation(
 			middleware.SecurityHeaders(
@@ -278,20 +1353,46 @@ func main() {
 	}
 
 	server := &http.Server{
-		Addr:    ":8080",
-		Handler: securityHandler(corsHandler(mux)),
+		Addr:    cfg.Server.Addr,
+		Handler: middleware.RequestID(securityHandler(corsHandler(middleware.Metrics(mux)))),
 	}
 
 	// Start server in goroutine
 	go func() {
-		log.Println("📡 HTTP/WebSocket server listening on :8080")
-		log.Println("   - Dashboard:    http://localhost:8080/")
-		log.Println("   - WebSocket:    ws://localhost:8080/ws")
-		log.Println("   - Route WS:     ws://localhost:8080/ws/route")
+		log.Printf("📡 HTTP/WebSocket server listening on %s", cfg.Server.Addr)
+		host := cfg.Server.Addr
+		if strings.HasPrefix(host, ":") {
+			host = "localhost" + host
+		}
+		log.Printf("   - Dashboard:    http://%s/", host)
+		log.Printf("   - WebSocket:    ws://%s/ws", host)
+		log.Printf("   - Route WS:     ws://%s/ws/route", host)
+		log.Printf("   - GraphQL WS:   ws://%s/ws/graphql", host)
+		log.Printf("   - Admin WS:     ws://%s/ws/admin", host)
+		log.Printf("   - Metrics:      http://%s/metrics", host)
+		log.Printf("   - Probes:       http://%s/healthz, http://%s/readyz", host, host)
 		log.Println("   - Route API:    POST /api/v1/route")
+		log.Println("   - GraphQL:      GET/POST /api/graphql")
+		log.Println("   - OpenAPI:      GET /api/v1/openapi.json")
+		log.Println("   - Versions:     GET /api/versions")
 		log.Println("   - Login:        POST /api/v1/auth/login")
 		log.Println("   - Register:     POST /api/v1/auth/register")
-		log.Println("   - Countries:    GET /api/v1/admin/countries")
+		log.Println("   - Reset:        POST /api/v1/auth/forgot-password, /api/v1/auth/reset-password")
+		log.Println("   - Countries:    GET /api/v1/countries, /api/v1/countries/{code}, /api/v1/admin/countries")
+		log.Println("   - Blocked:      GET/PUT /api/v1/admin/countries/blocked")
+		log.Println("   - Roles:        GET /api/v1/admin/roles, PUT /api/v1/admin/roles/{role}")
+		log.Println("   - Orgs:         /api/v1/org, /api/v1/admin/orgs")
+		log.Println("   - Limits:       GET /api/v1/payments/limits, /api/v1/admin/limits")
+		log.Println("   - Reviews:      GET /api/v1/admin/reviews, POST /api/v1/admin/reviews/{id}/{approve|reject}")
+		log.Println("   - KYC:          GET/POST /api/v1/kyc, GET /api/v1/admin/kyc, POST /api/v1/admin/kyc/{id}/{approve|reject}")
+		log.Println("   - Liquidity:    GET /api/v1/admin/liquidity/recommendations")
+		if registryHandler != nil {
+			log.Println("   - Registry:     GET /api/v1/admin/registry/nodes")
+		}
+		if dlqHandler != nil {
+			log.Println("   - DLQ:          GET /api/v1/admin/dlq, POST /api/v1/admin/dlq/{id}/replay, DELETE /api/v1/admin/dlq/{id}")
+		}
+		log.Println("   - Disputes:     GET/POST /api/v1/payments/disputes, POST /api/v1/admin/disputes/{id}/{accept|reject}")
 		if err := server.ListenAndServe(); err != http.ErrServerClosed {
 			log.Fatalf("Server error: %v", err)
 		}
@@ -303,11 +1404,27 @@ func main() {
 	<-quit
 
 	log.Println("Shutting down server...")
-	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+
+	// Stop taking payments and let in-flight settlements finish; whatever is
+	// still running at the deadline is left interrupted and resumed on startup
+	log.Printf("⏳ Draining in-flight payments (up to %v)...", time.Duration(cfg.Server.DrainTimeout))
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.DrainTimeout))
+	if interrupted, err := txnStore.Drain(drainCtx); err != nil {
+		log.Printf("⚠️  Drain incomplete: %v (interrupted: %s)", err, strings.Join(interrupted, ", "))
+	} else {
+		log.Println("✅ In-flight payments drained")
+	}
+	drainCancel()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout))
 	defer shutdownCancel()
 
-	if neo4jDriver != nil {
-		neo4jDriver.Close(shutdownCtx)
+	if client := neo4jSupervisor.Client(); client != nil {
+		client.Close(shutdownCtx)
+	}
+
+	if settlementServer != nil {
+		settlementServer.Stop()
 	}
 
 	if err := server.Shutdown(shutdownCtx); err != nil {
@@ -317,6 +1434,77 @@ func main() {
 	log.Println("Server stopped")
 }
 
+// loadMeshGraph replaces graph with the mesh stored in Neo4j. An empty Neo4j
+// is seeded from graph instead, so the defaults are only used until then.
+func loadMeshGraph(ctx context.Context, client *neo4jstore.Client, graph *router.Graph) error {
+	nodes, edges, err := client.LoadMesh(ctx)
+	if err != nil {
+		return err
+	}
+
+	if len(nodes) == 0 {
+		export := graph.Export()
+		seedNodes := make([]neo4jstore.Node, len(export.Nodes))
+		for i, n := range export.Nodes {
+			seedNodes[i] = neo4jstore.Node{ID: n.ID, Type: n.Type, Region: n.Region, IsActive: n.IsActive, Props: n.StoredProps()}
+		}
+		seedEdges := make([]neo4jstore.Edge, len(export.Edges))
+		for i, e := range export.Edges {
+			seedEdges[i] = neo4jstore.Edge{
+				SourceID:        e.SourceID,
+				TargetID:        e.TargetID,
+				BaseFee:         e.BaseFee,
+				Latency:         e.Latency,
+				LiquidityVolume: e.LiquidityVolume,
+				IsActive:        e.IsActive,
+			}
+		}
+		if err := client.ReplaceMesh(ctx, seedNodes, seedEdges); err != nil {
+			return fmt.Errorf("seed empty mesh: %w", err)
+		}
+		log.Printf("✅ Seeded Neo4j with the default mesh (%d nodes, %d edges)", len(seedNodes), len(seedEdges))
+		return nil
+	}
+
+	export := &router.MeshExport{
+		Nodes: make([]*router.Node, 0, len(nodes)),
+		Edges: make([]*router.Edge, 0, len(edges)),
+	}
+	for _, n := range nodes {
+		export.Nodes = append(export.Nodes, &router.Node{
+			ID:       n.ID,
+			Type:     n.Type,
+			Region:   n.Region,
+			IsActive: n.IsActive,
+			Props:    n.Props,
+			Capacity: router.CapacityFromProps(n.Props),
+		})
+	}
+	seen := make(map[string]bool, len(edges))
+	for _, e := range edges {
+		// The routing graph has one edge per direction; extra relationship
+		// types between the same nodes add nothing to routing
+		key := e.SourceID + "->" + e.TargetID
+		if seen[key] || e.SourceID == e.TargetID {
+			continue
+		}
+		seen[key] = true
+		export.Edges = append(export.Edges, &router.Edge{
+			SourceID:        e.SourceID,
+			TargetID:        e.TargetID,
+			BaseFee:         e.BaseFee,
+			Latency:         e.Latency,
+			LiquidityVolume: e.LiquidityVolume,
+			IsActive:        e.IsActive,
+		})
+	}
+	if err := graph.Import(export); err != nil {
+		return err
+	}
+	log.Printf("✅ Mesh graph loaded from Neo4j (%d nodes, %d edges)", len(export.Nodes), len(export.Edges))
+	return nil
+}
+
 // initializeMeshGraph creates the sample mesh topology
 func initializeMeshGraph() *router.Graph {
 	graph := router.NewGraph()
@@ -338,34 +1526,32 @@ func initializeMeshGraph() *router.Graph {
 	graph.AddNode(&router.Node{ID: "hub_secondary", Type: "Hub", IsActive: true})
 	graph.AddNode(&router.Node{ID: "hub_backup", Type: "Hub", IsActive: true})
 
-	// Add edges - SME to LP
-	graph.AddEdge(&router.Edge{SourceID: "sme_001", TargetID: "lp_alpha", BaseFee: 0.0008, Latency: 5, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "sme_001", TargetID: "lp_beta", BaseFee: 0.0015, Latency: 45, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "sme_002", TargetID: "lp_alpha", BaseFee: 0.0005, Latency: 8, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "sme_002", TargetID: "lp_gamma", BaseFee: 0.0012, Latency: 95, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "sme_003", TargetID: "lp_beta", BaseFee: 0.0007, Latency: 10, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "sme_004", TargetID: "lp_gamma", BaseFee: 0.0010, Latency: 12, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "sme_005", TargetID: "lp_beta", BaseFee: 0.0009, Latency: 18, IsActive: true})
-
-	// Add edges - LP to Hub
-	graph.AddEdge(&router.Edge{SourceID: "lp_alpha", TargetID: "hub_primary", BaseFee: 0.0015, Latency: 12, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "lp_beta", TargetID: "hub_primary", BaseFee: 0.0018, Latency: 25, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "lp_beta", TargetID: "hub_secondary", BaseFee: 0.0012, Latency: 8, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "lp_gamma", TargetID: "hub_backup", BaseFee: 0.0010, Latency: 15, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "lp_gamma", TargetID: "hub_primary", BaseFee: 0.0022, Latency: 85, IsActive: true})
+	// Add edges - SME to LP (all mesh edges are bidirectional so settlement can flow back)
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "sme_001", TargetID: "lp_alpha", BaseFee: 0.0008, Latency: 5, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "sme_001", TargetID: "lp_beta", BaseFee: 0.0015, Latency: 45, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "sme_002", TargetID: "lp_alpha", BaseFee: 0.0005, Latency: 8, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "sme_002", TargetID: "lp_gamma", BaseFee: 0.0012, Latency: 95, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "sme_003", TargetID: "lp_beta", BaseFee: 0.0007, Latency: 10, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "sme_004", TargetID: "lp_gamma", BaseFee: 0.0010, Latency: 12, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "sme_005", TargetID: "lp_beta", BaseFee: 0.0009, Latency: 18, IsActive: true})
+
+	// Add edges - LP to Hub (liquidity in cents, reserved as settlements cross them)
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "lp_alpha", TargetID: "hub_primary", BaseFee: 0.0015, LiquidityVolume: 50_000_000, Latency: 12, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "lp_beta", TargetID: "hub_primary", BaseFee: 0.0018, LiquidityVolume: 50_000_000, Latency: 25, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "lp_beta", TargetID: "hub_secondary", BaseFee: 0.0012, LiquidityVolume: 50_000_000, Latency: 8, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "lp_gamma", TargetID: "hub_backup", BaseFee: 0.0010, LiquidityVolume: 50_000_000, Latency: 15, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "lp_gamma", TargetID: "hub_primary", BaseFee: 0.0022, LiquidityVolume: 50_000_000, Latency: 85, IsActive: true})
 
 	// Add edges - Hub interconnects
-	graph.AddEdge(&router.Edge{SourceID: "hub_primary", TargetID: "hub_secondary", BaseFee: 0.0005, Latency: 35, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "hub_secondary", TargetID: "hub_primary", BaseFee: 0.0005, Latency: 35, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "hub_primary", TargetID: "hub_backup", BaseFee: 0.0008, Latency: 75, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "hub_backup", TargetID: "hub_primary", BaseFee: 0.0008, Latency: 75, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "hub_primary", TargetID: "hub_secondary", BaseFee: 0.0005, LiquidityVolume: 50_000_000, Latency: 35, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "hub_primary", TargetID: "hub_backup", BaseFee: 0.0008, LiquidityVolume: 50_000_000, Latency: 75, IsActive: true})
 
 	// Add edges - Hub to destination SMEs
-	graph.AddEdge(&router.Edge{SourceID: "hub_primary", TargetID: "sme_003", BaseFee: 0.0006, Latency: 10, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "hub_backup", TargetID: "sme_003", BaseFee: 0.0009, Latency: 20, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "hub_backup", TargetID: "sme_004", BaseFee: 0.0008, Latency: 15, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "hub_secondary", TargetID: "sme_005", BaseFee: 0.0007, Latency: 12, IsActive: true})
-	graph.AddEdge(&router.Edge{SourceID: "hub_secondary", TargetID: "sme_003", BaseFee: 0.0008, Latency: 18, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "hub_primary", TargetID: "sme_003", BaseFee: 0.0006, Latency: 10, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "hub_backup", TargetID: "sme_003", BaseFee: 0.0009, Latency: 20, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "hub_backup", TargetID: "sme_004", BaseFee: 0.0008, Latency: 15, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "hub_secondary", TargetID: "sme_005", BaseFee: 0.0007, Latency: 12, IsActive: true})
+	graph.AddBidirectionalEdge(&router.Edge{SourceID: "hub_secondary", TargetID: "sme_003", BaseFee: 0.0008, Latency: 18, IsActive: true})
 
 	log.Println("✅ Mesh graph initialized with topology")
 	return graph
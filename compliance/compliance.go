@@ -0,0 +1,164 @@
+// Package compliance screens payments before they are created. Screeners
+// can allow a payment, hold it for manual review or deny it outright.
+package compliance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Decision is the outcome of screening a payment
+type Decision string
+
+const (
+	Allow  Decision = "allow"
+	Review Decision = "review" // Create the payment but hold it until an admin approves it
+	Deny   Decision = "deny"
+)
+
+// ErrDenied is returned for payments a screener denied
+var ErrDenied = errors.New("payment blocked by compliance screening")
+
+// severity orders decisions so the strictest one wins
+var severity = map[Decision]int{Allow: 0, Review: 1, Deny: 2}
+
+// Request describes a payment about to be created
+type Request struct {
+	UserID   string
+	Amount   float64
+	Currency string
+	Route    []string // Country codes; the first is the source and the last the destination
+}
+
+// Result is a screening decision and the reasons behind it
+type Result struct {
+	Decision Decision `json:"decision"`
+	Reasons  []string `json:"reasons,omitempty"`
+}
+
+// Err returns an error wrapping ErrDenied for denied payments, or nil
+func (r *Result) Err() error {
+	if r.Decision != Deny {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDenied, strings.Join(r.Reasons, "; "))
+}
+
+// Screener decides whether a payment may be created. Implementations may
+// call out to external sanctions services.
+type Screener interface {
+	Screen(ctx context.Context, req *Request) (*Result, error)
+}
+
+// Chain runs every screener and returns the strictest decision with the
+// reasons of every screener that reached it
+type Chain []Screener
+
+// Screen runs the chain
+func (c Chain) Screen(ctx context.Context, req *Request) (*Result, error) {
+	combined := &Result{Decision: Allow}
+	for _, screener := range c {
+		result, err := screener.Screen(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case severity[result.Decision] > severity[combined.Decision]:
+			combined = &Result{Decision: result.Decision, Reasons: append([]string(nil), result.Reasons...)}
+		case result.Decision == combined.Decision && result.Decision != Allow:
+			combined.Reasons = append(combined.Reasons, result.Reasons...)
+		}
+	}
+	return combined, nil
+}
+
+// Config configures the built-in rule screener
+type Config struct {
+	DeniedCountries []string // Payments from or to these country codes are denied
+	ReviewThreshold float64  // Payments of at least this amount are held for review (0 = never)
+}
+
+// RuleScreener denies payments from or to listed countries and holds large
+// payments for review
+type RuleScreener struct {
+	denied    map[string]bool
+	threshold float64
+}
+
+// NewRuleScreener creates a rule screener from cfg
+func NewRuleScreener(cfg Config) *RuleScreener {
+	denied := make(map[string]bool, len(cfg.DeniedCountries))
+	for _, code := range cfg.DeniedCountries {
+		denied[strings.ToUpper(strings.TrimSpace(code))] = true
+	}
+	return &RuleScreener{denied: denied, threshold: cfg.ReviewThreshold}
+}
+
+// Screen checks the payment's source and destination against the deny list
+// and its amount against the review threshold
+func (s *RuleScreener) Screen(ctx context.Context, req *Request) (*Result, error) {
+	if len(req.Route) > 0 {
+		var reasons []string
+		source, destination := req.Route[0], req.Route[len(req.Route)-1]
+		if s.denied[strings.ToUpper(source)] {
+			reasons = append(reasons, "source country "+source+" is on the deny list")
+		}
+		if s.denied[strings.ToUpper(destination)] && destination != source {
+			reasons = append(reasons, "destination country "+destination+" is on the deny list")
+		}
+		if len(reasons) > 0 {
+			return &Result{Decision: Deny, Reasons: reasons}, nil
+		}
+	}
+	if s.threshold > 0 && req.Amount >= s.threshold {
+		return &Result{Decision: Review, Reasons: []string{
+			fmt.Sprintf("amount %.2f %s is at or above the review threshold of %.2f", req.Amount, req.Currency, s.threshold),
+		}}, nil
+	}
+	return &Result{Decision: Allow}, nil
+}
+
+// KYCSource reports whether a user's identity has been verified.
+// Implemented by the user stores in storage/users.
+type KYCSource interface {
+	KYCVerified(userID string) (bool, error)
+}
+
+// KYCScreener denies payments above a threshold from users whose identity
+// has not been verified
+type KYCScreener struct {
+	users     KYCSource
+	threshold float64
+}
+
+// NewKYCScreener creates a screener requiring verified identity for payments
+// above threshold (0 = no requirement)
+func NewKYCScreener(users KYCSource, threshold float64) *KYCScreener {
+	return &KYCScreener{users: users, threshold: threshold}
+}
+
+// Screen checks the payer's KYC status when the amount is above the threshold
+func (s *KYCScreener) Screen(ctx context.Context, req *Request) (*Result, error) {
+	if s.threshold <= 0 || req.Amount <= s.threshold {
+		return &Result{Decision: Allow}, nil
+	}
+	verified, err := s.users.KYCVerified(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check KYC status: %w", err)
+	}
+	if verified {
+		return &Result{Decision: Allow}, nil
+	}
+	return &Result{Decision: Deny, Reasons: []string{
+		fmt.Sprintf("identity verification is required for payments above %.2f", s.threshold),
+	}}, nil
+}
+
+// Compile-time interface checks
+var (
+	_ Screener = Chain(nil)
+	_ Screener = (*RuleScreener)(nil)
+	_ Screener = (*KYCScreener)(nil)
+)
@@ -0,0 +1,94 @@
+package compliance
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRuleScreener(t *testing.T) {
+	ctx := context.Background()
+	screener := NewRuleScreener(Config{DeniedCountries: []string{"prk", " IRN"}, ReviewThreshold: 10000})
+
+	tests := []struct {
+		name  string
+		req   Request
+		want  Decision
+		count int
+	}{
+		{"allowed", Request{Amount: 500, Route: []string{"USA", "PRK", "GBR"}}, Allow, 0},
+		{"denied source", Request{Amount: 500, Route: []string{"PRK", "CHN"}}, Deny, 1},
+		{"denied both ends", Request{Amount: 500, Route: []string{"IRN", "USA", "PRK"}}, Deny, 2},
+		{"deny beats review", Request{Amount: 50000, Route: []string{"USA", "IRN"}}, Deny, 1},
+		{"review threshold", Request{Amount: 10000, Currency: "USD", Route: []string{"USA", "GBR"}}, Review, 1},
+	}
+	for _, tt := range tests {
+		result, err := screener.Screen(ctx, &tt.req)
+		if err != nil {
+			t.Fatalf("%s: Screen: %v", tt.name, err)
+		}
+		if result.Decision != tt.want || len(result.Reasons) != tt.count {
+			t.Errorf("%s: result = %+v, want %s with %d reasons", tt.name, result, tt.want, tt.count)
+		}
+	}
+}
+
+type fixedScreener Result
+
+func (f fixedScreener) Screen(ctx context.Context, req *Request) (*Result, error) {
+	r := Result(f)
+	return &r, nil
+}
+
+func TestChainReturnsStrictestDecision(t *testing.T) {
+	chain := Chain{
+		fixedScreener{Decision: Review, Reasons: []string{"large amount"}},
+		fixedScreener{Decision: Allow},
+		fixedScreener{Decision: Review, Reasons: []string{"new account"}},
+	}
+	result, _ := chain.Screen(context.Background(), &Request{})
+	if result.Decision != Review || len(result.Reasons) != 2 || result.Err() != nil {
+		t.Errorf("result = %+v, want review with both reasons", result)
+	}
+
+	chain = append(chain, fixedScreener{Decision: Deny, Reasons: []string{"sanctioned"}})
+	result, _ = chain.Screen(context.Background(), &Request{})
+	if result.Decision != Deny || len(result.Reasons) != 1 || !errors.Is(result.Err(), ErrDenied) {
+		t.Errorf("result = %+v, want deny for the sanction only", result)
+	}
+}
+
+type kycStatuses map[string]bool
+
+func (k kycStatuses) KYCVerified(userID string) (bool, error) {
+	return k[userID], nil
+}
+
+func TestKYCScreenerRequiresVerificationAboveThreshold(t *testing.T) {
+	ctx := context.Background()
+	screener := NewKYCScreener(kycStatuses{"verified": true}, 1000)
+
+	tests := []struct {
+		user   string
+		amount float64
+		want   Decision
+	}{
+		{"unverified", 1000, Allow},
+		{"unverified", 1000.01, Deny},
+		{"verified", 50000, Allow},
+	}
+	for _, tt := range tests {
+		result, err := screener.Screen(ctx, &Request{UserID: tt.user, Amount: tt.amount})
+		if err != nil {
+			t.Fatalf("Screen: %v", err)
+		}
+		if result.Decision != tt.want {
+			t.Errorf("%s paying %.2f: decision = %s, want %s", tt.user, tt.amount, result.Decision, tt.want)
+		}
+	}
+
+	result, _ := NewKYCScreener(kycStatuses{}, 0).Screen(ctx, &Request{UserID: "unverified", Amount: 1e9})
+	if result.Decision != Allow {
+		t.Errorf("with no threshold: decision = %s, want allow", result.Decision)
+	}
+}
@@ -0,0 +1,1169 @@
+// Package config loads server configuration from an optional JSON file
+// with environment variable overrides.
+//
+// Precedence (lowest to highest): built-in defaults, the config file
+// (-config flag or CONFIG_FILE), then environment variables. Secrets are
+// best left to the environment (.env) rather than the file.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/compliance"
+	plmgrpc "github.com/plm/predictive-liquidity-mesh/engine/grpc"
+	"github.com/plm/predictive-liquidity-mesh/engine/liveness"
+	"github.com/plm/predictive-liquidity-mesh/engine/registry"
+	"github.com/plm/predictive-liquidity-mesh/limits"
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+	"github.com/plm/predictive-liquidity-mesh/notifications"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/payments/netting"
+	"github.com/plm/predictive-liquidity-mesh/payments/scheduler"
+	"github.com/plm/predictive-liquidity-mesh/pkg/boot"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+	neo4jstore "github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/storage/postgres"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+	"github.com/plm/predictive-liquidity-mesh/websocket"
+	"github.com/plm/predictive-liquidity-mesh/workers/adminstats"
+	"github.com/plm/predictive-liquidity-mesh/workers/entropyfeed"
+	"github.com/plm/predictive-liquidity-mesh/workers/fxrates"
+	"github.com/plm/predictive-liquidity-mesh/workers/ledgeraudit"
+	"github.com/plm/predictive-liquidity-mesh/workers/recovery"
+)
+
+// Duration is a time.Duration that reads from JSON strings like "30s" or "1h"
+type Duration time.Duration
+
+// UnmarshalJSON parses a Go duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalJSON formats the duration as a Go duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Config is the complete server configuration
+type Config struct {
+	Server     ServerConfig     `json:"server"`
+	WebSocket  WebSocketConfig  `json:"websocket"`
+	Routing    RoutingConfig    `json:"routing"`
+	Storage    StorageConfig    `json:"storage"`
+	Neo4j      Neo4jConfig      `json:"neo4j"`
+	Postgres   PostgresConfig   `json:"postgres"`
+	Redis      RedisConfig      `json:"redis"`
+	NATS       NATSConfig       `json:"nats"`
+	Fees       FeeConfig        `json:"fees"`
+	Pricing    PricingConfig    `json:"pricing"`
+	Quotes     QuoteConfig      `json:"quotes"`
+	Limits     LimitsConfig     `json:"limits"`
+	Compliance ComplianceConfig `json:"compliance"`
+	Stripe     StripeConfig     `json:"stripe"`
+	Providers  ProvidersConfig  `json:"payment_providers"`
+	Refunds    RefundsConfig    `json:"refunds"`
+	Notify     NotifyConfig     `json:"notifications"`
+	FX         FXConfig         `json:"fx"`
+	Scheduler  SchedulerConfig  `json:"scheduler"`
+	Netting    NettingConfig    `json:"netting"`
+	Ledger     LedgerConfig     `json:"ledger"`
+	Recovery   RecoveryConfig   `json:"recovery"`
+	Simulation SimulationConfig `json:"simulation"`
+	GRPC       GRPCConfig       `json:"grpc"`
+	Registry   RegistryConfig   `json:"registry"`
+	Liveness   LivenessConfig   `json:"liveness"`
+	Health     HealthConfig     `json:"health"`
+	Startup    StartupConfig    `json:"startup"`
+	Log        LogConfig        `json:"log"`
+}
+
+// ServerConfig holds HTTP server settings
+type ServerConfig struct {
+	Addr            string   `json:"addr"`
+	StaticDir       string   `json:"static_dir"`
+	CORSOrigins     []string `json:"cors_origins"` // "*" allows any origin
+	ShutdownTimeout Duration `json:"shutdown_timeout"`
+	DrainTimeout    Duration `json:"drain_timeout"` // How long shutdown waits for in-flight payments before interrupting them
+}
+
+// WebSocketConfig holds WebSocket hub buffering settings
+type WebSocketConfig struct {
+	BroadcastBuffer int `json:"broadcast_buffer"` // Messages queued for fan-out
+	SendBuffer      int `json:"send_buffer"`      // Messages queued per client
+	MaxDropped      int `json:"max_dropped"`      // Consecutive drops before a slow client is disconnected (0 = never)
+
+	AdminStatsInterval Duration `json:"admin_stats_interval"` // How often stats are pushed to admin dashboards
+}
+
+// RoutingConfig holds mesh routing settings
+type RoutingConfig struct {
+	K             int      `json:"k"`             // Number of alternative paths
+	LoadPenalty   float64  `json:"load_penalty"`  // Weight added per in-flight settlement
+	GraphRefresh  Duration `json:"graph_refresh"` // How often the country graph is reloaded from Neo4j
+	Bidirectional bool     `json:"bidirectional"` // Use bidirectional Dijkstra for mesh routes
+	CacheSize     int      `json:"cache_size"`    // Cached country route results (0 disables the cache)
+	CacheTTL      Duration `json:"cache_ttl"`     // How long a cached route stays valid
+
+	EntropyInterval Duration `json:"entropy_interval"` // How often mesh node entropy is recomputed from settlements
+	EntropyWindow   Duration `json:"entropy_window"`   // How far back settlements count toward entropy
+}
+
+// StorageConfig selects the transaction and user store backends
+type StorageConfig struct {
+	TransactionStore string `json:"transaction_store"` // memory | postgres
+	UserStore        string `json:"user_store"`        // memory | postgres
+}
+
+// Neo4jConfig holds Neo4j connection settings
+type Neo4jConfig struct {
+	URI      string `json:"uri"`
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Database string `json:"database"`
+
+	CredibilityFlushInterval Duration `json:"credibility_flush_interval"` // How often batched credibility changes are written
+	CredibilityHalfLife      Duration `json:"credibility_half_life"`      // Time for credibility to move halfway back to baseline; 0 = no decay
+}
+
+// PostgresConfig holds PostgreSQL connection settings
+type PostgresConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	User     string `json:"user"`
+	Password string `json:"password"`
+	Database string `json:"database"`
+	SSLMode  string `json:"ssl_mode"`
+}
+
+// RedisConfig holds the Redis connection URL (empty = Redis disabled)
+type RedisConfig struct {
+	URL string `json:"url"`
+}
+
+// NATSConfig holds the NATS connection settings (empty URL = NATS disabled).
+// When enabled, settlement lifecycle events are published to JetStream.
+type NATSConfig struct {
+	URL string `json:"url"` // Comma-separated for a cluster
+
+	// Authentication: at most one of token, user/password, NKey seed file
+	// and credentials (JWT) file
+	Token     string `json:"token"`
+	User      string `json:"user"`
+	Password  string `json:"password"`
+	NKeyFile  string `json:"nkey_file"`
+	CredsFile string `json:"creds_file"`
+
+	// TLS: the CA verifies the server; the cert and key authenticate this client
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+	CAFile   string `json:"ca_file"`
+
+	// Name and labels identify this server's connection in NATS monitoring
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels"`
+}
+
+// FeeConfig holds transaction fee rates as fractions (0.015 = 1.5%)
+type FeeConfig struct {
+	BaseFeePercent  float64 `json:"base_fee_percent"`
+	HopFeePercent   float64 `json:"hop_fee_percent"`
+	HaltFinePercent float64 `json:"halt_fine_percent"`
+}
+
+// PricingConfig holds the dynamic hop fee curve (see payments.PricingCurve)
+type PricingConfig struct {
+	Enabled           bool    `json:"enabled"`
+	Base              float64 `json:"base"`
+	CredibilityWeight float64 `json:"credibility_weight"`
+	SuccessRateWeight float64 `json:"success_rate_weight"`
+	Exponent          float64 `json:"exponent"`
+	MinMultiplier     float64 `json:"min_multiplier"`
+	MaxMultiplier     float64 `json:"max_multiplier"`
+}
+
+// QuoteConfig holds payment quote settings
+type QuoteConfig struct {
+	TTL        Duration `json:"ttl"`         // How long a quoted exchange rate is honored
+	SigningKey string   `json:"signing_key"` // HMAC key for quote IDs (empty = random per process)
+}
+
+// LimitsConfig holds the spending limits for users without their own rule
+// until an admin stores a default (0 = unlimited)
+type LimitsConfig struct {
+	PerTransaction float64 `json:"per_transaction"`
+	Daily          float64 `json:"daily"`
+	Monthly        float64 `json:"monthly"`
+}
+
+// ComplianceConfig holds the payment screening rules
+type ComplianceConfig struct {
+	DeniedCountries []string `json:"denied_countries"` // Payments from or to these country codes are refused
+	ReviewThreshold float64  `json:"review_threshold"` // Payments of at least this amount are held for review (0 = never)
+	KYCThreshold    float64  `json:"kyc_threshold"`    // Payments above this amount require a verified identity (0 = never)
+}
+
+// StripeConfig holds Stripe API keys (empty secret key = mock mode)
+type StripeConfig struct {
+	SecretKey      string `json:"secret_key"`
+	PublishableKey string `json:"publishable_key"`
+	WebhookSecret  string `json:"webhook_secret"`
+	ReturnURL      string `json:"return_url"` // Where payers land after 3-D Secure (default: notifications.app_url + /pay)
+}
+
+// ProvidersConfig holds which providers collect payments besides Stripe
+type ProvidersConfig struct {
+	BankTransfer bool              `json:"bank_transfer"` // Offer simulated bank transfers (Stripe mock mode only)
+	Currencies   map[string]string `json:"currencies"`    // Provider per payment currency, e.g. {"EUR": "bank_transfer"}; others use stripe
+}
+
+// RefundsConfig holds the refund policy
+type RefundsConfig struct {
+	RetainBaseFeeOnCancel bool `json:"retain_base_fee_on_cancel"` // Keep the platform fee when a payer cancels a queued payment
+}
+
+// NotifyConfig holds email notification settings
+type NotifyConfig struct {
+	Provider     string `json:"provider"` // log | smtp | none
+	From         string `json:"from"`
+	AppURL       string `json:"app_url"` // Frontend base URL for links in emails
+	SMTPHost     string `json:"smtp_host"`
+	SMTPPort     int    `json:"smtp_port"`
+	SMTPUsername string `json:"smtp_username"`
+	SMTPPassword string `json:"smtp_password"`
+}
+
+// FXConfig holds FX rate worker settings
+type FXConfig struct {
+	APIKey           string   `json:"api_key"`
+	Interval         Duration `json:"interval"`
+	Providers        []string `json:"providers"`          // Failover order: exchangerate-api, ecb, static
+	RatesFile        string   `json:"rates_file"`         // JSON rates for the static provider (empty = bundled rates)
+	RateLimitBackoff Duration `json:"rate_limit_backoff"` // How long a rate-limited provider is skipped
+}
+
+// SchedulerConfig holds scheduled payment worker settings
+type SchedulerConfig struct {
+	Interval Duration `json:"interval"` // How often due schedules are checked
+}
+
+// NettingConfig holds settlement netting settings
+type NettingConfig struct {
+	Enabled bool     `json:"enabled"` // Queue confirmed payments and settle net positions per corridor
+	Window  Duration `json:"window"`  // How long payments wait to be netted
+}
+
+// LedgerConfig holds settlement ledger audit settings
+type LedgerConfig struct {
+	AuditInterval Duration `json:"audit_interval"` // How often the hash chain is verified (0 = on demand only)
+}
+
+// RecoveryConfig holds stuck payment reconciler settings
+type RecoveryConfig struct {
+	Interval    Duration `json:"interval"`     // How often stuck payments are looked for (0 = disabled)
+	StuckAfter  Duration `json:"stuck_after"`  // How long a pending or processing payment may go without progress
+	MaxAttempts int      `json:"max_attempts"` // Attempts after which a paid payment is refunded (or escalated) instead of resumed
+}
+
+// SimulationConfig holds simulated hop latency and failure settings
+type SimulationConfig struct {
+	Seed        int    `json:"seed"`         // Random seed; the same seed and profile replay a scenario (0 = random)
+	ProfileFile string `json:"profile_file"` // JSON failure profile (empty = default latencies and failure chances)
+}
+
+// GRPCConfig holds settlement gRPC server settings
+type GRPCConfig struct {
+	Enabled  bool   `json:"enabled"`
+	Address  string `json:"address"`
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+	CAFile   string `json:"ca_file"`
+
+	// Peers maps node IDs served by other instances to their gRPC address;
+	// settlement hops from those nodes are forwarded there (using the
+	// certificate above as the client certificate)
+	Peers map[string]string `json:"peers"`
+}
+
+// RegistryConfig holds node registry settings for multi-instance deployments
+type RegistryConfig struct {
+	Enabled  bool     `json:"enabled"`
+	Instance string   `json:"instance"` // This instance's ID (defaults to the hostname)
+	Address  string   `json:"address"`  // gRPC address advertised to peers (defaults to grpc.address)
+	Nodes    []string `json:"nodes"`    // Node IDs served by this instance (empty = every node)
+	TTL      Duration `json:"ttl"`      // How long a registration lives unless refreshed
+	Interval Duration `json:"interval"` // How often registrations are refreshed and synced
+}
+
+// LivenessConfig holds node heartbeat settings
+type LivenessConfig struct {
+	HeartbeatInterval Duration `json:"heartbeat_interval"` // How often nodes heartbeat to peer instances
+	MissedHeartbeats  int      `json:"missed_heartbeats"`  // Heartbeats missed before a node is deactivated
+}
+
+// HealthConfig holds readiness check settings. Configured dependencies are
+// required for startup and readiness unless listed as optional.
+type HealthConfig struct {
+	Optional     []string `json:"optional"`      // neo4j, postgres, redis or nats; the server runs without them
+	CheckTimeout Duration `json:"check_timeout"` // Bounds each dependency check
+}
+
+// StartupConfig holds how long startup waits for dependencies
+type StartupConfig struct {
+	Timeout        Duration `json:"timeout"`         // Startup fails if a required dependency isn't up by then
+	InitialBackoff Duration `json:"initial_backoff"` // Doubles per failed attempt
+	MaxBackoff     Duration `json:"max_backoff"`
+}
+
+// LogConfig holds structured logging settings
+type LogConfig struct {
+	Format string `json:"format"` // text or json
+	Level  string `json:"level"`  // debug, info, warn or error
+}
+
+// Default returns the built-in defaults (matching the per-package defaults)
+func Default() *Config {
+	neo4jDefaults := neo4jstore.DefaultConfig()
+	pgDefaults := postgres.DefaultConfig()
+	fees := payments.DefaultFeeConfig()
+	pricing := payments.DefaultPricingCurve()
+	fxDefaults := fxrates.DefaultConfig()
+	entropyDefaults := entropyfeed.DefaultConfig()
+	hub := websocket.DefaultHubConfig()
+	notify := notifications.DefaultConfig()
+
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			StaticDir:       "./frontend-next/out",
+			CORSOrigins:     []string{"*"},
+			ShutdownTimeout: Duration(5 * time.Second),
+			DrainTimeout:    Duration(20 * time.Second),
+		},
+		WebSocket: WebSocketConfig{
+			BroadcastBuffer: hub.BroadcastBuffer,
+			SendBuffer:      hub.SendBuffer,
+			MaxDropped:      hub.MaxDropped,
+
+			AdminStatsInterval: Duration(adminstats.DefaultConfig().Interval),
+		},
+		Routing: RoutingConfig{
+			K:            3,
+			LoadPenalty:  0.0005, // +0.05% per in-flight settlement
+			GraphRefresh: Duration(5 * time.Minute),
+			CacheSize:    1024,
+			CacheTTL:     Duration(30 * time.Second),
+
+			EntropyInterval: Duration(entropyDefaults.Interval),
+			EntropyWindow:   Duration(entropyDefaults.Window),
+		},
+		Storage: StorageConfig{
+			TransactionStore: "memory",
+			UserStore:        "memory",
+		},
+		Neo4j: Neo4jConfig{
+			URI:      neo4jDefaults.URI,
+			Username: neo4jDefaults.Username,
+			Password: neo4jDefaults.Password,
+			Database: neo4jDefaults.Database,
+
+			CredibilityFlushInterval: Duration(neo4jstore.DefaultCredibilityBatchConfig().FlushInterval),
+			CredibilityHalfLife:      Duration(neo4jstore.DefaultCredibilityBatchConfig().HalfLife),
+		},
+		Postgres: PostgresConfig{
+			Host:     pgDefaults.Host,
+			Port:     pgDefaults.Port,
+			User:     pgDefaults.User,
+			Password: pgDefaults.Password,
+			Database: pgDefaults.Database,
+			SSLMode:  pgDefaults.SSLMode,
+		},
+		Fees: FeeConfig{
+			BaseFeePercent:  fees.BaseFeePercent,
+			HopFeePercent:   fees.HopFeePercent,
+			HaltFinePercent: fees.HaltFinePercent,
+		},
+		Pricing: PricingConfig{
+			Enabled:           pricing.Enabled,
+			Base:              pricing.Base,
+			CredibilityWeight: pricing.CredibilityWeight,
+			SuccessRateWeight: pricing.SuccessRateWeight,
+			Exponent:          pricing.Exponent,
+			MinMultiplier:     pricing.MinMultiplier,
+			MaxMultiplier:     pricing.MaxMultiplier,
+		},
+		Quotes: QuoteConfig{
+			TTL: Duration(payments.DefaultQuoteTTL),
+		},
+		Notify: NotifyConfig{
+			Provider: notify.Provider,
+			From:     notify.From,
+			AppURL:   notify.AppURL,
+			SMTPPort: notify.SMTPPort,
+		},
+		FX: FXConfig{
+			Interval:         Duration(time.Hour),
+			Providers:        fxDefaults.Providers,
+			RateLimitBackoff: Duration(fxDefaults.RateLimitBackoff),
+		},
+		Scheduler: SchedulerConfig{
+			Interval: Duration(scheduler.DefaultConfig().Interval),
+		},
+		Refunds: RefundsConfig{
+			RetainBaseFeeOnCancel: payments.DefaultRefundPolicy().RetainBaseFeeOnCancel,
+		},
+		Netting: NettingConfig{
+			Window: Duration(netting.DefaultConfig().Window),
+		},
+		Ledger: LedgerConfig{
+			AuditInterval: Duration(ledgeraudit.DefaultConfig().Interval),
+		},
+		Recovery: RecoveryConfig{
+			Interval:    Duration(recovery.DefaultConfig().Interval),
+			StuckAfter:  Duration(recovery.DefaultConfig().StuckAfter),
+			MaxAttempts: recovery.DefaultConfig().MaxAttempts,
+		},
+		GRPC: GRPCConfig{
+			Address: plmgrpc.DefaultServerConfig().Address,
+		},
+		Registry: RegistryConfig{
+			TTL:      Duration(registry.DefaultConfig().TTL),
+			Interval: Duration(registry.DefaultConfig().Interval),
+		},
+		Liveness: LivenessConfig{
+			HeartbeatInterval: Duration(liveness.DefaultConfig().SweepInterval),
+			MissedHeartbeats:  int(liveness.DefaultConfig().Timeout / liveness.DefaultConfig().SweepInterval),
+		},
+		Health: HealthConfig{
+			CheckTimeout: Duration(2 * time.Second),
+		},
+		Startup: StartupConfig{
+			Timeout:        Duration(boot.DefaultConfig().Timeout),
+			InitialBackoff: Duration(boot.DefaultConfig().InitialBackoff),
+			MaxBackoff:     Duration(boot.DefaultConfig().MaxBackoff),
+		},
+		Log: LogConfig{
+			Format: "text",
+			Level:  "info",
+		},
+	}
+}
+
+// Load builds the configuration from defaults, the JSON file at path (if any)
+// and environment overrides, then validates it
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides settings from environment variables
+func (c *Config) applyEnv() error {
+	str := func(key string, dst *string) {
+		if v := os.Getenv(key); v != "" {
+			*dst = v
+		}
+	}
+	var err error
+	num := func(key string, dst *float64) {
+		if v := os.Getenv(key); v != "" && err == nil {
+			if *dst, err = strconv.ParseFloat(v, 64); err != nil {
+				err = fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+	}
+	integer := func(key string, dst *int) {
+		if v := os.Getenv(key); v != "" && err == nil {
+			if *dst, err = strconv.Atoi(v); err != nil {
+				err = fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+	}
+	duration := func(key string, dst *Duration) {
+		if v := os.Getenv(key); v != "" && err == nil {
+			d, parseErr := time.ParseDuration(v)
+			if parseErr != nil {
+				err = fmt.Errorf("invalid %s: %w", key, parseErr)
+				return
+			}
+			*dst = Duration(d)
+		}
+	}
+	boolean := func(key string, dst *bool) {
+		if v := os.Getenv(key); v != "" && err == nil {
+			if *dst, err = strconv.ParseBool(v); err != nil {
+				err = fmt.Errorf("invalid %s: %w", key, err)
+			}
+		}
+	}
+
+	str("SERVER_ADDR", &c.Server.Addr)
+	str("STATIC_DIR", &c.Server.StaticDir)
+	if v := os.Getenv("CORS_ORIGINS"); v != "" {
+		c.Server.CORSOrigins = splitList(v)
+	}
+	duration("SHUTDOWN_TIMEOUT", &c.Server.ShutdownTimeout)
+	duration("DRAIN_TIMEOUT", &c.Server.DrainTimeout)
+
+	integer("WS_BROADCAST_BUFFER", &c.WebSocket.BroadcastBuffer)
+	integer("WS_SEND_BUFFER", &c.WebSocket.SendBuffer)
+	integer("WS_MAX_DROPPED", &c.WebSocket.MaxDropped)
+	duration("WS_ADMIN_STATS_INTERVAL", &c.WebSocket.AdminStatsInterval)
+
+	integer("ROUTING_K", &c.Routing.K)
+	num("ROUTING_LOAD_PENALTY", &c.Routing.LoadPenalty)
+	duration("ROUTING_GRAPH_REFRESH", &c.Routing.GraphRefresh)
+	boolean("ROUTING_BIDIRECTIONAL", &c.Routing.Bidirectional)
+	integer("ROUTING_CACHE_SIZE", &c.Routing.CacheSize)
+	duration("ROUTING_CACHE_TTL", &c.Routing.CacheTTL)
+	duration("ROUTING_ENTROPY_INTERVAL", &c.Routing.EntropyInterval)
+	duration("ROUTING_ENTROPY_WINDOW", &c.Routing.EntropyWindow)
+
+	str("TRANSACTION_STORE", &c.Storage.TransactionStore)
+	str("USER_STORE", &c.Storage.UserStore)
+
+	str("NEO4J_URI", &c.Neo4j.URI)
+	str("NEO4J_USER", &c.Neo4j.Username)
+	str("NEO4J_PASSWORD", &c.Neo4j.Password)
+	str("NEO4J_DATABASE", &c.Neo4j.Database)
+	duration("NEO4J_CREDIBILITY_FLUSH_INTERVAL", &c.Neo4j.CredibilityFlushInterval)
+	duration("NEO4J_CREDIBILITY_HALF_LIFE", &c.Neo4j.CredibilityHalfLife)
+
+	str("POSTGRES_HOST", &c.Postgres.Host)
+	integer("POSTGRES_PORT", &c.Postgres.Port)
+	str("POSTGRES_USER", &c.Postgres.User)
+	str("POSTGRES_PASSWORD", &c.Postgres.Password)
+	str("POSTGRES_DB", &c.Postgres.Database)
+	str("POSTGRES_SSLMODE", &c.Postgres.SSLMode)
+
+	str("REDIS_URL", &c.Redis.URL)
+
+	str("NATS_URL", &c.NATS.URL)
+	str("NATS_TOKEN", &c.NATS.Token)
+	str("NATS_USER", &c.NATS.User)
+	str("NATS_PASSWORD", &c.NATS.Password)
+	str("NATS_NKEY_FILE", &c.NATS.NKeyFile)
+	str("NATS_CREDS_FILE", &c.NATS.CredsFile)
+	str("NATS_CERT_FILE", &c.NATS.CertFile)
+	str("NATS_KEY_FILE", &c.NATS.KeyFile)
+	str("NATS_CA_FILE", &c.NATS.CAFile)
+	str("NATS_NAME", &c.NATS.Name)
+	if v := os.Getenv("NATS_LABELS"); v != "" {
+		c.NATS.Labels = make(map[string]string)
+		for _, pair := range splitList(v) {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok {
+				err = fmt.Errorf("invalid NATS_LABELS: %q is not key=value", pair)
+				continue
+			}
+			c.NATS.Labels[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+
+	num("FEE_BASE_PERCENT", &c.Fees.BaseFeePercent)
+	num("FEE_HOP_PERCENT", &c.Fees.HopFeePercent)
+	num("FEE_HALT_FINE_PERCENT", &c.Fees.HaltFinePercent)
+
+	boolean("PRICING_ENABLED", &c.Pricing.Enabled)
+	num("PRICING_BASE", &c.Pricing.Base)
+	num("PRICING_CREDIBILITY_WEIGHT", &c.Pricing.CredibilityWeight)
+	num("PRICING_SUCCESS_RATE_WEIGHT", &c.Pricing.SuccessRateWeight)
+	num("PRICING_EXPONENT", &c.Pricing.Exponent)
+	num("PRICING_MIN_MULTIPLIER", &c.Pricing.MinMultiplier)
+	num("PRICING_MAX_MULTIPLIER", &c.Pricing.MaxMultiplier)
+
+	duration("QUOTE_TTL", &c.Quotes.TTL)
+	str("QUOTE_SIGNING_KEY", &c.Quotes.SigningKey)
+
+	num("LIMIT_PER_TRANSACTION", &c.Limits.PerTransaction)
+	num("LIMIT_DAILY", &c.Limits.Daily)
+	num("LIMIT_MONTHLY", &c.Limits.Monthly)
+
+	if v := os.Getenv("COMPLIANCE_DENIED_COUNTRIES"); v != "" {
+		c.Compliance.DeniedCountries = splitList(v)
+	}
+	num("COMPLIANCE_REVIEW_THRESHOLD", &c.Compliance.ReviewThreshold)
+	num("COMPLIANCE_KYC_THRESHOLD", &c.Compliance.KYCThreshold)
+
+	str("STRIPE_SECRET_KEY", &c.Stripe.SecretKey)
+	str("STRIPE_PUBLISHABLE_KEY", &c.Stripe.PublishableKey)
+	str("STRIPE_WEBHOOK_SECRET", &c.Stripe.WebhookSecret)
+	str("STRIPE_RETURN_URL", &c.Stripe.ReturnURL)
+	boolean("PAYMENT_BANK_TRANSFER", &c.Providers.BankTransfer)
+	boolean("REFUND_RETAIN_BASE_FEE_ON_CANCEL", &c.Refunds.RetainBaseFeeOnCancel)
+	if v := os.Getenv("PAYMENT_CURRENCY_PROVIDERS"); v != "" {
+		c.Providers.Currencies = make(map[string]string)
+		for _, pair := range splitList(v) {
+			currency, provider, ok := strings.Cut(pair, "=")
+			if !ok {
+				err = fmt.Errorf("invalid PAYMENT_CURRENCY_PROVIDERS: %q is not currency=provider", pair)
+				continue
+			}
+			c.Providers.Currencies[strings.TrimSpace(currency)] = strings.TrimSpace(provider)
+		}
+	}
+
+	str("NOTIFY_PROVIDER", &c.Notify.Provider)
+	str("NOTIFY_FROM", &c.Notify.From)
+	str("APP_URL", &c.Notify.AppURL)
+	str("SMTP_HOST", &c.Notify.SMTPHost)
+	integer("SMTP_PORT", &c.Notify.SMTPPort)
+	str("SMTP_USERNAME", &c.Notify.SMTPUsername)
+	str("SMTP_PASSWORD", &c.Notify.SMTPPassword)
+
+	str("EXCHANGE_RATE_API_KEY", &c.FX.APIKey)
+	duration("FX_INTERVAL", &c.FX.Interval)
+	if v := os.Getenv("FX_PROVIDERS"); v != "" {
+		c.FX.Providers = splitList(v)
+	}
+	str("FX_RATES_FILE", &c.FX.RatesFile)
+	duration("FX_RATE_LIMIT_BACKOFF", &c.FX.RateLimitBackoff)
+	duration("SCHEDULER_INTERVAL", &c.Scheduler.Interval)
+	boolean("NETTING_ENABLED", &c.Netting.Enabled)
+	duration("NETTING_WINDOW", &c.Netting.Window)
+	duration("LEDGER_AUDIT_INTERVAL", &c.Ledger.AuditInterval)
+	duration("RECOVERY_INTERVAL", &c.Recovery.Interval)
+	duration("RECOVERY_STUCK_AFTER", &c.Recovery.StuckAfter)
+	integer("RECOVERY_MAX_ATTEMPTS", &c.Recovery.MaxAttempts)
+	integer("SIMULATION_SEED", &c.Simulation.Seed)
+	str("SIMULATION_PROFILE", &c.Simulation.ProfileFile)
+
+	boolean("GRPC_ENABLED", &c.GRPC.Enabled)
+	str("GRPC_ADDRESS", &c.GRPC.Address)
+	str("GRPC_CERT_FILE", &c.GRPC.CertFile)
+	str("GRPC_KEY_FILE", &c.GRPC.KeyFile)
+	str("GRPC_CA_FILE", &c.GRPC.CAFile)
+	if v := os.Getenv("GRPC_PEERS"); v != "" {
+		c.GRPC.Peers = make(map[string]string)
+		for _, pair := range splitList(v) {
+			nodeID, addr, ok := strings.Cut(pair, "=")
+			if !ok {
+				err = fmt.Errorf("invalid GRPC_PEERS: %q is not node=address", pair)
+				continue
+			}
+			c.GRPC.Peers[strings.TrimSpace(nodeID)] = strings.TrimSpace(addr)
+		}
+	}
+
+	boolean("REGISTRY_ENABLED", &c.Registry.Enabled)
+	str("REGISTRY_INSTANCE", &c.Registry.Instance)
+	str("REGISTRY_ADDRESS", &c.Registry.Address)
+	if v := os.Getenv("REGISTRY_NODES"); v != "" {
+		c.Registry.Nodes = splitList(v)
+	}
+	duration("REGISTRY_TTL", &c.Registry.TTL)
+	duration("REGISTRY_INTERVAL", &c.Registry.Interval)
+
+	duration("LIVENESS_HEARTBEAT_INTERVAL", &c.Liveness.HeartbeatInterval)
+	integer("LIVENESS_MISSED_HEARTBEATS", &c.Liveness.MissedHeartbeats)
+
+	if v := os.Getenv("HEALTH_OPTIONAL"); v != "" {
+		c.Health.Optional = splitList(v)
+	}
+	duration("HEALTH_CHECK_TIMEOUT", &c.Health.CheckTimeout)
+	duration("STARTUP_TIMEOUT", &c.Startup.Timeout)
+	duration("STARTUP_INITIAL_BACKOFF", &c.Startup.InitialBackoff)
+	duration("STARTUP_MAX_BACKOFF", &c.Startup.MaxBackoff)
+
+	str("LOG_FORMAT", &c.Log.Format)
+	str("LOG_LEVEL", &c.Log.Level)
+
+	return err
+}
+
+// Validate checks settings that would otherwise fail at runtime
+func (c *Config) Validate() error {
+	switch {
+	case c.Server.Addr == "":
+		return fmt.Errorf("server.addr is required")
+	case time.Duration(c.Server.DrainTimeout) <= 0:
+		return fmt.Errorf("server.drain_timeout must be positive")
+	case c.WebSocket.BroadcastBuffer < 1 || c.WebSocket.SendBuffer < 1:
+		return fmt.Errorf("websocket buffers must hold at least one message")
+	case c.WebSocket.MaxDropped < 0:
+		return fmt.Errorf("websocket.max_dropped must not be negative")
+	case time.Duration(c.WebSocket.AdminStatsInterval) <= 0:
+		return fmt.Errorf("websocket.admin_stats_interval must be positive")
+	case time.Duration(c.Neo4j.CredibilityFlushInterval) <= 0:
+		return fmt.Errorf("neo4j.credibility_flush_interval must be positive")
+	case time.Duration(c.Neo4j.CredibilityHalfLife) < 0:
+		return fmt.Errorf("neo4j.credibility_half_life must not be negative")
+	case c.Routing.K < 1:
+		return fmt.Errorf("routing.k must be at least 1")
+	case c.Fees.BaseFeePercent < 0 || c.Fees.HopFeePercent < 0 || c.Fees.HaltFinePercent < 0:
+		return fmt.Errorf("fee rates must not be negative")
+	case c.Fees.BaseFeePercent >= 1:
+		return fmt.Errorf("fees.base_fee_percent is a fraction (0.015 = 1.5%%)")
+	case time.Duration(c.Quotes.TTL) <= 0:
+		return fmt.Errorf("quotes.ttl must be positive")
+	case c.SpendingLimits().Validate() != nil:
+		return fmt.Errorf("spending limits must not be negative")
+	case c.Compliance.ReviewThreshold < 0:
+		return fmt.Errorf("compliance.review_threshold must not be negative")
+	case c.Compliance.KYCThreshold < 0:
+		return fmt.Errorf("compliance.kyc_threshold must not be negative")
+	case time.Duration(c.Routing.GraphRefresh) <= 0:
+		return fmt.Errorf("routing.graph_refresh must be positive")
+	case c.Routing.CacheSize < 0:
+		return fmt.Errorf("routing.cache_size must not be negative")
+	case c.Routing.CacheSize > 0 && time.Duration(c.Routing.CacheTTL) <= 0:
+		return fmt.Errorf("routing.cache_ttl must be positive when the route cache is enabled")
+	case time.Duration(c.Routing.EntropyInterval) <= 0 || time.Duration(c.Routing.EntropyWindow) <= 0:
+		return fmt.Errorf("routing.entropy_interval and routing.entropy_window must be positive")
+	case time.Duration(c.FX.Interval) <= 0:
+		return fmt.Errorf("fx.interval must be positive")
+	case len(c.FX.Providers) == 0:
+		return fmt.Errorf("fx.providers must list at least one provider")
+	case time.Duration(c.FX.RateLimitBackoff) < 0:
+		return fmt.Errorf("fx.rate_limit_backoff must not be negative")
+	case time.Duration(c.Scheduler.Interval) <= 0:
+		return fmt.Errorf("scheduler.interval must be positive")
+	case time.Duration(c.Netting.Window) <= 0:
+		return fmt.Errorf("netting.window must be positive")
+	case time.Duration(c.Ledger.AuditInterval) < 0:
+		return fmt.Errorf("ledger.audit_interval must not be negative")
+	case time.Duration(c.Recovery.Interval) < 0:
+		return fmt.Errorf("recovery.interval must not be negative")
+	case time.Duration(c.Recovery.StuckAfter) <= 0:
+		return fmt.Errorf("recovery.stuck_after must be positive")
+	case c.Recovery.MaxAttempts < 1:
+		return fmt.Errorf("recovery.max_attempts must be at least 1")
+	}
+	if _, err := logging.New(io.Discard, c.Log.Format, c.Log.Level); err != nil {
+		return fmt.Errorf("log: %w", err)
+	}
+	if err := c.PricingCurve().Validate(); err != nil {
+		return fmt.Errorf("pricing: %w", err)
+	}
+	if err := c.NotifierConfig().Validate(); err != nil {
+		return fmt.Errorf("notifications: %w", err)
+	}
+	if _, err := c.PaymentProviders(&payments.StripeClient{}); err != nil {
+		return fmt.Errorf("payment_providers: %w", err)
+	}
+	for _, provider := range c.FX.Providers {
+		if !fxrates.KnownProvider(provider) {
+			return fmt.Errorf("unknown fx provider %q (want %s, %s or %s)", provider,
+				fxrates.ProviderExchangeRateAPI, fxrates.ProviderECB, fxrates.ProviderStatic)
+		}
+	}
+	if c.Liveness.HeartbeatInterval <= 0 || c.Liveness.MissedHeartbeats < 1 {
+		return fmt.Errorf("liveness needs a positive heartbeat_interval and at least one missed heartbeat")
+	}
+	if c.Registry.Enabled && (c.Registry.Interval <= 0 || c.Registry.TTL <= c.Registry.Interval) {
+		return fmt.Errorf("registry.ttl must be longer than a positive registry.interval")
+	}
+	if err := c.validateNATS(); err != nil {
+		return err
+	}
+	if c.Health.CheckTimeout <= 0 {
+		return fmt.Errorf("health.check_timeout must be positive")
+	}
+	if c.Startup.Timeout <= 0 || c.Startup.InitialBackoff <= 0 || c.Startup.MaxBackoff < c.Startup.InitialBackoff {
+		return fmt.Errorf("startup needs a positive timeout and initial_backoff, and max_backoff of at least initial_backoff")
+	}
+	for _, dep := range c.Health.Optional {
+		if _, ok := c.configuredDependencies()[dep]; !ok {
+			return fmt.Errorf("health.optional: unknown dependency %q (neo4j, postgres, redis or nats)", dep)
+		}
+	}
+	for nodeID, addr := range c.GRPC.Peers {
+		if nodeID == "" || addr == "" {
+			return fmt.Errorf("grpc.peers entries need a node ID and an address")
+		}
+	}
+	for _, store := range []string{c.Storage.TransactionStore, c.Storage.UserStore} {
+		if store != "memory" && store != "postgres" {
+			return fmt.Errorf("unknown store backend %q (want memory or postgres)", store)
+		}
+	}
+	return nil
+}
+
+// validateNATS checks the NATS authentication and TLS settings
+func (c *Config) validateNATS() error {
+	methods := 0
+	for _, set := range []bool{c.NATS.Token != "", c.NATS.User != "", c.NATS.NKeyFile != "", c.NATS.CredsFile != ""} {
+		if set {
+			methods++
+		}
+	}
+	switch {
+	case methods > 1:
+		return fmt.Errorf("nats: set only one of token, user, nkey_file and creds_file")
+	case (c.NATS.CertFile == "") != (c.NATS.KeyFile == ""):
+		return fmt.Errorf("nats: cert_file and key_file must be set together")
+	}
+	for key := range c.NATS.Labels {
+		if key == "" {
+			return fmt.Errorf("nats.labels keys must not be empty")
+		}
+	}
+	return nil
+}
+
+// Neo4jClientConfig returns the Neo4j client configuration
+func (c *Config) Neo4jClientConfig() *neo4jstore.Config {
+	return &neo4jstore.Config{
+		URI:      c.Neo4j.URI,
+		Username: c.Neo4j.Username,
+		Password: c.Neo4j.Password,
+		Database: c.Neo4j.Database,
+	}
+}
+
+// CredibilityBatchConfig returns the configuration of batched credibility writes
+func (c *Config) CredibilityBatchConfig() *neo4jstore.CredibilityBatchConfig {
+	cfg := neo4jstore.DefaultCredibilityBatchConfig()
+	cfg.FlushInterval = time.Duration(c.Neo4j.CredibilityFlushInterval)
+	cfg.HalfLife = time.Duration(c.Neo4j.CredibilityHalfLife)
+	return cfg
+}
+
+// PostgresClientConfig returns the PostgreSQL client configuration
+func (c *Config) PostgresClientConfig() *postgres.Config {
+	cfg := postgres.DefaultConfig()
+	cfg.Host = c.Postgres.Host
+	cfg.Port = c.Postgres.Port
+	cfg.User = c.Postgres.User
+	cfg.Password = c.Postgres.Password
+	cfg.Database = c.Postgres.Database
+	cfg.SSLMode = c.Postgres.SSLMode
+	return cfg
+}
+
+// RedisClientConfig returns the Redis client configuration, or nil if Redis is disabled
+func (c *Config) RedisClientConfig() (*redisClient.Config, error) {
+	if c.Redis.URL == "" {
+		return nil, nil
+	}
+	return redisClient.ConfigFromURL(c.Redis.URL)
+}
+
+// NATSClientConfig returns the NATS client configuration
+func (c *Config) NATSClientConfig() *natsClient.Config {
+	cfg := natsClient.DefaultConfig()
+	cfg.URLs = c.NATS.URL
+	cfg.Token = c.NATS.Token
+	cfg.User = c.NATS.User
+	cfg.Password = c.NATS.Password
+	cfg.NKeyFile = c.NATS.NKeyFile
+	cfg.CredsFile = c.NATS.CredsFile
+	cfg.CertFile = c.NATS.CertFile
+	cfg.KeyFile = c.NATS.KeyFile
+	cfg.CAFile = c.NATS.CAFile
+	if c.NATS.Name != "" {
+		cfg.Name = c.NATS.Name
+	}
+	cfg.Labels = c.NATS.Labels
+	return cfg
+}
+
+// FeeConfig returns the transaction fee configuration
+func (c *Config) FeeConfig() payments.FeeConfig {
+	return payments.FeeConfig{
+		BaseFeePercent:  c.Fees.BaseFeePercent,
+		HopFeePercent:   c.Fees.HopFeePercent,
+		HaltFinePercent: c.Fees.HaltFinePercent,
+	}
+}
+
+// PricingCurve returns the dynamic hop fee curve
+func (c *Config) PricingCurve() payments.PricingCurve {
+	return payments.PricingCurve{
+		Enabled:           c.Pricing.Enabled,
+		Base:              c.Pricing.Base,
+		CredibilityWeight: c.Pricing.CredibilityWeight,
+		SuccessRateWeight: c.Pricing.SuccessRateWeight,
+		Exponent:          c.Pricing.Exponent,
+		MinMultiplier:     c.Pricing.MinMultiplier,
+		MaxMultiplier:     c.Pricing.MaxMultiplier,
+	}
+}
+
+// QuoteConfig returns the payment quote configuration
+func (c *Config) QuoteConfig() payments.QuoteConfig {
+	return payments.QuoteConfig{
+		TTL:        time.Duration(c.Quotes.TTL),
+		SigningKey: []byte(c.Quotes.SigningKey),
+	}
+}
+
+// Simulator returns the hop simulator, loading the failure profile file
+func (c *Config) Simulator() (*payments.Simulator, error) {
+	seed := int64(c.Simulation.Seed)
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	var profile *payments.FailureProfile
+	if c.Simulation.ProfileFile != "" {
+		var err error
+		if profile, err = payments.LoadFailureProfile(c.Simulation.ProfileFile); err != nil {
+			return nil, fmt.Errorf("simulation profile: %w", err)
+		}
+	}
+	return payments.NewSimulator(seed, profile), nil
+}
+
+// SpendingLimits returns the default spending limits for users
+func (c *Config) SpendingLimits() limits.Limits {
+	return limits.Limits{
+		PerTransaction: c.Limits.PerTransaction,
+		Daily:          c.Limits.Daily,
+		Monthly:        c.Limits.Monthly,
+	}
+}
+
+// ScreeningConfig returns the compliance screening rules
+func (c *Config) ScreeningConfig() compliance.Config {
+	return compliance.Config{
+		DeniedCountries: c.Compliance.DeniedCountries,
+		ReviewThreshold: c.Compliance.ReviewThreshold,
+	}
+}
+
+// NotifierConfig returns the email notification configuration
+func (c *Config) NotifierConfig() *notifications.Config {
+	cfg := notifications.DefaultConfig()
+	cfg.Provider = c.Notify.Provider
+	cfg.From = c.Notify.From
+	cfg.AppURL = strings.TrimRight(c.Notify.AppURL, "/")
+	cfg.SMTPHost = c.Notify.SMTPHost
+	cfg.SMTPPort = c.Notify.SMTPPort
+	cfg.SMTPUsername = c.Notify.SMTPUsername
+	cfg.SMTPPassword = c.Notify.SMTPPassword
+	return cfg
+}
+
+// WebSocketHubConfig returns the WebSocket hub configuration
+func (c *Config) WebSocketHubConfig() websocket.HubConfig {
+	return websocket.HubConfig{
+		BroadcastBuffer: c.WebSocket.BroadcastBuffer,
+		SendBuffer:      c.WebSocket.SendBuffer,
+		MaxDropped:      c.WebSocket.MaxDropped,
+	}
+}
+
+// AdminStatsConfig returns the admin dashboard stats publisher configuration
+func (c *Config) AdminStatsConfig() *adminstats.Config {
+	cfg := adminstats.DefaultConfig()
+	cfg.Interval = time.Duration(c.WebSocket.AdminStatsInterval)
+	return cfg
+}
+
+// EntropyFeedConfig returns the mesh entropy updater configuration
+func (c *Config) EntropyFeedConfig() *entropyfeed.Config {
+	cfg := entropyfeed.DefaultConfig()
+	cfg.Interval = time.Duration(c.Routing.EntropyInterval)
+	cfg.Window = time.Duration(c.Routing.EntropyWindow)
+	return cfg
+}
+
+// FXWorkerConfig returns the FX rate worker configuration. The Neo4j driver
+// and currencies are left for the caller to set.
+func (c *Config) FXWorkerConfig() *fxrates.Config {
+	cfg := fxrates.DefaultConfig()
+	if c.FX.APIKey != "" {
+		cfg.APIKey = c.FX.APIKey
+	}
+	cfg.Interval = time.Duration(c.FX.Interval)
+	cfg.Providers = c.FX.Providers
+	cfg.RatesFile = c.FX.RatesFile
+	cfg.RateLimitBackoff = time.Duration(c.FX.RateLimitBackoff)
+	return cfg
+}
+
+// LedgerAuditConfig returns the ledger auditor configuration
+func (c *Config) LedgerAuditConfig() *ledgeraudit.Config {
+	cfg := ledgeraudit.DefaultConfig()
+	cfg.Interval = time.Duration(c.Ledger.AuditInterval)
+	return cfg
+}
+
+// RecoveryConfig returns the stuck payment reconciler configuration
+func (c *Config) RecoveryConfig() *recovery.Config {
+	cfg := recovery.DefaultConfig()
+	cfg.Interval = time.Duration(c.Recovery.Interval)
+	cfg.StuckAfter = time.Duration(c.Recovery.StuckAfter)
+	cfg.MaxAttempts = c.Recovery.MaxAttempts
+	return cfg
+}
+
+// RefundPolicy returns how much of a payment refunds return
+func (c *Config) RefundPolicy() payments.RefundPolicy {
+	return payments.RefundPolicy{RetainBaseFeeOnCancel: c.Refunds.RetainBaseFeeOnCancel}
+}
+
+// NetterConfig returns the settlement netting configuration
+func (c *Config) NetterConfig() *netting.Config {
+	cfg := netting.DefaultConfig()
+	cfg.Window = time.Duration(c.Netting.Window)
+	return cfg
+}
+
+// StripeReturnURL returns where Stripe sends payers after they authenticate a
+// payment, the frontend's pay page unless configured
+func (c *Config) StripeReturnURL() string {
+	if c.Stripe.ReturnURL != "" || c.Notify.AppURL == "" {
+		return c.Stripe.ReturnURL
+	}
+	return strings.TrimSuffix(c.Notify.AppURL, "/") + "/pay"
+}
+
+// PaymentProviders returns the providers payments can be collected through,
+// with stripe collecting currencies no provider is configured for. Simulated
+// bank transfers are refused next to a real Stripe account: they report
+// funds received without any being collected, and settled payments are paid
+// out through Stripe Connect.
+func (c *Config) PaymentProviders(stripe *payments.StripeClient) (*payments.Providers, error) {
+	providers := payments.NewProviders(stripe)
+	if c.Providers.BankTransfer {
+		if c.Stripe.SecretKey != "" {
+			return nil, fmt.Errorf("bank_transfer is simulated and cannot be enabled with a Stripe secret key")
+		}
+		providers.Register(payments.NewBankTransferProvider())
+	}
+	for currency, name := range c.Providers.Currencies {
+		if err := providers.SetCurrencyProvider(currency, name); err != nil {
+			return nil, err
+		}
+	}
+	return providers, nil
+}
+
+// GRPCServerConfig returns the settlement gRPC server configuration
+func (c *Config) GRPCServerConfig() *plmgrpc.ServerConfig {
+	cfg := plmgrpc.DefaultServerConfig()
+	cfg.Address = c.GRPC.Address
+	cfg.CertFile = c.GRPC.CertFile
+	cfg.KeyFile = c.GRPC.KeyFile
+	cfg.CACertFile = c.GRPC.CAFile
+	return cfg
+}
+
+// GRPCClientConfig returns the configuration for forwarding settlements to
+// the peer at address
+func (c *Config) GRPCClientConfig(address string) *plmgrpc.ClientConfig {
+	cfg := plmgrpc.DefaultClientConfig()
+	cfg.Address = address
+	cfg.CertFile = c.GRPC.CertFile
+	cfg.KeyFile = c.GRPC.KeyFile
+	cfg.CACertFile = c.GRPC.CAFile
+	return cfg
+}
+
+// NodeRegistryConfig returns the node registry configuration (without the
+// certificate fingerprint, which the caller reads from grpc.cert_file)
+func (c *Config) NodeRegistryConfig() *registry.Config {
+	cfg := registry.DefaultConfig()
+	if c.Registry.Instance != "" {
+		cfg.Instance = c.Registry.Instance
+	}
+	cfg.Address = c.Registry.Address
+	if cfg.Address == "" {
+		cfg.Address = c.GRPC.Address
+	}
+	cfg.Nodes = c.Registry.Nodes
+	cfg.TTL = time.Duration(c.Registry.TTL)
+	cfg.Interval = time.Duration(c.Registry.Interval)
+	return cfg
+}
+
+// BootConfig returns how long startup waits for dependencies
+func (c *Config) BootConfig() *boot.Config {
+	return &boot.Config{
+		Timeout:        time.Duration(c.Startup.Timeout),
+		InitialBackoff: time.Duration(c.Startup.InitialBackoff),
+		MaxBackoff:     time.Duration(c.Startup.MaxBackoff),
+	}
+}
+
+// LivenessTrackerConfig returns the heartbeat liveness tracker configuration
+func (c *Config) LivenessTrackerConfig() *liveness.Config {
+	return liveness.IntervalConfig(time.Duration(c.Liveness.HeartbeatInterval), c.Liveness.MissedHeartbeats)
+}
+
+// configuredDependencies reports, for each external dependency, whether the
+// server is configured to use it
+func (c *Config) configuredDependencies() map[string]bool {
+	return map[string]bool{
+		"neo4j":    c.Neo4j.URI != "",
+		"postgres": c.Storage.TransactionStore == "postgres" || c.Storage.UserStore == "postgres",
+		"redis":    c.Redis.URL != "",
+		"nats":     c.NATS.URL != "",
+	}
+}
+
+// DependencyConfigured reports whether the server is configured to use dep
+func (c *Config) DependencyConfigured(dep string) bool {
+	return c.configuredDependencies()[dep]
+}
+
+// DependencyRequired reports whether readiness waits for dep: it is
+// configured and not listed in health.optional
+func (c *Config) DependencyRequired(dep string) bool {
+	if !c.DependencyConfigured(dep) {
+		return false
+	}
+	for _, optional := range c.Health.Optional {
+		if optional == dep {
+			return false
+		}
+	}
+	return true
+}
+
+// AllowsAnyOrigin reports whether CORS is open to every origin
+func (c *Config) AllowsAnyOrigin() bool {
+	for _, origin := range c.Server.CORSOrigins {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
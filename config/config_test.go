@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadFileThenEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(path, []byte(`{
+		"server": {"addr": ":9090", "cors_origins": ["https://app.example.com"]},
+		"routing": {"k": 5},
+		"fees": {"base_fee_percent": 0.01},
+		"fx": {"interval": "15m"}
+	}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("ROUTING_K", "4")
+	t.Setenv("CORS_ORIGINS", "https://a.example.com, https://b.example.com")
+	t.Setenv("NEO4J_PASSWORD", "from-env")
+	t.Setenv("NATS_LABELS", "region=eu, instance=plm-eu")
+	t.Setenv("HEALTH_OPTIONAL", "neo4j")
+	t.Setenv("REDIS_URL", "redis://localhost:6379/0")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.Server.Addr != ":9090" || time.Duration(cfg.FX.Interval) != 15*time.Minute {
+		t.Errorf("file values not applied: addr=%s interval=%v", cfg.Server.Addr, time.Duration(cfg.FX.Interval))
+	}
+	if cfg.Routing.K != 4 || cfg.Neo4jClientConfig().Password != "from-env" {
+		t.Errorf("env overrides not applied: k=%d", cfg.Routing.K)
+	}
+	if nats := cfg.NATSClientConfig(); nats.Name != "plm-server" || nats.Labels["region"] != "eu" || len(nats.Labels) != 2 {
+		t.Errorf("unexpected NATS connection name %q and labels %v", nats.Name, nats.Labels)
+	}
+	if cfg.DependencyRequired("neo4j") || !cfg.DependencyConfigured("neo4j") || !cfg.DependencyRequired("redis") || cfg.DependencyRequired("postgres") {
+		t.Errorf("unexpected readiness dependencies: optional=%v", cfg.Health.Optional)
+	}
+	if len(cfg.Server.CORSOrigins) != 2 || cfg.AllowsAnyOrigin() {
+		t.Errorf("unexpected CORS origins: %v", cfg.Server.CORSOrigins)
+	}
+
+	// Unset fields keep their defaults
+	fees := cfg.FeeConfig()
+	if fees.BaseFeePercent != 0.01 || fees.HopFeePercent != Default().Fees.HopFeePercent {
+		t.Errorf("unexpected fees: %+v", fees)
+	}
+}
+
+func TestLoadRejectsInvalidConfig(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"unknown field":         `{"server": {"adress": ":8080"}}`,
+		"no drain timeout":      `{"server": {"drain_timeout": "0s"}}`,
+		"bad duration":          `{"fx": {"interval": "hourly"}}`,
+		"zero k":                `{"routing": {"k": 0}}`,
+		"no send buffer":        `{"websocket": {"send_buffer": 0}}`,
+		"percent as 1.5":        `{"fees": {"base_fee_percent": 1.5}}`,
+		"unknown store":         `{"storage": {"user_store": "mongo"}}`,
+		"unknown fx provider":   `{"fx": {"providers": ["ecb", "yahoo"]}}`,
+		"two nats auths":        `{"nats": {"token": "t", "creds_file": "plm.creds"}}`,
+		"nats cert, no key":     `{"nats": {"cert_file": "client.pem"}}`,
+		"unknown optional dep":  `{"health": {"optional": ["mysql"]}}`,
+		"backoff over max":      `{"startup": {"initial_backoff": "30s", "max_backoff": "10s"}}`,
+		"no recovery attempts":  `{"recovery": {"max_attempts": 0}}`,
+		"provider not enabled":  `{"payment_providers": {"currencies": {"EUR": "bank_transfer"}}}`,
+		"simulated with stripe": `{"stripe": {"secret_key": "sk_test_123"}, "payment_providers": {"bank_transfer": true}}`,
+	} {
+		path := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	t.Setenv("ROUTING_K", "three")
+	if _, err := Load(""); err == nil {
+		t.Error("expected error for non-numeric ROUTING_K")
+	}
+}
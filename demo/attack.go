@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/engine/router"
 	"github.com/plm/predictive-liquidity-mesh/websocket"
 )
@@ -82,9 +83,9 @@ func (d *ChaosDemo) HandleAttackDemo(w http.ResponseWriter, r *http.Request) {
 
 	// Step 1: Find the primary (best) path
 	log.Println("📍 Step 1: Finding primary path...")
-	paths, err := d.router.FindKShortestPaths(ctx, source, destination)
+	paths, err := d.router.FindKShortestPaths(ctx, source, destination, amount)
 	if err != nil || len(paths) == 0 {
-		http.Error(w, "Failed to find routes: "+err.Error(), http.StatusInternalServerError)
+		apierror.Respond(w, http.StatusInternalServerError, "Failed to find routes: "+err.Error())
 		return
 	}
 
@@ -264,6 +265,13 @@ func (d *ChaosDemo) HandleResetDemo(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Clear injected edge degradation
+	if d.graph != nil {
+		for _, e := range d.graph.Degradations() {
+			d.graph.DegradeEdge(e.SourceID, e.TargetID, router.Degradation{})
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
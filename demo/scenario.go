@@ -0,0 +1,454 @@
+package demo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// Scenario step actions
+const (
+	ActionKillNode    = "kill_node"    // Node: open its circuit breaker and take it out of routing
+	ActionReviveNode  = "revive_node"  // Node: bring it back
+	ActionDegradeEdge = "degrade_edge" // From, To: inject LatencyMs and/or LiquidityReduction into the edge
+	ActionRestoreEdge = "restore_edge" // From, To: undo degrade_edge
+	ActionPartition   = "partition"    // Nodes or Region: cut every edge between them and the rest of the mesh
+	ActionHeal        = "heal"         // Undo everything the scenario has done so far
+)
+
+// maxScenarioLength bounds how far into the future a step may be scheduled
+const maxScenarioLength = 10 * time.Minute
+
+// ErrScenarioRunning is returned when starting a scenario while another runs
+var ErrScenarioRunning = errors.New("a chaos scenario is already running")
+
+// Offset is a step's time from the start of its scenario, written "2s" or "T+2s"
+type Offset time.Duration
+
+// UnmarshalJSON parses an offset string
+func (o *Offset) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("offset must be a string like \"T+2s\": %w", err)
+	}
+	d, err := time.ParseDuration(strings.TrimPrefix(s, "T+"))
+	if err != nil {
+		return err
+	}
+	*o = Offset(d)
+	return nil
+}
+
+// MarshalJSON writes the offset as "T+2s"
+func (o Offset) MarshalJSON() ([]byte, error) {
+	return json.Marshal("T+" + time.Duration(o).String())
+}
+
+// Step is one timed action of a scenario
+type Step struct {
+	At        Offset   `json:"at"`
+	Action    string   `json:"action"`
+	Node      string   `json:"node,omitempty"`
+	From      string   `json:"from,omitempty"`
+	To        string   `json:"to,omitempty"`
+	LatencyMs int64    `json:"latency_ms,omitempty"`
+	Nodes     []string `json:"nodes,omitempty"`
+	Region    string   `json:"region,omitempty"`
+
+	LiquidityReduction float64 `json:"liquidity_reduction,omitempty"`
+}
+
+// Scenario is a scripted failure drill. Whatever it changed is healed when
+// it ends unless KeepChanges is set.
+type Scenario struct {
+	Name        string `json:"name"`
+	Steps       []Step `json:"steps"`
+	KeepChanges bool   `json:"keep_changes,omitempty"`
+}
+
+// ParseScenario reads and validates a JSON scenario, ordering its steps by offset
+func ParseScenario(data []byte) (*Scenario, error) {
+	var s Scenario
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return nil, err
+	}
+	if len(s.Steps) == 0 {
+		return nil, errors.New("scenario has no steps")
+	}
+	for i, step := range s.Steps {
+		if err := step.validate(); err != nil {
+			return nil, fmt.Errorf("step %d (%s): %w", i+1, step.Action, err)
+		}
+	}
+	sort.SliceStable(s.Steps, func(i, j int) bool { return s.Steps[i].At < s.Steps[j].At })
+	return &s, nil
+}
+
+func (s *Step) validate() error {
+	switch {
+	case s.At < 0 || time.Duration(s.At) > maxScenarioLength:
+		return fmt.Errorf("at must be between T+0s and T+%v", maxScenarioLength)
+	case (s.Action == ActionKillNode || s.Action == ActionReviveNode) && s.Node == "":
+		return errors.New("node is required")
+	case (s.Action == ActionDegradeEdge || s.Action == ActionRestoreEdge) && (s.From == "" || s.To == ""):
+		return errors.New("from and to are required")
+	case s.Action == ActionDegradeEdge && s.LatencyMs <= 0 && s.LiquidityReduction <= 0:
+		return errors.New("latency_ms or liquidity_reduction is required")
+	case s.Action == ActionDegradeEdge && (s.LatencyMs < 0 || s.LiquidityReduction > 1):
+		return errors.New("latency_ms must not be negative and liquidity_reduction at most 1")
+	case s.Action == ActionPartition && len(s.Nodes) == 0 && s.Region == "":
+		return errors.New("nodes or region is required")
+	}
+	switch s.Action {
+	case ActionKillNode, ActionReviveNode, ActionDegradeEdge, ActionRestoreEdge, ActionPartition, ActionHeal:
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", s.Action)
+}
+
+// NodeController kills and revives mesh nodes; implemented by handlers.ChaosHandler
+type NodeController interface {
+	KillNode(ctx context.Context, nodeID string) error
+	ReviveNode(ctx context.Context, nodeID string) error
+}
+
+// Broadcaster sends progress events to WebSocket clients; implemented by websocket.Hub
+type Broadcaster interface {
+	BroadcastJSON(data map[string]interface{})
+}
+
+// StepResult is the execution of one step
+type StepResult struct {
+	Step
+	Status     string     `json:"status"` // pending, done, failed or skipped
+	Error      string     `json:"error,omitempty"`
+	ExecutedAt *time.Time `json:"executed_at,omitempty"`
+}
+
+// ScenarioRun is the progress of a scenario
+type ScenarioRun struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Status    string       `json:"status"` // running, completed, failed or cancelled
+	StartedAt time.Time    `json:"started_at"`
+	EndedAt   *time.Time   `json:"ended_at,omitempty"`
+	Steps     []StepResult `json:"steps"`
+}
+
+// ScenarioRunner executes chaos scenarios against the mesh, one at a time
+type ScenarioRunner struct {
+	graph *router.Graph
+	nodes NodeController
+	hub   Broadcaster
+
+	mu     sync.Mutex
+	run    *ScenarioRun
+	cancel context.CancelFunc
+	done   chan struct{}
+	undo   []func(ctx context.Context) // Reverses the changes made, newest last
+	// Degradation of each edge before the scenario first degraded it
+	degraded map[[2]string]router.Degradation
+}
+
+// NewScenarioRunner creates a scenario runner; hub may be nil
+func NewScenarioRunner(graph *router.Graph, nodes NodeController, hub Broadcaster) *ScenarioRunner {
+	return &ScenarioRunner{graph: graph, nodes: nodes, hub: hub}
+}
+
+// Start runs a scenario in the background, returning its initial progress
+func (r *ScenarioRunner) Start(ctx context.Context, s *Scenario) (*ScenarioRun, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.run != nil && r.run.Status == "running" {
+		return nil, ErrScenarioRunning
+	}
+
+	run := &ScenarioRun{
+		ID:        uuid.New().String(),
+		Name:      s.Name,
+		Status:    "running",
+		StartedAt: time.Now(),
+		Steps:     make([]StepResult, len(s.Steps)),
+	}
+	for i, step := range s.Steps {
+		run.Steps[i] = StepResult{Step: step, Status: "pending"}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r.run, r.cancel, r.done, r.undo = run, cancel, make(chan struct{}), nil
+	r.degraded = make(map[[2]string]router.Degradation)
+
+	slog.InfoContext(ctx, "chaos scenario started", "scenario", s.Name, "run_id", run.ID, "steps", len(s.Steps))
+	r.broadcastLocked("started", nil)
+	go r.execute(ctx, s, run.StartedAt, r.done)
+	return r.snapshotLocked(), nil
+}
+
+// Cancel stops the running scenario and heals its changes, reporting
+// whether one was running
+func (r *ScenarioRunner) Cancel() bool {
+	r.mu.Lock()
+	if r.run == nil || r.run.Status != "running" {
+		r.mu.Unlock()
+		return false
+	}
+	cancel, done := r.cancel, r.done
+	r.mu.Unlock()
+
+	cancel()
+	<-done
+	return true
+}
+
+// Current returns the progress of the running or last scenario, nil if none ran
+func (r *ScenarioRunner) Current() *ScenarioRun {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.snapshotLocked()
+}
+
+func (r *ScenarioRunner) execute(ctx context.Context, s *Scenario, start time.Time, done chan struct{}) {
+	defer close(done)
+	status := "completed"
+	for i, step := range s.Steps {
+		timer := time.NewTimer(time.Until(start.Add(time.Duration(step.At))))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			status = "cancelled"
+		case <-timer.C:
+		}
+		if status == "cancelled" {
+			break
+		}
+
+		err := r.apply(ctx, step)
+		now := time.Now()
+		r.mu.Lock()
+		result := &r.run.Steps[i]
+		result.Status, result.ExecutedAt = "done", &now
+		if err != nil {
+			result.Status, result.Error = "failed", err.Error()
+			status = "failed"
+			slog.WarnContext(ctx, "chaos scenario step failed", "scenario", s.Name, "step", i+1, "action", step.Action, "error", err)
+		}
+		r.broadcastLocked("step", result)
+		r.mu.Unlock()
+		if err != nil {
+			break
+		}
+	}
+
+	// Changes outlive the scenario only when asked to, and never a failed or cancelled one
+	if !s.KeepChanges || status != "completed" {
+		r.heal(context.WithoutCancel(ctx))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.run.Status, r.run.EndedAt = status, &now
+	for i := range r.run.Steps {
+		if r.run.Steps[i].Status == "pending" {
+			r.run.Steps[i].Status = "skipped"
+		}
+	}
+	r.cancel()
+	slog.InfoContext(ctx, "chaos scenario finished", "scenario", s.Name, "run_id", r.run.ID, "status", status)
+	r.broadcastLocked("finished", nil)
+}
+
+// apply executes a step, recording how to undo it
+func (r *ScenarioRunner) apply(ctx context.Context, step Step) error {
+	switch step.Action {
+	case ActionKillNode:
+		if r.graph.GetNode(step.Node) == nil {
+			return fmt.Errorf("unknown node %s", step.Node)
+		}
+		if err := r.nodes.KillNode(ctx, step.Node); err != nil {
+			return err
+		}
+		r.pushUndo(func(ctx context.Context) { r.nodes.ReviveNode(ctx, step.Node) })
+		return nil
+
+	case ActionReviveNode:
+		return r.nodes.ReviveNode(ctx, step.Node)
+
+	case ActionDegradeEdge:
+		edge := [2]string{step.From, step.To}
+		prev, err := r.graph.DegradeEdge(step.From, step.To, router.Degradation{
+			ExtraLatencyMs:     step.LatencyMs,
+			LiquidityReduction: step.LiquidityReduction,
+		})
+		if err != nil {
+			return err
+		}
+		r.mu.Lock()
+		if _, ok := r.degraded[edge]; !ok {
+			r.degraded[edge] = prev
+			r.undo = append(r.undo, func(ctx context.Context) { r.restoreEdge(step.From, step.To) })
+		}
+		r.mu.Unlock()
+		return nil
+
+	case ActionRestoreEdge:
+		if !r.restoreEdge(step.From, step.To) {
+			return fmt.Errorf("edge %s->%s is not degraded", step.From, step.To)
+		}
+		return nil
+
+	case ActionPartition:
+		group := make(map[string]bool, len(step.Nodes))
+		for _, id := range step.Nodes {
+			group[id] = true
+		}
+		if step.Region != "" {
+			for _, node := range r.graph.GetAllNodes() {
+				if node.Region == step.Region {
+					group[node.ID] = true
+				}
+			}
+		}
+		cut := r.graph.CrossingEdges(group)
+		if len(cut) == 0 {
+			return errors.New("partition cuts no edges")
+		}
+		for _, edge := range cut {
+			r.graph.SetEdgeActive(edge[0], edge[1], false)
+		}
+		r.pushUndo(func(ctx context.Context) {
+			for _, edge := range cut {
+				r.graph.SetEdgeActive(edge[0], edge[1], true)
+			}
+		})
+		return nil
+
+	case ActionHeal:
+		r.heal(ctx)
+		return nil
+	}
+	return fmt.Errorf("unknown action %q", step.Action)
+}
+
+func (r *ScenarioRunner) pushUndo(fn func(ctx context.Context)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.undo = append(r.undo, fn)
+}
+
+// restoreEdge puts back an edge's degradation from before the scenario
+// degraded it, reporting whether it was degraded
+func (r *ScenarioRunner) restoreEdge(from, to string) bool {
+	r.mu.Lock()
+	edge := [2]string{from, to}
+	prev, ok := r.degraded[edge]
+	delete(r.degraded, edge)
+	r.mu.Unlock()
+	if ok {
+		r.graph.DegradeEdge(from, to, prev)
+	}
+	return ok
+}
+
+// heal undoes every change made so far, newest first
+func (r *ScenarioRunner) heal(ctx context.Context) {
+	r.mu.Lock()
+	undo := r.undo
+	r.undo = nil
+	r.mu.Unlock()
+	for i := len(undo) - 1; i >= 0; i-- {
+		undo[i](ctx)
+	}
+}
+
+// snapshotLocked copies the current run. Caller must hold r.mu.
+func (r *ScenarioRunner) snapshotLocked() *ScenarioRun {
+	if r.run == nil {
+		return nil
+	}
+	run := *r.run
+	run.Steps = append([]StepResult(nil), r.run.Steps...)
+	return &run
+}
+
+// broadcastLocked sends a progress event. Caller must hold r.mu.
+func (r *ScenarioRunner) broadcastLocked(event string, step *StepResult) {
+	if r.hub == nil {
+		return
+	}
+	data := map[string]interface{}{
+		"event":  event,
+		"run_id": r.run.ID,
+		"name":   r.run.Name,
+		"status": r.run.Status,
+	}
+	if step != nil {
+		data["step"] = *step
+	}
+	r.hub.BroadcastJSON(map[string]interface{}{
+		"type": "CHAOS_SCENARIO",
+		"data": data,
+	})
+}
+
+// maxScenarioBytes bounds a scenario request body
+const maxScenarioBytes = 64 * 1024
+
+// HandleScenario handles /demo/scenario: POST starts a scenario, GET returns
+// the progress of the running or last one and DELETE cancels it
+func (r *ScenarioRunner) HandleScenario(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch req.Method {
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxScenarioBytes))
+		if err != nil {
+			apierror.Respond(w, http.StatusBadRequest, "failed to read body")
+			return
+		}
+		scenario, err := ParseScenario(body)
+		if err != nil {
+			apierror.Respond(w, http.StatusBadRequest, "invalid scenario: "+err.Error())
+			return
+		}
+		// The scenario outlives the request
+		run, err := r.Start(context.WithoutCancel(req.Context()), scenario)
+		if errors.Is(err, ErrScenarioRunning) {
+			apierror.Respond(w, http.StatusConflict, "a chaos scenario is already running")
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(run)
+
+	case http.MethodGet:
+		run := r.Current()
+		if run == nil {
+			apierror.Respond(w, http.StatusNotFound, "no scenario has run")
+			return
+		}
+		json.NewEncoder(w).Encode(run)
+
+	case http.MethodDelete:
+		if !r.Cancel() {
+			apierror.Respond(w, http.StatusNotFound, "no scenario is running")
+			return
+		}
+		json.NewEncoder(w).Encode(r.Current())
+
+	default:
+		apierror.Respond(w, http.StatusMethodNotAllowed, "method not allowed")
+	}
+}
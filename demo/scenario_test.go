@@ -0,0 +1,181 @@
+package demo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// fakeNodes records kills and revives
+type fakeNodes struct {
+	mu     sync.Mutex
+	killed map[string]bool
+}
+
+func (f *fakeNodes) KillNode(ctx context.Context, nodeID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.killed[nodeID] = true
+	return nil
+}
+
+func (f *fakeNodes) ReviveNode(ctx context.Context, nodeID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.killed, nodeID)
+	return nil
+}
+
+func (f *fakeNodes) isKilled(nodeID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.killed[nodeID]
+}
+
+// testGraph is a -- b -- c with b in region "eu"
+func testGraph() *router.Graph {
+	g := router.NewGraph()
+	g.AddNode(&router.Node{ID: "a", IsActive: true})
+	g.AddNode(&router.Node{ID: "b", Region: "eu", IsActive: true})
+	g.AddNode(&router.Node{ID: "c", IsActive: true})
+	g.AddBidirectionalEdge(&router.Edge{SourceID: "a", TargetID: "b", Latency: 10, IsActive: true})
+	g.AddBidirectionalEdge(&router.Edge{SourceID: "b", TargetID: "c", Latency: 10, IsActive: true})
+	return g
+}
+
+func edge(g *router.Graph, from, to string) *router.Edge {
+	for _, e := range g.GetAllEdges() {
+		if e.SourceID == from && e.TargetID == to {
+			return e
+		}
+	}
+	return nil
+}
+
+func waitFinished(t *testing.T, r *ScenarioRunner) *ScenarioRun {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if run := r.Current(); run.Status != "running" {
+			return run
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("scenario did not finish")
+	return nil
+}
+
+func TestParseScenario(t *testing.T) {
+	s, err := ParseScenario([]byte(`{"name":"drill","steps":[
+		{"at":"T+2s","action":"partition","region":"eu"},
+		{"at":"500ms","action":"kill_node","node":"lp_alpha"},
+		{"at":"T+1s","action":"degrade_edge","from":"a","to":"b","latency_ms":200}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseScenario failed: %v", err)
+	}
+	if s.Steps[0].Action != ActionKillNode || s.Steps[2].Action != ActionPartition || time.Duration(s.Steps[2].At) != 2*time.Second {
+		t.Errorf("steps not ordered by offset: %+v", s.Steps)
+	}
+
+	for name, body := range map[string]string{
+		"no steps":        `{"name":"x","steps":[]}`,
+		"unknown action":  `{"steps":[{"at":"1s","action":"explode"}]}`,
+		"missing node":    `{"steps":[{"at":"1s","action":"kill_node"}]}`,
+		"missing latency": `{"steps":[{"at":"1s","action":"degrade_edge","from":"a","to":"b"}]}`,
+		"empty partition": `{"steps":[{"at":"1s","action":"partition"}]}`,
+		"bad offset":      `{"steps":[{"at":"soon","action":"heal"}]}`,
+		"too late":        `{"steps":[{"at":"T+1h","action":"heal"}]}`,
+		"unknown field":   `{"steps":[{"at":"1s","action":"heal","nod":"a"}]}`,
+	} {
+		if _, err := ParseScenario([]byte(body)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestScenarioRunsStepsAndHeals(t *testing.T) {
+	g := testGraph()
+	nodes := &fakeNodes{killed: make(map[string]bool)}
+	r := NewScenarioRunner(g, nodes, nil)
+
+	s, err := ParseScenario([]byte(`{"name":"drill","steps":[
+		{"at":"0s","action":"kill_node","node":"c"},
+		{"at":"10ms","action":"degrade_edge","from":"a","to":"b","latency_ms":90},
+		{"at":"20ms","action":"partition","region":"eu"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Start(context.Background(), s); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if _, err := r.Start(context.Background(), s); err != ErrScenarioRunning {
+		t.Errorf("expected ErrScenarioRunning, got %v", err)
+	}
+
+	run := waitFinished(t, r)
+	if run.Status != "completed" {
+		t.Fatalf("expected completed, got %s: %+v", run.Status, run.Steps)
+	}
+	for _, step := range run.Steps {
+		if step.Status != "done" || step.ExecutedAt == nil {
+			t.Errorf("step not executed: %+v", step)
+		}
+	}
+	// Everything is healed at the end
+	if nodes.isKilled("c") {
+		t.Error("killed node not revived")
+	}
+	if e := edge(g, "a", "b"); e.EffectiveLatency() != 10 || !e.IsActive {
+		t.Errorf("edge not restored: %+v", e)
+	}
+	if e := edge(g, "c", "b"); !e.IsActive {
+		t.Error("partitioned edge not restored")
+	}
+}
+
+func TestScenarioKeepChangesAndCancel(t *testing.T) {
+	g := testGraph()
+	nodes := &fakeNodes{killed: make(map[string]bool)}
+	r := NewScenarioRunner(g, nodes, nil)
+
+	s, _ := ParseScenario([]byte(`{"name":"keep","keep_changes":true,"steps":[
+		{"at":"0s","action":"partition","nodes":["a"]},
+		{"at":"0s","action":"degrade_edge","from":"b","to":"c","latency_ms":50},
+		{"at":"0s","action":"degrade_edge","from":"b","to":"c","latency_ms":5}
+	]}`))
+	r.Start(context.Background(), s)
+	if run := waitFinished(t, r); run.Status != "completed" {
+		t.Fatalf("expected completed, got %s", run.Status)
+	}
+	if edge(g, "a", "b").IsActive || edge(g, "b", "c").EffectiveLatency() != 15 {
+		t.Error("kept changes were healed")
+	}
+
+	// A cancelled scenario heals what it did and skips the rest
+	g = testGraph()
+	r = NewScenarioRunner(g, nodes, nil)
+	s, _ = ParseScenario([]byte(`{"name":"cancel","keep_changes":true,"steps":[
+		{"at":"0s","action":"kill_node","node":"a"},
+		{"at":"T+1m","action":"heal"}
+	]}`))
+	r.Start(context.Background(), s)
+	time.Sleep(20 * time.Millisecond)
+	if !r.Cancel() {
+		t.Fatal("expected a running scenario to cancel")
+	}
+	run := r.Current()
+	if run.Status != "cancelled" || run.Steps[1].Status != "skipped" {
+		t.Errorf("unexpected run: %+v", run)
+	}
+	if nodes.isKilled("a") {
+		t.Error("cancelled scenario not healed")
+	}
+	if r.Cancel() {
+		t.Error("nothing left to cancel")
+	}
+}
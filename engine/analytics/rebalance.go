@@ -0,0 +1,253 @@
+// Package analytics watches edge liquidity in the routing graph and
+// recommends rebalancing transfers before corridors run dry.
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/websocket"
+)
+
+// Config holds rebalancing advisor configuration
+type Config struct {
+	// Threshold is the utilization (percent of capacity reserved or settled
+	// away) above which an edge needs rebalancing
+	Threshold float64
+	// Target is the utilization a recommended transfer brings the hot edge
+	// down to, without pushing the donor edge above it
+	Target float64
+	// CheckInterval is how often the background check runs
+	CheckInterval time.Duration
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Threshold:     80,
+		Target:        50,
+		CheckInterval: 10 * time.Second,
+	}
+}
+
+// EdgeUtilization is an edge's liquidity with its utilization
+type EdgeUtilization struct {
+	router.EdgeLiquidity
+	Utilization float64 `json:"utilization_percent"`
+}
+
+// Recommendation suggests moving liquidity from an underused edge to one
+// over the threshold. Both edges share a node, so the transfer stays on the
+// same side of the mesh.
+type Recommendation struct {
+	FromSourceID string  `json:"from_source_id"`
+	FromTargetID string  `json:"from_target_id"`
+	ToSourceID   string  `json:"to_source_id"`
+	ToTargetID   string  `json:"to_target_id"`
+	Amount       int64   `json:"amount"`
+	Utilization  float64 `json:"utilization_percent"` // Of the receiving edge, before the transfer
+	Reason       string  `json:"reason"`
+}
+
+// Report is a point-in-time view of edge utilization with recommendations
+type Report struct {
+	Threshold       float64           `json:"threshold_percent"`
+	Edges           []EdgeUtilization `json:"edges"` // Most utilized first
+	Recommendations []Recommendation  `json:"recommendations"`
+	GeneratedAt     time.Time         `json:"generated_at"`
+}
+
+// Advisor monitors edge utilization and produces rebalancing recommendations
+type Advisor struct {
+	graph *router.Graph
+	wsHub *websocket.Hub
+	cfg   *Config
+
+	mu sync.Mutex
+	// alerted holds edges over the threshold at the last check, so each
+	// crossing is alerted once
+	alerted map[string]bool
+}
+
+// NewAdvisor creates a new rebalancing advisor
+func NewAdvisor(graph *router.Graph, wsHub *websocket.Hub, cfg *Config) *Advisor {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Advisor{
+		graph:   graph,
+		wsHub:   wsHub,
+		cfg:     cfg,
+		alerted: make(map[string]bool),
+	}
+}
+
+// Utilization returns every liquidity-tracking edge, most utilized first
+func (a *Advisor) Utilization() []EdgeUtilization {
+	edges := a.graph.Liquidity()
+	out := make([]EdgeUtilization, len(edges))
+	for i, e := range edges {
+		out[i] = EdgeUtilization{EdgeLiquidity: e, Utilization: utilization(e)}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Utilization > out[j].Utilization })
+	return out
+}
+
+// Report returns the current utilization of every tracked edge together with
+// the recommendations for it
+func (a *Advisor) Report() *Report {
+	edges := a.Utilization()
+	return &Report{
+		Threshold:       a.cfg.Threshold,
+		Edges:           edges,
+		Recommendations: a.recommend(edges),
+		GeneratedAt:     time.Now().UTC(),
+	}
+}
+
+// Recommendations returns transfers that bring edges over the threshold back
+// towards the target, most utilized edge first. Donors are active edges
+// sharing the hot edge's target (or, failing that, its source), least
+// utilized first; a donor never gives up more than keeps it at the target.
+func (a *Advisor) Recommendations() []Recommendation {
+	return a.recommend(a.Utilization())
+}
+
+func (a *Advisor) recommend(edges []EdgeUtilization) []Recommendation {
+	target := a.cfg.Target / 100
+
+	// spare is what each donor can still give away
+	spare := make(map[string]int64, len(edges))
+	for _, e := range edges {
+		if e.IsActive && e.Utilization < a.cfg.Target {
+			spare[edgeKey(e.SourceID, e.TargetID)] = min(e.Capacity-int64(float64(used(e))/target), e.Available)
+		}
+	}
+
+	// Donors in ascending utilization
+	donors := make([]EdgeUtilization, len(edges))
+	copy(donors, edges)
+	sort.SliceStable(donors, func(i, j int) bool { return donors[i].Utilization < donors[j].Utilization })
+
+	recs := make([]Recommendation, 0)
+	for _, hot := range edges {
+		if hot.Utilization <= a.cfg.Threshold {
+			continue
+		}
+		need := int64(float64(used(hot))/target) - hot.Capacity
+		for _, shared := range []func(EdgeUtilization) bool{
+			func(d EdgeUtilization) bool { return d.TargetID == hot.TargetID },
+			func(d EdgeUtilization) bool { return d.SourceID == hot.SourceID },
+		} {
+			for _, donor := range donors {
+				if need <= 0 {
+					break
+				}
+				key := edgeKey(donor.SourceID, donor.TargetID)
+				if spare[key] <= 0 || !shared(donor) || key == edgeKey(hot.SourceID, hot.TargetID) {
+					continue
+				}
+				amount := min(need, spare[key])
+				spare[key] -= amount
+				need -= amount
+				recs = append(recs, Recommendation{
+					FromSourceID: donor.SourceID,
+					FromTargetID: donor.TargetID,
+					ToSourceID:   hot.SourceID,
+					ToTargetID:   hot.TargetID,
+					Amount:       amount,
+					Utilization:  hot.Utilization,
+					Reason: fmt.Sprintf("%s→%s is %.0f%% utilized; %s→%s is %.0f%%",
+						hot.SourceID, hot.TargetID, hot.Utilization, donor.SourceID, donor.TargetID, donor.Utilization),
+				})
+			}
+		}
+	}
+	return recs
+}
+
+// Check alerts WebSocket clients about edges that crossed the threshold since
+// the last check, with the first recommendation for each, and returns the
+// alerts sent
+func (a *Advisor) Check() []*websocket.LiquidityAlert {
+	edges := a.Utilization()
+	recs := a.recommend(edges)
+
+	a.mu.Lock()
+	over := make(map[string]bool)
+	var alerts []*websocket.LiquidityAlert
+	for _, e := range edges {
+		if e.Utilization <= a.cfg.Threshold {
+			continue
+		}
+		key := edgeKey(e.SourceID, e.TargetID)
+		over[key] = true
+		if a.alerted[key] {
+			continue
+		}
+		alert := &websocket.LiquidityAlert{
+			SourceID:    e.SourceID,
+			TargetID:    e.TargetID,
+			Utilization: e.Utilization,
+			Threshold:   a.cfg.Threshold,
+			Available:   e.Available,
+		}
+		for _, rec := range recs {
+			if rec.ToSourceID == e.SourceID && rec.ToTargetID == e.TargetID {
+				alert.FromSourceID, alert.FromTargetID, alert.Amount = rec.FromSourceID, rec.FromTargetID, rec.Amount
+				break
+			}
+		}
+		alerts = append(alerts, alert)
+	}
+	a.alerted = over
+	a.mu.Unlock()
+
+	for _, alert := range alerts {
+		log.Printf("💧 Edge %s->%s is %.0f%% utilized (threshold %.0f%%)", alert.SourceID, alert.TargetID, alert.Utilization, alert.Threshold)
+		if a.wsHub != nil {
+			a.wsHub.BroadcastLiquidityAlert(alert)
+		}
+	}
+	return alerts
+}
+
+// Start runs the periodic check until the context is cancelled
+func (a *Advisor) Start(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.CheckInterval)
+	defer ticker.Stop()
+
+	log.Printf("💧 Liquidity advisor started (threshold: %.0f%%, check: %v)", a.cfg.Threshold, a.cfg.CheckInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("💧 Liquidity advisor stopped")
+			return
+		case <-ticker.C:
+			a.Check()
+		}
+	}
+}
+
+// used returns the liquidity drawn from an edge, never negative
+func used(e EdgeUtilization) int64 {
+	return max(e.Capacity-e.Available, 0)
+}
+
+// utilization returns the percent of an edge's capacity in use
+func utilization(e router.EdgeLiquidity) float64 {
+	if e.Capacity <= 0 {
+		return 0
+	}
+	return float64(max(e.Capacity-e.Available, 0)) / float64(e.Capacity) * 100
+}
+
+func edgeKey(source, target string) string {
+	return source + "->" + target
+}
@@ -0,0 +1,77 @@
+package analytics
+
+import (
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// hubGraph has two LPs feeding hub_primary, each edge holding 1000
+func hubGraph() *router.Graph {
+	g := router.NewGraph()
+	for _, id := range []string{"lp_alpha", "lp_beta", "hub_primary"} {
+		g.AddNode(&router.Node{ID: id, IsActive: true})
+	}
+	g.AddEdge(&router.Edge{SourceID: "lp_alpha", TargetID: "hub_primary", LiquidityVolume: 1000, IsActive: true})
+	g.AddEdge(&router.Edge{SourceID: "lp_beta", TargetID: "hub_primary", LiquidityVolume: 1000, IsActive: true})
+	return g
+}
+
+// TestRecommendationsMoveLiquidityToHotEdge verifies an edge over the threshold
+// is topped up from an underused edge into the same hub
+func TestRecommendationsMoveLiquidityToHotEdge(t *testing.T) {
+	g := hubGraph()
+	if _, err := g.ReservePath([]string{"lp_alpha", "hub_primary"}, 900); err != nil {
+		t.Fatalf("ReservePath failed: %v", err)
+	}
+	if _, err := g.ReservePath([]string{"lp_beta", "hub_primary"}, 100); err != nil {
+		t.Fatalf("ReservePath failed: %v", err)
+	}
+
+	advisor := NewAdvisor(g, nil, DefaultConfig())
+	report := advisor.Report()
+	if len(report.Edges) != 2 || report.Edges[0].SourceID != "lp_alpha" || report.Edges[0].Utilization != 90 {
+		t.Fatalf("edges = %+v, want lp_alpha first at 90%%", report.Edges)
+	}
+
+	// lp_alpha needs 900/0.5 - 1000 = 800 more; lp_beta can spare 1000 - 100/0.5 = 800
+	if len(report.Recommendations) != 1 {
+		t.Fatalf("recommendations = %+v, want 1", report.Recommendations)
+	}
+	rec := report.Recommendations[0]
+	if rec.FromSourceID != "lp_beta" || rec.ToSourceID != "lp_alpha" || rec.ToTargetID != "hub_primary" || rec.Amount != 800 {
+		t.Errorf("recommendation = %+v, want 800 from lp_beta to lp_alpha", rec)
+	}
+}
+
+// TestCheckAlertsOncePerCrossing verifies alerts fire when an edge crosses the
+// threshold and again only after it has dropped back below
+func TestCheckAlertsOncePerCrossing(t *testing.T) {
+	g := hubGraph()
+	advisor := NewAdvisor(g, nil, DefaultConfig())
+
+	if alerts := advisor.Check(); len(alerts) != 0 {
+		t.Fatalf("alerts on idle mesh = %+v", alerts)
+	}
+
+	res, err := g.ReservePath([]string{"lp_alpha", "hub_primary"}, 850)
+	if err != nil {
+		t.Fatalf("ReservePath failed: %v", err)
+	}
+	alerts := advisor.Check()
+	if len(alerts) != 1 || alerts[0].SourceID != "lp_alpha" || alerts[0].FromSourceID != "lp_beta" || alerts[0].Amount == 0 {
+		t.Fatalf("alerts = %+v, want one for lp_alpha with a suggestion from lp_beta", alerts)
+	}
+	if alerts := advisor.Check(); len(alerts) != 0 {
+		t.Errorf("repeat alerts = %+v, want none while still over", alerts)
+	}
+
+	res.Release()
+	advisor.Check()
+	if _, err := g.ReservePath([]string{"lp_alpha", "hub_primary"}, 850); err != nil {
+		t.Fatalf("ReservePath failed: %v", err)
+	}
+	if alerts := advisor.Check(); len(alerts) != 1 {
+		t.Errorf("alerts after second crossing = %+v, want 1", alerts)
+	}
+}
@@ -0,0 +1,255 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// MetadataDeadline carries the caller's deadline (Unix millis) with each
+// streamed request, since all requests on a stream share the stream's deadline
+const MetadataDeadline = "plm-deadline-ms"
+
+// forwardBackoff is the wait before the first retry; it doubles per attempt
+const forwardBackoff = 100 * time.Millisecond
+
+// ErrForwarderClosed is returned by Forward after Close
+var ErrForwarderClosed = errors.New("forwarder closed")
+
+// errStreamReset fails requests in flight on a stream that was torn down
+var errStreamReset = status.Error(codes.Unavailable, "settlement stream reset")
+
+// Forwarder forwards settlement hops to a peer node over a long-lived
+// StreamSettle stream, reopening the stream and retrying when it fails
+type Forwarder struct {
+	client *SettlementClient
+	cfg    *ClientConfig
+	conn   io.Closer // Set when the forwarder owns the connection
+
+	mu      sync.Mutex // guards the fields below and serializes Send
+	stream  *SettlementClientStream
+	cancel  context.CancelFunc
+	pending map[string]chan forwardResult
+	closed  bool
+}
+
+type forwardResult struct {
+	resp *SettleResponse
+	err  error
+}
+
+// DialForwarder connects to the peer at cfg.Address, over mTLS when the
+// client certificate, key and CA are configured
+func DialForwarder(ctx context.Context, cfg *ClientConfig) (*Forwarder, error) {
+	if cfg == nil {
+		cfg = DefaultClientConfig()
+	}
+	conn, err := NewClientConn(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to peer %s: %w", cfg.Address, err)
+	}
+	f := NewForwarder(conn, cfg)
+	f.conn = conn
+	return f, nil
+}
+
+// NewForwarder creates a forwarder over an existing connection
+func NewForwarder(cc grpc.ClientConnInterface, cfg *ClientConfig) *Forwarder {
+	if cfg == nil {
+		cfg = DefaultClientConfig()
+	}
+	return &Forwarder{
+		client:  NewSettlementClient(cc),
+		cfg:     cfg,
+		pending: make(map[string]chan forwardResult),
+	}
+}
+
+// Address returns the peer address
+func (f *Forwarder) Address() string {
+	return f.cfg.Address
+}
+
+// Forward settles req on the peer and waits for the result. Without a
+// deadline on ctx the call gets CallTimeout; the deadline travels with the
+// request so the peer gives up when the caller does. Transport failures are
+// retried up to MaxRetries times with exponential backoff on a fresh stream.
+// The peer settles a RequestID at most once, so a retry after a lost
+// response returns the original result.
+func (f *Forwarder) Forward(ctx context.Context, req *SettleRequest) (*SettleResponse, error) {
+	if _, ok := ctx.Deadline(); !ok && f.cfg.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.cfg.CallTimeout)
+		defer cancel()
+	}
+
+	out := *req
+	out.Metadata = make(map[string]string, len(req.Metadata)+1)
+	for k, v := range req.Metadata {
+		out.Metadata[k] = v
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		out.Metadata[MetadataDeadline] = strconv.FormatInt(deadline.UnixMilli(), 10)
+	}
+
+	var lastErr error
+	backoff := forwardBackoff
+	for attempt := 0; attempt <= f.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, fmt.Errorf("forward %s to %s: %w (last error: %v)", req.RequestID, f.cfg.Address, ctx.Err(), lastErr)
+			}
+			backoff *= 2
+		}
+
+		resp, err := f.send(ctx, &out)
+		if err == nil {
+			return resp, nil
+		}
+		if !retryable(err) {
+			return nil, fmt.Errorf("forward %s to %s: %w", req.RequestID, f.cfg.Address, err)
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("forward %s to %s: giving up after %d attempts: %w", req.RequestID, f.cfg.Address, f.cfg.MaxRetries+1, lastErr)
+}
+
+// send makes one attempt: queue the request on the stream and wait for its response
+func (f *Forwarder) send(ctx context.Context, req *SettleRequest) (*SettleResponse, error) {
+	ch := make(chan forwardResult, 1)
+
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil, ErrForwarderClosed
+	}
+	stream, err := f.streamLocked()
+	if err != nil {
+		f.mu.Unlock()
+		return nil, err
+	}
+	f.pending[req.RequestID] = ch
+	if err := stream.Send(req); err != nil {
+		// The stream is broken; its real status surfaces on Recv
+		f.resetLocked(stream, errStreamReset)
+		f.mu.Unlock()
+		return nil, errStreamReset
+	}
+	f.mu.Unlock()
+
+	select {
+	case res := <-ch:
+		return res.resp, res.err
+	case <-ctx.Done():
+		f.mu.Lock()
+		if f.pending[req.RequestID] == ch {
+			delete(f.pending, req.RequestID)
+		}
+		f.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// streamLocked returns the open stream, opening one if needed
+func (f *Forwarder) streamLocked() (*SettlementClientStream, error) {
+	if f.stream != nil {
+		return f.stream, nil
+	}
+
+	// The stream outlives individual calls; per-request deadlines travel in metadata
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := f.client.StreamSettle(ctx)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	f.stream, f.cancel = stream, cancel
+	go f.receive(stream)
+	return stream, nil
+}
+
+// receive dispatches responses to waiting callers until the stream fails
+func (f *Forwarder) receive(stream *SettlementClientStream) {
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				err = errStreamReset
+			}
+			f.mu.Lock()
+			f.resetLocked(stream, err)
+			f.mu.Unlock()
+			return
+		}
+
+		f.mu.Lock()
+		ch, ok := f.pending[resp.RequestID]
+		delete(f.pending, resp.RequestID)
+		f.mu.Unlock()
+		if ok {
+			ch <- forwardResult{resp: resp}
+		}
+	}
+}
+
+// resetLocked tears down stream if it is still current and fails the
+// requests waiting on it
+func (f *Forwarder) resetLocked(stream *SettlementClientStream, err error) {
+	if f.stream != stream {
+		return
+	}
+	f.cancel()
+	f.stream, f.cancel = nil, nil
+	for id, ch := range f.pending {
+		ch <- forwardResult{err: err}
+		delete(f.pending, id)
+	}
+}
+
+// Close fails requests in flight and closes the connection if the
+// forwarder opened it
+func (f *Forwarder) Close() error {
+	f.mu.Lock()
+	f.closed = true
+	if f.stream != nil {
+		f.resetLocked(f.stream, ErrForwarderClosed)
+	}
+	f.mu.Unlock()
+
+	if f.conn != nil {
+		return f.conn.Close()
+	}
+	return nil
+}
+
+// retryable reports whether a forward attempt failed in transport, before
+// the peer could answer
+func retryable(err error) bool {
+	if err == io.EOF {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// requestContext applies the deadline a forwarding peer sent with req
+func requestContext(ctx context.Context, req *SettleRequest) (context.Context, context.CancelFunc) {
+	ms, err := strconv.ParseInt(req.Metadata[MetadataDeadline], 10, 64)
+	if err != nil {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, time.UnixMilli(ms))
+}
@@ -0,0 +1,129 @@
+package grpc
+
+import (
+	"context"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/liveness"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyConn fails the first failures streams it is asked to open
+type flakyConn struct {
+	*grpc.ClientConn
+	failures int32
+	opened   atomic.Int32
+}
+
+func (c *flakyConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	if c.opened.Add(1) <= c.failures {
+		return nil, status.Error(codes.Unavailable, "connection refused")
+	}
+	return c.ClientConn.NewStream(ctx, desc, method, opts...)
+}
+
+// TestSettleForwardsHopsToPeer verifies hops from a node served by a peer are
+// settled by that peer and the results combined
+func TestSettleForwardsHopsToPeer(t *testing.T) {
+	peerSvc, peerStore, _ := newTestSettlementService()
+	forwarder := NewForwarder(dialSettlementServer(t, peerSvc), DefaultClientConfig())
+	defer forwarder.Close()
+
+	svc, store, _ := newTestSettlementService()
+	svc.SetPeer("B", forwarder)
+	client := startSettlementServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.Settle(ctx, &SettleRequest{RequestID: "req-1", SourceID: "A", Path: []string{"A", "B", "D"}, Amount: 10000})
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if resp.Status != SettlementStatusCompleted || !samePath(resp.ActualPath, []string{"A", "B", "D"}) {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	local, err := store.GetTransaction(resp.LedgerEntryID)
+	if err != nil || !samePath(local.Route, []string{"A", "B"}) {
+		t.Fatalf("expected local hop A->B, got %+v (%v)", local, err)
+	}
+	remote := peerStore.GetAllTransactions()
+	if len(remote) != 1 || !samePath(remote[0].Route, []string{"B", "D"}) {
+		t.Fatalf("expected the peer to settle B->D, got %+v", remote)
+	}
+}
+
+// TestForwardRetriesAndSettlesOnce verifies transport failures are retried on a
+// fresh stream and a repeated request ID is not settled twice
+func TestForwardRetriesAndSettlesOnce(t *testing.T) {
+	peerSvc, peerStore, _ := newTestSettlementService()
+	conn := &flakyConn{ClientConn: dialSettlementServer(t, peerSvc), failures: 2}
+	forwarder := NewForwarder(conn, &ClientConfig{CallTimeout: 5 * time.Second, MaxRetries: 3})
+	defer forwarder.Close()
+
+	req := &SettleRequest{RequestID: "hop-1", SourceID: "B", Path: []string{"B", "D"}, Amount: 5000}
+	first, err := forwarder.Forward(context.Background(), req)
+	if err != nil {
+		t.Fatalf("Forward failed: %v", err)
+	}
+	if first.Status != SettlementStatusCompleted || conn.opened.Load() != 3 {
+		t.Fatalf("expected completion on the third stream, got %+v after %d streams", first, conn.opened.Load())
+	}
+
+	again, err := forwarder.Forward(context.Background(), req)
+	if err != nil {
+		t.Fatalf("repeated Forward failed: %v", err)
+	}
+	if again.LedgerEntryID != first.LedgerEntryID || len(peerStore.GetAllTransactions()) != 1 {
+		t.Fatalf("expected the retry to replay %s, got %s with %d transactions",
+			first.LedgerEntryID, again.LedgerEntryID, len(peerStore.GetAllTransactions()))
+	}
+
+	failing := NewForwarder(&flakyConn{ClientConn: conn.ClientConn, failures: 10}, &ClientConfig{MaxRetries: 1})
+	if _, err := failing.Forward(context.Background(), req); status.Code(err) != codes.Unavailable {
+		t.Fatalf("expected Unavailable after retries, got %v", err)
+	}
+}
+
+// TestForwardedDeadlineBoundsSettlement verifies the deadline sent with a
+// streamed request stops its settlement
+func TestForwardedDeadlineBoundsSettlement(t *testing.T) {
+	svc, _, _ := newTestSettlementService()
+	expired := strconv.FormatInt(time.Now().Add(-time.Second).UnixMilli(), 10)
+
+	resp := svc.settleOnce(context.Background(), &SettleRequest{
+		RequestID: "late-1", SourceID: "A", DestinationID: "D", Amount: 1000,
+		Metadata: map[string]string{MetadataDeadline: expired},
+	})
+	if resp.Status != SettlementStatusFailed || resp.ErrorCode != ErrorCodeTimeout {
+		t.Fatalf("expected timeout, got %+v", resp)
+	}
+}
+
+// TestHeartbeaterKeepsLocalNodesAliveOnPeer verifies heartbeats reach the
+// peer's liveness tracker
+func TestHeartbeaterKeepsLocalNodesAliveOnPeer(t *testing.T) {
+	tracker := liveness.NewTracker(buildDiamondGraph(), nil, nil)
+	peerSvc := NewSettlementService(buildDiamondGraph(), tracker)
+	forwarder := NewForwarder(dialSettlementServer(t, peerSvc), DefaultClientConfig())
+	defer forwarder.Close()
+
+	h := NewHeartbeater(func() []string { return []string{"A", "B"} }, time.Second)
+	h.AddPeer(forwarder)
+	if acked := h.Beat(context.Background()); acked != 2 {
+		t.Fatalf("acked %d heartbeats, want 2", acked)
+	}
+	if !tracker.IsAlive("A") || !tracker.IsAlive("B") || tracker.IsAlive("C") {
+		t.Fatal("expected the peer to track A and B as alive")
+	}
+
+	h.RemovePeer(forwarder.Address())
+	if acked := h.Beat(context.Background()); acked != 0 {
+		t.Errorf("acked %d heartbeats after removing the peer, want 0", acked)
+	}
+}
@@ -0,0 +1,112 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Heartbeat reports a node as alive to the peer
+func (f *Forwarder) Heartbeat(ctx context.Context, nodeID string) (*HeartbeatResponse, error) {
+	if f.cfg.CallTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.cfg.CallTimeout)
+		defer cancel()
+	}
+	return f.client.Heartbeat(ctx, &HeartbeatRequest{NodeID: nodeID, Timestamp: time.Now().UnixMilli()})
+}
+
+// Heartbeater sends heartbeats for this instance's nodes to every peer
+// instance, so peers deactivate those nodes when this instance goes silent
+type Heartbeater struct {
+	nodes    func() []string
+	interval time.Duration
+
+	mu    sync.Mutex
+	peers map[string]*Forwarder // By address
+	// failing holds peers whose last heartbeat failed, so each outage is
+	// logged once
+	failing map[string]bool
+}
+
+// NewHeartbeater creates a heartbeater for the nodes returned by nodes
+func NewHeartbeater(nodes func() []string, interval time.Duration) *Heartbeater {
+	return &Heartbeater{
+		nodes:    nodes,
+		interval: interval,
+		peers:    make(map[string]*Forwarder),
+		failing:  make(map[string]bool),
+	}
+}
+
+// AddPeer starts sending heartbeats to a peer instance
+func (h *Heartbeater) AddPeer(f *Forwarder) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.peers[f.Address()] = f
+}
+
+// RemovePeer stops sending heartbeats to the peer at address
+func (h *Heartbeater) RemovePeer(address string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.peers, address)
+	delete(h.failing, address)
+}
+
+// Beat sends one heartbeat per node to every peer and returns how many were
+// acknowledged
+func (h *Heartbeater) Beat(ctx context.Context) int {
+	h.mu.Lock()
+	peers := make([]*Forwarder, 0, len(h.peers))
+	for _, f := range h.peers {
+		peers = append(peers, f)
+	}
+	h.mu.Unlock()
+
+	nodes := h.nodes()
+	acked := 0
+	for _, f := range peers {
+		var lastErr error
+		for _, nodeID := range nodes {
+			if _, err := f.Heartbeat(ctx, nodeID); err != nil {
+				lastErr = err
+				continue
+			}
+			acked++
+		}
+
+		h.mu.Lock()
+		wasFailing := h.failing[f.Address()]
+		if lastErr != nil {
+			h.failing[f.Address()] = true
+		} else {
+			delete(h.failing, f.Address())
+		}
+		h.mu.Unlock()
+
+		switch {
+		case lastErr != nil && !wasFailing:
+			log.Printf("⚠️ Heartbeats to peer %s failing: %v", f.Address(), lastErr)
+		case lastErr == nil && wasFailing:
+			log.Printf("💓 Heartbeats to peer %s recovered", f.Address())
+		}
+	}
+	return acked
+}
+
+// Start sends heartbeats every interval until the context is cancelled
+func (h *Heartbeater) Start(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Beat(ctx)
+		}
+	}
+}
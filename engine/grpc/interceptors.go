@@ -0,0 +1,141 @@
+package grpc
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Fully-qualified method names for the settlement service
+const (
+	MethodSettle        = "/plm.settlement.v1.SettlementService/Settle"
+	MethodStreamSettle  = "/plm.settlement.v1.SettlementService/StreamSettle"
+	MethodGetNodeStatus = "/plm.settlement.v1.SettlementService/GetNodeStatus"
+	MethodHeartbeat     = "/plm.settlement.v1.SettlementService/Heartbeat"
+)
+
+// WildcardIdentity allows any authenticated peer to call a method
+const WildcardIdentity = "*"
+
+// MethodACL maps a full method name to the peer identities allowed to call it.
+// Identities are the Common Name of the verified client certificate.
+type MethodACL map[string][]string
+
+// Authorizer checks peer identities against a per-method allow-list
+type Authorizer struct {
+	acl MethodACL
+}
+
+// NewAuthorizer creates an authorizer for the given ACL.
+// A nil ACL disables authorization (all callers allowed).
+func NewAuthorizer(acl MethodACL) *Authorizer {
+	return &Authorizer{acl: acl}
+}
+
+// Authorize returns a gRPC status error if identity may not call method
+func (a *Authorizer) Authorize(method, identity string) error {
+	if a == nil || a.acl == nil {
+		return nil
+	}
+
+	if identity == "" {
+		return status.Error(codes.Unauthenticated, "client certificate required")
+	}
+
+	allowed, ok := a.acl[method]
+	if !ok {
+		return status.Errorf(codes.PermissionDenied, "method %s is not permitted", method)
+	}
+
+	for _, id := range allowed {
+		if id == WildcardIdentity || id == identity {
+			return nil
+		}
+	}
+
+	return status.Errorf(codes.PermissionDenied, "identity %q is not authorized for %s", identity, method)
+}
+
+// PeerIdentity extracts the client certificate Common Name from the context.
+// Returns an empty string if the peer did not present a TLS certificate.
+func PeerIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.AuthInfo == nil {
+		return ""
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok {
+		return ""
+	}
+
+	// Prefer the verified chain; fall back to the raw peer certificate
+	if len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+		return tlsInfo.State.VerifiedChains[0][0].Subject.CommonName
+	}
+	if len(tlsInfo.State.PeerCertificates) > 0 {
+		return tlsInfo.State.PeerCertificates[0].Subject.CommonName
+	}
+
+	return ""
+}
+
+// UnaryAuthInterceptor authorizes unary RPCs against the allow-list
+func UnaryAuthInterceptor(authz *Authorizer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authz.Authorize(info.FullMethod, PeerIdentity(ctx)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor authorizes streaming RPCs against the allow-list
+func StreamAuthInterceptor(authz *Authorizer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authz.Authorize(info.FullMethod, PeerIdentity(ss.Context())); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// UnaryLoggingInterceptor logs method, peer, duration and status for unary RPCs
+func UnaryLoggingInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logRPC(info.FullMethod, PeerIdentity(ctx), time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamLoggingInterceptor logs method, peer, duration and status for streaming RPCs
+func StreamLoggingInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logRPC(info.FullMethod, PeerIdentity(ss.Context()), time.Since(start), err)
+		return err
+	}
+}
+
+// logRPC writes a single access log line for an RPC
+func logRPC(method, identity string, duration time.Duration, err error) {
+	if identity == "" {
+		identity = "anonymous"
+	}
+
+	code := status.Code(err)
+	if code == codes.OK {
+		log.Printf("📡 gRPC %s peer=%s code=%s duration=%v", method, identity, code, duration)
+		return
+	}
+	log.Printf("⚠️ gRPC %s peer=%s code=%s duration=%v error=%v", method, identity, code, duration, err)
+}
@@ -0,0 +1,67 @@
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// peerContext builds a context carrying a verified client certificate with the given CN
+func peerContext(commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{
+			State: tls.ConnectionState{
+				PeerCertificates: []*x509.Certificate{cert},
+				VerifiedChains:   [][]*x509.Certificate{{cert}},
+			},
+		},
+	})
+}
+
+// TestUnauthorizedIdentityDeniedSettle verifies an unlisted cert identity cannot call Settle
+func TestUnauthorizedIdentityDeniedSettle(t *testing.T) {
+	authz := NewAuthorizer(MethodACL{
+		MethodSettle:    {"hub-node-01"},
+		MethodHeartbeat: {WildcardIdentity},
+	})
+	interceptor := UnaryAuthInterceptor(authz)
+
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return &SettleResponse{}, nil
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: MethodSettle}
+
+	_, err := interceptor(peerContext("rogue-node-99"), &SettleRequest{}, info, handler)
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("Expected PermissionDenied, got %v", err)
+	}
+	if called {
+		t.Fatal("Handler must not run for an unauthorized identity")
+	}
+
+	// The allow-listed identity passes through
+	if _, err := interceptor(peerContext("hub-node-01"), &SettleRequest{}, info, handler); err != nil {
+		t.Fatalf("Expected authorized call to succeed, got %v", err)
+	}
+	if !called {
+		t.Fatal("Handler should run for an authorized identity")
+	}
+
+	// Wildcard methods accept any authenticated peer
+	hbInfo := &grpc.UnaryServerInfo{FullMethod: MethodHeartbeat}
+	if _, err := interceptor(peerContext("rogue-node-99"), &HeartbeatRequest{}, hbInfo, handler); err != nil {
+		t.Fatalf("Expected wildcard method to accept peer, got %v", err)
+	}
+}
@@ -36,6 +36,9 @@ type ServerConfig struct {
 	// Keepalive
 	KeepaliveTime    time.Duration
 	KeepaliveTimeout time.Duration
+
+	// Per-method authorization (nil = allow all callers)
+	MethodACL MethodACL
 }
 
 // DefaultServerConfig returns production-ready defaults
@@ -91,6 +94,19 @@ func NewServer(cfg *ServerConfig) (*Server, error) {
 		}),
 	)
 
+	// Interceptors: logging wraps auth so denied calls are still recorded
+	authz := NewAuthorizer(cfg.MethodACL)
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(
+			UnaryLoggingInterceptor(),
+			UnaryAuthInterceptor(authz),
+		),
+		grpc.ChainStreamInterceptor(
+			StreamLoggingInterceptor(),
+			StreamAuthInterceptor(authz),
+		),
+	)
+
 	return &Server{
 		cfg:        cfg,
 		grpcServer: grpc.NewServer(opts...),
@@ -0,0 +1,545 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/liveness"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ServiceVersion is reported in heartbeat responses
+const ServiceVersion = "1.0.0"
+
+// maxStreamInFlight bounds concurrent settlements per StreamSettle stream
+const maxStreamInFlight = 32
+
+// settledTTL is how long a settlement result is remembered by RequestID, so a
+// request retried by a forwarding peer is answered without settling twice
+const settledTTL = 10 * time.Minute
+
+// CircuitBreaker is the per-node breaker consulted before settling.
+// Hop outcomes are recorded on it by the TransactionStore.
+type CircuitBreaker = payments.CircuitBreaker
+
+// SettlementService implements SettlementServiceServer backed by the mesh graph
+type SettlementService struct {
+	graph    *router.Graph
+	liveness *liveness.Tracker
+	router   *router.Router
+	txns     payments.TransactionStorer
+	breaker  CircuitBreaker // Optional
+
+	peersMu sync.RWMutex
+	peers   map[string]*Forwarder // Nodes served by peer instances
+
+	callsMu   sync.Mutex
+	calls     map[string]*settleCall
+	lastPrune time.Time
+}
+
+// settleCall is a settlement in progress or recently finished
+type settleCall struct {
+	done chan struct{}
+	resp *SettleResponse
+	at   time.Time
+}
+
+// NewSettlementService creates a new settlement service
+func NewSettlementService(graph *router.Graph, tracker *liveness.Tracker) *SettlementService {
+	return &SettlementService{
+		graph:    graph,
+		liveness: tracker,
+		router:   router.NewRouter(graph, 3),
+		peers:    make(map[string]*Forwarder),
+		calls:    make(map[string]*settleCall),
+	}
+}
+
+// SetRouter sets the router used to find and reroute settlement paths
+func (s *SettlementService) SetRouter(r *router.Router) {
+	s.router = r
+}
+
+// SetTransactionStore sets the store settlements are recorded in (required for Settle)
+func (s *SettlementService) SetTransactionStore(store payments.TransactionStorer) {
+	s.txns = store
+}
+
+// SetCircuitBreaker enables per-node circuit breaking
+func (s *SettlementService) SetCircuitBreaker(cb CircuitBreaker) {
+	s.breaker = cb
+}
+
+// SetPeer routes hops from nodeID onwards to the peer instance serving it.
+// A nil forwarder makes the node local again.
+func (s *SettlementService) SetPeer(nodeID string, f *Forwarder) {
+	s.peersMu.Lock()
+	defer s.peersMu.Unlock()
+	if f == nil {
+		delete(s.peers, nodeID)
+		return
+	}
+	s.peers[nodeID] = f
+}
+
+// LocalNodes returns the active graph nodes not served by a peer instance,
+// sorted by ID
+func (s *SettlementService) LocalNodes() []string {
+	var ids []string
+	for _, node := range s.graph.GetAllNodes() {
+		if s.graph.IsNodeActive(node.ID) && s.peer(node.ID) == nil {
+			ids = append(ids, node.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// peer returns the forwarder for a node served by a peer instance
+func (s *SettlementService) peer(nodeID string) *Forwarder {
+	s.peersMu.RLock()
+	defer s.peersMu.RUnlock()
+	return s.peers[nodeID]
+}
+
+// Settle routes and settles a payment from SourceID to DestinationID.
+// Settlement failures are reported in the response; only invalid requests return an error.
+func (s *SettlementService) Settle(ctx context.Context, req *SettleRequest) (*SettleResponse, error) {
+	if err := validateSettleRequest(req); err != nil {
+		return nil, err
+	}
+	return s.settleOnce(ctx, req), nil
+}
+
+// StreamSettle processes settlements over a bidirectional stream.
+// Requests are settled concurrently, so responses may arrive out of order; match on RequestID.
+func (s *SettlementService) StreamSettle(stream SettlementStream) error {
+	ctx := stream.Context()
+
+	var (
+		wg      sync.WaitGroup
+		sendMu  sync.Mutex
+		sendErr error
+	)
+	send := func(resp *SettleResponse) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if sendErr == nil {
+			sendErr = stream.Send(resp)
+		}
+	}
+	slots := make(chan struct{}, maxStreamInFlight)
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			wg.Wait()
+			return err
+		}
+
+		if err := validateSettleRequest(req); err != nil {
+			send(&SettleResponse{
+				RequestID:    req.RequestID,
+				Status:       SettlementStatusFailed,
+				ErrorMessage: status.Convert(err).Message(),
+			})
+			continue
+		}
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		}
+		wg.Add(1)
+		go func(req *SettleRequest) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			send(s.settleOnce(ctx, req))
+		}(req)
+	}
+
+	wg.Wait()
+	sendMu.Lock()
+	defer sendMu.Unlock()
+	return sendErr
+}
+
+// validateSettleRequest checks required fields
+func validateSettleRequest(req *SettleRequest) error {
+	switch {
+	case req.RequestID == "":
+		return status.Error(codes.InvalidArgument, "request_id is required")
+	case req.SourceID == "":
+		return status.Error(codes.InvalidArgument, "source_id is required")
+	case settlementDestination(req) == "":
+		return status.Error(codes.InvalidArgument, "destination_id or path is required")
+	case req.Amount <= 0:
+		return status.Error(codes.InvalidArgument, "amount must be positive")
+	case len(req.Path) > 0 && req.Path[0] != req.SourceID:
+		return status.Error(codes.InvalidArgument, "path must start at source_id")
+	}
+	return nil
+}
+
+// settlementDestination returns the final node of a request
+func settlementDestination(req *SettleRequest) string {
+	if req.DestinationID != "" {
+		return req.DestinationID
+	}
+	if len(req.Path) > 0 {
+		return req.Path[len(req.Path)-1]
+	}
+	return req.TargetID
+}
+
+// settleOnce settles req unless its RequestID was already settled recently,
+// in which case the earlier result is returned (waiting for it if still in
+// progress). A deadline sent by a forwarding peer bounds the settlement.
+func (s *SettlementService) settleOnce(ctx context.Context, req *SettleRequest) *SettleResponse {
+	ctx, cancel := requestContext(ctx, req)
+	defer cancel()
+
+	s.callsMu.Lock()
+	if now := time.Now(); now.Sub(s.lastPrune) > time.Minute {
+		for id, c := range s.calls {
+			if c.resp != nil && now.Sub(c.at) > settledTTL {
+				delete(s.calls, id)
+			}
+		}
+		s.lastPrune = now
+	}
+	if c, ok := s.calls[req.RequestID]; ok {
+		s.callsMu.Unlock()
+		select {
+		case <-c.done:
+			return c.resp
+		case <-ctx.Done():
+			return &SettleResponse{
+				RequestID:    req.RequestID,
+				Status:       SettlementStatusFailed,
+				ErrorCode:    ErrorCodeTimeout,
+				ErrorMessage: ctx.Err().Error(),
+			}
+		}
+	}
+	c := &settleCall{done: make(chan struct{})}
+	s.calls[req.RequestID] = c
+	s.callsMu.Unlock()
+
+	resp := s.settle(ctx, req)
+
+	s.callsMu.Lock()
+	c.resp, c.at = resp, time.Now()
+	s.callsMu.Unlock()
+	close(c.done)
+	return resp
+}
+
+// settle picks a path that avoids inactive nodes and open circuits, then
+// runs the settlement through the transaction store while holding path load
+// and reserving liquidity on its edges.
+func (s *SettlementService) settle(ctx context.Context, req *SettleRequest) *SettleResponse {
+	start := time.Now()
+	resp := &SettleResponse{RequestID: req.RequestID, Status: SettlementStatusFailed}
+	fail := func(code ErrorCode, msg string) *SettleResponse {
+		resp.ErrorCode = code
+		resp.ErrorMessage = msg
+		resp.LatencyMs = time.Since(start).Milliseconds()
+		return resp
+	}
+
+	if s.txns == nil {
+		return fail(ErrorCodeInternal, "transaction store not configured")
+	}
+	if err := ctx.Err(); err != nil {
+		// Typically a forwarding peer's deadline that passed in transit
+		return fail(ErrorCodeTimeout, err.Error())
+	}
+
+	candidates := s.candidatePaths(ctx, req)
+	if len(candidates) == 0 {
+		return fail(ErrorCodePathNotFound, fmt.Sprintf("no path from %s to %s", req.SourceID, settlementDestination(req)))
+	}
+
+	path, blockedBy, illiquid := s.selectPath(ctx, candidates, req.Amount)
+	if path == nil {
+		if blockedBy != "" {
+			return fail(ErrorCodeCircuitOpen, fmt.Sprintf("circuit open for node %s", blockedBy))
+		}
+		if illiquid {
+			return fail(ErrorCodeInsufficientLiquidity, fmt.Sprintf("no path with %d available liquidity", req.Amount))
+		}
+		return fail(ErrorCodeNodeUnavailable, "no path with all nodes active")
+	}
+	resp.ActualPath = path
+
+	// Hops from the first node served by a peer onwards are settled there
+	local, peer := s.splitAtPeer(path)
+	if len(local) > 1 {
+		txnID, code, err := s.settlePath(ctx, req, local)
+		resp.LedgerEntryID = txnID
+		if err != nil {
+			return fail(code, err.Error())
+		}
+		if final, err := s.txns.GetTransaction(txnID); err == nil && final.Amount > 0 {
+			resp.TotalFeeBps = int64(math.Round(final.TotalFees / final.Amount * 10000))
+		}
+	}
+
+	rerouted := len(req.Path) > 0 && !samePath(req.Path, path)
+	if peer != nil {
+		// Hops already settled here are not reversed if the peer fails
+		remote, err := s.forward(ctx, peer, req, path, len(local)-1)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fail(ErrorCodeTimeout, err.Error())
+			}
+			return fail(ErrorCodeNodeUnavailable, err.Error())
+		}
+		if remote.Status == SettlementStatusFailed {
+			return fail(remote.ErrorCode, fmt.Sprintf("peer %s: %s", peer.Address(), remote.ErrorMessage))
+		}
+		resp.ActualPath = append(append([]string{}, local[:len(local)-1]...), remote.ActualPath...)
+		resp.TotalFeeBps += remote.TotalFeeBps
+		if resp.LedgerEntryID == "" {
+			resp.LedgerEntryID = remote.LedgerEntryID
+		}
+		rerouted = rerouted || remote.Status == SettlementStatusRerouted
+	}
+
+	resp.Status = SettlementStatusCompleted
+	if rerouted {
+		resp.Status = SettlementStatusRerouted
+	}
+	resp.LatencyMs = time.Since(start).Milliseconds()
+	resp.CompletedAt = time.Now().UnixMilli()
+	return resp
+}
+
+// splitAtPeer splits path at the first node (other than the destination)
+// served by a peer instance, returning the local part up to and including
+// that node and the peer's forwarder. Without peers the whole path is local.
+func (s *SettlementService) splitAtPeer(path []string) ([]string, *Forwarder) {
+	for i, nodeID := range path[:len(path)-1] {
+		if f := s.peer(nodeID); f != nil {
+			return path[:i+1], f
+		}
+	}
+	return path, nil
+}
+
+// settlePath runs a settlement along path through the transaction store while
+// holding path load and reserving liquidity on its edges. It returns the
+// transaction ID (once created) and, on failure, the error code to report.
+func (s *SettlementService) settlePath(ctx context.Context, req *SettleRequest, path []string) (string, ErrorCode, error) {
+	if lt := s.graph.LoadTracker(); lt != nil {
+		release := lt.AcquirePath(path)
+		defer release()
+	}
+
+	// Hold liquidity along the path; committed on success, released otherwise
+	reservation, err := s.graph.ReservePath(path, req.Amount)
+	if err != nil {
+		if errors.Is(err, router.ErrInsufficientLiquidity) {
+			return "", ErrorCodeInsufficientLiquidity, err
+		}
+		return "", ErrorCodePathNotFound, err
+	}
+	defer reservation.Release()
+
+	currency := req.Metadata["currency"]
+	if currency == "" {
+		currency = "USD"
+	}
+	targetCurrency := req.Metadata["target_currency"]
+	if targetCurrency == "" {
+		targetCurrency = currency
+	}
+
+	// Amount is in the smallest currency unit; the store works in major units
+	txn, err := s.txns.CreateTransaction("node:"+req.SourceID, float64(req.Amount)/100, currency, targetCurrency, path, nil)
+	if err != nil {
+		return "", ErrorCodeInternal, fmt.Errorf("failed to create transaction: %w", err)
+	}
+
+	if err := s.txns.ProcessTransaction(ctx, txn.ID, nil, 0); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return txn.ID, ErrorCodeTimeout, err
+		}
+		return txn.ID, ErrorCodeNodeUnavailable, err
+	}
+
+	reservation.Commit()
+	return txn.ID, ErrorCodeUnspecified, nil
+}
+
+// forward hands the hops of path from index onwards to a peer. The hop gets
+// its own request ID so a path that passes through this instance again is
+// not mistaken for a retry.
+func (s *SettlementService) forward(ctx context.Context, peer *Forwarder, req *SettleRequest, path []string, index int) (*SettleResponse, error) {
+	hop := int(req.HopIndex) + index
+	return peer.Forward(ctx, &SettleRequest{
+		RequestID:     fmt.Sprintf("%s@%d", req.RequestID, hop),
+		SourceID:      path[index],
+		TargetID:      path[index+1],
+		DestinationID: path[len(path)-1],
+		Amount:        req.Amount,
+		Path:          path[index:],
+		HopIndex:      int32(hop),
+		Signature:     req.Signature,
+		Timestamp:     req.Timestamp,
+		Priority:      req.Priority,
+		Metadata:      req.Metadata,
+	})
+}
+
+// candidatePaths returns the requested path (if any) followed by router alternatives
+func (s *SettlementService) candidatePaths(ctx context.Context, req *SettleRequest) [][]string {
+	var candidates [][]string
+	if len(req.Path) > 1 {
+		candidates = append(candidates, req.Path)
+	}
+
+	if s.router == nil {
+		return candidates
+	}
+	paths, err := s.router.FindKShortestPaths(ctx, req.SourceID, settlementDestination(req), req.Amount)
+	if err != nil {
+		return candidates
+	}
+	for _, p := range paths {
+		candidates = append(candidates, p.Nodes)
+	}
+	return candidates
+}
+
+// selectPath returns the first candidate whose nodes are all active with closed
+// (or half-open) circuits and whose edges have liquidity for amount. blockedBy
+// names an open-circuit node if one was hit; illiquid reports whether a
+// candidate was skipped for lack of liquidity.
+func (s *SettlementService) selectPath(ctx context.Context, candidates [][]string, amount int64) (path []string, blockedBy string, illiquid bool) {
+next:
+	for _, candidate := range candidates {
+		for _, nodeID := range candidate {
+			if !s.graph.IsNodeActive(nodeID) {
+				continue next
+			}
+			if s.breaker == nil {
+				continue
+			}
+			err := s.breaker.Allow(ctx, redisClient.DefaultCircuitBreakerConfig(nodeID))
+			if errors.Is(err, redisClient.ErrCircuitOpen) {
+				if blockedBy == "" {
+					blockedBy = nodeID
+				}
+				continue next
+			}
+			if err != nil {
+				// Breaker backend unavailable: fail open rather than halting settlement
+				log.Printf("⚠️ Circuit check for %s failed: %v", nodeID, err)
+			}
+		}
+		if !s.graph.PathHasCapacity(candidate, amount) {
+			illiquid = true
+			continue
+		}
+		return candidate, "", false
+	}
+	return nil, blockedBy, illiquid
+}
+
+// samePath reports whether two node paths are identical
+func samePath(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GetNodeStatus returns the current status of a node
+func (s *SettlementService) GetNodeStatus(ctx context.Context, req *NodeStatusRequest) (*NodeStatusResponse, error) {
+	if req.NodeID == "" {
+		return nil, status.Error(codes.InvalidArgument, "node_id is required")
+	}
+
+	node := s.graph.GetNode(req.NodeID)
+	if node == nil {
+		return nil, status.Errorf(codes.NotFound, "node %s not found", req.NodeID)
+	}
+
+	resp := &NodeStatusResponse{
+		NodeID:    req.NodeID,
+		IsActive:  s.graph.IsNodeActive(req.NodeID),
+		Timestamp: time.Now().UnixMilli(),
+	}
+
+	// In-flight settlements traversing this node
+	if lt := s.graph.LoadTracker(); lt != nil {
+		resp.CurrentLoad = lt.Load(req.NodeID)
+		resp.PendingSettlements = resp.CurrentLoad
+	}
+
+	if s.breaker != nil {
+		resp.CircuitState = CircuitStateClosed
+		if cs, err := s.breaker.GetState(ctx, redisClient.DefaultCircuitBreakerConfig(req.NodeID)); err == nil {
+			resp.CircuitState = circuitStateFromRedis(cs.State)
+		}
+	}
+
+	return resp, nil
+}
+
+// Heartbeat records node liveness
+func (s *SettlementService) Heartbeat(ctx context.Context, req *HeartbeatRequest) (*HeartbeatResponse, error) {
+	if req.NodeID == "" {
+		return nil, status.Error(codes.InvalidArgument, "node_id is required")
+	}
+
+	if s.liveness != nil {
+		s.liveness.RecordHeartbeat(req.NodeID)
+	}
+
+	return &HeartbeatResponse{
+		NodeID:    req.NodeID,
+		Healthy:   s.graph.IsNodeActive(req.NodeID),
+		Timestamp: time.Now().UnixMilli(),
+		Version:   ServiceVersion,
+	}, nil
+}
+
+// circuitStateFromRedis maps breaker states to the wire enum
+func circuitStateFromRedis(state redisClient.State) CircuitState {
+	switch state {
+	case redisClient.StateOpen:
+		return CircuitStateOpen
+	case redisClient.StateHalfOpen:
+		return CircuitStateHalfOpen
+	default:
+		return CircuitStateClosed
+	}
+}
+
+// Compile-time interface check
+var _ SettlementServiceServer = (*SettlementService)(nil)
@@ -0,0 +1,178 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// CodecName is the content-subtype the settlement service is served with.
+// Messages are the plain Go structs in server.go encoded as JSON until
+// protoc-generated stubs for proto/settlement.proto are checked in.
+const CodecName = "json"
+
+// jsonCodec marshals settlement messages as JSON
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return CodecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// SettlementServiceDesc describes plm.settlement.v1.SettlementService for registration
+var SettlementServiceDesc = grpc.ServiceDesc{
+	ServiceName: "plm.settlement.v1.SettlementService",
+	HandlerType: (*SettlementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Settle", Handler: settleHandler},
+		{MethodName: "GetNodeStatus", Handler: getNodeStatusHandler},
+		{MethodName: "Heartbeat", Handler: heartbeatHandler},
+	},
+	Streams: []grpc.StreamDesc{
+		{StreamName: "StreamSettle", Handler: streamSettleHandler, ServerStreams: true, ClientStreams: true},
+	},
+	Metadata: "proto/settlement.proto",
+}
+
+// RegisterSettlementServiceServer registers the settlement service on a gRPC server
+func RegisterSettlementServiceServer(s grpc.ServiceRegistrar, srv SettlementServiceServer) {
+	s.RegisterService(&SettlementServiceDesc, srv)
+}
+
+func settleHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SettleRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SettlementServiceServer).Settle(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: MethodSettle}
+	return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SettlementServiceServer).Settle(ctx, req.(*SettleRequest))
+	})
+}
+
+func getNodeStatusHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NodeStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SettlementServiceServer).GetNodeStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: MethodGetNodeStatus}
+	return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SettlementServiceServer).GetNodeStatus(ctx, req.(*NodeStatusRequest))
+	})
+}
+
+func heartbeatHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SettlementServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: MethodHeartbeat}
+	return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SettlementServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	})
+}
+
+func streamSettleHandler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SettlementServiceServer).StreamSettle(&settlementServerStream{stream})
+}
+
+// settlementServerStream adapts grpc.ServerStream to SettlementStream
+type settlementServerStream struct {
+	grpc.ServerStream
+}
+
+func (s *settlementServerStream) Send(resp *SettleResponse) error {
+	return s.ServerStream.SendMsg(resp)
+}
+
+func (s *settlementServerStream) Recv() (*SettleRequest, error) {
+	req := new(SettleRequest)
+	if err := s.ServerStream.RecvMsg(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// SettlementClient calls the settlement service on a peer node
+type SettlementClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewSettlementClient creates a client over an existing connection (see NewClientConn)
+func NewSettlementClient(cc grpc.ClientConnInterface) *SettlementClient {
+	return &SettlementClient{cc: cc}
+}
+
+// Settle settles a single payment on the peer
+func (c *SettlementClient) Settle(ctx context.Context, req *SettleRequest, opts ...grpc.CallOption) (*SettleResponse, error) {
+	out := new(SettleResponse)
+	if err := c.cc.Invoke(ctx, MethodSettle, req, out, c.callOptions(opts)...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetNodeStatus queries a node's status on the peer
+func (c *SettlementClient) GetNodeStatus(ctx context.Context, req *NodeStatusRequest, opts ...grpc.CallOption) (*NodeStatusResponse, error) {
+	out := new(NodeStatusResponse)
+	if err := c.cc.Invoke(ctx, MethodGetNodeStatus, req, out, c.callOptions(opts)...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Heartbeat reports liveness to the peer
+func (c *SettlementClient) Heartbeat(ctx context.Context, req *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	out := new(HeartbeatResponse)
+	if err := c.cc.Invoke(ctx, MethodHeartbeat, req, out, c.callOptions(opts)...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamSettle opens a bidirectional settlement stream
+func (c *SettlementClient) StreamSettle(ctx context.Context, opts ...grpc.CallOption) (*SettlementClientStream, error) {
+	stream, err := c.cc.NewStream(ctx, &SettlementServiceDesc.Streams[0], MethodStreamSettle, c.callOptions(opts)...)
+	if err != nil {
+		return nil, err
+	}
+	return &SettlementClientStream{stream}, nil
+}
+
+// callOptions selects the JSON codec ahead of caller options
+func (c *SettlementClient) callOptions(opts []grpc.CallOption) []grpc.CallOption {
+	return append([]grpc.CallOption{grpc.CallContentSubtype(CodecName)}, opts...)
+}
+
+// SettlementClientStream is the client side of StreamSettle
+type SettlementClientStream struct {
+	grpc.ClientStream
+}
+
+// Send queues a settlement request
+func (s *SettlementClientStream) Send(req *SettleRequest) error {
+	return s.ClientStream.SendMsg(req)
+}
+
+// Recv waits for the next settlement result
+func (s *SettlementClientStream) Recv() (*SettleResponse, error) {
+	resp := new(SettleResponse)
+	if err := s.ClientStream.RecvMsg(resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
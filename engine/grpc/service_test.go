@@ -0,0 +1,69 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// buildDiamondGraph builds A -> {B, C} -> D with identical edge costs
+func buildDiamondGraph() *router.Graph {
+	graph := router.NewGraph()
+	for _, id := range []string{"A", "B", "C", "D"} {
+		graph.AddNode(&router.Node{ID: id, Type: "Hub", IsActive: true})
+	}
+	graph.AddEdge(&router.Edge{SourceID: "A", TargetID: "B", BaseFee: 0.001, Latency: 10, IsActive: true})
+	graph.AddEdge(&router.Edge{SourceID: "A", TargetID: "C", BaseFee: 0.001, Latency: 10, IsActive: true})
+	graph.AddEdge(&router.Edge{SourceID: "B", TargetID: "D", BaseFee: 0.001, Latency: 10, IsActive: true})
+	graph.AddEdge(&router.Edge{SourceID: "C", TargetID: "D", BaseFee: 0.001, Latency: 10, IsActive: true})
+	return graph
+}
+
+// TestNodeLoadReportedAndDeprioritized verifies load shows in NodeStatus and steers routing
+func TestNodeLoadReportedAndDeprioritized(t *testing.T) {
+	graph := buildDiamondGraph()
+	load := router.NewLoadTracker()
+	graph.SetLoadTracker(load, 0.001)
+
+	// Simulate 5 in-flight settlements through B
+	var releases []func()
+	for i := 0; i < 5; i++ {
+		releases = append(releases, load.AcquirePath([]string{"A", "B", "D"}))
+	}
+
+	svc := NewSettlementService(graph, nil)
+	ctx := context.Background()
+
+	statusB, err := svc.GetNodeStatus(ctx, &NodeStatusRequest{NodeID: "B"})
+	if err != nil {
+		t.Fatalf("GetNodeStatus(B) failed: %v", err)
+	}
+	statusC, err := svc.GetNodeStatus(ctx, &NodeStatusRequest{NodeID: "C"})
+	if err != nil {
+		t.Fatalf("GetNodeStatus(C) failed: %v", err)
+	}
+	if statusB.CurrentLoad != 5 || statusC.CurrentLoad != 0 {
+		t.Fatalf("Expected load B=5 C=0, got B=%d C=%d", statusB.CurrentLoad, statusC.CurrentLoad)
+	}
+
+	paths, err := router.NewRouter(graph, 2).FindKShortestPaths(ctx, "A", "D", 0)
+	if err != nil {
+		t.Fatalf("FindKShortestPaths failed: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("Expected a path from A to D")
+	}
+	if paths[0].Nodes[1] != "C" {
+		t.Fatalf("Expected loaded hub B to be deprioritized, best path: %v", paths[0].Nodes)
+	}
+
+	// Load drains after release
+	for _, release := range releases {
+		release()
+	}
+	statusB, _ = svc.GetNodeStatus(ctx, &NodeStatusRequest{NodeID: "B"})
+	if statusB.CurrentLoad != 0 {
+		t.Errorf("Expected load to drain to 0, got %d", statusB.CurrentLoad)
+	}
+}
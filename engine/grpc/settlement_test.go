@@ -0,0 +1,229 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeBreaker is an in-memory per-node circuit breaker
+type fakeBreaker struct {
+	mu       sync.Mutex
+	states   map[string]redisClient.State
+	failures map[string]int
+}
+
+func newFakeBreaker() *fakeBreaker {
+	return &fakeBreaker{states: make(map[string]redisClient.State), failures: make(map[string]int)}
+}
+
+func (f *fakeBreaker) GetState(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) (*redisClient.CircuitState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &redisClient.CircuitState{State: f.states[cfg.Name]}, nil
+}
+
+func (f *fakeBreaker) Allow(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.states[cfg.Name] == redisClient.StateOpen {
+		return redisClient.ErrCircuitOpen
+	}
+	return nil
+}
+
+func (f *fakeBreaker) RecordSuccess(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	return nil
+}
+
+func (f *fakeBreaker) RecordFailure(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[cfg.Name]++
+	return nil
+}
+
+func (f *fakeBreaker) open(nodeID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.states[nodeID] = redisClient.StateOpen
+}
+
+// startSettlementServer serves the settlement service over an in-memory listener
+func startSettlementServer(t *testing.T, svc *SettlementService) *SettlementClient {
+	t.Helper()
+	return NewSettlementClient(dialSettlementServer(t, svc))
+}
+
+// dialSettlementServer serves svc over an in-memory listener and connects to it
+func dialSettlementServer(t *testing.T, svc *SettlementService) *grpc.ClientConn {
+	t.Helper()
+
+	srv, err := NewServer(DefaultServerConfig())
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	RegisterSettlementServiceServer(srv.GRPCServer(), svc)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.GRPCServer().Serve(lis)
+	t.Cleanup(srv.GRPCServer().Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("dial failed: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// newTestSettlementService wires the diamond graph to a store, load tracker and breaker
+func newTestSettlementService() (*SettlementService, *payments.TransactionStore, *fakeBreaker) {
+	graph := buildDiamondGraph()
+	graph.SetLoadTracker(router.NewLoadTracker(), 0.001)
+
+	store := payments.NewTransactionStore()
+	breaker := newFakeBreaker()
+
+	svc := NewSettlementService(graph, nil)
+	svc.SetTransactionStore(store)
+	svc.SetCircuitBreaker(breaker)
+	return svc, store, breaker
+}
+
+// TestSettleOverGRPC verifies a settlement is routed, recorded and released end to end
+func TestSettleOverGRPC(t *testing.T) {
+	svc, store, _ := newTestSettlementService()
+	client := startSettlementServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	resp, err := client.Settle(ctx, &SettleRequest{RequestID: "req-1", SourceID: "A", DestinationID: "D", Amount: 10000})
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if resp.Status != SettlementStatusCompleted || len(resp.ActualPath) != 3 || resp.TotalFeeBps <= 0 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	txn, err := store.GetTransaction(resp.LedgerEntryID)
+	if err != nil {
+		t.Fatalf("ledger entry %q not recorded: %v", resp.LedgerEntryID, err)
+	}
+	if txn.Status != payments.StatusSuccess || txn.Amount != 100 {
+		t.Fatalf("unexpected transaction: status=%s amount=%v", txn.Status, txn.Amount)
+	}
+
+	// Path load is held while the settlement runs and released once it completes
+	tracked := svc.graph.LoadTracker().Snapshot()
+	for _, nodeID := range resp.ActualPath {
+		if load, ok := tracked[nodeID]; !ok {
+			t.Errorf("node %s never had the settlement's load", nodeID)
+		} else if load != 0 {
+			t.Errorf("node %s still has load %d", nodeID, load)
+		}
+	}
+
+	if _, err := client.Settle(ctx, &SettleRequest{RequestID: "req-2", SourceID: "A", DestinationID: "D"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for zero amount, got %v", err)
+	}
+}
+
+// TestSettleAvoidsOpenCircuits verifies open circuits reroute and are reported in NodeStatus
+func TestSettleAvoidsOpenCircuits(t *testing.T) {
+	svc, _, breaker := newTestSettlementService()
+	client := startSettlementServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	breaker.open("B")
+
+	resp, err := client.Settle(ctx, &SettleRequest{RequestID: "req-1", SourceID: "A", Path: []string{"A", "B", "D"}, Amount: 5000})
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if resp.Status != SettlementStatusRerouted || resp.ActualPath[1] != "C" {
+		t.Fatalf("expected reroute via C, got %+v", resp)
+	}
+
+	nodeStatus, err := client.GetNodeStatus(ctx, &NodeStatusRequest{NodeID: "B"})
+	if err != nil {
+		t.Fatalf("GetNodeStatus failed: %v", err)
+	}
+	if nodeStatus.CircuitState != CircuitStateOpen {
+		t.Fatalf("expected B circuit open, got %v", nodeStatus.CircuitState)
+	}
+
+	breaker.open("C")
+	resp, err = client.Settle(ctx, &SettleRequest{RequestID: "req-2", SourceID: "A", DestinationID: "D", Amount: 5000})
+	if err != nil {
+		t.Fatalf("Settle failed: %v", err)
+	}
+	if resp.Status != SettlementStatusFailed || resp.ErrorCode != ErrorCodeCircuitOpen {
+		t.Fatalf("expected circuit-open failure, got %+v", resp)
+	}
+}
+
+// TestStreamSettle verifies every streamed request gets a response, including invalid ones
+func TestStreamSettle(t *testing.T) {
+	svc, _, _ := newTestSettlementService()
+	client := startSettlementServer(t, svc)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.StreamSettle(ctx)
+	if err != nil {
+		t.Fatalf("StreamSettle failed: %v", err)
+	}
+
+	requests := []*SettleRequest{
+		{RequestID: "s-1", SourceID: "A", DestinationID: "D", Amount: 1000},
+		{RequestID: "s-2", SourceID: "B", DestinationID: "D", Amount: 2000},
+		{RequestID: "s-3", SourceID: "A", DestinationID: "D"}, // Invalid: no amount
+	}
+	for _, req := range requests {
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("Send failed: %v", err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend failed: %v", err)
+	}
+
+	results := make(map[string]*SettleResponse)
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv failed: %v", err)
+		}
+		results[resp.RequestID] = resp
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 responses, got %d", len(results))
+	}
+	if results["s-1"].Status != SettlementStatusCompleted || results["s-2"].Status != SettlementStatusCompleted {
+		t.Fatalf("expected s-1 and s-2 to complete: %+v %+v", results["s-1"], results["s-2"])
+	}
+	if results["s-3"].Status != SettlementStatusFailed {
+		t.Fatalf("expected s-3 to fail validation: %+v", results["s-3"])
+	}
+}
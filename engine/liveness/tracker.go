@@ -0,0 +1,230 @@
+// Package liveness tracks node heartbeats and toggles node availability in the routing graph.
+// Nodes that stop heartbeating are marked inactive so the router avoids them.
+package liveness
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+	"github.com/plm/predictive-liquidity-mesh/websocket"
+)
+
+// NodeStore persists node availability (e.g. the Neo4j graph)
+type NodeStore interface {
+	SetNodeActive(ctx context.Context, nodeID string, isActive bool) error
+}
+
+// CircuitBreaker is opened for nodes that go silent and reset when they resume
+type CircuitBreaker interface {
+	ForceOpen(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error
+	Reset(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error
+}
+
+// Config holds liveness tracker configuration
+type Config struct {
+	// Timeout after which a silent node is marked inactive
+	Timeout time.Duration
+	// SweepInterval is how often the background sweep runs
+	SweepInterval time.Duration
+	// Now returns the current time (overridable for tests)
+	Now func() time.Time
+}
+
+// DefaultConfig returns sensible defaults: nodes heartbeat every 5s and are
+// deactivated after missing 3
+func DefaultConfig() *Config {
+	return IntervalConfig(5*time.Second, 3)
+}
+
+// IntervalConfig returns a configuration for nodes heartbeating every
+// interval, deactivated after missing the given number of heartbeats
+func IntervalConfig(interval time.Duration, missed int) *Config {
+	return &Config{
+		Timeout:       interval * time.Duration(missed),
+		SweepInterval: interval,
+		Now:           time.Now,
+	}
+}
+
+// Tracker records the last heartbeat per node and deactivates silent nodes
+type Tracker struct {
+	graph *router.Graph
+	wsHub *websocket.Hub
+	cfg   *Config
+
+	store   NodeStore      // Optional
+	breaker CircuitBreaker // Optional
+
+	mu            sync.RWMutex
+	lastHeartbeat map[string]time.Time
+	// deactivated holds nodes this tracker marked inactive, so it only
+	// reactivates nodes it took down (not ones killed via chaos endpoints)
+	deactivated map[string]bool
+}
+
+// NewTracker creates a new liveness tracker
+func NewTracker(graph *router.Graph, wsHub *websocket.Hub, cfg *Config) *Tracker {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return &Tracker{
+		graph:         graph,
+		wsHub:         wsHub,
+		cfg:           cfg,
+		lastHeartbeat: make(map[string]time.Time),
+		deactivated:   make(map[string]bool),
+	}
+}
+
+// SetNodeStore persists liveness changes to a node store
+func (t *Tracker) SetNodeStore(store NodeStore) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = store
+}
+
+// SetCircuitBreaker opens silent nodes' circuits and resets them on resume
+func (t *Tracker) SetCircuitBreaker(cb CircuitBreaker) {
+	t.breaker = cb
+}
+
+// RecordHeartbeat records a heartbeat and reactivates the node if the tracker had deactivated it
+func (t *Tracker) RecordHeartbeat(nodeID string) {
+	t.mu.Lock()
+	t.lastHeartbeat[nodeID] = t.cfg.Now()
+	wasDown := t.deactivated[nodeID]
+	delete(t.deactivated, nodeID)
+	t.mu.Unlock()
+
+	if wasDown {
+		t.setActive(nodeID, true)
+		log.Printf("💚 Node %s resumed heartbeats, reactivated", nodeID)
+	}
+}
+
+// LastHeartbeat returns the last heartbeat time for a node
+func (t *Tracker) LastHeartbeat(nodeID string) (time.Time, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ts, ok := t.lastHeartbeat[nodeID]
+	return ts, ok
+}
+
+// IsAlive reports whether the node has heartbeated within the timeout
+func (t *Tracker) IsAlive(nodeID string) bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ts, ok := t.lastHeartbeat[nodeID]
+	return ok && t.cfg.Now().Sub(ts) <= t.cfg.Timeout
+}
+
+// Sweep marks nodes inactive whose last heartbeat is older than the timeout.
+// Only nodes that have heartbeated at least once are tracked.
+func (t *Tracker) Sweep() []string {
+	now := t.cfg.Now()
+
+	t.mu.Lock()
+	var expired []string
+	for nodeID, ts := range t.lastHeartbeat {
+		if t.deactivated[nodeID] {
+			continue
+		}
+		if now.Sub(ts) > t.cfg.Timeout {
+			t.deactivated[nodeID] = true
+			expired = append(expired, nodeID)
+		}
+	}
+	t.mu.Unlock()
+
+	for _, nodeID := range expired {
+		t.setActive(nodeID, false)
+		log.Printf("⚠️ Node %s missed heartbeats (timeout %v), deactivated", nodeID, t.cfg.Timeout)
+	}
+
+	return expired
+}
+
+// Start runs the periodic sweep until the context is cancelled
+func (t *Tracker) Start(ctx context.Context) {
+	ticker := time.NewTicker(t.cfg.SweepInterval)
+	defer ticker.Stop()
+
+	log.Printf("💓 Liveness tracker started (timeout: %v, sweep: %v)", t.cfg.Timeout, t.cfg.SweepInterval)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("💓 Liveness tracker stopped")
+			return
+		case <-ticker.C:
+			t.Sweep()
+		}
+	}
+}
+
+// setActive applies a liveness change to the graph, node store and circuit
+// breaker, then notifies WebSocket clients. Store and breaker failures are
+// logged; the graph change stands regardless.
+func (t *Tracker) setActive(nodeID string, isActive bool) {
+	if isActive {
+		t.graph.SetNodeActive(nodeID)
+	} else {
+		t.graph.SetNodeInactive(nodeID)
+	}
+
+	t.mu.RLock()
+	store := t.store
+	t.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if store != nil {
+		if err := store.SetNodeActive(ctx, nodeID, isActive); err != nil {
+			log.Printf("⚠️ Failed to persist liveness of %s: %v", nodeID, err)
+		}
+	}
+	if t.breaker != nil {
+		cfg := redisClient.DefaultCircuitBreakerConfig(nodeID)
+		var err error
+		if isActive {
+			err = t.breaker.Reset(ctx, cfg)
+		} else {
+			err = t.breaker.ForceOpen(ctx, cfg)
+		}
+		if err != nil {
+			log.Printf("⚠️ Failed to update circuit for %s: %v", nodeID, err)
+		}
+	}
+
+	t.broadcast(nodeID, isActive)
+}
+
+// broadcast notifies WebSocket clients of a liveness change
+func (t *Tracker) broadcast(nodeID string, isActive bool) {
+	if t.wsHub == nil {
+		return
+	}
+	t.wsHub.BroadcastNodeStatus(&websocket.NodeStatusUpdate{
+		NodeID:   nodeID,
+		IsActive: isActive,
+	})
+	if t.breaker != nil {
+		state, prev := "open", "closed"
+		if isActive {
+			state, prev = "closed", "open"
+		}
+		t.wsHub.BroadcastCircuitBreaker(&websocket.CircuitBreakerEvent{
+			NodeID:    nodeID,
+			State:     state,
+			PrevState: prev,
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package liveness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+)
+
+// fakeClock is a manually advanced clock for deterministic tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// TestMissedHeartbeatDeactivatesAndResumeReactivates verifies the sweep lifecycle
+func TestMissedHeartbeatDeactivatesAndResumeReactivates(t *testing.T) {
+	graph := router.NewGraph()
+	graph.AddNode(&router.Node{ID: "hub_a", Type: "Hub", IsActive: true})
+	graph.AddNode(&router.Node{ID: "hub_b", Type: "Hub", IsActive: true})
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	tracker := NewTracker(graph, nil, &Config{
+		Timeout:       10 * time.Second,
+		SweepInterval: time.Second,
+		Now:           clock.Now,
+	})
+
+	tracker.RecordHeartbeat("hub_a")
+	tracker.RecordHeartbeat("hub_b")
+
+	// hub_b keeps heartbeating, hub_a goes silent
+	clock.Advance(6 * time.Second)
+	tracker.RecordHeartbeat("hub_b")
+	clock.Advance(6 * time.Second)
+
+	expired := tracker.Sweep()
+	if len(expired) != 1 || expired[0] != "hub_a" {
+		t.Fatalf("Expected only hub_a to expire, got %v", expired)
+	}
+	if graph.IsNodeActive("hub_a") {
+		t.Error("hub_a should be inactive after missed heartbeat")
+	}
+	if !graph.IsNodeActive("hub_b") {
+		t.Error("hub_b should remain active")
+	}
+
+	// A second sweep must not report hub_a again
+	if again := tracker.Sweep(); len(again) != 0 {
+		t.Errorf("Expected no new expirations, got %v", again)
+	}
+
+	// Heartbeat resumes
+	clock.Advance(time.Second)
+	tracker.RecordHeartbeat("hub_a")
+	if !graph.IsNodeActive("hub_a") {
+		t.Error("hub_a should be reactivated after heartbeat resumes")
+	}
+}
+
+// TestTrackerDoesNotReviveManuallyKilledNode verifies chaos kills are left alone
+func TestTrackerDoesNotReviveManuallyKilledNode(t *testing.T) {
+	graph := router.NewGraph()
+	graph.AddNode(&router.Node{ID: "hub_a", Type: "Hub", IsActive: true})
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	tracker := NewTracker(graph, nil, &Config{Timeout: 10 * time.Second, Now: clock.Now})
+
+	tracker.RecordHeartbeat("hub_a")
+	graph.SetNodeInactive("hub_a")
+
+	clock.Advance(time.Second)
+	tracker.RecordHeartbeat("hub_a")
+	if graph.IsNodeActive("hub_a") {
+		t.Error("Tracker should not reactivate a node it did not deactivate")
+	}
+}
+
+// fakeNodeStore records persisted liveness, as would Neo4j
+type fakeNodeStore map[string]bool
+
+func (f fakeNodeStore) SetNodeActive(ctx context.Context, nodeID string, isActive bool) error {
+	f[nodeID] = isActive
+	return nil
+}
+
+// fakeBreaker records circuit states by node
+type fakeBreaker map[string]redisClient.State
+
+func (f fakeBreaker) ForceOpen(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	f[cfg.Name] = redisClient.StateOpen
+	return nil
+}
+
+func (f fakeBreaker) Reset(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	f[cfg.Name] = redisClient.StateClosed
+	return nil
+}
+
+// TestMissedHeartbeatsPersistAndOpenCircuit verifies a node missing its
+// heartbeats is marked inactive in the store with its circuit opened, and
+// restored once it resumes
+func TestMissedHeartbeatsPersistAndOpenCircuit(t *testing.T) {
+	graph := router.NewGraph()
+	graph.AddNode(&router.Node{ID: "hub_a", Type: "Hub", IsActive: true})
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	cfg := IntervalConfig(5*time.Second, 3)
+	cfg.Now = clock.Now
+	tracker := NewTracker(graph, nil, cfg)
+	store, breaker := fakeNodeStore{}, fakeBreaker{}
+	tracker.SetNodeStore(store)
+	tracker.SetCircuitBreaker(breaker)
+
+	tracker.RecordHeartbeat("hub_a")
+	clock.Advance(10 * time.Second)
+	if expired := tracker.Sweep(); len(expired) != 0 {
+		t.Fatalf("expected hub_a alive after 2 missed heartbeats, got %v", expired)
+	}
+
+	clock.Advance(6 * time.Second)
+	if expired := tracker.Sweep(); len(expired) != 1 {
+		t.Fatalf("expected hub_a to expire after 3 missed heartbeats, got %v", expired)
+	}
+	if active, ok := store["hub_a"]; !ok || active || breaker["hub_a"] != redisClient.StateOpen {
+		t.Fatalf("expected hub_a stored inactive with an open circuit, got store=%v breaker=%v", store, breaker)
+	}
+
+	tracker.RecordHeartbeat("hub_a")
+	if !store["hub_a"] || breaker["hub_a"] != redisClient.StateClosed {
+		t.Errorf("expected hub_a stored active with a closed circuit, got store=%v breaker=%v", store, breaker)
+	}
+}
@@ -0,0 +1,57 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps records in process, for single-instance deployments
+// and tests
+type MemoryBackend struct {
+	mu      sync.Mutex
+	records map[string]memoryRecord
+	now     func() time.Time
+}
+
+type memoryRecord struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryBackend creates an empty in-memory backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{records: make(map[string]memoryRecord), now: time.Now}
+}
+
+// Put stores a node's record for ttl
+func (m *MemoryBackend) Put(ctx context.Context, nodeID string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records[nodeID] = memoryRecord{value: value, expiresAt: m.now().Add(ttl)}
+	return nil
+}
+
+// List returns every unexpired record
+func (m *MemoryBackend) List(ctx context.Context) ([][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := m.now()
+	out := make([][]byte, 0, len(m.records))
+	for nodeID, rec := range m.records {
+		if now.After(rec.expiresAt) {
+			delete(m.records, nodeID)
+			continue
+		}
+		out = append(out, rec.value)
+	}
+	return out, nil
+}
+
+// Delete removes a node's record
+func (m *MemoryBackend) Delete(ctx context.Context, nodeID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.records, nodeID)
+	return nil
+}
@@ -0,0 +1,286 @@
+// Package registry lets mesh server instances discover each other. Every
+// instance announces the nodes it serves (gRPC address, certificate
+// fingerprint and health) to a shared backend with a TTL, and syncs the
+// routing graph from the records of all instances: nodes without a healthy
+// record are not routed through, and nodes served elsewhere are reported as
+// peers so their settlement hops can be forwarded.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+// NodeRecord is a node's registration
+type NodeRecord struct {
+	NodeID          string    `json:"node_id"`
+	Instance        string    `json:"instance"`                   // Server instance serving the node
+	Address         string    `json:"address"`                    // gRPC address of that instance
+	CertFingerprint string    `json:"cert_fingerprint,omitempty"` // SHA-256 of the instance's certificate
+	Healthy         bool      `json:"healthy"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// Backend stores the records shared by all instances (e.g. Redis)
+type Backend interface {
+	Put(ctx context.Context, nodeID string, value []byte, ttl time.Duration) error
+	List(ctx context.Context) ([][]byte, error)
+	Delete(ctx context.Context, nodeID string) error
+}
+
+// Config holds registry configuration
+type Config struct {
+	// Instance identifies this server instance
+	Instance string
+	// Address is the gRPC address peers reach this instance on
+	Address string
+	// CertFingerprint is the SHA-256 fingerprint of this instance's
+	// certificate (see CertFingerprint)
+	CertFingerprint string
+	// Nodes are the node IDs this instance serves; empty serves every node
+	// in the graph
+	Nodes []string
+	// TTL is how long a record lives without being refreshed
+	TTL time.Duration
+	// Interval is how often records are refreshed and the graph synced
+	Interval time.Duration
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() *Config {
+	hostname, _ := os.Hostname()
+	return &Config{
+		Instance: hostname,
+		TTL:      30 * time.Second,
+		Interval: 10 * time.Second,
+	}
+}
+
+// PeerFunc is told when a node becomes served by another instance at
+// address, or stops being (address empty)
+type PeerFunc func(nodeID, address string)
+
+// Registry announces local nodes and syncs node reachability from the backend
+type Registry struct {
+	backend Backend
+	graph   *router.Graph
+	cfg     *Config
+	onPeer  PeerFunc
+
+	mu      sync.Mutex
+	records map[string]*NodeRecord // As of the last sync
+	peers   map[string]string      // Remote node ID → instance address
+	// deactivated holds nodes this registry marked inactive, so it only
+	// reactivates nodes it took down (not ones killed via chaos endpoints)
+	deactivated map[string]bool
+}
+
+// NewRegistry creates a new node registry
+func NewRegistry(backend Backend, graph *router.Graph, cfg *Config) *Registry {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Registry{
+		backend:     backend,
+		graph:       graph,
+		cfg:         cfg,
+		records:     make(map[string]*NodeRecord),
+		peers:       make(map[string]string),
+		deactivated: make(map[string]bool),
+	}
+}
+
+// SetPeerFunc sets the callback for nodes gained or lost by other instances
+func (r *Registry) SetPeerFunc(fn PeerFunc) {
+	r.onPeer = fn
+}
+
+// localNodes returns the node IDs served by this instance
+func (r *Registry) localNodes() []string {
+	if len(r.cfg.Nodes) > 0 {
+		return r.cfg.Nodes
+	}
+	nodes := r.graph.GetAllNodes()
+	ids := make([]string, len(nodes))
+	for i, n := range nodes {
+		ids[i] = n.ID
+	}
+	return ids
+}
+
+// Announce registers (or refreshes) every local node, healthy while active
+// in the graph
+func (r *Registry) Announce(ctx context.Context) error {
+	now := time.Now().UTC()
+	for _, nodeID := range r.localNodes() {
+		data, err := json.Marshal(&NodeRecord{
+			NodeID:          nodeID,
+			Instance:        r.cfg.Instance,
+			Address:         r.cfg.Address,
+			CertFingerprint: r.cfg.CertFingerprint,
+			Healthy:         r.graph.IsNodeActive(nodeID),
+			UpdatedAt:       now,
+		})
+		if err != nil {
+			return err
+		}
+		if err := r.backend.Put(ctx, nodeID, data, r.cfg.TTL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deregister removes the records of every local node
+func (r *Registry) Deregister(ctx context.Context) error {
+	for _, nodeID := range r.localNodes() {
+		if err := r.backend.Delete(ctx, nodeID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync reads every record and updates the graph: nodes without a healthy
+// record are deactivated, and nodes it deactivated are reactivated once a
+// healthy record reappears. Peers are reported for healthy nodes served by
+// other instances.
+func (r *Registry) Sync(ctx context.Context) error {
+	raw, err := r.backend.List(ctx)
+	if err != nil {
+		return err
+	}
+	records := make(map[string]*NodeRecord, len(raw))
+	for _, data := range raw {
+		rec := new(NodeRecord)
+		if err := json.Unmarshal(data, rec); err != nil || rec.NodeID == "" {
+			continue
+		}
+		records[rec.NodeID] = rec
+	}
+
+	r.mu.Lock()
+	r.records = records
+
+	var down, up []string
+	for _, node := range r.graph.GetAllNodes() {
+		rec, ok := records[node.ID]
+		reachable := ok && rec.Healthy
+		switch {
+		case !reachable && !r.deactivated[node.ID] && r.graph.IsNodeActive(node.ID):
+			r.deactivated[node.ID] = true
+			down = append(down, node.ID)
+		case reachable && r.deactivated[node.ID]:
+			delete(r.deactivated, node.ID)
+			up = append(up, node.ID)
+		}
+	}
+
+	peers := make(map[string]string)
+	for nodeID, rec := range records {
+		if rec.Healthy && rec.Instance != r.cfg.Instance && rec.Address != "" {
+			peers[nodeID] = rec.Address
+		}
+	}
+	type change struct{ nodeID, address string }
+	var changes []change
+	for nodeID, addr := range peers {
+		if r.peers[nodeID] != addr {
+			changes = append(changes, change{nodeID, addr})
+		}
+	}
+	for nodeID := range r.peers {
+		if _, ok := peers[nodeID]; !ok {
+			changes = append(changes, change{nodeID, ""})
+		}
+	}
+	r.peers = peers
+	r.mu.Unlock()
+
+	for _, nodeID := range down {
+		r.graph.SetNodeInactive(nodeID)
+		log.Printf("⚠️ Node %s has no healthy registration, deactivated", nodeID)
+	}
+	for _, nodeID := range up {
+		r.graph.SetNodeActive(nodeID)
+		log.Printf("💚 Node %s registered again, reactivated", nodeID)
+	}
+	if r.onPeer != nil {
+		for _, c := range changes {
+			r.onPeer(c.nodeID, c.address)
+		}
+	}
+	return nil
+}
+
+// Nodes returns the records as of the last sync, sorted by node ID
+func (r *Registry) Nodes() []*NodeRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]*NodeRecord, 0, len(r.records))
+	for _, rec := range r.records {
+		out = append(out, rec)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].NodeID < out[j].NodeID })
+	return out
+}
+
+// Start announces and syncs every interval until the context is cancelled,
+// then deregisters the local nodes
+func (r *Registry) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+
+	log.Printf("📒 Node registry started (instance: %s, ttl: %v, refresh: %v)", r.cfg.Instance, r.cfg.TTL, r.cfg.Interval)
+
+	for {
+		r.refresh(ctx)
+		select {
+		case <-ctx.Done():
+			deregCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := r.Deregister(deregCtx); err != nil {
+				log.Printf("⚠️ Node registry deregistration failed: %v", err)
+			}
+			cancel()
+			log.Println("📒 Node registry stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh runs one announce and sync, logging failures
+func (r *Registry) refresh(ctx context.Context) {
+	if err := r.Announce(ctx); err != nil {
+		log.Printf("⚠️ Node registry announce failed: %v", err)
+	}
+	if err := r.Sync(ctx); err != nil {
+		log.Printf("⚠️ Node registry sync failed: %v", err)
+	}
+}
+
+// CertFingerprint returns the hex SHA-256 fingerprint of the first
+// certificate in a PEM file
+func CertFingerprint(certFile string) (string, error) {
+	data, err := os.ReadFile(certFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read certificate: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return "", fmt.Errorf("no certificate in %s", certFile)
+	}
+	sum := sha256.Sum256(block.Bytes)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+)
+
+func testGraph() *router.Graph {
+	g := router.NewGraph()
+	for _, id := range []string{"A", "B", "C"} {
+		g.AddNode(&router.Node{ID: id, IsActive: true})
+	}
+	return g
+}
+
+// TestSyncTracksRegisteredNodesAndPeers verifies two instances sharing a
+// backend see each other's nodes as peers and unregistered nodes go inactive
+func TestSyncTracksRegisteredNodesAndPeers(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+
+	eu := NewRegistry(backend, testGraph(), &Config{Instance: "eu", Address: "eu:50051", Nodes: []string{"A"}, TTL: time.Minute})
+	us := NewRegistry(backend, testGraph(), &Config{Instance: "us", Address: "us:50051", Nodes: []string{"B"}, TTL: time.Minute})
+
+	peers := make(map[string]string)
+	eu.SetPeerFunc(func(nodeID, address string) { peers[nodeID] = address })
+
+	for _, r := range []*Registry{eu, us} {
+		if err := r.Announce(ctx); err != nil {
+			t.Fatalf("Announce: %v", err)
+		}
+	}
+	if err := eu.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+
+	if !eu.graph.IsNodeActive("A") || !eu.graph.IsNodeActive("B") || eu.graph.IsNodeActive("C") {
+		t.Fatalf("expected A and B active and unregistered C inactive")
+	}
+	if len(peers) != 1 || peers["B"] != "us:50051" {
+		t.Fatalf("peers = %v, want B at us:50051", peers)
+	}
+	if nodes := eu.Nodes(); len(nodes) != 2 || nodes[0].NodeID != "A" || nodes[1].Instance != "us" {
+		t.Fatalf("nodes = %+v", nodes)
+	}
+
+	// The US instance shuts down: B goes inactive and stops being a peer
+	if err := us.Deregister(ctx); err != nil {
+		t.Fatalf("Deregister: %v", err)
+	}
+	if err := eu.Sync(ctx); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if eu.graph.IsNodeActive("B") {
+		t.Error("expected B inactive after its instance deregistered")
+	}
+	if addr, ok := peers["B"]; !ok || addr != "" {
+		t.Errorf("expected B reported as no longer a peer, got %q", addr)
+	}
+
+	// Back again: B is reactivated
+	us.Announce(ctx)
+	eu.Sync(ctx)
+	if !eu.graph.IsNodeActive("B") || peers["B"] != "us:50051" {
+		t.Error("expected B reactivated once re-registered")
+	}
+}
+
+// TestSyncLeavesManuallyKilledNodesDown verifies the registry only revives
+// nodes it deactivated itself
+func TestSyncLeavesManuallyKilledNodesDown(t *testing.T) {
+	ctx := context.Background()
+	backend := NewMemoryBackend()
+	r := NewRegistry(backend, testGraph(), &Config{Instance: "eu", TTL: time.Minute})
+
+	r.graph.SetNodeInactive("C")
+	r.Announce(ctx)
+	r.Sync(ctx)
+
+	nodes := r.Nodes()
+	if len(nodes) != 3 || nodes[2].Healthy {
+		t.Fatalf("expected C registered unhealthy, got %+v", nodes)
+	}
+	r.Sync(ctx)
+	if r.graph.IsNodeActive("C") || r.deactivated["C"] {
+		t.Error("expected killed node C to stay down and untracked")
+	}
+}
+
+// TestRecordsExpire verifies a record disappears once its TTL passes
+func TestRecordsExpire(t *testing.T) {
+	backend := NewMemoryBackend()
+	now := time.Now()
+	backend.now = func() time.Time { return now }
+
+	backend.Put(context.Background(), "A", []byte(`{"node_id":"A"}`), time.Second)
+	now = now.Add(2 * time.Second)
+	if recs, _ := backend.List(context.Background()); len(recs) != 0 {
+		t.Errorf("expected expired record gone, got %d", len(recs))
+	}
+}
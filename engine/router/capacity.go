@@ -0,0 +1,224 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrUnknownNode is returned for a node that isn't in the graph
+var ErrUnknownNode = errors.New("unknown node")
+
+// NodeCapacity limits the settlements a node takes. Routing skips a node that
+// is closed or at either limit. The zero value is unlimited and always open.
+type NodeCapacity struct {
+	MaxConcurrent  int64           `json:"max_concurrent,omitempty"`  // In-flight settlements, 0 = unlimited
+	MaxPerMinute   int64           `json:"max_per_minute,omitempty"`  // Settlements started per minute, 0 = unlimited
+	OperatingHours *OperatingHours `json:"operating_hours,omitempty"` // nil = always open
+}
+
+// OperatingHours is the daily window in which a node settles. A window
+// closing before it opens runs overnight, e.g. 22:00 to 06:00.
+type OperatingHours struct {
+	Open     string `json:"open"`               // HH:MM
+	Close    string `json:"close"`              // HH:MM
+	Timezone string `json:"timezone,omitempty"` // IANA name, default UTC
+}
+
+// Validate checks the limits and operating hours
+func (c NodeCapacity) Validate() error {
+	if c.MaxConcurrent < 0 {
+		return errors.New("max_concurrent must not be negative")
+	}
+	if c.MaxPerMinute < 0 {
+		return errors.New("max_per_minute must not be negative")
+	}
+	if c.OperatingHours != nil {
+		if _, _, _, err := c.OperatingHours.parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsOpen reports whether t falls within the operating hours. Hours that do
+// not parse are treated as always open; SetNodeCapacity rejects them.
+func (h *OperatingHours) IsOpen(t time.Time) bool {
+	opens, closes, loc, err := h.parse()
+	if err != nil || opens == closes {
+		return true
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	if opens < closes {
+		return minute >= opens && minute < closes
+	}
+	return minute >= opens || minute < closes
+}
+
+// locations caches time zones, which are loaded from disk
+var locations sync.Map
+
+// parse returns the open and close times in minutes after midnight, and the time zone
+func (h *OperatingHours) parse() (opens, closes int, loc *time.Location, err error) {
+	if opens, err = parseClock(h.Open); err != nil {
+		return 0, 0, nil, fmt.Errorf("operating_hours.open: %w", err)
+	}
+	if closes, err = parseClock(h.Close); err != nil {
+		return 0, 0, nil, fmt.Errorf("operating_hours.close: %w", err)
+	}
+
+	name := h.Timezone
+	if name == "" {
+		name = "UTC"
+	}
+	if cached, ok := locations.Load(name); ok {
+		return opens, closes, cached.(*time.Location), nil
+	}
+	loc, err = time.LoadLocation(name)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("operating_hours.timezone: unknown time zone %q", h.Timezone)
+	}
+	locations.Store(name, loc)
+	return opens, closes, loc, nil
+}
+
+// parseClock parses HH:MM as minutes after midnight
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("must be HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// SetNodeCapacity replaces the capacity of a node
+func (g *Graph) SetNodeCapacity(nodeID string, c NodeCapacity) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	node, ok := g.nodes[nodeID]
+	if !ok {
+		return fmt.Errorf("%w %s", ErrUnknownNode, nodeID)
+	}
+	node.Capacity = c
+	return nil
+}
+
+// NodeAvailable reports whether a node can take a settlement now: it is
+// within its operating hours and below its limits
+func (g *Graph) NodeAvailable(nodeID string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	node, ok := g.nodes[nodeID]
+	return ok && g.available(node, g.clock())
+}
+
+// available reports whether node can take a settlement at now.
+// Caller must hold at least RLock.
+func (g *Graph) available(node *Node, now time.Time) bool {
+	c := node.Capacity
+	if c.OperatingHours != nil && !c.OperatingHours.IsOpen(now) {
+		return false
+	}
+	if g.load == nil {
+		return true
+	}
+	if c.MaxConcurrent > 0 && g.load.Load(node.ID) >= c.MaxConcurrent {
+		return false
+	}
+	if c.MaxPerMinute > 0 && g.load.Started(node.ID, now) >= c.MaxPerMinute {
+		return false
+	}
+	return true
+}
+
+// unavailable returns the nodes that cannot take a settlement at now.
+// Caller must hold at least RLock.
+func (g *Graph) unavailable(now time.Time) map[string]bool {
+	var out map[string]bool
+	for id, node := range g.nodes {
+		if node.Capacity == (NodeCapacity{}) || g.available(node, now) {
+			continue
+		}
+		if out == nil {
+			out = make(map[string]bool)
+		}
+		out[id] = true
+	}
+	return out
+}
+
+// clock returns the current time, from g.now in tests
+func (g *Graph) clock() time.Time {
+	if g.now != nil {
+		return g.now()
+	}
+	return time.Now()
+}
+
+// Capacity property names on Neo4j nodes
+const (
+	propMaxConcurrent = "max_concurrent"
+	propMaxPerMinute  = "max_per_minute"
+	propOpen          = "open"
+	propClose         = "close"
+	propTimezone      = "timezone"
+)
+
+// Props returns the capacity as flat node properties, for storage in Neo4j.
+// Unset attributes are returned as nil so they are removed.
+func (c NodeCapacity) Props() map[string]interface{} {
+	props := map[string]interface{}{
+		propMaxConcurrent: nil,
+		propMaxPerMinute:  nil,
+		propOpen:          nil,
+		propClose:         nil,
+		propTimezone:      nil,
+	}
+	if c.MaxConcurrent > 0 {
+		props[propMaxConcurrent] = c.MaxConcurrent
+	}
+	if c.MaxPerMinute > 0 {
+		props[propMaxPerMinute] = c.MaxPerMinute
+	}
+	if h := c.OperatingHours; h != nil {
+		props[propOpen], props[propClose] = h.Open, h.Close
+		if h.Timezone != "" {
+			props[propTimezone] = h.Timezone
+		}
+	}
+	return props
+}
+
+// StoredProps returns the node properties with its capacity merged in, for
+// writing the node to Neo4j
+func (n *Node) StoredProps() map[string]interface{} {
+	props := make(map[string]interface{}, len(n.Props)+5)
+	for k, v := range n.Props {
+		props[k] = v
+	}
+	for k, v := range n.Capacity.Props() {
+		if v != nil {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// CapacityFromProps reads a capacity stored with NodeCapacity.Props
+func CapacityFromProps(props map[string]interface{}) NodeCapacity {
+	var c NodeCapacity
+	c.MaxConcurrent, _ = props[propMaxConcurrent].(int64)
+	c.MaxPerMinute, _ = props[propMaxPerMinute].(int64)
+	opens, _ := props[propOpen].(string)
+	closes, _ := props[propClose].(string)
+	if opens != "" && closes != "" {
+		timezone, _ := props[propTimezone].(string)
+		c.OperatingHours = &OperatingHours{Open: opens, Close: closes, Timezone: timezone}
+	}
+	return c
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOperatingHoursIsOpen(t *testing.T) {
+	day := &OperatingHours{Open: "09:00", Close: "17:00", Timezone: "Asia/Kolkata"}
+	night := &OperatingHours{Open: "22:00", Close: "06:00"}
+	tests := []struct {
+		hours *OperatingHours
+		at    string
+		want  bool
+	}{
+		{day, "2026-03-02T03:30:00Z", true},   // 09:00 IST
+		{day, "2026-03-02T03:29:00Z", false},  // 08:59 IST
+		{day, "2026-03-02T11:30:00Z", false},  // 17:00 IST, close is exclusive
+		{night, "2026-03-02T23:15:00Z", true}, // Before midnight
+		{night, "2026-03-02T05:59:00Z", true}, // After midnight
+		{night, "2026-03-02T12:00:00Z", false},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		if got := tt.hours.IsOpen(at); got != tt.want {
+			t.Errorf("%s-%s %s: IsOpen(%s) = %v, want %v", tt.hours.Open, tt.hours.Close, tt.hours.Timezone, tt.at, got, tt.want)
+		}
+	}
+}
+
+func TestNodeCapacityValidate(t *testing.T) {
+	invalid := []NodeCapacity{
+		{MaxConcurrent: -1},
+		{MaxPerMinute: -1},
+		{OperatingHours: &OperatingHours{Open: "9am", Close: "17:00"}},
+		{OperatingHours: &OperatingHours{Open: "09:00", Close: "24:00"}},
+		{OperatingHours: &OperatingHours{Open: "09:00", Close: "17:00", Timezone: "Mars/Olympus"}},
+	}
+	for _, c := range invalid {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", c)
+		}
+	}
+	if err := NewGraph().SetNodeCapacity("missing", NodeCapacity{}); err == nil {
+		t.Error("SetNodeCapacity on an unknown node must fail")
+	}
+}
+
+func TestRoutingSkipsUnavailableNodes(t *testing.T) {
+	g := NewGraph()
+	for _, id := range []string{"A", "B", "C", "D"} {
+		g.AddNode(&Node{ID: id, Type: "Hub", IsActive: true})
+	}
+	g.AddEdge(&Edge{SourceID: "A", TargetID: "B", BaseFee: 0.001, Latency: 10, IsActive: true})
+	g.AddEdge(&Edge{SourceID: "B", TargetID: "D", BaseFee: 0.001, Latency: 10, IsActive: true})
+	g.AddEdge(&Edge{SourceID: "A", TargetID: "C", BaseFee: 0.01, Latency: 50, IsActive: true})
+	g.AddEdge(&Edge{SourceID: "C", TargetID: "D", BaseFee: 0.01, Latency: 50, IsActive: true})
+
+	now, _ := time.Parse(time.RFC3339, "2026-03-02T12:00:00Z")
+	g.now = func() time.Time { return now }
+	lt := NewLoadTracker()
+	lt.now = g.now
+	g.SetLoadTracker(lt, 0)
+
+	route := func() []string {
+		t.Helper()
+		paths, err := NewRouter(g, 1).FindKShortestPaths(context.Background(), "A", "D", 0)
+		if err != nil || len(paths) == 0 {
+			t.Fatalf("FindKShortestPaths: %v", err)
+		}
+		return paths[0].Nodes
+	}
+	if got := route(); got[1] != "B" {
+		t.Fatalf("route = %v, want via B", got)
+	}
+
+	// Closed outside its hours
+	if err := g.SetNodeCapacity("B", NodeCapacity{OperatingHours: &OperatingHours{Open: "13:00", Close: "18:00"}}); err != nil {
+		t.Fatalf("SetNodeCapacity: %v", err)
+	}
+	if got := route(); got[1] != "C" {
+		t.Errorf("route with B closed = %v, want via C", got)
+	}
+
+	// At its concurrency limit
+	g.SetNodeCapacity("B", NodeCapacity{MaxConcurrent: 1})
+	lt.Acquire("B")
+	if g.NodeAvailable("B") {
+		t.Error("B is at max_concurrent and must be unavailable")
+	}
+	if got := route(); got[1] != "C" {
+		t.Errorf("route with B full = %v, want via C", got)
+	}
+	lt.Release("B")
+
+	// The settlement above counts against the throughput limit until the next minute
+	g.SetNodeCapacity("B", NodeCapacity{MaxPerMinute: 1})
+	if g.NodeAvailable("B") {
+		t.Error("B has started max_per_minute settlements and must be unavailable")
+	}
+	if got := route(); got[1] != "C" {
+		t.Errorf("route with B throttled = %v, want via C", got)
+	}
+	now = now.Add(time.Minute)
+	if !g.NodeAvailable("B") {
+		t.Error("B must be available again in the next minute")
+	}
+}
+
+func TestCapacityPropsRoundTrip(t *testing.T) {
+	c := NodeCapacity{MaxConcurrent: 5, MaxPerMinute: 60, OperatingHours: &OperatingHours{Open: "08:00", Close: "20:00", Timezone: "Europe/London"}}
+	n := &Node{ID: "hub", Props: map[string]interface{}{"name": "Hub"}, Capacity: c}
+	props := n.StoredProps()
+	if props["name"] != "Hub" {
+		t.Errorf("StoredProps dropped node properties: %v", props)
+	}
+	got := CapacityFromProps(props)
+	if got.MaxConcurrent != 5 || got.MaxPerMinute != 60 || got.OperatingHours == nil || *got.OperatingHours != *c.OperatingHours {
+		t.Errorf("CapacityFromProps = %+v, want %+v", got, c)
+	}
+	if (NodeCapacity{}).Props()[propMaxConcurrent] != nil {
+		t.Error("unset limits must be stored as nil")
+	}
+}
@@ -0,0 +1,197 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// MaxWaypoints is the most waypoints a route may be required to pass through
+const MaxWaypoints = 3
+
+// RouteConstraints restricts the paths a CountryRouter returns
+type RouteConstraints struct {
+	MaxHops int      `json:"max_hops,omitempty"` // 0 = unlimited
+	Avoid   []string `json:"avoid,omitempty"`    // Countries to route around, on top of blocked ones
+	Via     []string `json:"via,omitempty"`      // Waypoints the route must pass through, in order
+
+	// PreferOpen ranks paths whose countries are within their settlement
+	// windows now ahead of paths that wait for a country to open
+	PreferOpen bool `json:"prefer_open,omitempty"`
+}
+
+// Validate checks the constraints are consistent
+func (c RouteConstraints) Validate() error {
+	if c.MaxHops < 0 {
+		return fmt.Errorf("max_hops must not be negative")
+	}
+	if len(c.Via) > MaxWaypoints {
+		return fmt.Errorf("at most %d waypoints are allowed", MaxWaypoints)
+	}
+	if c.MaxHops > 0 && c.MaxHops <= len(c.Via) {
+		return fmt.Errorf("max_hops %d is too small to pass %d waypoints", c.MaxHops, len(c.Via))
+	}
+	avoid := make(map[string]bool, len(c.Avoid))
+	for _, code := range c.Avoid {
+		avoid[code] = true
+	}
+	seen := make(map[string]bool, len(c.Via))
+	for _, code := range c.Via {
+		if avoid[code] {
+			return fmt.Errorf("waypoint %s is also avoided", code)
+		}
+		if seen[code] {
+			return fmt.Errorf("waypoint %s is listed twice", code)
+		}
+		seen[code] = true
+	}
+	return nil
+}
+
+// findViaPaths finds up to K paths passing through the waypoints in order by
+// joining the K shortest paths of each leg. The caller holds the graph read lock.
+func (r *CountryRouter) findViaPaths(ctx context.Context, source, target string, blocked map[string]bool) ([]*CountryPath, error) {
+	stops := make([]string, 0, len(r.constraints.Via)+2)
+	stops = append(stops, source)
+	stops = append(stops, r.constraints.Via...)
+	stops = append(stops, target)
+
+	for _, code := range r.constraints.Via {
+		if code == source || code == target {
+			return nil, fmt.Errorf("waypoint %s is the source or target", code)
+		}
+		if blocked[code] {
+			return nil, fmt.Errorf("waypoint country %s is blocked", code)
+		}
+		if _, ok := r.graph.nodes[code]; !ok {
+			return nil, fmt.Errorf("waypoint country not found: %s", code)
+		}
+	}
+
+	// Every other leg needs at least one hop
+	legHops := 0
+	if r.constraints.MaxHops > 0 {
+		legHops = r.constraints.MaxHops - (len(stops) - 2)
+	}
+
+	combined := []*CountryPath{{}}
+	for i := 0; i < len(stops)-1; i++ {
+		// A leg may not pass through the other stops
+		legBlocked := make(map[string]bool, len(blocked)+len(stops))
+		for code := range blocked {
+			legBlocked[code] = true
+		}
+		for j, code := range stops {
+			if j != i && j != i+1 {
+				legBlocked[code] = true
+			}
+		}
+
+		legs, err := r.yen(ctx, stops[i], stops[i+1], legBlocked, legHops)
+		if err != nil {
+			return nil, err
+		}
+
+		var next []*CountryPath
+		for _, prefix := range combined {
+			for _, leg := range legs {
+				if path := joinLeg(prefix, leg, r.constraints.MaxHops); path != nil {
+					next = append(next, path)
+				}
+			}
+		}
+		if len(next) == 0 {
+			return nil, fmt.Errorf("no path found from %s to %s via %v", source, target, r.constraints.Via)
+		}
+		combined = next
+	}
+
+	sort.SliceStable(combined, func(i, j int) bool {
+		return combined[i].TotalWeight < combined[j].TotalWeight
+	})
+	if len(combined) > r.k {
+		combined = combined[:r.k]
+	}
+	for _, path := range combined {
+		r.calculatePathFees(path)
+	}
+	return combined, nil
+}
+
+// joinLeg appends leg to prefix, returning nil if the result would revisit a
+// country or exceed maxHops (0 = unlimited)
+func joinLeg(prefix, leg *CountryPath, maxHops int) *CountryPath {
+	if len(prefix.Nodes) == 0 {
+		return &CountryPath{
+			Nodes:       append([]string(nil), leg.Nodes...),
+			TotalWeight: leg.TotalWeight,
+		}
+	}
+	if maxHops > 0 && len(prefix.Nodes)+len(leg.Nodes)-2 > maxHops {
+		return nil
+	}
+	visited := make(map[string]bool, len(prefix.Nodes))
+	for _, code := range prefix.Nodes {
+		visited[code] = true
+	}
+	nodes := append(make([]string, 0, len(prefix.Nodes)+len(leg.Nodes)-1), prefix.Nodes...)
+	for _, code := range leg.Nodes[1:] {
+		if visited[code] {
+			return nil
+		}
+		nodes = append(nodes, code)
+	}
+	return &CountryPath{
+		Nodes:       nodes,
+		TotalWeight: prefix.TotalWeight + leg.TotalWeight,
+	}
+}
+
+// boundedShortestPath finds the lowest-weight path of at most maxHops hops.
+// dist[h] holds the best weight reaching each country in at most h hops.
+func (r *CountryRouter) boundedShortestPath(source, target string, excludedEdges, excludedNodes map[string]bool, maxHops int) *CountryPath {
+	if excludedNodes[source] || excludedNodes[target] {
+		return nil
+	}
+
+	dist := []map[string]float64{{source: 0}}
+	prev := []map[string]string{{}}
+	for h := 1; h <= maxHops; h++ {
+		cur := make(map[string]float64, len(dist[h-1]))
+		for code, d := range dist[h-1] {
+			cur[code] = d
+		}
+		from := make(map[string]string)
+		for code, d := range dist[h-1] {
+			for targetCode, edge := range r.graph.edges[code] {
+				if !edge.IsActive || excludedNodes[targetCode] || excludedEdges[code+"->"+targetCode] {
+					continue
+				}
+				newDist := d + r.graph.edgeWeight(edge, r.preference)
+				if old, ok := cur[targetCode]; !ok || newDist < old {
+					cur[targetCode] = newDist
+					from[targetCode] = code
+				}
+			}
+		}
+		dist = append(dist, cur)
+		prev = append(prev, from)
+	}
+
+	weight, ok := dist[maxHops][target]
+	if !ok {
+		return nil
+	}
+
+	// Walk back through the layers; a country without a predecessor in a
+	// layer was already reached with fewer hops
+	path := &CountryPath{Nodes: []string{target}, TotalWeight: weight}
+	current := target
+	for h := maxHops; h > 0 && current != source; h-- {
+		if p, ok := prev[h][current]; ok {
+			current = p
+			path.Nodes = append([]string{current}, path.Nodes...)
+		}
+	}
+	return path
+}
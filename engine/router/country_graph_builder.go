@@ -3,9 +3,12 @@ package router
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
 )
 
 // CountryData represents country data from Neo4j
@@ -24,6 +27,20 @@ type TradeConnection struct {
 	Target string
 }
 
+// DefaultTradeBaseCost is the base cost of a trade corridor without an explicit cost
+const DefaultTradeBaseCost = 0.01
+
+// TradeRelationship is the Neo4j relationship type for trade corridors.
+// Corridors are stored in both directions.
+const TradeRelationship = "TRADES_WITH"
+
+// tradeCorridor is a trade connection with its base cost and latency
+type tradeCorridor struct {
+	TradeConnection
+	BaseCost  float64
+	LatencyMs int64
+}
+
 // DefaultTradeConnections returns the standard trade connections
 var DefaultTradeConnections = []TradeConnection{
 	// USD hub connections
@@ -57,8 +74,161 @@ var DefaultTradeConnections = []TradeConnection{
 	{"CHE", "AUT"}, {"ISR", "USA"}, {"TUR", "DEU"},
 }
 
+// loadTradeConnections reads the active trade corridors stored in Neo4j
+func loadTradeConnections(ctx context.Context, session neo4j.SessionWithContext) ([]tradeCorridor, error) {
+	result, err := session.Run(ctx, `
+		MATCH (a:Country)-[r:`+TradeRelationship+`]->(b:Country)
+		WHERE coalesce(r.active, true)
+		RETURN a.code AS source, b.code AS target, r.base_cost AS base_cost, r.latency_ms AS latency_ms
+	`, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var connections []tradeCorridor
+	for result.Next(ctx) {
+		record := result.Record()
+		source, _ := record.Get("source")
+		target, _ := record.Get("target")
+		baseCost, _ := record.Get("base_cost")
+		latency, _ := record.Get("latency_ms")
+		corridor := tradeCorridor{
+			TradeConnection: TradeConnection{Source: toString(source), Target: toString(target)},
+			BaseCost:        toFloat(baseCost),
+			LatencyMs:       int64(toFloat(latency)),
+		}
+		if corridor.BaseCost <= 0 {
+			corridor.BaseCost = DefaultTradeBaseCost
+		}
+		connections = append(connections, corridor)
+	}
+	return connections, result.Err()
+}
+
+// defaultCorridors returns DefaultTradeConnections at the default base cost
+func defaultCorridors() []tradeCorridor {
+	corridors := make([]tradeCorridor, len(DefaultTradeConnections))
+	for i, conn := range DefaultTradeConnections {
+		corridors[i] = tradeCorridor{TradeConnection: conn, BaseCost: DefaultTradeBaseCost}
+	}
+	return corridors
+}
+
+// SeedTradeConnections stores DefaultTradeConnections in Neo4j the first time
+// it runs. Once any corridor exists it does nothing, so corridors removed
+// through the admin API stay removed across restarts.
+func SeedTradeConnections(ctx context.Context, driver neo4j.DriverWithContext, database string) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "seed_trade_connections")
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		existing, err := tx.Run(ctx, `MATCH ()-[r:`+TradeRelationship+`]->() RETURN count(r) AS count`, nil)
+		if err != nil {
+			return nil, err
+		}
+		if record, err := existing.Single(ctx); err != nil {
+			return nil, err
+		} else if count, _ := record.Get("count"); toFloat(count) > 0 {
+			return nil, nil
+		}
+
+		connections := make([]map[string]any, 0, len(DefaultTradeConnections))
+		for _, conn := range DefaultTradeConnections {
+			connections = append(connections, map[string]any{"source": conn.Source, "target": conn.Target, "base_cost": DefaultTradeBaseCost})
+		}
+		_, err = tx.Run(ctx, `
+			UNWIND $connections AS conn
+			MATCH (a:Country {code: conn.source})
+			MATCH (b:Country {code: conn.target})
+			MERGE (a)-[r1:`+TradeRelationship+`]->(b)
+			ON CREATE SET r1.base_cost = conn.base_cost, r1.active = true, r1.created_at = datetime()
+			MERGE (b)-[r2:`+TradeRelationship+`]->(a)
+			ON CREATE SET r2.base_cost = conn.base_cost, r2.active = true, r2.created_at = datetime()
+		`, map[string]any{"connections": connections})
+		return nil, err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to seed trade connections: %w", err)
+	}
+	return nil
+}
+
+// SaveCountryGraph replaces the countries and trade corridors stored in Neo4j
+// with e in a single transaction. Countries missing from e are deleted.
+func SaveCountryGraph(ctx context.Context, driver neo4j.DriverWithContext, database string, e *CountryExport) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "save_country_graph")
+
+	countries := make([]map[string]any, len(e.Nodes))
+	codes := make([]string, len(e.Nodes))
+	for i, node := range e.Nodes {
+		codes[i] = node.Code
+		countries[i] = map[string]any{
+			"code":         node.Code,
+			"name":         node.Name,
+			"currency":     node.Currency,
+			"credibility":  node.Credibility,
+			"success_rate": node.SuccessRate,
+			"fx_rate":      node.FXRate,
+			"hours":        node.Hours.Props(),
+		}
+	}
+	corridors := make([]map[string]any, len(e.Edges))
+	for i, edge := range e.Edges {
+		corridors[i] = map[string]any{
+			"source":     edge.SourceCode,
+			"target":     edge.TargetCode,
+			"base_cost":  edge.BaseCost,
+			"latency_ms": edge.LatencyMs,
+			"active":     edge.IsActive,
+		}
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database, AccessMode: neo4j.AccessModeWrite})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		queries := []struct {
+			cypher string
+			params map[string]any
+		}{
+			{`
+				UNWIND $countries AS c
+				MERGE (n:Country {code: c.code})
+				SET n.name = c.name, n.currency = c.currency, n.base_credibility = c.credibility,
+				    n.success_rate = c.success_rate, n.fx_rate = c.fx_rate
+				SET n += c.hours
+			`, map[string]any{"countries": countries}},
+			{`MATCH (n:Country) WHERE NOT n.code IN $codes DETACH DELETE n`, map[string]any{"codes": codes}},
+			{`MATCH (:Country)-[r:` + TradeRelationship + `]->(:Country) DELETE r`, nil},
+			{`
+				UNWIND $corridors AS conn
+				MATCH (a:Country {code: conn.source})
+				MATCH (b:Country {code: conn.target})
+				CREATE (a)-[r1:` + TradeRelationship + `]->(b)
+				SET r1.base_cost = conn.base_cost, r1.latency_ms = conn.latency_ms, r1.active = conn.active, r1.created_at = datetime()
+				CREATE (b)-[r2:` + TradeRelationship + `]->(a)
+				SET r2.base_cost = conn.base_cost, r2.latency_ms = conn.latency_ms, r2.active = conn.active, r2.created_at = datetime()
+			`, map[string]any{"corridors": corridors}},
+		}
+		for _, q := range queries {
+			if _, err := tx.Run(ctx, q.cypher, q.params); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save country graph: %w", err)
+	}
+	return nil
+}
+
 // BuildCountryGraphFromNeo4j builds a CountryGraph from Neo4j country data
 func BuildCountryGraphFromNeo4j(ctx context.Context, driver neo4j.DriverWithContext, database string) (*CountryGraph, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "build_country_graph")
+
 	graph := NewCountryGraph()
 
 	session := driver.NewSession(ctx, neo4j.SessionConfig{DatabaseName: database})
@@ -69,7 +239,8 @@ func BuildCountryGraphFromNeo4j(ctx context.Context, driver neo4j.DriverWithCont
 		MATCH (c:Country)
 		RETURN c.code AS code, c.name AS name, c.currency AS currency,
 		       c.base_credibility AS credibility, c.success_rate AS success_rate,
-		       c.fx_rate AS fx_rate
+		       c.fx_rate AS fx_rate, coalesce(c.is_active, true) AS is_active,
+		       properties(c) AS props
 	`, nil)
 	if err != nil {
 		return nil, err
@@ -85,6 +256,9 @@ func BuildCountryGraphFromNeo4j(ctx context.Context, driver neo4j.DriverWithCont
 		credibility, _ := record.Get("credibility")
 		successRate, _ := record.Get("success_rate")
 		fxRate, _ := record.Get("fx_rate")
+		isActive, _ := record.Get("is_active")
+		props, _ := record.Get("props")
+		nodeProps, _ := props.(map[string]interface{})
 
 		data := &CountryData{
 			Code:        toString(code),
@@ -104,15 +278,25 @@ func BuildCountryGraphFromNeo4j(ctx context.Context, driver neo4j.DriverWithCont
 			Credibility: data.Credibility,
 			SuccessRate: data.SuccessRate,
 			FXRate:      data.FXRate,
-			IsActive:    true,
+			IsActive:    isActive != false, // Halted countries are stored with is_active = false
+			Hours:       settlementWindowFromProps(nodeProps),
 		})
 	}
 
 	log.Printf("📊 Loaded %d countries into routing graph", len(countries))
 
-	// Add trade connections
+	// Trade corridors persisted in Neo4j (managed via the admin API), falling
+	// back to the built-in defaults until they have been seeded
+	connections, err := loadTradeConnections(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	if len(connections) == 0 {
+		connections = defaultCorridors()
+	}
+
 	edgeCount := 0
-	for _, conn := range DefaultTradeConnections {
+	for _, conn := range connections {
 		if _, ok := countries[conn.Source]; !ok {
 			continue
 		}
@@ -120,23 +304,11 @@ func BuildCountryGraphFromNeo4j(ctx context.Context, driver neo4j.DriverWithCont
 			continue
 		}
 
-		// Base cost is derived from FX rates difference (normalized)
-		srcRate := countries[conn.Source].FXRate
-		tgtRate := countries[conn.Target].FXRate
-		if srcRate == 0 {
-			srcRate = 1
-		}
-		if tgtRate == 0 {
-			tgtRate = 1
-		}
-
-		// Base cost: normalized difference (0-1 range)
-		baseCost := 0.01 // Default small cost
-
 		graph.AddEdge(&CountryEdge{
 			SourceCode: conn.Source,
 			TargetCode: conn.Target,
-			BaseCost:   baseCost,
+			BaseCost:   conn.BaseCost,
+			LatencyMs:  conn.LatencyMs,
 			IsActive:   true,
 		})
 		edgeCount++
@@ -227,7 +399,7 @@ func BuildCountryGraphWithDefaults() *CountryGraph {
 		graph.AddEdge(&CountryEdge{
 			SourceCode: conn.Source,
 			TargetCode: conn.Target,
-			BaseCost:   0.01,
+			BaseCost:   DefaultTradeBaseCost,
 			IsActive:   true,
 		})
 	}
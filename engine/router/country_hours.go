@@ -0,0 +1,248 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SettlementWindow is when a country's payment system accepts payments: its
+// business days and hours, with a cutoff after which payments wait for the
+// next business day. Hours closing before they open run overnight. Systems
+// settling in batches run one every BatchMinutes from the open time.
+type SettlementWindow struct {
+	OperatingHours
+	Cutoff       string   `json:"cutoff,omitempty"`        // HH:MM, last acceptance of the day; default the close time
+	Days         []string `json:"days,omitempty"`          // mon..sun; empty = every day
+	BatchMinutes int      `json:"batch_minutes,omitempty"` // 0 = settles continuously
+}
+
+// maxBatchMinutes bounds the batch interval to a day
+const maxBatchMinutes = 24 * 60
+
+// weekdays maps the day names of a SettlementWindow
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Validate checks the hours, cutoff and days
+func (w *SettlementWindow) Validate() error {
+	if _, _, _, err := w.window(); err != nil {
+		return err
+	}
+	if w.BatchMinutes < 0 || w.BatchMinutes > maxBatchMinutes {
+		return fmt.Errorf("batch_minutes must be between 0 and %d", maxBatchMinutes)
+	}
+	for _, day := range w.Days {
+		if _, ok := weekdays[strings.ToLower(day)]; !ok {
+			return fmt.Errorf("days: unknown day %q, want mon..sun", day)
+		}
+	}
+	return nil
+}
+
+// window returns when payments are accepted, in minutes after midnight
+func (w *SettlementWindow) window() (opens, closes int, loc *time.Location, err error) {
+	opens, closes, loc, err = w.parse()
+	if err != nil || w.Cutoff == "" {
+		return opens, closes, loc, err
+	}
+	cutoff, err := parseClock(w.Cutoff)
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("cutoff: %w", err)
+	}
+	// The cutoff must fall within the hours, measured from the open time
+	if within := (cutoff - opens + 1440) % 1440; within == 0 || (opens != closes && within > (closes-opens+1440)%1440) {
+		return 0, 0, nil, errors.New("cutoff must be after the open time and no later than the close time")
+	}
+	return opens, cutoff, loc, nil
+}
+
+// businessDay reports whether a window opening on day counts
+func (w *SettlementWindow) businessDay(day time.Weekday) bool {
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if weekdays[strings.ToLower(d)] == day {
+			return true
+		}
+	}
+	return false
+}
+
+// NextAccept returns the earliest time at or after t at which the country
+// accepts a payment: t itself inside the window, otherwise the next opening.
+func (w *SettlementWindow) NextAccept(t time.Time) time.Time {
+	at, _ := w.accept(t)
+	return at
+}
+
+// NextSettlement returns when a payment arriving at t settles: when it is
+// accepted, or for batch systems the first batch run after that
+func (w *SettlementWindow) NextSettlement(t time.Time) time.Time {
+	at, opened := w.accept(t)
+	if w.BatchMinutes <= 0 {
+		return at
+	}
+	batch := time.Duration(w.BatchMinutes) * time.Minute
+	runs := (at.Sub(opened) + batch - 1) / batch
+	return opened.Add(runs * batch)
+}
+
+// accept returns NextAccept(t) and the opening of the window accepting it
+func (w *SettlementWindow) accept(t time.Time) (at, opened time.Time) {
+	opens, closes, loc, err := w.window()
+	if err != nil {
+		return t, t
+	}
+	length := time.Duration((closes-opens+1440)%1440) * time.Minute
+	if length == 0 {
+		length = 24 * time.Hour
+	}
+
+	local := t.In(loc)
+	// Start a day early for an overnight window opened yesterday
+	for d := -1; d <= 7; d++ {
+		start := time.Date(local.Year(), local.Month(), local.Day()+d, opens/60, opens%60, 0, 0, loc)
+		if !w.businessDay(start.Weekday()) || !t.Before(start.Add(length)) {
+			continue
+		}
+		if t.Before(start) {
+			return start, start
+		}
+		return t, start
+	}
+	return t, t // No business days; Validate rejects these
+}
+
+// SetCountryHours sets when a country accepts payments; nil clears it
+func (g *CountryGraph) SetCountryHours(code string, hours *SettlementWindow) error {
+	if hours != nil {
+		if err := hours.Validate(); err != nil {
+			return err
+		}
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	node, ok := g.nodes[code]
+	if !ok {
+		return fmt.Errorf("country not found: %s", code)
+	}
+	// Replace the node so exported copies keep the old hours
+	cp := *node
+	cp.Hours = hours
+	g.nodes[code] = &cp
+	g.version++
+	return nil
+}
+
+// closedCountries returns the countries outside their settlement window at now,
+// sorted. The caller holds the graph read lock.
+func (g *CountryGraph) closedCountries(now time.Time) []string {
+	var closed []string
+	for code, node := range g.nodes {
+		if node.Hours != nil && node.Hours.NextAccept(now).After(now) {
+			closed = append(closed, code)
+		}
+	}
+	sort.Strings(closed)
+	return closed
+}
+
+// estimateCompletion annotates a path with when it settles if started at now.
+// Each country on it holds the payment until its settlement window is open
+// and its next batch runs, then the payment moves on over the corridor taking
+// the corridor's historical processing time, or its latency until one has
+// been measured. times may be nil. The caller holds the graph read lock.
+func (g *CountryGraph) estimateCompletion(path *CountryPath, now time.Time, times *HopTimes) {
+	t := now
+	var wait time.Duration
+	closed := false
+	for i, code := range path.Nodes {
+		if node, ok := g.nodes[code]; ok && node.Hours != nil {
+			closed = closed || node.Hours.NextAccept(t).After(t)
+			next := node.Hours.NextSettlement(t)
+			wait += next.Sub(t)
+			t = next
+		}
+		if i+1 < len(path.Nodes) {
+			if d, ok := times.Estimate(code, path.Nodes[i+1]); ok {
+				t = t.Add(d)
+			} else if edge, ok := g.edges[code][path.Nodes[i+1]]; ok {
+				t = t.Add(time.Duration(edge.latency()) * time.Millisecond)
+			}
+		}
+	}
+	path.EstimatedCompletionAt = t.UTC()
+	path.EstimatedDurationMs = t.Sub(now).Milliseconds()
+	path.WaitMs = wait.Milliseconds()
+	path.WithinHours = !closed
+}
+
+// clock returns the current time, from g.now in tests
+func (g *CountryGraph) clock() time.Time {
+	if g.now != nil {
+		return g.now()
+	}
+	return time.Now()
+}
+
+// Props returns the window as Country node properties, for storage in Neo4j.
+// A nil window returns nil values so the properties are removed.
+func (w *SettlementWindow) Props() map[string]interface{} {
+	props := map[string]interface{}{
+		"hours_open": nil, "hours_close": nil, "hours_cutoff": nil, "hours_timezone": nil, "hours_days": nil,
+		"hours_batch_minutes": nil,
+	}
+	if w == nil {
+		return props
+	}
+	props["hours_open"], props["hours_close"] = w.Open, w.Close
+	if w.Cutoff != "" {
+		props["hours_cutoff"] = w.Cutoff
+	}
+	if w.Timezone != "" {
+		props["hours_timezone"] = w.Timezone
+	}
+	if len(w.Days) > 0 {
+		props["hours_days"] = w.Days
+	}
+	if w.BatchMinutes > 0 {
+		props["hours_batch_minutes"] = int64(w.BatchMinutes)
+	}
+	return props
+}
+
+// settlementWindowFromProps reads a window stored with SettlementWindow.Props,
+// returning nil when none is stored or it is no longer valid
+func settlementWindowFromProps(props map[string]interface{}) *SettlementWindow {
+	w := &SettlementWindow{
+		OperatingHours: OperatingHours{
+			Open:     toString(props["hours_open"]),
+			Close:    toString(props["hours_close"]),
+			Timezone: toString(props["hours_timezone"]),
+		},
+		Cutoff: toString(props["hours_cutoff"]),
+	}
+	if w.Open == "" || w.Close == "" {
+		return nil
+	}
+	if batch, ok := props["hours_batch_minutes"].(int64); ok {
+		w.BatchMinutes = int(batch)
+	}
+	if days, ok := props["hours_days"].([]interface{}); ok {
+		for _, d := range days {
+			w.Days = append(w.Days, toString(d))
+		}
+	}
+	if err := w.Validate(); err != nil {
+		log.Printf("⚠️ Ignoring invalid settlement hours: %v", err)
+		return nil
+	}
+	return w
+}
@@ -0,0 +1,108 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSettlementWindowNextAccept(t *testing.T) {
+	// Weekdays 09:00-17:00 New York, cutoff 15:00
+	ny := &SettlementWindow{
+		OperatingHours: OperatingHours{Open: "09:00", Close: "17:00", Timezone: "America/New_York"},
+		Cutoff:         "15:00",
+		Days:           []string{"mon", "tue", "wed", "thu", "fri"},
+	}
+	night := &SettlementWindow{OperatingHours: OperatingHours{Open: "22:00", Close: "04:00"}}
+	tests := []struct {
+		window   *SettlementWindow
+		at, want string
+	}{
+		{ny, "2026-03-04T15:00:00Z", "2026-03-04T15:00:00Z"}, // Wednesday 10:00, open
+		{ny, "2026-03-04T12:00:00Z", "2026-03-04T14:00:00Z"}, // Wednesday 07:00, opens at 09:00
+		{ny, "2026-03-04T20:30:00Z", "2026-03-05T14:00:00Z"}, // Wednesday 15:30, past the cutoff
+		{ny, "2026-03-06T21:00:00Z", "2026-03-09T13:00:00Z"}, // Friday 16:00, opens Monday (after DST starts)
+		{night, "2026-03-04T23:00:00Z", "2026-03-04T23:00:00Z"},
+		{night, "2026-03-05T03:59:00Z", "2026-03-05T03:59:00Z"}, // Window opened yesterday
+		{night, "2026-03-05T04:00:00Z", "2026-03-05T22:00:00Z"},
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := tt.window.NextAccept(at); !got.Equal(want) {
+			t.Errorf("%s-%s NextAccept(%s) = %s, want %s", tt.window.Open, tt.window.Close, tt.at, got.UTC().Format(time.RFC3339), tt.want)
+		}
+	}
+}
+
+func TestSettlementWindowValidate(t *testing.T) {
+	hours := OperatingHours{Open: "09:00", Close: "17:00"}
+	invalid := []*SettlementWindow{
+		{OperatingHours: hours, Cutoff: "18:00"},
+		{OperatingHours: hours, Cutoff: "09:00"},
+		{OperatingHours: hours, Cutoff: "3pm"},
+		{OperatingHours: hours, Days: []string{"monday"}},
+		{OperatingHours: OperatingHours{Open: "09:00", Close: "17:00", Timezone: "Nowhere/City"}},
+	}
+	for _, w := range invalid {
+		if err := w.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil, want error", w)
+		}
+	}
+	valid := &SettlementWindow{OperatingHours: OperatingHours{Open: "22:00", Close: "04:00"}, Cutoff: "02:00", Days: []string{"Mon"}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("overnight cutoff: %v", err)
+	}
+	if got := settlementWindowFromProps(valid.Props()); got == nil || got.Cutoff != "02:00" || got.Open != "22:00" {
+		t.Errorf("settlementWindowFromProps = %+v", got)
+	}
+}
+
+func TestCountryRouterPreferOpen(t *testing.T) {
+	graph := NewCountryGraph()
+	for _, code := range []string{"USA", "GBR", "JPN", "SGP"} {
+		graph.AddNode(&CountryNode{Code: code, SuccessRate: 1, IsActive: true})
+	}
+	graph.AddEdge(&CountryEdge{SourceCode: "USA", TargetCode: "JPN", BaseCost: 0.001, LatencyMs: 100, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "JPN", TargetCode: "SGP", BaseCost: 0.001, LatencyMs: 100, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "USA", TargetCode: "GBR", BaseCost: 0.01, LatencyMs: 100, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "GBR", TargetCode: "SGP", BaseCost: 0.01, LatencyMs: 100, IsActive: true})
+
+	// 12:00 UTC is 21:00 in Tokyo, after its cutoff
+	now, _ := time.Parse(time.RFC3339, "2026-03-04T12:00:00Z")
+	graph.now = func() time.Time { return now }
+	if err := graph.SetCountryHours("JPN", &SettlementWindow{
+		OperatingHours: OperatingHours{Open: "09:00", Close: "18:00", Timezone: "Asia/Tokyo"},
+		Cutoff:         "17:00",
+	}); err != nil {
+		t.Fatalf("SetCountryHours: %v", err)
+	}
+
+	countryRouter := NewCountryRouter(graph, 2)
+	countryRouter.SetCache(10, time.Minute)
+	paths, err := countryRouter.FindKShortestPaths(context.Background(), "USA", "SGP", nil)
+	if err != nil || len(paths) != 2 {
+		t.Fatalf("FindKShortestPaths = %v, %v", paths, err)
+	}
+	if paths[0].Nodes[1] != "JPN" || paths[0].WithinHours {
+		t.Errorf("cheapest path = %v (within hours %v), want via closed JPN", paths[0].Nodes, paths[0].WithinHours)
+	}
+	// Tokyo opens at 09:00, 00:00 UTC the next day
+	if want := time.Date(2026, 3, 5, 0, 0, 0, 100e6, time.UTC); !paths[0].EstimatedCompletionAt.Equal(want) {
+		t.Errorf("estimated completion = %s, want %s", paths[0].EstimatedCompletionAt, want)
+	}
+
+	paths, err = countryRouter.WithConstraints(RouteConstraints{PreferOpen: true}).FindKShortestPaths(context.Background(), "USA", "SGP", nil)
+	if err != nil || len(paths) != 2 {
+		t.Fatalf("FindKShortestPaths(prefer_open) = %v, %v", paths, err)
+	}
+	if paths[0].Nodes[1] != "GBR" || !paths[0].WithinHours || paths[0].WaitMs != 0 {
+		t.Errorf("prefer_open path = %+v, want via open GBR", paths[0])
+	}
+	if want := now.Add(200 * time.Millisecond); !paths[0].EstimatedCompletionAt.Equal(want) {
+		t.Errorf("estimated completion = %s, want %s", paths[0].EstimatedCompletionAt, want)
+	}
+	if paths[1].Nodes[1] != "JPN" {
+		t.Errorf("closed path must still be offered second, got %v", paths[1].Nodes)
+	}
+}
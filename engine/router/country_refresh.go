@@ -0,0 +1,91 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// CountryGraphRefresher periodically reloads a CountryGraph from Neo4j so
+// credibility, success rate, FX rate and trade corridor changes reach routing
+// without a restart
+type CountryGraphRefresher struct {
+	graph    *CountryGraph
+	driver   neo4j.DriverWithContext
+	database string
+	interval time.Duration
+
+	mu          sync.Mutex // serializes refreshes
+	lastRefresh time.Time
+	onRefresh   []func(*CountryGraph)
+}
+
+// NewCountryGraphRefresher creates a refresher for graph
+func NewCountryGraphRefresher(graph *CountryGraph, driver neo4j.DriverWithContext, database string, interval time.Duration) *CountryGraphRefresher {
+	return &CountryGraphRefresher{
+		graph:    graph,
+		driver:   driver,
+		database: database,
+		interval: interval,
+	}
+}
+
+// OnRefresh registers a callback run after each successful refresh
+func (r *CountryGraphRefresher) OnRefresh(fn func(*CountryGraph)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRefresh = append(r.onRefresh, fn)
+}
+
+// Refresh reloads the graph from Neo4j and swaps it in. On error (or if Neo4j
+// has no countries) the current graph is kept.
+func (r *CountryGraphRefresher) Refresh(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fresh, err := BuildCountryGraphFromNeo4j(ctx, r.driver, r.database)
+	if err != nil {
+		return fmt.Errorf("failed to refresh country graph: %w", err)
+	}
+	if fresh.NodeCount() == 0 {
+		return errors.New("failed to refresh country graph: no countries in Neo4j")
+	}
+
+	r.graph.Replace(fresh)
+	r.lastRefresh = time.Now()
+	for _, fn := range r.onRefresh {
+		fn(r.graph)
+	}
+	return nil
+}
+
+// LastRefresh returns when the graph was last refreshed (zero if never)
+func (r *CountryGraphRefresher) LastRefresh() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastRefresh
+}
+
+// Start refreshes the graph every interval until ctx is cancelled
+func (r *CountryGraphRefresher) Start(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			refreshCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			if err := r.Refresh(refreshCtx); err != nil {
+				slog.WarnContext(ctx, "country graph refresh failed", "error", err)
+			}
+			cancel()
+		}
+	}
+}
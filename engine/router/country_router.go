@@ -7,7 +7,11 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
 )
 
 // CountryNode represents a country in the routing graph
@@ -19,6 +23,7 @@ type CountryNode struct {
 	SuccessRate float64 `json:"success_rate"` // 0-1, higher is better
 	FXRate      float64 `json:"fx_rate"`      // Exchange rate to USD
 	IsActive    bool    `json:"is_active"`
+	Hours       *SettlementWindow `json:"hours,omitempty"` // nil = always accepting
 }
 
 // CountryEdge represents a trade connection between countries
@@ -26,9 +31,21 @@ type CountryEdge struct {
 	SourceCode string  `json:"source_code"`
 	TargetCode string  `json:"target_code"`
 	BaseCost   float64 `json:"base_cost"` // Base transaction cost (0-1)
+	LatencyMs  int64   `json:"latency_ms"` // Settlement latency (0 uses DefaultTradeLatencyMs)
 	IsActive   bool    `json:"is_active"`
 }
 
+// DefaultTradeLatencyMs is the settlement latency of a corridor without a measured latency
+const DefaultTradeLatencyMs = 100
+
+// latency returns the edge latency in milliseconds
+func (e *CountryEdge) latency() int64 {
+	if e.LatencyMs > 0 {
+		return e.LatencyMs
+	}
+	return DefaultTradeLatencyMs
+}
+
 // CountryPath represents a calculated route with fees
 type CountryPath struct {
 	Nodes          []string  `json:"nodes"`           // Country codes in order
@@ -36,6 +53,14 @@ type CountryPath struct {
 	TotalFeePercent float64  `json:"total_fee_percent"` // Total fees as percentage
 	HopCount       int       `json:"hop_count"`       // Number of hops
 	FinalAmount    float64   `json:"final_amount"`    // Amount after fees (per 1.0 input)
+	TotalLatencyMs int64     `json:"total_latency_ms"` // Sum of corridor latencies
+	Reliability    float64   `json:"reliability"`     // Product of the success rates of the countries after the source
+
+	// Settlement timing at the time of routing, see CountryGraph.estimateCompletion
+	EstimatedCompletionAt time.Time `json:"estimated_completion_at"`
+	EstimatedDurationMs   int64     `json:"estimated_duration_ms"` // Until EstimatedCompletionAt
+	WaitMs                int64     `json:"wait_ms"`               // Time spent waiting for settlement windows and batches
+	WithinHours           bool      `json:"within_hours"`          // Every country accepts the payment without waiting
 }
 
 // CountryGraph holds the routing graph with countries
@@ -44,6 +69,8 @@ type CountryGraph struct {
 	nodes    map[string]*CountryNode
 	edges    map[string]map[string]*CountryEdge // source -> target -> edge
 	blocked  map[string]bool                    // Blocked country codes
+	version  uint64                             // Bumped on every change, invalidates cached routes
+	now      func() time.Time                   // Overrides time.Now in tests
 }
 
 // NewCountryGraph creates a new country routing graph
@@ -60,6 +87,7 @@ func (g *CountryGraph) AddNode(node *CountryNode) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 	g.nodes[node.Code] = node
+	g.version++
 }
 
 // AddEdge adds a trading edge between countries
@@ -80,8 +108,125 @@ func (g *CountryGraph) AddEdge(edge *CountryEdge) {
 		SourceCode: edge.TargetCode,
 		TargetCode: edge.SourceCode,
 		BaseCost:   edge.BaseCost,
+		LatencyMs:  edge.LatencyMs,
 		IsActive:   edge.IsActive,
 	}
+	g.version++
+}
+
+// RemoveEdge removes the trading edge between two countries in both directions.
+// Returns false if no such edge existed.
+func (g *CountryGraph) RemoveEdge(source, target string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	_, forward := g.edges[source][target]
+	_, reverse := g.edges[target][source]
+	delete(g.edges[source], target)
+	delete(g.edges[target], source)
+	g.version++
+	return forward || reverse
+}
+
+// Edge returns a copy of the trading edge from source to target, or nil if there is none
+func (g *CountryGraph) Edge(source, target string) *CountryEdge {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	edge, ok := g.edges[source][target]
+	if !ok {
+		return nil
+	}
+	cp := *edge
+	return &cp
+}
+
+// Country returns a copy of a country and of its trading edges, sorted by
+// partner. Returns false if the country is not in the graph.
+func (g *CountryGraph) Country(code string) (*CountryNode, []*CountryEdge, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	node, ok := g.nodes[code]
+	if !ok {
+		return nil, nil, false
+	}
+	cp := *node
+	edges := make([]*CountryEdge, 0, len(g.edges[code]))
+	for _, edge := range g.edges[code] {
+		e := *edge
+		edges = append(edges, &e)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].TargetCode < edges[j].TargetCode })
+	return &cp, edges, true
+}
+
+// SetNodeActive halts (active = false) or resumes a country.
+// Returns false if the country is not in the graph.
+func (g *CountryGraph) SetNodeActive(code string, active bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	node, ok := g.nodes[code]
+	if !ok {
+		return false
+	}
+	cp := *node
+	cp.IsActive = active
+	g.nodes[code] = &cp
+	g.version++
+	return true
+}
+
+// InactiveNodes returns the codes of halted countries
+func (g *CountryGraph) InactiveNodes() map[string]bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	inactive := make(map[string]bool)
+	for code, node := range g.nodes {
+		if !node.IsActive {
+			inactive[code] = true
+		}
+	}
+	return inactive
+}
+
+// HasNode reports whether a country is in the graph
+func (g *CountryGraph) HasNode(code string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	_, ok := g.nodes[code]
+	return ok
+}
+
+// Replace atomically swaps in the nodes and edges of src, keeping the blocked
+// set. Routing in progress finishes on the old data; src must not be used afterwards.
+func (g *CountryGraph) Replace(src *CountryGraph) {
+	src.mu.RLock()
+	nodes, edges := src.nodes, src.edges
+	src.mu.RUnlock()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nodes = nodes
+	g.edges = edges
+	g.version++
+}
+
+// CountryScore returns a country's credibility and success rate, for
+// credibility-based pricing
+func (g *CountryGraph) CountryScore(code string) (credibility, successRate float64, ok bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	node, ok := g.nodes[code]
+	if !ok {
+		return 0, 0, false
+	}
+	return node.Credibility, node.SuccessRate, true
+}
+
+// NodeCount returns the number of countries in the graph
+func (g *CountryGraph) NodeCount() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return len(g.nodes)
 }
 
 // SetBlocked updates the set of blocked countries
@@ -93,6 +238,7 @@ func (g *CountryGraph) SetBlocked(blockedCodes []string) {
 	for _, code := range blockedCodes {
 		g.blocked[code] = true
 	}
+	g.version++
 }
 
 // IsBlocked checks if a country is blocked
@@ -102,6 +248,69 @@ func (g *CountryGraph) IsBlocked(code string) bool {
 	return g.blocked[code]
 }
 
+// BlockedCodes returns the blocked countries, sorted
+func (g *CountryGraph) BlockedCodes() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	codes := make([]string, 0, len(g.blocked))
+	for code := range g.blocked {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// FXRates returns each country's exchange rate to USD, keyed by country code
+func (g *CountryGraph) FXRates() map[string]float64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	rates := make(map[string]float64, len(g.nodes))
+	for code, node := range g.nodes {
+		if node.FXRate > 0 {
+			rates[code] = node.FXRate
+		}
+	}
+	return rates
+}
+
+// CurrencyRates returns each currency's exchange rate to USD, keyed by currency
+func (g *CountryGraph) CurrencyRates() map[string]float64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	rates := make(map[string]float64)
+	for _, node := range g.nodes {
+		if node.Currency != "" && node.FXRate > 0 {
+			rates[node.Currency] = node.FXRate
+		}
+	}
+	return rates
+}
+
+// ApplyFXRates updates the exchange rate of every country whose currency is
+// in rates (keyed by currency) and returns the resulting FXRates
+func (g *CountryGraph) ApplyFXRates(rates map[string]float64) map[string]float64 {
+	g.mu.Lock()
+	changed := false
+	for code, node := range g.nodes {
+		rate, ok := rates[node.Currency]
+		if !ok || rate <= 0 || rate == node.FXRate {
+			continue
+		}
+		cp := *node
+		cp.FXRate = rate
+		g.nodes[code] = &cp
+		changed = true
+	}
+	if changed {
+		g.version++
+	}
+	g.mu.Unlock()
+
+	return g.FXRates()
+}
+
 // GetEdgeWeight calculates the edge weight using the formula:
 // Weight = 0.8 * Cost + 0.1 * (1 - Credibility) + 0.1 * (1 - SuccessRate)
 // 
@@ -125,11 +334,40 @@ func (g *CountryGraph) GetEdgeWeight(edge *CountryEdge) float64 {
 	return weight
 }
 
+// edgeWeight weighs an edge for a routing preference. Caller must hold at least RLock.
+func (g *CountryGraph) edgeWeight(edge *CountryEdge, pref Preference) float64 {
+	if pref.IsZero() {
+		return g.GetEdgeWeight(edge)
+	}
+	risk := 1.0
+	if targetNode := g.nodes[edge.TargetCode]; targetNode != nil {
+		risk = ((1 - targetNode.Credibility) + (1 - targetNode.SuccessRate)) / 2
+	}
+	return pref.weigh(edge.BaseCost, edge.latency(), risk)
+}
+
+// EdgeWeightBetween returns the routing weight of the edge source→target.
+// Returns false if no such edge exists.
+func (g *CountryGraph) EdgeWeightBetween(source, target string) (float64, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	edge, ok := g.edges[source][target]
+	if !ok {
+		return 0, false
+	}
+	return g.GetEdgeWeight(edge), true
+}
+
 // CountryRouter provides K-shortest path finding for countries
 type CountryRouter struct {
 	graph           *CountryGraph
 	k               int     // Number of paths to find (default 3)
 	hopFeePercent   float64 // Fee per hop (default 0.0002 = 0.02%)
+	preference      Preference
+	constraints     RouteConstraints
+	cache           *routeCache // Shared by copies from WithPreference and WithConstraints
+	hopTimes        *HopTimes   // Historical corridor processing times, for completion estimates
 }
 
 // NewCountryRouter creates a new country router
@@ -144,17 +382,109 @@ func NewCountryRouter(graph *CountryGraph, k int) *CountryRouter {
 	}
 }
 
+// SetCache enables caching of up to size results for ttl. Cached results are
+// also dropped whenever the graph changes. size <= 0 disables the cache.
+func (r *CountryRouter) SetCache(size int, ttl time.Duration) {
+	if size <= 0 || ttl <= 0 {
+		r.cache = nil
+		return
+	}
+	r.cache = newRouteCache(size, ttl)
+}
+
+// SetHopTimes sets the processing times used to estimate when paths complete
+func (r *CountryRouter) SetHopTimes(times *HopTimes) {
+	r.hopTimes = times
+}
+
+// WithPreference returns a copy of the router that optimizes for pref
+func (r *CountryRouter) WithPreference(pref Preference) *CountryRouter {
+	cp := *r
+	cp.preference = pref
+	return &cp
+}
+
+// WithConstraints returns a copy of the router that only returns paths satisfying c
+func (r *CountryRouter) WithConstraints(c RouteConstraints) *CountryRouter {
+	cp := *r
+	cp.constraints = c
+	return &cp
+}
+
+// BestRoute returns the country codes of the lowest-weight path between two countries
+func (r *CountryRouter) BestRoute(ctx context.Context, source, target string) ([]string, error) {
+	paths, err := r.FindKShortestPaths(ctx, source, target, nil)
+	if err != nil {
+		return nil, err
+	}
+	return paths[0].Nodes, nil
+}
+
 // FindKShortestPaths finds the K shortest paths between countries
 // blockedCodes are countries to exclude from routing
 func (r *CountryRouter) FindKShortestPaths(ctx context.Context, source, target string, blockedCodes []string) ([]*CountryPath, error) {
+	if err := r.constraints.Validate(); err != nil {
+		return nil, err
+	}
+
 	r.graph.mu.RLock()
 	defer r.graph.mu.RUnlock()
-	
+
+	now := r.graph.clock()
+	var closed []string
+	if r.constraints.PreferOpen {
+		closed = r.graph.closedCountries(now)
+	}
+
+	var paths []*CountryPath
+	var err error
+	if r.cache == nil {
+		paths, err = r.findPaths(ctx, source, target, blockedCodes, closed)
+	} else {
+		paths, err = r.cachedPaths(ctx, source, target, blockedCodes, closed)
+	}
+	if err != nil {
+		return paths, err
+	}
+
+	// Completion depends on the time, so it is estimated after the cache
+	for _, path := range paths {
+		r.graph.estimateCompletion(path, now, r.hopTimes)
+	}
+	return paths, nil
+}
+
+// cachedPaths returns findPaths from the cache, computing it on a miss. The
+// caller holds the graph read lock.
+func (r *CountryRouter) cachedPaths(ctx context.Context, source, target string, blockedCodes, closed []string) ([]*CountryPath, error) {
+	key := r.cacheKey(source, target, blockedCodes, closed)
+	if paths, ok := r.cache.get(key, r.graph.version); ok {
+		metrics.RouteCacheLookups.Inc("hit")
+		return clonePaths(paths), nil
+	}
+	metrics.RouteCacheLookups.Inc("miss")
+
+	paths, err := r.findPaths(ctx, source, target, blockedCodes, closed)
+	if err != nil {
+		return paths, err
+	}
+	r.cache.put(key, r.graph.version, paths)
+	return clonePaths(paths), nil
+}
+
+// findPaths computes the paths for FindKShortestPaths, ranking paths avoiding
+// the closed countries first. The caller holds the graph read lock.
+func (r *CountryRouter) findPaths(ctx context.Context, source, target string, blockedCodes, closed []string) ([]*CountryPath, error) {
+	defer metrics.RouteComputeDuration.ObserveSince(time.Now(), "country")
+
 	// Build blocked set
 	blocked := make(map[string]bool)
 	for _, code := range blockedCodes {
 		blocked[code] = true
 	}
+	for _, code := range r.constraints.Avoid {
+		blocked[code] = true
+	}
 	// Also add graph-level blocked
 	for code := range r.graph.blocked {
 		blocked[code] = true
@@ -175,10 +505,56 @@ func (r *CountryRouter) FindKShortestPaths(ctx context.Context, source, target s
 	if _, ok := r.graph.nodes[target]; !ok {
 		return nil, fmt.Errorf("target country not found: %s", target)
 	}
-	
+
+	paths, err := r.routeAround(ctx, source, target, blocked)
+	if err != nil || len(closed) == 0 {
+		return paths, err
+	}
+
+	// Route around the closed countries too, keeping the endpoints and
+	// waypoints, and rank those paths first
+	open := make(map[string]bool, len(blocked)+len(closed))
+	for code := range blocked {
+		open[code] = true
+	}
+	for _, code := range closed {
+		open[code] = true
+	}
+	delete(open, source)
+	delete(open, target)
+	for _, code := range r.constraints.Via {
+		delete(open, code)
+	}
+	preferred, err := r.routeAround(ctx, source, target, open)
+	if err != nil {
+		return paths, nil // Every path passes a closed country
+	}
+	for _, path := range paths {
+		if len(preferred) < r.k && !containsCountryPath(preferred, path) {
+			preferred = append(preferred, path)
+		}
+	}
+	return preferred, nil
+}
+
+// routeAround finds the paths avoiding blocked, through the waypoints if any.
+// The caller holds the graph read lock.
+func (r *CountryRouter) routeAround(ctx context.Context, source, target string, blocked map[string]bool) ([]*CountryPath, error) {
+	if len(r.constraints.Via) > 0 {
+		return r.findViaPaths(ctx, source, target, blocked)
+	}
+	return r.yen(ctx, source, target, blocked, r.constraints.MaxHops)
+}
+
+// yen runs Yen's algorithm for up to K loopless paths of at most maxHops hops
+// (0 = unlimited). The caller holds the graph read lock.
+func (r *CountryRouter) yen(ctx context.Context, source, target string, blocked map[string]bool, maxHops int) ([]*CountryPath, error) {
 	// Find shortest path first using Dijkstra
-	shortestPath := r.dijkstra(source, target, nil, blocked)
+	shortestPath := r.shortestPath(source, target, nil, blocked, maxHops)
 	if shortestPath == nil {
+		if maxHops > 0 {
+			return nil, fmt.Errorf("no path found from %s to %s within %d hops", source, target, maxHops)
+		}
 		return nil, fmt.Errorf("no path found from %s to %s", source, target)
 	}
 	
@@ -200,6 +576,14 @@ func (r *CountryRouter) FindKShortestPaths(ctx context.Context, source, target s
 		prevPath := A[k-1]
 		
 		for i := 0; i < len(prevPath.Nodes)-1; i++ {
+			// Hops left for the spur once the root path is taken
+			spurHops := 0
+			if maxHops > 0 {
+				if spurHops = maxHops - i; spurHops <= 0 {
+					break
+				}
+			}
+
 			spurNode := prevPath.Nodes[i]
 			rootPath := prevPath.Nodes[:i+1]
 			
@@ -226,7 +610,7 @@ func (r *CountryRouter) FindKShortestPaths(ctx context.Context, source, target s
 				excludedNodes[prevPath.Nodes[j]] = true
 			}
 			
-			spurPath := r.dijkstra(spurNode, target, excludedEdges, excludedNodes)
+			spurPath := r.shortestPath(spurNode, target, excludedEdges, excludedNodes, spurHops)
 			
 			if spurPath != nil {
 				totalPath := r.combinePaths(rootPath, spurPath)
@@ -249,6 +633,14 @@ func (r *CountryRouter) FindKShortestPaths(ctx context.Context, source, target s
 	return A, nil
 }
 
+// shortestPath finds the lowest-weight path of at most maxHops hops (0 = unlimited)
+func (r *CountryRouter) shortestPath(source, target string, excludedEdges, excludedNodes map[string]bool, maxHops int) *CountryPath {
+	if maxHops > 0 {
+		return r.boundedShortestPath(source, target, excludedEdges, excludedNodes, maxHops)
+	}
+	return r.dijkstra(source, target, excludedEdges, excludedNodes)
+}
+
 // dijkstra finds shortest path using Dijkstra's algorithm
 func (r *CountryRouter) dijkstra(source, target string, excludedEdges, excludedNodes map[string]bool) *CountryPath {
 	if excludedNodes[source] || excludedNodes[target] {
@@ -293,7 +685,7 @@ func (r *CountryRouter) dijkstra(source, target string, excludedEdges, excludedN
 				continue
 			}
 			
-			weight := r.graph.GetEdgeWeight(edge)
+			weight := r.graph.edgeWeight(edge, r.preference)
 			newDist := dist[current.node] + weight
 			
 			if newDist < dist[targetCode] {
@@ -335,7 +727,7 @@ func (r *CountryRouter) combinePaths(rootNodes []string, spurPath *CountryPath)
 	for i := 0; i < len(rootNodes)-1; i++ {
 		if edges, ok := r.graph.edges[rootNodes[i]]; ok {
 			if edge, ok := edges[rootNodes[i+1]]; ok {
-				combined.TotalWeight += r.graph.GetEdgeWeight(edge)
+				combined.TotalWeight += r.graph.edgeWeight(edge, r.preference)
 			}
 		}
 	}
@@ -363,6 +755,18 @@ func (r *CountryRouter) calculatePathFees(path *CountryPath) {
 		path.FinalAmount = 1.0
 		path.TotalFeePercent = 0
 	}
+
+	// Latency and reliability annotations for comparing paths
+	path.TotalLatencyMs = 0
+	path.Reliability = 1.0
+	for i := 0; i+1 < len(path.Nodes); i++ {
+		if edge, ok := r.graph.edges[path.Nodes[i]][path.Nodes[i+1]]; ok {
+			path.TotalLatencyMs += edge.latency()
+		}
+		if node, ok := r.graph.nodes[path.Nodes[i+1]]; ok {
+			path.Reliability *= node.SuccessRate
+		}
+	}
 }
 
 // Helper functions
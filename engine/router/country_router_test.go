@@ -0,0 +1,244 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+// TestCountryGraphRemoveEdge verifies removed corridors are no longer routed through
+func TestCountryGraphRemoveEdge(t *testing.T) {
+	graph := BuildCountryGraphWithDefaults()
+	countryRouter := NewCountryRouter(graph, 3)
+
+	if _, ok := graph.EdgeWeightBetween("USA", "GBR"); !ok {
+		t.Fatal("expected default USA-GBR edge")
+	}
+	if !graph.RemoveEdge("GBR", "USA") {
+		t.Fatal("expected RemoveEdge to report an existing edge")
+	}
+	if graph.RemoveEdge("GBR", "USA") {
+		t.Fatal("expected RemoveEdge to report no edge the second time")
+	}
+	for _, pair := range [][2]string{{"USA", "GBR"}, {"GBR", "USA"}} {
+		if _, ok := graph.EdgeWeightBetween(pair[0], pair[1]); ok {
+			t.Errorf("edge %s->%s still present", pair[0], pair[1])
+		}
+	}
+
+	paths, err := countryRouter.FindKShortestPaths(context.Background(), "USA", "GBR", nil)
+	if err != nil {
+		t.Fatalf("Failed to find paths: %v", err)
+	}
+	for _, path := range paths {
+		if len(path.Nodes) == 2 {
+			t.Errorf("direct USA->GBR path returned after removal: %v", path.Nodes)
+		}
+	}
+
+	graph.AddEdge(&CountryEdge{SourceCode: "USA", TargetCode: "GBR", BaseCost: DefaultTradeBaseCost, IsActive: true})
+	if _, ok := graph.EdgeWeightBetween("GBR", "USA"); !ok {
+		t.Error("expected re-added edge in both directions")
+	}
+}
+
+// TestCountryGraphReplace verifies a refresh swaps node data while keeping blocked countries
+func TestCountryGraphReplace(t *testing.T) {
+	graph := BuildCountryGraphWithDefaults()
+	graph.SetBlocked([]string{"RUS"})
+
+	fresh := NewCountryGraph()
+	fresh.AddNode(&CountryNode{Code: "USA", Credibility: 0.5, SuccessRate: 0.5, FXRate: 1, IsActive: true})
+	fresh.AddNode(&CountryNode{Code: "GBR", Credibility: 0.9, SuccessRate: 0.9, FXRate: 0.8, IsActive: true})
+	fresh.AddEdge(&CountryEdge{SourceCode: "USA", TargetCode: "GBR", BaseCost: 0.02, IsActive: true})
+
+	before, _ := graph.EdgeWeightBetween("USA", "GBR")
+	graph.Replace(fresh)
+
+	if graph.NodeCount() != 2 {
+		t.Fatalf("expected 2 countries after replace, got %d", graph.NodeCount())
+	}
+	if rate := graph.FXRates()["GBR"]; rate != 0.8 {
+		t.Errorf("expected refreshed GBR FX rate 0.8, got %v", rate)
+	}
+	after, ok := graph.EdgeWeightBetween("USA", "GBR")
+	if !ok || after == before {
+		t.Errorf("expected refreshed USA->GBR weight, got %v (was %v)", after, before)
+	}
+	if !graph.IsBlocked("RUS") {
+		t.Error("blocked countries lost on replace")
+	}
+}
+
+// TestCountryRouterPreference verifies cheapest and fastest pick different corridors
+func TestCountryRouterPreference(t *testing.T) {
+	graph := NewCountryGraph()
+	for _, code := range []string{"AAA", "BBB", "CCC", "DDD"} {
+		graph.AddNode(&CountryNode{Code: code, Credibility: 0.9, SuccessRate: 0.9, FXRate: 1, IsActive: true})
+	}
+	// AAA-BBB-DDD is cheap but slow, AAA-CCC-DDD is fast but expensive
+	graph.AddEdge(&CountryEdge{SourceCode: "AAA", TargetCode: "BBB", BaseCost: 0.001, LatencyMs: 2000, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "BBB", TargetCode: "DDD", BaseCost: 0.001, LatencyMs: 2000, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "AAA", TargetCode: "CCC", BaseCost: 0.05, LatencyMs: 50, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "CCC", TargetCode: "DDD", BaseCost: 0.05, LatencyMs: 50, IsActive: true})
+
+	countryRouter := NewCountryRouter(graph, 2)
+	for name, tc := range map[string]struct {
+		pref Preference
+		via  string
+	}{
+		"cheapest": {PreferCheapest, "BBB"},
+		"fastest":  {PreferFastest, "CCC"},
+	} {
+		paths, err := countryRouter.WithPreference(tc.pref).FindKShortestPaths(context.Background(), "AAA", "DDD", nil)
+		if err != nil {
+			t.Fatalf("%s: failed to find paths: %v", name, err)
+		}
+		if len(paths) != 2 || paths[0].Nodes[1] != tc.via {
+			t.Errorf("%s: expected best path via %s, got %v", name, tc.via, paths[0].Nodes)
+		}
+	}
+
+	paths, _ := countryRouter.WithPreference(PreferFastest).FindKShortestPaths(context.Background(), "AAA", "DDD", nil)
+	if paths[0].TotalLatencyMs != 100 || paths[1].TotalLatencyMs != 4000 {
+		t.Errorf("unexpected latencies %d, %d", paths[0].TotalLatencyMs, paths[1].TotalLatencyMs)
+	}
+	if _, err := ParsePreference("slowest"); err == nil {
+		t.Error("expected unknown preference to be rejected")
+	}
+}
+
+// TestCountryRouterConstraints verifies max hops, avoided countries and waypoints
+func TestCountryRouterConstraints(t *testing.T) {
+	graph := NewCountryGraph()
+	for _, code := range []string{"AAA", "BBB", "CCC", "DDD", "EEE"} {
+		graph.AddNode(&CountryNode{Code: code, Credibility: 0.9, SuccessRate: 0.9, FXRate: 1, IsActive: true})
+	}
+	// Cheapest is the long AAA-BBB-CCC-DDD chain; AAA-EEE-DDD is shorter but costly
+	graph.AddEdge(&CountryEdge{SourceCode: "AAA", TargetCode: "BBB", BaseCost: 0.001, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "BBB", TargetCode: "CCC", BaseCost: 0.001, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "CCC", TargetCode: "DDD", BaseCost: 0.001, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "AAA", TargetCode: "EEE", BaseCost: 0.05, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "EEE", TargetCode: "DDD", BaseCost: 0.05, IsActive: true})
+
+	countryRouter := NewCountryRouter(graph, 3).WithPreference(PreferCheapest)
+	ctx := context.Background()
+
+	paths, err := countryRouter.WithConstraints(RouteConstraints{MaxHops: 2}).FindKShortestPaths(ctx, "AAA", "DDD", nil)
+	if err != nil {
+		t.Fatalf("max hops: %v", err)
+	}
+	for _, path := range paths {
+		if path.HopCount > 2 {
+			t.Errorf("max hops: path %v exceeds 2 hops", path.Nodes)
+		}
+	}
+	if _, err := countryRouter.WithConstraints(RouteConstraints{MaxHops: 1}).FindKShortestPaths(ctx, "AAA", "DDD", nil); err == nil {
+		t.Error("expected no path within 1 hop")
+	}
+
+	paths, err = countryRouter.WithConstraints(RouteConstraints{Avoid: []string{"CCC"}}).FindKShortestPaths(ctx, "AAA", "DDD", nil)
+	if err != nil || len(paths) != 1 || paths[0].Nodes[1] != "EEE" {
+		t.Errorf("avoid: expected only the EEE path, got %v (%v)", paths, err)
+	}
+
+	paths, err = countryRouter.WithConstraints(RouteConstraints{Via: []string{"EEE"}}).FindKShortestPaths(ctx, "AAA", "DDD", nil)
+	if err != nil {
+		t.Fatalf("via: %v", err)
+	}
+	for _, path := range paths {
+		if len(path.Nodes) != 3 || path.Nodes[1] != "EEE" {
+			t.Errorf("via: path %v does not pass EEE", path.Nodes)
+		}
+	}
+	if _, err := countryRouter.WithConstraints(RouteConstraints{Via: []string{"EEE"}, Avoid: []string{"EEE"}}).FindKShortestPaths(ctx, "AAA", "DDD", nil); err == nil {
+		t.Error("expected avoided waypoint to be rejected")
+	}
+}
+
+// TestCountryRouterCache verifies cached routes are reused until the graph changes
+func TestCountryRouterCache(t *testing.T) {
+	graph := BuildCountryGraphWithDefaults()
+	countryRouter := NewCountryRouter(graph, 3)
+	countryRouter.SetCache(2, time.Minute)
+	ctx := context.Background()
+
+	hits := metrics.RouteCacheLookups.Value("hit")
+	first, err := countryRouter.FindKShortestPaths(ctx, "USA", "THA", []string{"RUS", "CHN"})
+	if err != nil {
+		t.Fatalf("Failed to find paths: %v", err)
+	}
+	first[0].Nodes[0] = "XXX" // Callers must not be able to corrupt the cache
+
+	// Same request with the blocked set in another order is a hit
+	second, _ := countryRouter.FindKShortestPaths(ctx, "USA", "THA", []string{"CHN", "RUS", "CHN"})
+	if got := metrics.RouteCacheLookups.Value("hit") - hits; got != 1 {
+		t.Fatalf("expected 1 cache hit, got %v", got)
+	}
+	if second[0].Nodes[0] != "USA" {
+		t.Errorf("cached path was modified by a caller: %v", second[0].Nodes)
+	}
+
+	// A different preference is a different entry
+	countryRouter.WithPreference(PreferFastest).FindKShortestPaths(ctx, "USA", "THA", []string{"RUS", "CHN"})
+	if got := metrics.RouteCacheLookups.Value("hit") - hits; got != 1 {
+		t.Errorf("expected preference to miss the cache, got %v hits", got)
+	}
+	if countryRouter.cache.len() != 2 {
+		t.Errorf("expected 2 cached entries, got %d", countryRouter.cache.len())
+	}
+
+	// Blocking a country invalidates the cache
+	graph.SetBlocked([]string{second[0].Nodes[1]})
+	third, err := countryRouter.FindKShortestPaths(ctx, "USA", "THA", []string{"RUS", "CHN"})
+	if got := metrics.RouteCacheLookups.Value("hit") - hits; got != 1 {
+		t.Errorf("expected graph change to miss the cache, got %v hits", got)
+	}
+	if err == nil && pathsEqualCountry(third[0].Nodes, second[0].Nodes) && len(second[0].Nodes) > 2 {
+		t.Errorf("stale path returned after blocking %s", second[0].Nodes[1])
+	}
+}
+
+// TestCountryGraphHaltResume verifies halted countries are reported until resumed
+func TestCountryGraphHaltResume(t *testing.T) {
+	graph := BuildCountryGraphWithDefaults()
+	if len(graph.InactiveNodes()) != 0 {
+		t.Fatal("expected no halted countries by default")
+	}
+	if graph.SetNodeActive("XXX", false) {
+		t.Fatal("expected SetNodeActive to report an unknown country")
+	}
+
+	if !graph.SetNodeActive("SGP", false) {
+		t.Fatal("expected SetNodeActive to find SGP")
+	}
+	if halted := graph.InactiveNodes(); len(halted) != 1 || !halted["SGP"] {
+		t.Fatalf("InactiveNodes = %v, want SGP", halted)
+	}
+
+	graph.SetNodeActive("SGP", true)
+	if halted := graph.InactiveNodes(); len(halted) != 0 {
+		t.Fatalf("InactiveNodes = %v after resume, want none", halted)
+	}
+}
+
+// TestCountryGraphApplyFXRates verifies fetched rates reach every country using the currency
+func TestCountryGraphApplyFXRates(t *testing.T) {
+	graph := NewCountryGraph()
+	graph.AddNode(&CountryNode{Code: "DEU", Currency: "EUR", FXRate: 0.92, IsActive: true})
+	graph.AddNode(&CountryNode{Code: "FRA", Currency: "EUR", FXRate: 0.92, IsActive: true})
+	graph.AddNode(&CountryNode{Code: "JPN", Currency: "JPY", FXRate: 149.5, IsActive: true})
+
+	rates := graph.ApplyFXRates(map[string]float64{"EUR": 0.95, "GBP": 0.8})
+	if rates["DEU"] != 0.95 || rates["FRA"] != 0.95 {
+		t.Errorf("EUR countries = %v, want 0.95", rates)
+	}
+	if rates["JPN"] != 149.5 {
+		t.Errorf("JPN = %v, want unchanged 149.5", rates["JPN"])
+	}
+	if got := graph.CurrencyRates(); got["EUR"] != 0.95 || len(got) != 2 {
+		t.Errorf("CurrencyRates = %v, want EUR 0.95 and JPY", got)
+	}
+}
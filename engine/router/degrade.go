@@ -0,0 +1,137 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// ErrUnknownEdge is returned for an edge that isn't in the graph
+var ErrUnknownEdge = errors.New("unknown edge")
+
+// Degradation is chaos injected into an edge at runtime: extra latency, which
+// raises the edge's routing weight, and withheld liquidity, which lowers the
+// amount it can carry. The zero value is a healthy edge.
+type Degradation struct {
+	ExtraLatencyMs     int64   `json:"extra_latency_ms"`
+	LiquidityReduction float64 `json:"liquidity_reduction"` // Fraction of provisioned liquidity withheld, 0 to 1
+}
+
+// IsZero reports whether d leaves the edge healthy
+func (d Degradation) IsZero() bool {
+	return d.ExtraLatencyMs == 0 && d.LiquidityReduction == 0
+}
+
+// Validate checks the degradation's bounds
+func (d Degradation) Validate() error {
+	if d.ExtraLatencyMs < 0 {
+		return errors.New("extra_latency_ms must not be negative")
+	}
+	if d.LiquidityReduction < 0 || d.LiquidityReduction > 1 {
+		return errors.New("liquidity_reduction must be between 0 and 1")
+	}
+	return nil
+}
+
+// EdgeDegradation is a point-in-time view of a degraded edge
+type EdgeDegradation struct {
+	SourceID string `json:"source_id"`
+	TargetID string `json:"target_id"`
+	Degradation
+	LatencyMs          int64 `json:"latency_ms"`           // Configured latency
+	EffectiveLatencyMs int64 `json:"effective_latency_ms"` // Latency routing sees
+	Withheld           int64 `json:"withheld"`
+	Available          int64 `json:"available"`
+}
+
+// EffectiveLatency returns the latency including injected degradation
+func (e *Edge) EffectiveLatency() int64 {
+	return e.Latency + e.ExtraLatency
+}
+
+// Withheld returns the liquidity taken out of Available by degradation
+func (e *Edge) Withheld() int64 {
+	return int64(float64(e.LiquidityVolume) * e.LiquidityCut)
+}
+
+func (e *Edge) degradation() Degradation {
+	return Degradation{ExtraLatencyMs: e.ExtraLatency, LiquidityReduction: e.LiquidityCut}
+}
+
+func (e *Edge) degradationView() EdgeDegradation {
+	return EdgeDegradation{
+		SourceID:           e.SourceID,
+		TargetID:           e.TargetID,
+		Degradation:        e.degradation(),
+		LatencyMs:          e.Latency,
+		EffectiveLatencyMs: e.EffectiveLatency(),
+		Withheld:           e.Withheld(),
+		Available:          e.Available(),
+	}
+}
+
+// DegradeEdge replaces the degradation of the edge sourceID->targetID, the
+// zero Degradation restoring it, and returns the previous one. Reducing
+// liquidity requires an edge that tracks it. Changes of available liquidity
+// are reported to the liquidity callback.
+func (g *Graph) DegradeEdge(sourceID, targetID string, d Degradation) (Degradation, error) {
+	if err := d.Validate(); err != nil {
+		return Degradation{}, err
+	}
+
+	g.mu.Lock()
+	edge, ok := g.edges[sourceID][targetID]
+	if !ok {
+		g.mu.Unlock()
+		return Degradation{}, fmt.Errorf("%w %s -> %s", ErrUnknownEdge, sourceID, targetID)
+	}
+	if d.LiquidityReduction > 0 && edge.LiquidityVolume == 0 {
+		g.mu.Unlock()
+		return Degradation{}, fmt.Errorf("edge %s -> %s does not track liquidity", sourceID, targetID)
+	}
+	prev := edge.degradation()
+	old := edge.Available()
+	edge.ExtraLatency, edge.LiquidityCut = d.ExtraLatencyMs, d.LiquidityReduction
+	var changes []LiquidityChange
+	if edge.Available() != old {
+		changes = append(changes, LiquidityChange{SourceID: sourceID, TargetID: targetID, Old: old, New: edge.Available()})
+	}
+	cb := g.onLiquidity
+	g.mu.Unlock()
+
+	notifyLiquidity(cb, changes)
+	return prev, nil
+}
+
+// Degradations returns every degraded edge, ordered by source then target
+func (g *Graph) Degradations() []EdgeDegradation {
+	g.mu.RLock()
+	degraded := make([]EdgeDegradation, 0)
+	for _, targets := range g.edges {
+		for _, edge := range targets {
+			if !edge.degradation().IsZero() {
+				degraded = append(degraded, edge.degradationView())
+			}
+		}
+	}
+	g.mu.RUnlock()
+
+	sort.Slice(degraded, func(i, j int) bool {
+		if degraded[i].SourceID != degraded[j].SourceID {
+			return degraded[i].SourceID < degraded[j].SourceID
+		}
+		return degraded[i].TargetID < degraded[j].TargetID
+	})
+	return degraded
+}
+
+// EdgeDegradation returns the current state of the edge sourceID->targetID
+func (g *Graph) EdgeDegradation(sourceID, targetID string) (EdgeDegradation, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	edge, ok := g.edges[sourceID][targetID]
+	if !ok {
+		return EdgeDegradation{}, false
+	}
+	return edge.degradationView(), true
+}
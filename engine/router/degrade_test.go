@@ -0,0 +1,91 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// degradeGraph has two routes from a to d: the cheaper via b and one via c
+func degradeGraph() *Graph {
+	g := NewGraph()
+	for _, id := range []string{"a", "b", "c", "d"} {
+		g.AddNode(&Node{ID: id, IsActive: true})
+	}
+	g.AddEdge(&Edge{SourceID: "a", TargetID: "b", BaseFee: 0.001, Latency: 20, LiquidityVolume: 1000, IsActive: true})
+	g.AddEdge(&Edge{SourceID: "b", TargetID: "d", BaseFee: 0.001, Latency: 20, IsActive: true})
+	g.AddEdge(&Edge{SourceID: "a", TargetID: "c", BaseFee: 0.0015, Latency: 30, IsActive: true})
+	g.AddEdge(&Edge{SourceID: "c", TargetID: "d", BaseFee: 0.0015, Latency: 30, IsActive: true})
+	return g
+}
+
+func bestPath(t *testing.T, r *Router, amount int64) string {
+	t.Helper()
+	paths, err := r.FindKShortestPaths(context.Background(), "a", "d", amount)
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("FindKShortestPaths = %v, %v", paths, err)
+	}
+	return strings.Join(paths[0].Nodes, ",")
+}
+
+// TestDegradedEdgeIsAvoided verifies injected latency and withheld liquidity
+// steer routing away from an edge until it is restored
+func TestDegradedEdgeIsAvoided(t *testing.T) {
+	g := degradeGraph()
+	r := NewRouter(g, 2)
+	if got := bestPath(t, r, 0); got != "a,b,d" {
+		t.Fatalf("healthy route = %s, want a,b,d", got)
+	}
+
+	if _, err := g.DegradeEdge("a", "b", Degradation{ExtraLatencyMs: 500}); err != nil {
+		t.Fatalf("DegradeEdge failed: %v", err)
+	}
+	if got := bestPath(t, r, 0); got != "a,c,d" {
+		t.Errorf("route with latency injected = %s, want a,c,d", got)
+	}
+	paths, _ := r.FindKShortestPaths(context.Background(), "a", "d", 0)
+	if paths[1].TotalLatency != 540 {
+		t.Errorf("degraded path latency = %d, want 540", paths[1].TotalLatency)
+	}
+
+	// Withheld liquidity only rules the edge out for amounts it can't carry
+	var changes []LiquidityChange
+	g.SetLiquidityCallback(func(c LiquidityChange) { changes = append(changes, c) })
+	prev, err := g.DegradeEdge("a", "b", Degradation{LiquidityReduction: 0.75})
+	if err != nil || prev.ExtraLatencyMs != 500 {
+		t.Fatalf("DegradeEdge = %+v, %v", prev, err)
+	}
+	if len(changes) != 1 || changes[0].New != 250 {
+		t.Errorf("liquidity changes = %+v, want 1000 -> 250", changes)
+	}
+	if got := bestPath(t, r, 200); got != "a,b,d" {
+		t.Errorf("route for 200 = %s, want a,b,d", got)
+	}
+	if got := bestPath(t, r, 300); got != "a,c,d" {
+		t.Errorf("route for 300 = %s, want a,c,d", got)
+	}
+	if d := g.Degradations(); len(d) != 1 || d[0].Withheld != 750 || d[0].Available != 250 {
+		t.Errorf("Degradations = %+v", d)
+	}
+
+	g.DegradeEdge("a", "b", Degradation{})
+	if got := bestPath(t, r, 300); got != "a,b,d" || len(g.Degradations()) != 0 {
+		t.Errorf("restored route = %s, degradations %+v", got, g.Degradations())
+	}
+}
+
+func TestDegradeEdgeRejectsInvalid(t *testing.T) {
+	g := degradeGraph()
+	if _, err := g.DegradeEdge("a", "x", Degradation{ExtraLatencyMs: 10}); !errors.Is(err, ErrUnknownEdge) {
+		t.Errorf("unknown edge error = %v", err)
+	}
+	if _, err := g.DegradeEdge("a", "c", Degradation{LiquidityReduction: 0.5}); err == nil {
+		t.Error("expected error reducing liquidity of an untracked edge")
+	}
+	for _, d := range []Degradation{{ExtraLatencyMs: -1}, {LiquidityReduction: 1.5}} {
+		if _, err := g.DegradeEdge("a", "b", d); err == nil {
+			t.Errorf("expected error for %+v", d)
+		}
+	}
+}
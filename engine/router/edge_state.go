@@ -0,0 +1,76 @@
+package router
+
+import "fmt"
+
+// SetEdgeActive enables or disables the edge sourceID->targetID, reporting
+// whether it exists
+func (g *Graph) SetEdgeActive(sourceID, targetID string, active bool) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	edge, ok := g.edges[sourceID][targetID]
+	if ok {
+		edge.IsActive = active
+	}
+	return ok
+}
+
+// Edge returns a copy of the edge sourceID->targetID, or nil
+func (g *Graph) Edge(sourceID, targetID string) *Edge {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	edge, ok := g.edges[sourceID][targetID]
+	if !ok {
+		return nil
+	}
+	cp := *edge
+	return &cp
+}
+
+// EdgePatch holds the edge attributes to change; nil fields are left as they are
+type EdgePatch struct {
+	BaseFee         *float64
+	Latency         *int64
+	LiquidityVolume *int64
+	IsActive        *bool
+}
+
+// PatchEdge applies p to the edge sourceID->targetID and returns a copy of
+// the result, or ErrUnknownEdge
+func (g *Graph) PatchEdge(sourceID, targetID string, p EdgePatch) (*Edge, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	edge, ok := g.edges[sourceID][targetID]
+	if !ok {
+		return nil, fmt.Errorf("%w %s -> %s", ErrUnknownEdge, sourceID, targetID)
+	}
+	if p.BaseFee != nil {
+		edge.BaseFee = *p.BaseFee
+	}
+	if p.Latency != nil {
+		edge.Latency = *p.Latency
+	}
+	if p.LiquidityVolume != nil {
+		edge.LiquidityVolume = *p.LiquidityVolume
+	}
+	if p.IsActive != nil {
+		edge.IsActive = *p.IsActive
+	}
+	cp := *edge
+	return &cp, nil
+}
+
+// CrossingEdges returns the active edges, in either direction, between the
+// nodes in group and the rest of the mesh as [source, target] pairs
+func (g *Graph) CrossingEdges(group map[string]bool) [][2]string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	var crossing [][2]string
+	for sourceID, targets := range g.edges {
+		for targetID, edge := range targets {
+			if edge.IsActive && group[sourceID] != group[targetID] {
+				crossing = append(crossing, [2]string{sourceID, targetID})
+			}
+		}
+	}
+	return crossing
+}
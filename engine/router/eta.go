@@ -0,0 +1,70 @@
+package router
+
+import (
+	"sync"
+	"time"
+)
+
+// hopTimeWeight is the weight of each new sample in a corridor's moving average
+const hopTimeWeight = 0.2
+
+// HopTimes learns how long each corridor takes to process a payment, as an
+// exponentially weighted moving average of the observed hop durations.
+// A nil *HopTimes has no estimates.
+type HopTimes struct {
+	mu    sync.RWMutex
+	times map[string]time.Duration // "from->to" -> average
+}
+
+// NewHopTimes creates an empty set of hop times
+func NewHopTimes() *HopTimes {
+	return &HopTimes{times: make(map[string]time.Duration)}
+}
+
+// Observe records a hop from one country to the next that took d
+func (h *HopTimes) Observe(from, to string, d time.Duration) {
+	if d < 0 {
+		return
+	}
+	key := from + "->" + to
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	avg, ok := h.times[key]
+	if !ok {
+		h.times[key] = d
+		return
+	}
+	h.times[key] = avg + time.Duration(hopTimeWeight*float64(d-avg))
+}
+
+// Estimate returns the average processing time of a corridor, if one has been observed
+func (h *HopTimes) Estimate(from, to string) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	d, ok := h.times[from+"->"+to]
+	return d, ok
+}
+
+// EstimateCompletion returns when a mesh path settles if started at now:
+// each node holds the payment until its operating hours open, then the
+// payment crosses the edge in its effective latency
+func (g *Graph) EstimateCompletion(path *Path, now time.Time) time.Time {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	t := now
+	for i, id := range path.Nodes {
+		if node, ok := g.nodes[id]; ok && node.Capacity.OperatingHours != nil {
+			window := SettlementWindow{OperatingHours: *node.Capacity.OperatingHours}
+			t = window.NextAccept(t)
+		}
+		if i < len(path.Edges) {
+			t = t.Add(time.Duration(path.Edges[i].EffectiveLatency()) * time.Millisecond)
+		}
+	}
+	return t.UTC()
+}
@@ -0,0 +1,94 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHopTimesMovingAverage(t *testing.T) {
+	var none *HopTimes
+	if _, ok := none.Estimate("USA", "GBR"); ok {
+		t.Error("nil HopTimes must have no estimates")
+	}
+
+	times := NewHopTimes()
+	times.Observe("USA", "GBR", time.Second)
+	times.Observe("USA", "GBR", 2*time.Second)
+	if d, ok := times.Estimate("USA", "GBR"); !ok || d != 1200*time.Millisecond {
+		t.Errorf("Estimate = %s, %v, want 1.2s", d, ok)
+	}
+	if _, ok := times.Estimate("GBR", "USA"); ok {
+		t.Error("corridors are measured per direction")
+	}
+}
+
+func TestSettlementWindowNextSettlement(t *testing.T) {
+	// Batches every 2 hours from 08:00 UTC, accepting until 16:00
+	w := &SettlementWindow{OperatingHours: OperatingHours{Open: "08:00", Close: "18:00"}, Cutoff: "16:00", BatchMinutes: 120}
+	tests := []struct{ at, want string }{
+		{"2026-03-04T08:00:00Z", "2026-03-04T08:00:00Z"},
+		{"2026-03-04T08:01:00Z", "2026-03-04T10:00:00Z"},
+		{"2026-03-04T15:59:00Z", "2026-03-04T16:00:00Z"},
+		{"2026-03-04T16:30:00Z", "2026-03-05T08:00:00Z"}, // Past the cutoff
+	}
+	for _, tt := range tests {
+		at, _ := time.Parse(time.RFC3339, tt.at)
+		want, _ := time.Parse(time.RFC3339, tt.want)
+		if got := w.NextSettlement(at); !got.Equal(want) {
+			t.Errorf("NextSettlement(%s) = %s, want %s", tt.at, got.Format(time.RFC3339), tt.want)
+		}
+	}
+	if err := (&SettlementWindow{OperatingHours: w.OperatingHours, BatchMinutes: -1}).Validate(); err == nil {
+		t.Error("negative batch_minutes must be rejected")
+	}
+}
+
+func TestCountryRouterCompletionEstimate(t *testing.T) {
+	graph := NewCountryGraph()
+	for _, code := range []string{"USA", "GBR", "IND"} {
+		graph.AddNode(&CountryNode{Code: code, SuccessRate: 1, IsActive: true})
+	}
+	graph.AddEdge(&CountryEdge{SourceCode: "USA", TargetCode: "GBR", BaseCost: 0.001, LatencyMs: 100, IsActive: true})
+	graph.AddEdge(&CountryEdge{SourceCode: "GBR", TargetCode: "IND", BaseCost: 0.001, LatencyMs: 100, IsActive: true})
+	now, _ := time.Parse(time.RFC3339, "2026-03-04T10:05:00Z")
+	graph.now = func() time.Time { return now }
+
+	// GBR is open but settles in hourly batches
+	graph.SetCountryHours("GBR", &SettlementWindow{OperatingHours: OperatingHours{Open: "08:00", Close: "18:00", Timezone: "Europe/London"}, BatchMinutes: 60})
+	times := NewHopTimes()
+	times.Observe("USA", "GBR", 3*time.Second)
+
+	countryRouter := NewCountryRouter(graph, 1)
+	countryRouter.SetHopTimes(times)
+	paths, err := countryRouter.FindKShortestPaths(context.Background(), "USA", "IND", nil)
+	if err != nil {
+		t.Fatalf("FindKShortestPaths: %v", err)
+	}
+	path := paths[0]
+	// 3s measured to GBR, wait for the 11:00 batch, then 100ms latency to IND
+	want := time.Date(2026, 3, 4, 11, 0, 0, 100e6, time.UTC)
+	if !path.EstimatedCompletionAt.Equal(want) || !path.WithinHours {
+		t.Errorf("estimate = %s (within hours %v), want %s", path.EstimatedCompletionAt, path.WithinHours, want)
+	}
+	if path.EstimatedDurationMs != want.Sub(now).Milliseconds() || path.WaitMs != (55*time.Minute-3*time.Second).Milliseconds() {
+		t.Errorf("duration = %dms, wait = %dms", path.EstimatedDurationMs, path.WaitMs)
+	}
+}
+
+func TestGraphEstimateCompletion(t *testing.T) {
+	g := NewGraph()
+	g.AddNode(&Node{ID: "A", IsActive: true})
+	g.AddNode(&Node{ID: "B", IsActive: true, Capacity: NodeCapacity{OperatingHours: &OperatingHours{Open: "12:00", Close: "20:00"}}})
+	edge := &Edge{SourceID: "A", TargetID: "B", Latency: 50, IsActive: true}
+	g.AddEdge(edge)
+
+	now, _ := time.Parse(time.RFC3339, "2026-03-04T11:00:00Z")
+	path := &Path{Nodes: []string{"A", "B"}, Edges: []*Edge{edge}}
+	if got, want := g.EstimateCompletion(path, now), time.Date(2026, 3, 4, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("EstimateCompletion = %s, want %s", got, want)
+	}
+	if got, want := g.EstimateCompletion(path, now.Add(2*time.Hour)), now.Add(2*time.Hour+50*time.Millisecond); !got.Equal(want) {
+		t.Errorf("EstimateCompletion while open = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,226 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MeshExport is a copy of the mesh topology used for backup and restore
+type MeshExport struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+// CountryExport is a copy of the country topology used for backup and restore.
+// Corridors are bidirectional, so each appears once.
+type CountryExport struct {
+	Nodes []*CountryNode `json:"nodes"`
+	Edges []*CountryEdge `json:"edges"`
+}
+
+// validMeshTypes are the node types accepted by the admin API
+var validMeshTypes = map[string]bool{"SME": true, "LiquidityProvider": true, "Hub": true}
+
+// Export copies the mesh nodes and edges, sorted for stable output
+func (g *Graph) Export() *MeshExport {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	export := &MeshExport{
+		Nodes: make([]*Node, 0, len(g.nodes)),
+		Edges: make([]*Edge, 0),
+	}
+	for _, node := range g.nodes {
+		cp := *node
+		if node.Props != nil {
+			cp.Props = make(map[string]interface{}, len(node.Props))
+			for k, v := range node.Props {
+				cp.Props[k] = v
+			}
+		}
+		export.Nodes = append(export.Nodes, &cp)
+	}
+	for _, targets := range g.edges {
+		for _, edge := range targets {
+			cp := *edge
+			export.Edges = append(export.Edges, &cp)
+		}
+	}
+
+	sort.Slice(export.Nodes, func(i, j int) bool { return export.Nodes[i].ID < export.Nodes[j].ID })
+	sort.Slice(export.Edges, func(i, j int) bool {
+		a, b := export.Edges[i], export.Edges[j]
+		if a.SourceID != b.SourceID {
+			return a.SourceID < b.SourceID
+		}
+		return a.TargetID < b.TargetID
+	})
+	return export
+}
+
+// Validate checks that the export describes a consistent mesh
+func (e *MeshExport) Validate() error {
+	ids := make(map[string]bool, len(e.Nodes))
+	for _, node := range e.Nodes {
+		switch {
+		case node == nil || node.ID == "":
+			return fmt.Errorf("mesh node id is required")
+		case !validMeshTypes[node.Type]:
+			return fmt.Errorf("mesh node %s has invalid type %q", node.ID, node.Type)
+		case ids[node.ID]:
+			return fmt.Errorf("duplicate mesh node %s", node.ID)
+		}
+		if err := node.Capacity.Validate(); err != nil {
+			return fmt.Errorf("mesh node %s: %w", node.ID, err)
+		}
+		ids[node.ID] = true
+	}
+
+	edges := make(map[string]bool, len(e.Edges))
+	for _, edge := range e.Edges {
+		if edge == nil {
+			return fmt.Errorf("mesh edge must not be null")
+		}
+		key := edge.SourceID + "->" + edge.TargetID
+		switch {
+		case !ids[edge.SourceID] || !ids[edge.TargetID]:
+			return fmt.Errorf("mesh edge %s references an unknown node", key)
+		case edge.SourceID == edge.TargetID:
+			return fmt.Errorf("mesh edge %s is a self-loop", key)
+		case edge.BaseFee < 0 || edge.Latency < 0 || edge.LiquidityVolume < 0:
+			return fmt.Errorf("mesh edge %s has a negative fee, latency or liquidity", key)
+		case edges[key]:
+			return fmt.Errorf("duplicate mesh edge %s", key)
+		}
+		edges[key] = true
+	}
+	return nil
+}
+
+// Import validates e and atomically replaces the mesh nodes and edges with it.
+// Entropy of nodes that remain is kept; the load tracker is unaffected.
+func (g *Graph) Import(e *MeshExport) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+
+	nodes := make(map[string]*Node, len(e.Nodes))
+	for _, node := range e.Nodes {
+		cp := *node
+		nodes[node.ID] = &cp
+	}
+	edges := make(map[string]map[string]*Edge)
+	for _, edge := range e.Edges {
+		if edges[edge.SourceID] == nil {
+			edges[edge.SourceID] = make(map[string]*Edge)
+		}
+		cp := *edge
+		edges[edge.SourceID][edge.TargetID] = &cp
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.nodes = nodes
+	g.edges = edges
+	for id := range g.entropy {
+		if _, ok := nodes[id]; !ok {
+			delete(g.entropy, id)
+		}
+	}
+	return nil
+}
+
+// Export copies the countries and corridors, sorted for stable output.
+// Corridors to countries that are not in the graph cannot be routed over and are left out.
+func (g *CountryGraph) Export() *CountryExport {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	export := &CountryExport{
+		Nodes: make([]*CountryNode, 0, len(g.nodes)),
+		Edges: make([]*CountryEdge, 0),
+	}
+	for _, node := range g.nodes {
+		cp := *node
+		export.Nodes = append(export.Nodes, &cp)
+	}
+	for source, targets := range g.edges {
+		if _, ok := g.nodes[source]; !ok {
+			continue
+		}
+		for target, edge := range targets {
+			if _, ok := g.nodes[target]; !ok {
+				continue
+			}
+			// The reverse direction is recreated by AddEdge on import
+			if _, reverse := g.edges[target][source]; reverse && source > target {
+				continue
+			}
+			cp := *edge
+			export.Edges = append(export.Edges, &cp)
+		}
+	}
+
+	sort.Slice(export.Nodes, func(i, j int) bool { return export.Nodes[i].Code < export.Nodes[j].Code })
+	sort.Slice(export.Edges, func(i, j int) bool {
+		a, b := export.Edges[i], export.Edges[j]
+		if a.SourceCode != b.SourceCode {
+			return a.SourceCode < b.SourceCode
+		}
+		return a.TargetCode < b.TargetCode
+	})
+	return export
+}
+
+// Validate checks that the export describes a consistent country graph
+func (e *CountryExport) Validate() error {
+	codes := make(map[string]bool, len(e.Nodes))
+	for _, node := range e.Nodes {
+		switch {
+		case node == nil || node.Code == "":
+			return fmt.Errorf("country code is required")
+		case codes[node.Code]:
+			return fmt.Errorf("duplicate country %s", node.Code)
+		case node.Credibility < 0 || node.Credibility > 1 || node.SuccessRate < 0 || node.SuccessRate > 1:
+			return fmt.Errorf("country %s credibility and success_rate must be between 0 and 1", node.Code)
+		case node.FXRate < 0:
+			return fmt.Errorf("country %s fx_rate must not be negative", node.Code)
+		}
+		codes[node.Code] = true
+	}
+
+	for _, edge := range e.Edges {
+		if edge == nil {
+			return fmt.Errorf("country edge must not be null")
+		}
+		key := edge.SourceCode + "-" + edge.TargetCode
+		switch {
+		case !codes[edge.SourceCode] || !codes[edge.TargetCode]:
+			return fmt.Errorf("country edge %s references an unknown country", key)
+		case edge.SourceCode == edge.TargetCode:
+			return fmt.Errorf("country edge %s is a self-loop", key)
+		case edge.BaseCost < 0 || edge.BaseCost > 1:
+			return fmt.Errorf("country edge %s base_cost must be between 0 and 1", key)
+		case edge.LatencyMs < 0:
+			return fmt.Errorf("country edge %s latency_ms must not be negative", key)
+		}
+	}
+	return nil
+}
+
+// Graph validates e and builds a country graph from it, for use with Replace
+func (e *CountryExport) Graph() (*CountryGraph, error) {
+	if err := e.Validate(); err != nil {
+		return nil, err
+	}
+	graph := NewCountryGraph()
+	for _, node := range e.Nodes {
+		cp := *node
+		graph.AddNode(&cp)
+	}
+	for _, edge := range e.Edges {
+		cp := *edge
+		graph.AddEdge(&cp)
+	}
+	return graph, nil
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+// TestMeshExportImportRoundTrip verifies an exported mesh restores to identical routes
+func TestMeshExportImportRoundTrip(t *testing.T) {
+	original := buildTestGraph(20)
+	data, err := json.Marshal(original.Export())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	var export MeshExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	restored := NewGraph()
+	restored.AddNode(&Node{ID: "stale", Type: "Hub", IsActive: true})
+	if err := restored.Import(&export); err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+	if restored.GetNode("stale") != nil {
+		t.Error("expected import to replace existing nodes")
+	}
+
+	again, _ := json.Marshal(restored.Export())
+	if string(again) != string(data) {
+		t.Error("expected the restored graph to export identically")
+	}
+	if _, err := NewRouter(restored, 3).FindKShortestPaths(context.Background(), "node_0", "node_19", 0); err != nil {
+		t.Fatalf("routing on restored graph: %v", err)
+	}
+
+	bad := export
+	bad.Edges = append(bad.Edges, &Edge{SourceID: "node_0", TargetID: "missing", IsActive: true})
+	if err := restored.Import(&bad); err == nil {
+		t.Error("expected edge to unknown node to be rejected")
+	}
+}
+
+// TestCountryExportRoundTrip verifies corridors are exported once and restored in both directions
+func TestCountryExportRoundTrip(t *testing.T) {
+	graph := BuildCountryGraphWithDefaults()
+	graph.AddEdge(&CountryEdge{SourceCode: "USA", TargetCode: "GBR", BaseCost: 0.02, LatencyMs: 250, IsActive: true})
+	export := graph.Export()
+
+	seen := make(map[string]bool)
+	for _, edge := range export.Edges {
+		if seen[edge.TargetCode+"-"+edge.SourceCode] {
+			t.Errorf("corridor %s-%s exported in both directions", edge.SourceCode, edge.TargetCode)
+		}
+		seen[edge.SourceCode+"-"+edge.TargetCode] = true
+	}
+
+	restored, err := export.Graph()
+	if err != nil {
+		t.Fatalf("Graph: %v", err)
+	}
+	for _, pair := range [][2]string{{"USA", "GBR"}, {"GBR", "USA"}} {
+		want, _ := graph.EdgeWeightBetween(pair[0], pair[1])
+		if got, ok := restored.EdgeWeightBetween(pair[0], pair[1]); !ok || got != want {
+			t.Errorf("%s->%s: got %v (%v), want %v", pair[0], pair[1], got, ok, want)
+		}
+	}
+	if edge := restored.edges["GBR"]["USA"]; edge.LatencyMs != 250 {
+		t.Errorf("expected reverse corridor latency 250, got %d", edge.LatencyMs)
+	}
+
+	export.Nodes = append(export.Nodes, &CountryNode{Code: "USA"})
+	if _, err := export.Graph(); err == nil {
+		t.Error("expected duplicate country to be rejected")
+	}
+}
@@ -0,0 +1,198 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// ErrInsufficientLiquidity is returned when an edge on a path cannot carry a settlement
+var ErrInsufficientLiquidity = errors.New("insufficient liquidity")
+
+// LiquidityChange is a change of an edge's available liquidity
+type LiquidityChange struct {
+	SourceID string
+	TargetID string
+	Old      int64
+	New      int64
+}
+
+// SetLiquidityCallback sets a function called after reservations, releases
+// and commits change an edge's available liquidity. It runs without the
+// graph lock held.
+func (g *Graph) SetLiquidityCallback(cb func(LiquidityChange)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.onLiquidity = cb
+}
+
+// EdgeLiquidity is a point-in-time view of an edge that tracks liquidity
+type EdgeLiquidity struct {
+	SourceID  string `json:"source_id"`
+	TargetID  string `json:"target_id"`
+	Capacity  int64  `json:"capacity"` // Provisioned LiquidityVolume
+	Reserved  int64  `json:"reserved"`
+	Settled   int64  `json:"settled"`
+	Withheld  int64  `json:"withheld,omitempty"` // By chaos degradation (see DegradeEdge)
+	Available int64  `json:"available"`
+	IsActive  bool   `json:"is_active"`
+}
+
+// Liquidity returns the state of every edge that tracks liquidity, ordered
+// by source then target
+func (g *Graph) Liquidity() []EdgeLiquidity {
+	g.mu.RLock()
+	edges := make([]EdgeLiquidity, 0)
+	for _, targets := range g.edges {
+		for _, edge := range targets {
+			if edge.LiquidityVolume == 0 {
+				continue
+			}
+			edges = append(edges, EdgeLiquidity{
+				SourceID:  edge.SourceID,
+				TargetID:  edge.TargetID,
+				Capacity:  edge.LiquidityVolume,
+				Reserved:  edge.Reserved,
+				Settled:   edge.Settled,
+				Withheld:  edge.Withheld(),
+				Available: edge.Available(),
+				IsActive:  edge.IsActive,
+			})
+		}
+	}
+	g.mu.RUnlock()
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].SourceID != edges[j].SourceID {
+			return edges[i].SourceID < edges[j].SourceID
+		}
+		return edges[i].TargetID < edges[j].TargetID
+	})
+	return edges
+}
+
+// Reservation holds liquidity on every tracked edge of a path for an
+// in-flight settlement. Exactly one of Commit or Release takes effect.
+type Reservation struct {
+	graph  *Graph
+	path   []string
+	amount int64
+	once   sync.Once
+}
+
+// ReservePath reserves amount on each edge along path. Edges that do not
+// track liquidity are not constrained. Nothing is reserved unless every
+// tracked edge has enough available liquidity.
+func (g *Graph) ReservePath(path []string, amount int64) (*Reservation, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	g.mu.Lock()
+	for i := 0; i+1 < len(path); i++ {
+		edge := g.edges[path[i]][path[i+1]]
+		if edge == nil {
+			g.mu.Unlock()
+			return nil, fmt.Errorf("no edge from %s to %s", path[i], path[i+1])
+		}
+		if !edge.HasCapacity(amount) {
+			g.mu.Unlock()
+			return nil, fmt.Errorf("%w on %s -> %s: %d available, %d needed",
+				ErrInsufficientLiquidity, edge.SourceID, edge.TargetID, edge.Available(), amount)
+		}
+	}
+	changes := g.adjustPathLocked(path, func(edge, _ *Edge) { edge.Reserved += amount })
+	cb := g.onLiquidity
+	g.mu.Unlock()
+
+	notifyLiquidity(cb, changes)
+	return &Reservation{graph: g, path: append([]string(nil), path...), amount: amount}, nil
+}
+
+// PathHasCapacity reports whether every edge on path exists and can carry
+// amount. A later ReservePath can still fail if other settlements get there
+// first.
+func (g *Graph) PathHasCapacity(path []string, amount int64) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for i := 0; i+1 < len(path); i++ {
+		edge := g.edges[path[i]][path[i+1]]
+		if edge == nil || !edge.HasCapacity(amount) {
+			return false
+		}
+	}
+	return true
+}
+
+// Commit moves the reserved amount across the path: it is drawn from each
+// edge and credited to the edge running the other way
+func (r *Reservation) Commit() {
+	r.once.Do(func() {
+		r.graph.settle(r.path, func(edge, reverse *Edge) {
+			edge.Reserved = max(edge.Reserved-r.amount, 0)
+			edge.Settled += r.amount
+			if reverse != nil && reverse.LiquidityVolume > 0 {
+				reverse.Settled -= r.amount
+			}
+		})
+	})
+}
+
+// Release returns the reserved amount to each edge (the settlement failed)
+func (r *Reservation) Release() {
+	r.once.Do(func() {
+		r.graph.settle(r.path, func(edge, _ *Edge) {
+			edge.Reserved = max(edge.Reserved-r.amount, 0)
+		})
+	})
+}
+
+// settle applies a reservation outcome and notifies the liquidity callback
+func (g *Graph) settle(path []string, apply func(edge, reverse *Edge)) {
+	g.mu.Lock()
+	changes := g.adjustPathLocked(path, apply)
+	cb := g.onLiquidity
+	g.mu.Unlock()
+
+	notifyLiquidity(cb, changes)
+}
+
+// adjustPathLocked applies fn to each tracked edge on path (and its reverse,
+// which may be nil) and returns the resulting changes of available liquidity.
+// Edges removed since the reservation are skipped. Caller must hold g.mu.
+func (g *Graph) adjustPathLocked(path []string, fn func(edge, reverse *Edge)) []LiquidityChange {
+	var changes []LiquidityChange
+	record := func(edge *Edge, old int64) {
+		if edge.Available() != old {
+			changes = append(changes, LiquidityChange{SourceID: edge.SourceID, TargetID: edge.TargetID, Old: old, New: edge.Available()})
+		}
+	}
+	for i := 0; i+1 < len(path); i++ {
+		edge := g.edges[path[i]][path[i+1]]
+		if edge == nil || edge.LiquidityVolume == 0 {
+			continue
+		}
+		reverse := g.edges[path[i+1]][path[i]]
+		old := edge.Available()
+		var oldReverse int64
+		if reverse != nil {
+			oldReverse = reverse.Available()
+		}
+		fn(edge, reverse)
+		record(edge, old)
+		if reverse != nil && reverse.LiquidityVolume > 0 {
+			record(reverse, oldReverse)
+		}
+	}
+	return changes
+}
+
+func notifyLiquidity(cb func(LiquidityChange), changes []LiquidityChange) {
+	if cb == nil {
+		return
+	}
+	for _, change := range changes {
+		cb(change)
+	}
+}
@@ -0,0 +1,85 @@
+package router
+
+import (
+	"errors"
+	"testing"
+)
+
+func liquidityGraph() *Graph {
+	g := NewGraph()
+	for _, id := range []string{"a", "b", "c"} {
+		g.AddNode(&Node{ID: id, IsActive: true})
+	}
+	g.AddBidirectionalEdge(&Edge{SourceID: "a", TargetID: "b", LiquidityVolume: 1000, IsActive: true})
+	g.AddBidirectionalEdge(&Edge{SourceID: "b", TargetID: "c", IsActive: true}) // Untracked
+	return g
+}
+
+func available(t *testing.T, g *Graph, from, to string) int64 {
+	t.Helper()
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.edges[from][to].Available()
+}
+
+// TestReservePathLifecycle verifies reserve, release and commit adjust edge liquidity
+func TestReservePathLifecycle(t *testing.T) {
+	g := liquidityGraph()
+	var changes []LiquidityChange
+	g.SetLiquidityCallback(func(c LiquidityChange) { changes = append(changes, c) })
+	path := []string{"a", "b", "c"}
+
+	res, err := g.ReservePath(path, 600)
+	if err != nil {
+		t.Fatalf("ReservePath failed: %v", err)
+	}
+	if got := available(t, g, "a", "b"); got != 400 {
+		t.Errorf("available after reserve = %d, want 400", got)
+	}
+	if len(changes) != 1 || changes[0] != (LiquidityChange{SourceID: "a", TargetID: "b", Old: 1000, New: 400}) {
+		t.Errorf("changes after reserve = %+v", changes)
+	}
+
+	if _, err := g.ReservePath(path, 500); !errors.Is(err, ErrInsufficientLiquidity) {
+		t.Fatalf("second reservation error = %v, want ErrInsufficientLiquidity", err)
+	}
+	if g.PathHasCapacity(path, 500) {
+		t.Error("PathHasCapacity = true with only 400 available")
+	}
+
+	res.Release()
+	res.Commit() // No effect after Release
+	if got := available(t, g, "a", "b"); got != 1000 {
+		t.Errorf("available after release = %d, want 1000", got)
+	}
+	if got := available(t, g, "b", "a"); got != 1000 {
+		t.Errorf("reverse available after release = %d, want 1000", got)
+	}
+
+	res, err = g.ReservePath(path, 300)
+	if err != nil {
+		t.Fatalf("ReservePath failed: %v", err)
+	}
+	changes = nil
+	res.Commit()
+	if got := available(t, g, "a", "b"); got != 700 {
+		t.Errorf("available after commit = %d, want 700", got)
+	}
+	if got := available(t, g, "b", "a"); got != 1300 {
+		t.Errorf("reverse available after commit = %d, want 1300", got)
+	}
+	if len(changes) != 1 || changes[0].New != 1300 {
+		t.Errorf("changes after commit = %+v, want only the reverse edge", changes)
+	}
+}
+
+// TestReservePathMissingEdge verifies nothing is reserved when the path is broken
+func TestReservePathMissingEdge(t *testing.T) {
+	g := liquidityGraph()
+	if _, err := g.ReservePath([]string{"a", "b", "x"}, 100); err == nil || errors.Is(err, ErrInsufficientLiquidity) {
+		t.Fatalf("error = %v, want missing edge", err)
+	}
+	if got := available(t, g, "a", "b"); got != 1000 {
+		t.Errorf("available = %d, want 1000", got)
+	}
+}
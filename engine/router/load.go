@@ -0,0 +1,137 @@
+package router
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadTracker counts in-flight settlements per node, and the settlements
+// started per minute. In-flight counters are atomic so hot-path increments
+// don't contend on the map lock.
+type LoadTracker struct {
+	mu       sync.RWMutex
+	counters map[string]*nodeLoad
+
+	now func() time.Time // Clock for the per-minute counts, time.Now if nil
+}
+
+// nodeLoad is the load of one node
+type nodeLoad struct {
+	inFlight atomic.Int64
+
+	mu      sync.Mutex
+	minute  int64 // Unix minute of started
+	started int64
+}
+
+// NewLoadTracker creates a new load tracker
+func NewLoadTracker() *LoadTracker {
+	return &LoadTracker{
+		counters: make(map[string]*nodeLoad),
+	}
+}
+
+// counter returns the counter for a node, creating it if needed
+func (lt *LoadTracker) counter(nodeID string) *nodeLoad {
+	lt.mu.RLock()
+	c, ok := lt.counters[nodeID]
+	lt.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+	if c, ok = lt.counters[nodeID]; !ok {
+		c = &nodeLoad{}
+		lt.counters[nodeID] = c
+	}
+	return c
+}
+
+// Acquire marks a settlement as in-flight through a node
+func (lt *LoadTracker) Acquire(nodeID string) {
+	c := lt.counter(nodeID)
+	c.inFlight.Add(1)
+
+	minute := lt.clock().Unix() / 60
+	c.mu.Lock()
+	if c.minute != minute {
+		c.minute, c.started = minute, 0
+	}
+	c.started++
+	c.mu.Unlock()
+}
+
+// Started returns the number of settlements started through a node in the
+// calendar minute of now
+func (lt *LoadTracker) Started(nodeID string, now time.Time) int64 {
+	lt.mu.RLock()
+	c, ok := lt.counters[nodeID]
+	lt.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.minute != now.Unix()/60 {
+		return 0
+	}
+	return c.started
+}
+
+// clock returns the current time, from lt.now in tests
+func (lt *LoadTracker) clock() time.Time {
+	if lt.now != nil {
+		return lt.now()
+	}
+	return time.Now()
+}
+
+// Release marks an in-flight settlement through a node as finished
+func (lt *LoadTracker) Release(nodeID string) {
+	c := lt.counter(nodeID)
+	if c.inFlight.Add(-1) < 0 {
+		c.inFlight.Store(0)
+	}
+}
+
+// AcquirePath marks a settlement in-flight on every node of a path.
+// Returns a function that releases all of them.
+func (lt *LoadTracker) AcquirePath(nodes []string) func() {
+	for _, nodeID := range nodes {
+		lt.Acquire(nodeID)
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			for _, nodeID := range nodes {
+				lt.Release(nodeID)
+			}
+		})
+	}
+}
+
+// Load returns the number of in-flight settlements through a node
+func (lt *LoadTracker) Load(nodeID string) int64 {
+	lt.mu.RLock()
+	c, ok := lt.counters[nodeID]
+	lt.mu.RUnlock()
+	if !ok {
+		return 0
+	}
+	return c.inFlight.Load()
+}
+
+// Snapshot returns the current load of every tracked node
+func (lt *LoadTracker) Snapshot() map[string]int64 {
+	lt.mu.RLock()
+	defer lt.mu.RUnlock()
+
+	result := make(map[string]int64, len(lt.counters))
+	for nodeID, c := range lt.counters {
+		result[nodeID] = c.inFlight.Load()
+	}
+	return result
+}
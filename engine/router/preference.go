@@ -0,0 +1,63 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Preference weighs what a route is optimized for. Each edge costs
+//
+//	Cost × fee + Latency × latency (seconds) + Risk × risk
+//
+// where risk is the entropy of the source node for mesh routes and the mean
+// of (1 - credibility) and (1 - success rate) of the target country for
+// country routes. The zero Preference keeps each router's default composite weight.
+type Preference struct {
+	Cost    float64 `json:"cost"`
+	Latency float64 `json:"latency"`
+	Risk    float64 `json:"risk"`
+}
+
+// Named preferences accepted by ParsePreference
+var (
+	PreferBalanced     = Preference{}
+	PreferCheapest     = Preference{Cost: 1}
+	PreferFastest      = Preference{Latency: 1}
+	PreferMostReliable = Preference{Risk: 1}
+)
+
+// tieBreakPerHop makes equally weighted routes prefer fewer hops
+const tieBreakPerHop = 1e-9
+
+// ParsePreference resolves "balanced" (or ""), "cheapest", "fastest" or "most_reliable"
+func ParsePreference(name string) (Preference, error) {
+	switch strings.ToLower(name) {
+	case "", "balanced":
+		return PreferBalanced, nil
+	case "cheapest":
+		return PreferCheapest, nil
+	case "fastest":
+		return PreferFastest, nil
+	case "most_reliable":
+		return PreferMostReliable, nil
+	}
+	return Preference{}, fmt.Errorf("unknown preference %q (want balanced, cheapest, fastest or most_reliable)", name)
+}
+
+// IsZero reports whether p is the default (balanced) preference
+func (p Preference) IsZero() bool {
+	return p == Preference{}
+}
+
+// Validate checks that custom coefficients are non-negative
+func (p Preference) Validate() error {
+	if p.Cost < 0 || p.Latency < 0 || p.Risk < 0 {
+		return fmt.Errorf("preference weights must not be negative")
+	}
+	return nil
+}
+
+// weigh combines an edge's fee, latency and risk
+func (p Preference) weigh(fee float64, latencyMs int64, risk float64) float64 {
+	return p.Cost*fee + p.Latency*float64(latencyMs)/1000 + p.Risk*risk + tieBreakPerHop
+}
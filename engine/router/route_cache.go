@@ -0,0 +1,121 @@
+package router
+
+import (
+	"container/list"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// routeCache is an LRU cache of country routing results. Entries expire after
+// ttl and are dropped once the graph version they were computed on changes.
+type routeCache struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	entries map[string]*list.Element
+	order   *list.List // Front is most recently used
+	now     func() time.Time
+}
+
+type routeCacheEntry struct {
+	key     string
+	version uint64
+	expires time.Time
+	paths   []*CountryPath
+}
+
+func newRouteCache(size int, ttl time.Duration) *routeCache {
+	return &routeCache{
+		size:    size,
+		ttl:     ttl,
+		entries: make(map[string]*list.Element, size),
+		order:   list.New(),
+		now:     time.Now,
+	}
+}
+
+// get returns the cached paths for key if they were computed on version and have not expired
+func (c *routeCache) get(key string, version uint64) ([]*CountryPath, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*routeCacheEntry)
+	if entry.version != version || c.now().After(entry.expires) {
+		c.order.Remove(el)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return entry.paths, true
+}
+
+// put stores paths for key, evicting the least recently used entry when full
+func (c *routeCache) put(key string, version uint64, paths []*CountryPath) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &routeCacheEntry{key: key, version: version, expires: c.now().Add(c.ttl), paths: paths}
+	if el, ok := c.entries[key]; ok {
+		el.Value = entry
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*routeCacheEntry).key)
+	}
+}
+
+// len returns the number of cached entries
+func (c *routeCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// cacheKey identifies a request by everything that affects its result
+func (r *CountryRouter) cacheKey(source, target string, blockedCodes, closed []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s|%s|%d|%v|%d|", source, target, r.k, r.preference, r.constraints.MaxHops)
+	b.WriteString(sortedSet(blockedCodes))
+	b.WriteByte('|')
+	b.WriteString(sortedSet(r.constraints.Avoid))
+	b.WriteByte('|')
+	b.WriteString(strings.Join(r.constraints.Via, ",")) // Waypoint order matters
+	b.WriteByte('|')
+	b.WriteString(strings.Join(closed, ",")) // Sorted by closedCountries
+	return b.String()
+}
+
+// sortedSet joins codes in a canonical order without duplicates
+func sortedSet(codes []string) string {
+	set := append([]string(nil), codes...)
+	sort.Strings(set)
+	out := set[:0]
+	for i, code := range set {
+		if i == 0 || code != set[i-1] {
+			out = append(out, code)
+		}
+	}
+	return strings.Join(out, ",")
+}
+
+// clonePaths copies paths so callers cannot modify cached results
+func clonePaths(paths []*CountryPath) []*CountryPath {
+	out := make([]*CountryPath, len(paths))
+	for i, p := range paths {
+		cp := *p
+		cp.Nodes = append([]string(nil), p.Nodes...)
+		out[i] = &cp
+	}
+	return out
+}
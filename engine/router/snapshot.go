@@ -0,0 +1,500 @@
+package router
+
+import (
+	"context"
+	"math"
+	"sync"
+)
+
+// meshSnapshot is an index-based copy of the routable part of the mesh for a
+// single request. Edge weights are computed once while the graph read lock is
+// held; the path search then runs on the snapshot without the lock.
+type meshSnapshot struct {
+	ids   []string
+	index map[string]int
+	out   [][]arc // Outgoing arcs by source index
+	in    [][]arc // Incoming arcs by target index, for the backward search
+}
+
+// arc is one usable edge; node is the far end (target in out, source in in)
+type arc struct {
+	node   int
+	weight float64
+	edge   *Edge
+}
+
+// snapshot copies the edges usable for amount, weighted for pref.
+// Caller must hold at least RLock.
+func (g *Graph) snapshot(amount int64, pref Preference) *meshSnapshot {
+	s := &meshSnapshot{
+		ids:   make([]string, 0, len(g.nodes)),
+		index: make(map[string]int, len(g.nodes)),
+	}
+	for id := range g.nodes {
+		s.add(id)
+	}
+	closed := g.unavailable(g.clock()) // Outside operating hours or at capacity
+	for sourceID, targets := range g.edges {
+		for targetID, edge := range targets {
+			if !edge.IsActive || !edge.HasCapacity(amount) || closed[sourceID] || closed[targetID] {
+				continue
+			}
+			if targetNode, ok := g.nodes[targetID]; ok && !targetNode.IsActive {
+				continue
+			}
+			from, to := s.add(sourceID), s.add(targetID)
+			weight := g.weightFor(edge, pref)
+			s.out[from] = append(s.out[from], arc{node: to, weight: weight, edge: edge})
+			s.in[to] = append(s.in[to], arc{node: from, weight: weight, edge: edge})
+		}
+	}
+	return s
+}
+
+// add returns the index of id, assigning one if needed
+func (s *meshSnapshot) add(id string) int {
+	if i, ok := s.index[id]; ok {
+		return i
+	}
+	i := len(s.ids)
+	s.ids = append(s.ids, id)
+	s.index[id] = i
+	s.out = append(s.out, nil)
+	s.in = append(s.in, nil)
+	return i
+}
+
+// hop is one edge of a candidate path with its routing weight
+type hop struct {
+	edge   *Edge
+	weight float64
+}
+
+// candidate is a path found on a snapshot
+type candidate struct {
+	nodes  []int
+	hops   []hop
+	weight float64
+}
+
+// path converts a candidate to a Path
+func (s *meshSnapshot) path(c *candidate) *Path {
+	p := &Path{
+		Nodes:       make([]string, len(c.nodes)),
+		Edges:       make([]*Edge, len(c.hops)),
+		TotalWeight: c.weight,
+	}
+	for i, n := range c.nodes {
+		p.Nodes[i] = s.ids[n]
+	}
+	for i, h := range c.hops {
+		p.Edges[i] = h.edge
+		p.TotalFee += h.edge.BaseFee
+		p.TotalLatency += h.edge.EffectiveLatency()
+	}
+	return p
+}
+
+// kShortest runs Yen's algorithm for up to k loopless paths from src to dst
+func (s *meshSnapshot) kShortest(ctx context.Context, b *searchBuffers, src, dst, k int, bidirectional bool) ([]*candidate, error) {
+	search := s.dijkstra
+	if bidirectional {
+		search = s.bidirectionalDijkstra
+	}
+
+	b.exclude()
+	first := search(b, src, dst, nil)
+	if first == nil {
+		return nil, nil
+	}
+
+	// A holds the K shortest paths, B the candidates
+	A := []*candidate{first}
+	var B candidateHeap
+
+	for len(A) < k {
+		if ctx.Err() != nil {
+			return A, ctx.Err()
+		}
+
+		prev := A[len(A)-1]
+		rootWeight := 0.0
+		for i := 0; i < len(prev.nodes)-1; i++ {
+			if i > 0 {
+				rootWeight += prev.hops[i-1].weight
+			}
+			spur := prev.nodes[i]
+			root := prev.nodes[:i+1]
+
+			// Exclude root path nodes (except the spur node)
+			b.exclude(prev.nodes[:i]...)
+
+			// Exclude the next edge of every found path sharing this root
+			var skip []int
+			for _, p := range A {
+				if len(p.nodes) > i+1 && sharesPrefix(p.nodes, root) {
+					skip = append(skip, p.nodes[i+1])
+				}
+			}
+
+			spurPath := search(b, spur, dst, skip)
+			if spurPath == nil {
+				continue
+			}
+
+			total := &candidate{
+				nodes:  make([]int, 0, len(root)+len(spurPath.nodes)-1),
+				hops:   make([]hop, 0, i+len(spurPath.hops)),
+				weight: rootWeight + spurPath.weight,
+			}
+			total.nodes = append(append(total.nodes, root...), spurPath.nodes[1:]...)
+			total.hops = append(append(total.hops, prev.hops[:i]...), spurPath.hops...)
+			if !B.contains(total) && !containsCandidate(A, total) {
+				B.push(total)
+			}
+		}
+
+		if len(B) == 0 {
+			break
+		}
+		A = append(A, B.pop())
+	}
+	return A, nil
+}
+
+// dijkstra finds the lowest-weight path from src to dst avoiding excluded
+// nodes and the src->skip edges
+func (s *meshSnapshot) dijkstra(b *searchBuffers, src, dst int, skip []int) *candidate {
+	if b.excluded(src) || b.excluded(dst) {
+		return nil
+	}
+	b.reset()
+	b.dist[src] = 0
+	b.fwd.push(src, 0)
+
+	for b.fwd.len() > 0 {
+		u, _ := b.fwd.pop()
+		if b.done[u] {
+			continue
+		}
+		b.done[u] = true
+		if u == dst {
+			break
+		}
+		for i := range s.out[u] {
+			a := &s.out[u][i]
+			if b.excluded(a.node) || (u == src && containsInt(skip, a.node)) {
+				continue
+			}
+			if d := b.dist[u] + a.weight; d < b.dist[a.node] {
+				b.dist[a.node] = d
+				b.prev[a.node] = u
+				b.prevArc[a.node] = a
+				b.fwd.push(a.node, d)
+			}
+		}
+	}
+
+	if math.IsInf(b.dist[dst], 1) {
+		return nil
+	}
+	return b.trace(src, dst, dst, b.dist[dst])
+}
+
+// bidirectionalDijkstra searches from both ends at once and stops when the
+// two frontiers can no longer improve on the best meeting point
+func (s *meshSnapshot) bidirectionalDijkstra(b *searchBuffers, src, dst int, skip []int) *candidate {
+	if b.excluded(src) || b.excluded(dst) {
+		return nil
+	}
+	b.reset()
+	b.dist[src], b.distB[dst] = 0, 0
+	b.fwd.push(src, 0)
+	b.bwd.push(dst, 0)
+
+	best, meet := math.Inf(1), -1
+	if src == dst {
+		best, meet = 0, src
+	}
+
+	for b.fwd.len() > 0 && b.bwd.len() > 0 {
+		if b.fwd.min()+b.bwd.min() >= best {
+			break
+		}
+
+		if b.fwd.min() <= b.bwd.min() {
+			u, _ := b.fwd.pop()
+			if b.done[u] {
+				continue
+			}
+			b.done[u] = true
+			for i := range s.out[u] {
+				a := &s.out[u][i]
+				if b.excluded(a.node) || (u == src && containsInt(skip, a.node)) {
+					continue
+				}
+				if d := b.dist[u] + a.weight; d < b.dist[a.node] {
+					b.dist[a.node] = d
+					b.prev[a.node] = u
+					b.prevArc[a.node] = a
+					b.fwd.push(a.node, d)
+					if total := d + b.distB[a.node]; total < best {
+						best, meet = total, a.node
+					}
+				}
+			}
+		} else {
+			u, _ := b.bwd.pop()
+			if b.doneB[u] {
+				continue
+			}
+			b.doneB[u] = true
+			for i := range s.in[u] {
+				a := &s.in[u][i]
+				if b.excluded(a.node) || (a.node == src && containsInt(skip, u)) {
+					continue
+				}
+				if d := b.distB[u] + a.weight; d < b.distB[a.node] {
+					b.distB[a.node] = d
+					b.next[a.node] = u
+					b.nextArc[a.node] = a
+					b.bwd.push(a.node, d)
+					if total := d + b.dist[a.node]; total < best {
+						best, meet = total, a.node
+					}
+				}
+			}
+		}
+	}
+
+	if meet < 0 {
+		return nil
+	}
+	return b.trace(src, dst, meet, best)
+}
+
+// searchBuffers holds the per-node arrays of a search. They are sized to the
+// snapshot, reused across the spur searches of a request and pooled across
+// requests.
+type searchBuffers struct {
+	dist, distB []float64
+	prev, next  []int
+	prevArc     []*arc // Arc into the node on the forward side
+	nextArc     []*arc // Arc out of the node on the backward side
+	done, doneB []bool
+	fwd, bwd    nodeHeap
+
+	excludedGen []uint32 // Node is excluded when its entry equals gen
+	gen         uint32
+}
+
+var bufferPool = sync.Pool{New: func() any { return &searchBuffers{} }}
+
+// getSearchBuffers returns pooled buffers sized for n nodes
+func getSearchBuffers(n int) *searchBuffers {
+	b := bufferPool.Get().(*searchBuffers)
+	if cap(b.dist) < n {
+		*b = searchBuffers{
+			dist:        make([]float64, n),
+			distB:       make([]float64, n),
+			prev:        make([]int, n),
+			next:        make([]int, n),
+			prevArc:     make([]*arc, n),
+			nextArc:     make([]*arc, n),
+			done:        make([]bool, n),
+			doneB:       make([]bool, n),
+			excludedGen: make([]uint32, n),
+		}
+	}
+	b.dist, b.distB = b.dist[:n], b.distB[:n]
+	b.prev, b.next = b.prev[:n], b.next[:n]
+	b.prevArc, b.nextArc = b.prevArc[:n], b.nextArc[:n]
+	b.done, b.doneB = b.done[:n], b.doneB[:n]
+	b.excludedGen = b.excludedGen[:n]
+	return b
+}
+
+// putSearchBuffers returns buffers to the pool
+func putSearchBuffers(b *searchBuffers) {
+	// Drop edge pointers so pooled buffers don't keep old graphs alive
+	clear(b.prevArc[:cap(b.prevArc)])
+	clear(b.nextArc[:cap(b.nextArc)])
+	bufferPool.Put(b)
+}
+
+// reset prepares the buffers for a new search
+func (b *searchBuffers) reset() {
+	inf := math.Inf(1)
+	for i := range b.dist {
+		b.dist[i], b.distB[i] = inf, inf
+		b.prev[i], b.next[i] = -1, -1
+		b.done[i], b.doneB[i] = false, false
+	}
+	b.fwd = b.fwd[:0]
+	b.bwd = b.bwd[:0]
+}
+
+// exclude replaces the set of excluded nodes
+func (b *searchBuffers) exclude(nodes ...int) {
+	b.gen++
+	if b.gen == 0 {
+		clear(b.excludedGen)
+		b.gen = 1
+	}
+	for _, n := range nodes {
+		b.excludedGen[n] = b.gen
+	}
+}
+
+func (b *searchBuffers) excluded(n int) bool {
+	return b.excludedGen[n] == b.gen
+}
+
+// trace rebuilds the path through meet from the forward and backward predecessors
+func (b *searchBuffers) trace(src, dst, meet int, weight float64) *candidate {
+	c := &candidate{weight: weight}
+	for n := meet; n != src; n = b.prev[n] {
+		c.nodes = append(c.nodes, n)
+		c.hops = append(c.hops, hop{edge: b.prevArc[n].edge, weight: b.prevArc[n].weight})
+	}
+	c.nodes = append(c.nodes, src)
+	for i, j := 0, len(c.nodes)-1; i < j; i, j = i+1, j-1 {
+		c.nodes[i], c.nodes[j] = c.nodes[j], c.nodes[i]
+	}
+	for i, j := 0, len(c.hops)-1; i < j; i, j = i+1, j-1 {
+		c.hops[i], c.hops[j] = c.hops[j], c.hops[i]
+	}
+	for n := meet; n != dst; n = b.next[n] {
+		c.nodes = append(c.nodes, b.next[n])
+		c.hops = append(c.hops, hop{edge: b.nextArc[n].edge, weight: b.nextArc[n].weight})
+	}
+	return c
+}
+
+// nodeHeap is a binary min-heap of (node, dist) pairs. It is hand-rolled
+// rather than built on container/heap to avoid boxing every push.
+type nodeHeap []heapEntry
+
+type heapEntry struct {
+	node int
+	dist float64
+}
+
+func (h nodeHeap) len() int     { return len(h) }
+func (h nodeHeap) min() float64 { return h[0].dist }
+
+func (h *nodeHeap) push(node int, dist float64) {
+	*h = append(*h, heapEntry{node: node, dist: dist})
+	q := *h
+	for i := len(q) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if q[parent].dist <= q[i].dist {
+			break
+		}
+		q[parent], q[i] = q[i], q[parent]
+		i = parent
+	}
+}
+
+func (h *nodeHeap) pop() (int, float64) {
+	q := *h
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q = q[:last]
+	for i := 0; ; {
+		smallest, l, r := i, 2*i+1, 2*i+2
+		if l < len(q) && q[l].dist < q[smallest].dist {
+			smallest = l
+		}
+		if r < len(q) && q[r].dist < q[smallest].dist {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		q[i], q[smallest] = q[smallest], q[i]
+		i = smallest
+	}
+	*h = q
+	return top.node, top.dist
+}
+
+// candidateHeap is a min-heap of candidate paths ordered by weight
+type candidateHeap []*candidate
+
+func (h *candidateHeap) push(c *candidate) {
+	*h = append(*h, c)
+	q := *h
+	for i := len(q) - 1; i > 0; {
+		parent := (i - 1) / 2
+		if q[parent].weight <= q[i].weight {
+			break
+		}
+		q[parent], q[i] = q[i], q[parent]
+		i = parent
+	}
+}
+
+func (h *candidateHeap) pop() *candidate {
+	q := *h
+	top := q[0]
+	last := len(q) - 1
+	q[0] = q[last]
+	q[last] = nil
+	q = q[:last]
+	for i := 0; ; {
+		smallest, l, r := i, 2*i+1, 2*i+2
+		if l < len(q) && q[l].weight < q[smallest].weight {
+			smallest = l
+		}
+		if r < len(q) && q[r].weight < q[smallest].weight {
+			smallest = r
+		}
+		if smallest == i {
+			break
+		}
+		q[i], q[smallest] = q[smallest], q[i]
+		i = smallest
+	}
+	*h = q
+	return top
+}
+
+func (h candidateHeap) contains(c *candidate) bool {
+	return containsCandidate(h, c)
+}
+
+func containsCandidate(cs []*candidate, c *candidate) bool {
+	for _, other := range cs {
+		if intsEqual(other.nodes, c.nodes) {
+			return true
+		}
+	}
+	return false
+}
+
+func sharesPrefix(path, prefix []int) bool {
+	return len(prefix) <= len(path) && intsEqual(path[:len(prefix)], prefix)
+}
+
+func intsEqual(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Hypothesis describes hypothetical conditions for a what-if route
+// simulation, applied to a copy of the country graph
+type Hypothesis struct {
+	Blocked   []string                   `json:"blocked,omitempty"`   // Countries to block
+	Unblocked []string                   `json:"unblocked,omitempty"` // Blocked countries to allow
+	Countries map[string]CountryOverride `json:"countries,omitempty"` // Score changes by country code
+	Edges     []EdgeOverride             `json:"edges,omitempty"`     // Corridor changes, applied in both directions
+}
+
+// CountryOverride changes a country's scores; nil fields are left as they are
+type CountryOverride struct {
+	Credibility *float64 `json:"credibility,omitempty"`
+	SuccessRate *float64 `json:"success_rate,omitempty"`
+}
+
+// EdgeOverride changes, adds or removes the corridor between two countries;
+// nil fields of an existing corridor are left as they are
+type EdgeOverride struct {
+	Source    string   `json:"source"`
+	Target    string   `json:"target"`
+	BaseCost  *float64 `json:"base_cost,omitempty"`
+	LatencyMs *int64   `json:"latency_ms,omitempty"`
+	Removed   bool     `json:"removed,omitempty"`
+}
+
+// IsZero reports whether the hypothesis changes nothing
+func (h Hypothesis) IsZero() bool {
+	return len(h.Blocked) == 0 && len(h.Unblocked) == 0 && len(h.Countries) == 0 && len(h.Edges) == 0
+}
+
+// Hypothetical returns a copy of the graph with h applied. The graph itself
+// is not changed; the copy shares no mutable state with it.
+func (g *CountryGraph) Hypothetical(h Hypothesis) (*CountryGraph, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	sim := NewCountryGraph()
+	for code, node := range g.nodes {
+		cp := *node
+		sim.nodes[code] = &cp
+	}
+	for source, targets := range g.edges {
+		sim.edges[source] = make(map[string]*CountryEdge, len(targets))
+		for target, edge := range targets {
+			cp := *edge
+			sim.edges[source][target] = &cp
+		}
+	}
+	for code := range g.blocked {
+		sim.blocked[code] = true
+	}
+
+	country := func(code string) (*CountryNode, error) {
+		node, ok := sim.nodes[code]
+		if !ok {
+			return nil, fmt.Errorf("unknown country %s", code)
+		}
+		return node, nil
+	}
+	for _, code := range h.Blocked {
+		if _, err := country(code); err != nil {
+			return nil, err
+		}
+		sim.blocked[code] = true
+	}
+	for _, code := range h.Unblocked {
+		if _, err := country(code); err != nil {
+			return nil, err
+		}
+		delete(sim.blocked, code)
+	}
+
+	// Sorted so the first invalid override reported is deterministic
+	codes := make([]string, 0, len(h.Countries))
+	for code := range h.Countries {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		node, err := country(code)
+		if err != nil {
+			return nil, err
+		}
+		o := h.Countries[code]
+		if o.Credibility != nil {
+			if *o.Credibility < 0 || *o.Credibility > 1 {
+				return nil, fmt.Errorf("%s credibility must be between 0 and 1", code)
+			}
+			node.Credibility = *o.Credibility
+		}
+		if o.SuccessRate != nil {
+			if *o.SuccessRate < 0 || *o.SuccessRate > 1 {
+				return nil, fmt.Errorf("%s success_rate must be between 0 and 1", code)
+			}
+			node.SuccessRate = *o.SuccessRate
+		}
+	}
+
+	for _, o := range h.Edges {
+		if err := sim.applyEdgeOverride(o); err != nil {
+			return nil, err
+		}
+	}
+	return sim, nil
+}
+
+// applyEdgeOverride applies o to both directions of a corridor of a graph
+// not yet shared
+func (g *CountryGraph) applyEdgeOverride(o EdgeOverride) error {
+	for _, code := range []string{o.Source, o.Target} {
+		if _, ok := g.nodes[code]; !ok {
+			return fmt.Errorf("unknown country %s", code)
+		}
+	}
+	if o.Source == o.Target {
+		return fmt.Errorf("corridor %s -> %s must join two countries", o.Source, o.Target)
+	}
+	if o.BaseCost != nil && (*o.BaseCost < 0 || *o.BaseCost > 1) {
+		return fmt.Errorf("corridor %s -> %s base_cost must be between 0 and 1", o.Source, o.Target)
+	}
+	if o.LatencyMs != nil && *o.LatencyMs < 0 {
+		return fmt.Errorf("corridor %s -> %s latency_ms must not be negative", o.Source, o.Target)
+	}
+
+	for _, pair := range [][2]string{{o.Source, o.Target}, {o.Target, o.Source}} {
+		source, target := pair[0], pair[1]
+		if o.Removed {
+			delete(g.edges[source], target)
+			continue
+		}
+		edge, ok := g.edges[source][target]
+		if !ok {
+			if o.BaseCost == nil {
+				return fmt.Errorf("corridor %s -> %s does not exist; base_cost is required to add it", o.Source, o.Target)
+			}
+			edge = &CountryEdge{SourceCode: source, TargetCode: target, IsActive: true}
+			if g.edges[source] == nil {
+				g.edges[source] = make(map[string]*CountryEdge)
+			}
+			g.edges[source][target] = edge
+		}
+		if o.BaseCost != nil {
+			edge.BaseCost = *o.BaseCost
+		}
+		if o.LatencyMs != nil {
+			edge.LatencyMs = *o.LatencyMs
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package router
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+// TestHypotheticalLeavesGraphUntouched verifies what-if conditions apply to a
+// copy only
+func TestHypotheticalLeavesGraphUntouched(t *testing.T) {
+	graph := BuildCountryGraphWithDefaults()
+	sim, err := graph.Hypothetical(Hypothesis{
+		Blocked:   []string{"DEU"},
+		Countries: map[string]CountryOverride{"CHE": {Credibility: ptr(0.7)}},
+		Edges: []EdgeOverride{
+			{Source: "FRA", Target: "POL", BaseCost: ptr(0.01)},
+			{Source: "USA", Target: "GBR", Removed: true},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Hypothetical failed: %v", err)
+	}
+
+	if !sim.IsBlocked("DEU") || graph.IsBlocked("DEU") {
+		t.Error("block should apply to the copy only")
+	}
+	if c, _, _ := sim.CountryScore("CHE"); c != 0.7 {
+		t.Errorf("simulated CHE credibility = %v, want 0.7", c)
+	}
+	if c, _, _ := graph.CountryScore("CHE"); c != 0.99 {
+		t.Errorf("graph CHE credibility changed to %v", c)
+	}
+	if sim.Edge("POL", "FRA") == nil || graph.Edge("FRA", "POL") != nil {
+		t.Error("added corridor should exist both ways in the copy only")
+	}
+	if sim.Edge("GBR", "USA") != nil || graph.Edge("USA", "GBR") == nil {
+		t.Error("removed corridor should be gone from the copy only")
+	}
+
+	ctx := context.Background()
+	before, _ := NewCountryRouter(graph, 3).BestRoute(ctx, "FRA", "POL")
+	after, _ := NewCountryRouter(sim, 3).BestRoute(ctx, "FRA", "POL")
+	if !slices.Contains(before, "DEU") || slices.Contains(after, "DEU") {
+		t.Errorf("expected the route to move off DEU: %v -> %v", before, after)
+	}
+}
+
+func TestHypotheticalRejectsInvalid(t *testing.T) {
+	graph := BuildCountryGraphWithDefaults()
+	for name, h := range map[string]Hypothesis{
+		"unknown blocked":     {Blocked: []string{"XXX"}},
+		"credibility range":   {Countries: map[string]CountryOverride{"CHE": {Credibility: ptr(1.5)}}},
+		"unknown country":     {Countries: map[string]CountryOverride{"XXX": {SuccessRate: ptr(0.5)}}},
+		"new corridor cost":   {Edges: []EdgeOverride{{Source: "FRA", Target: "POL", LatencyMs: ptr(int64(50))}}},
+		"self corridor":       {Edges: []EdgeOverride{{Source: "FRA", Target: "FRA", BaseCost: ptr(0.01)}}},
+		"negative latency":    {Edges: []EdgeOverride{{Source: "DEU", Target: "FRA", LatencyMs: ptr(int64(-1))}}},
+		"unknown corridor to": {Edges: []EdgeOverride{{Source: "DEU", Target: "XXX", Removed: true}}},
+	} {
+		if _, err := graph.Hypothetical(h); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -3,13 +3,13 @@
 package router
 
 import (
-	"container/heap"
 	"context"
 	"fmt"
-	"math"
 	"sync"
+	"time"
 
 	"github.com/plm/predictive-liquidity-mesh/pkg/entropy"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
 )
 
 // Graph represents the liquidity mesh topology
@@ -18,6 +18,14 @@ type Graph struct {
 	nodes    map[string]*Node
 	edges    map[string]map[string]*Edge // source -> target -> edge
 	entropy  map[string]*entropy.NodeEntropy
+
+	// Optional load-avoidance term (penalty per in-flight settlement at the target)
+	load        *LoadTracker
+	loadPenalty float64
+
+	onLiquidity func(LiquidityChange) // Optional, see SetLiquidityCallback
+
+	now func() time.Time // Clock for operating hours, time.Now if nil
 }
 
 // Node represents a mesh node (SME, LiquidityProvider, or Hub)
@@ -27,6 +35,7 @@ type Node struct {
 	Region   string
 	IsActive bool
 	Props    map[string]interface{}
+	Capacity NodeCapacity // Limits and operating hours, unlimited by default
 }
 
 // Edge represents a liquidity edge between nodes
@@ -35,8 +44,28 @@ type Edge struct {
 	TargetID        string
 	BaseFee         float64 // Base fee percentage (e.g., 0.0015 = 0.15%)
 	Latency         int64   // Latency in milliseconds
-	LiquidityVolume int64   // Available liquidity
+	LiquidityVolume int64   // Provisioned liquidity (0 = not tracked, unconstrained)
 	IsActive        bool
+
+	// Settlement activity on tracked edges (see ReservePath), not exported
+	Reserved int64 `json:"-"` // Held by in-flight settlements
+	Settled  int64 `json:"-"` // Net amount committed across the edge; negative when more came back the other way
+
+	// Chaos degradation injected at runtime (see DegradeEdge), not exported
+	ExtraLatency int64   `json:"-"` // Added to Latency when weighing the edge
+	LiquidityCut float64 `json:"-"` // Fraction of LiquidityVolume withheld from Available
+}
+
+// Available returns the liquidity neither reserved, settled away nor withheld.
+// Only meaningful for edges that track liquidity.
+func (e *Edge) Available() int64 {
+	return e.LiquidityVolume - e.Reserved - e.Settled - e.Withheld()
+}
+
+// HasCapacity reports whether the edge can carry amount.
+// Edges with no tracked liquidity are treated as unconstrained.
+func (e *Edge) HasCapacity(amount int64) bool {
+	return amount <= 0 || e.LiquidityVolume == 0 || e.Available() >= amount
 }
 
 // Path represents a route through the mesh
@@ -75,6 +104,16 @@ func (g *Graph) AddEdge(edge *Edge) {
 	g.edges[edge.SourceID][edge.TargetID] = edge
 }
 
+// AddBidirectionalEdge adds an edge and its reverse with the same properties,
+// so settlement can flow both ways between the two nodes.
+func (g *Graph) AddBidirectionalEdge(edge *Edge) {
+	reverse := *edge
+	reverse.SourceID, reverse.TargetID = edge.TargetID, edge.SourceID
+
+	g.AddEdge(edge)
+	g.AddEdge(&reverse)
+}
+
 // UpdateNodeEntropy updates the entropy data for a node
 func (g *Graph) UpdateNodeEntropy(nodeID string, distribution map[string]float64) {
 	g.mu.Lock()
@@ -82,6 +121,26 @@ func (g *Graph) UpdateNodeEntropy(nodeID string, distribution map[string]float64
 	g.entropy[nodeID] = entropy.CalculateNodeEntropy(nodeID, distribution)
 }
 
+// NodeEntropies returns the liquidity distribution entropy of each node
+// that has one, keyed by node ID
+func (g *Graph) NodeEntropies() map[string]float64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	out := make(map[string]float64, len(g.entropy))
+	for id, e := range g.entropy {
+		out[id] = e.Entropy
+	}
+	return out
+}
+
+// ClearNodeEntropy removes a node's entropy data, so its edges are weighted
+// by fee alone
+func (g *Graph) ClearNodeEntropy(nodeID string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.entropy, nodeID)
+}
+
 // SetNodeActive marks a node as active
 func (g *Graph) SetNodeActive(nodeID string) {
 	g.mu.Lock()
@@ -181,6 +240,22 @@ func (g *Graph) UpdateEdge(sourceID, targetID string, baseFee float64, latency i
 	}
 }
 
+// SetLoadTracker enables the load-avoidance term in edge weights.
+// A penalty of 0 tracks load without affecting routing.
+func (g *Graph) SetLoadTracker(lt *LoadTracker, penalty float64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.load = lt
+	g.loadPenalty = penalty
+}
+
+// LoadTracker returns the graph's load tracker (may be nil)
+func (g *Graph) LoadTracker() *LoadTracker {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.load
+}
+
 // GetEdgeWeight calculates the entropy-weighted edge weight.
 // Formula: W = Fee × (1 + H), where H is Shannon entropy.
 func (g *Graph) GetEdgeWeight(edge *Edge) float64 {
@@ -203,15 +278,40 @@ func (g *Graph) getEdgeWeightUnlocked(edge *Edge) float64 {
 	weight := edge.BaseFee * (1.0 + H)
 	
 	// Add small latency component to break ties
-	weight += float64(edge.Latency) * 0.00001
+	weight += float64(edge.EffectiveLatency()) * 0.00001
+
+	// Busy target nodes are less preferred
+	if g.load != nil && g.loadPenalty > 0 {
+		weight += float64(g.load.Load(edge.TargetID)) * g.loadPenalty
+	}
 	
 	return weight
 }
 
+// weightFor weighs an edge for a routing preference. Caller must hold at least RLock.
+func (g *Graph) weightFor(edge *Edge, pref Preference) float64 {
+	if pref.IsZero() {
+		return g.getEdgeWeightUnlocked(edge)
+	}
+	H := 0.0
+	if nodeEntropy, ok := g.entropy[edge.SourceID]; ok {
+		H = nodeEntropy.Volatility()
+	}
+	weight := pref.weigh(edge.BaseFee, edge.EffectiveLatency(), H)
+
+	// Busy target nodes are less preferred regardless of preference
+	if g.load != nil && g.loadPenalty > 0 {
+		weight += float64(g.load.Load(edge.TargetID)) * g.loadPenalty
+	}
+	return weight
+}
+
 // Router provides path-finding capabilities
 type Router struct {
-	graph *Graph
-	k     int // Number of paths to find
+	graph      *Graph
+	k             int // Number of paths to find
+	preference    Preference
+	bidirectional bool // Search spur paths from both ends
 }
 
 // NewRouter creates a new router with the specified K value
@@ -222,248 +322,65 @@ func NewRouter(graph *Graph, k int) *Router {
 	return &Router{graph: graph, k: k}
 }
 
-// FindKShortestPaths implements Yen's algorithm to find K shortest paths.
-// Returns up to K alternative routes from source to target.
-func (r *Router) FindKShortestPaths(ctx context.Context, source, target string) ([]*Path, error) {
-	r.graph.mu.RLock()
-	defer r.graph.mu.RUnlock()
-	
-	// Verify source and target exist
-	if _, ok := r.graph.nodes[source]; !ok {
-		return nil, fmt.Errorf("source node not found: %s", source)
-	}
-	if _, ok := r.graph.nodes[target]; !ok {
-		return nil, fmt.Errorf("target node not found: %s", target)
-	}
-	
-	// Find the shortest path first using Dijkstra
-	shortestPath := r.dijkstra(source, target, nil, nil)
-	if shortestPath == nil {
-		return nil, fmt.Errorf("no path found from %s to %s", source, target)
-	}
-	
-	// A holds the K shortest paths
-	A := []*Path{shortestPath}
-	
-	// B is a min-heap of candidate paths
-	B := &pathHeap{}
-	heap.Init(B)
-	
-	// Yen's algorithm main loop
-	for k := 1; k < r.k; k++ {
-		// Check context
-		if ctx.Err() != nil {
-			return A, ctx.Err()
-		}
-		
-		// Get the previous shortest path
-		prevPath := A[k-1]
-		
-		// For each node in the previous path (except the last)
-		for i := 0; i < len(prevPath.Nodes)-1; i++ {
-			// Spur node is where we diverge from previous path
-			spurNode := prevPath.Nodes[i]
-			rootPath := prevPath.Nodes[:i+1]
-			
-			// Track edges and nodes to exclude
-			excludedEdges := make(map[string]bool)
-			excludedNodes := make(map[string]bool)
-			
-			// Exclude edges that share this root path
-			for _, path := range A {
-				if len(path.Nodes) > i && pathsSharePrefix(path.Nodes, rootPath) {
-					if i+1 < len(path.Nodes) {
-						edgeKey := path.Nodes[i] + "->" + path.Nodes[i+1]
-						excludedEdges[edgeKey] = true
-					}
-				}
-			}
-			
-			// Exclude root path nodes (except spur node)
-			for j := 0; j < i; j++ {
-				excludedNodes[prevPath.Nodes[j]] = true
-			}
-			
-			// Find shortest path from spur to target, excluding edges/nodes
-			spurPath := r.dijkstra(spurNode, target, excludedEdges, excludedNodes)
-			
-			if spurPath != nil {
-				// Combine root path with spur path
-				totalPath := r.combinePaths(rootPath, spurPath)
-				
-				// Add to candidates if not already in A
-				if !containsPath(A, totalPath) && !heapContainsPath(B, totalPath) {
-					heap.Push(B, totalPath)
-				}
-			}
-		}
-		
-		// No more candidates
-		if B.Len() == 0 {
-			break
-		}
-		
-		// Add the best candidate to A
-		bestCandidate := heap.Pop(B).(*Path)
-		A = append(A, bestCandidate)
-	}
-	
-	return A, nil
+// WithPreference returns a copy of the router that optimizes for pref
+func (r *Router) WithPreference(pref Preference) *Router {
+	cp := *r
+	cp.preference = pref
+	return &cp
 }
 
-// dijkstra finds the shortest path using Dijkstra's algorithm
-func (r *Router) dijkstra(source, target string, excludedEdges, excludedNodes map[string]bool) *Path {
-	if excludedNodes[source] || excludedNodes[target] {
-		return nil
-	}
-	
-	// Distance and predecessor maps
-	dist := make(map[string]float64)
-	prev := make(map[string]string)
-	prevEdge := make(map[string]*Edge)
-	
-	for nodeID := range r.graph.nodes {
-		dist[nodeID] = math.Inf(1)
-	}
-	dist[source] = 0
-	
-	// Priority queue
-	pq := &dijkstraHeap{{node: source, dist: 0}}
-	heap.Init(pq)
-	
-	visited := make(map[string]bool)
-	
-	for pq.Len() > 0 {
-		current := heap.Pop(pq).(*dijkstraItem)
-		
-		if visited[current.node] {
-			continue
-		}
-		visited[current.node] = true
-		
-		if current.node == target {
-			break
-		}
-		
-		// Explore neighbors
-		neighbors := r.graph.edges[current.node]
-		for targetID, edge := range neighbors {
-			if !edge.IsActive {
-				continue
-			}
-			// Skip inactive nodes
-			if targetNode, ok := r.graph.nodes[targetID]; ok && !targetNode.IsActive {
-				continue
-			}
-			if excludedNodes[targetID] {
-				continue
-			}
-			edgeKey := current.node + "->" + targetID
-			if excludedEdges[edgeKey] {
-				continue
-			}
-			
-			weight := r.graph.getEdgeWeightUnlocked(edge)
-			newDist := dist[current.node] + weight
-			
-			if newDist < dist[targetID] {
-				dist[targetID] = newDist
-				prev[targetID] = current.node
-				prevEdge[targetID] = edge
-				heap.Push(pq, &dijkstraItem{node: targetID, dist: newDist})
-			}
-		}
-	}
-	
-	// Reconstruct path
-	if dist[target] == math.Inf(1) {
-		return nil
-	}
-	
-	path := &Path{
-		Nodes:       []string{},
-		Edges:       []*Edge{},
-		TotalWeight: dist[target],
-	}
-	
-	// Build path backwards
-	current := target
-	for current != "" {
-		path.Nodes = append([]string{current}, path.Nodes...)
-		if edge, ok := prevEdge[current]; ok {
-			path.Edges = append([]*Edge{edge}, path.Edges...)
-			path.TotalFee += edge.BaseFee
-			path.TotalLatency += edge.Latency
-		}
-		current = prev[current]
-	}
-	
-	return path
+// WithBidirectional returns a copy of the router that uses bidirectional
+// Dijkstra, which explores fewer nodes on large, well-connected meshes
+func (r *Router) WithBidirectional(enabled bool) *Router {
+	cp := *r
+	cp.bidirectional = enabled
+	return &cp
 }
 
-// combinePaths combines a root path with a spur path
-func (r *Router) combinePaths(rootNodes []string, spurPath *Path) *Path {
-	combined := &Path{
-		Nodes: make([]string, 0, len(rootNodes)+len(spurPath.Nodes)-1),
-		Edges: make([]*Edge, 0),
-	}
-	
-	// Add root nodes
-	combined.Nodes = append(combined.Nodes, rootNodes...)
-	
-	// Add root edges
-	for i := 0; i < len(rootNodes)-1; i++ {
-		if edges, ok := r.graph.edges[rootNodes[i]]; ok {
-			if edge, ok := edges[rootNodes[i+1]]; ok {
-				combined.Edges = append(combined.Edges, edge)
-				combined.TotalFee += edge.BaseFee
-				combined.TotalLatency += edge.Latency
-				combined.TotalWeight += r.graph.getEdgeWeightUnlocked(edge)
-			}
-		}
-	}
-	
-	// Add spur path (skip first node as it's the spur node already in root)
-	if len(spurPath.Nodes) > 1 {
-		combined.Nodes = append(combined.Nodes, spurPath.Nodes[1:]...)
+// FindKShortestPaths implements Yen's algorithm to find K shortest paths.
+// Returns up to K alternative routes from source to target.
+// Edges without amount available (see Edge.Available) are skipped; amount <= 0 disables the check.
+// The graph is locked only while a snapshot is taken, so updates are not
+// blocked by long searches.
+func (r *Router) FindKShortestPaths(ctx context.Context, source, target string, amount int64) ([]*Path, error) {
+	defer metrics.RouteComputeDuration.ObserveSince(time.Now(), "mesh")
+
+	snap, err := r.snapshot(source, target, amount)
+	if err != nil {
+		return nil, err
 	}
-	combined.Edges = append(combined.Edges, spurPath.Edges...)
-	combined.TotalFee += spurPath.TotalFee
-	combined.TotalLatency += spurPath.TotalLatency
-	combined.TotalWeight += spurPath.TotalWeight
-	
-	return combined
-}
 
-// Helper functions
-func pathsSharePrefix(path, prefix []string) bool {
-	if len(prefix) > len(path) {
-		return false
-	}
-	for i := range prefix {
-		if path[i] != prefix[i] {
-			return false
+	buf := getSearchBuffers(len(snap.ids))
+	defer putSearchBuffers(buf)
+
+	found, err := snap.kShortest(ctx, buf, snap.index[source], snap.index[target], r.k, r.bidirectional)
+	if len(found) == 0 && err == nil {
+		if amount > 0 {
+			return nil, fmt.Errorf("no path found from %s to %s with liquidity for %d", source, target, amount)
 		}
+		return nil, fmt.Errorf("no path found from %s to %s", source, target)
 	}
-	return true
-}
 
-func containsPath(paths []*Path, path *Path) bool {
-	for _, p := range paths {
-		if pathsEqual(p.Nodes, path.Nodes) {
-			return true
-		}
+	paths := make([]*Path, len(found))
+	for i, c := range found {
+		paths[i] = snap.path(c)
 	}
-	return false
+	return paths, err
 }
 
-func heapContainsPath(h *pathHeap, path *Path) bool {
-	for _, p := range *h {
-		if pathsEqual(p.Nodes, path.Nodes) {
-			return true
-		}
+// snapshot verifies the endpoints and copies the routable graph
+func (r *Router) snapshot(source, target string, amount int64) (*meshSnapshot, error) {
+	r.graph.mu.RLock()
+	defer r.graph.mu.RUnlock()
+
+	// Verify source and target exist
+	if _, ok := r.graph.nodes[source]; !ok {
+		return nil, fmt.Errorf("source node not found: %s", source)
 	}
-	return false
+	if _, ok := r.graph.nodes[target]; !ok {
+		return nil, fmt.Errorf("target node not found: %s", target)
+	}
+	return r.graph.snapshot(amount, r.preference), nil
 }
 
 func pathsEqual(a, b []string) bool {
@@ -477,46 +394,3 @@ func pathsEqual(a, b []string) bool {
 	}
 	return true
 }
-
-// pathHeap is a min-heap of paths ordered by total weight
-type pathHeap []*Path
-
-func (h pathHeap) Len() int           { return len(h) }
-func (h pathHeap) Less(i, j int) bool { return h[i].TotalWeight < h[j].TotalWeight }
-func (h pathHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
-
-func (h *pathHeap) Push(x interface{}) {
-	*h = append(*h, x.(*Path))
-}
-
-func (h *pathHeap) Pop() interface{} {
-	old := *h
-	n := len(old)
-	x := old[n-1]
-	*h = old[0 : n-1]
-	return x
-}
-
-// dijkstraItem represents a node in the priority queue
-type dijkstraItem struct {
-	node string
-	dist float64
-}
-
-type dijkstraHeap []*dijkstraItem
-
-func (h dijkstraHeap) Len() int           { return len(h) }
-func (h dijkstraHeap) Less(i, j int) bool { return h[i].dist < h[j].dist }
-func (h dijkstraHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
-
-func (h *dijkstraHeap) Push(x interface{}) {
-	*h = append(*h, x.(*dijkstraItem))
-}
-
-func (h *dijkstraHeap) Pop() interface{} {
-	old := *h
-	n := len(old)
-	x := old[n-1]
-	*h = old[0 : n-1]
-	return x
-}
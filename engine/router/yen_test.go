@@ -5,6 +5,7 @@ package router
 import (
 	"context"
 	"fmt"
+	"math"
 	"math/rand"
 	"testing"
 	"time"
@@ -16,7 +17,7 @@ func TestYenKShortestPaths(t *testing.T) {
 	router := NewRouter(graph, 3)
 
 	ctx := context.Background()
-	paths, err := router.FindKShortestPaths(ctx, "node_0", "node_9")
+	paths, err := router.FindKShortestPaths(ctx, "node_0", "node_9", 0)
 
 	if err != nil {
 		t.Fatalf("Failed to find paths: %v", err)
@@ -82,6 +83,79 @@ func TestEntropyWeighting(t *testing.T) {
 	}
 }
 
+// TestCapacityAwareRouting verifies edges without enough liquidity are skipped
+func TestCapacityAwareRouting(t *testing.T) {
+	graph := NewGraph()
+	for _, id := range []string{"A", "B", "C", "D"} {
+		graph.AddNode(&Node{ID: id, Type: "Hub", IsActive: true})
+	}
+
+	// Cheap path A->B->D has only 10k of liquidity on B->D
+	graph.AddEdge(&Edge{SourceID: "A", TargetID: "B", BaseFee: 0.001, Latency: 10, LiquidityVolume: 50000000, IsActive: true})
+	graph.AddEdge(&Edge{SourceID: "B", TargetID: "D", BaseFee: 0.001, Latency: 10, LiquidityVolume: 10000, IsActive: true})
+	// Expensive path A->C->D has deep liquidity
+	graph.AddEdge(&Edge{SourceID: "A", TargetID: "C", BaseFee: 0.002, Latency: 10, LiquidityVolume: 50000000, IsActive: true})
+	graph.AddEdge(&Edge{SourceID: "C", TargetID: "D", BaseFee: 0.002, Latency: 10, LiquidityVolume: 50000000, IsActive: true})
+
+	router := NewRouter(graph, 3)
+	ctx := context.Background()
+
+	small, err := router.FindKShortestPaths(ctx, "A", "D", 5000)
+	if err != nil {
+		t.Fatalf("Failed to find paths for small payment: %v", err)
+	}
+	if len(small) != 2 || !pathsEqual(small[0].Nodes, []string{"A", "B", "D"}) {
+		t.Fatalf("Expected cheap path first and both paths for small payment, got %d paths starting %v", len(small), small[0].Nodes)
+	}
+
+	large, err := router.FindKShortestPaths(ctx, "A", "D", 10000000)
+	if err != nil {
+		t.Fatalf("Failed to find paths for large payment: %v", err)
+	}
+	if len(large) != 1 || !pathsEqual(large[0].Nodes, []string{"A", "C", "D"}) {
+		t.Fatalf("Expected only A->C->D for large payment, got %d paths starting %v", len(large), large[0].Nodes)
+	}
+
+	if _, err := router.FindKShortestPaths(ctx, "A", "D", 100000000); err == nil {
+		t.Fatal("Expected no path when no route has enough liquidity")
+	}
+}
+
+// TestBidirectionalEdgeReverseRouting verifies settlement can flow back along symmetric edges
+func TestBidirectionalEdgeReverseRouting(t *testing.T) {
+	graph := NewGraph()
+	for _, id := range []string{"sme_001", "hub", "sme_003"} {
+		graph.AddNode(&Node{ID: id, Type: "Hub", IsActive: true})
+	}
+	graph.AddBidirectionalEdge(&Edge{SourceID: "sme_001", TargetID: "hub", BaseFee: 0.001, Latency: 10, LiquidityVolume: 1000, IsActive: true})
+	graph.AddBidirectionalEdge(&Edge{SourceID: "hub", TargetID: "sme_003", BaseFee: 0.002, Latency: 20, IsActive: true})
+
+	router := NewRouter(graph, 3)
+	ctx := context.Background()
+
+	for _, tc := range [][2]string{{"sme_001", "sme_003"}, {"sme_003", "sme_001"}} {
+		paths, err := router.FindKShortestPaths(ctx, tc[0], tc[1], 0)
+		if err != nil {
+			t.Fatalf("%s -> %s: %v", tc[0], tc[1], err)
+		}
+		if len(paths[0].Nodes) != 3 || paths[0].TotalFee != 0.003 {
+			t.Errorf("%s -> %s: got %v (fee %.4f)", tc[0], tc[1], paths[0].Nodes, paths[0].TotalFee)
+		}
+	}
+
+	reverse := graph.edges["hub"]["sme_001"]
+	if reverse == nil || reverse.LiquidityVolume != 1000 || reverse.Latency != 10 {
+		t.Fatalf("Reverse edge should copy forward edge properties, got %+v", reverse)
+	}
+
+	// Directed edges remain one-way
+	graph.AddNode(&Node{ID: "sme_009", Type: "SME", IsActive: true})
+	graph.AddEdge(&Edge{SourceID: "hub", TargetID: "sme_009", BaseFee: 0.001, IsActive: true})
+	if _, err := router.FindKShortestPaths(ctx, "sme_009", "hub", 0); err == nil {
+		t.Fatal("Expected no reverse path over a directed edge")
+	}
+}
+
 // BenchmarkYen50Nodes is Checkpoint 2: K=3 paths in <10ms for 50-node graph
 func BenchmarkYen50Nodes(b *testing.B) {
 	graph := buildTestGraph(50)
@@ -95,7 +169,7 @@ func BenchmarkYen50Nodes(b *testing.B) {
 
 	for i := 0; i < b.N; i++ {
 		start := time.Now()
-		paths, err := router.FindKShortestPaths(ctx, "node_0", "node_49")
+		paths, err := router.FindKShortestPaths(ctx, "node_0", "node_49", 0)
 		elapsed := time.Since(start)
 		totalDuration += elapsed
 		runCount++
@@ -130,7 +204,7 @@ func BenchmarkYen100Nodes(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		_, err := router.FindKShortestPaths(ctx, "node_0", "node_99")
+		_, err := router.FindKShortestPaths(ctx, "node_0", "node_99", 0)
 		if err != nil {
 			b.Fatalf("Failed to find paths: %v", err)
 		}
@@ -191,3 +265,80 @@ func min(a, b int) int {
 	}
 	return b
 }
+
+// TestBidirectionalMatchesDijkstra verifies both searches return the same paths
+func TestBidirectionalMatchesDijkstra(t *testing.T) {
+	ctx := context.Background()
+	for _, n := range []int{10, 50, 200} {
+		graph := buildTestGraph(n)
+		target := fmt.Sprintf("node_%d", n-1)
+
+		want, err := NewRouter(graph, 5).FindKShortestPaths(ctx, "node_0", target, 0)
+		if err != nil {
+			t.Fatalf("%d nodes: %v", n, err)
+		}
+		got, err := NewRouter(graph, 5).WithBidirectional(true).FindKShortestPaths(ctx, "node_0", target, 0)
+		if err != nil {
+			t.Fatalf("%d nodes, bidirectional: %v", n, err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("%d nodes: got %d paths, want %d", n, len(got), len(want))
+		}
+		for i := range want {
+			if math.Abs(got[i].TotalWeight-want[i].TotalWeight) > 1e-12 || len(got[i].Edges) != len(got[i].Nodes)-1 {
+				t.Errorf("%d nodes, path %d: got %v (%.9f), want %v (%.9f)",
+					n, i+1, got[i].Nodes, got[i].TotalWeight, want[i].Nodes, want[i].TotalWeight)
+			}
+		}
+	}
+}
+
+// BenchmarkYen1000Nodes covers K=3 on a 1,000-node mesh (target <10ms)
+func BenchmarkYen1000Nodes(b *testing.B) {
+	for _, bidirectional := range []bool{false, true} {
+		name := "dijkstra"
+		if bidirectional {
+			name = "bidirectional"
+		}
+		b.Run(name, func(b *testing.B) {
+			graph := buildMeshGraph(1000)
+			router := NewRouter(graph, 3).WithBidirectional(bidirectional)
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := router.FindKShortestPaths(ctx, "node_0", "node_999", 0); err != nil {
+					b.Fatalf("Failed to find paths: %v", err)
+				}
+			}
+		})
+	}
+}
+
+// buildMeshGraph creates a randomly connected mesh with n nodes and 4 outgoing
+// edges per node, approximating a production topology
+func buildMeshGraph(n int) *Graph {
+	graph := NewGraph()
+	rng := rand.New(rand.NewSource(42))
+
+	for i := 0; i < n; i++ {
+		graph.AddNode(&Node{ID: fmt.Sprintf("node_%d", i), Type: "Hub", IsActive: true})
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < 4; j++ {
+			target := rng.Intn(n)
+			if target == i {
+				continue
+			}
+			graph.AddBidirectionalEdge(&Edge{
+				SourceID: fmt.Sprintf("node_%d", i),
+				TargetID: fmt.Sprintf("node_%d", target),
+				BaseFee:  0.001 + rng.Float64()*0.002,
+				Latency:  int64(5 + rng.Intn(20)),
+				IsActive: true,
+			})
+		}
+	}
+	return graph
+}
@@ -39,8 +39,11 @@ interface AdminData {
         success_count: number;
         failed_count: number;
         pending_count: number;
+        refund_count: number;
+        refunded_amount: number;
+        retained_fees: number;
     };
-    all_transactions: Transaction[];
+    recent_transactions: Transaction[];
     analytics: Analytics;
 }
 
@@ -110,7 +113,7 @@ export default function AdminAnalyticsPage() {
     }
 
     const analytics = data?.analytics;
-    const transactions = data?.all_transactions || [];
+    const transactions = data?.recent_transactions || [];
 
     // Prepare chart data
     const volumeLabels = Object.keys(analytics?.daily_volume || {}).sort();
@@ -156,11 +159,16 @@ export default function AdminAnalyticsPage() {
                     <div className="bg-gradient-to-br from-red-500/20 to-red-600/10 rounded-2xl p-6 border border-red-500/30">
                         <div className="text-red-400 text-sm mb-2">❌ Failed</div>
                         <div className="text-3xl font-bold text-white">{analytics?.failed_count || 0}</div>
+                        {(data?.stats.refund_count || 0) > 0 && (
+                            <div className="text-red-300/70 text-xs mt-1">
+                                {data?.stats.refund_count} refunded (${(data?.stats.refunded_amount || 0).toFixed(2)}, ${(data?.stats.retained_fees || 0).toFixed(2)} fees retained)
+                            </div>
+                        )}
                     </div>
                 </div>
 
                 {/* Charts */}
-                {transactions.length > 0 && (
+                {volumeLabels.length > 0 && (
                     <div className="grid grid-cols-1 lg:grid-cols-2 gap-6 mb-8">
                         <div className="bg-slate-800/50 rounded-2xl p-6 border border-white/10">
                             <h3 className="text-lg font-semibold text-white mb-4">📈 Daily Volume</h3>
@@ -185,11 +193,11 @@ export default function AdminAnalyticsPage() {
                     </div>
                 )}
 
-                {/* All Transactions Table */}
+                {/* Recent Transactions Table */}
                 <div className="bg-slate-800/50 rounded-2xl p-6 border border-white/10">
                     <div className="flex items-center justify-between mb-4">
-                        <h3 className="text-lg font-semibold text-white">📋 All Transactions</h3>
-                        <span className="text-slate-400 text-sm">{transactions.length} total</span>
+                        <h3 className="text-lg font-semibold text-white">📋 Recent Transactions</h3>
+                        <span className="text-slate-400 text-sm">{transactions.length} of {analytics?.total_transactions || 0}</span>
                     </div>
 
                     {transactions.length === 0 ? (
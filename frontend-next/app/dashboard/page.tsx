@@ -6,7 +6,7 @@ import { useAuth } from '@/lib/auth-context';
 import { useBlockedCountries } from '@/lib/blocked-countries-context';
 import { useWebSocket } from '@/lib/websocket-context';
 import { COUNTRY_COORDINATES, TRADE_CONNECTIONS, getFlagEmoji, CountryGeo } from '@/lib/country-data';
-import { auth, API_BASE_URL } from '@/lib/auth';
+import { API_BASE_URL, wsUrl } from '@/lib/auth';
 import Link from 'next/link';
 
 // Dynamic import for Leaflet map (SSR disabled)
@@ -71,6 +71,7 @@ export default function Dashboard() {
     const [isCalculating, setIsCalculating] = useState(false);
     const [routeError, setRouteError] = useState<string | null>(null);
     const routeWsRef = useRef<WebSocket | null>(null);
+    const [routesVersion, setRoutesVersion] = useState(0); // Bumped when the server invalidates routes
 
     // Load Leaflet CSS on client side
     useEffect(() => {
@@ -83,8 +84,7 @@ export default function Dashboard() {
 
     // Connect to route WebSocket
     useEffect(() => {
-        const wsUrl = API_BASE_URL.replace('http', 'ws') + '/ws/route';
-        const ws = new WebSocket(wsUrl);
+        const ws = new WebSocket(wsUrl('/ws/route'));
 
         ws.onopen = () => {
             console.log('Route WebSocket connected');
@@ -103,6 +103,8 @@ export default function Dashboard() {
                         setRouteError(data.error || 'Route calculation failed');
                         setRoutes([]);
                     }
+                } else if (data.type === 'routes_invalidated') {
+                    setRoutesVersion(v => v + 1);
                 }
             } catch {
                 console.error('Failed to parse route response');
@@ -142,12 +144,12 @@ export default function Dashboard() {
         } else {
             setRoutes([]);
         }
-    }, [startNode, endNode, blockedCountries]);
+    }, [startNode, endNode, blockedCountries, routesVersion]);
 
     // Fetch countries from API
     const fetchCountries = useCallback(async () => {
         try {
-            const response = await auth.authFetch(`${API_BASE_URL}/api/v1/admin/countries`);
+            const response = await fetch(`${API_BASE_URL}/api/v1/countries`);
             if (response.ok) {
                 const data = await response.json();
                 setCountries(data.countries || []);
@@ -0,0 +1,81 @@
+'use client';
+
+import { useState } from 'react';
+import Link from 'next/link';
+import { auth } from '@/lib/auth';
+
+export default function ForgotPasswordPage() {
+    const [email, setEmail] = useState('');
+    const [message, setMessage] = useState('');
+    const [error, setError] = useState('');
+    const [isLoading, setIsLoading] = useState(false);
+
+    const handleSubmit = async (e: React.FormEvent) => {
+        e.preventDefault();
+        setError('');
+        setMessage('');
+        setIsLoading(true);
+
+        try {
+            setMessage(await auth.forgotPassword(email));
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Password reset request failed');
+        } finally {
+            setIsLoading(false);
+        }
+    };
+
+    return (
+        <div className="min-h-screen flex items-center justify-center px-4">
+            <div className="w-full max-w-md">
+                <div className="bg-slate-800/50 backdrop-blur-lg rounded-2xl border border-white/10 p-8 shadow-2xl">
+                    <div className="text-center mb-8">
+                        <div className="text-4xl mb-4">🔑</div>
+                        <h1 className="text-2xl font-bold text-white">Forgot Password</h1>
+                        <p className="text-slate-400 mt-2">We&apos;ll email you a link to choose a new one</p>
+                    </div>
+
+                    <form onSubmit={handleSubmit} className="space-y-6">
+                        <div>
+                            <label className="block text-sm text-slate-400 mb-2">Email</label>
+                            <input
+                                type="email"
+                                value={email}
+                                onChange={(e) => setEmail(e.target.value)}
+                                placeholder="your@email.com"
+                                required
+                                className="w-full px-4 py-3 bg-black/30 border border-white/10 rounded-lg text-white placeholder-slate-500 focus:outline-none focus:ring-2 focus:ring-emerald-500 focus:border-transparent transition-all"
+                            />
+                        </div>
+
+                        {message && (
+                            <div className="p-3 bg-emerald-500/10 border border-emerald-500/20 rounded-lg text-emerald-400 text-sm">
+                                {message}
+                            </div>
+                        )}
+
+                        {error && (
+                            <div className="p-3 bg-red-500/10 border border-red-500/20 rounded-lg text-red-400 text-sm">
+                                {error}
+                            </div>
+                        )}
+
+                        <button
+                            type="submit"
+                            disabled={isLoading}
+                            className="w-full py-3 bg-gradient-to-r from-emerald-500 to-cyan-500 text-slate-900 font-semibold rounded-lg hover:opacity-90 transition-opacity disabled:opacity-50 disabled:cursor-not-allowed"
+                        >
+                            {isLoading ? 'Sending...' : 'Send reset link'}
+                        </button>
+                    </form>
+
+                    <div className="mt-6 text-center">
+                        <Link href="/login" className="text-emerald-400 text-sm hover:underline">
+                            Back to login
+                        </Link>
+                    </div>
+                </div>
+            </div>
+        </div>
+    );
+}
@@ -2,6 +2,7 @@
 
 import { useState, useEffect } from 'react';
 import { useRouter } from 'next/navigation';
+import Link from 'next/link';
 import { useAuth } from '@/lib/auth-context';
 
 export default function LoginPage() {
@@ -78,6 +79,12 @@ export default function LoginPage() {
                             />
                         </div>
 
+                        <div className="text-right -mt-3">
+                            <Link href="/forgot-password" className="text-sm text-emerald-400 hover:underline">
+                                Forgot password?
+                            </Link>
+                        </div>
+
                         {error && (
                             <div className="p-3 bg-red-500/10 border border-red-500/20 rounded-lg text-red-400 text-sm">
                                 {error}
@@ -1,6 +1,7 @@
 'use client';
 
 import { useAuth } from '@/lib/auth-context';
+import { wsUrl } from '@/lib/auth';
 import Link from 'next/link';
 import { useEffect, useState } from 'react';
 
@@ -9,14 +10,14 @@ export default function Home() {
   const [wsStatus, setWsStatus] = useState<'connecting' | 'connected' | 'disconnected'>('connecting');
 
   useEffect(() => {
-    const ws = new WebSocket('ws://localhost:8080/ws');
+    const ws = new WebSocket(wsUrl('/ws'));
 
     ws.onopen = () => setWsStatus('connected');
     ws.onclose = () => setWsStatus('disconnected');
     ws.onerror = () => setWsStatus('disconnected');
 
     return () => ws.close();
-  }, []);
+  }, [user]);
 
   return (
     <div className="min-h-screen">
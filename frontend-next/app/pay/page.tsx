@@ -7,6 +7,16 @@ import { auth, API_BASE_URL } from '@/lib/auth';
 import { getFlagEmoji } from '@/lib/country-data';
 import Link from 'next/link';
 
+interface HopFee {
+    from: string;
+    to: string;
+    credibility?: number;
+    success_rate?: number;
+    multiplier: number;
+    rate: number;
+    fee: number;
+}
+
 interface FeeBreakdown {
     base_fee: number;
     base_fee_rate: string;
@@ -17,6 +27,7 @@ interface FeeBreakdown {
     halt_count: number;
     total_fees: number;
     final_amount: number;
+    hops?: HopFee[];
 }
 
 interface Transaction {
@@ -50,6 +61,15 @@ interface StripeInitResponse {
     is_mock_mode: boolean;
 }
 
+// A payment sent to authenticate (3-D Secure), resumed when Stripe returns the payer
+interface PendingPayment {
+    transaction_id: string;
+    stripe_payment_id: string;
+    route: string[];
+}
+
+const PENDING_PAYMENT_KEY = 'plm_pending_payment';
+
 export default function PayPage() {
     return (
         <Suspense fallback={<div className="min-h-screen flex items-center justify-center">Loading...</div>}>
@@ -63,8 +83,16 @@ function PayPageContent() {
     const searchParams = useSearchParams();
     const router = useRouter();
 
+    // Stripe appends payment_intent to the return URL after 3-D Secure
+    const returnedIntent = searchParams.get('payment_intent');
+    const [pending] = useState<PendingPayment | null>(() => {
+        if (typeof window === 'undefined') return null;
+        const saved = sessionStorage.getItem(PENDING_PAYMENT_KEY);
+        return saved ? JSON.parse(saved) : null;
+    });
+
     const routeParam = searchParams.get('route');
-    const route = routeParam ? routeParam.split(',') : [];
+    const route = routeParam ? routeParam.split(',') : (returnedIntent && pending ? pending.route : []);
 
     const [amount, setAmount] = useState('1000');
     const [currency] = useState('USD');
@@ -137,12 +165,18 @@ function PayPageContent() {
 
             const data = await response.json();
 
-            if (data.success) {
-                setSuccess(true);
-                setFinalTransaction(data.transaction);
-            } else {
-                setError(data.message || 'Payment failed during mesh processing');
+            if (data.requires_action && data.next_action?.redirect_url) {
+                // Authenticate with the card issuer; Stripe sends the payer back here
+                const pendingPayment: PendingPayment = {
+                    transaction_id: stripeData.transaction_id,
+                    stripe_payment_id: stripeData.stripe_payment_id,
+                    route
+                };
+                sessionStorage.setItem(PENDING_PAYMENT_KEY, JSON.stringify(pendingPayment));
+                window.location.href = data.next_action.redirect_url;
+                return;
             }
+            showResult(data);
         } catch (err) {
             setError(err instanceof Error ? err.message : 'Payment processing failed');
         } finally {
@@ -151,8 +185,47 @@ function PayPageContent() {
         }
     };
 
+    // Step 3 (3-D Secure only): settle the payment once the payer has authenticated
+    const resumePayment = async (payment: PendingPayment) => {
+        setIsProcessing(true);
+        setError(null);
+
+        try {
+            const response = await auth.authFetch(`${API_BASE_URL}/api/v1/stripe/resume`, {
+                method: 'POST',
+                headers: { 'Content-Type': 'application/json' },
+                body: JSON.stringify({
+                    transaction_id: payment.transaction_id,
+                    stripe_payment_id: payment.stripe_payment_id
+                })
+            });
+            showResult(await response.json());
+        } catch (err) {
+            setError(err instanceof Error ? err.message : 'Payment processing failed');
+        } finally {
+            setIsProcessing(false);
+        }
+    };
+
+    const showResult = (data: { success?: boolean; transaction?: Transaction; message?: string; error?: string }) => {
+        if (data.success && data.transaction) {
+            setSuccess(true);
+            setFinalTransaction(data.transaction);
+        } else {
+            setError(data.message || data.error || 'Payment failed during mesh processing');
+        }
+    };
+
+    // Returning from 3-D Secure
+    useEffect(() => {
+        if (!user || !returnedIntent || !pending || pending.stripe_payment_id !== returnedIntent) return;
+        sessionStorage.removeItem(PENDING_PAYMENT_KEY);
+        resumePayment(pending);
+    }, [user, returnedIntent]);
+
     // Initiate when user logs in or route changes
     useEffect(() => {
+        if (returnedIntent) return; // Resuming an existing payment
         if (route.length >= 2 && parseFloat(amount) > 0 && user) {
             initiatePayment();
         }
@@ -160,7 +233,7 @@ function PayPageContent() {
 
     // Auto-reinitiate with debounce when amount changes
     useEffect(() => {
-        if (!user || route.length < 2 || parseFloat(amount) <= 0) return;
+        if (!user || returnedIntent || route.length < 2 || parseFloat(amount) <= 0) return;
 
         setStripeData(null); // Clear old data
         const timer = setTimeout(() => {
@@ -260,7 +333,7 @@ function PayPageContent() {
                         {/* Actions */}
                         <div className="flex gap-4 justify-center">
                             <a
-                                href={`${API_BASE_URL}/api/v1/receipts/${finalTransaction.id}`}
+                                href={`${API_BASE_URL}/api/v1/receipts/${finalTransaction.id}?format=pdf`}
                                 target="_blank"
                                 className="px-6 py-3 bg-blue-500 text-white rounded-xl font-semibold hover:bg-blue-600 transition-colors"
                             >
@@ -426,6 +499,12 @@ function PayPageContent() {
                                         <span className="text-slate-400">Hop Fees ({stripeData.fee_breakdown.hop_fee_rate} × {stripeData.fee_breakdown.hop_count})</span>
                                         <span className="text-red-400">-${stripeData.fee_breakdown.hop_fees.toFixed(2)}</span>
                                     </div>
+                                    {stripeData.fee_breakdown.hops?.map((hop) => (
+                                        <div key={`${hop.from}-${hop.to}`} className="flex justify-between text-xs pl-4">
+                                            <span className="text-slate-500">{hop.from} → {hop.to} ({hop.multiplier.toFixed(2)}×)</span>
+                                            <span className="text-slate-500">-${hop.fee.toFixed(4)}</span>
+                                        </div>
+                                    ))}
                                     {stripeData.fee_breakdown.halt_fines > 0 && (
                                         <div className="flex justify-between text-sm">
                                             <span className="text-slate-400">Halt Fines</span>
@@ -199,7 +199,7 @@ export default function PaySuccessPage() {
                                             <td className="py-3">
                                                 {txn.status === 'success' && (
                                                     <a
-                                                        href={`${API_BASE_URL}/api/v1/receipts/${txn.id}`}
+                                                        href={`${API_BASE_URL}/api/v1/receipts/${txn.id}?format=pdf`}
                                                         target="_blank"
                                                         className="text-blue-400 hover:text-blue-300 text-sm"
                                                     >
@@ -194,7 +194,7 @@ export default function TransactionsPage() {
                                     </div>
                                     {txn.status === 'success' && (
                                         <a
-                                            href={`${API_BASE_URL}/api/v1/receipts/${txn.id}`}
+                                            href={`${API_BASE_URL}/api/v1/receipts/${txn.id}?format=pdf`}
                                             target="_blank"
                                             className="px-4 py-2 bg-blue-500/20 text-blue-400 rounded-lg text-sm hover:bg-blue-500/30"
                                         >
@@ -5,7 +5,7 @@ export interface User {
     id: string;
     email: string;
     username: string;
-    role: 'ADMIN' | 'USER';
+    role: 'ADMIN' | 'OPERATOR' | 'AUDITOR' | 'TREASURY' | 'USER' | 'SERVICE';
     is_active: boolean;
 }
 
@@ -83,6 +83,34 @@ export const auth = {
         return data;
     },
 
+    async forgotPassword(email: string): Promise<string> {
+        const response = await fetch(`${API_BASE_URL}/api/v1/auth/forgot-password`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ email }),
+        });
+
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || 'Password reset request failed');
+        }
+        return data.message;
+    },
+
+    async resetPassword(token: string, password: string): Promise<string> {
+        const response = await fetch(`${API_BASE_URL}/api/v1/auth/reset-password`, {
+            method: 'POST',
+            headers: { 'Content-Type': 'application/json' },
+            body: JSON.stringify({ token, password }),
+        });
+
+        const data = await response.json();
+        if (!response.ok) {
+            throw new Error(data.error || 'Password reset failed');
+        }
+        return data.message;
+    },
+
     logout(): void {
         this.clearAuth();
     },
@@ -101,3 +129,11 @@ export const auth = {
         return fetch(url, { ...options, headers });
     },
 };
+
+// WebSocket URL for path. Browsers cannot set headers on the upgrade request,
+// so the access token is passed as a query parameter.
+export function wsUrl(path: string): string {
+    const url = API_BASE_URL.replace(/^http/, 'ws') + path;
+    const token = auth.getToken();
+    return token ? `${url}?token=${encodeURIComponent(token)}` : url;
+}
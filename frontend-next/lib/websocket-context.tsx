@@ -1,6 +1,7 @@
 'use client';
 
 import { createContext, useContext, useEffect, useState, useCallback, ReactNode, useRef } from 'react';
+import { wsUrl } from '@/lib/auth';
 
 export interface FXRate {
     currency: string;
@@ -28,7 +29,7 @@ export function WebSocketProvider({ children }: { children: ReactNode }) {
         if (wsRef.current?.readyState === WebSocket.OPEN) return;
 
         try {
-            const ws = new WebSocket('ws://localhost:8080/ws');
+            const ws = new WebSocket(wsUrl('/ws'));
             wsRef.current = ws;
 
             ws.onopen = () => {
@@ -43,6 +44,26 @@ export function WebSocketProvider({ children }: { children: ReactNode }) {
                 try {
                     const message = JSON.parse(event.data);
 
+                    // Freshly fetched rates pushed by the FX worker
+                    if (message.type === 'FX_RATE' && message.data?.rates) {
+                        const timestamp = message.data.updated_at
+                            ? new Date(message.data.updated_at).getTime()
+                            : Date.now();
+
+                        setFxRates((prev) => {
+                            const next = new Map(prev);
+                            Object.entries(message.data.rates).forEach(([currency, rate]) => {
+                                next.set(currency, {
+                                    currency,
+                                    rate: rate as number,
+                                    timestamp,
+                                });
+                            });
+                            return next;
+                        });
+                        setLastUpdate(timestamp);
+                    }
+
                     if (message.type === 'fx_update' && message.rates) {
                         const newRates = new Map<string, FXRate>();
                         const timestamp = Date.now();
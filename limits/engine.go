@@ -0,0 +1,283 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// VolumeSource reports the transfer volume limits are measured against.
+// Implemented by payments.TransactionStore.
+type VolumeSource interface {
+	UserVolume(userID string, since time.Time) float64
+	OrgVolume(orgID string, since time.Time) float64
+}
+
+// Usage is a subject's limits and how much of them has been used
+type Usage struct {
+	Scope            Scope    `json:"scope"`
+	SubjectID        string   `json:"subject_id"`
+	Limits           Limits   `json:"limits"`
+	DailyUsed        float64  `json:"daily_used"`
+	MonthlyUsed      float64  `json:"monthly_used"`
+	DailyRemaining   *float64 `json:"daily_remaining,omitempty"`   // Omitted when unlimited
+	MonthlyRemaining *float64 `json:"monthly_remaining,omitempty"` // Omitted when unlimited
+}
+
+// Quota is the spending headroom a user has left
+type Quota struct {
+	User           Usage     `json:"user"`
+	Org            *Usage    `json:"org,omitempty"`             // Set for organization members
+	MaxTransaction *float64  `json:"max_transaction,omitempty"` // Largest transfer allowed right now; omitted when unlimited
+	DayResetsAt    time.Time `json:"day_resets_at"`
+	MonthResetsAt  time.Time `json:"month_resets_at"`
+}
+
+// Engine checks new transfers against the limits configured for the payer
+// and their organization
+type Engine struct {
+	store    Store
+	volumes  VolumeSource
+	orgs     payments.OrgResolver
+	defaults Limits
+
+	mu       sync.Mutex          // guards reserved
+	reserved map[ruleKey]float64 // Held by Reserve for transfers being created
+}
+
+// NewEngine creates a limits engine reading rules from store and volume from volumes
+func NewEngine(store Store, volumes VolumeSource) *Engine {
+	return &Engine{store: store, volumes: volumes, reserved: make(map[ruleKey]float64)}
+}
+
+// SetDefaults sets the limits for users without a rule when no default
+// rule has been stored
+func (e *Engine) SetDefaults(defaults Limits) {
+	e.defaults = defaults
+}
+
+// Defaults returns the configured fallback limits for users
+func (e *Engine) Defaults() Limits {
+	return e.defaults
+}
+
+// SetOrgResolver applies organization limits to organization members. An
+// organization's own monthly spending limit counts as an org monthly limit.
+func (e *Engine) SetOrgResolver(orgs payments.OrgResolver) {
+	e.orgs = orgs
+}
+
+// Check returns an error wrapping ErrLimitExceeded if userID may not
+// transfer amounts now. Each amount is held to the per-transaction cap and
+// their total to the daily and monthly limits, so a batch is checked in one call.
+func (e *Engine) Check(ctx context.Context, userID string, amounts ...float64) error {
+	release, err := e.Reserve(ctx, userID, amounts...)
+	if err != nil {
+		return err
+	}
+	release()
+	return nil
+}
+
+// Reserve checks amounts like Check and holds them against the limits of
+// userID and their organization until release is called, so concurrent
+// transfers cannot together exceed a limit. Call release once the
+// transactions are stored, and their volume counts, or were not created.
+func (e *Engine) Reserve(ctx context.Context, userID string, amounts ...float64) (release func(), err error) {
+	user, org, err := e.resolve(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	quota := e.quotaLocked(userID, user, org)
+	if err := quota.User.check(amounts); err != nil {
+		return nil, err
+	}
+	if quota.Org != nil {
+		if err := quota.Org.check(amounts); err != nil {
+			return nil, err
+		}
+	}
+
+	total := 0.0
+	for _, a := range amounts {
+		total += a
+	}
+	keys := []ruleKey{{ScopeUser, userID}}
+	if org != nil {
+		keys = append(keys, ruleKey{ScopeOrg, org.OrgID})
+	}
+	for _, key := range keys {
+		e.reserved[key] += total
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			for _, key := range keys {
+				if e.reserved[key] -= total; e.reserved[key] <= 1e-9 {
+					delete(e.reserved, key)
+				}
+			}
+		})
+	}, nil
+}
+
+// Quota returns userID's limits and remaining headroom
+func (e *Engine) Quota(ctx context.Context, userID string) (*Quota, error) {
+	user, org, err := e.resolve(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.quotaLocked(userID, user, org), nil
+}
+
+// memberOrg is the organization a user transfers for, with its limits
+type memberOrg struct {
+	OrgID string
+	Limits
+}
+
+// resolve returns userID's limits and, for organization members, their
+// organization's (nil otherwise)
+func (e *Engine) resolve(ctx context.Context, userID string) (Limits, *memberOrg, error) {
+	user, err := e.userLimits(ctx, userID)
+	if err != nil {
+		return Limits{}, nil, err
+	}
+	terms := e.orgTerms(userID)
+	if terms == nil {
+		return user, nil, nil
+	}
+	org, err := e.orgLimits(ctx, terms)
+	if err != nil {
+		return Limits{}, nil, err
+	}
+	return user, &memberOrg{OrgID: terms.OrgID, Limits: org}, nil
+}
+
+// quotaLocked measures the resolved limits against current volume plus the
+// amounts reserved. Callers must hold e.mu.
+func (e *Engine) quotaLocked(userID string, user Limits, org *memberOrg) *Quota {
+	now := time.Now()
+	day, month := payments.DayStart(now), payments.MonthStart(now)
+	quota := &Quota{
+		DayResetsAt:   day.AddDate(0, 0, 1),
+		MonthResetsAt: month.AddDate(0, 1, 0),
+	}
+
+	held := e.reserved[ruleKey{ScopeUser, userID}]
+	quota.User = newUsage(ScopeUser, userID, user,
+		e.volumes.UserVolume(userID, day)+held, e.volumes.UserVolume(userID, month)+held)
+	if org != nil {
+		held := e.reserved[ruleKey{ScopeOrg, org.OrgID}]
+		usage := newUsage(ScopeOrg, org.OrgID, org.Limits,
+			e.volumes.OrgVolume(org.OrgID, day)+held, e.volumes.OrgVolume(org.OrgID, month)+held)
+		quota.Org = &usage
+	}
+
+	maxTxn := math.Inf(1)
+	for _, usage := range []*Usage{&quota.User, quota.Org} {
+		if usage != nil {
+			maxTxn = math.Min(maxTxn, usage.headroom())
+		}
+	}
+	if !math.IsInf(maxTxn, 1) {
+		quota.MaxTransaction = &maxTxn
+	}
+	return quota
+}
+
+// userLimits returns the user's rule, else the stored default, else the configured default
+func (e *Engine) userLimits(ctx context.Context, userID string) (Limits, error) {
+	for _, key := range []ruleKey{{ScopeUser, userID}, {ScopeDefault, ""}} {
+		rule, err := e.store.Get(ctx, key.scope, key.subjectID)
+		if err != nil {
+			return Limits{}, fmt.Errorf("failed to read %s limits: %w", key.scope, err)
+		}
+		if rule != nil {
+			return rule.Limits, nil
+		}
+	}
+	return e.defaults, nil
+}
+
+// orgLimits returns the organization's rule, tightened by its own monthly spending limit
+func (e *Engine) orgLimits(ctx context.Context, terms *payments.OrgTerms) (Limits, error) {
+	rule, err := e.store.Get(ctx, ScopeOrg, terms.OrgID)
+	if err != nil {
+		return Limits{}, fmt.Errorf("failed to read org limits: %w", err)
+	}
+	var limits Limits
+	if rule != nil {
+		limits = rule.Limits
+	}
+	if terms.MonthlyLimit > 0 && (limits.Monthly == 0 || terms.MonthlyLimit < limits.Monthly) {
+		limits.Monthly = terms.MonthlyLimit
+	}
+	return limits, nil
+}
+
+func (e *Engine) orgTerms(userID string) *payments.OrgTerms {
+	if e.orgs == nil {
+		return nil
+	}
+	return e.orgs.OrgTerms(userID)
+}
+
+func newUsage(scope Scope, subjectID string, limits Limits, dailyUsed, monthlyUsed float64) Usage {
+	usage := Usage{Scope: scope, SubjectID: subjectID, Limits: limits, DailyUsed: dailyUsed, MonthlyUsed: monthlyUsed}
+	if limits.Daily > 0 {
+		remaining := math.Max(limits.Daily-dailyUsed, 0)
+		usage.DailyRemaining = &remaining
+	}
+	if limits.Monthly > 0 {
+		remaining := math.Max(limits.Monthly-monthlyUsed, 0)
+		usage.MonthlyRemaining = &remaining
+	}
+	return usage
+}
+
+// headroom returns the largest transfer the usage allows (+Inf when unlimited)
+func (u *Usage) headroom() float64 {
+	largest := math.Inf(1)
+	if u.Limits.PerTransaction > 0 {
+		largest = u.Limits.PerTransaction
+	}
+	for _, remaining := range []*float64{u.DailyRemaining, u.MonthlyRemaining} {
+		if remaining != nil {
+			largest = math.Min(largest, *remaining)
+		}
+	}
+	return largest
+}
+
+func (u *Usage) check(amounts []float64) error {
+	who := "your"
+	if u.Scope == ScopeOrg {
+		who = "your organization's"
+	}
+	amount, largest := 0.0, 0.0
+	for _, a := range amounts {
+		amount += a
+		largest = math.Max(largest, a)
+	}
+	switch {
+	case u.Limits.PerTransaction > 0 && largest > u.Limits.PerTransaction:
+		return fmt.Errorf("%w: %s per-transaction limit is %.2f", ErrLimitExceeded, who, u.Limits.PerTransaction)
+	case u.Limits.Daily > 0 && u.DailyUsed+amount > u.Limits.Daily:
+		return fmt.Errorf("%w: %s daily limit is %.2f (%.2f used)", ErrLimitExceeded, who, u.Limits.Daily, u.DailyUsed)
+	case u.Limits.Monthly > 0 && u.MonthlyUsed+amount > u.Limits.Monthly:
+		return fmt.Errorf("%w: %s monthly limit is %.2f (%.2f used)", ErrLimitExceeded, who, u.Limits.Monthly, u.MonthlyUsed)
+	}
+	return nil
+}
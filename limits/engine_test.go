@@ -0,0 +1,94 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+type fakeVolumes struct {
+	user, org map[string]float64 // Volume this month, all of it today
+}
+
+func (f fakeVolumes) UserVolume(userID string, since time.Time) float64 { return f.user[userID] }
+func (f fakeVolumes) OrgVolume(orgID string, since time.Time) float64   { return f.org[orgID] }
+
+type fakeOrgs map[string]*payments.OrgTerms
+
+func (f fakeOrgs) OrgTerms(userID string) *payments.OrgTerms { return f[userID] }
+
+func TestEngineChecksUserAndOrgLimits(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	volumes := fakeVolumes{user: map[string]float64{"alice": 400, "bob": 50}, org: map[string]float64{"org-1": 900}}
+	engine := NewEngine(store, volumes)
+	engine.SetDefaults(Limits{PerTransaction: 1000})
+	engine.SetOrgResolver(fakeOrgs{"bob": {OrgID: "org-1", MonthlyLimit: 1000}})
+
+	if err := engine.Check(ctx, "carol", 1500); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("config default cap: err = %v, want ErrLimitExceeded", err)
+	}
+	store.Set(ctx, &Rule{Scope: ScopeDefault, Limits: Limits{Daily: 500}})
+	if err := engine.Check(ctx, "carol", 1500); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("stored default daily limit: err = %v, want ErrLimitExceeded", err)
+	}
+	if err := engine.Check(ctx, "alice", 100); err != nil {
+		t.Errorf("within daily limit: %v", err)
+	}
+	if err := engine.Check(ctx, "alice", 60, 60); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("batch over daily limit: err = %v, want ErrLimitExceeded", err)
+	}
+
+	// A user rule replaces the default
+	store.Set(ctx, &Rule{Scope: ScopeUser, SubjectID: "alice", Limits: Limits{PerTransaction: 50}})
+	if err := engine.Check(ctx, "alice", 40, 40, 40); err != nil {
+		t.Errorf("batch under per-transaction cap: %v", err)
+	}
+	if err := engine.Check(ctx, "alice", 60); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("over user cap: err = %v, want ErrLimitExceeded", err)
+	}
+
+	// The organization's own monthly limit applies to its members
+	if err := engine.Check(ctx, "bob", 150); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("over org monthly limit: err = %v, want ErrLimitExceeded", err)
+	}
+	quota, err := engine.Quota(ctx, "bob")
+	if err != nil {
+		t.Fatalf("Quota: %v", err)
+	}
+	if quota.Org == nil || *quota.Org.MonthlyRemaining != 100 || *quota.User.DailyRemaining != 450 {
+		t.Fatalf("quota = %+v, want 100 left for the org and 450 today for bob", quota)
+	}
+	if *quota.MaxTransaction != 100 {
+		t.Errorf("max transaction = %v, want 100", *quota.MaxTransaction)
+	}
+}
+
+func TestReserveHoldsAmountsUntilReleased(t *testing.T) {
+	ctx := context.Background()
+	engine := NewEngine(NewMemoryStore(), fakeVolumes{})
+	engine.SetDefaults(Limits{Daily: 100})
+
+	release, err := engine.Reserve(ctx, "alice", 60)
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	// A concurrent transfer sees the reservation before the first is stored
+	if _, err := engine.Reserve(ctx, "alice", 60); !errors.Is(err, ErrLimitExceeded) {
+		t.Errorf("second reservation: err = %v, want ErrLimitExceeded", err)
+	}
+	if quota, _ := engine.Quota(ctx, "alice"); *quota.User.DailyRemaining != 40 {
+		t.Errorf("remaining = %v, want 40 while reserved", *quota.User.DailyRemaining)
+	}
+	release()
+	release() // Releasing twice is harmless
+	if err := engine.Check(ctx, "alice", 60); err != nil {
+		t.Errorf("after release: %v", err)
+	}
+	if len(engine.reserved) != 0 {
+		t.Errorf("reserved = %v, want nothing held", engine.reserved)
+	}
+}
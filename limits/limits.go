@@ -0,0 +1,155 @@
+// Package limits enforces spending limits: a per-transaction cap and
+// maximum daily and monthly transfer volume, configured per user and per
+// organization.
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scope is what a limit rule applies to
+type Scope string
+
+const (
+	ScopeDefault Scope = "default" // Users without their own rule
+	ScopeUser    Scope = "user"
+	ScopeOrg     Scope = "org" // Shared by every member of the organization
+)
+
+var (
+	ErrLimitExceeded = errors.New("spending limit exceeded")
+	ErrInvalidScope  = errors.New("scope must be default, user or org")
+	ErrInvalidLimits = errors.New("limits must not be negative")
+)
+
+// Limits caps transfer volume, measured in payments.VolumeCurrency. Zero
+// means unlimited.
+type Limits struct {
+	PerTransaction float64 `json:"per_transaction"` // Largest single transfer
+	Daily          float64 `json:"daily"`           // Volume per UTC day
+	Monthly        float64 `json:"monthly"`         // Volume per UTC calendar month
+}
+
+// Validate checks that no limit is negative
+func (l Limits) Validate() error {
+	if l.PerTransaction < 0 || l.Daily < 0 || l.Monthly < 0 {
+		return ErrInvalidLimits
+	}
+	return nil
+}
+
+// Valid reports whether s is a known scope
+func (s Scope) Valid() bool {
+	return s == ScopeDefault || s == ScopeUser || s == ScopeOrg
+}
+
+// Rule is the limits configured for one user or organization, or the
+// default for users
+type Rule struct {
+	Scope     Scope  `json:"scope"`
+	SubjectID string `json:"subject_id,omitempty"` // User or organization ID; empty for the default
+	Limits
+	UpdatedBy string    `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the rule's scope, subject and limits
+func (r *Rule) Validate() error {
+	if !r.Scope.Valid() {
+		return ErrInvalidScope
+	}
+	if (r.Scope == ScopeDefault) != (r.SubjectID == "") {
+		return fmt.Errorf("subject ID is required for %s limits and not allowed for the default", r.Scope)
+	}
+	return r.Limits.Validate()
+}
+
+// Store persists limit rules. Implemented by MemoryStore and the
+// Postgres-backed store in storage/postgres.
+type Store interface {
+	// Get returns the rule for a subject, or nil if none is configured
+	Get(ctx context.Context, scope Scope, subjectID string) (*Rule, error)
+	// List returns every configured rule
+	List(ctx context.Context) ([]*Rule, error)
+	// Set creates or replaces a rule
+	Set(ctx context.Context, rule *Rule) error
+	// Delete removes a rule; deleting a missing rule is not an error
+	Delete(ctx context.Context, scope Scope, subjectID string) error
+}
+
+type ruleKey struct {
+	scope     Scope
+	subjectID string
+}
+
+// MemoryStore is an in-memory Store
+type MemoryStore struct {
+	mu    sync.RWMutex
+	rules map[ruleKey]*Rule
+}
+
+// NewMemoryStore creates an empty rule store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{rules: make(map[ruleKey]*Rule)}
+}
+
+// Get returns the rule for a subject, or nil if none is configured
+func (m *MemoryStore) Get(ctx context.Context, scope Scope, subjectID string) (*Rule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	rule, ok := m.rules[ruleKey{scope, subjectID}]
+	if !ok {
+		return nil, nil
+	}
+	cp := *rule
+	return &cp, nil
+}
+
+// List returns every configured rule, by scope and subject
+func (m *MemoryStore) List(ctx context.Context) ([]*Rule, error) {
+	m.mu.RLock()
+	rules := make([]*Rule, 0, len(m.rules))
+	for _, rule := range m.rules {
+		cp := *rule
+		rules = append(rules, &cp)
+	}
+	m.mu.RUnlock()
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Scope != rules[j].Scope {
+			return rules[i].Scope < rules[j].Scope
+		}
+		return rules[i].SubjectID < rules[j].SubjectID
+	})
+	return rules, nil
+}
+
+// Set creates or replaces a rule
+func (m *MemoryStore) Set(ctx context.Context, rule *Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	if rule.UpdatedAt.IsZero() {
+		rule.UpdatedAt = time.Now().UTC()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *rule
+	m.rules[ruleKey{rule.Scope, rule.SubjectID}] = &cp
+	return nil
+}
+
+// Delete removes a rule
+func (m *MemoryStore) Delete(ctx context.Context, scope Scope, subjectID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.rules, ruleKey{scope, subjectID})
+	return nil
+}
+
+// Compile-time interface check
+var _ Store = (*MemoryStore)(nil)
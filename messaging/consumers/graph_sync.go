@@ -5,6 +5,7 @@ package consumers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"sync"
@@ -15,16 +16,26 @@ import (
 	"github.com/plm/predictive-liquidity-mesh/storage/neo4j"
 )
 
-// GraphSyncConsumer synchronizes liquidity updates to Neo4j
+// GraphSyncConsumerName is the durable name of the graph sync consumer, and
+// the consumer its dead letters are filed under
+const GraphSyncConsumerName = "graph-sync-consumer"
+
+// errMalformed marks updates that can never be applied, which are
+// dead-lettered without waiting for redeliveries
+var errMalformed = errors.New("malformed liquidity update")
+
+// GraphSyncConsumer synchronizes liquidity updates to Neo4j. Updates that
+// still fail after MaxDeliver deliveries are moved to the dead letter stream.
 type GraphSyncConsumer struct {
-	nats      *natsClient.Client
-	neo4j     *neo4j.Client
-	consumer  jetstream.Consumer
-	ctx       context.Context
-	cancel    context.CancelFunc
-	wg        sync.WaitGroup
-	workers   int
-	batchSize int
+	nats       *natsClient.Client
+	neo4j      *neo4j.Client
+	consumer   jetstream.Consumer
+	ctx        context.Context
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+	workers    int
+	batchSize  int
+	maxDeliver int
 }
 
 // GraphSyncConfig configures the graph sync consumer
@@ -32,6 +43,7 @@ type GraphSyncConfig struct {
 	Workers      int           // Number of parallel workers
 	BatchSize    int           // Messages per batch
 	PollInterval time.Duration // How often to poll for messages
+	MaxDeliver   int           // Deliveries before a failing message is dead-lettered
 }
 
 // DefaultGraphSyncConfig returns sensible defaults
@@ -40,6 +52,7 @@ func DefaultGraphSyncConfig() *GraphSyncConfig {
 		Workers:      5,
 		BatchSize:    100,
 		PollInterval: 100 * time.Millisecond,
+		MaxDeliver:   3,
 	}
 }
 
@@ -57,10 +70,13 @@ func NewGraphSyncConsumer(
 	// Create work queue consumer for liquidity updates
 	consumerCfg := natsClient.DefaultConsumerConfig(
 		natsClient.LiquidityUpdatesStream,
-		"graph-sync-consumer",
+		GraphSyncConsumerName,
 	)
 	consumerCfg.FilterSubject = "liquidity.>"
 	consumerCfg.MaxAckPending = cfg.BatchSize * cfg.Workers
+	// One delivery beyond our own limit, so a message whose dead-lettering
+	// failed is seen again rather than stranded in the work queue
+	consumerCfg.MaxDeliver = cfg.MaxDeliver + 1
 
 	consumer, err := nats.CreateWorkQueueConsumer(ctx, consumerCfg)
 	if err != nil {
@@ -70,13 +86,14 @@ func NewGraphSyncConsumer(
 	consumerCtx, cancel := context.WithCancel(ctx)
 
 	return &GraphSyncConsumer{
-		nats:      nats,
-		neo4j:     neo4j,
-		consumer:  consumer,
-		ctx:       consumerCtx,
-		cancel:    cancel,
-		workers:   cfg.Workers,
-		batchSize: cfg.BatchSize,
+		nats:       nats,
+		neo4j:      neo4j,
+		consumer:   consumer,
+		ctx:        consumerCtx,
+		cancel:     cancel,
+		workers:    cfg.Workers,
+		batchSize:  cfg.BatchSize,
+		maxDeliver: cfg.MaxDeliver,
 	}, nil
 }
 
@@ -124,9 +141,7 @@ func (c *GraphSyncConsumer) worker(id int) {
 
 			for msg := range msgs.Messages() {
 				if err := c.processMessage(msg); err != nil {
-					log.Printf("Worker %d: Failed to process message: %v", id, err)
-					// NAK for redelivery
-					msg.Nak()
+					c.fail(id, msg, err)
 				} else {
 					// ACK on success
 					msg.Ack()
@@ -140,11 +155,33 @@ func (c *GraphSyncConsumer) worker(id int) {
 	}
 }
 
+// fail NAKs a message for redelivery, or moves it to the dead letter stream
+// once it has been delivered MaxDeliver times or is malformed
+func (c *GraphSyncConsumer) fail(id int, msg jetstream.Msg, err error) {
+	var delivered uint64
+	if meta, metaErr := msg.Metadata(); metaErr == nil {
+		delivered = meta.NumDelivered
+	}
+	if !errors.Is(err, errMalformed) && delivered < uint64(c.maxDeliver) {
+		log.Printf("Worker %d: Failed to process message (delivery %d/%d): %v", id, delivered, c.maxDeliver, err)
+		// NAK for redelivery
+		msg.Nak()
+		return
+	}
+
+	if dlqErr := c.nats.DeadLetter(c.ctx, msg, GraphSyncConsumerName, err); dlqErr != nil {
+		log.Printf("Worker %d: Failed to dead-letter message: %v (processing error: %v)", id, dlqErr, err)
+		msg.Nak()
+		return
+	}
+	log.Printf("Worker %d: Moved message on %s to dead letter queue after %d deliveries: %v", id, msg.Subject(), delivered, err)
+}
+
 // processMessage processes a single liquidity update message
 func (c *GraphSyncConsumer) processMessage(msg jetstream.Msg) error {
 	var event natsClient.LiquidityUpdateEvent
 	if err := json.Unmarshal(msg.Data(), &event); err != nil {
-		return fmt.Errorf("failed to unmarshal event: %w", err)
+		return fmt.Errorf("%w: failed to unmarshal event: %v", errMalformed, err)
 	}
 
 	start := time.Now()
@@ -188,7 +225,7 @@ func (c *GraphSyncConsumer) processMessage(msg jetstream.Msg) error {
 // updateLiquidityVolume updates an edge's liquidity volume
 func (c *GraphSyncConsumer) updateLiquidityVolume(event *natsClient.LiquidityUpdateEvent) error {
 	if event.SourceID == "" || event.TargetID == "" {
-		return fmt.Errorf("missing source or target ID for volume update")
+		return fmt.Errorf("%w: missing source or target ID for volume update", errMalformed)
 	}
 
 	return c.neo4j.UpdateEdge(c.ctx, event.SourceID, event.TargetID, map[string]interface{}{
@@ -200,7 +237,7 @@ func (c *GraphSyncConsumer) updateLiquidityVolume(event *natsClient.LiquidityUpd
 // updateBaseFee updates an edge's base fee
 func (c *GraphSyncConsumer) updateBaseFee(event *natsClient.LiquidityUpdateEvent) error {
 	if event.SourceID == "" || event.TargetID == "" {
-		return fmt.Errorf("missing source or target ID for fee update")
+		return fmt.Errorf("%w: missing source or target ID for fee update", errMalformed)
 	}
 
 	return c.neo4j.UpdateEdge(c.ctx, event.SourceID, event.TargetID, map[string]interface{}{
@@ -218,7 +255,7 @@ func (c *GraphSyncConsumer) updateNodeStatus(event *natsClient.LiquidityUpdateEv
 // updateLatency updates an edge's latency
 func (c *GraphSyncConsumer) updateLatency(event *natsClient.LiquidityUpdateEvent) error {
 	if event.SourceID == "" || event.TargetID == "" {
-		return fmt.Errorf("missing source or target ID for latency update")
+		return fmt.Errorf("%w: missing source or target ID for latency update", errMalformed)
 	}
 
 	return c.neo4j.UpdateEdge(c.ctx, event.SourceID, event.TargetID, map[string]interface{}{
@@ -0,0 +1,75 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/nats-io/nats.go/jetstream"
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+	"github.com/plm/predictive-liquidity-mesh/websocket"
+)
+
+// MsgTypeSettlementEvent is the WebSocket message type settlement events are broadcast as
+const MsgTypeSettlementEvent websocket.MessageType = "SETTLEMENT_EVENT"
+
+// SettlementEventForwarder broadcasts settlement lifecycle events to this
+// server's WebSocket clients. Every replica reads the stream with its own
+// ordered consumer, so clients see every event whichever replica processed
+// the payment.
+type SettlementEventForwarder struct {
+	hub        *websocket.Hub
+	consumer   jetstream.Consumer
+	consumeCtx jetstream.ConsumeContext
+}
+
+// NewSettlementEventForwarder creates a forwarder for events published from now on
+func NewSettlementEventForwarder(ctx context.Context, nats *natsClient.Client, hub *websocket.Hub) (*SettlementEventForwarder, error) {
+	consumer, err := nats.JetStream().OrderedConsumer(ctx, natsClient.SettlementEventsStream, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{natsClient.SettlementEventsSubject + ".>"},
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	return &SettlementEventForwarder{
+		hub:      hub,
+		consumer: consumer,
+	}, nil
+}
+
+// Start begins forwarding events to the hub
+func (f *SettlementEventForwarder) Start() error {
+	consumeCtx, err := f.consumer.Consume(func(msg jetstream.Msg) {
+		f.forward(msg.Data())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume settlement events: %w", err)
+	}
+	f.consumeCtx = consumeCtx
+	log.Println("Forwarding settlement events to WebSocket clients")
+	return nil
+}
+
+// Stop stops forwarding events
+func (f *SettlementEventForwarder) Stop() {
+	if f.consumeCtx != nil {
+		f.consumeCtx.Stop()
+	}
+}
+
+// forward broadcasts one event to its user's clients and to admins
+func (f *SettlementEventForwarder) forward(data []byte) {
+	var event natsClient.SettlementEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		log.Printf("Failed to unmarshal settlement event: %v", err)
+		return
+	}
+
+	f.hub.BroadcastToUser(event.UserID, map[string]interface{}{
+		"type": string(MsgTypeSettlementEvent),
+		"data": &event,
+	})
+}
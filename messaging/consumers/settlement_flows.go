@@ -0,0 +1,73 @@
+package consumers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// FlowObserver receives the path and amount of each completed settlement
+type FlowObserver func(path []string, amount float64, at time.Time)
+
+// SettlementFlowFeed passes completed settlements to an observer such as the
+// entropy updater. Like SettlementEventForwarder, every replica reads the
+// stream with its own ordered consumer, so each sees the whole mesh's flows.
+type SettlementFlowFeed struct {
+	observe    FlowObserver
+	consumer   jetstream.Consumer
+	consumeCtx jetstream.ConsumeContext
+}
+
+// NewSettlementFlowFeed creates a feed for settlements completed from now on
+func NewSettlementFlowFeed(ctx context.Context, nats *natsClient.Client, observe FlowObserver) (*SettlementFlowFeed, error) {
+	consumer, err := nats.JetStream().OrderedConsumer(ctx, natsClient.SettlementEventsStream, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{natsClient.SettlementEventsSubject + "." + natsClient.SettlementCompleted},
+		DeliverPolicy:  jetstream.DeliverNewPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+
+	return &SettlementFlowFeed{
+		observe:  observe,
+		consumer: consumer,
+	}, nil
+}
+
+// Start begins passing completed settlements to the observer
+func (f *SettlementFlowFeed) Start() error {
+	consumeCtx, err := f.consumer.Consume(func(msg jetstream.Msg) {
+		f.handle(msg.Data())
+	})
+	if err != nil {
+		return fmt.Errorf("failed to consume settlement events: %w", err)
+	}
+	f.consumeCtx = consumeCtx
+	log.Println("Feeding completed settlements to the entropy updater")
+	return nil
+}
+
+// Stop stops the feed
+func (f *SettlementFlowFeed) Stop() {
+	if f.consumeCtx != nil {
+		f.consumeCtx.Stop()
+	}
+}
+
+// handle passes one completed settlement to the observer
+func (f *SettlementFlowFeed) handle(data []byte) {
+	var event natsClient.SettlementEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		log.Printf("Failed to unmarshal settlement event: %v", err)
+		return
+	}
+	if event.EventType != natsClient.SettlementCompleted {
+		return
+	}
+	f.observe(event.Path, event.Amount, event.Timestamp)
+}
@@ -6,11 +6,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
 )
 
 // StreamName constants
@@ -26,16 +29,24 @@ type Config struct {
 	// Connection URLs (comma-separated for cluster)
 	URLs string
 
-	// Authentication
-	Token    string
-	User     string
-	Password string
+	// Authentication (at most one of token, user/password, NKey and credentials)
+	Token     string
+	User      string
+	Password  string
+	NKeyFile  string // NKey seed file
+	CredsFile string // Decentralized auth credentials (user JWT and NKey seed)
 
-	// TLS
+	// TLS: CAFile verifies the server, CertFile/KeyFile authenticate this
+	// client; setting any of them requires a TLS connection
 	CertFile string
 	KeyFile  string
 	CAFile   string
 
+	// Name identifies the connection in the server's monitoring; Labels are
+	// appended to it as {key=value,...}
+	Name   string
+	Labels map[string]string
+
 	// Reconnection
 	MaxReconnects   int
 	ReconnectWait   time.Duration
@@ -46,6 +57,7 @@ type Config struct {
 func DefaultConfig() *Config {
 	return &Config{
 		URLs:            "nats://localhost:4222",
+		Name:            "plm-server",
 		MaxReconnects:   -1, // Unlimited
 		ReconnectWait:   2 * time.Second,
 		ReconnectJitter: 500 * time.Millisecond,
@@ -66,7 +78,11 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 		cfg = DefaultConfig()
 	}
 
-	opts := []nats.Option{
+	opts, err := clientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts,
 		nats.MaxReconnects(cfg.MaxReconnects),
 		nats.ReconnectWait(cfg.ReconnectWait),
 		nats.ReconnectJitter(cfg.ReconnectJitter, cfg.ReconnectJitter*2),
@@ -78,14 +94,7 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 		nats.ReconnectHandler(func(nc *nats.Conn) {
 			fmt.Printf("NATS reconnected to %s\n", nc.ConnectedUrl())
 		}),
-	}
-
-	// Authentication
-	if cfg.Token != "" {
-		opts = append(opts, nats.Token(cfg.Token))
-	} else if cfg.User != "" && cfg.Password != "" {
-		opts = append(opts, nats.UserInfo(cfg.User, cfg.Password))
-	}
+	)
 
 	// Connect
 	nc, err := nats.Connect(cfg.URLs, opts...)
@@ -107,6 +116,64 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 	}, nil
 }
 
+// clientOptions returns the connection name, authentication and TLS options of cfg
+func clientOptions(cfg *Config) ([]nats.Option, error) {
+	var opts []nats.Option
+	if name := connectionName(cfg); name != "" {
+		opts = append(opts, nats.Name(name))
+	}
+
+	// Authentication
+	methods := 0
+	for _, set := range []bool{cfg.Token != "", cfg.User != "", cfg.NKeyFile != "", cfg.CredsFile != ""} {
+		if set {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return nil, fmt.Errorf("NATS authentication: set only one of token, user/password, nkey file and credentials file")
+	}
+	switch {
+	case cfg.CredsFile != "":
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	case cfg.NKeyFile != "":
+		opt, err := nats.NkeyOptionFromSeed(cfg.NKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load NATS nkey: %w", err)
+		}
+		opts = append(opts, opt)
+	case cfg.Token != "":
+		opts = append(opts, nats.Token(cfg.Token))
+	case cfg.User != "":
+		opts = append(opts, nats.UserInfo(cfg.User, cfg.Password))
+	}
+
+	// TLS
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, fmt.Errorf("NATS TLS: cert file and key file must be set together")
+	}
+	if cfg.CAFile != "" {
+		opts = append(opts, nats.RootCAs(cfg.CAFile))
+	}
+	if cfg.CertFile != "" {
+		opts = append(opts, nats.ClientCert(cfg.CertFile, cfg.KeyFile))
+	}
+	return opts, nil
+}
+
+// connectionName returns cfg.Name with its labels appended, sorted by key
+func connectionName(cfg *Config) string {
+	if len(cfg.Labels) == 0 {
+		return cfg.Name
+	}
+	labels := make([]string, 0, len(cfg.Labels))
+	for k, v := range cfg.Labels {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return cfg.Name + "{" + strings.Join(labels, ",") + "}"
+}
+
 // Close closes the NATS connection
 func (c *Client) Close() {
 	c.mu.Lock()
@@ -162,7 +229,7 @@ func (c *Client) SetupStreams(ctx context.Context) error {
 		return fmt.Errorf("failed to create settlement stream: %w", err)
 	}
 
-	return nil
+	return c.setupDeadLetterStream(ctx)
 }
 
 // LiquidityUpdateEvent represents a liquidity change event
@@ -189,20 +256,32 @@ func (c *Client) PublishLiquidityUpdate(ctx context.Context, event *LiquidityUpd
 	subject := fmt.Sprintf("liquidity.updates.%s", event.NodeID)
 	_, err = c.js.Publish(ctx, subject, data)
 	if err != nil {
+		metrics.NATSPublishErrors.Inc("liquidity_update")
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
 	return nil
 }
 
+// Settlement lifecycle event types, published as settlement.events.<type>
+const (
+	SettlementCreated     = "created"
+	SettlementHopComplete = "hop_complete"
+	SettlementCompleted   = "completed"
+	SettlementFailed      = "failed"
+	SettlementRefunded    = "refunded"
+)
+
 // SettlementEvent represents a settlement transaction event
 type SettlementEvent struct {
 	EventID      string    `json:"event_id"`
-	RequestID    string    `json:"request_id"`
-	EventType    string    `json:"event_type"` // "initiated", "hop_complete", "completed", "failed", "rerouted"
+	RequestID    string    `json:"request_id"` // Transaction ID
+	EventType    string    `json:"event_type"` // One of the Settlement* event types
+	UserID       string    `json:"user_id,omitempty"`
 	SourceID     string    `json:"source_id"`
 	TargetID     string    `json:"target_id"`
-	Amount       int64     `json:"amount"`
+	Amount       float64   `json:"amount"`
+	Currency     string    `json:"currency,omitempty"`
 	Path         []string  `json:"path"`
 	CurrentHop   int       `json:"current_hop"`
 	Status       string    `json:"status"`
@@ -220,6 +299,7 @@ func (c *Client) PublishSettlementEvent(ctx context.Context, event *SettlementEv
 	subject := fmt.Sprintf("settlement.events.%s", event.EventType)
 	_, err = c.js.Publish(ctx, subject, data)
 	if err != nil {
+		metrics.NATSPublishErrors.Inc("settlement_event")
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
@@ -268,3 +348,17 @@ func (c *Client) CreateWorkQueueConsumer(ctx context.Context, cfg *ConsumerConfi
 
 	return consumer, nil
 }
+
+// ConsumerLag returns the messages a durable consumer has yet to process:
+// those not yet delivered plus those delivered but not acknowledged
+func (c *Client) ConsumerLag(ctx context.Context, stream, consumer string) (uint64, error) {
+	cons, err := c.js.Consumer(ctx, stream, consumer)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open consumer %s: %w", consumer, err)
+	}
+	info, err := cons.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read consumer %s: %w", consumer, err)
+	}
+	return info.NumPending + uint64(info.NumAckPending), nil
+}
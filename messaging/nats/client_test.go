@@ -0,0 +1,61 @@
+package nats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+)
+
+// applyOptions builds cfg's client options and applies them
+func applyOptions(t *testing.T, cfg *Config) (*nats.Options, error) {
+	t.Helper()
+	opts, err := clientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	o := nats.GetDefaultOptions()
+	for _, opt := range opts {
+		if err := opt(&o); err != nil {
+			return nil, err
+		}
+	}
+	return &o, nil
+}
+
+func TestClientOptions(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Labels = map[string]string{"region": "eu", "instance": "plm-eu"}
+	cfg.User, cfg.Password = "plm", "secret"
+	o, err := applyOptions(t, cfg)
+	if err != nil {
+		t.Fatalf("clientOptions: %v", err)
+	}
+	if o.Name != "plm-server{instance=plm-eu,region=eu}" {
+		t.Errorf("unexpected connection name %q", o.Name)
+	}
+	if o.User != "plm" || o.Password != "secret" || o.Secure {
+		t.Errorf("unexpected auth or TLS: user=%q secure=%v", o.User, o.Secure)
+	}
+}
+
+func TestClientOptionsRejectsInvalidSettings(t *testing.T) {
+	dir := t.TempDir()
+	seed := filepath.Join(dir, "plm.nk")
+	if err := os.WriteFile(seed, []byte("not a seed"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, cfg := range map[string]*Config{
+		"two auth methods": {Token: "t", CredsFile: "plm.creds"},
+		"cert without key": {CertFile: "client.pem"},
+		"invalid nkey":     {NKeyFile: seed},
+		"missing creds":    {CredsFile: filepath.Join(dir, "missing.creds")},
+		"missing ca file":  {CAFile: filepath.Join(dir, "missing.pem")},
+	} {
+		if _, err := applyOptions(t, cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+// Dead letters are published as deadletter.<consumer>
+const (
+	DeadLetterStream  = "DEAD_LETTERS"
+	DeadLetterSubject = "deadletter"
+)
+
+// Headers recording where a dead letter came from and why
+const (
+	headerDLQSubject    = "Plm-Dlq-Subject"
+	headerDLQStream     = "Plm-Dlq-Stream"
+	headerDLQConsumer   = "Plm-Dlq-Consumer"
+	headerDLQDeliveries = "Plm-Dlq-Deliveries"
+	headerDLQError      = "Plm-Dlq-Error"
+	headerDLQFailedAt   = "Plm-Dlq-Failed-At"
+)
+
+// ErrDeadLetterNotFound is returned for a dead letter that doesn't exist (or
+// was already replayed or discarded)
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// DeadLetter is a message a consumer gave up on
+type DeadLetter struct {
+	ID         uint64    `json:"id"`       // Sequence in the dead letter stream
+	Subject    string    `json:"subject"`  // Subject the message was originally published on
+	Stream     string    `json:"stream"`   // Stream it was consumed from
+	Consumer   string    `json:"consumer"` // Consumer that gave up on it
+	Deliveries uint64    `json:"deliveries"`
+	Error      string    `json:"error"` // Last processing error
+	Data       string    `json:"data"`  // Original payload
+	FailedAt   time.Time `json:"failed_at"`
+}
+
+// setupDeadLetterStream creates the stream poison messages are moved to
+func (c *Client) setupDeadLetterStream(ctx context.Context) error {
+	_, err := c.js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:        DeadLetterStream,
+		Description: "Messages consumers failed to process after their maximum deliveries",
+		Subjects:    []string{DeadLetterSubject + ".>"},
+		Retention:   jetstream.LimitsPolicy, // Kept until replayed, discarded or expired
+		MaxAge:      14 * 24 * time.Hour,
+		MaxBytes:    256 * 1024 * 1024, // 256MB
+		Discard:     jetstream.DiscardOld,
+		Replicas:    1,
+		Storage:     jetstream.FileStorage,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter stream: %w", err)
+	}
+	return nil
+}
+
+// DeadLetter moves msg to the dead letter stream and terminates it so it is
+// not redelivered. cause is the last processing error.
+func (c *Client) DeadLetter(ctx context.Context, msg jetstream.Msg, consumer string, cause error) error {
+	header := nats.Header{}
+	header.Set(headerDLQSubject, msg.Subject())
+	header.Set(headerDLQConsumer, consumer)
+	header.Set(headerDLQFailedAt, time.Now().UTC().Format(time.RFC3339Nano))
+	if cause != nil {
+		header.Set(headerDLQError, cause.Error())
+	}
+	if meta, err := msg.Metadata(); err == nil {
+		header.Set(headerDLQStream, meta.Stream)
+		header.Set(headerDLQDeliveries, strconv.FormatUint(meta.NumDelivered, 10))
+	}
+
+	if _, err := c.js.PublishMsg(ctx, &nats.Msg{
+		Subject: DeadLetterSubject + "." + consumer,
+		Header:  header,
+		Data:    msg.Data(),
+	}); err != nil {
+		metrics.NATSPublishErrors.Inc("dead_letter")
+		return fmt.Errorf("failed to publish dead letter: %w", err)
+	}
+	metrics.NATSDeadLetters.Inc(consumer)
+
+	if err := msg.Term(); err != nil {
+		return fmt.Errorf("failed to terminate dead-lettered message: %w", err)
+	}
+	return nil
+}
+
+// DeadLetters returns up to limit dead letters, oldest first, optionally only
+// those of one consumer
+func (c *Client) DeadLetters(ctx context.Context, consumer string, limit int) ([]*DeadLetter, error) {
+	stream, err := c.js.Stream(ctx, DeadLetterStream)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dead letter stream: %w", err)
+	}
+	subject := DeadLetterSubject + ".>"
+	if consumer != "" {
+		subject = DeadLetterSubject + "." + consumer
+	}
+
+	letters := make([]*DeadLetter, 0)
+	for seq := uint64(1); len(letters) < limit; seq++ {
+		// Next message on subject at or after seq
+		raw, err := stream.GetMsg(ctx, seq, jetstream.WithGetMsgSubject(subject))
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dead letter: %w", err)
+		}
+		letters = append(letters, deadLetterFromMsg(raw))
+		seq = raw.Sequence
+	}
+	return letters, nil
+}
+
+// DeadLetterDepth returns the number of dead letters waiting
+func (c *Client) DeadLetterDepth(ctx context.Context) (uint64, error) {
+	stream, err := c.js.Stream(ctx, DeadLetterStream)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open dead letter stream: %w", err)
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read dead letter stream: %w", err)
+	}
+	return info.State.Msgs, nil
+}
+
+// ReplayDeadLetter republishes a dead letter on its original subject, for its
+// consumer to process again, and removes it from the dead letter stream
+func (c *Client) ReplayDeadLetter(ctx context.Context, id uint64) (*DeadLetter, error) {
+	stream, letter, err := c.deadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.js.Publish(ctx, letter.Subject, []byte(letter.Data)); err != nil {
+		return nil, fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+	if err := stream.DeleteMsg(ctx, id); err != nil {
+		return nil, fmt.Errorf("replayed dead letter %d but failed to remove it: %w", id, err)
+	}
+	return letter, nil
+}
+
+// DiscardDeadLetter removes a dead letter without replaying it
+func (c *Client) DiscardDeadLetter(ctx context.Context, id uint64) (*DeadLetter, error) {
+	stream, letter, err := c.deadLetter(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.DeleteMsg(ctx, id); err != nil {
+		return nil, fmt.Errorf("failed to discard dead letter: %w", err)
+	}
+	return letter, nil
+}
+
+// deadLetter reads one dead letter
+func (c *Client) deadLetter(ctx context.Context, id uint64) (jetstream.Stream, *DeadLetter, error) {
+	stream, err := c.js.Stream(ctx, DeadLetterStream)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open dead letter stream: %w", err)
+	}
+	raw, err := stream.GetMsg(ctx, id)
+	if errors.Is(err, jetstream.ErrMsgNotFound) {
+		return nil, nil, ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read dead letter: %w", err)
+	}
+	letter := deadLetterFromMsg(raw)
+	if letter.Subject == "" {
+		return nil, nil, fmt.Errorf("dead letter %d has no original subject", id)
+	}
+	return stream, letter, nil
+}
+
+func deadLetterFromMsg(raw *jetstream.RawStreamMsg) *DeadLetter {
+	letter := &DeadLetter{
+		ID:       raw.Sequence,
+		Subject:  raw.Header.Get(headerDLQSubject),
+		Stream:   raw.Header.Get(headerDLQStream),
+		Consumer: raw.Header.Get(headerDLQConsumer),
+		Error:    raw.Header.Get(headerDLQError),
+		Data:     string(raw.Data),
+		FailedAt: raw.Time,
+	}
+	letter.Deliveries, _ = strconv.ParseUint(raw.Header.Get(headerDLQDeliveries), 10, 64)
+	if t, err := time.Parse(time.RFC3339Nano, raw.Header.Get(headerDLQFailedAt)); err == nil {
+		letter.FailedAt = t
+	}
+	return letter
+}
@@ -0,0 +1,78 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - PAYMENT TRANSACTIONS
+-- Migration: 003_transactions.sql
+-- Description: Durable storage for payments.TransactionStore
+-- ============================================================================
+
+-- ============================================================================
+-- TABLE: transactions
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS transactions (
+    id              TEXT PRIMARY KEY,                 -- txn_<hex>
+    user_id         TEXT NOT NULL,
+    
+    -- Amounts
+    amount          DOUBLE PRECISION NOT NULL CHECK (amount > 0),
+    currency        VARCHAR(3),
+    target_currency VARCHAR(3),
+    
+    -- Routing
+    route           JSONB NOT NULL DEFAULT '[]'::JSONB, -- Country codes in order
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending',
+    
+    -- Fee breakdown
+    base_fee        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    hop_fees        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    halt_fines      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    total_fees      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    final_amount    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    admin_profit    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    
+    -- Mesh simulation results
+    hop_results      JSONB NOT NULL DEFAULT '[]'::JSONB,
+    hops_completed   INT NOT NULL DEFAULT 0,
+    failed_at        TEXT,
+    candidate_routes JSONB NOT NULL DEFAULT '[]'::JSONB,
+    attempts         JSONB NOT NULL DEFAULT '[]'::JSONB,
+    
+    -- Payment details
+    card_last4      VARCHAR(4),
+    payment_method  TEXT,
+    
+    -- Timestamps
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    processed_at    TIMESTAMPTZ,
+    completed_at    TIMESTAMPTZ,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- ============================================================================
+-- INDEXES
+-- ============================================================================
+CREATE INDEX IF NOT EXISTS idx_transactions_user_id ON transactions(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_transactions_status ON transactions(status);
+CREATE INDEX IF NOT EXISTS idx_transactions_created_at ON transactions(created_at DESC);
+
+-- ============================================================================
+-- TRIGGER: Updated timestamp
+-- ============================================================================
+CREATE OR REPLACE FUNCTION transactions_updated_at()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.updated_at = NOW();
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_transactions_updated_at ON transactions;
+CREATE TRIGGER trg_transactions_updated_at
+    BEFORE UPDATE ON transactions
+    FOR EACH ROW
+    EXECUTE FUNCTION transactions_updated_at();
+
+-- ============================================================================
+-- COMMENTS
+-- ============================================================================
+COMMENT ON TABLE transactions IS 'Payment transactions routed through the country mesh';
+COMMENT ON COLUMN transactions.route IS 'JSONB array of country codes for the current route';
+COMMENT ON COLUMN transactions.attempts IS 'JSONB array of routing attempts (anti-fragility retries)';
@@ -0,0 +1,11 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - PAYMENT BATCHES
+-- Migration: 004_payment_batches.sql
+-- Description: Groups transactions submitted through the batch payment API
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS batch_id TEXT; -- batch_<hex>, NULL for single payments
+
+CREATE INDEX IF NOT EXISTS idx_transactions_batch_id ON transactions(batch_id) WHERE batch_id IS NOT NULL;
+
+COMMENT ON COLUMN transactions.batch_id IS 'Batch the transaction was submitted in (POST /api/v1/payments/batch)';
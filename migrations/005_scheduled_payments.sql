@@ -0,0 +1,45 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - SCHEDULED PAYMENTS
+-- Migration: 005_scheduled_payments.sql
+-- Description: Durable storage for payments/scheduler
+-- ============================================================================
+
+-- ============================================================================
+-- TABLE: scheduled_payments
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS scheduled_payments (
+    id              TEXT PRIMARY KEY,                 -- sched_<hex>
+    user_id         TEXT NOT NULL,
+
+    -- Transfer
+    source          VARCHAR(3) NOT NULL,
+    target          VARCHAR(3) NOT NULL,
+    amount          DOUBLE PRECISION NOT NULL CHECK (amount > 0),
+    currency        VARCHAR(3),
+    target_currency VARCHAR(3),
+
+    -- Timing
+    frequency       VARCHAR(10) NOT NULL,             -- once | daily | weekly | monthly
+    start_at        TIMESTAMPTZ NOT NULL,
+    end_at          TIMESTAMPTZ,
+    status          VARCHAR(20) NOT NULL DEFAULT 'active',
+
+    -- Execution state
+    next_run_at         TIMESTAMPTZ NOT NULL,
+    occurrence          INT NOT NULL DEFAULT 0,
+    run_count           INT NOT NULL DEFAULT 0,
+    last_run_at         TIMESTAMPTZ,
+    last_transaction_id TEXT,
+    last_error          TEXT,
+
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- ============================================================================
+-- INDEXES
+-- ============================================================================
+CREATE INDEX IF NOT EXISTS idx_scheduled_payments_user_id ON scheduled_payments(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_scheduled_payments_due ON scheduled_payments(next_run_at) WHERE status = 'active';
+
+COMMENT ON TABLE scheduled_payments IS 'Recurring and future-dated payments executed by the payment scheduler';
@@ -0,0 +1,22 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - ADMIN AUDIT TRAIL
+-- Migration: 006_audit_trail.sql
+-- Description: Extends audit_log for the audit package (actor, before/after state)
+-- ============================================================================
+
+-- Entries outlive the accounts that made them, and in-memory user stores
+-- have no users row to reference
+ALTER TABLE audit_log DROP CONSTRAINT IF EXISTS audit_log_user_id_fkey;
+ALTER TABLE audit_log ALTER COLUMN user_id TYPE TEXT USING user_id::TEXT;
+
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS actor_email  TEXT;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS before_state JSONB;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS after_state  JSONB;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS path         TEXT;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS status       INTEGER;
+ALTER TABLE audit_log ADD COLUMN IF NOT EXISTS request_id   TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_resource ON audit_log(resource_type, resource_id);
+
+COMMENT ON COLUMN audit_log.before_state IS 'Resource state before the change (NULL on create)';
+COMMENT ON COLUMN audit_log.after_state IS 'Resource state after the change (NULL on delete)';
@@ -0,0 +1,23 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - FX RATE HISTORY
+-- Migration: 007_fx_rate_history.sql
+-- Description: One row per currency per FX worker fetch, for trend charts
+--              and volatility estimates
+-- ============================================================================
+
+-- ============================================================================
+-- TABLE: fx_rate_history
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS fx_rate_history (
+    id          BIGSERIAL PRIMARY KEY,
+    currency    VARCHAR(3) NOT NULL,
+    base        VARCHAR(3) NOT NULL DEFAULT 'USD',
+    rate        DOUBLE PRECISION NOT NULL CHECK (rate > 0),  -- Units of currency per 1 base
+    provider    TEXT,                                        -- exchangerate-api | ecb | static
+    fetched_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- ============================================================================
+-- INDEXES
+-- ============================================================================
+CREATE INDEX IF NOT EXISTS idx_fx_rate_history_currency_time ON fx_rate_history(currency, fetched_at);
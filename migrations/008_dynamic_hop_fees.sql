@@ -0,0 +1,9 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - DYNAMIC HOP FEES
+-- Migration: 008_dynamic_hop_fees.sql
+-- Description: Per-hop fee pricing (destination credibility and success rate)
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS hop_fee_breakdown JSONB;
+
+COMMENT ON COLUMN transactions.hop_fee_breakdown IS 'Per-hop fee multiplier, rate and amount at creation (NULL for flat-rate transactions)';
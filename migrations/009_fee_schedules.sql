@@ -0,0 +1,27 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - FEE SCHEDULES
+-- Migration: 009_fee_schedules.sql
+-- Description: Versioned fee rates set by admins; each transaction records
+--              the schedule it was priced under
+-- ============================================================================
+
+-- ============================================================================
+-- TABLE: fee_schedules
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS fee_schedules (
+    version            INT PRIMARY KEY,
+    base_fee_percent   DOUBLE PRECISION NOT NULL CHECK (base_fee_percent >= 0 AND base_fee_percent < 1),
+    hop_fee_percent    DOUBLE PRECISION NOT NULL CHECK (hop_fee_percent >= 0 AND hop_fee_percent < 1),
+    halt_fine_percent  DOUBLE PRECISION NOT NULL CHECK (halt_fine_percent >= 0 AND halt_fine_percent < 1),
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_by         TEXT                                  -- Admin username, or 'config' for the seeded schedule
+);
+
+-- ============================================================================
+-- TRANSACTIONS: schedule in effect at creation
+-- ============================================================================
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_schedule_version INT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_rates JSONB;
+
+COMMENT ON COLUMN transactions.fee_schedule_version IS 'fee_schedules.version active when the transaction was created';
+COMMENT ON COLUMN transactions.fee_rates IS 'Base, hop and halt rates the fees were computed from';
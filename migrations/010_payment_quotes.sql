@@ -0,0 +1,14 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - PAYMENT QUOTES
+-- Migration: 010_payment_quotes.sql
+-- Description: Exchange rates locked by a quote (POST /api/v1/payments/quote)
+--              and honored at settlement until the quote expires
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS quote_id TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS quoted_fx_rates JSONB;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS quote_expires_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN transactions.quote_id IS 'Signed quote the transaction was created from';
+COMMENT ON COLUMN transactions.quoted_fx_rates IS 'Per-USD rates locked by the quote, keyed by country code';
+COMMENT ON COLUMN transactions.quote_expires_at IS 'Settlement uses quoted_fx_rates until this time, current rates afterwards';
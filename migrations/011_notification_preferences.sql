@@ -0,0 +1,18 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - NOTIFICATION PREFERENCES
+-- Migration: 011_notification_preferences.sql
+-- Description: Per-user email notification settings
+--              (GET/PUT /api/v1/notifications/preferences)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    user_id             TEXT PRIMARY KEY,
+    email               BOOLEAN NOT NULL DEFAULT TRUE,
+    payment_succeeded   BOOLEAN NOT NULL DEFAULT TRUE,
+    payment_refunded    BOOLEAN NOT NULL DEFAULT TRUE,
+    attach_receipts     BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE notification_preferences IS 'Users without a row receive every notification';
+COMMENT ON COLUMN notification_preferences.email IS 'Master switch for payment emails; account emails are always sent';
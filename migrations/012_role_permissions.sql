@@ -0,0 +1,19 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - STAFF ROLES & PERMISSIONS
+-- Migration: 012_role_permissions.sql
+-- Description: Operator, auditor and treasury roles, and the role permission
+--              grants edited at runtime (GET/PUT /api/v1/admin/roles)
+-- ============================================================================
+
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'OPERATOR';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'AUDITOR';
+ALTER TYPE user_role ADD VALUE IF NOT EXISTS 'TREASURY';
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role            TEXT PRIMARY KEY,
+    permissions     TEXT[] NOT NULL DEFAULT '{}',
+    updated_by      TEXT,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE role_permissions IS 'Roles without a row keep their built-in permissions; ADMIN always holds every permission';
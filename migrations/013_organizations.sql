@@ -0,0 +1,31 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - ORGANIZATIONS
+-- Migration: 013_organizations.sql
+-- Description: Tenant organizations with negotiated fees and monthly spending
+--              limits, their members, and org attribution of transactions
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS organizations (
+    id                  TEXT PRIMARY KEY,
+    name                TEXT NOT NULL,
+    fee_override        JSONB,                               -- NULL = fee schedule applies
+    monthly_spend_limit DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (monthly_spend_limit >= 0), -- 0 = unlimited
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organizations_name ON organizations (LOWER(name));
+
+-- A user belongs to at most one organization
+CREATE TABLE IF NOT EXISTS organization_members (
+    user_id     TEXT PRIMARY KEY,
+    org_id      TEXT NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    role        TEXT NOT NULL CHECK (role IN ('admin', 'member')),
+    joined_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_org ON organization_members (org_id);
+
+-- Organization the user transacted for (kept if they later leave it)
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS org_id TEXT;
+CREATE INDEX IF NOT EXISTS idx_transactions_org ON transactions (org_id, created_at) WHERE org_id IS NOT NULL;
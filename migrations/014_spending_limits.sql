@@ -0,0 +1,19 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - SPENDING LIMITS
+-- Migration: 014_spending_limits.sql
+-- Description: Per-transaction caps and daily/monthly volume limits for
+--              users and organizations (/api/v1/admin/limits)
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS spending_limits (
+    scope           TEXT NOT NULL CHECK (scope IN ('default', 'user', 'org')),
+    subject_id      TEXT NOT NULL DEFAULT '',
+    per_transaction DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (per_transaction >= 0),
+    daily           DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (daily >= 0),
+    monthly         DOUBLE PRECISION NOT NULL DEFAULT 0 CHECK (monthly >= 0),
+    updated_by      TEXT,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (scope, subject_id)
+);
+
+COMMENT ON TABLE spending_limits IS 'Zero means unlimited; users without a user rule fall back to the default rule, then to the server config';
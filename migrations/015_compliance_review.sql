@@ -0,0 +1,13 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - COMPLIANCE REVIEW
+-- Migration: 015_compliance_review.sql
+-- Description: Transactions held by compliance screening (status
+--              'pending_review') and the record of their review
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS review JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_pending_review ON transactions(created_at)
+    WHERE status = 'pending_review';
+
+COMMENT ON COLUMN transactions.review IS 'Screening reasons for a hold and the approving or rejecting admin';
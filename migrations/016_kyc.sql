@@ -0,0 +1,20 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - KYC
+-- Migration: 016_kyc.sql
+-- Description: Identity verification status per user, metadata of the
+--              submitted documents and the reviewing admin's decision
+-- ============================================================================
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_status TEXT NOT NULL DEFAULT 'unverified'
+    CHECK (kyc_status IN ('unverified', 'pending', 'verified', 'rejected'));
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_documents JSONB NOT NULL DEFAULT '[]';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_submitted_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_reviewed_by TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_reviewed_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS kyc_note TEXT;
+
+-- Review queue
+CREATE INDEX IF NOT EXISTS idx_users_kyc_pending ON users(kyc_submitted_at)
+    WHERE kyc_status = 'pending';
+
+COMMENT ON COLUMN users.kyc_documents IS 'Document metadata only; the files live in external storage';
@@ -0,0 +1,15 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - DISPUTES
+-- Migration: 017_disputes.sql
+-- Description: Payer disputes of completed payments, their status history,
+--              and the Stripe PaymentIntent refunds are issued against
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payment_intent_id TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS dispute JSONB;
+
+-- Dispute queue
+CREATE INDEX IF NOT EXISTS idx_transactions_open_disputes ON transactions(created_at)
+    WHERE dispute->>'status' = 'open';
+
+COMMENT ON COLUMN transactions.dispute IS 'Reason, status transitions and refund of the payer''s dispute';
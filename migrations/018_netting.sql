@@ -0,0 +1,13 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - SETTLEMENT NETTING
+-- Migration: 018_netting.sql
+-- Description: Netting set that settled a payment together with offsetting
+--              payments in the same corridor
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS netting_set_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_netting_set ON transactions(netting_set_id)
+    WHERE netting_set_id IS NOT NULL;
+
+COMMENT ON COLUMN transactions.netting_set_id IS 'Netting set the payment (or its net settlement) belongs to; the set is recorded in the ledger';
@@ -0,0 +1,14 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - SETTLEMENT COMPENSATION
+-- Migration: 019_compensations.sql
+-- Description: Saga trail of settled hops reversed when a later hop of the
+--              same attempt failed
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS compensations JSONB;
+
+-- Payments with hops that could not be reversed need manual resolution
+CREATE INDEX IF NOT EXISTS idx_transactions_failed_compensations ON transactions(created_at)
+    WHERE compensations @> '[{"status": "failed"}]';
+
+COMMENT ON COLUMN transactions.compensations IS 'Per failed attempt: each hop, its compensating action and whether it was reversed';
@@ -0,0 +1,25 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - CREDIBILITY HISTORY
+-- Migration: 020_credibility_history.sql
+-- Description: One row per change of a country's credibility, from batched
+--              payment hop outcomes or decay toward its baseline, for charts
+-- ============================================================================
+
+-- ============================================================================
+-- TABLE: credibility_history
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS credibility_history (
+    id           BIGSERIAL PRIMARY KEY,
+    country_code VARCHAR(3) NOT NULL,
+    credibility  DOUBLE PRECISION NOT NULL,
+    change       DOUBLE PRECISION NOT NULL,
+    reason       TEXT NOT NULL,                    -- hops | decay
+    successes    INTEGER NOT NULL DEFAULT 0,
+    failures     INTEGER NOT NULL DEFAULT 0,
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- ============================================================================
+-- INDEXES
+-- ============================================================================
+CREATE INDEX IF NOT EXISTS idx_credibility_history_country_time ON credibility_history(country_code, recorded_at);
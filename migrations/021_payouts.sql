@@ -0,0 +1,16 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - PAYOUTS
+-- Migration: 021_payouts.sql
+-- Description: Stripe Connect account a settled payment is paid out to and
+--              the state of the transfer paying it
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payout_account TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payout JSONB;
+
+-- Settled payments still to be paid out, retried on startup
+CREATE INDEX IF NOT EXISTS idx_transactions_unpaid_payouts ON transactions(created_at)
+    WHERE payout_account IS NOT NULL AND (payout IS NULL OR payout->>'status' <> 'paid');
+
+COMMENT ON COLUMN transactions.payout_account IS 'Recipient Stripe Connect account (acct_...) paid the final amount once settled';
+COMMENT ON COLUMN transactions.payout IS 'Transfer paying out the final amount: Stripe ID, minor-unit amount, status, attempts';
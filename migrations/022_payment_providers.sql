@@ -0,0 +1,10 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - PAYMENT PROVIDERS
+-- Migration: 022_payment_providers.sql
+-- Description: Provider that collected each payment, so confirmation and
+--              refunds go back to it
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS payment_provider TEXT;
+
+COMMENT ON COLUMN transactions.payment_provider IS 'stripe | bank_transfer; NULL for payments collected by Stripe before providers were selectable';
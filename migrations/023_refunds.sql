@@ -0,0 +1,10 @@
+-- ============================================================================
+-- PREDICTIVE LIQUIDITY MESH - REFUND BREAKDOWNS
+-- Migration: 023_refunds.sql
+-- Description: What a refund returned to the payer and what fees it kept,
+--              under the refund policy
+-- ============================================================================
+
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS refund JSONB;
+
+COMMENT ON COLUMN transactions.refund IS 'Refund ID, reason (mesh_failure | user_cancelled | rejected | external), amount returned and fees retained';
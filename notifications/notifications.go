@@ -0,0 +1,129 @@
+// Package notifications emails users about their account and payments.
+// Messages are rendered from templates, filtered by per-user preferences and
+// delivered by a pluggable Sender (SMTP, or the log provider in development).
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// Sender providers
+const (
+	ProviderLog  = "log"  // Log messages instead of sending them (development)
+	ProviderSMTP = "smtp" // Send through an SMTP relay
+	ProviderNone = "none" // Notifications disabled
+)
+
+// Kind identifies a notification and its template
+type Kind string
+
+// Notification kinds
+const (
+	KindWelcome          Kind = "welcome"
+	KindPasswordReset    Kind = "password.reset"
+	KindPasswordChanged  Kind = "password.changed"
+	KindPaymentSucceeded Kind = "payment.succeeded"
+	KindPaymentRefunded  Kind = "payment.refunded" // Failed payment whose charge was refunded
+)
+
+// Config holds notification settings
+type Config struct {
+	Provider string
+	From     string // Sender address, e.g. "PLM <no-reply@plm.local>"
+	AppURL   string // Frontend base URL used for links in emails
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string // Empty = no authentication
+	SMTPPassword string
+
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	Workers        int
+	QueueSize      int
+}
+
+// DefaultConfig returns the default notification configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Provider:       ProviderLog,
+		From:           "Predictive Liquidity Mesh <no-reply@plm.local>",
+		AppURL:         "http://localhost:3000",
+		SMTPPort:       587,
+		MaxAttempts:    3,
+		InitialBackoff: 2 * time.Second,
+		Workers:        2,
+		QueueSize:      256,
+	}
+}
+
+// Validate checks the provider settings
+func (c *Config) Validate() error {
+	switch c.Provider {
+	case ProviderLog, ProviderNone:
+		return nil
+	case ProviderSMTP:
+		if c.SMTPHost == "" || c.From == "" {
+			return fmt.Errorf("smtp provider requires a host and from address")
+		}
+		if c.SMTPPort < 1 || c.SMTPPort > 65535 {
+			return fmt.Errorf("invalid smtp port %d", c.SMTPPort)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown provider %q (want %s, %s or %s)", c.Provider, ProviderLog, ProviderSMTP, ProviderNone)
+}
+
+// Message is a rendered email
+type Message struct {
+	From        string
+	To          string
+	Subject     string
+	Text        string
+	HTML        string // Optional alternative to Text
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Sender delivers messages. Implement it to plug in another email provider.
+type Sender interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewSender returns the sender for cfg.Provider (nil for ProviderNone)
+func NewSender(cfg *Config) (Sender, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	switch cfg.Provider {
+	case ProviderSMTP:
+		return NewSMTPSender(cfg), nil
+	case ProviderLog:
+		return LogSender{}, nil
+	}
+	return nil, nil
+}
+
+// LogSender logs messages instead of sending them
+type LogSender struct{}
+
+// Send logs the message envelope
+func (LogSender) Send(ctx context.Context, msg *Message) error {
+	names := make([]string, len(msg.Attachments))
+	for i, a := range msg.Attachments {
+		names[i] = a.Filename
+	}
+	slog.InfoContext(ctx, "email (log provider)", "to", msg.To, "subject", msg.Subject,
+		"attachments", strings.Join(names, ","))
+	return nil
+}
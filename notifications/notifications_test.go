@@ -0,0 +1,143 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []*Message
+}
+
+func (f *fakeSender) Send(ctx context.Context, msg *Message) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, msg)
+	return nil
+}
+
+type fakeDirectory map[string]*users.StoredUser
+
+func (d fakeDirectory) GetByID(id string) (*users.StoredUser, error) {
+	if u, ok := d[id]; ok {
+		return u, nil
+	}
+	return nil, users.ErrUserNotFound
+}
+
+func refundedTransaction() *payments.Transaction {
+	return &payments.Transaction{
+		ID:             "txn_1",
+		UserID:         "u1",
+		Amount:         100,
+		Currency:       "USD",
+		TargetCurrency: "GBP",
+		Route:          []string{"USA", "GBR"},
+		Status:         payments.StatusFailed,
+		PaymentMethod:  "refunded:re_123",
+		CreatedAt:      time.Now(),
+		HopResults:     []payments.HopResult{{FromCountry: "USA", ToCountry: "GBR", Error: "node timeout"}},
+	}
+}
+
+func TestNotifierHonorsPreferences(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{}
+	prefs := NewMemoryPreferences()
+	directory := fakeDirectory{"u1": {ID: "u1", Email: "ada@example.com", Username: "ada", IsActive: true}}
+	n := NewNotifier(DefaultConfig(), sender, prefs, directory)
+
+	msg, err := n.build(ctx, job{kind: KindPaymentRefunded, userID: "u1", txn: refundedTransaction()})
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if msg.To != "ada@example.com" || !strings.Contains(msg.Subject, "refunded") {
+		t.Errorf("message = %q to %q", msg.Subject, msg.To)
+	}
+	if !strings.Contains(msg.Text, "re_123") || !strings.Contains(msg.Text, "USA -> GBR") {
+		t.Errorf("text body missing refund details:\n%s", msg.Text)
+	}
+	if len(msg.Attachments) != 1 || msg.Attachments[0].Filename != "refund_receipt_txn_1.pdf" {
+		t.Errorf("attachments = %+v, want the refund receipt", msg.Attachments)
+	}
+
+	off := DefaultPreferences("u1")
+	off.PaymentRefunded = false
+	prefs.Save(ctx, off)
+	if msg, err := n.build(ctx, job{kind: KindPaymentRefunded, userID: "u1", txn: refundedTransaction()}); err != nil || msg != nil {
+		t.Errorf("opted-out refund email = %v, %v; want nothing", msg, err)
+	}
+
+	off.Email = false
+	prefs.Save(ctx, off)
+	if msg, err := n.build(ctx, job{kind: KindWelcome, userID: "u1"}); err != nil || msg == nil {
+		t.Errorf("welcome email = %v, %v; account emails ignore preferences", msg, err)
+	}
+}
+
+func TestBuildMIME(t *testing.T) {
+	raw, err := buildMIME(&Message{
+		From:        "PLM <no-reply@plm.local>",
+		To:          "ada@example.com",
+		Subject:     "Payment → settled",
+		Text:        "plain body",
+		HTML:        "<p>html body</p>",
+		Attachments: []Attachment{{Filename: "receipt.pdf", ContentType: "application/pdf", Data: bytes.Repeat([]byte{1}, 200)}},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("buildMIME: %v", err)
+	}
+
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if subject, _ := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject")); subject != "Payment → settled" {
+		t.Errorf("subject = %q", subject)
+	}
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("content type: %v", err)
+	}
+
+	var types []string
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		mediaType, partParams, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		types = append(types, mediaType)
+		if mediaType == "multipart/alternative" {
+			alt := multipart.NewReader(part, partParams["boundary"])
+			for {
+				p, err := alt.NextPart()
+				if err != nil {
+					break
+				}
+				altType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+				types = append(types, altType)
+			}
+		}
+	}
+	want := "multipart/alternative,text/plain,text/html,application/pdf"
+	if got := strings.Join(types, ","); got != want {
+		t.Errorf("parts = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,203 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/receipts"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+// Directory looks up notification recipients.
+// Implemented by users.Store and users.PostgresStore.
+type Directory interface {
+	GetByID(id string) (*users.StoredUser, error)
+}
+
+// job is a queued notification
+type job struct {
+	kind   Kind
+	userID string
+	txn    *payments.Transaction // Payment notifications
+
+	resetToken string // Password reset notifications
+	expiresAt  time.Time
+}
+
+// Notifier renders and delivers notifications in the background
+type Notifier struct {
+	cfg       *Config
+	sender    Sender
+	prefs     PreferenceStore
+	directory Directory
+	receipts  *receipts.Generator
+	queue     chan job
+}
+
+// NewNotifier creates a notifier delivering through sender
+func NewNotifier(cfg *Config, sender Sender, prefs PreferenceStore, directory Directory) *Notifier {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Notifier{
+		cfg:       cfg,
+		sender:    sender,
+		prefs:     prefs,
+		directory: directory,
+		receipts:  receipts.NewGenerator("Predictive Liquidity Mesh"),
+		queue:     make(chan job, cfg.QueueSize),
+	}
+}
+
+// Start runs the delivery workers until ctx is cancelled
+func (n *Notifier) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < n.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-n.queue:
+					n.deliver(ctx, j)
+				}
+			}
+		}()
+	}
+	log.Printf("📧 Notifier started (%s provider, %d workers)", n.cfg.Provider, n.cfg.Workers)
+	wg.Wait()
+}
+
+// NotifyRegistration queues the welcome email for a new user
+func (n *Notifier) NotifyRegistration(userID string) {
+	n.enqueue(job{kind: KindWelcome, userID: userID})
+}
+
+// NotifyPasswordReset queues the email with a user's password reset link
+func (n *Notifier) NotifyPasswordReset(userID, token string, expiresAt time.Time) {
+	n.enqueue(job{kind: KindPasswordReset, userID: userID, resetToken: token, expiresAt: expiresAt})
+}
+
+// NotifyPasswordChanged queues the confirmation that a user's password was reset
+func (n *Notifier) NotifyPasswordChanged(userID string) {
+	n.enqueue(job{kind: KindPasswordChanged, userID: userID})
+}
+
+// NotifyTransaction queues the email for a payment lifecycle event:
+// settled payments and refunded failures. Failures without a refund send nothing.
+func (n *Notifier) NotifyTransaction(event payments.StatusEvent, txn *payments.Transaction) {
+	switch event {
+	case payments.EventPaymentSucceeded:
+		n.enqueue(job{kind: KindPaymentSucceeded, userID: txn.UserID, txn: txn})
+	case payments.EventPaymentRefunded:
+		n.enqueue(job{kind: KindPaymentRefunded, userID: txn.UserID, txn: txn})
+	}
+}
+
+func (n *Notifier) enqueue(j job) {
+	select {
+	case n.queue <- j:
+	default:
+		slog.Warn("notification queue full, dropping", "kind", j.kind, "user_id", j.userID)
+	}
+}
+
+// deliver builds the message and sends it, retrying with exponential backoff
+func (n *Notifier) deliver(ctx context.Context, j job) {
+	msg, err := n.build(ctx, j)
+	if err != nil {
+		slog.WarnContext(ctx, "notification not sent", "kind", j.kind, "user_id", j.userID, "error", err)
+		return
+	}
+	if msg == nil {
+		return // Disabled by the user's preferences
+	}
+
+	backoff := n.cfg.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		err := n.sender.Send(ctx, msg)
+		if err == nil {
+			slog.InfoContext(ctx, "notification sent", "kind", j.kind, "user_id", j.userID)
+			return
+		}
+		if attempt >= n.cfg.MaxAttempts {
+			slog.ErrorContext(ctx, "notification failed", "kind", j.kind, "user_id", j.userID,
+				"attempts", attempt, "error", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// build renders j for its recipient, or returns nil if their preferences opt out
+func (n *Notifier) build(ctx context.Context, j job) (*Message, error) {
+	user, err := n.directory.GetByID(j.userID)
+	if err != nil {
+		return nil, fmt.Errorf("recipient: %w", err)
+	}
+	if !user.IsActive || user.Email == "" {
+		return nil, nil
+	}
+
+	prefs, err := n.prefs.Get(ctx, j.userID)
+	if err != nil {
+		return nil, fmt.Errorf("preferences: %w", err)
+	}
+	if !prefs.Allows(j.kind) {
+		return nil, nil
+	}
+
+	data := &TemplateData{Name: user.FullName, Email: user.Email, AppURL: n.cfg.AppURL}
+	if data.Name == "" {
+		data.Name = user.Username
+	}
+	if j.resetToken != "" {
+		data.ResetURL = n.cfg.AppURL + "/reset-password?token=" + url.QueryEscape(j.resetToken)
+		data.ExpiresAt = j.expiresAt
+	}
+
+	var receiptKind receipts.Kind
+	if j.txn != nil {
+		receiptKind = receipts.KindPayment
+		if j.kind == KindPaymentRefunded {
+			receiptKind = receipts.KindRefund
+		}
+		if data.Receipt, err = n.receipts.NewReceipt(j.txn, receiptKind); err != nil {
+			return nil, fmt.Errorf("receipt: %w", err)
+		}
+	}
+
+	msg, err := Render(j.kind, data)
+	if err != nil {
+		return nil, err
+	}
+	msg.From = n.cfg.From
+
+	if j.txn != nil && prefs.AttachReceipts {
+		pdf, err := n.receipts.Render(j.txn, receiptKind, receipts.FormatPDF)
+		if err != nil {
+			return nil, fmt.Errorf("receipt pdf: %w", err)
+		}
+		filename := "receipt_" + j.txn.ID + ".pdf"
+		if receiptKind == receipts.KindRefund {
+			filename = "refund_receipt_" + j.txn.ID + ".pdf"
+		}
+		msg.Attachments = append(msg.Attachments, Attachment{
+			Filename: filename, ContentType: receipts.FormatPDF.ContentType(), Data: pdf,
+		})
+	}
+	return msg, nil
+}
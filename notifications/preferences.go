@@ -0,0 +1,77 @@
+package notifications
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Preferences are a user's email notification settings. Account emails
+// (welcome, password reset) are always sent; payment emails honor these switches.
+type Preferences struct {
+	UserID           string    `json:"user_id"`
+	Email            bool      `json:"email"` // Master switch for payment emails
+	PaymentSucceeded bool      `json:"payment_succeeded"`
+	PaymentRefunded  bool      `json:"payment_refunded"`
+	AttachReceipts   bool      `json:"attach_receipts"` // Attach the PDF receipt to payment emails
+	UpdatedAt        time.Time `json:"updated_at,omitempty"`
+}
+
+// DefaultPreferences returns the settings of a user who has not changed them
+func DefaultPreferences(userID string) *Preferences {
+	return &Preferences{
+		UserID:           userID,
+		Email:            true,
+		PaymentSucceeded: true,
+		PaymentRefunded:  true,
+		AttachReceipts:   true,
+	}
+}
+
+// Allows reports whether a notification of kind may be sent
+func (p *Preferences) Allows(kind Kind) bool {
+	switch kind {
+	case KindPaymentSucceeded:
+		return p.Email && p.PaymentSucceeded
+	case KindPaymentRefunded:
+		return p.Email && p.PaymentRefunded
+	}
+	return true // Account emails
+}
+
+// PreferenceStore persists notification preferences
+type PreferenceStore interface {
+	// Get returns userID's preferences, or the defaults if none were saved
+	Get(ctx context.Context, userID string) (*Preferences, error)
+	Save(ctx context.Context, prefs *Preferences) error
+}
+
+// MemoryPreferences is an in-memory PreferenceStore
+type MemoryPreferences struct {
+	mu    sync.RWMutex
+	prefs map[string]Preferences
+}
+
+// NewMemoryPreferences creates an empty in-memory preference store
+func NewMemoryPreferences() *MemoryPreferences {
+	return &MemoryPreferences{prefs: make(map[string]Preferences)}
+}
+
+// Get returns userID's preferences, or the defaults if none were saved
+func (m *MemoryPreferences) Get(ctx context.Context, userID string) (*Preferences, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if p, ok := m.prefs[userID]; ok {
+		return &p, nil
+	}
+	return DefaultPreferences(userID), nil
+}
+
+// Save stores prefs, stamping UpdatedAt
+func (m *MemoryPreferences) Save(ctx context.Context, prefs *Preferences) error {
+	prefs.UpdatedAt = time.Now().UTC()
+	m.mu.Lock()
+	m.prefs[prefs.UserID] = *prefs
+	m.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,175 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPSender sends messages through an SMTP relay, using STARTTLS when the
+// server offers it
+type SMTPSender struct {
+	addr string
+	host string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPSender creates an SMTP sender from cfg
+func NewSMTPSender(cfg *Config) *SMTPSender {
+	s := &SMTPSender{
+		addr: net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		host: cfg.SMTPHost,
+		from: cfg.From,
+	}
+	if cfg.SMTPUsername != "" {
+		s.auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+	return s
+}
+
+// Send delivers msg. net/smtp has no context support, so ctx only cancels
+// sends that have not started.
+func (s *SMTPSender) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if msg.From == "" {
+		msg.From = s.from
+	}
+	from, err := mail.ParseAddress(msg.From)
+	if err != nil {
+		return fmt.Errorf("invalid from address: %w", err)
+	}
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	body, err := buildMIME(msg, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := smtp.SendMail(s.addr, s.auth, from.Address, []string{to.Address}, body); err != nil {
+		return fmt.Errorf("smtp send to %s: %w", s.host, err)
+	}
+	return nil
+}
+
+// buildMIME encodes msg as a multipart/mixed message: a text/plain and
+// text/html alternative followed by base64 attachments
+func buildMIME(msg *Message, now time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	mixed := multipart.NewWriter(&buf)
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", msg.From)
+	header.Set("To", msg.To)
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", now.Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(msg.From))
+	header.Set("MIME-Version", "1.0")
+	header.Set("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type"} {
+		fmt.Fprintf(&buf, "%s: %s\r\n", key, header.Get(key))
+	}
+	buf.WriteString("\r\n")
+
+	// Body: text and (optionally) HTML alternatives
+	altBoundary := "alt-" + mixed.Boundary()
+	bodyPart, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + altBoundary},
+	})
+	if err != nil {
+		return nil, err
+	}
+	alternative := multipart.NewWriter(bodyPart)
+	if err := alternative.SetBoundary(altBoundary); err != nil {
+		return nil, err
+	}
+	if err := writeQuotedPrintable(alternative, "text/plain; charset=utf-8", msg.Text); err != nil {
+		return nil, err
+	}
+	if msg.HTML != "" {
+		if err := writeQuotedPrintable(alternative, "text/html; charset=utf-8", msg.HTML); err != nil {
+			return nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, err
+	}
+
+	for _, a := range msg.Attachments {
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func writeQuotedPrintable(w *multipart.Writer, contentType, content string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeBase64Lines writes data base64 encoded in 76 character lines (RFC 2045)
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
+
+// messageID returns a unique Message-ID in the sender's domain
+func messageID(from string) string {
+	domain := "plm.local"
+	if addr, err := mail.ParseAddress(from); err == nil {
+		if i := strings.LastIndex(addr.Address, "@"); i >= 0 {
+			domain = addr.Address[i+1:]
+		}
+	}
+	b := make([]byte, 12)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
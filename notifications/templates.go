@@ -0,0 +1,175 @@
+package notifications
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/receipts"
+)
+
+// TemplateData is the data available to notification templates
+type TemplateData struct {
+	Name    string // Full name, or username if unset
+	Email   string
+	AppURL  string
+	Receipt *receipts.Receipt // Payment notifications
+
+	ResetURL  string    // Password reset link
+	ExpiresAt time.Time // When the reset link expires
+}
+
+// emailTemplate holds the templates of one notification kind
+type emailTemplate struct {
+	subject string
+	text    string
+	html    string
+}
+
+var emailTemplates = map[Kind]emailTemplate{
+	KindWelcome: {
+		subject: `Welcome to Predictive Liquidity Mesh, {{.Name}}`,
+		text: `Hi {{.Name}},
+
+Your account ({{.Email}}) is ready. Sign in to start sending cross-border payments:
+{{.AppURL}}/login
+
+You can choose which emails you receive under notification settings.
+`,
+		html: `<p>Hi {{.Name}},</p>
+<p>Your account (<b>{{.Email}}</b>) is ready.</p>
+<p><a href="{{.AppURL}}/login" style="background:#10b981;color:#fff;padding:10px 16px;border-radius:6px;text-decoration:none">Sign in</a></p>
+<p style="color:#64748b;font-size:12px">You can choose which emails you receive under notification settings.</p>`,
+	},
+	KindPasswordReset: {
+		subject: `Reset your Predictive Liquidity Mesh password`,
+		text: `Hi {{.Name}},
+
+We received a request to reset the password for {{.Email}}. Choose a new password here:
+{{.ResetURL}}
+
+The link works once and expires at {{.ExpiresAt.Format "15:04 MST on January 2"}}.
+If you did not ask for a reset, ignore this email; your password is unchanged.
+`,
+		html: `<p>Hi {{.Name}},</p>
+<p>We received a request to reset the password for <b>{{.Email}}</b>.</p>
+<p><a href="{{.ResetURL}}" style="background:#10b981;color:#fff;padding:10px 16px;border-radius:6px;text-decoration:none">Choose a new password</a></p>
+<p style="color:#64748b;font-size:12px">The link works once and expires at {{.ExpiresAt.Format "15:04 MST on January 2"}}.
+If you did not ask for a reset, ignore this email; your password is unchanged.</p>`,
+	},
+	KindPasswordChanged: {
+		subject: `Your Predictive Liquidity Mesh password was changed`,
+		text: `Hi {{.Name}},
+
+The password for {{.Email}} was just reset. If this was not you, contact support immediately.
+`,
+		html: `<p>Hi {{.Name}},</p>
+<p>The password for <b>{{.Email}}</b> was just reset.</p>
+<p style="color:#ef4444">If this was not you, contact support immediately.</p>`,
+	},
+	KindPaymentSucceeded: {
+		subject: `Payment {{.Receipt.TransactionID}} completed: {{money .Receipt.FinalAmount}} {{.Receipt.TargetCurrency}} delivered`,
+		text: `Hi {{.Name}},
+
+Your payment {{.Receipt.TransactionID}} has settled.
+
+  Sent:     {{money .Receipt.Amount}} {{.Receipt.Currency}}
+  Fees:     {{money .Receipt.TotalFees}} {{.Receipt.Currency}}
+  Received: {{money .Receipt.FinalAmount}} {{.Receipt.TargetCurrency}}
+  Route:    {{route .Receipt.Route}}
+
+Receipt: {{.AppURL}}/transactions
+`,
+		html: `<p>Hi {{.Name}},</p>
+<p>Your payment <b>{{.Receipt.TransactionID}}</b> has settled.</p>
+<table cellpadding="4" style="border-collapse:collapse">
+  <tr><td>Sent</td><td align="right">{{money .Receipt.Amount}} {{.Receipt.Currency}}</td></tr>
+  <tr><td>Fees</td><td align="right" style="color:#ef4444">-{{money .Receipt.TotalFees}} {{.Receipt.Currency}}</td></tr>
+  <tr style="font-weight:bold"><td>Received</td><td align="right">{{money .Receipt.FinalAmount}} {{.Receipt.TargetCurrency}}</td></tr>
+  <tr><td>Route</td><td align="right">{{route .Receipt.Route}}</td></tr>
+</table>
+<p><a href="{{.AppURL}}/transactions">View your transactions</a></p>`,
+	},
+	KindPaymentRefunded: {
+		subject: `Payment {{.Receipt.TransactionID}} failed and was refunded`,
+		text: `Hi {{.Name}},
+
+Your payment {{.Receipt.TransactionID}} of {{money .Receipt.Amount}} {{.Receipt.Currency}} could not be settled{{with .Receipt.Refund}}{{if .FailedAt}} (failed at {{.FailedAt}}){{end}}.
+
+{{money .Amount}} {{$.Receipt.Currency}} has been refunded to your card (refund {{.RefundID}}).
+Refunds usually appear within 5-10 business days.{{end}}
+
+Details: {{.AppURL}}/transactions
+`,
+		html: `<p>Hi {{.Name}},</p>
+<p>Your payment <b>{{.Receipt.TransactionID}}</b> of {{money .Receipt.Amount}} {{.Receipt.Currency}} could not be settled{{with .Receipt.Refund}}{{if .FailedAt}} (failed at {{.FailedAt}}){{end}}.</p>
+<p><b>{{money .Amount}} {{$.Receipt.Currency}}</b> has been refunded to your card (refund <code>{{.RefundID}}</code>).
+Refunds usually appear within 5-10 business days.{{end}}</p>
+<p><a href="{{.AppURL}}/transactions">View your transactions</a></p>`,
+	},
+}
+
+var templateFuncs = map[string]interface{}{
+	"money": func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) },
+	"route": func(codes []string) string { return strings.Join(codes, " → ") },
+}
+
+// htmlLayout wraps every HTML body
+const htmlLayout = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"></head>
+<body style="font-family:Helvetica,Arial,sans-serif;color:#0f172a;max-width:600px;margin:0 auto;padding:24px">
+<h2 style="color:#10b981">Predictive Liquidity Mesh</h2>
+{{template "body" .}}
+</body></html>`
+
+// Render renders the subject, text and HTML body of a notification
+func Render(kind Kind, data *TemplateData) (*Message, error) {
+	tmpl, ok := emailTemplates[kind]
+	if !ok {
+		return nil, fmt.Errorf("no template for notification %q", kind)
+	}
+
+	subject, err := renderText(string(kind)+".subject", tmpl.subject, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := renderText(string(kind)+".text", tmpl.text, data)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := htmltemplate.New(string(kind)).Funcs(templateFuncs).Parse(htmlLayout)
+	if err == nil {
+		_, err = page.New("body").Parse(tmpl.html)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s html template: %w", kind, err)
+	}
+	var html bytes.Buffer
+	if err := page.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", kind, err)
+	}
+
+	return &Message{
+		To:      data.Email,
+		Subject: strings.TrimSpace(subject),
+		Text:    text,
+		HTML:    html.String(),
+	}, nil
+}
+
+func renderText(name, text string, data *TemplateData) (string, error) {
+	tmpl, err := texttemplate.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("parse %s template: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
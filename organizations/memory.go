@@ -0,0 +1,244 @@
+package organizations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// MemoryStore is an in-memory Store. It also serves reads for the Postgres
+// store, which writes changes through to the database.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	orgs    map[string]*Organization
+	members map[string]*Member // userID -> membership
+}
+
+// NewMemoryStore creates an empty in-memory organization store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		orgs:    make(map[string]*Organization),
+		members: make(map[string]*Member),
+	}
+}
+
+// NewID returns a new organization ID
+func NewID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return "org_" + hex.EncodeToString(b)
+}
+
+// Create validates org and stores it, assigning an ID if it has none
+func (s *MemoryStore) Create(ctx context.Context, org *Organization) error {
+	if err := org.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.nameTakenLocked(org.Name, "") {
+		return ErrDuplicateName
+	}
+	if org.ID == "" {
+		org.ID = NewID()
+	}
+	now := time.Now().UTC()
+	if org.CreatedAt.IsZero() {
+		org.CreatedAt = now
+	}
+	org.UpdatedAt = now
+	cp := *org
+	s.orgs[org.ID] = &cp
+	return nil
+}
+
+// Get returns an organization by ID
+func (s *MemoryStore) Get(ctx context.Context, id string) (*Organization, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	org, ok := s.orgs[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return s.snapshotLocked(org), nil
+}
+
+// List returns every organization, by name
+func (s *MemoryStore) List(ctx context.Context) ([]*Organization, error) {
+	s.mu.RLock()
+	orgs := make([]*Organization, 0, len(s.orgs))
+	for _, org := range s.orgs {
+		orgs = append(orgs, s.snapshotLocked(org))
+	}
+	s.mu.RUnlock()
+	sort.Slice(orgs, func(i, j int) bool { return orgs[i].Name < orgs[j].Name })
+	return orgs, nil
+}
+
+// Update replaces an organization's settings
+func (s *MemoryStore) Update(ctx context.Context, org *Organization) error {
+	if err := org.Validate(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.orgs[org.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	if s.nameTakenLocked(org.Name, org.ID) {
+		return ErrDuplicateName
+	}
+	org.CreatedAt = existing.CreatedAt
+	org.UpdatedAt = time.Now().UTC()
+	cp := *org
+	s.orgs[org.ID] = &cp
+	return nil
+}
+
+// AddMember adds or re-roles a member. Users already in another
+// organization must be removed from it first.
+func (s *MemoryStore) AddMember(ctx context.Context, m *Member) error {
+	if !m.Role.Valid() {
+		return ErrInvalidRole
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.orgs[m.OrgID]; !ok {
+		return ErrNotFound
+	}
+	existing, ok := s.members[m.UserID]
+	if ok && existing.OrgID != m.OrgID {
+		return ErrAlreadyMember
+	}
+	if ok && existing.Role == RoleAdmin && m.Role != RoleAdmin && s.adminCountLocked(m.OrgID) == 1 {
+		return ErrLastAdminLeave
+	}
+	if ok {
+		m.JoinedAt = existing.JoinedAt
+	} else if m.JoinedAt.IsZero() {
+		m.JoinedAt = time.Now().UTC()
+	}
+	cp := *m
+	s.members[m.UserID] = &cp
+	return nil
+}
+
+// RemoveMember removes userID from the organization
+func (s *MemoryStore) RemoveMember(ctx context.Context, orgID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.members[userID]
+	if !ok || m.OrgID != orgID {
+		return ErrNotMember
+	}
+	if m.Role == RoleAdmin && s.adminCountLocked(orgID) == 1 {
+		return ErrLastAdminLeave
+	}
+	delete(s.members, userID)
+	return nil
+}
+
+// Members returns an organization's members, admins first
+func (s *MemoryStore) Members(ctx context.Context, orgID string) ([]*Member, error) {
+	s.mu.RLock()
+	if _, ok := s.orgs[orgID]; !ok {
+		s.mu.RUnlock()
+		return nil, ErrNotFound
+	}
+	members := make([]*Member, 0)
+	for _, m := range s.members {
+		if m.OrgID == orgID {
+			cp := *m
+			members = append(members, &cp)
+		}
+	}
+	s.mu.RUnlock()
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Role != members[j].Role {
+			return members[i].Role == RoleAdmin
+		}
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+	return members, nil
+}
+
+// MembershipOf returns userID's membership, or nil if they have none
+func (s *MemoryStore) MembershipOf(ctx context.Context, userID string) (*Member, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.members[userID]
+	if !ok {
+		return nil, nil
+	}
+	cp := *m
+	return &cp, nil
+}
+
+// OrgTerms returns the terms userID's organization transacts under, or nil
+func (s *MemoryStore) OrgTerms(userID string) *payments.OrgTerms {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	m, ok := s.members[userID]
+	if !ok {
+		return nil
+	}
+	org, ok := s.orgs[m.OrgID]
+	if !ok {
+		return nil
+	}
+	return &payments.OrgTerms{OrgID: org.ID, Fees: org.FeeOverride, MonthlyLimit: org.MonthlySpendLimit}
+}
+
+// RestoreOrg loads a previously persisted organization
+func (s *MemoryStore) RestoreOrg(org *Organization) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs[org.ID] = org
+}
+
+// RestoreMember loads a previously persisted membership
+func (s *MemoryStore) RestoreMember(m *Member) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.members[m.UserID] = m
+}
+
+func (s *MemoryStore) snapshotLocked(org *Organization) *Organization {
+	cp := *org
+	cp.MemberCount = 0
+	for _, m := range s.members {
+		if m.OrgID == org.ID {
+			cp.MemberCount++
+		}
+	}
+	return &cp
+}
+
+func (s *MemoryStore) adminCountLocked(orgID string) int {
+	n := 0
+	for _, m := range s.members {
+		if m.OrgID == orgID && m.Role == RoleAdmin {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *MemoryStore) nameTakenLocked(name, exceptID string) bool {
+	for id, org := range s.orgs {
+		if id != exceptID && strings.EqualFold(org.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Compile-time interface check
+var _ Store = (*MemoryStore)(nil)
@@ -0,0 +1,56 @@
+package organizations
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func TestMembershipRules(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+
+	acme := &Organization{Name: "Acme", MonthlySpendLimit: 5000, FeeOverride: &payments.FeeConfig{BaseFeePercent: 0.01}}
+	if err := store.Create(ctx, acme); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := store.Create(ctx, &Organization{Name: "ACME"}); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("duplicate name: err = %v, want ErrDuplicateName", err)
+	}
+	other := &Organization{Name: "Globex"}
+	store.Create(ctx, other)
+
+	if err := store.AddMember(ctx, &Member{OrgID: acme.ID, UserID: "alice", Role: RoleAdmin}); err != nil {
+		t.Fatalf("AddMember: %v", err)
+	}
+	store.AddMember(ctx, &Member{OrgID: acme.ID, UserID: "bob", Role: RoleMember})
+	if err := store.AddMember(ctx, &Member{OrgID: other.ID, UserID: "bob", Role: RoleMember}); !errors.Is(err, ErrAlreadyMember) {
+		t.Errorf("second organization: err = %v, want ErrAlreadyMember", err)
+	}
+
+	// The last admin can neither leave nor be demoted
+	if err := store.RemoveMember(ctx, acme.ID, "alice"); !errors.Is(err, ErrLastAdminLeave) {
+		t.Errorf("remove last admin: err = %v, want ErrLastAdminLeave", err)
+	}
+	if err := store.AddMember(ctx, &Member{OrgID: acme.ID, UserID: "alice", Role: RoleMember}); !errors.Is(err, ErrLastAdminLeave) {
+		t.Errorf("demote last admin: err = %v, want ErrLastAdminLeave", err)
+	}
+	store.AddMember(ctx, &Member{OrgID: acme.ID, UserID: "bob", Role: RoleAdmin})
+	if err := store.RemoveMember(ctx, acme.ID, "alice"); err != nil {
+		t.Errorf("remove admin with another admin left: %v", err)
+	}
+
+	got, _ := store.Get(ctx, acme.ID)
+	if got.MemberCount != 1 {
+		t.Errorf("member count = %d, want 1", got.MemberCount)
+	}
+	terms := store.OrgTerms("bob")
+	if terms == nil || terms.OrgID != acme.ID || terms.MonthlyLimit != 5000 || terms.Fees.BaseFeePercent != 0.01 {
+		t.Errorf("terms = %+v, want Acme's limit and fee override", terms)
+	}
+	if store.OrgTerms("alice") != nil {
+		t.Error("former member still has organization terms")
+	}
+}
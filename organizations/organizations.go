@@ -0,0 +1,88 @@
+// Package organizations groups users into tenant organizations with shared
+// transaction history, negotiated fees and a monthly spending limit.
+package organizations
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// Role is a member's role within their organization
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"  // Manages members and sees the organization's transactions
+	RoleMember Role = "member" // Transacts under the organization's terms
+)
+
+var (
+	ErrNotFound       = errors.New("organization not found")
+	ErrNotMember      = errors.New("user is not a member of this organization")
+	ErrAlreadyMember  = errors.New("user already belongs to another organization")
+	ErrInvalidRole    = errors.New("role must be admin or member")
+	ErrNameRequired   = errors.New("organization name is required")
+	ErrInvalidLimit   = errors.New("monthly spend limit must not be negative")
+	ErrDuplicateName  = errors.New("an organization with this name already exists")
+	ErrLastAdminLeave = errors.New("an organization must keep at least one admin")
+)
+
+// Organization is a tenant whose members transact under shared terms
+type Organization struct {
+	ID                string              `json:"id"`
+	Name              string              `json:"name"`
+	FeeOverride       *payments.FeeConfig `json:"fee_override,omitempty"` // Replaces the fee schedule for members
+	MonthlySpendLimit float64             `json:"monthly_spend_limit"`    // 0 = unlimited
+	MemberCount       int                 `json:"member_count"`           // Set on reads
+	CreatedAt         time.Time           `json:"created_at"`
+	UpdatedAt         time.Time           `json:"updated_at"`
+}
+
+// Validate checks the organization's settings
+func (o *Organization) Validate() error {
+	if strings.TrimSpace(o.Name) == "" {
+		return ErrNameRequired
+	}
+	if o.MonthlySpendLimit < 0 {
+		return ErrInvalidLimit
+	}
+	if o.FeeOverride != nil {
+		return o.FeeOverride.Validate()
+	}
+	return nil
+}
+
+// Member is a user's membership in an organization. A user belongs to at
+// most one organization.
+type Member struct {
+	OrgID    string    `json:"org_id"`
+	UserID   string    `json:"user_id"`
+	Role     Role      `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleMember
+}
+
+// Store persists organizations and their members
+type Store interface {
+	Create(ctx context.Context, org *Organization) error
+	Get(ctx context.Context, id string) (*Organization, error)
+	List(ctx context.Context) ([]*Organization, error)
+	Update(ctx context.Context, org *Organization) error
+
+	// AddMember adds or re-roles a member
+	AddMember(ctx context.Context, m *Member) error
+	RemoveMember(ctx context.Context, orgID, userID string) error
+	Members(ctx context.Context, orgID string) ([]*Member, error)
+	// MembershipOf returns userID's membership, or nil if they have none
+	MembershipOf(ctx context.Context, userID string) (*Member, error)
+
+	// OrgTerms returns the terms userID's organization transacts under
+	payments.OrgResolver
+}
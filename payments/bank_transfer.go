@@ -0,0 +1,89 @@
+package payments
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// bankTransferPrefix starts the reference of every bank transfer
+const bankTransferPrefix = "bt_"
+
+// BankTransferProvider collects payments by bank transfer. It is simulated:
+// the payer is given a reference to quote on their transfer, and the funds
+// count as received when the payment is confirmed. No bank is contacted.
+type BankTransferProvider struct{}
+
+// NewBankTransferProvider creates a simulated bank transfer provider
+func NewBankTransferProvider() *BankTransferProvider {
+	return &BankTransferProvider{}
+}
+
+// Name returns the provider name of bank transfers
+func (p *BankTransferProvider) Name() string {
+	return ProviderBankTransfer
+}
+
+// GetPublishableKey returns "": bank transfers need no frontend key
+func (p *BankTransferProvider) GetPublishableKey() string {
+	return ""
+}
+
+// IsMockMode reports true, since no bank is contacted
+func (p *BankTransferProvider) IsMockMode() bool {
+	return true
+}
+
+// CreatePaymentIntent issues the reference the payer quotes on their bank
+// transfer. The same idempotency key always yields the same reference.
+func (p *BankTransferProvider) CreatePaymentIntent(req *PaymentIntentRequest) (*PaymentIntentResponse, error) {
+	var ref string
+	if req.IdempotencyKey != "" {
+		sum := sha256.Sum256([]byte(req.IdempotencyKey))
+		ref = hex.EncodeToString(sum[:12])
+	} else {
+		b := make([]byte, 12)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("bank transfer reference: %w", err)
+		}
+		ref = hex.EncodeToString(b)
+	}
+	return &PaymentIntentResponse{
+		ID:       bankTransferPrefix + ref,
+		Amount:   req.Amount,
+		Currency: req.Currency,
+		Status:   "requires_action", // Waiting for the payer's transfer
+	}, nil
+}
+
+// ConfirmPaymentIntent reports the transfer as received
+func (p *BankTransferProvider) ConfirmPaymentIntent(paymentIntentID string) (*PaymentIntentResponse, error) {
+	if !strings.HasPrefix(paymentIntentID, bankTransferPrefix) {
+		return nil, fmt.Errorf("%q is not a bank transfer reference", paymentIntentID)
+	}
+	return &PaymentIntentResponse{
+		ID:     paymentIntentID,
+		Status: "succeeded",
+	}, nil
+}
+
+// GetPaymentIntent reports the transfer as received, as ConfirmPaymentIntent does
+func (p *BankTransferProvider) GetPaymentIntent(paymentIntentID string) (*PaymentIntentResponse, error) {
+	return p.ConfirmPaymentIntent(paymentIntentID)
+}
+
+// RefundPayment returns the funds to the payer's account
+func (p *BankTransferProvider) RefundPayment(paymentIntentID string, amount int64, reason string) (*RefundResponse, error) {
+	if !strings.HasPrefix(paymentIntentID, bankTransferPrefix) {
+		return nil, fmt.Errorf("%q is not a bank transfer reference", paymentIntentID)
+	}
+	return &RefundResponse{
+		ID:              "re_" + paymentIntentID,
+		PaymentIntentID: paymentIntentID,
+		Amount:          amount,
+		Status:          "succeeded",
+		Reason:          reason,
+	}, nil
+}
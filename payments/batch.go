@@ -0,0 +1,165 @@
+package payments
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// MaxBatchSize is the maximum number of payments in one batch
+const MaxBatchSize = 100
+
+// ErrBatchNotFound is returned for unknown batch IDs
+var ErrBatchNotFound = errors.New("batch not found")
+
+// BatchItem is one payment in a batch submission
+type BatchItem struct {
+	Amount         float64  `json:"amount"`
+	Currency       string   `json:"currency"`
+	TargetCurrency string   `json:"target_currency"`
+	Route          []string `json:"route"`
+}
+
+// Batch is a set of payments submitted together
+type Batch struct {
+	ID           string         `json:"id"`
+	UserID       string         `json:"user_id"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Transactions []*Transaction `json:"transactions"`
+}
+
+// BatchSummary aggregates the status of a batch's transactions
+type BatchSummary struct {
+	BatchID          string  `json:"batch_id"`
+	Total            int     `json:"total"`
+	Pending          int     `json:"pending"`
+	HeldForReview    int     `json:"held_for_review"`
+	Processing       int     `json:"processing"`
+	Succeeded        int     `json:"succeeded"`
+	Failed           int     `json:"failed"`
+	Complete         bool    `json:"complete"` // No transaction left pending, held or processing
+	TotalAmount      float64 `json:"total_amount"`
+	TotalFees        float64 `json:"total_fees"`
+	TotalFinalAmount float64 `json:"total_final_amount"` // Delivered by succeeded transactions
+}
+
+// generateBatchID generates a unique batch ID
+func generateBatchID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return "batch_" + hex.EncodeToString(bytes)
+}
+
+// PrepareBatch validates the items and builds their pending transactions under
+// a new batch ID without storing them. Fails without side effects if any item
+// is invalid.
+func (s *TransactionStore) PrepareBatch(userID string, items []BatchItem, haltedNodes map[string]bool) ([]*Transaction, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("batch must contain at least one payment")
+	}
+	if len(items) > MaxBatchSize {
+		return nil, fmt.Errorf("batch exceeds %d payments", MaxBatchSize)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rates := s.currencyRatesLocked()
+	total := 0.0
+	for i, item := range items {
+		if item.Amount <= 0 {
+			return nil, fmt.Errorf("payment %d: amount must be positive", i)
+		}
+		total += toVolumeCurrency(rates, item.Amount, item.Currency)
+	}
+	if err := s.checkOrgLimitLocked(userID, total); err != nil {
+		return nil, err
+	}
+
+	batchID := generateBatchID()
+	txns := make([]*Transaction, 0, len(items))
+	for i, item := range items {
+		txn, err := s.newTransaction(userID, item.Amount, item.Currency, item.TargetCurrency, item.Route, haltedNodes)
+		if err != nil {
+			return nil, fmt.Errorf("payment %d: %w", i, err)
+		}
+		txn.BatchID = batchID
+		txns = append(txns, txn)
+	}
+	return txns, nil
+}
+
+// AddBatch stores transactions built by PrepareBatch, all at once
+func (s *TransactionStore) AddBatch(txns []*Transaction) {
+	s.mu.Lock()
+	for _, txn := range txns {
+		s.addLocked(txn)
+	}
+	s.mu.Unlock()
+
+	for _, txn := range txns {
+		s.publishEvent(natsClient.SettlementCreated, txn.ID)
+	}
+}
+
+// CreateBatch creates one pending transaction per item under a shared batch ID.
+// Either every item is created or none is.
+func (s *TransactionStore) CreateBatch(userID string, items []BatchItem, haltedNodes map[string]bool) (*Batch, error) {
+	txns, err := s.PrepareBatch(userID, items, haltedNodes)
+	if err != nil {
+		return nil, err
+	}
+	s.AddBatch(txns)
+	return s.GetBatch(txns[0].BatchID)
+}
+
+// GetBatch returns a batch with snapshots of its transactions in submission order
+func (s *TransactionStore) GetBatch(batchID string) (*Batch, error) {
+	s.mu.RLock()
+	ids := append([]string(nil), s.batches[batchID]...)
+	s.mu.RUnlock()
+
+	if len(ids) == 0 {
+		return nil, ErrBatchNotFound
+	}
+
+	batch := &Batch{ID: batchID, Transactions: make([]*Transaction, 0, len(ids))}
+	for _, id := range ids {
+		txn, err := s.Snapshot(id)
+		if err != nil {
+			return nil, err
+		}
+		batch.Transactions = append(batch.Transactions, txn)
+	}
+	batch.UserID = batch.Transactions[0].UserID
+	batch.CreatedAt = batch.Transactions[0].CreatedAt
+	return batch, nil
+}
+
+// Summary aggregates the batch's transaction statuses and amounts
+func (b *Batch) Summary() BatchSummary {
+	summary := BatchSummary{BatchID: b.ID, Total: len(b.Transactions)}
+	for _, txn := range b.Transactions {
+		summary.TotalAmount += txn.Amount
+		summary.TotalFees += txn.TotalFees
+		switch txn.Status {
+		case StatusPending:
+			summary.Pending++
+		case StatusPendingReview:
+			summary.HeldForReview++
+		case StatusProcessing, StatusNetting, StatusInterrupted:
+			summary.Processing++
+		case StatusSuccess:
+			summary.Succeeded++
+			summary.TotalFinalAmount += txn.FinalAmount
+		case StatusFailed:
+			summary.Failed++
+		}
+	}
+	summary.Complete = summary.Pending == 0 && summary.HeldForReview == 0 && summary.Processing == 0
+	return summary
+}
@@ -0,0 +1,72 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCreateBatchIsAllOrNothing(t *testing.T) {
+	store := NewTransactionStore()
+
+	_, err := store.CreateBatch("user1", []BatchItem{
+		{Amount: 100, Currency: "USD", TargetCurrency: "GBP", Route: []string{"USA", "GBR"}},
+		{Amount: 50, Currency: "USD", TargetCurrency: "EUR", Route: []string{"USA"}},
+	}, nil)
+	if err == nil {
+		t.Fatal("expected invalid route to reject the batch")
+	}
+	if n := len(store.GetUserTransactions("user1")); n != 0 {
+		t.Fatalf("expected no transactions from a rejected batch, got %d", n)
+	}
+
+	tooMany := make([]BatchItem, MaxBatchSize+1)
+	if _, err := store.CreateBatch("user1", tooMany, nil); err == nil {
+		t.Fatal("expected oversized batch to be rejected")
+	}
+
+	batch, err := store.CreateBatch("user1", []BatchItem{
+		{Amount: 100, Currency: "USD", TargetCurrency: "GBP", Route: []string{"USA", "GBR"}},
+		{Amount: 50, Currency: "USD", TargetCurrency: "EUR", Route: []string{"USA", "GBR", "DEU"}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	if len(batch.Transactions) != 2 || batch.UserID != "user1" {
+		t.Fatalf("unexpected batch: %+v", batch)
+	}
+	for _, txn := range batch.Transactions {
+		if txn.BatchID != batch.ID || txn.Status != StatusPending {
+			t.Errorf("transaction %s: batch %q status %s", txn.ID, txn.BatchID, txn.Status)
+		}
+	}
+
+	if _, err := store.GetBatch("batch_missing"); !errors.Is(err, ErrBatchNotFound) {
+		t.Errorf("expected ErrBatchNotFound, got %v", err)
+	}
+}
+
+func TestBatchSummary(t *testing.T) {
+	store := NewTransactionStore()
+	batch, err := store.CreateBatch("user1", []BatchItem{
+		{Amount: 100, Route: []string{"USA", "GBR"}},
+		{Amount: 200, Route: []string{"USA", "GBR"}},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summary := batch.Summary()
+	if summary.Total != 2 || summary.Pending != 2 || summary.Complete || summary.TotalAmount != 300 {
+		t.Fatalf("unexpected summary before processing: %+v", summary)
+	}
+
+	for _, txn := range batch.Transactions {
+		store.ProcessTransaction(context.Background(), txn.ID, nil, 0)
+	}
+	batch, _ = store.GetBatch(batch.ID)
+	summary = batch.Summary()
+	if !summary.Complete || summary.Succeeded+summary.Failed != 2 {
+		t.Fatalf("unexpected summary after processing: %+v", summary)
+	}
+}
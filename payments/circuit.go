@@ -0,0 +1,120 @@
+package payments
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+)
+
+// circuitTimeout bounds each circuit breaker call made while processing
+const circuitTimeout = time.Second
+
+// CircuitBreaker is the per-node breaker consulted while processing hops.
+// Satisfied by storage/redis.CircuitBreaker; circuits are named by country code.
+type CircuitBreaker interface {
+	GetState(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) (*redisClient.CircuitState, error)
+	Allow(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error
+	RecordSuccess(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error
+	RecordFailure(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error
+}
+
+// Compile-time interface check
+var _ CircuitBreaker = (*redisClient.CircuitBreaker)(nil)
+
+// SetCircuitBreaker enables per-node circuit breaking during processing
+func (s *TransactionStore) SetCircuitBreaker(cb CircuitBreaker) {
+	s.breaker = cb
+}
+
+// SetCircuitCallback sets the callback for circuit state changes and half-open probes
+func (s *TransactionStore) SetCircuitCallback(cb func(nodeID string, prev, state redisClient.State)) {
+	s.onCircuitChange = cb
+}
+
+// OpenCircuit returns the first node in route whose circuit is open, or "" if none.
+// Breaker errors are treated as closed so a Redis outage doesn't halt payments.
+func (s *TransactionStore) OpenCircuit(ctx context.Context, route []string) string {
+	for _, nodeID := range route {
+		if state, ok := s.circuitState(ctx, nodeID); ok && state == redisClient.StateOpen {
+			return nodeID
+		}
+	}
+	return ""
+}
+
+// checkRouteCircuits is OpenCircuit for a route about to be processed.
+// Half-open nodes are let through as probes and reported to the circuit callback.
+func (s *TransactionStore) checkRouteCircuits(ctx context.Context, route []string) string {
+	for _, nodeID := range route {
+		state, ok := s.circuitState(ctx, nodeID)
+		if !ok {
+			continue
+		}
+		switch state {
+		case redisClient.StateOpen:
+			return nodeID
+		case redisClient.StateHalfOpen:
+			slog.InfoContext(ctx, "half-open circuit probe", "node", nodeID)
+			s.notifyCircuit(nodeID, redisClient.StateOpen, redisClient.StateHalfOpen)
+		}
+	}
+	return ""
+}
+
+// circuitState reads a node's circuit; ok is false without a breaker or on error
+func (s *TransactionStore) circuitState(ctx context.Context, nodeID string) (redisClient.State, bool) {
+	if s.breaker == nil {
+		return redisClient.StateClosed, false
+	}
+	ctx, cancel := context.WithTimeout(ctx, circuitTimeout)
+	defer cancel()
+
+	cs, err := s.breaker.GetState(ctx, redisClient.DefaultCircuitBreakerConfig(nodeID))
+	if err != nil {
+		slog.WarnContext(ctx, "circuit check failed", "node", nodeID, "error", err)
+		return redisClient.StateClosed, false
+	}
+	return cs.State, true
+}
+
+// recordHop records a hop outcome on the target node's circuit and reports any state change.
+// Recording outlives ctx's cancellation so a timed-out payment still counts as a failure.
+func (s *TransactionStore) recordHop(ctx context.Context, nodeID string, success bool) {
+	if s.breaker == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(logging.Detach(ctx), 3*circuitTimeout)
+	defer cancel()
+
+	cfg := redisClient.DefaultCircuitBreakerConfig(nodeID)
+	before, ok := s.circuitState(ctx, nodeID)
+	if !ok {
+		return
+	}
+
+	var err error
+	if success {
+		err = s.breaker.RecordSuccess(ctx, cfg)
+	} else {
+		err = s.breaker.RecordFailure(ctx, cfg)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to record circuit outcome", "node", nodeID, "error", err)
+		return
+	}
+
+	if after, ok := s.circuitState(ctx, nodeID); ok && after != before {
+		slog.InfoContext(ctx, "circuit state changed", "node", nodeID, "from", before, "to", after)
+		s.notifyCircuit(nodeID, before, after)
+	}
+}
+
+// notifyCircuit reports a circuit transition. Must be called without holding s.mu.
+func (s *TransactionStore) notifyCircuit(nodeID string, prev, state redisClient.State) {
+	if s.onCircuitChange != nil {
+		s.onCircuitChange(nodeID, prev, state)
+	}
+}
@@ -0,0 +1,112 @@
+package payments
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+)
+
+// fakeBreaker opens a node's circuit after threshold failures
+type fakeBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	failures  map[string]int
+	states    map[string]redisClient.State
+}
+
+func (f *fakeBreaker) GetState(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) (*redisClient.CircuitState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &redisClient.CircuitState{State: f.states[cfg.Name]}, nil
+}
+
+func (f *fakeBreaker) Allow(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	return nil
+}
+
+func (f *fakeBreaker) RecordSuccess(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.states[cfg.Name] == redisClient.StateHalfOpen {
+		f.states[cfg.Name] = redisClient.StateClosed
+	}
+	return nil
+}
+
+func (f *fakeBreaker) RecordFailure(ctx context.Context, cfg *redisClient.CircuitBreakerConfig) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[cfg.Name]++
+	if f.failures[cfg.Name] >= f.threshold {
+		f.states[cfg.Name] = redisClient.StateOpen
+	}
+	return nil
+}
+
+type circuitChange struct {
+	node        string
+	prev, state redisClient.State
+}
+
+func TestFailedHopsOpenCircuitAndBlockRoute(t *testing.T) {
+	breaker := &fakeBreaker{threshold: 2, failures: make(map[string]int), states: make(map[string]redisClient.State)}
+	store := NewTransactionStore()
+	store.SetCircuitBreaker(breaker)
+
+	var mu sync.Mutex
+	var changes []circuitChange
+	store.SetCircuitCallback(func(nodeID string, prev, state redisClient.State) {
+		mu.Lock()
+		defer mu.Unlock()
+		changes = append(changes, circuitChange{nodeID, prev, state})
+	})
+
+	ctx := context.Background()
+	route := []string{"USA", "GBR"}
+
+	// Every hop fails, so GBR's circuit opens on the second failure
+	for i := 0; i < 2; i++ {
+		txn, _ := store.CreateTransaction("user-1", 100, "USD", "GBP", route, nil)
+		if err := store.ProcessTransaction(ctx, txn.ID, nil, 1.0); err == nil {
+			t.Fatalf("attempt %d: expected hop failure", i+1)
+		}
+	}
+	if len(changes) != 1 || changes[0] != (circuitChange{"GBR", redisClient.StateClosed, redisClient.StateOpen}) {
+		t.Fatalf("expected a single GBR closed→open change, got %+v", changes)
+	}
+	if got := store.OpenCircuit(ctx, []string{"USA", "SGP", "GBR"}); got != "GBR" {
+		t.Fatalf("OpenCircuit = %q, want GBR", got)
+	}
+
+	// Open circuit blocks the route without attempting any hop
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "GBP", route, nil)
+	err := store.ProcessTransaction(ctx, txn.ID, nil, 0)
+	if err == nil || !strings.Contains(err.Error(), "circuit open") {
+		t.Fatalf("expected circuit open error, got %v", err)
+	}
+	blocked, _ := store.GetTransaction(txn.ID)
+	if blocked.Status != StatusFailed || blocked.FailedAt != "GBR" || len(blocked.HopResults) != 0 {
+		t.Fatalf("unexpected blocked transaction: status=%s failed_at=%s hops=%d", blocked.Status, blocked.FailedAt, len(blocked.HopResults))
+	}
+
+	// Half-open probe is reported, and its success closes the circuit
+	breaker.mu.Lock()
+	breaker.states["GBR"] = redisClient.StateHalfOpen
+	breaker.mu.Unlock()
+	changes = nil
+
+	txn, _ = store.CreateTransaction("user-1", 100, "USD", "GBP", route, nil)
+	if err := store.ProcessTransaction(ctx, txn.ID, nil, 0); err != nil {
+		t.Fatalf("probe failed: %v", err)
+	}
+	want := []circuitChange{
+		{"GBR", redisClient.StateOpen, redisClient.StateHalfOpen},
+		{"GBR", redisClient.StateHalfOpen, redisClient.StateClosed},
+	}
+	if len(changes) != 2 || changes[0] != want[0] || changes[1] != want[1] {
+		t.Fatalf("expected probe then close, got %+v", changes)
+	}
+}
@@ -0,0 +1,170 @@
+package payments
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// DisputeWindow is how long after completion a payment can be disputed
+const DisputeWindow = 120 * 24 * time.Hour
+
+var (
+	// ErrNotDisputable is returned when disputing a payment that did not
+	// complete or completed outside the dispute window
+	ErrNotDisputable = errors.New("only payments completed in the last 120 days can be disputed")
+	// ErrDisputeExists is returned when disputing a payment a second time
+	ErrDisputeExists = errors.New("payment has already been disputed")
+	// ErrNoOpenDispute is returned when resolving a payment without an open dispute
+	ErrNoOpenDispute = errors.New("payment has no open dispute")
+	// ErrInvalidDisputeReason is returned for unknown dispute reasons
+	ErrInvalidDisputeReason = errors.New("reason must be not_received, incorrect_amount, unauthorized, duplicate or other")
+)
+
+// DisputeStatus is where a dispute is in its lifecycle
+type DisputeStatus string
+
+const (
+	DisputeOpen     DisputeStatus = "open"
+	DisputeAccepted DisputeStatus = "accepted" // The payment was refunded
+	DisputeRejected DisputeStatus = "rejected"
+)
+
+// DisputeReason is why the payer disputes a payment
+type DisputeReason string
+
+const (
+	DisputeNotReceived     DisputeReason = "not_received"
+	DisputeIncorrectAmount DisputeReason = "incorrect_amount"
+	DisputeUnauthorized    DisputeReason = "unauthorized"
+	DisputeDuplicate       DisputeReason = "duplicate"
+	DisputeOther           DisputeReason = "other"
+)
+
+// Valid reports whether r is a known dispute reason
+func (r DisputeReason) Valid() bool {
+	switch r {
+	case DisputeNotReceived, DisputeIncorrectAmount, DisputeUnauthorized, DisputeDuplicate, DisputeOther:
+		return true
+	}
+	return false
+}
+
+// DisputeTransition records one change of a dispute's status
+type DisputeTransition struct {
+	Status DisputeStatus `json:"status"`
+	Actor  string        `json:"actor"` // Payer's user ID when opened, admin username when resolved
+	Note   string        `json:"note,omitempty"`
+	At     time.Time     `json:"at"`
+}
+
+// Dispute is a payer's challenge of a completed payment
+type Dispute struct {
+	Status     DisputeStatus       `json:"status"`
+	Reason     DisputeReason       `json:"reason"`
+	Details    string              `json:"details,omitempty"`
+	OpenedAt   time.Time           `json:"opened_at"`
+	ResolvedBy string              `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time          `json:"resolved_at,omitempty"`
+	RefundID   string              `json:"refund_id,omitempty"` // Stripe refund issued when accepted, also recorded as the transaction's Refund
+	History    []DisputeTransition `json:"history"`
+}
+
+// OpenDispute opens a dispute against a successful payment completed within
+// DisputeWindow. Each payment can be disputed once.
+func (s *TransactionStore) OpenDispute(txnID, userID string, reason DisputeReason, details string) (*Transaction, error) {
+	if !reason.Valid() {
+		return nil, ErrInvalidDisputeReason
+	}
+
+	s.mu.Lock()
+	txn, ok := s.transactions[txnID]
+	if !ok || txn.UserID != userID {
+		s.mu.Unlock()
+		return nil, errors.New("transaction not found")
+	}
+	if txn.Dispute != nil {
+		s.mu.Unlock()
+		return nil, ErrDisputeExists
+	}
+	now := time.Now()
+	if txn.Status != StatusSuccess || txn.CompletedAt == nil || now.Sub(*txn.CompletedAt) > DisputeWindow {
+		s.mu.Unlock()
+		return nil, ErrNotDisputable
+	}
+	txn.Dispute = &Dispute{
+		Status:   DisputeOpen,
+		Reason:   reason,
+		Details:  details,
+		OpenedAt: now,
+		History:  []DisputeTransition{{Status: DisputeOpen, Actor: userID, Note: details, At: now}},
+	}
+	s.mu.Unlock()
+
+	return s.Snapshot(txnID)
+}
+
+// ResolveDispute closes an open dispute. Accepting it records refund, the
+// Stripe refund the caller issued, on the transaction so it counts towards
+// refund stats and receipts; rejecting it leaves the payment as is.
+func (s *TransactionStore) ResolveDispute(txnID string, decision DisputeStatus, reviewer, note string, refund *Refund) (*Transaction, error) {
+	if decision != DisputeAccepted && decision != DisputeRejected {
+		return nil, errors.New("decision must be accepted or rejected")
+	}
+
+	s.mu.Lock()
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, errors.New("transaction not found")
+	}
+	if txn.Dispute == nil || txn.Dispute.Status != DisputeOpen {
+		s.mu.Unlock()
+		return nil, ErrNoOpenDispute
+	}
+
+	now := time.Now()
+	dispute := *txn.Dispute
+	dispute.Status, dispute.ResolvedBy, dispute.ResolvedAt = decision, reviewer, &now
+	dispute.History = append(append([]DisputeTransition(nil), dispute.History...),
+		DisputeTransition{Status: decision, Actor: reviewer, Note: note, At: now})
+	if decision == DisputeAccepted && refund != nil {
+		r := *refund
+		if r.RefundedAt.IsZero() {
+			r.RefundedAt = now
+		}
+		dispute.RefundID = r.ID
+		txn.Refund = &r
+	}
+	txn.Dispute = &dispute
+	s.countLocked(txn)
+	s.mu.Unlock()
+
+	return s.Snapshot(txnID)
+}
+
+// Disputes returns disputed transactions with the given dispute status (""
+// for all), oldest dispute first
+func (s *TransactionStore) Disputes(status DisputeStatus) []*Transaction {
+	s.mu.RLock()
+	disputed := make([]*Transaction, 0)
+	for _, txn := range s.transactions {
+		if txn.Dispute != nil && (status == "" || txn.Dispute.Status == status) {
+			disputed = append(disputed, txn)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(disputed, func(i, j int) bool { return disputed[i].Dispute.OpenedAt.Before(disputed[j].Dispute.OpenedAt) })
+	return disputed
+}
+
+// SetPaymentIntent records the Stripe PaymentIntent that paid for a
+// transaction, so it can be refunded later
+func (s *TransactionStore) SetPaymentIntent(txnID, paymentIntentID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if txn, ok := s.transactions[txnID]; ok {
+		txn.PaymentIntentID = paymentIntentID
+	}
+}
@@ -0,0 +1,65 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDisputeLifecycle(t *testing.T) {
+	store := NewTransactionStore()
+	route := []string{"USA", "GBR"}
+
+	pending, _ := store.CreateTransaction("user-1", 100, "USD", "GBP", route, nil)
+	if _, err := store.OpenDispute(pending.ID, "user-1", DisputeNotReceived, ""); !errors.Is(err, ErrNotDisputable) {
+		t.Errorf("disputing a pending payment: err = %v, want ErrNotDisputable", err)
+	}
+
+	paid, _ := store.CreateTransaction("user-1", 250, "USD", "GBP", route, nil)
+	if err := store.ProcessTransaction(context.Background(), paid.ID, nil, 0); err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	if _, err := store.OpenDispute(paid.ID, "user-2", DisputeUnauthorized, ""); err == nil {
+		t.Error("another user disputed the payment")
+	}
+	if _, err := store.OpenDispute(paid.ID, "user-1", "changed_mind", ""); !errors.Is(err, ErrInvalidDisputeReason) {
+		t.Errorf("unknown reason: err = %v, want ErrInvalidDisputeReason", err)
+	}
+	opened, err := store.OpenDispute(paid.ID, "user-1", DisputeNotReceived, "recipient says nothing arrived")
+	if err != nil {
+		t.Fatalf("OpenDispute: %v", err)
+	}
+	if opened.Dispute.Status != DisputeOpen || len(opened.Dispute.History) != 1 {
+		t.Errorf("dispute = %+v, want open with one transition", opened.Dispute)
+	}
+	if _, err := store.OpenDispute(paid.ID, "user-1", DisputeDuplicate, ""); !errors.Is(err, ErrDisputeExists) {
+		t.Errorf("disputing twice: err = %v, want ErrDisputeExists", err)
+	}
+	if queue := store.Disputes(DisputeOpen); len(queue) != 1 || queue[0].ID != paid.ID {
+		t.Errorf("open disputes = %v, want the paid transaction", queue)
+	}
+
+	refund := DefaultRefundPolicy().Refund(paid, RefundDisputed)
+	refund.ID = "re_123"
+	resolved, err := store.ResolveDispute(paid.ID, DisputeAccepted, "treasurer", "confirmed with recipient bank", &refund)
+	if err != nil {
+		t.Fatalf("ResolveDispute: %v", err)
+	}
+	d := resolved.Dispute
+	if d.Status != DisputeAccepted || d.RefundID != "re_123" || d.ResolvedBy != "treasurer" || len(d.History) != 2 {
+		t.Errorf("resolved dispute = %+v, want accepted with the refund and two transitions", d)
+	}
+	if r := resolved.Refund; r == nil || r.ID != "re_123" || r.Amount != 250 || r.Reason != RefundDisputed || r.RefundedAt.IsZero() {
+		t.Errorf("transaction refund = %+v, want the dispute refund of 250", r)
+	}
+	if stats := store.PlatformStats(""); stats.Refunds != 1 || !approxEqual(stats.RefundedAmount, 250) {
+		t.Errorf("refund stats = %d refunds of %v, want 1 of 250", stats.Refunds, stats.RefundedAmount)
+	}
+	assertStats(t, store.PlatformStats(""), scanStats(store.RecentTransactions("", 10)))
+	if _, err := store.ResolveDispute(paid.ID, DisputeRejected, "treasurer", "", nil); !errors.Is(err, ErrNoOpenDispute) {
+		t.Errorf("resolving twice: err = %v, want ErrNoOpenDispute", err)
+	}
+	if len(store.Disputes(DisputeOpen)) != 0 || len(store.Disputes("")) != 1 {
+		t.Error("accepted dispute should leave the open queue but stay listed")
+	}
+}
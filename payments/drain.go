@@ -0,0 +1,149 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+// interruptGrace is how long Drain waits, after its deadline, for processing
+// to stop at the next hop before marking what is left interrupted as is
+const interruptGrace = 2 * time.Second
+
+// interruptedReason is recorded on the attempt a shutdown interrupted
+const interruptedReason = "interrupted by shutdown"
+
+// ErrShuttingDown is returned for processing refused or interrupted because
+// the server is shutting down. Interrupted transactions are left
+// StatusInterrupted for ResumeInterrupted on the next startup.
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// ErrNotInterrupted is returned when resuming a transaction that wasn't interrupted
+var ErrNotInterrupted = errors.New("transaction was not interrupted")
+
+// Draining reports whether Drain has been called; new processing is refused
+func (s *TransactionStore) Draining() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.draining
+}
+
+// Drain stops new processing and waits for the transactions being processed
+// to finish. If ctx expires first, processing still running is stopped at its
+// next hop and left StatusInterrupted. Returns the IDs of the interrupted
+// transactions, and ctx's error if any were.
+func (s *TransactionStore) Drain(ctx context.Context) ([]string, error) {
+	s.mu.Lock()
+	s.draining = true
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil, nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	ids := make([]string, 0, len(s.processing))
+	for txnID, cancel := range s.processing {
+		ids = append(ids, txnID)
+		cancel(ErrShuttingDown)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-time.After(interruptGrace):
+	}
+
+	// Transactions whose holder hasn't stopped (or was between attempts) are
+	// marked interrupted as they are
+	s.mu.Lock()
+	interrupted := make([]string, 0, len(ids))
+	for _, txnID := range ids {
+		txn, ok := s.transactions[txnID]
+		if !ok {
+			continue
+		}
+		if txn.Status == StatusPending || txn.Status == StatusProcessing {
+			txn.Status = StatusInterrupted
+			s.countLocked(txn)
+		}
+		if txn.Status == StatusInterrupted {
+			interrupted = append(interrupted, txnID)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Strings(interrupted)
+	if len(interrupted) == 0 {
+		return nil, nil
+	}
+	return interrupted, fmt.Errorf("%d transactions interrupted: %w", len(interrupted), ctx.Err())
+}
+
+// beginProcessing registers a processing lock for Drain, which cancels ctx
+// with ErrShuttingDown if the lock is still held at its deadline. Refused
+// once draining.
+func (s *TransactionStore) beginProcessing(txnID string, cancel context.CancelCauseFunc) (func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.draining {
+		return nil, ErrShuttingDown
+	}
+	s.inflight.Add(1)
+	s.processing[txnID] = cancel
+	return func() {
+		s.mu.Lock()
+		delete(s.processing, txnID)
+		s.mu.Unlock()
+		s.inflight.Done()
+	}, nil
+}
+
+// interruptIfShuttingDown marks a transaction interrupted if ctx was
+// cancelled by Drain, reporting whether it was. Settled hops are kept for
+// ResumeInterrupted to compensate.
+func (s *TransactionStore) interruptIfShuttingDown(ctx context.Context, txnID string) bool {
+	if !errors.Is(context.Cause(ctx), ErrShuttingDown) {
+		return false
+	}
+	s.mu.Lock()
+	if txn, ok := s.transactions[txnID]; ok {
+		txn.Status = StatusInterrupted
+		s.countLocked(txn)
+	}
+	s.mu.Unlock()
+	slog.WarnContext(ctx, "transaction interrupted by shutdown", "transaction_id", txnID)
+	return true
+}
+
+// InterruptedTransactions returns the transactions a shutdown interrupted, oldest first
+func (s *TransactionStore) InterruptedTransactions() []*Transaction {
+	s.mu.RLock()
+	interrupted := make([]*Transaction, 0)
+	for _, txn := range s.transactions {
+		if txn.Status == StatusInterrupted {
+			interrupted = append(interrupted, txn)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(interrupted, func(i, j int) bool { return interrupted[i].CreatedAt.Before(interrupted[j].CreatedAt) })
+	return interrupted
+}
+
+// ResumeInterrupted prepares an interrupted transaction to be processed
+// again: the hops it settled are compensated, the interrupted run is recorded
+// as an attempt and the transaction is reset to pending.
+func (s *TransactionStore) ResumeInterrupted(ctx context.Context, txnID string) error {
+	return s.requeue(ctx, txnID, interruptedReason, ErrNotInterrupted, StatusInterrupted)
+}
@@ -0,0 +1,118 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// slowHops makes every settled hop take delay, signalling the first one
+type slowHops struct {
+	delay   time.Duration
+	started chan struct{}
+	once    sync.Once
+}
+
+func (p *slowHops) PublishSettlementEvent(ctx context.Context, event *natsClient.SettlementEvent) error {
+	if event.EventType == natsClient.SettlementHopComplete {
+		p.once.Do(func() { close(p.started) })
+		time.Sleep(p.delay)
+	}
+	return nil
+}
+
+// startSlowPayment starts processing a four-country payment whose hops each
+// take delay, returning once the first hop settled
+func startSlowPayment(t *testing.T, store *TransactionStore, delay time.Duration) (*Transaction, <-chan error) {
+	t.Helper()
+	publisher := &slowHops{delay: delay, started: make(chan struct{})}
+	store.SetEventPublisher(publisher)
+	txn, err := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR", "DEU", "FRA"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- store.ProcessTransaction(context.Background(), txn.ID, nil, 0) }()
+	<-publisher.started
+	return txn, done
+}
+
+// TestDrainWaitsForInFlightProcessing verifies Drain refuses new processing
+// and returns once the running payment settles
+func TestDrainWaitsForInFlightProcessing(t *testing.T) {
+	store := NewTransactionStore()
+	txn, done := startSlowPayment(t, store, 20*time.Millisecond)
+	other, _ := store.CreateTransaction("user-2", 50, "USD", "USD", []string{"USA", "GBR"}, nil)
+
+	drained := make(chan error, 1)
+	go func() {
+		_, err := store.Drain(context.Background())
+		drained <- err
+	}()
+	for !store.Draining() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := store.ProcessTransaction(context.Background(), other.ID, nil, 0); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected new processing refused while draining, got %v", err)
+	}
+
+	if err := <-drained; err != nil {
+		t.Fatalf("Drain: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	if snap, _ := store.Snapshot(txn.ID); snap.Status != StatusSuccess {
+		t.Errorf("expected the in-flight payment to finish, got %s", snap.Status)
+	}
+}
+
+// TestDrainDeadlineInterruptsAndResumeCompensates verifies processing still
+// running at the drain deadline is left interrupted, and resuming it reverses
+// the settled hops and makes it pending again
+func TestDrainDeadlineInterruptsAndResumeCompensates(t *testing.T) {
+	store := NewTransactionStore()
+	var reversed []string
+	store.SetCompensator(func(ctx context.Context, txn *Transaction, step SagaStep) error {
+		reversed = append(reversed, step.ToCountry+"->"+step.FromCountry)
+		return nil
+	})
+	txn, done := startSlowPayment(t, store, 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	interrupted, err := store.Drain(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || len(interrupted) != 1 || interrupted[0] != txn.ID {
+		t.Fatalf("expected %s interrupted at the deadline, got %v (%v)", txn.ID, interrupted, err)
+	}
+	if err := <-done; !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected processing stopped with ErrShuttingDown, got %v", err)
+	}
+	snap, _ := store.Snapshot(txn.ID)
+	if snap.Status != StatusInterrupted || snap.HopsCompleted == 0 || len(snap.Compensations) != 0 {
+		t.Fatalf("expected interrupted with settled hops kept, got %s after %d hops", snap.Status, snap.HopsCompleted)
+	}
+	settled := snap.HopsCompleted
+	if list := store.InterruptedTransactions(); len(list) != 1 {
+		t.Errorf("expected 1 interrupted transaction, got %d", len(list))
+	}
+
+	store.draining = false // As after a restart
+	if err := store.ResumeInterrupted(context.Background(), txn.ID); err != nil {
+		t.Fatalf("ResumeInterrupted: %v", err)
+	}
+	snap, _ = store.Snapshot(txn.ID)
+	if snap.Status != StatusPending || snap.HopsCompleted != 0 || len(snap.Attempts) != 1 {
+		t.Fatalf("expected pending with the interrupted run recorded, got %s with %d attempts", snap.Status, len(snap.Attempts))
+	}
+	if len(snap.Compensations) != 1 || len(reversed) != settled || reversed[len(reversed)-1] != "GBR->USA" {
+		t.Errorf("expected settled hops reversed, got %v", reversed)
+	}
+	if err := store.ResumeInterrupted(context.Background(), txn.ID); !errors.Is(err, ErrNotInterrupted) {
+		t.Errorf("expected ErrNotInterrupted resuming twice, got %v", err)
+	}
+}
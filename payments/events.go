@@ -0,0 +1,78 @@
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// publishTimeout bounds how long a lifecycle event publish may delay processing
+const publishTimeout = 2 * time.Second
+
+// EventPublisher publishes settlement lifecycle events.
+// Implemented by the NATS client, which writes them to the SETTLEMENT_EVENTS stream.
+type EventPublisher interface {
+	PublishSettlementEvent(ctx context.Context, event *natsClient.SettlementEvent) error
+}
+
+// settlementEventTypes maps status callback events to settlement event types
+var settlementEventTypes = map[StatusEvent]string{
+	EventPaymentSucceeded: natsClient.SettlementCompleted,
+	EventPaymentFailed:    natsClient.SettlementFailed,
+	EventPaymentRefunded:  natsClient.SettlementRefunded,
+}
+
+// SetEventPublisher enables publishing of settlement lifecycle events
+func (s *TransactionStore) SetEventPublisher(p EventPublisher) {
+	s.publisher = p
+}
+
+// generateEventID generates a unique settlement event ID
+func generateEventID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return "evt_" + hex.EncodeToString(bytes)
+}
+
+// publishEvent publishes a lifecycle event built from a snapshot of the
+// transaction. Failures are logged and never fail the payment.
+// Must be called without holding s.mu.
+func (s *TransactionStore) publishEvent(eventType, txnID string) {
+	if s.publisher == nil {
+		return
+	}
+	txn, err := s.Snapshot(txnID)
+	if err != nil {
+		return
+	}
+
+	event := &natsClient.SettlementEvent{
+		EventID:    generateEventID(),
+		RequestID:  txn.ID,
+		EventType:  eventType,
+		UserID:     txn.UserID,
+		Amount:     txn.Amount,
+		Currency:   txn.Currency,
+		Path:       txn.Route,
+		CurrentHop: txn.HopsCompleted,
+		Status:     string(txn.Status),
+		Timestamp:  time.Now(),
+	}
+	if len(txn.Route) > 0 {
+		event.SourceID = txn.Route[0]
+		event.TargetID = txn.Route[len(txn.Route)-1]
+	}
+	if eventType == natsClient.SettlementFailed && len(txn.Attempts) > 0 {
+		event.ErrorMessage = txn.Attempts[len(txn.Attempts)-1].Error
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+	defer cancel()
+	if err := s.publisher.PublishSettlementEvent(ctx, event); err != nil {
+		slog.Warn("failed to publish settlement event", "event", eventType, "txn_id", txnID, "error", err)
+	}
+}
@@ -0,0 +1,79 @@
+package payments
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// recordingPublisher keeps published settlement events in order
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []*natsClient.SettlementEvent
+}
+
+func (p *recordingPublisher) PublishSettlementEvent(ctx context.Context, event *natsClient.SettlementEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) types() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	types := make([]string, len(p.events))
+	for i, e := range p.events {
+		types[i] = e.EventType
+	}
+	return types
+}
+
+func TestTransactionLifecycleEvents(t *testing.T) {
+	publisher := &recordingPublisher{}
+	store := NewTransactionStore()
+	store.SetEventPublisher(publisher)
+
+	txn, err := store.CreateTransaction("user-1", 100, "USD", "INR", []string{"USA", "SGP", "IND"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.ProcessTransaction(context.Background(), txn.ID, nil, 0); err != nil {
+		t.Fatal(err)
+	}
+	store.MarkAsRefunded(txn.ID, Refund{ID: "re_123"})
+
+	want := []string{
+		natsClient.SettlementCreated,
+		natsClient.SettlementHopComplete,
+		natsClient.SettlementHopComplete,
+		natsClient.SettlementCompleted,
+		natsClient.SettlementRefunded,
+	}
+	if got := publisher.types(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+
+	hop := publisher.events[2]
+	if hop.RequestID != txn.ID || hop.UserID != "user-1" || hop.CurrentHop != 2 {
+		t.Errorf("hop event = %+v, want second hop of %s for user-1", hop, txn.ID)
+	}
+	if hop.SourceID != "USA" || hop.TargetID != "IND" {
+		t.Errorf("hop event source/target = %s/%s, want USA/IND", hop.SourceID, hop.TargetID)
+	}
+
+	// A payment that fails before entering the mesh reports why
+	declined, err := store.CreateTransaction("user-1", 50, "USD", "INR", []string{"USA", "IND"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.MarkPaymentFailed(declined.ID, "card declined")
+
+	last := publisher.events[len(publisher.events)-1]
+	if last.EventType != natsClient.SettlementFailed || last.ErrorMessage != "card declined" {
+		t.Errorf("failed event = %+v, want card declined failure", last)
+	}
+}
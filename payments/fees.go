@@ -0,0 +1,110 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// FeeSchedule is one published version of the fee rates. Every transaction
+// records the version it was priced under.
+type FeeSchedule struct {
+	Version int `json:"version"`
+	FeeConfig
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by,omitempty"` // Admin username, or "config" for the seeded schedule
+}
+
+// Validate checks the fee rates
+func (f FeeConfig) Validate() error {
+	switch {
+	case f.BaseFeePercent < 0 || f.HopFeePercent < 0 || f.HaltFinePercent < 0:
+		return fmt.Errorf("fee rates must not be negative")
+	case f.BaseFeePercent >= 1 || f.HopFeePercent >= 1 || f.HaltFinePercent >= 1:
+		return fmt.Errorf("fee rates are fractions (0.015 = 1.5%%)")
+	}
+	return nil
+}
+
+// FeeScheduleStore keeps every published fee schedule. Implemented by
+// MemoryFeeSchedules and the Postgres-backed store in storage/postgres.
+type FeeScheduleStore interface {
+	// Current returns the latest schedule, or nil if none has been published
+	Current(ctx context.Context) (*FeeSchedule, error)
+	// Publish saves fees as the next version
+	Publish(ctx context.Context, fees FeeConfig, createdBy string) (*FeeSchedule, error)
+	// List returns up to limit schedules, newest first
+	List(ctx context.Context, limit int) ([]*FeeSchedule, error)
+}
+
+// ActiveFeeSchedule returns the latest schedule in store, publishing seed as
+// version 1 when the store is empty
+func ActiveFeeSchedule(ctx context.Context, store FeeScheduleStore, seed FeeConfig) (*FeeSchedule, error) {
+	current, err := store.Current(ctx)
+	if err != nil || current != nil {
+		return current, err
+	}
+	return store.Publish(ctx, seed, "config")
+}
+
+// MemoryFeeSchedules is an in-memory FeeScheduleStore
+type MemoryFeeSchedules struct {
+	mu        sync.RWMutex
+	schedules []*FeeSchedule
+}
+
+// NewMemoryFeeSchedules creates an empty schedule store
+func NewMemoryFeeSchedules() *MemoryFeeSchedules {
+	return &MemoryFeeSchedules{}
+}
+
+// Current returns the latest schedule, or nil if none has been published
+func (m *MemoryFeeSchedules) Current(ctx context.Context) (*FeeSchedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if len(m.schedules) == 0 {
+		return nil, nil
+	}
+	cp := *m.schedules[len(m.schedules)-1]
+	return &cp, nil
+}
+
+// Publish saves fees as the next version
+func (m *MemoryFeeSchedules) Publish(ctx context.Context, fees FeeConfig, createdBy string) (*FeeSchedule, error) {
+	if err := fees.Validate(); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	schedule := &FeeSchedule{
+		Version:   len(m.schedules) + 1,
+		FeeConfig: fees,
+		CreatedAt: time.Now().UTC(),
+		CreatedBy: createdBy,
+	}
+	m.schedules = append(m.schedules, schedule)
+	cp := *schedule
+	return &cp, nil
+}
+
+// List returns up to limit schedules, newest first
+func (m *MemoryFeeSchedules) List(ctx context.Context, limit int) ([]*FeeSchedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]*FeeSchedule, 0)
+	for i := len(m.schedules) - 1; i >= 0 && (limit <= 0 || len(result) < limit); i-- {
+		cp := *m.schedules[i]
+		result = append(result, &cp)
+	}
+	return result, nil
+}
+
+// feeRates returns the rates the transaction was created under, or current
+// for transactions created before fee schedules were recorded
+func (t *Transaction) feeRates(current FeeConfig) FeeConfig {
+	if t.FeeRates != nil {
+		return *t.FeeRates
+	}
+	return current
+}
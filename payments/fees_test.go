@@ -0,0 +1,59 @@
+package payments
+
+import (
+	"context"
+	"testing"
+)
+
+func TestActiveFeeScheduleSeedsAndVersions(t *testing.T) {
+	ctx := context.Background()
+	schedules := NewMemoryFeeSchedules()
+
+	seeded, err := ActiveFeeSchedule(ctx, schedules, DefaultFeeConfig())
+	if err != nil || seeded.Version != 1 || seeded.CreatedBy != "config" {
+		t.Fatalf("seeded schedule = %+v, %v; want version 1 from config", seeded, err)
+	}
+	if again, _ := ActiveFeeSchedule(ctx, schedules, FeeConfig{BaseFeePercent: 0.5}); again.Version != 1 {
+		t.Errorf("existing schedule was replaced by the seed: %+v", again)
+	}
+
+	if _, err := schedules.Publish(ctx, FeeConfig{BaseFeePercent: -0.1}, "admin"); err == nil {
+		t.Error("expected negative rates to be rejected")
+	}
+	next, err := schedules.Publish(ctx, FeeConfig{BaseFeePercent: 0.02, HopFeePercent: 0.001}, "admin")
+	if err != nil || next.Version != 2 {
+		t.Fatalf("published schedule = %+v, %v; want version 2", next, err)
+	}
+	history, _ := schedules.List(ctx, 0)
+	if len(history) != 2 || history[0].Version != 2 || history[1].Version != 1 {
+		t.Errorf("history = %+v, want versions 2 then 1", history)
+	}
+}
+
+func TestTransactionKeepsFeeScheduleFromCreation(t *testing.T) {
+	store := NewTransactionStore()
+	store.SetFeeSchedule(&FeeSchedule{Version: 1, FeeConfig: DefaultFeeConfig()})
+
+	txn, err := store.CreateTransaction("user-1", 100, "USD", "GBP", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if txn.FeeScheduleVersion != 1 || txn.FeeRates == nil || *txn.FeeRates != DefaultFeeConfig() {
+		t.Fatalf("transaction fee schedule = v%d %+v, want v1 defaults", txn.FeeScheduleVersion, txn.FeeRates)
+	}
+
+	// A schedule published before processing does not reprice the transaction
+	store.SetFeeSchedule(&FeeSchedule{Version: 2, FeeConfig: FeeConfig{BaseFeePercent: 0.05, HopFeePercent: 0.01}})
+	if err := store.ProcessTransaction(context.Background(), txn.ID, nil, 0); err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	done, _ := store.GetTransaction(txn.ID)
+	if hop := done.HopResults[0]; !approxEqual(hop.HopFee, 100*DefaultFeeConfig().HopFeePercent) {
+		t.Errorf("hop fee = %v, want the creation-time rate", hop.HopFee)
+	}
+
+	later, _ := store.CreateTransaction("user-1", 100, "USD", "GBP", []string{"USA", "GBR"}, nil)
+	if later.FeeScheduleVersion != 2 || !approxEqual(later.BaseFee, 5) {
+		t.Errorf("new transaction = v%d base %v, want v2 base 5", later.FeeScheduleVersion, later.BaseFee)
+	}
+}
@@ -0,0 +1,61 @@
+package payments
+
+import (
+	"context"
+	"log/slog"
+)
+
+// fxConverter converts a payment hop by hop. Rates are keyed by country code
+// and quoted per USD (as on router.CountryNode), so each hop applies the cross
+// rate between the currency the funds are held in and the next country's.
+type fxConverter struct {
+	ctx   context.Context // For logging
+	rates map[string]float64
+	held  float64 // Per-USD rate of the currency the funds are held in (0 if unknown)
+	scale float64 // Source currency → held currency
+}
+
+// newFXConverter starts a conversion with funds held in the route's source currency
+func newFXConverter(ctx context.Context, rates map[string]float64, route []string) *fxConverter {
+	c := &fxConverter{ctx: ctx, rates: rates, scale: 1.0}
+	if len(route) > 0 {
+		c.held = rates[route[0]]
+	}
+	return c
+}
+
+// toHeld converts a source-currency amount into the currency the funds are held in
+func (c *fxConverter) toHeld(amount float64) float64 {
+	return amount * c.scale
+}
+
+// next returns the rate for moving funds into toCountry's currency.
+// A missing rate leaves the funds in their current currency (rate 1.0) rather than
+// failing the payment; fallback reports this when rates were supplied.
+func (c *fxConverter) next(fromCountry, toCountry string) (rate float64, fallback bool) {
+	target, ok := c.rates[toCountry]
+	if !ok || target <= 0 || c.held <= 0 {
+		if len(c.rates) > 0 {
+			slog.WarnContext(c.ctx, "no FX rate, hop settles without conversion", "from", fromCountry, "to", toCountry)
+			return 1.0, true
+		}
+		return 1.0, false
+	}
+
+	rate = target / c.held
+	c.held = target
+	c.scale *= rate
+	return rate, false
+}
+
+// EffectiveFXRate returns the overall source → target conversion applied by the
+// successful hops (1.0 if no conversion took place)
+func (t *Transaction) EffectiveFXRate() float64 {
+	rate := 1.0
+	for _, hop := range t.HopResults {
+		if hop.Success && hop.FXRate > 0 {
+			rate *= hop.FXRate
+		}
+	}
+	return rate
+}
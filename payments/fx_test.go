@@ -0,0 +1,77 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+)
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestProcessTransactionConvertsCurrencyPerHop(t *testing.T) {
+	store := NewTransactionStore()
+	rates := map[string]float64{"USA": 1.0, "DEU": 0.92, "GBR": 0.79}
+
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "GBP", []string{"USA", "DEU", "GBR"}, nil)
+	if err := store.ProcessTransaction(context.Background(), txn.ID, rates, 0); err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+	done, _ := store.GetTransaction(txn.ID)
+
+	hopFee := 100 * store.feeConfig.HopFeePercent
+	first, second := done.HopResults[0], done.HopResults[1]
+
+	// USA → DEU: fee in USD, output in EUR
+	if !approxEqual(first.FXRate, 0.92) || !approxEqual(first.AmountOut, (100-done.TotalFees-hopFee)*0.92) {
+		t.Errorf("unexpected first hop: %+v", first)
+	}
+	// DEU → GBR: cross rate, fee charged in EUR
+	if !approxEqual(second.FXRate, 0.79/0.92) || !approxEqual(second.HopFee, hopFee*0.92) {
+		t.Errorf("unexpected second hop: %+v", second)
+	}
+	if !approxEqual(second.AmountIn, first.AmountOut) || !approxEqual(done.FinalAmount, second.AmountOut) {
+		t.Errorf("final amount %v does not match last hop output %v", done.FinalAmount, second.AmountOut)
+	}
+	if !approxEqual(done.EffectiveFXRate(), 0.79) {
+		t.Errorf("effective rate = %v, want 0.79", done.EffectiveFXRate())
+	}
+}
+
+func TestProcessTransactionMissingRateFallsBack(t *testing.T) {
+	store := NewTransactionStore()
+	rates := map[string]float64{"USA": 1.0, "GBR": 0.79}
+
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "GBP", []string{"USA", "SGP", "GBR"}, nil)
+	if err := store.ProcessTransaction(context.Background(), txn.ID, rates, 0); err != nil {
+		t.Fatalf("ProcessTransaction failed: %v", err)
+	}
+	done, _ := store.GetTransaction(txn.ID)
+
+	// SGP has no rate, so funds stay in USD until GBR converts them
+	if hop := done.HopResults[0]; !hop.FXFallback || hop.FXRate != 1.0 {
+		t.Errorf("expected fallback on USA → SGP: %+v", hop)
+	}
+	if hop := done.HopResults[1]; hop.FXFallback || !approxEqual(hop.FXRate, 0.79) {
+		t.Errorf("expected USD → GBP conversion on SGP → GBR: %+v", hop)
+	}
+	if !approxEqual(done.EffectiveFXRate(), 0.79) {
+		t.Errorf("effective rate = %v, want 0.79", done.EffectiveFXRate())
+	}
+}
+
+func TestCreateTransactionRejectsUnknownCurrencies(t *testing.T) {
+	store := NewTransactionStore()
+	for _, currencies := range [][2]string{{"usd", "GBP"}, {"USD", "GBP' OR 1=1"}, {"XXX", "USD"}} {
+		if _, err := store.CreateTransaction("user-1", 100, currencies[0], currencies[1], []string{"USA", "GBR"}, nil); !errors.Is(err, refdata.ErrUnknownCurrency) {
+			t.Errorf("CreateTransaction(%q, %q) error = %v, want ErrUnknownCurrency", currencies[0], currencies[1], err)
+		}
+	}
+	if _, err := store.CreateTransaction("user-1", 100, "", "", []string{"USA", "GBR"}, nil); err != nil {
+		t.Errorf("currencies are optional: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+package payments
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// History sort fields
+const (
+	SortByCreatedAt   = "created_at"
+	SortByAmount      = "amount"
+	SortByFinalAmount = "final_amount"
+)
+
+// HistoryQuery filters, sorts and pages a user's transaction history
+type HistoryQuery struct {
+	Limit      int                 // Page size; 0 returns every match
+	Offset     int                 // Matches to skip
+	From       time.Time           // Created at or after (zero = unbounded)
+	To         time.Time           // Created before (zero = unbounded)
+	Statuses   []TransactionStatus // Empty matches every status
+	SortBy     string              // created_at (default), amount or final_amount
+	Descending bool
+}
+
+// Validate checks the query is well formed
+func (q HistoryQuery) Validate() error {
+	if q.Limit < 0 || q.Offset < 0 {
+		return fmt.Errorf("limit and offset must not be negative")
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.To.After(q.From) {
+		return fmt.Errorf("to must be after from")
+	}
+	switch q.SortBy {
+	case "", SortByCreatedAt, SortByAmount, SortByFinalAmount:
+	default:
+		return fmt.Errorf("sort must be one of created_at, amount, final_amount")
+	}
+	return nil
+}
+
+// HistoryPage is one page of a user's transaction history
+type HistoryPage struct {
+	Transactions []*Transaction `json:"transactions"`
+	Total        int            `json:"total"` // Matches before paging
+	Limit        int            `json:"limit"`
+	Offset       int            `json:"offset"`
+	HasMore      bool           `json:"has_more"`
+}
+
+// QueryUserTransactions returns a filtered, sorted page of a user's transactions
+func (s *TransactionStore) QueryUserTransactions(userID string, q HistoryQuery) (*HistoryPage, error) {
+	s.mu.RLock()
+	ids := s.userTxns[userID]
+	s.mu.RUnlock()
+	return s.queryTransactions(ids, q)
+}
+
+// queryTransactions filters, sorts and pages the transactions with the given IDs
+func (s *TransactionStore) queryTransactions(ids []string, q HistoryQuery) (*HistoryPage, error) {
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[TransactionStatus]bool, len(q.Statuses))
+	for _, status := range q.Statuses {
+		statuses[status] = true
+	}
+
+	s.mu.RLock()
+	matches := make([]*Transaction, 0, len(ids))
+	for _, id := range ids {
+		txn, ok := s.transactions[id]
+		if !ok {
+			continue
+		}
+		if len(statuses) > 0 && !statuses[txn.Status] {
+			continue
+		}
+		if !q.From.IsZero() && txn.CreatedAt.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && !txn.CreatedAt.Before(q.To) {
+			continue
+		}
+		matches = append(matches, txn)
+	}
+	s.mu.RUnlock()
+
+	sortTransactions(matches, q.SortBy, q.Descending)
+
+	page := &HistoryPage{Total: len(matches), Limit: q.Limit, Offset: q.Offset}
+	start := q.Offset
+	if start > len(matches) {
+		start = len(matches)
+	}
+	end := len(matches)
+	if q.Limit > 0 && start+q.Limit < end {
+		end = start + q.Limit
+	}
+	page.Transactions = matches[start:end]
+	page.HasMore = end < len(matches)
+	return page, nil
+}
+
+// sortTransactions orders transactions by field, breaking ties by creation time then ID
+func sortTransactions(txns []*Transaction, field string, descending bool) {
+	less := func(a, b *Transaction) bool {
+		switch field {
+		case SortByAmount:
+			if a.Amount != b.Amount {
+				return a.Amount < b.Amount
+			}
+		case SortByFinalAmount:
+			if a.FinalAmount != b.FinalAmount {
+				return a.FinalAmount < b.FinalAmount
+			}
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt)
+		}
+		return a.ID < b.ID
+	}
+	sort.SliceStable(txns, func(i, j int) bool {
+		if descending {
+			return less(txns[j], txns[i])
+		}
+		return less(txns[i], txns[j])
+	})
+}
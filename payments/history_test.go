@@ -0,0 +1,64 @@
+package payments
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestQueryUserTransactions(t *testing.T) {
+	store := NewTransactionStore()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	statuses := []TransactionStatus{StatusSuccess, StatusFailed, StatusSuccess, StatusPending, StatusSuccess}
+	for i, status := range statuses {
+		store.Restore(&Transaction{
+			ID:        fmt.Sprintf("txn-%d", i),
+			UserID:    "user-1",
+			Amount:    float64(100 * (5 - i)),
+			Status:    status,
+			CreatedAt: base.AddDate(0, 0, i),
+		})
+	}
+	store.Restore(&Transaction{ID: "other", UserID: "user-2", Status: StatusSuccess, CreatedAt: base})
+
+	// Newest first, paged
+	page, err := store.QueryUserTransactions("user-1", HistoryQuery{Limit: 2, Offset: 1, Descending: true})
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if page.Total != 5 || !page.HasMore || len(page.Transactions) != 2 || page.Transactions[0].ID != "txn-3" {
+		t.Fatalf("unexpected page: total=%d has_more=%v txns=%v", page.Total, page.HasMore, ids(page.Transactions))
+	}
+
+	// Status and date filters, sorted by amount ascending
+	page, err = store.QueryUserTransactions("user-1", HistoryQuery{
+		From:     base.AddDate(0, 0, 1),
+		To:       base.AddDate(0, 0, 5),
+		Statuses: []TransactionStatus{StatusSuccess},
+		SortBy:   SortByAmount,
+	})
+	if err != nil {
+		t.Fatalf("query failed: %v", err)
+	}
+	if got := ids(page.Transactions); page.Total != 2 || page.HasMore || got != "[txn-4 txn-2]" {
+		t.Fatalf("unexpected filtered page: total=%d txns=%s", page.Total, got)
+	}
+
+	// Offset past the end is an empty page, not an error
+	page, _ = store.QueryUserTransactions("user-1", HistoryQuery{Limit: 10, Offset: 50})
+	if page.Total != 5 || len(page.Transactions) != 0 || page.HasMore {
+		t.Fatalf("unexpected page past the end: %+v", page)
+	}
+
+	if _, err := store.QueryUserTransactions("user-1", HistoryQuery{SortBy: "user_id"}); err == nil {
+		t.Error("expected error for unsupported sort field")
+	}
+}
+
+func ids(txns []*Transaction) string {
+	out := make([]string, len(txns))
+	for i, txn := range txns {
+		out[i] = txn.ID
+	}
+	return fmt.Sprint(out)
+}
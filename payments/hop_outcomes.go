@@ -0,0 +1,92 @@
+package payments
+
+import (
+	"sync"
+	"time"
+)
+
+// HopOutcomeWindow is how far back the store remembers hop outcomes per country
+const HopOutcomeWindow = time.Hour
+
+// hopOutcomeBucket is how finely hop outcomes are counted in time
+const hopOutcomeBucket = time.Minute
+
+// HopOutcomes counts payment hops into a country
+type HopOutcomes struct {
+	Successes int `json:"successes"`
+	Failures  int `json:"failures"`
+}
+
+// SuccessRate returns the share of successful hops. Returns false without hops.
+func (o HopOutcomes) SuccessRate() (float64, bool) {
+	total := o.Successes + o.Failures
+	if total == 0 {
+		return 0, false
+	}
+	return float64(o.Successes) / float64(total), true
+}
+
+type outcomeBucket struct {
+	start time.Time
+	HopOutcomes
+}
+
+// hopOutcomes keeps per-country hop outcomes of the last HopOutcomeWindow in
+// minute buckets, oldest first
+type hopOutcomes struct {
+	mu      sync.Mutex
+	buckets map[string][]outcomeBucket
+}
+
+func newHopOutcomes() *hopOutcomes {
+	return &hopOutcomes{buckets: make(map[string][]outcomeBucket)}
+}
+
+// record counts a hop into code settled at at
+func (h *hopOutcomes) record(code string, success bool, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	start := at.Truncate(hopOutcomeBucket)
+	buckets := h.buckets[code]
+	if n := len(buckets); n == 0 || buckets[n-1].start.Before(start) {
+		buckets = append(buckets, outcomeBucket{start: start})
+	}
+	last := &buckets[len(buckets)-1]
+	if success {
+		last.Successes++
+	} else {
+		last.Failures++
+	}
+
+	drop := 0
+	for drop < len(buckets) && buckets[drop].start.Before(start.Add(-HopOutcomeWindow)) {
+		drop++
+	}
+	h.buckets[code] = buckets[drop:]
+}
+
+// since sums the hops into code settled in buckets starting at or after since
+func (h *hopOutcomes) since(code string, since time.Time) HopOutcomes {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var total HopOutcomes
+	for _, b := range h.buckets[code] {
+		if b.start.Before(since.Truncate(hopOutcomeBucket)) {
+			continue
+		}
+		total.Successes += b.Successes
+		total.Failures += b.Failures
+	}
+	return total
+}
+
+// CountryOutcomes returns the hops into a country processed by this store
+// over the last window, capped at HopOutcomeWindow
+func (s *TransactionStore) CountryOutcomes(code string, window time.Duration) HopOutcomes {
+	if window <= 0 || window > HopOutcomeWindow {
+		window = HopOutcomeWindow
+	}
+	return s.outcomes.since(code, time.Now().Add(-window))
+}
@@ -0,0 +1,51 @@
+package payments
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCountryOutcomesCountProcessedHops(t *testing.T) {
+	ctx := context.Background()
+	store := NewTransactionStore()
+	store.SetSimulator(NewSimulator(1, instantProfile()))
+	route := []string{"USA", "GBR", "DEU"}
+
+	ok, _ := store.CreateTransaction("user-1", 100, "USD", "USD", route, nil)
+	failed, _ := store.CreateTransaction("user-1", 100, "USD", "USD", route, nil)
+	store.ProcessTransaction(ctx, ok.ID, nil, 0)
+	store.ProcessTransaction(ctx, failed.ID, nil, 1)
+
+	if got := store.CountryOutcomes("GBR", time.Hour); got.Successes != 1 || got.Failures != 1 {
+		t.Errorf("GBR outcomes = %+v, want 1 success and 1 failure", got)
+	}
+	if rate, ok := store.CountryOutcomes("GBR", 0).SuccessRate(); !ok || rate != 0.5 {
+		t.Errorf("GBR success rate = %v, %v; want 0.5", rate, ok)
+	}
+	// The failed payment stopped at GBR
+	if got := store.CountryOutcomes("DEU", time.Hour); got.Successes != 1 || got.Failures != 0 {
+		t.Errorf("DEU outcomes = %+v, want 1 success", got)
+	}
+	if _, ok := store.CountryOutcomes("USA", time.Hour).SuccessRate(); ok {
+		t.Error("source country has a success rate without hops into it")
+	}
+}
+
+func TestHopOutcomesDropOldBuckets(t *testing.T) {
+	h := newHopOutcomes()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	h.record("GBR", false, now.Add(-2*HopOutcomeWindow))
+	h.record("GBR", true, now.Add(-10*time.Minute))
+	h.record("GBR", true, now)
+
+	if got := h.since("GBR", now.Add(-HopOutcomeWindow)); got.Successes != 2 || got.Failures != 0 {
+		t.Errorf("outcomes = %+v, want the 2 successes within the window", got)
+	}
+	if got := h.since("GBR", now.Add(-time.Minute)); got.Successes != 1 {
+		t.Errorf("last minute = %+v, want 1 success", got)
+	}
+	if n := len(h.buckets["GBR"]); n != 2 {
+		t.Errorf("%d buckets kept, want 2", n)
+	}
+}
@@ -0,0 +1,243 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// IdempotencyTTL is how long an idempotency key is remembered
+const IdempotencyTTL = 24 * time.Hour
+
+// IdempotencyPendingTTL is how long a key reserved in the backend stays
+// reserved if its request never finishes (e.g. the instance crashed)
+const IdempotencyPendingTTL = 5 * time.Minute
+
+// idempotencySweepInterval is how often expired records are evicted
+const idempotencySweepInterval = time.Minute
+
+var (
+	// ErrIdempotencyKeyReused is returned when a key is replayed with a different request body
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	// ErrIdempotencyKeyInProgress is returned when another instance is still
+	// running the request made with a key
+	ErrIdempotencyKeyInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// IdempotencyRecord is the cached outcome of a request made with an Idempotency-Key
+type IdempotencyRecord struct {
+	TransactionID string    `json:"transaction_id"`
+	RequestHash   string    `json:"request_hash"`
+	StatusCode    int       `json:"status_code"`
+	Response      []byte    `json:"response"`
+	CreatedAt     time.Time `json:"created_at"`
+	Pending       bool      `json:"pending,omitempty"` // Reserved by a request still running
+}
+
+// IdempotencyBackend is a shared key store (e.g. Redis) so keys survive across instances
+type IdempotencyBackend interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Reserve sets key to value only if it is not set, reporting whether it was
+	Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// idempotencyLock serializes requests with one key on this instance
+type idempotencyLock struct {
+	sync.Mutex
+	users int // Requests holding or waiting for the lock
+}
+
+// idempotencyCache holds records in memory with per-key locks. Locks are
+// dropped once no request uses them, and records once they expire.
+type idempotencyCache struct {
+	mu        sync.Mutex
+	records   map[string]*IdempotencyRecord
+	locks     map[string]*idempotencyLock
+	lastSweep time.Time
+	backend   IdempotencyBackend
+}
+
+func newIdempotencyCache() *idempotencyCache {
+	return &idempotencyCache{
+		records:   make(map[string]*IdempotencyRecord),
+		locks:     make(map[string]*idempotencyLock),
+		lastSweep: time.Now(),
+	}
+}
+
+// SetIdempotencyBackend sets a shared backend for idempotency records
+func (s *TransactionStore) SetIdempotencyBackend(backend IdempotencyBackend) {
+	s.idempotency.mu.Lock()
+	defer s.idempotency.mu.Unlock()
+	s.idempotency.backend = backend
+}
+
+// Idempotent runs create at most once per (userID, key).
+// Repeated calls return the original record with replayed=true.
+// Concurrent calls with the same key wait for the first to finish; with a
+// backend, the key is reserved there before create runs, so a call on
+// another instance fails with ErrIdempotencyKeyInProgress instead.
+func (s *TransactionStore) Idempotent(ctx context.Context, userID, key, requestHash string, create func() (*IdempotencyRecord, error)) (*IdempotencyRecord, bool, error) {
+	c := s.idempotency
+	scoped := "idempotency:" + userID + ":" + key
+
+	unlock := c.lock(scoped)
+	defer unlock()
+
+	rec, err := c.reserve(ctx, scoped)
+	if err != nil {
+		return nil, false, err
+	}
+	if rec != nil {
+		if rec.RequestHash != requestHash {
+			return nil, false, ErrIdempotencyKeyReused
+		}
+		return rec, true, nil
+	}
+
+	rec, err = create()
+	if err != nil {
+		c.release(ctx, scoped)
+		return nil, false, err
+	}
+	rec.RequestHash = requestHash
+	rec.CreatedAt = time.Now()
+
+	c.store(ctx, scoped, rec)
+	return rec, false, nil
+}
+
+// lock takes the local lock of key, returning its release
+func (c *idempotencyCache) lock(key string) func() {
+	c.mu.Lock()
+	lock, ok := c.locks[key]
+	if !ok {
+		lock = &idempotencyLock{}
+		c.locks[key] = lock
+	}
+	lock.users++
+	c.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		c.mu.Lock()
+		if lock.users--; lock.users == 0 {
+			delete(c.locks, key)
+		}
+		c.mu.Unlock()
+	}
+}
+
+// reserve returns the finished record of key, or reserves key for the
+// caller and returns nil. Backend failures are logged and the key treated
+// as free, so an outage does not block payments.
+func (c *idempotencyCache) reserve(ctx context.Context, key string) (*IdempotencyRecord, error) {
+	c.mu.Lock()
+	rec, ok := c.records[key]
+	if ok && time.Since(rec.CreatedAt) > IdempotencyTTL {
+		delete(c.records, key)
+		ok = false
+	}
+	backend := c.backend
+	c.mu.Unlock()
+
+	if ok {
+		return rec, nil
+	}
+	if backend == nil {
+		return nil, nil
+	}
+
+	pending, _ := json.Marshal(IdempotencyRecord{Pending: true, CreatedAt: time.Now()})
+	// A record may expire between a lost reservation and its read; try again once
+	for attempt := 0; attempt < 2; attempt++ {
+		reserved, err := backend.Reserve(ctx, key, pending, IdempotencyPendingTTL)
+		if err != nil {
+			slog.WarnContext(ctx, "idempotency backend reservation failed", "error", err)
+			return nil, nil
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		data, found, err := backend.Get(ctx, key)
+		if err != nil {
+			slog.WarnContext(ctx, "idempotency backend lookup failed", "error", err)
+			return nil, nil
+		}
+		if !found {
+			continue
+		}
+
+		var remote IdempotencyRecord
+		if err := json.Unmarshal(data, &remote); err != nil {
+			slog.WarnContext(ctx, "corrupt idempotency record", "key", key, "error", err)
+			return nil, nil
+		}
+		if remote.Pending {
+			return nil, ErrIdempotencyKeyInProgress
+		}
+
+		c.mu.Lock()
+		c.records[key] = &remote
+		c.mu.Unlock()
+		return &remote, nil
+	}
+	return nil, ErrIdempotencyKeyInProgress
+}
+
+// release frees the backend reservation of a request that failed, so the
+// key can be retried
+func (c *idempotencyCache) release(ctx context.Context, key string) {
+	c.mu.Lock()
+	backend := c.backend
+	c.mu.Unlock()
+
+	if backend == nil {
+		return
+	}
+	if err := backend.Delete(ctx, key); err != nil {
+		slog.WarnContext(ctx, "idempotency backend release failed", "key", key, "error", err)
+	}
+}
+
+// store saves a record locally and in the backend, replacing the reservation
+func (c *idempotencyCache) store(ctx context.Context, key string, rec *IdempotencyRecord) {
+	c.mu.Lock()
+	c.records[key] = rec
+	c.sweepLocked(rec.CreatedAt)
+	backend := c.backend
+	c.mu.Unlock()
+
+	if backend == nil {
+		return
+	}
+
+	data, err := json.Marshal(rec)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to encode idempotency record", "error", err)
+		return
+	}
+	if err := backend.Set(ctx, key, data, IdempotencyTTL); err != nil {
+		slog.WarnContext(ctx, "idempotency backend write failed", "error", err)
+	}
+}
+
+// sweepLocked evicts expired records, at most once per sweep interval
+func (c *idempotencyCache) sweepLocked(now time.Time) {
+	if now.Sub(c.lastSweep) < idempotencySweepInterval {
+		return
+	}
+	c.lastSweep = now
+	for key, rec := range c.records {
+		if now.Sub(rec.CreatedAt) > IdempotencyTTL {
+			delete(c.records, key)
+		}
+	}
+}
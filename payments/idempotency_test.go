@@ -0,0 +1,154 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryBackend is an in-memory IdempotencyBackend, shared between stores
+// like Redis is between instances
+type memoryBackend struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func (b *memoryBackend) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	v, ok := b.values[key]
+	return v, ok, nil
+}
+
+func (b *memoryBackend) Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.values[key]; ok {
+		return false, nil
+	}
+	b.values[key] = value
+	return true, nil
+}
+
+func (b *memoryBackend) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.values[key] = value
+	return nil
+}
+
+func (b *memoryBackend) Delete(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.values, key)
+	return nil
+}
+
+func TestIdempotentReplayReturnsOriginalTransaction(t *testing.T) {
+	store := NewTransactionStore()
+	ctx := context.Background()
+
+	calls := 0
+	create := func() (*IdempotencyRecord, error) {
+		calls++
+		txn, err := store.CreateTransaction("user-1", 100, "USD", "EUR", []string{"US", "DE"}, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &IdempotencyRecord{TransactionID: txn.ID, StatusCode: 201}, nil
+	}
+
+	first, replayed, err := store.Idempotent(ctx, "user-1", "key-1", "hash-a", create)
+	if err != nil || replayed {
+		t.Fatalf("first call: replayed=%v err=%v", replayed, err)
+	}
+
+	second, replayed, err := store.Idempotent(ctx, "user-1", "key-1", "hash-a", create)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if !replayed || second.TransactionID != first.TransactionID {
+		t.Fatalf("expected replay of %s, got %s (replayed=%v)", first.TransactionID, second.TransactionID, replayed)
+	}
+	if calls != 1 {
+		t.Fatalf("create called %d times, want 1", calls)
+	}
+	if n := len(store.GetUserTransactions("user-1")); n != 1 {
+		t.Fatalf("expected 1 transaction, got %d", n)
+	}
+
+	if _, _, err := store.Idempotent(ctx, "user-1", "key-1", "hash-b", create); !errors.Is(err, ErrIdempotencyKeyReused) {
+		t.Fatalf("expected ErrIdempotencyKeyReused, got %v", err)
+	}
+
+	// Keys are scoped per user
+	if _, replayed, err := store.Idempotent(ctx, "user-2", "key-1", "hash-b", create); err != nil || replayed {
+		t.Fatalf("other user: replayed=%v err=%v", replayed, err)
+	}
+}
+
+// TestIdempotentReservesKeyAcrossInstances verifies a key is reserved in the
+// backend before create runs, so another instance cannot run it too
+func TestIdempotentReservesKeyAcrossInstances(t *testing.T) {
+	ctx := context.Background()
+	backend := &memoryBackend{values: make(map[string][]byte)}
+	a, b := NewTransactionStore(), NewTransactionStore()
+	a.SetIdempotencyBackend(backend)
+	b.SetIdempotencyBackend(backend)
+
+	done := func() (*IdempotencyRecord, error) {
+		return &IdempotencyRecord{TransactionID: "txn-1", StatusCode: 200}, nil
+	}
+	_, _, err := a.Idempotent(ctx, "user-1", "key-1", "hash-a", func() (*IdempotencyRecord, error) {
+		// Still running on instance a
+		if _, _, err := b.Idempotent(ctx, "user-1", "key-1", "hash-a", done); !errors.Is(err, ErrIdempotencyKeyInProgress) {
+			t.Errorf("concurrent call on another instance: err = %v, want ErrIdempotencyKeyInProgress", err)
+		}
+		return done()
+	})
+	if err != nil {
+		t.Fatalf("Idempotent: %v", err)
+	}
+	rec, replayed, err := b.Idempotent(ctx, "user-1", "key-1", "hash-a", done)
+	if err != nil || !replayed || rec.TransactionID != "txn-1" {
+		t.Errorf("replay on another instance: rec = %+v, replayed = %v, err = %v", rec, replayed, err)
+	}
+
+	// A failed request releases its reservation so the key can be retried
+	failed := errors.New("card declined")
+	if _, _, err := a.Idempotent(ctx, "user-1", "key-2", "hash-a", func() (*IdempotencyRecord, error) { return nil, failed }); !errors.Is(err, failed) {
+		t.Fatalf("err = %v, want the create error", err)
+	}
+	if _, replayed, err := b.Idempotent(ctx, "user-1", "key-2", "hash-a", done); err != nil || replayed {
+		t.Errorf("retry after failure: replayed = %v, err = %v", replayed, err)
+	}
+}
+
+// TestIdempotencyCacheEvicts verifies unused locks are dropped and expired
+// records swept
+func TestIdempotencyCacheEvicts(t *testing.T) {
+	ctx := context.Background()
+	store := NewTransactionStore()
+	create := func() (*IdempotencyRecord, error) { return &IdempotencyRecord{StatusCode: 200}, nil }
+
+	if _, _, err := store.Idempotent(ctx, "user-1", "old", "hash", create); err != nil {
+		t.Fatalf("Idempotent: %v", err)
+	}
+	c := store.idempotency
+	if len(c.locks) != 0 {
+		t.Errorf("locks = %d after the request finished, want 0", len(c.locks))
+	}
+
+	c.mu.Lock()
+	c.records["idempotency:user-1:old"].CreatedAt = time.Now().Add(-IdempotencyTTL - time.Minute)
+	c.lastSweep = time.Now().Add(-idempotencySweepInterval)
+	c.mu.Unlock()
+	if _, _, err := store.Idempotent(ctx, "user-1", "new", "hash", create); err != nil {
+		t.Fatalf("Idempotent: %v", err)
+	}
+	if _, ok := c.records["idempotency:user-1:old"]; ok || len(c.records) != 1 {
+		t.Errorf("records = %d, want the expired one evicted", len(c.records))
+	}
+}
@@ -0,0 +1,223 @@
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+)
+
+// Processing lock timing defaults
+const (
+	processingLockTTL   = 15 * time.Second      // Lifetime without renewal; bounds how long a crashed holder blocks
+	processingLockWait  = 10 * time.Second      // How long to wait for a lock held elsewhere
+	processingLockRetry = 50 * time.Millisecond // Poll interval while waiting
+	lockReleaseTimeout  = 2 * time.Second       // Bounds releasing a lock
+)
+
+// ErrTransactionLocked is returned when a transaction stays locked by another
+// holder (possibly another server instance) for longer than the lock wait
+var ErrTransactionLocked = errors.New("transaction is being processed elsewhere")
+
+// Locker provides the locks that keep a transaction from being processed
+// concurrently. Satisfied by storage/redis.Locker, which shares locks across
+// server instances; the default locker only covers this process.
+type Locker interface {
+	TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context, key, token string) error
+}
+
+// Compile-time interface check
+var _ Locker = (*redisClient.Locker)(nil)
+
+// SetLocker sets the locker guarding transaction processing
+func (s *TransactionStore) SetLocker(l Locker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.locker = l
+}
+
+// heldLockKey is the context key of the transaction locks held by a call chain
+type heldLockKey struct{}
+
+// heldLock is a processing lock held through a context
+type heldLock struct {
+	txnID  string
+	parent *heldLock
+}
+
+// holdsLock reports whether ctx carries the lock of txnID
+func holdsLock(ctx context.Context, txnID string) bool {
+	for l, _ := ctx.Value(heldLockKey{}).(*heldLock); l != nil; l = l.parent {
+		if l.txnID == txnID {
+			return true
+		}
+	}
+	return false
+}
+
+// LockTransaction acquires the processing lock of txnID, waiting for another
+// holder to release it. The lock is renewed until unlock is called. The
+// returned context carries the lock, so processing done with it doesn't lock
+// again, and is cancelled if the lock is lost, stopping that processing
+// before a new holder can start, or if Drain's deadline passes. Returns
+// ErrTransactionLocked if the lock isn't released in time, and
+// ErrShuttingDown once draining.
+func (s *TransactionStore) LockTransaction(ctx context.Context, txnID string) (context.Context, func(), error) {
+	if holdsLock(ctx, txnID) {
+		return ctx, func() {}, nil
+	}
+
+	s.mu.RLock()
+	locker, ttl, wait, draining := s.locker, s.lockTTL, s.lockWait, s.draining
+	s.mu.RUnlock()
+	if draining {
+		return nil, nil, ErrShuttingDown
+	}
+
+	key := "transaction:" + txnID
+	token := generateLockToken()
+	deadline := time.Now().Add(wait)
+	for {
+		ok, err := locker.TryLock(ctx, key, token, ttl)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to lock transaction %s: %w", txnID, err)
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			return nil, nil, ErrTransactionLocked
+		}
+		select {
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		case <-time.After(processingLockRetry):
+		}
+	}
+
+	parent, _ := ctx.Value(heldLockKey{}).(*heldLock)
+	lockCtx, cancel := context.WithCancelCause(context.WithValue(ctx, heldLockKey{}, &heldLock{txnID: txnID, parent: parent}))
+	finish, err := s.beginProcessing(txnID, cancel)
+	if err != nil {
+		cancel(err)
+		releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), lockReleaseTimeout)
+		defer cancelRelease()
+		locker.Unlock(releaseCtx, key, token)
+		return nil, nil, err
+	}
+	done := make(chan struct{})
+	go s.renewLock(lockCtx, func() { cancel(nil) }, done, locker, key, token, ttl)
+
+	var once sync.Once
+	unlock := func() {
+		once.Do(func() {
+			defer finish()
+			close(done)
+			cancel(nil)
+			releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), lockReleaseTimeout)
+			defer cancelRelease()
+			if err := locker.Unlock(releaseCtx, key, token); err != nil {
+				slog.WarnContext(ctx, "failed to release transaction lock", "transaction_id", txnID, "error", err)
+			}
+		})
+	}
+	return lockCtx, unlock, nil
+}
+
+// renewLock extends a held lock every third of its TTL until done. If the
+// lock is lost, or can't be renewed before it would expire, the holder's
+// context is cancelled.
+func (s *TransactionStore) renewLock(ctx context.Context, cancel context.CancelFunc, done <-chan struct{}, locker Locker, key, token string, ttl time.Duration) {
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+
+	renewed := time.Now()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		ok, err := locker.Extend(ctx, key, token, ttl)
+		switch {
+		case err == nil && ok:
+			renewed = time.Now()
+			continue
+		case err == nil:
+			slog.ErrorContext(ctx, "transaction lock lost, stopping processing", "lock", key)
+		case time.Since(renewed) >= ttl-ttl/3:
+			slog.ErrorContext(ctx, "transaction lock could not be renewed, stopping processing", "lock", key, "error", err)
+		default:
+			slog.WarnContext(ctx, "failed to renew transaction lock", "lock", key, "error", err)
+			continue
+		}
+		cancel()
+		return
+	}
+}
+
+// generateLockToken generates a random token identifying a lock holder
+func generateLockToken() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// memoryLocker is the default in-process Locker
+type memoryLocker struct {
+	mu    sync.Mutex
+	locks map[string]memoryLock
+}
+
+type memoryLock struct {
+	token   string
+	expires time.Time
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{locks: make(map[string]memoryLock)}
+}
+
+// TryLock acquires key for token unless another token holds it
+func (m *memoryLocker) TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.locks[key]; ok && time.Now().Before(l.expires) {
+		return false, nil
+	}
+	m.locks[key] = memoryLock{token: token, expires: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Extend resets the TTL of key, reporting false if token no longer holds it
+func (m *memoryLocker) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l, ok := m.locks[key]
+	if !ok || l.token != token || time.Now().After(l.expires) {
+		return false, nil
+	}
+	m.locks[key] = memoryLock{token: token, expires: time.Now().Add(ttl)}
+	return true, nil
+}
+
+// Unlock releases key if token still holds it
+func (m *memoryLocker) Unlock(ctx context.Context, key, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.locks[key]; ok && l.token == token {
+		delete(m.locks, key)
+	}
+	return nil
+}
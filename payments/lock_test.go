@@ -0,0 +1,166 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// newReplicas returns two stores sharing a locker, each holding the same
+// pending transaction, like two server instances loading it from Postgres
+func newReplicas(t *testing.T, locker Locker) (a, b *TransactionStore, txnID string) {
+	t.Helper()
+	a, b = NewTransactionStore(), NewTransactionStore()
+	a.SetLocker(locker)
+	b.SetLocker(locker)
+
+	txn, err := a.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR", "DEU"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	snap, _ := a.Snapshot(txn.ID)
+	b.Restore(snap)
+	return a, b, txn.ID
+}
+
+// TestConcurrentProcessingAcrossReplicas verifies only one replica processes
+// a transaction when both try at once
+func TestConcurrentProcessingAcrossReplicas(t *testing.T) {
+	a, b, txnID := newReplicas(t, newMemoryLocker())
+	a.lockWait, b.lockWait = 0, 0
+
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, store := range []*TransactionStore{a, b} {
+		wg.Add(1)
+		go func(i int, store *TransactionStore) {
+			defer wg.Done()
+			errs[i] = store.ProcessTransaction(context.Background(), txnID, nil, 0)
+		}(i, store)
+	}
+	wg.Wait()
+
+	processed, locked := 0, 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			processed++
+		case errors.Is(err, ErrTransactionLocked):
+			locked++
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if processed != 1 || locked != 1 {
+		t.Fatalf("expected one replica to process and one to be locked out, got %v", errs)
+	}
+}
+
+// TestLockWaitsForRelease verifies a waiting caller gets the lock once the
+// holder releases it, and finds the transaction already processed
+func TestLockWaitsForRelease(t *testing.T) {
+	store := NewTransactionStore()
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+
+	done := make(chan error, 1)
+	go func() { done <- store.ProcessTransaction(context.Background(), txn.ID, nil, 0) }()
+	time.Sleep(10 * time.Millisecond)
+
+	_, unlock, err := store.LockTransaction(context.Background(), txn.ID)
+	if err != nil {
+		t.Fatalf("LockTransaction: %v", err)
+	}
+	defer unlock()
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	if snap, _ := store.Snapshot(txn.ID); snap.Status != StatusSuccess {
+		t.Errorf("lock acquired before processing finished (status %s)", snap.Status)
+	}
+}
+
+// TestHeldLockIsReentrant verifies processing with a context that already
+// holds the transaction's lock doesn't wait for itself
+func TestHeldLockIsReentrant(t *testing.T) {
+	store := NewTransactionStore()
+	store.lockWait = 0
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+
+	ctx, unlock, err := store.LockTransaction(context.Background(), txn.ID)
+	if err != nil {
+		t.Fatalf("LockTransaction: %v", err)
+	}
+	defer unlock()
+
+	if err := store.ProcessTransactionWithRoute(ctx, txn.ID, txn.Route, nil, 0); err != nil {
+		t.Fatalf("processing under the held lock: %v", err)
+	}
+	if _, _, err := store.LockTransaction(context.Background(), txn.ID); !errors.Is(err, ErrTransactionLocked) {
+		t.Errorf("expected the lock to still be held by the outer caller, got %v", err)
+	}
+}
+
+// TestLockRenewalOutlivesTTL verifies a holder keeps its lock past the TTL
+// while processing
+func TestLockRenewalOutlivesTTL(t *testing.T) {
+	a, b, txnID := newReplicas(t, newMemoryLocker())
+	a.lockTTL = 30 * time.Millisecond
+	b.lockWait = 0
+
+	done := make(chan error, 1)
+	go func() { done <- a.ProcessTransaction(context.Background(), txnID, nil, 0) }()
+	time.Sleep(80 * time.Millisecond)
+
+	if _, _, err := b.LockTransaction(context.Background(), txnID); !errors.Is(err, ErrTransactionLocked) {
+		t.Errorf("expected the lock to be renewed past its TTL, got %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+}
+
+// stealingPublisher takes over the transaction's lock once the first hop
+// settles, as if the holder stalled past the TTL
+type stealingPublisher struct {
+	locker *memoryLocker
+	once   sync.Once
+}
+
+func (p *stealingPublisher) PublishSettlementEvent(ctx context.Context, event *natsClient.SettlementEvent) error {
+	if event.EventType == natsClient.SettlementHopComplete {
+		p.once.Do(func() {
+			key := "transaction:" + event.RequestID
+			p.locker.mu.Lock()
+			p.locker.locks[key] = memoryLock{token: "other-replica", expires: time.Now().Add(time.Minute)}
+			p.locker.mu.Unlock()
+		})
+	}
+	return nil
+}
+
+// TestLostLockStopsProcessing verifies a holder that loses its lock stops
+// settling hops and compensates the ones already settled
+func TestLostLockStopsProcessing(t *testing.T) {
+	locker := newMemoryLocker()
+	store := NewTransactionStore()
+	store.SetLocker(locker)
+	store.lockTTL = 30 * time.Millisecond
+	store.SetEventPublisher(&stealingPublisher{locker: locker})
+
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR", "DEU", "FRA"}, nil)
+	if err := store.ProcessTransaction(context.Background(), txn.ID, nil, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected processing to stop when the lock was lost, got %v", err)
+	}
+
+	snap, _ := store.Snapshot(txn.ID)
+	if snap.Status != StatusFailed || len(snap.Compensations) != 1 {
+		t.Errorf("expected a compensated failure, got %s with %d compensations", snap.Status, len(snap.Compensations))
+	}
+	if l := locker.locks["transaction:"+txn.ID]; l.token != "other-replica" {
+		t.Errorf("the lost lock was released from under its new holder")
+	}
+}
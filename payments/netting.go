@@ -0,0 +1,135 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// NettingUserID owns the transactions that move a netting set's net amount
+const NettingUserID = "system:netting"
+
+// ErrNotNettable is returned when queueing a payment that is not pending
+var ErrNotNettable = errors.New("only pending payments can be queued for netting")
+
+// NettingStatus is the outcome of a netting set
+type NettingStatus string
+
+const (
+	NettingSettled NettingStatus = "settled" // Net amount moved (or nothing to move); members succeeded
+	NettingFailed  NettingStatus = "failed"  // Net settlement failed; members failed
+)
+
+// NettingSet is a group of payments between the same two countries settled
+// together by moving only their net amount through the mesh. Amounts are in
+// the currency of Source.
+type NettingSet struct {
+	ID               string        `json:"id"`
+	Source           string        `json:"source"` // Corridor countries, Source < Target
+	Target           string        `json:"target"`
+	Currency         string        `json:"currency"`
+	FXRate           float64       `json:"fx_rate"` // Source → Target rate used to net and deliver
+	TransactionIDs   []string      `json:"transaction_ids"`
+	Gross            float64       `json:"gross"`                        // Sum of member amounts
+	Net              float64       `json:"net"`                          // Positive flows Source → Target, negative Target → Source
+	NetTransactionID string        `json:"net_transaction_id,omitempty"` // Empty when the members offset exactly
+	Route            []string      `json:"route,omitempty"`              // Route of the net settlement
+	Status           NettingStatus `json:"status"`
+	Error            string        `json:"error,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+	SettledAt        *time.Time    `json:"settled_at,omitempty"`
+}
+
+// QueueForNetting moves a pending payment into the netting queue. From then on
+// it is settled only by CompleteNettingSet.
+func (s *TransactionStore) QueueForNetting(txnID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		return fmt.Errorf("transaction not found: %s", txnID)
+	}
+	if txn.Status != StatusPending {
+		return ErrNotNettable
+	}
+	now := time.Now()
+	txn.Status = StatusNetting
+	txn.ProcessedAt = &now
+	s.countLocked(txn)
+	return nil
+}
+
+// CreateNetSettlement creates the pending transaction that moves a netting
+// set's net amount along route. Members have already been charged fees, so
+// the net settlement is fee-free and not subject to organization limits.
+func (s *TransactionStore) CreateNetSettlement(set *NettingSet, amount float64, currency string, route []string) (*Transaction, error) {
+	if amount <= 0 {
+		return nil, fmt.Errorf("net amount must be positive")
+	}
+
+	s.mu.Lock()
+	txn, err := s.newTransaction(NettingUserID, amount, currency, currency, route, nil)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	txn.OrgID = ""
+	txn.BaseFee, txn.HopFees, txn.HopFeeBreakdown, txn.HaltFines = 0, 0, nil, 0
+	txn.TotalFees, txn.AdminProfit, txn.FinalAmount = 0, 0, amount
+	txn.FeeScheduleVersion, txn.FeeRates = 0, &FeeConfig{}
+	txn.CardLast4, txn.PaymentMethod = "", "netting"
+	txn.NettingSetID = set.ID
+	s.addLocked(txn)
+	s.mu.Unlock()
+
+	return txn, nil
+}
+
+// CompleteNettingSet settles the set's queued members, or fails them if the
+// set failed. Members deliver their amount after fees converted at
+// set.FXRate. Returns the IDs of the members completed.
+func (s *TransactionStore) CompleteNettingSet(set *NettingSet) []string {
+	now := time.Now()
+
+	s.mu.Lock()
+	completed := make([]string, 0, len(set.TransactionIDs))
+	for _, id := range set.TransactionIDs {
+		txn, ok := s.transactions[id]
+		if !ok || txn.Status != StatusNetting {
+			continue
+		}
+		txn.NettingSetID = set.ID
+		txn.CompletedAt = &now
+		reason := ""
+		if set.Status == NettingSettled {
+			rate := set.FXRate
+			if rate <= 0 {
+				rate = 1
+			}
+			if txn.Route[0] != set.Source {
+				rate = 1 / rate
+			}
+			txn.Status = StatusSuccess
+			txn.HopsCompleted = len(txn.Route) - 1
+			txn.FinalAmount = (txn.Amount - txn.TotalFees) * rate
+		} else {
+			txn.Status = StatusFailed
+			txn.FailedAt = txn.Route[0]
+			reason = "net settlement failed: " + set.Error
+		}
+		recordAttemptLocked(txn, reason)
+		s.countLocked(txn)
+		completed = append(completed, id)
+	}
+	s.mu.Unlock()
+
+	event := EventPaymentSucceeded
+	if set.Status != NettingSettled {
+		event = EventPaymentFailed
+	}
+	for _, id := range completed {
+		s.notifyStatus(event, id)
+	}
+	return completed
+}
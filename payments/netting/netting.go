@@ -0,0 +1,323 @@
+// Package netting settles offsetting payments together. Payments queued with
+// a Netter wait for the end of the netting window; payments between the same
+// two countries are then netted and only the net amount is moved through the
+// mesh, saving the fees of settling each payment gross.
+package netting
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// Config holds netting settings
+type Config struct {
+	Window        time.Duration // How long payments wait to be netted
+	FailureChance float64       // Simulated mesh failure chance per net settlement
+}
+
+// DefaultConfig returns default netting settings
+func DefaultConfig() *Config {
+	return &Config{
+		Window:        30 * time.Second,
+		FailureChance: 0.05,
+	}
+}
+
+// Notifier is told about every netting set once it has been settled or failed
+type Notifier func(set *payments.NettingSet)
+
+// Refunder returns a failed member's funds to the payer, who was charged
+// when the payment was confirmed
+type Refunder func(ctx context.Context, txn *payments.Transaction) error
+
+// corridor is an unordered pair of countries, a < b
+type corridor struct {
+	a, b string
+}
+
+func corridorOf(route []string) corridor {
+	src, dst := route[0], route[len(route)-1]
+	if dst < src {
+		return corridor{dst, src}
+	}
+	return corridor{src, dst}
+}
+
+// Netter queues payments per corridor and settles their net positions at the
+// end of each window
+type Netter struct {
+	txns   payments.TransactionStorer
+	config *Config
+
+	mu       sync.Mutex // guards queue
+	queue    map[corridor][]string
+	flushMu  sync.Mutex // serializes flushes
+	fxRates  func() map[string]float64
+	notifier Notifier
+	refunder Refunder
+}
+
+// NewNetter creates a netter settling through txns
+func NewNetter(txns payments.TransactionStorer, cfg *Config) *Netter {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Netter{
+		txns:    txns,
+		config:  cfg,
+		queue:   make(map[corridor][]string),
+		fxRates: func() map[string]float64 { return nil },
+	}
+}
+
+// SetFXRates sets the source of FX rates used to net opposite directions
+func (n *Netter) SetFXRates(rates func() map[string]float64) {
+	n.fxRates = rates
+}
+
+// SetNotifier sets the callback for settled and failed netting sets
+func (n *Netter) SetNotifier(fn Notifier) {
+	n.notifier = fn
+}
+
+// SetRefunder sets how members of failed netting sets are refunded. Without
+// one they are left failed and unrefunded.
+func (n *Netter) SetRefunder(fn Refunder) {
+	n.refunder = fn
+}
+
+// Submit queues a pending payment for the current netting window
+func (n *Netter) Submit(txnID string) error {
+	txn, err := n.txns.GetTransaction(txnID)
+	if err != nil {
+		return err
+	}
+	if err := n.txns.QueueForNetting(txnID); err != nil {
+		return err
+	}
+	n.enqueue(txnID, txn.Route)
+	return nil
+}
+
+// Recover re-queues payments left queued by a previous run (loaded from
+// storage) and returns how many were found
+func (n *Netter) Recover() int {
+	count := 0
+	for _, txn := range n.txns.GetAllTransactions() {
+		if txn.Status == payments.StatusNetting {
+			n.enqueue(txn.ID, txn.Route)
+			count++
+		}
+	}
+	return count
+}
+
+func (n *Netter) enqueue(txnID string, route []string) {
+	c := corridorOf(route)
+	n.mu.Lock()
+	n.queue[c] = append(n.queue[c], txnID)
+	n.mu.Unlock()
+}
+
+// ErrNotQueued is returned when cancelling a payment that is not waiting
+// to be netted
+var ErrNotQueued = errors.New("payment is not queued for netting")
+
+// Cancel takes a queued payment out of the netting window. refund is run
+// while no flush can start, and the payment stays queued if it fails, so a
+// payment is never both refunded and settled.
+func (n *Netter) Cancel(txnID string, refund func() error) error {
+	n.flushMu.Lock()
+	defer n.flushMu.Unlock()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for c, ids := range n.queue {
+		for i, id := range ids {
+			if id != txnID {
+				continue
+			}
+			if err := refund(); err != nil {
+				return err
+			}
+			n.queue[c] = append(ids[:i:i], ids[i+1:]...)
+			if len(n.queue[c]) == 0 {
+				delete(n.queue, c)
+			}
+			return nil
+		}
+	}
+	return ErrNotQueued
+}
+
+// Pending returns the number of queued payments
+func (n *Netter) Pending() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	count := 0
+	for _, ids := range n.queue {
+		count += len(ids)
+	}
+	return count
+}
+
+// Start flushes the queue at the end of every window until ctx is cancelled
+func (n *Netter) Start(ctx context.Context) {
+	ticker := time.NewTicker(n.config.Window)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.Flush(ctx)
+		}
+	}
+}
+
+// Flush nets and settles everything queued so far, one set per corridor
+func (n *Netter) Flush(ctx context.Context) []*payments.NettingSet {
+	n.flushMu.Lock()
+	defer n.flushMu.Unlock()
+
+	n.mu.Lock()
+	queue := n.queue
+	n.queue = make(map[corridor][]string)
+	n.mu.Unlock()
+
+	sets := make([]*payments.NettingSet, 0, len(queue))
+	for c, ids := range queue {
+		sets = append(sets, n.settle(ctx, c, ids))
+	}
+	return sets
+}
+
+// settle computes a corridor's net position, moves it through the mesh and
+// completes the members
+func (n *Netter) settle(ctx context.Context, c corridor, ids []string) *payments.NettingSet {
+	rates := n.fxRates()
+	set := &payments.NettingSet{
+		ID:             generateSetID(),
+		Source:         c.a,
+		Target:         c.b,
+		FXRate:         corridorRate(rates, c),
+		TransactionIDs: ids,
+		Status:         payments.NettingSettled,
+		CreatedAt:      time.Now(),
+	}
+
+	// Positions in the Source country's currency; the first payment each way
+	// provides the route and currency of a net settlement in that direction
+	var forward, backward *payments.Transaction
+	for _, id := range ids {
+		txn, err := n.txns.GetTransaction(id)
+		if err != nil {
+			continue
+		}
+		if txn.Route[0] == c.a {
+			set.Gross += txn.Amount
+			set.Net += txn.Amount
+			if forward == nil {
+				forward = txn
+			}
+		} else {
+			set.Gross += txn.Amount / set.FXRate
+			set.Net -= txn.Amount / set.FXRate
+			if backward == nil {
+				backward = txn
+			}
+		}
+	}
+	switch {
+	case forward != nil:
+		set.Currency = forward.Currency
+	case backward != nil:
+		set.Currency = backward.TargetCurrency
+	}
+
+	amount, via := set.Net, forward
+	if set.Net < 0 {
+		amount, via = -set.Net*set.FXRate, backward
+	}
+	if amount = math.Round(amount*100) / 100; amount > 0 && via != nil {
+		set.Route = via.Route
+		if err := n.moveNet(ctx, set, amount, via.Currency, rates); err != nil {
+			set.Status = payments.NettingFailed
+			set.Error = err.Error()
+		}
+	}
+
+	now := time.Now()
+	set.SettledAt = &now
+	completed := n.txns.CompleteNettingSet(set)
+
+	if set.Status == payments.NettingSettled {
+		slog.InfoContext(ctx, "netting set settled", "netting_set_id", set.ID, "corridor", c.a+"-"+c.b,
+			"payments", len(completed), "gross", set.Gross, "net", set.Net, "net_transaction_id", set.NetTransactionID)
+	} else {
+		slog.WarnContext(ctx, "netting set failed", "netting_set_id", set.ID, "corridor", c.a+"-"+c.b,
+			"payments", len(completed), "net", set.Net, "error", set.Error)
+		n.refundMembers(ctx, completed)
+	}
+	if n.notifier != nil {
+		n.notifier(set)
+	}
+	return set
+}
+
+// refundMembers refunds the members of a failed set; those that cannot be
+// refunded are logged for a manual refund
+func (n *Netter) refundMembers(ctx context.Context, ids []string) {
+	if n.refunder == nil {
+		return
+	}
+	for _, id := range ids {
+		txn, err := n.txns.GetTransaction(id)
+		if err != nil {
+			continue
+		}
+		if err := n.refunder(ctx, txn); err != nil {
+			slog.ErrorContext(ctx, "netting member refund failed; refund it manually", "transaction_id", id, "error", err)
+		}
+	}
+}
+
+// moveNet creates and processes the transaction carrying a set's net amount
+func (n *Netter) moveNet(ctx context.Context, set *payments.NettingSet, amount float64, currency string, rates map[string]float64) error {
+	txn, err := n.txns.CreateNetSettlement(set, amount, currency, set.Route)
+	if err != nil {
+		return err
+	}
+	set.NetTransactionID = txn.ID
+
+	payCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	return n.txns.ProcessTransaction(payCtx, txn.ID, rates, n.config.FailureChance)
+}
+
+// corridorRate returns the rate converting the corridor's first country's
+// currency into the second's, 1.0 when either rate is unknown (as a hop
+// without a rate settles unconverted)
+func corridorRate(rates map[string]float64, c corridor) float64 {
+	from, to := rates[c.a], rates[c.b]
+	if from <= 0 || to <= 0 {
+		return 1.0
+	}
+	return to / from
+}
+
+// generateSetID generates a unique netting set ID
+func generateSetID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return "net_" + hex.EncodeToString(bytes)
+}
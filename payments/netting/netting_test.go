@@ -0,0 +1,144 @@
+package netting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func newTestNetter(t *testing.T) (*Netter, *payments.TransactionStore) {
+	t.Helper()
+	txns := payments.NewTransactionStore()
+	return NewNetter(txns, &Config{Window: time.Minute}), txns
+}
+
+func submit(t *testing.T, n *Netter, txns *payments.TransactionStore, amount float64, route []string) *payments.Transaction {
+	t.Helper()
+	txn, err := txns.CreateTransaction("user1", amount, "USD", "USD", route, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if err := n.Submit(txn.ID); err != nil {
+		t.Fatalf("Submit: %v", err)
+	}
+	return txn
+}
+
+// TestFlushSettlesOnlyTheNet verifies opposite payments in a corridor are
+// settled by one fee-free transaction carrying the difference
+func TestFlushSettlesOnlyTheNet(t *testing.T) {
+	n, txns := newTestNetter(t)
+	out := submit(t, n, txns, 100, []string{"USA", "GBR"})
+	back := submit(t, n, txns, 60, []string{"GBR", "USA"})
+
+	if out.Status != payments.StatusNetting || n.Pending() != 2 {
+		t.Fatalf("status = %s, pending = %d, want netting and 2", out.Status, n.Pending())
+	}
+	if err := txns.ProcessTransaction(context.Background(), out.ID, nil, 0); err == nil {
+		t.Fatal("expected queued payment not to be processed individually")
+	}
+
+	sets := n.Flush(context.Background())
+	if len(sets) != 1 || n.Pending() != 0 {
+		t.Fatalf("sets = %d, pending = %d, want 1 and 0", len(sets), n.Pending())
+	}
+	set := sets[0]
+	if set.Status != payments.NettingSettled || set.Gross != 160 || set.Net != -40 {
+		t.Fatalf("set = %+v, want settled with gross 160 and net -40", set)
+	}
+
+	net, err := txns.GetTransaction(set.NetTransactionID)
+	if err != nil {
+		t.Fatalf("net transaction: %v", err)
+	}
+	if net.Amount != 40 || net.TotalFees != 0 || net.Route[0] != "USA" || net.NettingSetID != set.ID || net.Status != payments.StatusSuccess {
+		t.Errorf("net transaction = %+v, want 40 USA→GBR fee-free and settled", net)
+	}
+	for _, txn := range []*payments.Transaction{out, back} {
+		if txn.Status != payments.StatusSuccess || txn.NettingSetID != set.ID || txn.FinalAmount != txn.Amount-txn.TotalFees {
+			t.Errorf("member %s = %s in %q delivering %.2f, want success in %s", txn.ID, txn.Status, txn.NettingSetID, txn.FinalAmount, set.ID)
+		}
+	}
+}
+
+// TestFlushOffsettingPaymentsMovesNothing verifies exactly offsetting
+// payments settle without a net transaction
+func TestFlushOffsettingPaymentsMovesNothing(t *testing.T) {
+	n, txns := newTestNetter(t)
+	out := submit(t, n, txns, 50, []string{"USA", "GBR"})
+	submit(t, n, txns, 50, []string{"GBR", "USA"})
+
+	sets := n.Flush(context.Background())
+	if len(sets) != 1 || sets[0].NetTransactionID != "" || sets[0].Status != payments.NettingSettled {
+		t.Fatalf("sets = %+v, want one settled set without a net transaction", sets)
+	}
+	if out.Status != payments.StatusSuccess {
+		t.Errorf("member status = %s, want success", out.Status)
+	}
+	if got := len(txns.GetAllTransactions()); got != 2 {
+		t.Errorf("transactions = %d, want only the 2 members", got)
+	}
+}
+
+// TestRecoverRequeuesNettingPayments verifies payments left queued by a
+// previous run are picked up again
+func TestRecoverRequeuesNettingPayments(t *testing.T) {
+	n, txns := newTestNetter(t)
+	submit(t, n, txns, 25, []string{"USA", "GBR"})
+
+	restarted := NewNetter(txns, &Config{Window: time.Minute})
+	if got := restarted.Recover(); got != 1 || restarted.Pending() != 1 {
+		t.Fatalf("recovered %d, pending %d, want 1 and 1", got, restarted.Pending())
+	}
+}
+
+// TestCancelRemovesOnlyAfterRefund verifies a cancelled payment leaves the
+// queue once refunded, and stays queued when the refund fails
+func TestCancelRemovesOnlyAfterRefund(t *testing.T) {
+	n, txns := newTestNetter(t)
+	txn := submit(t, n, txns, 30, []string{"USA", "GBR"})
+
+	if err := n.Cancel(txn.ID, func() error { return errors.New("provider down") }); err == nil || n.Pending() != 1 {
+		t.Fatalf("failed refund: err = %v, pending = %d, want an error and 1", err, n.Pending())
+	}
+	if err := n.Cancel(txn.ID, func() error { return nil }); err != nil || n.Pending() != 0 {
+		t.Fatalf("cancel: err = %v, pending = %d, want nil and 0", err, n.Pending())
+	}
+	if err := n.Cancel(txn.ID, func() error { return nil }); !errors.Is(err, ErrNotQueued) {
+		t.Errorf("second cancel: err = %v, want ErrNotQueued", err)
+	}
+	if sets := n.Flush(context.Background()); len(sets) != 0 {
+		t.Errorf("sets = %d, want the cancelled payment not to be settled", len(sets))
+	}
+}
+
+// TestFailedSetRefundsMembers verifies every member of a set whose net
+// settlement failed is handed to the refunder
+func TestFailedSetRefundsMembers(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	n := NewNetter(txns, &Config{Window: time.Minute, FailureChance: 1})
+	var refunded []string
+	n.SetRefunder(func(ctx context.Context, txn *payments.Transaction) error {
+		refunded = append(refunded, txn.ID)
+		txns.MarkAsRefunded(txn.ID, payments.Refund{ID: "re_" + txn.ID, Reason: payments.RefundMeshFailure, Amount: txn.Amount})
+		return nil
+	})
+	out := submit(t, n, txns, 100, []string{"USA", "GBR"})
+	back := submit(t, n, txns, 40, []string{"GBR", "USA"})
+
+	sets := n.Flush(context.Background())
+	if len(sets) != 1 || sets[0].Status != payments.NettingFailed {
+		t.Fatalf("sets = %+v, want one failed set", sets)
+	}
+	if len(refunded) != 2 {
+		t.Fatalf("refunded %v, want both members", refunded)
+	}
+	for _, txn := range []*payments.Transaction{out, back} {
+		if txn.Status != payments.StatusFailed || txn.Refund == nil {
+			t.Errorf("member %s = %s with refund %v, want failed and refunded", txn.ID, txn.Status, txn.Refund)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSpendingLimitExceeded is returned when a transaction would take its
+// organization over its monthly spending limit
+var ErrSpendingLimitExceeded = errors.New("organization monthly spending limit exceeded")
+
+// OrgTerms are the terms a user's organization transacts under
+type OrgTerms struct {
+	OrgID        string
+	Fees         *FeeConfig // Replaces the fee schedule when set
+	MonthlyLimit float64    // Maximum volume created per calendar month, in VolumeCurrency (0 = unlimited)
+}
+
+// OrgResolver looks up the organization a user transacts for.
+// It is called with the store locked and must not call back into the store.
+type OrgResolver interface {
+	// OrgTerms returns the terms of userID's organization, or nil if they have none
+	OrgTerms(userID string) *OrgTerms
+}
+
+// SetOrgResolver attributes new transactions to their user's organization,
+// applying its fee override and spending limit
+func (s *TransactionStore) SetOrgResolver(r OrgResolver) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orgs = r
+}
+
+// orgTermsLocked returns the terms for userID's organization, or nil.
+// Callers must hold s.mu.
+func (s *TransactionStore) orgTermsLocked(userID string) *OrgTerms {
+	if s.orgs == nil {
+		return nil
+	}
+	return s.orgs.OrgTerms(userID)
+}
+
+// checkOrgLimitLocked fails if amount more volume, in VolumeCurrency, would
+// exceed the monthly limit of userID's organization. Callers must hold s.mu.
+func (s *TransactionStore) checkOrgLimitLocked(userID string, amount float64) error {
+	terms := s.orgTermsLocked(userID)
+	if terms == nil || terms.MonthlyLimit <= 0 {
+		return nil
+	}
+	spent := s.volumeLocked(s.orgTxns[terms.OrgID], MonthStart(time.Now()))
+	if spent+amount > terms.MonthlyLimit {
+		return fmt.Errorf("%w (%.2f of %.2f used)", ErrSpendingLimitExceeded, spent, terms.MonthlyLimit)
+	}
+	return nil
+}
+
+// QueryOrgTransactions returns a filtered, sorted page of an organization's transactions
+func (s *TransactionStore) QueryOrgTransactions(orgID string, q HistoryQuery) (*HistoryPage, error) {
+	s.mu.RLock()
+	ids := s.orgTxns[orgID]
+	s.mu.RUnlock()
+	return s.queryTransactions(ids, q)
+}
@@ -0,0 +1,71 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeOrgs map[string]*OrgTerms
+
+func (f fakeOrgs) OrgTerms(userID string) *OrgTerms { return f[userID] }
+
+func TestOrgMembersTransactUnderOrgTerms(t *testing.T) {
+	store := NewTransactionStore()
+	store.SetFeeSchedule(&FeeSchedule{Version: 3, FeeConfig: DefaultFeeConfig()})
+	terms := &OrgTerms{OrgID: "org-1", Fees: &FeeConfig{BaseFeePercent: 0.005}, MonthlyLimit: 250}
+	store.SetOrgResolver(fakeOrgs{"alice": terms, "bob": terms})
+	route := []string{"USA", "GBR"}
+
+	txn, err := store.CreateTransaction("alice", 100, "USD", "GBP", route, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if txn.OrgID != "org-1" || txn.FeeScheduleVersion != 0 || !approxEqual(txn.BaseFee, 0.5) {
+		t.Errorf("org transaction = org %q v%d base %v, want org-1 with the override fee 0.5", txn.OrgID, txn.FeeScheduleVersion, txn.BaseFee)
+	}
+	if _, err := store.CreateTransaction("bob", 100, "USD", "GBP", route, nil); err != nil {
+		t.Fatalf("second member within limit: %v", err)
+	}
+
+	// The limit is shared by every member
+	if _, err := store.CreateTransaction("alice", 100, "USD", "GBP", route, nil); !errors.Is(err, ErrSpendingLimitExceeded) {
+		t.Errorf("over limit: err = %v, want ErrSpendingLimitExceeded", err)
+	}
+	if _, err := store.CreateTransaction("carol", 1000, "USD", "GBP", route, nil); err != nil {
+		t.Errorf("non-member was limited: %v", err)
+	}
+	if v := store.OrgVolume("org-1", MonthStart(txn.CreatedAt)); !approxEqual(v, 200) {
+		t.Errorf("org volume = %v, want 200", v)
+	}
+
+	page, err := store.QueryOrgTransactions("org-1", HistoryQuery{})
+	if err != nil {
+		t.Fatalf("QueryOrgTransactions: %v", err)
+	}
+	if page.Total != 2 {
+		t.Errorf("org history has %d transactions, want 2", page.Total)
+	}
+}
+
+func TestVolumeIsMeasuredInVolumeCurrency(t *testing.T) {
+	store := NewTransactionStore()
+	store.SetCurrencyRates(func() map[string]float64 { return map[string]float64{"USD": 1, "JPY": 150} })
+	store.SetOrgResolver(fakeOrgs{"alice": {OrgID: "org-1", MonthlyLimit: 250}})
+
+	txn, err := store.CreateTransaction("alice", 15000, "JPY", "USD", []string{"JPN", "USA"}, nil)
+	if err != nil {
+		t.Fatalf("15000 JPY (100 USD) within a 250 USD limit: %v", err)
+	}
+	if _, err := store.CreateTransaction("alice", 100, "USD", "GBP", []string{"USA", "GBR"}, nil); err != nil {
+		t.Fatalf("second payment within limit: %v", err)
+	}
+	if v := store.UserVolume("alice", MonthStart(txn.CreatedAt)); !approxEqual(v, 200) {
+		t.Errorf("user volume = %v, want 200 USD", v)
+	}
+	if _, err := store.CreateTransaction("alice", 9000, "JPY", "USD", []string{"JPN", "USA"}, nil); !errors.Is(err, ErrSpendingLimitExceeded) {
+		t.Errorf("9000 JPY (60 USD) over the remaining limit: err = %v, want ErrSpendingLimitExceeded", err)
+	}
+	if got := store.InVolumeCurrency(300, "XYZ"); got != 300 {
+		t.Errorf("unknown currency = %v, want counted unconverted", got)
+	}
+}
@@ -0,0 +1,112 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+)
+
+// PayoutStatus is the state of the transfer paying out a settled payment
+type PayoutStatus string
+
+const (
+	PayoutPending  PayoutStatus = "pending" // Transfer requested, outcome not yet known
+	PayoutPaid     PayoutStatus = "paid"
+	PayoutFailed   PayoutStatus = "failed"   // Every attempt failed; retried on the next startup
+	PayoutReversed PayoutStatus = "reversed" // Transfer reversed after a dispute was accepted
+)
+
+// ErrPayoutAccountLocked is returned when changing where a payment is paid
+// out after settlement has finished
+var ErrPayoutAccountLocked = errors.New("payout account can only be set before settlement")
+
+// Payout is the transfer of a settled payment's final amount to the
+// recipient's connected Stripe account
+type Payout struct {
+	ID          string       `json:"id,omitempty"` // Stripe transfer, set once paid
+	Destination string       `json:"destination"`  // Connected account
+	Amount      int64        `json:"amount"`       // Minor units of Currency
+	Currency    string       `json:"currency"`
+	Status      PayoutStatus `json:"status"`
+	Attempts    int          `json:"attempts"`
+	Error       string       `json:"error,omitempty"`
+	ReversalID  string       `json:"reversal_id,omitempty"` // Stripe transfer reversal, set once reversed
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+// PayoutHeld reports whether a payment must not be paid out because it is
+// disputed: the dispute is open, or was accepted and the payer refunded
+func (t *Transaction) PayoutHeld() bool {
+	return t.Dispute != nil && t.Dispute.Status != DisputeRejected
+}
+
+// ValidPayoutAccount reports whether account looks like a Stripe Connect account ID
+func ValidPayoutAccount(account string) bool {
+	return strings.HasPrefix(account, "acct_") && len(account) > len("acct_")
+}
+
+// SetPayoutAccount sets the connected account the final amount is paid out
+// to once the payment settles
+func (s *TransactionStore) SetPayoutAccount(txnID, account string) error {
+	if !ValidPayoutAccount(account) {
+		return fmt.Errorf("invalid payout account %q", account)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		return fmt.Errorf("transaction not found")
+	}
+	if txn.Status == StatusSuccess || txn.Status == StatusFailed {
+		return ErrPayoutAccountLocked
+	}
+	txn.PayoutAccount = account
+	return nil
+}
+
+// RecordPayout saves the latest state of a transaction's payout
+func (s *TransactionStore) RecordPayout(txnID string, payout Payout) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		return fmt.Errorf("transaction not found")
+	}
+	payout.UpdatedAt = time.Now()
+	txn.Payout = &payout
+	return nil
+}
+
+// UnpaidPayouts returns the settled payments with a payout account that
+// have not been paid out, leaving out disputed ones
+func (s *TransactionStore) UnpaidPayouts() []*Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var unpaid []*Transaction
+	for _, id := range s.order {
+		txn := s.transactions[id]
+		if txn.Status == StatusSuccess && txn.PayoutAccount != "" && !txn.PayoutHeld() &&
+			(txn.Payout == nil || (txn.Payout.Status != PayoutPaid && txn.Payout.Status != PayoutReversed)) {
+			unpaid = append(unpaid, txn)
+		}
+	}
+	return unpaid
+}
+
+// defaultMinorUnits is the precision of currencies without known minor units
+const defaultMinorUnits = 2
+
+// MinorUnits converts an amount to the smallest unit of its currency, as
+// providers take payment intents, refunds and transfers: cents for USD,
+// yen for JPY
+func MinorUnits(amount float64, currency string) int64 {
+	units, ok := refdata.MinorUnits(strings.ToUpper(currency))
+	if !ok {
+		units = defaultMinorUnits
+	}
+	return int64(math.Round(amount * math.Pow10(units)))
+}
@@ -0,0 +1,249 @@
+// Package payouts pays the receiving side. Once a payment settles through
+// the mesh and its provider has collected the payer's funds, its final amount
+// is transferred with Stripe Connect to the connected account the payer named
+// as recipient.
+package payouts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+var (
+	// ErrNotSettled is returned when paying out a payment that has not settled
+	ErrNotSettled = errors.New("only settled payments can be paid out")
+	// ErrNoPayoutAccount is returned when paying out a payment without a payout account
+	ErrNoPayoutAccount = errors.New("payment has no payout account")
+	// ErrNotCollected is returned when paying out a payment whose provider
+	// has not received the payer's funds
+	ErrNotCollected = errors.New("payment was not collected by its payment provider")
+	// ErrDisputed is returned when paying out a payment with an open or accepted dispute
+	ErrDisputed = errors.New("disputed payments are not paid out")
+	// ErrPayoutInProgress is returned when reversing a payout whose transfer
+	// outcome is not yet known
+	ErrPayoutInProgress = errors.New("payout is in progress; try again later")
+)
+
+// Transferer creates and reverses Stripe Connect transfers. Implemented by
+// payments.StripeClient.
+type Transferer interface {
+	CreateTransfer(req *payments.TransferRequest) (*payments.TransferResponse, error)
+	ReverseTransfer(transferID, idempotencyKey string) (string, error)
+}
+
+// Collections reports whether a payment's funds were collected.
+// Implemented by payments.Providers.
+type Collections interface {
+	Collected(txn *payments.Transaction) (bool, error)
+}
+
+// Config holds payout settings
+type Config struct {
+	MaxAttempts  int           // Transfer attempts per payout before it is marked failed
+	RetryBackoff time.Duration // Wait before the second attempt, doubled after each failure
+	QueueSize    int           // Settled payments waiting to be paid out
+}
+
+// DefaultConfig returns default payout settings
+func DefaultConfig() *Config {
+	return &Config{
+		MaxAttempts:  3,
+		RetryBackoff: 2 * time.Second,
+		QueueSize:    1024,
+	}
+}
+
+// Payer pays out settled payments one at a time
+type Payer struct {
+	txns        payments.TransactionStorer
+	collections Collections
+	transfer    Transferer
+	config      *Config
+	queue       chan string
+}
+
+// NewPayer creates a payer recording payouts in txns. Only payments
+// collections reports as collected are paid out.
+func NewPayer(txns payments.TransactionStorer, collections Collections, transfer Transferer, cfg *Config) *Payer {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Payer{
+		txns:        txns,
+		collections: collections,
+		transfer:    transfer,
+		config:      cfg,
+		queue:       make(chan string, cfg.QueueSize),
+	}
+}
+
+// Observe queues the payout of a payment that just settled. Meant to be
+// called from the transaction store's status callback.
+func (p *Payer) Observe(event payments.StatusEvent, txn *payments.Transaction) {
+	if event != payments.EventPaymentSucceeded || txn.PayoutAccount == "" {
+		return
+	}
+	p.Queue(txn.ID)
+}
+
+// Queue queues the payout of a settled payment, e.g. one held while it was
+// disputed
+func (p *Payer) Queue(txnID string) {
+	select {
+	case p.queue <- txnID:
+	default:
+		slog.Warn("payout queue full, paying out on the next startup", "transaction_id", txnID)
+	}
+}
+
+// Start pays out the payments a previous run left unpaid, then queued
+// payments until ctx is cancelled
+func (p *Payer) Start(ctx context.Context) {
+	for _, txn := range p.txns.UnpaidPayouts() {
+		if ctx.Err() != nil {
+			return
+		}
+		p.Pay(ctx, txn.ID)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case txnID := <-p.queue:
+			p.Pay(ctx, txnID)
+		}
+	}
+}
+
+// Pay transfers a settled payment's final amount to its payout account,
+// retrying failed transfers. A payout already paid or reversed is returned
+// unchanged, and disputed payments are held until the dispute is rejected.
+// Nothing is paid out before the payment's provider reports the payer's funds
+// as collected. Retries reuse one idempotency key, so Stripe never pays a payment twice.
+func (p *Payer) Pay(ctx context.Context, txnID string) (*payments.Payout, error) {
+	txn, err := p.txns.GetTransaction(txnID)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case txn.Status != payments.StatusSuccess:
+		return nil, ErrNotSettled
+	case txn.PayoutAccount == "":
+		return nil, ErrNoPayoutAccount
+	case txn.Payout != nil && (txn.Payout.Status == payments.PayoutPaid || txn.Payout.Status == payments.PayoutReversed):
+		return txn.Payout, nil
+	case txn.PayoutHeld():
+		return nil, ErrDisputed
+	}
+	collected, err := p.collections.Collected(txn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify collection: %w", err)
+	}
+	if !collected {
+		return nil, ErrNotCollected
+	}
+
+	currency := txn.TargetCurrency
+	if currency == "" {
+		currency = txn.Currency
+	}
+	amount := payments.MinorUnits(txn.FinalAmount, currency)
+	if amount <= 0 {
+		return nil, fmt.Errorf("payout amount %v %s is not positive", txn.FinalAmount, currency)
+	}
+
+	payout := payments.Payout{
+		Destination: txn.PayoutAccount,
+		Amount:      amount,
+		Currency:    currency,
+		Status:      payments.PayoutPending,
+	}
+	if txn.Payout != nil {
+		payout.Attempts = txn.Payout.Attempts
+	}
+	req := &payments.TransferRequest{
+		Amount:         amount,
+		Currency:       currency,
+		Destination:    txn.PayoutAccount,
+		Description:    "PLM payout for payment " + txn.ID,
+		TransferGroup:  txn.ID,
+		Metadata:       map[string]string{"transaction_id": txn.ID},
+		IdempotencyKey: "payout:" + txn.ID,
+	}
+
+	backoff := p.config.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		// Recorded as pending first, so a crash mid-transfer is retried on startup
+		payout.Attempts++
+		p.record(ctx, txnID, payout)
+
+		resp, err := p.transfer.CreateTransfer(req)
+		if err == nil {
+			payout.ID = resp.ID
+			payout.Status = payments.PayoutPaid
+			payout.Error = ""
+			p.record(ctx, txnID, payout)
+			slog.InfoContext(ctx, "payment paid out", "transaction_id", txnID, "transfer", resp.ID, "destination", payout.Destination, "amount", amount, "currency", currency)
+			return &payout, nil
+		}
+
+		payout.Error = err.Error()
+		if attempt >= p.config.MaxAttempts {
+			payout.Status = payments.PayoutFailed
+			p.record(ctx, txnID, payout)
+			slog.ErrorContext(ctx, "payout failed", "transaction_id", txnID, "attempts", payout.Attempts, "error", err)
+			return &payout, err
+		}
+		slog.WarnContext(ctx, "payout attempt failed, retrying", "transaction_id", txnID, "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			p.record(ctx, txnID, payout)
+			return &payout, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// Reverse takes back the payout of a payment whose dispute was accepted, so
+// the platform is not out of pocket for both the refund and the transfer.
+// Payments that were never paid out have nothing to reverse and return their
+// payout (possibly nil) unchanged, as do payouts already reversed.
+func (p *Payer) Reverse(ctx context.Context, txnID string) (*payments.Payout, error) {
+	txn, err := p.txns.GetTransaction(txnID)
+	if err != nil {
+		return nil, err
+	}
+	if txn.Payout == nil || txn.Payout.Status == payments.PayoutFailed || txn.Payout.Status == payments.PayoutReversed {
+		return txn.Payout, nil
+	}
+	if txn.Payout.Status == payments.PayoutPending {
+		return txn.Payout, ErrPayoutInProgress
+	}
+
+	payout := *txn.Payout
+	reversalID, err := p.transfer.ReverseTransfer(payout.ID, "reversal:"+txnID)
+	if err != nil {
+		return &payout, err
+	}
+	payout.Status = payments.PayoutReversed
+	payout.ReversalID = reversalID
+	if err := p.txns.RecordPayout(txnID, payout); err != nil {
+		return &payout, err
+	}
+	slog.InfoContext(ctx, "payout reversed", "transaction_id", txnID, "transfer", payout.ID, "reversal", reversalID)
+	return &payout, nil
+}
+
+// record saves the payout state, logging (not returning) failures
+func (p *Payer) record(ctx context.Context, txnID string, payout payments.Payout) {
+	if err := p.txns.RecordPayout(txnID, payout); err != nil {
+		slog.ErrorContext(ctx, "failed to record payout", "transaction_id", txnID, "error", err)
+	}
+}
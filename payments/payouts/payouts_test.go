@@ -0,0 +1,177 @@
+package payouts
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// fakeTransferer fails the first failures transfers, then succeeds
+type fakeTransferer struct {
+	failures  int
+	requests  []*payments.TransferRequest
+	reversals []string
+}
+
+func (f *fakeTransferer) CreateTransfer(req *payments.TransferRequest) (*payments.TransferResponse, error) {
+	f.requests = append(f.requests, req)
+	if len(f.requests) <= f.failures {
+		return nil, errors.New("stripe unavailable")
+	}
+	return &payments.TransferResponse{ID: "tr_1", Destination: req.Destination, Amount: req.Amount, Currency: req.Currency}, nil
+}
+
+func (f *fakeTransferer) ReverseTransfer(transferID, idempotencyKey string) (string, error) {
+	f.reversals = append(f.reversals, transferID)
+	return "trr_1", nil
+}
+
+// collected reports payments with a PaymentIntent as collected, as a
+// provider in mock mode does
+type collected struct{}
+
+func (collected) Collected(txn *payments.Transaction) (bool, error) {
+	return txn.PaymentIntentID != "", nil
+}
+
+func settled(txns *payments.TransactionStore, id string, final float64, currency string) {
+	txns.Restore(&payments.Transaction{
+		ID:              id,
+		UserID:          "user1",
+		Currency:        "USD",
+		TargetCurrency:  currency,
+		FinalAmount:     final,
+		Status:          payments.StatusSuccess,
+		PaymentIntentID: "pi_" + id,
+		PayoutAccount:   "acct_recipient",
+	})
+}
+
+// TestPayRetriesWithOneIdempotencyKey verifies a failed transfer is retried
+// with the same key and the paid payout is recorded in minor units
+func TestPayRetriesWithOneIdempotencyKey(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	settled(txns, "txn1", 98.765, "USD")
+	stripe := &fakeTransferer{failures: 1}
+	payer := NewPayer(txns, collected{}, stripe, &Config{MaxAttempts: 3})
+
+	payout, err := payer.Pay(context.Background(), "txn1")
+	if err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	if payout.Status != payments.PayoutPaid || payout.ID != "tr_1" || payout.Amount != 9877 || payout.Attempts != 2 {
+		t.Errorf("payout = %+v, want paid tr_1 of 9877 after 2 attempts", payout)
+	}
+	if len(stripe.requests) != 2 || stripe.requests[0].IdempotencyKey != stripe.requests[1].IdempotencyKey {
+		t.Errorf("requests = %d, want 2 sharing an idempotency key", len(stripe.requests))
+	}
+	if unpaid := txns.UnpaidPayouts(); len(unpaid) != 0 {
+		t.Errorf("unpaid = %d, want 0", len(unpaid))
+	}
+
+	// Paying again does not transfer twice
+	if _, err := payer.Pay(context.Background(), "txn1"); err != nil || len(stripe.requests) != 2 {
+		t.Errorf("second Pay: err = %v, requests = %d, want no new transfer", err, len(stripe.requests))
+	}
+}
+
+// TestPayFailsAfterMaxAttempts verifies a payout that keeps failing is
+// recorded as failed and left for the next startup
+func TestPayFailsAfterMaxAttempts(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	settled(txns, "txn1", 1500, "JPY")
+	stripe := &fakeTransferer{failures: 5}
+	payer := NewPayer(txns, collected{}, stripe, &Config{MaxAttempts: 2})
+
+	payout, err := payer.Pay(context.Background(), "txn1")
+	if err == nil {
+		t.Fatal("expected Pay to fail")
+	}
+	if payout.Status != payments.PayoutFailed || payout.Attempts != 2 || payout.Amount != 1500 || payout.Error == "" {
+		t.Errorf("payout = %+v, want failed 1500 JPY after 2 attempts", payout)
+	}
+	if unpaid := txns.UnpaidPayouts(); len(unpaid) != 1 {
+		t.Errorf("unpaid = %d, want 1", len(unpaid))
+	}
+}
+
+// TestPayRequiresSettledPayment verifies unsettled payments are not paid out
+func TestPayRequiresSettledPayment(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	txn, err := txns.CreateTransaction("user1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if err := txns.SetPayoutAccount(txn.ID, "acct_recipient"); err != nil {
+		t.Fatalf("SetPayoutAccount: %v", err)
+	}
+	stripe := &fakeTransferer{}
+	if _, err := NewPayer(txns, collected{}, stripe, nil).Pay(context.Background(), txn.ID); !errors.Is(err, ErrNotSettled) {
+		t.Errorf("err = %v, want ErrNotSettled", err)
+	}
+	if len(stripe.requests) != 0 {
+		t.Errorf("requests = %d, want 0", len(stripe.requests))
+	}
+}
+
+// TestDisputedPayoutsAreHeldAndReversed verifies a disputed payment is not
+// paid out while the dispute is open, and a paid payout is reversed once a
+// dispute is accepted
+func TestDisputedPayoutsAreHeldAndReversed(t *testing.T) {
+	ctx := context.Background()
+	txns := payments.NewTransactionStore()
+	stripe := &fakeTransferer{}
+	payer := NewPayer(txns, collected{}, stripe, nil)
+
+	settled(txns, "held", 100, "USD")
+	held, _ := txns.GetTransaction("held")
+	held.Dispute = &payments.Dispute{Status: payments.DisputeOpen, Reason: payments.DisputeNotReceived}
+	if _, err := payer.Pay(ctx, "held"); !errors.Is(err, ErrDisputed) {
+		t.Errorf("paying out an open dispute: err = %v, want ErrDisputed", err)
+	}
+	if len(stripe.requests) != 0 || len(txns.UnpaidPayouts()) != 0 {
+		t.Errorf("requests = %d, unpaid = %d, want the disputed payout held", len(stripe.requests), len(txns.UnpaidPayouts()))
+	}
+	held.Dispute.Status = payments.DisputeRejected
+	if payout, err := payer.Pay(ctx, "held"); err != nil || payout.Status != payments.PayoutPaid {
+		t.Errorf("paying out after the dispute was rejected: payout = %+v, err = %v", payout, err)
+	}
+
+	settled(txns, "paid", 50, "USD")
+	if _, err := payer.Pay(ctx, "paid"); err != nil {
+		t.Fatalf("Pay: %v", err)
+	}
+	paid, _ := txns.GetTransaction("paid")
+	paid.Dispute = &payments.Dispute{Status: payments.DisputeAccepted, Reason: payments.DisputeNotReceived}
+	payout, err := payer.Reverse(ctx, "paid")
+	if err != nil {
+		t.Fatalf("Reverse: %v", err)
+	}
+	if payout.Status != payments.PayoutReversed || payout.ReversalID != "trr_1" || len(stripe.reversals) != 1 || stripe.reversals[0] != "tr_1" {
+		t.Errorf("payout = %+v after %v, want tr_1 reversed once", payout, stripe.reversals)
+	}
+	if _, err := payer.Reverse(ctx, "paid"); err != nil || len(stripe.reversals) != 1 {
+		t.Errorf("second Reverse: err = %v, reversals = %d, want no new reversal", err, len(stripe.reversals))
+	}
+	if _, err := payer.Pay(ctx, "paid"); err != nil || len(stripe.requests) != 2 {
+		t.Errorf("Pay after reversal: err = %v, requests = %d, want no new transfer", err, len(stripe.requests))
+	}
+}
+
+// TestPayRequiresCollectedPayment verifies a settled payment is not paid out
+// unless its funds were collected
+func TestPayRequiresCollectedPayment(t *testing.T) {
+	txns := payments.NewTransactionStore()
+	settled(txns, "txn1", 100, "USD")
+	txn, _ := txns.GetTransaction("txn1")
+	txn.PaymentIntentID = ""
+	stripe := &fakeTransferer{}
+	if _, err := NewPayer(txns, collected{}, stripe, nil).Pay(context.Background(), "txn1"); !errors.Is(err, ErrNotCollected) {
+		t.Errorf("err = %v, want ErrNotCollected", err)
+	}
+	if len(stripe.requests) != 0 {
+		t.Errorf("requests = %d, want 0", len(stripe.requests))
+	}
+}
@@ -0,0 +1,139 @@
+package payments
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// PricingCurve scales each hop's fee by the destination country's risk:
+//
+//	multiplier = Base + CredibilityWeight × (1 - credibility)^Exponent
+//	                  + SuccessRateWeight × (1 - success rate)^Exponent
+//
+// clamped to [MinMultiplier, MaxMultiplier]. The hop fee is the flat hop
+// rate times the multiplier, so reliable destinations cost less than risky ones.
+type PricingCurve struct {
+	Enabled           bool    `json:"enabled"` // When false every hop pays the flat hop rate
+	Base              float64 `json:"base"`    // Multiplier for a perfectly reliable destination
+	CredibilityWeight float64 `json:"credibility_weight"`
+	SuccessRateWeight float64 `json:"success_rate_weight"`
+	Exponent          float64 `json:"exponent"` // Curve shape: 1 = linear, > 1 punishes only very risky countries
+	MinMultiplier     float64 `json:"min_multiplier"`
+	MaxMultiplier     float64 `json:"max_multiplier"`
+}
+
+// DefaultPricingCurve returns the default curve: a 0.5× hop fee for a
+// perfect destination rising to at most 3× for unreliable ones
+func DefaultPricingCurve() PricingCurve {
+	return PricingCurve{
+		Enabled:           true,
+		Base:              0.5,
+		CredibilityWeight: 5,
+		SuccessRateWeight: 5,
+		Exponent:          1,
+		MinMultiplier:     0.5,
+		MaxMultiplier:     3,
+	}
+}
+
+// Validate checks the curve's coefficients
+func (c PricingCurve) Validate() error {
+	switch {
+	case c.Base < 0 || c.CredibilityWeight < 0 || c.SuccessRateWeight < 0:
+		return fmt.Errorf("pricing base and weights must not be negative")
+	case c.Exponent <= 0:
+		return fmt.Errorf("pricing exponent must be positive")
+	case c.MinMultiplier < 0 || c.MaxMultiplier < c.MinMultiplier:
+		return fmt.Errorf("pricing multipliers must satisfy 0 <= min_multiplier <= max_multiplier")
+	}
+	return nil
+}
+
+// Multiplier returns the hop fee multiplier for a destination
+func (c PricingCurve) Multiplier(credibility, successRate float64) float64 {
+	if !c.Enabled {
+		return 1
+	}
+	risk := func(score float64) float64 {
+		return math.Pow(math.Max(0, math.Min(1, 1-score)), c.Exponent)
+	}
+	m := c.Base + c.CredibilityWeight*risk(credibility) + c.SuccessRateWeight*risk(successRate)
+	return math.Max(c.MinMultiplier, math.Min(c.MaxMultiplier, m))
+}
+
+// CountryScorer reports a country's credibility and success rate.
+// Implemented by router.CountryGraph.
+type CountryScorer interface {
+	CountryScore(code string) (credibility, successRate float64, ok bool)
+}
+
+// HopFee is the priced fee for one hop of a transaction's route
+type HopFee struct {
+	From        string  `json:"from"`
+	To          string  `json:"to"`
+	Credibility float64 `json:"credibility,omitempty"`  // Destination credibility when priced
+	SuccessRate float64 `json:"success_rate,omitempty"` // Destination success rate when priced
+	Multiplier  float64 `json:"multiplier"`
+	Rate        float64 `json:"rate"` // Fraction of the amount charged for this hop
+	Fee         float64 `json:"fee"`
+}
+
+// Pricer prices hop fees from destination country scores
+type Pricer struct {
+	scores CountryScorer
+
+	mu    sync.RWMutex
+	curve PricingCurve
+}
+
+// NewPricer creates a pricer using scores and curve
+func NewPricer(scores CountryScorer, curve PricingCurve) *Pricer {
+	return &Pricer{scores: scores, curve: curve}
+}
+
+// Curve returns the curve in effect
+func (p *Pricer) Curve() PricingCurve {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.curve
+}
+
+// SetCurve replaces the curve applied to new transactions
+func (p *Pricer) SetCurve(curve PricingCurve) error {
+	if err := curve.Validate(); err != nil {
+		return err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.curve = curve
+	return nil
+}
+
+// HopFees prices every hop of route for amount at the flat hopRate.
+// Destinations without a score pay the flat rate.
+func (p *Pricer) HopFees(amount, hopRate float64, route []string) []HopFee {
+	curve := p.Curve()
+	fees := make([]HopFee, 0, len(route)-1)
+	for i := 0; i+1 < len(route); i++ {
+		hop := HopFee{From: route[i], To: route[i+1], Multiplier: 1}
+		if credibility, successRate, ok := p.scores.CountryScore(hop.To); ok {
+			hop.Credibility = credibility
+			hop.SuccessRate = successRate
+			hop.Multiplier = curve.Multiplier(credibility, successRate)
+		}
+		hop.Rate = hopRate * hop.Multiplier
+		hop.Fee = amount * hop.Rate
+		fees = append(fees, hop)
+	}
+	return fees
+}
+
+// pricedHopFee returns the fee priced at creation for hop i (from → to), or
+// flat when the transaction was not priced or has been rerouted since
+func (t *Transaction) pricedHopFee(i int, from, to string, flat float64) float64 {
+	if i < len(t.HopFeeBreakdown) && t.HopFeeBreakdown[i].From == from && t.HopFeeBreakdown[i].To == to {
+		return t.HopFeeBreakdown[i].Fee
+	}
+	return flat
+}
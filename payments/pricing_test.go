@@ -0,0 +1,69 @@
+package payments
+
+import (
+	"math"
+	"testing"
+)
+
+// fakeScorer returns fixed credibility and success rates per country
+type fakeScorer map[string][2]float64
+
+func (f fakeScorer) CountryScore(code string) (float64, float64, bool) {
+	score, ok := f[code]
+	return score[0], score[1], ok
+}
+
+func TestPricingCurveMultiplier(t *testing.T) {
+	curve := DefaultPricingCurve()
+
+	if m := curve.Multiplier(1, 1); m != 0.5 {
+		t.Errorf("perfect destination multiplier = %v, want 0.5", m)
+	}
+	if m := curve.Multiplier(0.9, 0.9); math.Abs(m-1.5) > 1e-9 {
+		t.Errorf("multiplier = %v, want 1.5", m)
+	}
+	if m := curve.Multiplier(0, 0); m != 3 {
+		t.Errorf("unreliable destination multiplier = %v, want clamped to 3", m)
+	}
+
+	curve.Enabled = false
+	if m := curve.Multiplier(0, 0); m != 1 {
+		t.Errorf("disabled curve multiplier = %v, want 1", m)
+	}
+
+	if err := (PricingCurve{Exponent: 0}).Validate(); err == nil {
+		t.Error("expected zero exponent to be rejected")
+	}
+	if err := (PricingCurve{Exponent: 1, MinMultiplier: 2, MaxMultiplier: 1}).Validate(); err == nil {
+		t.Error("expected min_multiplier above max_multiplier to be rejected")
+	}
+}
+
+func TestTransactionHopFeesArePricedPerDestination(t *testing.T) {
+	store := NewTransactionStore()
+	store.SetPricer(NewPricer(fakeScorer{
+		"GBR": {1, 1},     // 0.5x
+		"DEU": {0.9, 0.9}, // 1.5x
+	}, DefaultPricingCurve()))
+
+	txn, err := store.CreateTransaction("user1", 1000, "USD", "EUR", []string{"USA", "GBR", "DEU", "FRA"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if len(txn.HopFeeBreakdown) != 3 {
+		t.Fatalf("breakdown has %d hops, want 3", len(txn.HopFeeBreakdown))
+	}
+
+	flat := 1000 * DefaultFeeConfig().HopFeePercent
+	want := []float64{0.5 * flat, 1.5 * flat, flat} // FRA has no score and pays the flat rate
+	var sum float64
+	for i, hop := range txn.HopFeeBreakdown {
+		if math.Abs(hop.Fee-want[i]) > 1e-9 {
+			t.Errorf("hop %s->%s fee = %v, want %v", hop.From, hop.To, hop.Fee, want[i])
+		}
+		sum += hop.Fee
+	}
+	if math.Abs(txn.HopFees-sum) > 1e-9 {
+		t.Errorf("HopFees = %v, want the breakdown sum %v", txn.HopFees, sum)
+	}
+}
@@ -0,0 +1,138 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Payment provider names
+const (
+	ProviderStripe       = "stripe"
+	ProviderBankTransfer = "bank_transfer"
+)
+
+// ErrUnknownProvider is returned when selecting a provider that is not configured
+var ErrUnknownProvider = errors.New("unknown payment provider")
+
+// Provider collects the payer's funds before a payment is settled through the
+// mesh, and refunds them when it cannot be. StripeClient is the default.
+type Provider interface {
+	Name() string
+	CreatePaymentIntent(req *PaymentIntentRequest) (*PaymentIntentResponse, error)
+	ConfirmPaymentIntent(paymentIntentID string) (*PaymentIntentResponse, error)
+	GetPaymentIntent(paymentIntentID string) (*PaymentIntentResponse, error)
+	RefundPayment(paymentIntentID string, amount int64, reason string) (*RefundResponse, error)
+	GetPublishableKey() string // For the frontend; empty when the provider needs none
+	IsMockMode() bool          // No real funds are moved
+}
+
+// Name returns the provider name of Stripe
+func (c *StripeClient) Name() string {
+	return ProviderStripe
+}
+
+// CollectedBy returns the provider that collected the payment
+func (t *Transaction) CollectedBy() string {
+	if t.PaymentProvider == "" {
+		return ProviderStripe
+	}
+	return t.PaymentProvider
+}
+
+// SetPaymentProvider records the provider collecting a transaction's payment
+func (s *TransactionStore) SetPaymentProvider(txnID, provider string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if txn, ok := s.transactions[txnID]; ok {
+		txn.PaymentProvider = provider
+	}
+}
+
+// Providers selects the provider collecting a payment: the one the payer
+// asked for, else the one configured for the payment currency, else the
+// fallback. Configure before use; it is not safe to change concurrently.
+type Providers struct {
+	providers  map[string]Provider
+	currencies map[string]string
+	fallback   string
+}
+
+// NewProviders creates a registry using fallback for currencies without a provider
+func NewProviders(fallback Provider) *Providers {
+	p := &Providers{
+		providers:  make(map[string]Provider),
+		currencies: make(map[string]string),
+		fallback:   fallback.Name(),
+	}
+	p.Register(fallback)
+	return p
+}
+
+// Register adds a provider, replacing any with the same name
+func (p *Providers) Register(provider Provider) {
+	p.providers[provider.Name()] = provider
+}
+
+// SetCurrencyProvider collects payments in currency through the named provider
+func (p *Providers) SetCurrencyProvider(currency, name string) error {
+	if _, ok := p.providers[name]; !ok {
+		return fmt.Errorf("%w %q for %s", ErrUnknownProvider, name, currency)
+	}
+	p.currencies[strings.ToUpper(currency)] = name
+	return nil
+}
+
+// Get returns the named provider. An empty name is Stripe, which collected
+// payments before providers were selectable.
+func (p *Providers) Get(name string) (Provider, error) {
+	if name == "" {
+		name = ProviderStripe
+	}
+	provider, ok := p.providers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownProvider, name)
+	}
+	return provider, nil
+}
+
+// Select returns the provider to collect a payment in currency, name
+// overriding the currency's provider when set
+func (p *Providers) Select(name, currency string) (Provider, error) {
+	if name != "" {
+		return p.Get(name)
+	}
+	if name, ok := p.currencies[strings.ToUpper(currency)]; ok {
+		return p.Get(name)
+	}
+	return p.Get(p.fallback)
+}
+
+// Collected reports whether the provider that collected a payment has
+// received its funds. A payment without a PaymentIntent was never collected
+// through a provider.
+func (p *Providers) Collected(txn *Transaction) (bool, error) {
+	if txn.PaymentIntentID == "" {
+		return false, nil
+	}
+	provider, err := p.Get(txn.CollectedBy())
+	if err != nil {
+		return false, err
+	}
+	intent, err := provider.GetPaymentIntent(txn.PaymentIntentID)
+	if err != nil {
+		return false, err
+	}
+	return intent.Status == IntentSucceeded, nil
+}
+
+// Names returns the registered provider names, sorted
+func (p *Providers) Names() []string {
+	names := make([]string, 0, len(p.providers))
+	for name := range p.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,64 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestProvidersSelect verifies the requested provider wins over the
+// currency's, which wins over the fallback
+func TestProvidersSelect(t *testing.T) {
+	providers := NewProviders(&StripeClient{})
+	if err := providers.SetCurrencyProvider("EUR", ProviderBankTransfer); !errors.Is(err, ErrUnknownProvider) {
+		t.Fatalf("err = %v, want ErrUnknownProvider before registering", err)
+	}
+	providers.Register(NewBankTransferProvider())
+	if err := providers.SetCurrencyProvider("eur", ProviderBankTransfer); err != nil {
+		t.Fatalf("SetCurrencyProvider: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name, currency, want string
+	}{
+		{"", "USD", ProviderStripe},
+		{"", "EUR", ProviderBankTransfer},
+		{ProviderStripe, "EUR", ProviderStripe},
+		{ProviderBankTransfer, "USD", ProviderBankTransfer},
+	} {
+		provider, err := providers.Select(tc.name, tc.currency)
+		if err != nil || provider.Name() != tc.want {
+			t.Errorf("Select(%q, %s) = %v, %v, want %s", tc.name, tc.currency, provider, err, tc.want)
+		}
+	}
+	if _, err := providers.Select("adyen", "USD"); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("err = %v, want ErrUnknownProvider", err)
+	}
+
+	// Payments collected before providers were selectable went through Stripe
+	if provider, err := providers.Get((&Transaction{}).CollectedBy()); err != nil || provider.Name() != ProviderStripe {
+		t.Errorf("Get(legacy) = %v, %v, want stripe", provider, err)
+	}
+}
+
+// TestBankTransferReference verifies an idempotency key always yields the
+// same reference and only bank transfer references are confirmed
+func TestBankTransferReference(t *testing.T) {
+	bank := NewBankTransferProvider()
+	first, err := bank.CreatePaymentIntent(&PaymentIntentRequest{Amount: 5000, Currency: "EUR", IdempotencyKey: "user1:key"})
+	if err != nil {
+		t.Fatalf("CreatePaymentIntent: %v", err)
+	}
+	again, _ := bank.CreatePaymentIntent(&PaymentIntentRequest{Amount: 5000, Currency: "EUR", IdempotencyKey: "user1:key"})
+	other, _ := bank.CreatePaymentIntent(&PaymentIntentRequest{Amount: 5000, Currency: "EUR"})
+	if first.ID != again.ID || first.ID == other.ID || first.Status != "requires_action" {
+		t.Errorf("references = %s, %s, %s (status %s), want the first two equal", first.ID, again.ID, other.ID, first.Status)
+	}
+
+	confirmed, err := bank.ConfirmPaymentIntent(first.ID)
+	if err != nil || confirmed.Status != "succeeded" {
+		t.Errorf("ConfirmPaymentIntent = %+v, %v, want succeeded", confirmed, err)
+	}
+	if _, err := bank.ConfirmPaymentIntent("pi_123"); err == nil {
+		t.Error("expected a Stripe PaymentIntent not to be confirmed")
+	}
+}
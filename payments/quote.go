@@ -0,0 +1,244 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// DefaultQuoteTTL is how long a quoted exchange rate is honored
+const DefaultQuoteTTL = 2 * time.Minute
+
+// quoteIDPrefix marks quote IDs (the rest is the signed quote)
+const quoteIDPrefix = "qt_"
+
+// Quote errors
+var (
+	ErrQuoteInvalid = errors.New("invalid quote")
+	ErrQuoteExpired = errors.New("quote expired")
+)
+
+// Quote is a priced payment: its route, fees and the exchange rates locked
+// until ExpiresAt. The ID is the signed quote itself, so quotes need no storage.
+type Quote struct {
+	ID             string             `json:"quote_id"`
+	UserID         string             `json:"-"`
+	Amount         float64            `json:"amount"`
+	Currency       string             `json:"currency"`
+	TargetCurrency string             `json:"target_currency"`
+	Route          []string           `json:"route"`
+	FXRates        map[string]float64 `json:"fx_rates"`     // Locked per-USD rates of the route's countries
+	FXRate         float64            `json:"fx_rate"`      // Source → target rate across the route
+	TotalFees      float64            `json:"total_fees"`   // In the source currency
+	FinalAmount    float64            `json:"final_amount"` // Received, in the target currency
+	IssuedAt       time.Time          `json:"issued_at"`
+	ExpiresAt      time.Time          `json:"expires_at"`
+}
+
+// quotePayload is the signed part of a quote ID
+type quotePayload struct {
+	UserID         string             `json:"u"`
+	Amount         float64            `json:"a"`
+	Currency       string             `json:"c"`
+	TargetCurrency string             `json:"tc"`
+	Route          []string           `json:"r"`
+	FXRates        map[string]float64 `json:"fx"`
+	FXRate         float64            `json:"x"`
+	TotalFees      float64            `json:"f"`
+	FinalAmount    float64            `json:"fa"`
+	IssuedAt       int64              `json:"i"`
+	ExpiresAt      int64              `json:"e"`
+}
+
+// QuoteConfig configures quote signing
+type QuoteConfig struct {
+	TTL        time.Duration
+	SigningKey []byte // Empty = random per process (quotes do not survive restarts)
+}
+
+// DefaultQuoteConfig returns the default quote configuration
+func DefaultQuoteConfig() QuoteConfig {
+	return QuoteConfig{TTL: DefaultQuoteTTL}
+}
+
+// QuoteSigner issues and verifies quote IDs
+type QuoteSigner struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewQuoteSigner creates a signer from cfg
+func NewQuoteSigner(cfg QuoteConfig) *QuoteSigner {
+	key := cfg.SigningKey
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		rand.Read(key)
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultQuoteTTL
+	}
+	return &QuoteSigner{key: key, ttl: ttl}
+}
+
+// TTL returns how long issued quotes are valid
+func (s *QuoteSigner) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue stamps q with its issue and expiry times and signs it into q.ID
+func (s *QuoteSigner) Issue(q *Quote, now time.Time) {
+	q.IssuedAt = now.UTC().Truncate(time.Second)
+	q.ExpiresAt = q.IssuedAt.Add(s.ttl)
+	payload, _ := json.Marshal(quotePayload{
+		UserID:         q.UserID,
+		Amount:         q.Amount,
+		Currency:       q.Currency,
+		TargetCurrency: q.TargetCurrency,
+		Route:          q.Route,
+		FXRates:        q.FXRates,
+		FXRate:         q.FXRate,
+		TotalFees:      q.TotalFees,
+		FinalAmount:    q.FinalAmount,
+		IssuedAt:       q.IssuedAt.Unix(),
+		ExpiresAt:      q.ExpiresAt.Unix(),
+	})
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	q.ID = quoteIDPrefix + encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+}
+
+// Verify decodes a quote ID, returning ErrQuoteInvalid if it was not issued
+// by this signer and ErrQuoteExpired (with the quote) once it has expired
+func (s *QuoteSigner) Verify(id string, now time.Time) (*Quote, error) {
+	encoded, sig, ok := strings.Cut(strings.TrimPrefix(id, quoteIDPrefix), ".")
+	if !ok || !strings.HasPrefix(id, quoteIDPrefix) {
+		return nil, ErrQuoteInvalid
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, s.sign(encoded)) {
+		return nil, ErrQuoteInvalid
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrQuoteInvalid
+	}
+	var p quotePayload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, ErrQuoteInvalid
+	}
+
+	q := &Quote{
+		ID:             id,
+		UserID:         p.UserID,
+		Amount:         p.Amount,
+		Currency:       p.Currency,
+		TargetCurrency: p.TargetCurrency,
+		Route:          p.Route,
+		FXRates:        p.FXRates,
+		FXRate:         p.FXRate,
+		TotalFees:      p.TotalFees,
+		FinalAmount:    p.FinalAmount,
+		IssuedAt:       time.Unix(p.IssuedAt, 0).UTC(),
+		ExpiresAt:      time.Unix(p.ExpiresAt, 0).UTC(),
+	}
+	if !now.Before(q.ExpiresAt) {
+		return q, ErrQuoteExpired
+	}
+	return q, nil
+}
+
+func (s *QuoteSigner) sign(encoded string) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(quoteIDPrefix + encoded))
+	return h.Sum(nil)
+}
+
+// PreviewTransaction prices a transaction exactly as CreateTransaction would
+// without storing it
+func (s *TransactionStore) PreviewTransaction(userID string, amount float64, currency, targetCurrency string, route []string, haltedNodes map[string]bool) (*Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.newTransaction(userID, amount, currency, targetCurrency, route, haltedNodes)
+}
+
+// CreateQuotedTransaction creates a pending transaction for q, locking the
+// quoted exchange rates until the quote expires
+func (s *TransactionStore) CreateQuotedTransaction(userID string, q *Quote, haltedNodes map[string]bool) (*Transaction, error) {
+	s.mu.Lock()
+	if err := s.checkOrgLimitLocked(userID, toVolumeCurrency(s.currencyRatesLocked(), q.Amount, q.Currency)); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	txn, err := s.newTransaction(userID, q.Amount, q.Currency, q.TargetCurrency, q.Route, haltedNodes)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	expiresAt := q.ExpiresAt
+	txn.QuoteID = q.ID
+	txn.QuotedFXRates = q.FXRates
+	txn.QuoteExpiresAt = &expiresAt
+
+	s.addLocked(txn)
+	s.mu.Unlock()
+
+	s.publishEvent(natsClient.SettlementCreated, txn.ID)
+	return txn, nil
+}
+
+// EstimateSettlement simulates txn's hops without failures, returning the
+// amount received in the target currency and the overall exchange rate
+func EstimateSettlement(txn *Transaction, fxRates map[string]float64) (finalAmount, fxRate float64) {
+	fx := newFXConverter(context.Background(), fxRates, txn.Route)
+	flat := txn.Amount * txn.feeRates(DefaultFeeConfig()).HopFeePercent
+	amount := txn.Amount - txn.TotalFees
+	fxRate = 1.0
+	for i := 0; i+1 < len(txn.Route); i++ {
+		from, to := txn.Route[i], txn.Route[i+1]
+		hopFee := fx.toHeld(txn.pricedHopFee(i, from, to, flat))
+		rate, _ := fx.next(from, to)
+		amount = (amount - hopFee) * rate
+		fxRate *= rate
+	}
+	return amount, fxRate
+}
+
+// fxRatesAt returns the rates to settle txn with: current, overridden by the
+// quoted rates while the quote is valid
+func (t *Transaction) fxRatesAt(ctx context.Context, now time.Time, current map[string]float64) map[string]float64 {
+	if t.QuotedFXRates == nil || t.QuoteExpiresAt == nil {
+		return current
+	}
+	if !now.Before(*t.QuoteExpiresAt) {
+		slog.InfoContext(ctx, "quote expired, settling at current rates", "transaction_id", t.ID, "expired_at", *t.QuoteExpiresAt)
+		return current
+	}
+	rates := make(map[string]float64, len(current)+len(t.QuotedFXRates))
+	for code, rate := range current {
+		rates[code] = rate
+	}
+	for code, rate := range t.QuotedFXRates {
+		rates[code] = rate
+	}
+	return rates
+}
+
+// LockedRates returns the rates of route's countries from fxRates
+func LockedRates(route []string, fxRates map[string]float64) map[string]float64 {
+	locked := make(map[string]float64, len(route))
+	for _, code := range route {
+		if rate, ok := fxRates[code]; ok {
+			locked[code] = rate
+		}
+	}
+	return locked
+}
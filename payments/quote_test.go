@@ -0,0 +1,70 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestQuoteSignerVerifies(t *testing.T) {
+	signer := NewQuoteSigner(QuoteConfig{TTL: time.Minute, SigningKey: []byte("test-key")})
+	now := time.Now()
+	quote := &Quote{UserID: "user-1", Amount: 100, Currency: "USD", TargetCurrency: "GBP",
+		Route: []string{"USA", "GBR"}, FXRates: map[string]float64{"USA": 1, "GBR": 0.79}}
+	signer.Issue(quote, now)
+
+	got, err := signer.Verify(quote.ID, now.Add(30*time.Second))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if got.UserID != "user-1" || got.Amount != 100 || got.FXRates["GBR"] != 0.79 || !got.ExpiresAt.Equal(quote.ExpiresAt) {
+		t.Errorf("verified quote = %+v, want the issued quote", got)
+	}
+
+	if _, err := signer.Verify(quote.ID, now.Add(2*time.Minute)); !errors.Is(err, ErrQuoteExpired) {
+		t.Errorf("expired quote: err = %v, want ErrQuoteExpired", err)
+	}
+	other := NewQuoteSigner(QuoteConfig{SigningKey: []byte("other-key")})
+	if _, err := other.Verify(quote.ID, now); !errors.Is(err, ErrQuoteInvalid) {
+		t.Errorf("foreign quote: err = %v, want ErrQuoteInvalid", err)
+	}
+	if _, err := signer.Verify(quote.ID[:len(quote.ID)-2]+"xx", now); !errors.Is(err, ErrQuoteInvalid) {
+		t.Errorf("tampered quote: err = %v, want ErrQuoteInvalid", err)
+	}
+}
+
+func TestQuotedTransactionSettlesAtLockedRate(t *testing.T) {
+	store := NewTransactionStore()
+	route := []string{"USA", "GBR"}
+	locked := map[string]float64{"USA": 1, "GBR": 0.79}
+	current := map[string]float64{"USA": 1, "GBR": 0.5}
+
+	preview, _ := store.PreviewTransaction("user-1", 100, "USD", "GBP", route, nil)
+	final, rate := EstimateSettlement(preview, locked)
+	if !approxEqual(rate, 0.79) {
+		t.Fatalf("quoted rate = %v, want 0.79", rate)
+	}
+
+	quote := &Quote{Amount: 100, Currency: "USD", TargetCurrency: "GBP", Route: route, FXRates: locked,
+		ExpiresAt: time.Now().Add(time.Minute)}
+	txn, err := store.CreateQuotedTransaction("user-1", quote, nil)
+	if err != nil {
+		t.Fatalf("CreateQuotedTransaction: %v", err)
+	}
+	if err := store.ProcessTransaction(context.Background(), txn.ID, current, 0); err != nil {
+		t.Fatalf("ProcessTransaction: %v", err)
+	}
+	done, _ := store.GetTransaction(txn.ID)
+	if !approxEqual(done.FinalAmount, final) {
+		t.Errorf("final amount = %v, want the quoted %v", done.FinalAmount, final)
+	}
+
+	quote.ExpiresAt = time.Now().Add(-time.Second)
+	expired, _ := store.CreateQuotedTransaction("user-1", quote, nil)
+	store.ProcessTransaction(context.Background(), expired.ID, current, 0)
+	done, _ = store.GetTransaction(expired.ID)
+	if !approxEqual(done.EffectiveFXRate(), 0.5) {
+		t.Errorf("expired quote settled at %v, want the current rate 0.5", done.EffectiveFXRate())
+	}
+}
@@ -0,0 +1,130 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// stuckReason is recorded on the attempt of a run the reconciler found stuck
+const stuckReason = "stuck in processing"
+
+// ErrNotStuck is returned when recovering a transaction that is no longer
+// pending or processing
+var ErrNotStuck = errors.New("transaction is not pending or processing")
+
+// LastActivity returns when the transaction last made progress: the start of
+// its current run, the end of its last attempt, or its creation
+func (t *Transaction) LastActivity() time.Time {
+	if t.ProcessedAt != nil {
+		return *t.ProcessedAt
+	}
+	if n := len(t.Attempts); n > 0 && !t.Attempts[n-1].EndedAt.IsZero() {
+		return t.Attempts[n-1].EndedAt
+	}
+	return t.CreatedAt
+}
+
+// SettlementStarted reports whether the payer confirmed the transaction and
+// it entered the mesh at least once
+func (t *Transaction) SettlementStarted() bool {
+	return t.ProcessedAt != nil || len(t.Attempts) > 0 || t.Status == StatusProcessing
+}
+
+// StuckTransactions returns the pending and processing transactions with no
+// progress since before, oldest first
+func (s *TransactionStore) StuckTransactions(before time.Time) []*Transaction {
+	s.mu.RLock()
+	stuck := make([]*Transaction, 0)
+	for _, txn := range s.transactions {
+		if (txn.Status == StatusPending || txn.Status == StatusProcessing) && txn.LastActivity().Before(before) {
+			stuck = append(stuck, txn)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(stuck, func(i, j int) bool { return stuck[i].CreatedAt.Before(stuck[j].CreatedAt) })
+	return stuck
+}
+
+// RequeueStuck returns a stuck transaction to pending so it can be processed,
+// failed or held: the hops its stalled run settled are compensated and the
+// run is recorded as an attempt. Pending transactions are left as they are.
+func (s *TransactionStore) RequeueStuck(ctx context.Context, txnID string) error {
+	return s.requeue(ctx, txnID, stuckReason, ErrNotStuck, StatusPending, StatusProcessing)
+}
+
+// EscalateStuck requeues a stuck transaction and holds it in the review
+// queue, where an admin approves (resumes) or rejects (fails) it
+func (s *TransactionStore) EscalateStuck(ctx context.Context, txnID string, reasons []string) error {
+	ctx, unlock, err := s.LockTransaction(ctx, txnID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := s.RequeueStuck(ctx, txnID); err != nil {
+		return err
+	}
+	if err := s.HoldForReview(txnID, reasons); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if txn, ok := s.transactions[txnID]; ok {
+		txn.Review.Escalated = true
+	}
+	return nil
+}
+
+// requeue compensates the hops a stopped run settled, records the run as an
+// attempt and resets the transaction to pending. notFrom is returned if the
+// transaction's status isn't one of from.
+func (s *TransactionStore) requeue(ctx context.Context, txnID, reason string, notFrom error, from ...TransactionStatus) error {
+	ctx, unlock, err := s.LockTransaction(ctx, txnID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s.mu.Lock()
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("transaction not found: %s", txnID)
+	}
+	allowed := false
+	for _, status := range from {
+		allowed = allowed || txn.Status == status
+	}
+	if !allowed {
+		s.mu.Unlock()
+		return notFrom
+	}
+	failedAt := ""
+	if txn.HopsCompleted < len(txn.Route) {
+		failedAt = txn.Route[txn.HopsCompleted]
+	}
+	s.mu.Unlock()
+
+	s.compensate(ctx, txnID, failedAt, reason)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	txn.FailedAt = failedAt
+	if txn.ProcessedAt != nil {
+		now := time.Now()
+		txn.CompletedAt = &now
+		recordAttemptLocked(txn, reason)
+	}
+	txn.Status = StatusPending
+	txn.HopResults = make([]HopResult, 0)
+	txn.HopsCompleted = 0
+	txn.FailedAt = ""
+	txn.ProcessedAt = nil
+	txn.CompletedAt = nil
+	s.countLocked(txn)
+	return nil
+}
@@ -0,0 +1,52 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestStuckTransactionsAreRequeuedAndEscalated verifies only idle pending
+// and processing transactions are listed as stuck, and escalating one
+// reverses its settled hops and holds it for review
+func TestStuckTransactionsAreRequeuedAndEscalated(t *testing.T) {
+	store := NewTransactionStore()
+	stalled, _ := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR", "DEU"}, nil)
+	fresh, _ := store.CreateTransaction("user-2", 50, "USD", "USD", []string{"USA", "GBR"}, nil)
+	done, _ := store.CreateTransaction("user-3", 50, "USD", "USD", []string{"USA", "GBR"}, nil)
+	store.ProcessTransaction(context.Background(), done.ID, nil, 0)
+
+	// A run that stalled after its first hop an hour ago
+	started := time.Now().Add(-time.Hour)
+	store.mu.Lock()
+	for _, txn := range []*Transaction{store.transactions[stalled.ID], store.transactions[done.ID]} {
+		txn.CreatedAt = started
+	}
+	txn := store.transactions[stalled.ID]
+	txn.Status, txn.ProcessedAt, txn.HopsCompleted = StatusProcessing, &started, 1
+	txn.HopResults = []HopResult{{FromCountry: "USA", ToCountry: "GBR", Success: true, AmountOut: 98}}
+	store.mu.Unlock()
+
+	stuck := store.StuckTransactions(time.Now().Add(-10 * time.Minute))
+	if len(stuck) != 1 || stuck[0].ID != stalled.ID {
+		t.Fatalf("expected only %s stuck, got %d", stalled.ID, len(stuck))
+	}
+	if len(store.StuckTransactions(time.Now().Add(time.Minute))) != 2 {
+		t.Errorf("expected the fresh pending payment stuck past its cutoff too (%s)", fresh.ID)
+	}
+
+	if err := store.EscalateStuck(context.Background(), stalled.ID, []string{"stuck payment: not settled"}); err != nil {
+		t.Fatalf("EscalateStuck: %v", err)
+	}
+	snap, _ := store.Snapshot(stalled.ID)
+	if snap.Status != StatusPendingReview || snap.Review == nil || !snap.Review.Escalated {
+		t.Fatalf("expected an escalated review hold, got %s %+v", snap.Status, snap.Review)
+	}
+	if len(snap.Compensations) != 1 || len(snap.Attempts) != 1 || snap.HopsCompleted != 0 {
+		t.Errorf("expected the stalled run compensated and recorded, got %d compensations, %d attempts", len(snap.Compensations), len(snap.Attempts))
+	}
+	if err := store.RequeueStuck(context.Background(), done.ID); !errors.Is(err, ErrNotStuck) {
+		t.Errorf("expected ErrNotStuck for a settled payment, got %v", err)
+	}
+}
@@ -0,0 +1,64 @@
+package payments
+
+import (
+	"math"
+	"time"
+)
+
+// RefundReason is why a payment was refunded, which decides how much of it
+// the refund policy returns
+type RefundReason string
+
+const (
+	RefundMeshFailure   RefundReason = "mesh_failure"     // Every route failed, or the payment got stuck
+	RefundUserCancelled RefundReason = "user_cancelled"   // The payer cancelled before settlement
+	RefundRejected      RefundReason = "rejected"         // An admin rejected the payment
+	RefundDisputed      RefundReason = "dispute_accepted" // An admin accepted the payer's dispute
+	RefundExternal      RefundReason = "external"         // Refunded outside PLM, e.g. from the Stripe dashboard
+)
+
+// Refund is what was returned to the payer when a payment was refunded
+type Refund struct {
+	ID         string       `json:"id"` // Provider refund ID
+	Reason     RefundReason `json:"reason"`
+	Amount     float64      `json:"amount"`   // Returned to the payer, in Currency
+	Retained   float64      `json:"retained"` // Fees kept by the platform
+	Currency   string       `json:"currency"`
+	RefundedAt time.Time    `json:"refunded_at"`
+}
+
+// Partial reports whether part of the payment was kept
+func (r Refund) Partial() bool {
+	return r.Retained > 0
+}
+
+// MinorAmount returns the refunded amount in minor units of its currency
+func (r Refund) MinorAmount() int64 {
+	return MinorUnits(r.Amount, r.Currency)
+}
+
+// RefundPolicy decides how much of a payment a refund returns
+type RefundPolicy struct {
+	RetainBaseFeeOnCancel bool // Keep the platform fee when the payer cancels
+}
+
+// DefaultRefundPolicy keeps the platform fee of cancelled payments and
+// refunds everything else in full
+func DefaultRefundPolicy() RefundPolicy {
+	return RefundPolicy{RetainBaseFeeOnCancel: true}
+}
+
+// Refund returns the refund of txn for reason, without a provider ID. Only
+// a payer's cancellation can keep fees; failures are refunded in full.
+func (p RefundPolicy) Refund(txn *Transaction, reason RefundReason) Refund {
+	refund := Refund{
+		Reason:   reason,
+		Amount:   txn.Amount,
+		Currency: txn.Currency,
+	}
+	if reason == RefundUserCancelled && p.RetainBaseFeeOnCancel {
+		refund.Retained = math.Round(math.Min(txn.BaseFee, txn.Amount)*100) / 100
+		refund.Amount = txn.Amount - refund.Retained
+	}
+	return refund
+}
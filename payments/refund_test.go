@@ -0,0 +1,40 @@
+package payments
+
+import "testing"
+
+func TestRefundPolicyRetainsBaseFeeOnlyOnCancel(t *testing.T) {
+	txn := &Transaction{Amount: 100, BaseFee: 2.505, Currency: "USD"}
+
+	cancelled := DefaultRefundPolicy().Refund(txn, RefundUserCancelled)
+	if cancelled.Retained != 2.51 || !approxEqual(cancelled.Amount, 97.49) || !cancelled.Partial() || cancelled.MinorAmount() != 9749 {
+		t.Errorf("cancelled = %+v, want 2.51 kept and 97.49 (9749 cents) refunded", cancelled)
+	}
+	for _, reason := range []RefundReason{RefundMeshFailure, RefundRejected, RefundDisputed} {
+		if r := DefaultRefundPolicy().Refund(txn, reason); r.Amount != 100 || r.Partial() {
+			t.Errorf("%s = %+v, want a full refund", reason, r)
+		}
+	}
+	if r := (RefundPolicy{}).Refund(txn, RefundUserCancelled); r.Amount != 100 || r.Partial() {
+		t.Errorf("without retention: %+v, want a full refund", r)
+	}
+
+	// The fee kept never exceeds the payment
+	small := &Transaction{Amount: 1, BaseFee: 2.5}
+	if r := DefaultRefundPolicy().Refund(small, RefundUserCancelled); r.Retained != 1 || r.Amount != 0 {
+		t.Errorf("small payment = %+v, want all of it kept", r)
+	}
+}
+
+func TestRefundMinorAmountUsesCurrencyPrecision(t *testing.T) {
+	yen := &Transaction{Amount: 12345, BaseFee: 185, Currency: "JPY"}
+	if r := DefaultRefundPolicy().Refund(yen, RefundMeshFailure); r.MinorAmount() != 12345 {
+		t.Errorf("JPY refund = %d minor units, want 12345 yen", r.MinorAmount())
+	}
+	if r := DefaultRefundPolicy().Refund(yen, RefundUserCancelled); r.MinorAmount() != 12160 {
+		t.Errorf("cancelled JPY refund = %+v (%d minor units), want 12160 yen", r, r.MinorAmount())
+	}
+	dinar := &Transaction{Amount: 10.125, Currency: "BHD"}
+	if r := DefaultRefundPolicy().Refund(dinar, RefundMeshFailure); r.MinorAmount() != 10125 {
+		t.Errorf("BHD refund = %d minor units, want 10125 fils", r.MinorAmount())
+	}
+}
@@ -0,0 +1,106 @@
+package payments
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+var (
+	// ErrHeldForReview is returned when processing a transaction held for compliance review
+	ErrHeldForReview = errors.New("transaction is held for compliance review")
+	// ErrNotHeld is returned when resolving a transaction that is not held
+	ErrNotHeld = errors.New("transaction is not held for review")
+)
+
+// ReviewDecision is how an admin resolved a held transaction
+type ReviewDecision string
+
+const (
+	ReviewApproved ReviewDecision = "approved"
+	ReviewRejected ReviewDecision = "rejected"
+)
+
+// Review records why a transaction was held for compliance review and how
+// the hold was resolved
+type Review struct {
+	Reasons    []string       `json:"reasons"`
+	HeldAt     time.Time      `json:"held_at"`
+	Decision   ReviewDecision `json:"decision,omitempty"`
+	ReviewedBy string         `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time     `json:"reviewed_at,omitempty"`
+	Note       string         `json:"note,omitempty"`
+	Escalated  bool           `json:"escalated,omitempty"` // Held by the stuck payment reconciler rather than screening
+}
+
+// HoldForReview moves a pending transaction to pending_review. It cannot be
+// processed until ResolveReview approves it.
+func (s *TransactionStore) HoldForReview(txnID string, reasons []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		return errors.New("transaction not found")
+	}
+	if txn.Status != StatusPending {
+		return errors.New("only pending transactions can be held")
+	}
+	txn.Status = StatusPendingReview
+	txn.Review = &Review{Reasons: reasons, HeldAt: time.Now()}
+	s.countLocked(txn)
+	return nil
+}
+
+// ResolveReview approves a held transaction, returning it to pending, or
+// rejects it, failing it before it enters the mesh
+func (s *TransactionStore) ResolveReview(txnID string, decision ReviewDecision, reviewer, note string) (*Transaction, error) {
+	if decision != ReviewApproved && decision != ReviewRejected {
+		return nil, errors.New("decision must be approved or rejected")
+	}
+
+	s.mu.Lock()
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		s.mu.Unlock()
+		return nil, errors.New("transaction not found")
+	}
+	if txn.Status != StatusPendingReview {
+		s.mu.Unlock()
+		return nil, ErrNotHeld
+	}
+
+	now := time.Now()
+	review := *txn.Review
+	review.Decision, review.ReviewedBy, review.ReviewedAt, review.Note = decision, reviewer, &now, note
+	txn.Review = &review
+	if decision == ReviewApproved {
+		txn.Status = StatusPending
+	} else {
+		txn.Status = StatusFailed
+		txn.CompletedAt = &now
+		recordAttemptLocked(txn, "rejected in compliance review")
+	}
+	s.countLocked(txn)
+	s.mu.Unlock()
+
+	if decision == ReviewRejected {
+		s.notifyStatus(EventPaymentFailed, txnID)
+	}
+	return s.Snapshot(txnID)
+}
+
+// HeldTransactions returns the transactions awaiting compliance review, oldest first
+func (s *TransactionStore) HeldTransactions() []*Transaction {
+	s.mu.RLock()
+	held := make([]*Transaction, 0)
+	for _, txn := range s.transactions {
+		if txn.Status == StatusPendingReview {
+			held = append(held, txn)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(held, func(i, j int) bool { return held[i].CreatedAt.Before(held[j].CreatedAt) })
+	return held
+}
@@ -0,0 +1,45 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestHeldTransactionWaitsForReview(t *testing.T) {
+	ctx := context.Background()
+	store := NewTransactionStore()
+	route := []string{"USA", "GBR"}
+
+	held, _ := store.CreateTransaction("user-1", 50000, "USD", "GBP", route, nil)
+	if err := store.HoldForReview(held.ID, []string{"large amount"}); err != nil {
+		t.Fatalf("HoldForReview: %v", err)
+	}
+	if err := store.ProcessTransaction(ctx, held.ID, nil, 0); !errors.Is(err, ErrHeldForReview) {
+		t.Errorf("processing a held transaction: err = %v, want ErrHeldForReview", err)
+	}
+	if queue := store.HeldTransactions(); len(queue) != 1 || queue[0].ID != held.ID {
+		t.Errorf("review queue = %v, want the held transaction", queue)
+	}
+
+	approved, err := store.ResolveReview(held.ID, ReviewApproved, "treasurer", "documents checked")
+	if err != nil {
+		t.Fatalf("ResolveReview: %v", err)
+	}
+	if approved.Status != StatusPending || approved.Review.Decision != ReviewApproved || approved.Review.ReviewedBy != "treasurer" {
+		t.Errorf("approved transaction = %s %+v, want pending with the review recorded", approved.Status, approved.Review)
+	}
+	if _, err := store.ResolveReview(held.ID, ReviewRejected, "treasurer", ""); !errors.Is(err, ErrNotHeld) {
+		t.Errorf("resolving twice: err = %v, want ErrNotHeld", err)
+	}
+	if err := store.ProcessTransaction(ctx, held.ID, nil, 0); err != nil {
+		t.Errorf("processing an approved transaction: %v", err)
+	}
+
+	rejected, _ := store.CreateTransaction("user-1", 60000, "USD", "GBP", route, nil)
+	store.HoldForReview(rejected.ID, []string{"large amount"})
+	done, _ := store.ResolveReview(rejected.ID, ReviewRejected, "treasurer", "source of funds unclear")
+	if done.Status != StatusFailed || len(done.Attempts) != 1 || len(store.HeldTransactions()) != 0 {
+		t.Errorf("rejected transaction = %s with %d attempts, want failed and off the queue", done.Status, len(done.Attempts))
+	}
+}
@@ -0,0 +1,160 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// compensationTimeout bounds reversing the hops of one failed attempt
+const compensationTimeout = 30 * time.Second
+
+// compensationRetries is how many times a compensating action is tried
+const compensationRetries = 3
+
+// StepStatus is the state of one hop in a settlement saga
+type StepStatus string
+
+const (
+	StepCompleted          StepStatus = "completed"           // Hop settled; reversed if a later hop fails
+	StepFailed             StepStatus = "failed"              // Hop that failed the attempt
+	StepCompensated        StepStatus = "compensated"         // Hop reversed
+	StepCompensationFailed StepStatus = "compensation_failed" // Reversal failed; needs manual resolution
+)
+
+// CompensationStatus is the outcome of reversing a failed attempt
+type CompensationStatus string
+
+const (
+	CompensationCompleted CompensationStatus = "completed" // Every settled hop was reversed
+	CompensationFailed    CompensationStatus = "failed"    // A hop could not be reversed; funds are held at its target
+)
+
+// SagaStep is one hop of an attempt with its compensating action
+type SagaStep struct {
+	Hop           int        `json:"hop"`
+	FromCountry   string     `json:"from_country"`
+	ToCountry     string     `json:"to_country"`
+	Amount        float64    `json:"amount"` // Amount delivered to ToCountry, returned by the compensation
+	Status        StepStatus `json:"status"`
+	Action        string     `json:"action,omitempty"` // Compensating action, for settled hops
+	Attempts      int        `json:"attempts,omitempty"`
+	Error         string     `json:"error,omitempty"`
+	CompletedAt   time.Time  `json:"completed_at"`
+	CompensatedAt *time.Time `json:"compensated_at,omitempty"`
+}
+
+// Compensation is the trail of reversing the settled hops of a failed
+// attempt, last hop first
+type Compensation struct {
+	Attempt     int                `json:"attempt"` // RouteAttempt the compensation belongs to
+	Reason      string             `json:"reason"`
+	FailedAt    string             `json:"failed_at"`
+	Status      CompensationStatus `json:"status"`
+	Steps       []SagaStep         `json:"steps"`
+	StartedAt   time.Time          `json:"started_at"`
+	CompletedAt time.Time          `json:"completed_at"`
+}
+
+// Compensator reverses a settled hop, returning step.Amount from
+// step.ToCountry to step.FromCountry
+type Compensator func(ctx context.Context, txn *Transaction, step SagaStep) error
+
+// SetCompensator sets the action that reverses settled hops. Without one,
+// reversals are simulated and always succeed, like the hops themselves.
+func (s *TransactionStore) SetCompensator(c Compensator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.compensator = c
+}
+
+// compensate reverses the settled hops of a failing attempt, last hop
+// first, and records the trail on the transaction. It stops at the first
+// hop that cannot be reversed, since the funds are then held at its target.
+// Runs even if ctx is cancelled, as the hops must be unwound regardless.
+func (s *TransactionStore) compensate(ctx context.Context, txnID, failedAt, reason string) {
+	s.mu.Lock()
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		s.mu.Unlock()
+		return
+	}
+	hops := append([]HopResult(nil), txn.HopResults...)
+	attempt := len(txn.Attempts) + 1
+	compensator := s.compensator
+	s.mu.Unlock()
+
+	comp := Compensation{
+		Attempt:   attempt,
+		Reason:    reason,
+		FailedAt:  failedAt,
+		Status:    CompensationCompleted,
+		Steps:     make([]SagaStep, len(hops)),
+		StartedAt: time.Now(),
+	}
+	settled := 0
+	for i, hop := range hops {
+		step := SagaStep{
+			Hop:         i,
+			FromCountry: hop.FromCountry,
+			ToCountry:   hop.ToCountry,
+			Amount:      hop.AmountOut,
+			Status:      StepFailed,
+			Error:       hop.Error,
+			CompletedAt: hop.Timestamp,
+		}
+		if hop.Success {
+			step.Status = StepCompleted
+			step.Action = fmt.Sprintf("return %.2f from %s to %s", hop.AmountOut, hop.ToCountry, hop.FromCountry)
+			settled++
+		}
+		comp.Steps[i] = step
+	}
+	if settled == 0 {
+		return
+	}
+
+	cctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+	snapshot, _ := s.Snapshot(txnID)
+
+	for i := len(comp.Steps) - 1; i >= 0; i-- {
+		step := &comp.Steps[i]
+		if step.Status != StepCompleted {
+			continue
+		}
+
+		var err error
+		for step.Attempts < compensationRetries {
+			step.Attempts++
+			if compensator == nil {
+				err = nil
+				break
+			}
+			if err = compensator(cctx, snapshot, *step); err == nil || cctx.Err() != nil {
+				break
+			}
+		}
+		if err != nil {
+			step.Status = StepCompensationFailed
+			step.Error = err.Error()
+			comp.Status = CompensationFailed
+			slog.ErrorContext(ctx, "hop compensation failed", "transaction_id", txnID, "hop", step.Hop,
+				"from", step.FromCountry, "to", step.ToCountry, "amount", step.Amount, "error", err)
+			break
+		}
+		now := time.Now()
+		step.Status = StepCompensated
+		step.CompensatedAt = &now
+	}
+	comp.CompletedAt = time.Now()
+
+	s.mu.Lock()
+	txn.Compensations = append(txn.Compensations, comp)
+	s.mu.Unlock()
+
+	if comp.Status == CompensationCompleted {
+		slog.InfoContext(ctx, "settled hops compensated", "transaction_id", txnID, "hops", settled, "failed_at", failedAt)
+	}
+}
@@ -0,0 +1,113 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// cancelAfterHops cancels processing once the given number of hops settled
+type cancelAfterHops struct {
+	hops   int
+	cancel context.CancelFunc
+}
+
+func (p *cancelAfterHops) PublishSettlementEvent(ctx context.Context, event *natsClient.SettlementEvent) error {
+	if event.EventType == natsClient.SettlementHopComplete {
+		if p.hops--; p.hops == 0 {
+			p.cancel()
+		}
+	}
+	return nil
+}
+
+// failMidRoute processes a four-country payment that fails after two hops
+func failMidRoute(t *testing.T, store *TransactionStore) *Transaction {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store.SetEventPublisher(&cancelAfterHops{hops: 2, cancel: cancel})
+
+	txn, err := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR", "DEU", "FRA"}, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction: %v", err)
+	}
+	if err := store.ProcessTransaction(ctx, txn.ID, nil, 0); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancellation at the third hop, got %v", err)
+	}
+	snap, _ := store.Snapshot(txn.ID)
+	return snap
+}
+
+// TestFailedHopCompensatesSettledHops verifies settled hops are reversed last
+// first when a later hop fails, and the trail shows in the trace
+func TestFailedHopCompensatesSettledHops(t *testing.T) {
+	store := NewTransactionStore()
+	var reversed []string
+	store.SetCompensator(func(ctx context.Context, txn *Transaction, step SagaStep) error {
+		reversed = append(reversed, step.ToCountry+"->"+step.FromCountry)
+		return nil
+	})
+
+	txn := failMidRoute(t, store)
+	if txn.Status != StatusFailed || len(txn.Compensations) != 1 {
+		t.Fatalf("expected a failed payment with one compensation, got %s with %d", txn.Status, len(txn.Compensations))
+	}
+	comp := txn.Compensations[0]
+	if comp.Status != CompensationCompleted || comp.Attempt != 1 || comp.FailedAt != "DEU" || len(comp.Steps) != 2 {
+		t.Fatalf("unexpected compensation: %+v", comp)
+	}
+	for _, step := range comp.Steps {
+		if step.Status != StepCompensated || step.CompensatedAt == nil || step.Amount <= 0 {
+			t.Errorf("step %d not compensated: %+v", step.Hop, step)
+		}
+	}
+	if len(reversed) != 2 || reversed[0] != "DEU->GBR" || reversed[1] != "GBR->USA" {
+		t.Errorf("reversed %v, want DEU->GBR then GBR->USA", reversed)
+	}
+
+	trace, _ := store.GetTrace(txn.ID, nil)
+	compensated := 0
+	for _, ev := range trace.Timeline {
+		if ev.Event == "hop_compensated" {
+			compensated++
+		}
+	}
+	if len(trace.Compensations) != 1 || compensated != 2 {
+		t.Errorf("expected the trace to show 2 compensated hops, got %d", compensated)
+	}
+}
+
+// TestCompensationStopsAtIrreversibleHop verifies a hop that cannot be
+// reversed fails the compensation and leaves earlier hops untouched
+func TestCompensationStopsAtIrreversibleHop(t *testing.T) {
+	store := NewTransactionStore()
+	calls := 0
+	store.SetCompensator(func(ctx context.Context, txn *Transaction, step SagaStep) error {
+		calls++
+		return errors.New("counterparty unreachable")
+	})
+
+	comp := failMidRoute(t, store).Compensations[0]
+	if comp.Status != CompensationFailed || calls != compensationRetries {
+		t.Fatalf("expected failure after %d tries, got %s after %d", compensationRetries, comp.Status, calls)
+	}
+	if comp.Steps[1].Status != StepCompensationFailed || comp.Steps[0].Status != StepCompleted {
+		t.Errorf("unexpected steps: %+v", comp.Steps)
+	}
+}
+
+// TestFirstHopFailureNeedsNoCompensation verifies nothing is recorded when no
+// hop had settled
+func TestFirstHopFailureNeedsNoCompensation(t *testing.T) {
+	store := NewTransactionStore()
+	txn, _ := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR"}, nil)
+	store.ProcessTransaction(context.Background(), txn.ID, nil, 1)
+
+	snap, _ := store.Snapshot(txn.ID)
+	if snap.Status != StatusFailed || len(snap.Compensations) != 0 {
+		t.Errorf("expected failed payment without compensation, got %s with %d", snap.Status, len(snap.Compensations))
+	}
+}
@@ -0,0 +1,131 @@
+// Package scheduler runs recurring and future-dated payments. Schedules are
+// kept in a Store; a background Scheduler executes due schedules, routing each
+// payment over the best path available at execution time.
+package scheduler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Frequency is how often a schedule runs
+type Frequency string
+
+const (
+	FrequencyOnce    Frequency = "once"
+	FrequencyDaily   Frequency = "daily"
+	FrequencyWeekly  Frequency = "weekly"
+	FrequencyMonthly Frequency = "monthly"
+)
+
+// Status is the lifecycle state of a schedule
+type Status string
+
+const (
+	StatusActive    Status = "active"    // Waiting for its next run
+	StatusCompleted Status = "completed" // One-off executed or end date reached
+	StatusFailed    Status = "failed"    // One-off that could not be executed
+	StatusCancelled Status = "cancelled" // Cancelled by the user
+)
+
+var (
+	// ErrNotFound is returned for unknown schedule IDs
+	ErrNotFound = errors.New("schedule not found")
+	// ErrNotActive is returned when cancelling a schedule that already ended
+	ErrNotActive = errors.New("schedule is not active")
+)
+
+// Schedule is a recurring or future-dated transfer between two countries
+type Schedule struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	Source         string     `json:"source"` // Source country code
+	Target         string     `json:"target"` // Target country code
+	Amount         float64    `json:"amount"`
+	Currency       string     `json:"currency"`
+	TargetCurrency string     `json:"target_currency"`
+	Frequency      Frequency  `json:"frequency"`
+	StartAt        time.Time  `json:"start_at"`
+	EndAt          *time.Time `json:"end_at,omitempty"` // Recurring schedules stop after this time
+	Status         Status     `json:"status"`
+
+	// Execution state
+	NextRunAt         time.Time  `json:"next_run_at"`
+	Occurrence        int        `json:"occurrence"` // Index of NextRunAt counted from StartAt
+	RunCount          int        `json:"run_count"`
+	LastRunAt         *time.Time `json:"last_run_at,omitempty"`
+	LastTransactionID string     `json:"last_transaction_id,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks a new schedule's fields
+func (s *Schedule) Validate(now time.Time) error {
+	switch {
+	case s.Source == "" || s.Target == "":
+		return fmt.Errorf("source and target are required")
+	case s.Source == s.Target:
+		return fmt.Errorf("source and target must differ")
+	case s.Amount <= 0:
+		return fmt.Errorf("amount must be positive")
+	case s.StartAt.IsZero():
+		return fmt.Errorf("start_at is required")
+	case s.StartAt.Before(now.Add(-time.Minute)):
+		return fmt.Errorf("start_at must not be in the past")
+	case s.EndAt != nil && s.EndAt.Before(s.StartAt):
+		return fmt.Errorf("end_at must be after start_at")
+	}
+	switch s.Frequency {
+	case FrequencyOnce, FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		return nil
+	}
+	return fmt.Errorf("frequency must be once, daily, weekly or monthly")
+}
+
+// occurrence returns the n-th run time counted from start (n = 0 is start).
+// Monthly runs keep start's day of month, clamped to shorter months.
+func (f Frequency) occurrence(start time.Time, n int) time.Time {
+	switch f {
+	case FrequencyDaily:
+		return start.AddDate(0, 0, n)
+	case FrequencyWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case FrequencyMonthly:
+		y, m, d := start.Date()
+		first := time.Date(y, m+time.Month(n), 1, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+		if last := first.AddDate(0, 1, -1).Day(); d > last {
+			d = last
+		}
+		return first.AddDate(0, 0, d-1)
+	}
+	return start
+}
+
+// advance moves NextRunAt to the first occurrence after now, skipping runs
+// missed while the scheduler was down. Returns false when the schedule has no
+// further runs.
+func (s *Schedule) advance(now time.Time) bool {
+	if s.Frequency == FrequencyOnce {
+		return false
+	}
+	for {
+		s.Occurrence++
+		s.NextRunAt = s.Frequency.occurrence(s.StartAt, s.Occurrence)
+		if s.NextRunAt.After(now) {
+			break
+		}
+	}
+	return s.EndAt == nil || !s.NextRunAt.After(*s.EndAt)
+}
+
+// generateScheduleID generates a unique schedule ID
+func generateScheduleID() string {
+	bytes := make([]byte, 16)
+	rand.Read(bytes)
+	return "sched_" + hex.EncodeToString(bytes)
+}
@@ -0,0 +1,244 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// Event is a schedule execution event reported to the notifier
+type Event string
+
+const (
+	EventExecuted Event = "schedule.executed" // Payment created and processed (it may still have failed), or held for review
+	EventFailed   Event = "schedule.failed"   // No payment could be created (e.g. no route, or denied by screening)
+)
+
+// RouteFunc returns the best route between two countries at execution time
+type RouteFunc func(ctx context.Context, source, target string) ([]string, error)
+
+// CreateFunc creates the pending transaction for one occurrence. A payment
+// flagged by compliance screening is returned held for review.
+type CreateFunc func(ctx context.Context, userID string, amount float64, currency, targetCurrency string, route []string) (*payments.Transaction, error)
+
+// Notifier is told about every schedule execution. txn is nil for EventFailed.
+type Notifier func(event Event, s *Schedule, txn *payments.Transaction)
+
+// Config holds scheduler settings
+type Config struct {
+	Interval      time.Duration // How often due schedules are checked
+	FailureChance float64       // Simulated mesh failure chance per payment
+}
+
+// DefaultConfig returns default scheduler settings
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:      time.Minute,
+		FailureChance: 0.05,
+	}
+}
+
+// Scheduler creates schedules and executes them when due
+type Scheduler struct {
+	store  Store
+	txns   payments.TransactionStorer
+	route  RouteFunc
+	config *Config
+	now    func() time.Time
+
+	mu       sync.Mutex // serializes runs so a schedule is never executed twice
+	fxRates  func() map[string]float64
+	notifier Notifier
+	create   CreateFunc
+}
+
+// NewScheduler creates a scheduler executing payments through txns
+func NewScheduler(store Store, txns payments.TransactionStorer, route RouteFunc, cfg *Config) *Scheduler {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Scheduler{
+		store:   store,
+		txns:    txns,
+		route:   route,
+		config:  cfg,
+		now:     time.Now,
+		fxRates: func() map[string]float64 { return nil },
+	}
+}
+
+// SetFXRates sets the source of FX rates applied to executed payments
+func (s *Scheduler) SetFXRates(rates func() map[string]float64) {
+	s.fxRates = rates
+}
+
+// SetCreator sets how payments are created, so they are held to the same
+// spending limits and compliance screening as payments made through the
+// API. Without one they are created directly in the transaction store.
+func (s *Scheduler) SetCreator(create CreateFunc) {
+	s.create = create
+}
+
+// SetNotifier sets the callback for execution events
+func (s *Scheduler) SetNotifier(n Notifier) {
+	s.notifier = n
+}
+
+// Create validates and stores a new active schedule
+func (s *Scheduler) Create(ctx context.Context, sched *Schedule) error {
+	now := s.now()
+	if err := sched.Validate(now); err != nil {
+		return err
+	}
+
+	sched.ID = generateScheduleID()
+	sched.Status = StatusActive
+	sched.NextRunAt = sched.StartAt
+	sched.Occurrence = 0
+	sched.CreatedAt = now
+	sched.UpdatedAt = now
+	if err := s.store.Save(ctx, sched); err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return nil
+}
+
+// Get returns a user's schedule
+func (s *Scheduler) Get(ctx context.Context, userID, id string) (*Schedule, error) {
+	sched, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if sched.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return sched, nil
+}
+
+// List returns a user's schedules
+func (s *Scheduler) List(ctx context.Context, userID string) ([]*Schedule, error) {
+	return s.store.ListByUser(ctx, userID)
+}
+
+// Cancel stops a user's active schedule
+func (s *Scheduler) Cancel(ctx context.Context, userID, id string) (*Schedule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sched, err := s.Get(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sched.Status != StatusActive {
+		return nil, ErrNotActive
+	}
+	sched.Status = StatusCancelled
+	sched.UpdatedAt = s.now()
+	if err := s.store.Save(ctx, sched); err != nil {
+		return nil, fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return sched, nil
+}
+
+// Start executes due schedules every interval until ctx is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.RunDue(ctx)
+		}
+	}
+}
+
+// RunDue executes every schedule that is due and returns how many ran
+func (s *Scheduler) RunDue(ctx context.Context) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	due, err := s.store.Due(ctx, s.now())
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to load due schedules", "error", err)
+		return 0
+	}
+	for _, sched := range due {
+		s.execute(ctx, sched)
+	}
+	return len(due)
+}
+
+// execute runs one occurrence of a schedule and advances it
+func (s *Scheduler) execute(ctx context.Context, sched *Schedule) {
+	now := s.now()
+	sched.LastRunAt = &now
+	sched.UpdatedAt = now
+
+	txn, err := s.pay(ctx, sched)
+	if err != nil {
+		sched.LastError = err.Error()
+		slog.WarnContext(ctx, "scheduled payment failed", "schedule_id", sched.ID, "error", err)
+	} else {
+		sched.LastError = ""
+		sched.LastTransactionID = txn.ID
+		sched.RunCount++
+		slog.InfoContext(ctx, "scheduled payment executed", "schedule_id", sched.ID, "transaction_id", txn.ID, "status", txn.Status)
+	}
+
+	if !sched.advance(now) {
+		sched.Status = StatusCompleted
+		if err != nil && sched.Frequency == FrequencyOnce {
+			sched.Status = StatusFailed
+		}
+	}
+	if saveErr := s.store.Save(ctx, sched); saveErr != nil {
+		slog.ErrorContext(ctx, "failed to save schedule", "schedule_id", sched.ID, "error", saveErr)
+	}
+
+	if s.notifier != nil {
+		if err != nil {
+			s.notifier(EventFailed, sched, nil)
+		} else {
+			s.notifier(EventExecuted, sched, txn)
+		}
+	}
+}
+
+// pay routes, creates and processes the payment for one occurrence
+func (s *Scheduler) pay(ctx context.Context, sched *Schedule) (*payments.Transaction, error) {
+	route, err := s.route(ctx, sched.Source, sched.Target)
+	if err != nil {
+		return nil, fmt.Errorf("no route: %w", err)
+	}
+
+	var txn *payments.Transaction
+	if s.create != nil {
+		txn, err = s.create(ctx, sched.UserID, sched.Amount, sched.Currency, sched.TargetCurrency, route)
+	} else {
+		txn, err = s.txns.CreateTransaction(sched.UserID, sched.Amount, sched.Currency, sched.TargetCurrency, route, nil)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
+	}
+	if txn.Status == payments.StatusPendingReview {
+		slog.InfoContext(ctx, "scheduled payment held for compliance review", "schedule_id", sched.ID, "transaction_id", txn.ID)
+		return txn, nil
+	}
+
+	payCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	// A failed payment is still an execution; its outcome is on the transaction
+	s.txns.ProcessTransaction(payCtx, txn.ID, s.fxRates(), s.config.FailureChance)
+
+	if processed, err := s.txns.GetTransaction(txn.ID); err == nil {
+		txn = processed
+	}
+	return txn, nil
+}
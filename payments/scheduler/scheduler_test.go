@@ -0,0 +1,109 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func TestMonthlyOccurrenceClampsDay(t *testing.T) {
+	start := time.Date(2026, time.January, 31, 9, 0, 0, 0, time.UTC)
+	want := []string{"2026-01-31", "2026-02-28", "2026-03-31", "2026-04-30"}
+	for n, w := range want {
+		if got := FrequencyMonthly.occurrence(start, n).Format("2006-01-02"); got != w {
+			t.Errorf("occurrence %d: got %s, want %s", n, got, w)
+		}
+	}
+}
+
+func newTestScheduler(now *time.Time, route RouteFunc) (*Scheduler, *payments.TransactionStore) {
+	txns := payments.NewTransactionStore()
+	s := NewScheduler(NewMemoryStore(), txns, route, &Config{Interval: time.Minute})
+	s.now = func() time.Time { return *now }
+	return s, txns
+}
+
+func TestRecurringScheduleRunsAndSkipsMissedRuns(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	s, txns := newTestScheduler(&now, func(ctx context.Context, source, target string) ([]string, error) {
+		return []string{source, "GBR", target}, nil
+	})
+
+	var events []Event
+	s.SetNotifier(func(event Event, sched *Schedule, txn *payments.Transaction) {
+		events = append(events, event)
+	})
+
+	sched := &Schedule{UserID: "user1", Source: "USA", Target: "DEU", Amount: 100, Frequency: FrequencyDaily, StartAt: now.Add(time.Hour)}
+	if err := s.Create(ctx, sched); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if n := s.RunDue(ctx); n != 0 {
+		t.Fatalf("expected nothing due before start, ran %d", n)
+	}
+
+	// Three days late: one payment, then resume at the next future occurrence
+	now = now.Add(72 * time.Hour)
+	if n := s.RunDue(ctx); n != 1 {
+		t.Fatalf("expected 1 due schedule, ran %d", n)
+	}
+	got, _ := s.Get(ctx, "user1", sched.ID)
+	if got.RunCount != 1 || got.Status != StatusActive || !got.NextRunAt.After(now) || got.NextRunAt.Sub(now) > 24*time.Hour {
+		t.Fatalf("unexpected schedule after run: %+v", got)
+	}
+	txn, err := txns.GetTransaction(got.LastTransactionID)
+	if err != nil || len(txn.Route) != 3 || txn.UserID != "user1" {
+		t.Fatalf("expected routed transaction for user1, got %+v (%v)", txn, err)
+	}
+	if len(events) != 1 || events[0] != EventExecuted {
+		t.Errorf("expected one executed event, got %v", events)
+	}
+
+	if _, err := s.Get(ctx, "user2", sched.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected other users not to see the schedule, got %v", err)
+	}
+	if _, err := s.Cancel(ctx, "user1", sched.ID); err != nil {
+		t.Fatalf("Cancel: %v", err)
+	}
+	now = now.Add(48 * time.Hour)
+	if n := s.RunDue(ctx); n != 0 {
+		t.Errorf("expected cancelled schedule not to run, ran %d", n)
+	}
+}
+
+func TestOneOffWithoutRouteFails(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	s, _ := newTestScheduler(&now, func(ctx context.Context, source, target string) ([]string, error) {
+		return nil, errors.New("no path found")
+	})
+
+	sched := &Schedule{UserID: "user1", Source: "USA", Target: "DEU", Amount: 100, Frequency: FrequencyOnce, StartAt: now}
+	if err := s.Create(ctx, sched); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	s.RunDue(ctx)
+
+	got, _ := s.Get(ctx, "user1", sched.ID)
+	if got.Status != StatusFailed || got.LastError == "" || got.RunCount != 0 {
+		t.Fatalf("expected failed one-off, got %+v", got)
+	}
+}
+
+func TestValidateRejectsBadSchedules(t *testing.T) {
+	now := time.Now()
+	for name, sched := range map[string]Schedule{
+		"same country": {Source: "USA", Target: "USA", Amount: 1, Frequency: FrequencyOnce, StartAt: now},
+		"no amount":    {Source: "USA", Target: "GBR", Frequency: FrequencyOnce, StartAt: now},
+		"past start":   {Source: "USA", Target: "GBR", Amount: 1, Frequency: FrequencyOnce, StartAt: now.Add(-time.Hour)},
+		"bad freq":     {Source: "USA", Target: "GBR", Amount: 1, Frequency: "hourly", StartAt: now},
+	} {
+		if err := sched.Validate(now); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store persists schedules. Implemented by MemoryStore and the Postgres-backed
+// store in storage/postgres.
+type Store interface {
+	Save(ctx context.Context, s *Schedule) error
+	Get(ctx context.Context, id string) (*Schedule, error)
+	ListByUser(ctx context.Context, userID string) ([]*Schedule, error)
+	// Due returns active schedules whose next run is at or before now
+	Due(ctx context.Context, now time.Time) ([]*Schedule, error)
+}
+
+// MemoryStore is an in-memory Store
+type MemoryStore struct {
+	mu        sync.RWMutex
+	schedules map[string]*Schedule
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{schedules: make(map[string]*Schedule)}
+}
+
+// Save inserts or replaces a schedule
+func (m *MemoryStore) Save(ctx context.Context, s *Schedule) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *s
+	m.schedules[s.ID] = &cp
+	return nil
+}
+
+// Get returns a copy of a schedule
+func (m *MemoryStore) Get(ctx context.Context, id string) (*Schedule, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	s, ok := m.schedules[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+// ListByUser returns a user's schedules, newest first
+func (m *MemoryStore) ListByUser(ctx context.Context, userID string) ([]*Schedule, error) {
+	return m.filter(func(s *Schedule) bool { return s.UserID == userID }, func(a, b *Schedule) bool {
+		return a.CreatedAt.After(b.CreatedAt)
+	}), nil
+}
+
+// Due returns active schedules whose next run is at or before now, oldest first
+func (m *MemoryStore) Due(ctx context.Context, now time.Time) ([]*Schedule, error) {
+	return m.filter(func(s *Schedule) bool {
+		return s.Status == StatusActive && !s.NextRunAt.After(now)
+	}, func(a, b *Schedule) bool {
+		return a.NextRunAt.Before(b.NextRunAt)
+	}), nil
+}
+
+func (m *MemoryStore) filter(keep func(*Schedule) bool, less func(a, b *Schedule) bool) []*Schedule {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]*Schedule, 0)
+	for _, s := range m.schedules {
+		if keep(s) {
+			cp := *s
+			result = append(result, &cp)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return less(result[i], result[j]) })
+	return result
+}
+
+// Compile-time interface check
+var _ Store = (*MemoryStore)(nil)
@@ -0,0 +1,185 @@
+package payments
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+// Latency distribution kinds
+const (
+	LatencyUniform = "uniform" // Evenly between MinMs and MaxMs
+	LatencyNormal  = "normal"  // Around MeanMs with StdDevMs, clamped to [MinMs, MaxMs]
+)
+
+// defaultHopError is the failure message of a simulated hop
+const defaultHopError = "node timeout"
+
+// LatencyDistribution is the simulated latency of a hop
+type LatencyDistribution struct {
+	Kind     string  `json:"kind"` // LatencyUniform (default) or LatencyNormal
+	MinMs    int64   `json:"min_ms"`
+	MaxMs    int64   `json:"max_ms"`
+	MeanMs   float64 `json:"mean_ms,omitempty"`
+	StdDevMs float64 `json:"stddev_ms,omitempty"`
+}
+
+// CountryProfile overrides the simulation of hops into one country
+type CountryProfile struct {
+	FailureRate *float64             `json:"failure_rate,omitempty"` // Replaces the caller's failure chance (0-1)
+	Latency     *LatencyDistribution `json:"latency,omitempty"`
+	Error       string               `json:"error,omitempty"` // Failure message (default "node timeout")
+}
+
+// FailureProfile describes how simulated hops behave, for reproducible
+// demo and test scenarios
+type FailureProfile struct {
+	Name      string                    `json:"name"`
+	Latency   LatencyDistribution       `json:"latency"`   // Hop latency unless the destination overrides it
+	Countries map[string]CountryProfile `json:"countries"` // By destination country code
+}
+
+// DefaultFailureProfile returns the stock simulation: 50-200ms hops that
+// fail at the caller's failure chance
+func DefaultFailureProfile() *FailureProfile {
+	return &FailureProfile{
+		Name:    "default",
+		Latency: LatencyDistribution{Kind: LatencyUniform, MinMs: 50, MaxMs: 200},
+	}
+}
+
+// LoadFailureProfile reads a JSON failure profile. An omitted default
+// latency keeps DefaultFailureProfile's.
+func LoadFailureProfile(path string) (*FailureProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	profile := DefaultFailureProfile()
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(profile); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := profile.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return profile, nil
+}
+
+// Validate checks the profile's rates and distributions
+func (p *FailureProfile) Validate() error {
+	if err := p.Latency.validate(); err != nil {
+		return fmt.Errorf("latency: %w", err)
+	}
+	for code, c := range p.Countries {
+		if c.FailureRate != nil && (*c.FailureRate < 0 || *c.FailureRate > 1) {
+			return fmt.Errorf("countries.%s: failure_rate must be between 0 and 1", code)
+		}
+		if c.Latency != nil {
+			if err := c.Latency.validate(); err != nil {
+				return fmt.Errorf("countries.%s.latency: %w", code, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *LatencyDistribution) validate() error {
+	switch {
+	case d.Kind != "" && d.Kind != LatencyUniform && d.Kind != LatencyNormal:
+		return fmt.Errorf("kind must be %q or %q", LatencyUniform, LatencyNormal)
+	case d.MinMs < 0 || d.MaxMs < d.MinMs:
+		return fmt.Errorf("min_ms must be between 0 and max_ms")
+	case d.StdDevMs < 0:
+		return fmt.Errorf("stddev_ms must not be negative")
+	}
+	return nil
+}
+
+// sample draws a latency from the distribution
+func (d *LatencyDistribution) sample(rng *rand.Rand) time.Duration {
+	ms := float64(d.MinMs)
+	if d.Kind == LatencyNormal {
+		ms = d.MeanMs + rng.NormFloat64()*d.StdDevMs
+	} else if d.MaxMs > d.MinMs {
+		ms += float64(rng.Int63n(d.MaxMs - d.MinMs))
+	}
+	ms = min(max(ms, float64(d.MinMs)), float64(d.MaxMs))
+	return time.Duration(ms * float64(time.Millisecond))
+}
+
+// HopOutcome is the simulated result of one hop
+type HopOutcome struct {
+	Latency time.Duration
+	Failed  bool
+	Error   string // Set when Failed
+}
+
+// Simulator draws hop outcomes from a seeded random source and a failure
+// profile. Processing the same payments in the same order with the same
+// seed and profile reproduces the same outcomes.
+type Simulator struct {
+	mu      sync.Mutex
+	rng     *rand.Rand
+	seed    int64
+	profile *FailureProfile
+}
+
+// NewSimulator creates a simulator; a nil profile uses DefaultFailureProfile
+func NewSimulator(seed int64, profile *FailureProfile) *Simulator {
+	if profile == nil {
+		profile = DefaultFailureProfile()
+	}
+	return &Simulator{rng: rand.New(rand.NewSource(seed)), seed: seed, profile: profile}
+}
+
+// Seed returns the seed the simulator was created with, to replay a run
+func (s *Simulator) Seed() int64 {
+	return s.seed
+}
+
+// Profile returns the simulator's failure profile
+func (s *Simulator) Profile() *FailureProfile {
+	return s.profile
+}
+
+// Hop simulates a hop into toCountry. failureChance is the caller's chance
+// of a hop failing; the destination's profile rate replaces it, unless the
+// caller allows no failures (0).
+func (s *Simulator) Hop(toCountry string, failureChance float64) HopOutcome {
+	latency := &s.profile.Latency
+	outcome := HopOutcome{Error: defaultHopError}
+	if c, ok := s.profile.Countries[toCountry]; ok {
+		if c.Latency != nil {
+			latency = c.Latency
+		}
+		if c.FailureRate != nil && failureChance > 0 {
+			failureChance = *c.FailureRate
+		}
+		if c.Error != "" {
+			outcome.Error = c.Error
+		}
+	}
+
+	s.mu.Lock()
+	outcome.Latency = latency.sample(s.rng)
+	outcome.Failed = s.rng.Float64() < failureChance // Drawn every hop, so the sequence doesn't depend on the chance
+	s.mu.Unlock()
+
+	if !outcome.Failed {
+		outcome.Error = ""
+	}
+	return outcome
+}
+
+// SetSimulator sets the source of simulated hop latencies and failures
+func (s *TransactionStore) SetSimulator(sim *Simulator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.simulator = sim
+}
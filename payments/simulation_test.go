@@ -0,0 +1,105 @@
+package payments
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// instantProfile has no hop latency, so scenarios run fast
+func instantProfile() *FailureProfile {
+	return &FailureProfile{Name: "instant", Countries: map[string]CountryProfile{}}
+}
+
+func TestSameSeedReplaysScenario(t *testing.T) {
+	run := func(seed int64) []TransactionStatus {
+		store := NewTransactionStore()
+		store.SetSimulator(NewSimulator(seed, instantProfile()))
+		var statuses []TransactionStatus
+		for i := 0; i < 20; i++ {
+			txn, _ := store.CreateTransaction("user-1", 100, "USD", "USD", []string{"USA", "GBR", "DEU"}, nil)
+			store.ProcessTransaction(context.Background(), txn.ID, nil, 0.3)
+			snap, _ := store.Snapshot(txn.ID)
+			statuses = append(statuses, snap.Status)
+		}
+		return statuses
+	}
+
+	first, replay := run(42), run(42)
+	failed := 0
+	for i := range first {
+		if first[i] != replay[i] {
+			t.Fatalf("payment %d: %s, replayed as %s", i, first[i], replay[i])
+		}
+		if first[i] == StatusFailed {
+			failed++
+		}
+	}
+	if failed == 0 || failed == len(first) {
+		t.Errorf("expected a mix of outcomes at a 30%% failure chance, got %d of %d failed", failed, len(first))
+	}
+}
+
+func TestProfileOverridesCountryFailureRate(t *testing.T) {
+	always, never := 1.0, 0.0
+	profile := instantProfile()
+	profile.Countries["GBR"] = CountryProfile{FailureRate: &always, Error: "correspondent bank offline"}
+	profile.Countries["DEU"] = CountryProfile{FailureRate: &never}
+	sim := NewSimulator(1, profile)
+
+	for i := 0; i < 50; i++ {
+		if out := sim.Hop("GBR", 0.05); !out.Failed || out.Error != "correspondent bank offline" {
+			t.Fatalf("expected every hop into GBR to fail, got %+v", out)
+		}
+		if out := sim.Hop("DEU", 0.9); out.Failed {
+			t.Fatal("expected hops into DEU never to fail")
+		}
+		if out := sim.Hop("GBR", 0); out.Failed {
+			t.Fatal("a caller allowing no failures must not get one")
+		}
+	}
+}
+
+func TestLatencyDistributions(t *testing.T) {
+	profile := instantProfile()
+	profile.Latency = LatencyDistribution{Kind: LatencyUniform, MinMs: 50, MaxMs: 200}
+	profile.Countries["JPN"] = CountryProfile{Latency: &LatencyDistribution{Kind: LatencyNormal, MinMs: 100, MaxMs: 300, MeanMs: 150, StdDevMs: 500}}
+	sim := NewSimulator(7, profile)
+
+	for i := 0; i < 200; i++ {
+		if ms := sim.Hop("USA", 0).Latency.Milliseconds(); ms < 50 || ms >= 200 {
+			t.Fatalf("uniform latency %dms outside [50, 200)", ms)
+		}
+		if ms := sim.Hop("JPN", 0).Latency.Milliseconds(); ms < 100 || ms > 300 {
+			t.Fatalf("normal latency %dms not clamped to [100, 300]", ms)
+		}
+	}
+}
+
+func TestLoadFailureProfile(t *testing.T) {
+	profile, err := LoadFailureProfile(filepath.Join("..", "failure-profile.example.json"))
+	if err != nil {
+		t.Fatalf("example profile: %v", err)
+	}
+	if rate := profile.Countries["GBR"].FailureRate; rate == nil || *rate != 0.5 {
+		t.Errorf("unexpected GBR profile: %+v", profile.Countries["GBR"])
+	}
+
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"rate over 1":    `{"countries": {"GBR": {"failure_rate": 1.5}}}`,
+		"min over max":   `{"latency": {"min_ms": 300, "max_ms": 100}}`,
+		"unknown kind":   `{"latency": {"kind": "poisson", "max_ms": 100}}`,
+		"unknown field":  `{"countries": {"GBR": {"failure": 0.5}}}`,
+		"negative stdev": `{"latency": {"kind": "normal", "max_ms": 100, "stddev_ms": -1}}`,
+	} {
+		path := filepath.Join(dir, "profile.json")
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFailureProfile(path); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
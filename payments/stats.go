@@ -0,0 +1,285 @@
+package payments
+
+import "slices"
+
+// PlatformStats are totals over a set of transactions. The store keeps them
+// up to date as transactions are created and change state, so reading them
+// does not scan the transactions.
+type PlatformStats struct {
+	TotalVolume  float64
+	TotalFees    float64
+	TotalProfit  float64 // Admin profit of successful payments plus base fees of failed ones (fees retained by refunded ones)
+	Transactions int
+	ByStatus     map[TransactionStatus]int
+	DailyVolume  map[string]float64 // YYYY-MM-DD of creation -> amount
+	DailyFees    map[string]float64
+	Corridors    map[Corridor]CorridorVolume // Successful payments by hop
+
+	Refunds        int
+	RefundedAmount float64 // Returned to payers
+	RetainedFees   float64 // Kept from refunded payments under the refund policy
+}
+
+// Corridor is a hop between two adjacent countries of a route
+type Corridor struct {
+	Source string
+	Target string
+}
+
+// CorridorVolume is the successful payments routed over a corridor
+type CorridorVolume struct {
+	Count  int
+	Volume float64
+}
+
+func newPlatformStats() *PlatformStats {
+	return &PlatformStats{
+		ByStatus:    make(map[TransactionStatus]int),
+		DailyVolume: make(map[string]float64),
+		DailyFees:   make(map[string]float64),
+		Corridors:   make(map[Corridor]CorridorVolume),
+	}
+}
+
+// Count returns the number of transactions in any of statuses
+func (p *PlatformStats) Count(statuses ...TransactionStatus) int {
+	n := 0
+	for _, status := range statuses {
+		n += p.ByStatus[status]
+	}
+	return n
+}
+
+// Summary returns the stats in the shape of GetAdminStats
+func (p *PlatformStats) Summary() map[string]interface{} {
+	return map[string]interface{}{
+		"total_profit":       p.TotalProfit,
+		"total_volume":       p.TotalVolume,
+		"success_count":      p.Count(StatusSuccess),
+		"failed_count":       p.Count(StatusFailed),
+		"pending_count":      p.Count(StatusPending, StatusProcessing, StatusNetting, StatusInterrupted),
+		"total_transactions": p.Transactions,
+		"refund_count":       p.Refunds,
+		"refunded_amount":    p.RefundedAmount,
+		"retained_fees":      p.RetainedFees,
+	}
+}
+
+// clone returns a copy safe to read while the original is updated
+func (p *PlatformStats) clone() *PlatformStats {
+	cp := *p
+	cp.ByStatus = make(map[TransactionStatus]int, len(p.ByStatus))
+	for k, v := range p.ByStatus {
+		cp.ByStatus[k] = v
+	}
+	cp.DailyVolume = make(map[string]float64, len(p.DailyVolume))
+	for k, v := range p.DailyVolume {
+		cp.DailyVolume[k] = v
+	}
+	cp.DailyFees = make(map[string]float64, len(p.DailyFees))
+	for k, v := range p.DailyFees {
+		cp.DailyFees[k] = v
+	}
+	cp.Corridors = make(map[Corridor]CorridorVolume, len(p.Corridors))
+	for k, v := range p.Corridors {
+		cp.Corridors[k] = v
+	}
+	return &cp
+}
+
+// statsEntry is what one transaction contributes to PlatformStats
+type statsEntry struct {
+	counted bool
+	org     string
+	day     string
+	volume  float64
+	fees    float64
+	status  TransactionStatus
+	profit  float64
+	route   []string // Set while successful, for corridors
+	refund  *Refund
+}
+
+func statsEntryOf(txn *Transaction) statsEntry {
+	e := statsEntry{
+		counted: true,
+		org:     txn.OrgID,
+		day:     txn.CreatedAt.Format("2006-01-02"),
+		volume:  txn.Amount,
+		fees:    txn.TotalFees,
+		status:  txn.Status,
+	}
+	switch txn.Status {
+	case StatusSuccess:
+		// A settled payment refunded after a dispute keeps only what the refund retained
+		e.profit = txn.AdminProfit
+		if txn.Refund != nil {
+			e.profit = txn.Refund.Retained
+		}
+		e.route = append([]string(nil), txn.Route...)
+	case StatusFailed:
+		// Still collect partial fees on failed transactions, unless refunded
+		e.profit = txn.BaseFee
+		if txn.Refund != nil {
+			e.profit = txn.Refund.Retained
+		}
+	}
+	if txn.Refund != nil {
+		refund := *txn.Refund
+		e.refund = &refund
+	}
+	return e
+}
+
+// replace swaps the contribution of old for that of e. Only the parts that
+// changed are touched, so repeated state changes do not accumulate rounding.
+func (p *PlatformStats) replace(old, e statsEntry) {
+	if old.counted != e.counted {
+		if e.counted {
+			p.Transactions++
+		} else {
+			p.Transactions--
+		}
+	}
+	if old.day != e.day || old.volume != e.volume || old.fees != e.fees {
+		p.addAmounts(old, -1)
+		p.addAmounts(e, 1)
+	}
+	if old.status != e.status || old.counted != e.counted {
+		if old.counted {
+			if p.ByStatus[old.status]--; p.ByStatus[old.status] == 0 {
+				delete(p.ByStatus, old.status)
+			}
+		}
+		if e.counted {
+			p.ByStatus[e.status]++
+		}
+	}
+	if old.profit != e.profit {
+		p.TotalProfit += e.profit - old.profit
+	}
+	if !slices.Equal(old.route, e.route) {
+		p.addCorridors(old, -1)
+		p.addCorridors(e, 1)
+	}
+	if old.refund != nil && (e.refund == nil || *old.refund != *e.refund) {
+		p.addRefund(old.refund, -1)
+	}
+	if e.refund != nil && (old.refund == nil || *old.refund != *e.refund) {
+		p.addRefund(e.refund, 1)
+	}
+}
+
+func (p *PlatformStats) addRefund(r *Refund, sign int) {
+	p.Refunds += sign
+	p.RefundedAmount += float64(sign) * r.Amount
+	p.RetainedFees += float64(sign) * r.Retained
+}
+
+func (p *PlatformStats) addAmounts(e statsEntry, sign float64) {
+	if !e.counted {
+		return
+	}
+	p.TotalVolume += sign * e.volume
+	p.TotalFees += sign * e.fees
+	p.DailyVolume[e.day] += sign * e.volume
+	p.DailyFees[e.day] += sign * e.fees
+}
+
+func (p *PlatformStats) addCorridors(e statsEntry, sign int) {
+	for i := 0; i+1 < len(e.route); i++ {
+		key := Corridor{Source: e.route[i], Target: e.route[i+1]}
+		c := p.Corridors[key]
+		c.Count += sign
+		c.Volume += float64(sign) * e.volume
+		if c.Count == 0 {
+			delete(p.Corridors, key)
+			continue
+		}
+		p.Corridors[key] = c
+	}
+}
+
+// statsIndex keeps PlatformStats for the platform and each organization,
+// remembering each transaction's contribution so a state change replaces it
+type statsIndex struct {
+	all     *PlatformStats
+	orgs    map[string]*PlatformStats
+	entries map[string]statsEntry
+}
+
+func newStatsIndex() *statsIndex {
+	return &statsIndex{
+		all:     newPlatformStats(),
+		orgs:    make(map[string]*PlatformStats),
+		entries: make(map[string]statsEntry),
+	}
+}
+
+// update recounts txn after it was added or changed
+func (x *statsIndex) update(txn *Transaction) {
+	old, e := x.entries[txn.ID], statsEntryOf(txn)
+	x.entries[txn.ID] = e
+	x.all.replace(old, e)
+
+	if old.org == e.org {
+		if e.org != "" {
+			x.org(e.org).replace(old, e)
+		}
+		return
+	}
+	if old.org != "" {
+		x.org(old.org).replace(old, statsEntry{})
+	}
+	if e.org != "" {
+		x.org(e.org).replace(statsEntry{}, e)
+	}
+}
+
+func (x *statsIndex) org(orgID string) *PlatformStats {
+	p, ok := x.orgs[orgID]
+	if !ok {
+		p = newPlatformStats()
+		x.orgs[orgID] = p
+	}
+	return p
+}
+
+// countLocked recounts txn in the platform stats after it was added or
+// changed state. Callers must hold s.mu.
+func (s *TransactionStore) countLocked(txn *Transaction) {
+	s.stats.update(txn)
+}
+
+// PlatformStats returns the totals over all transactions, or over one
+// organization's when orgID is set
+func (s *TransactionStore) PlatformStats(orgID string) *PlatformStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if orgID == "" {
+		return s.stats.all.clone()
+	}
+	if p, ok := s.stats.orgs[orgID]; ok {
+		return p.clone()
+	}
+	return newPlatformStats()
+}
+
+// RecentTransactions returns up to limit of the most recently created
+// transactions, newest first, optionally of one organization
+func (s *TransactionStore) RecentTransactions(orgID string, limit int) []*Transaction {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := s.order
+	if orgID != "" {
+		ids = s.orgTxns[orgID]
+	}
+	result := make([]*Transaction, 0, min(limit, len(ids)))
+	for i := len(ids) - 1; i >= 0 && len(result) < limit; i-- {
+		if txn, ok := s.transactions[ids[i]]; ok {
+			result = append(result, txn)
+		}
+	}
+	return result
+}
@@ -0,0 +1,130 @@
+package payments
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// scanStats recomputes what the store's running totals should hold
+func scanStats(txns []*Transaction) *PlatformStats {
+	want := newPlatformStats()
+	for _, txn := range txns {
+		want.Transactions++
+		want.TotalVolume += txn.Amount
+		want.TotalFees += txn.TotalFees
+		want.ByStatus[txn.Status]++
+		switch txn.Status {
+		case StatusSuccess:
+			if txn.Refund != nil {
+				want.TotalProfit += txn.Refund.Retained
+			} else {
+				want.TotalProfit += txn.AdminProfit
+			}
+		case StatusFailed:
+			if txn.Refund != nil {
+				want.TotalProfit += txn.Refund.Retained
+			} else {
+				want.TotalProfit += txn.BaseFee
+			}
+		}
+		if txn.Refund != nil {
+			want.Refunds++
+			want.RefundedAmount += txn.Refund.Amount
+			want.RetainedFees += txn.Refund.Retained
+		}
+	}
+	return want
+}
+
+func assertStats(t *testing.T, got, want *PlatformStats) {
+	t.Helper()
+	if got.Transactions != want.Transactions || !approxEqual(got.TotalVolume, want.TotalVolume) ||
+		!approxEqual(got.TotalFees, want.TotalFees) || !approxEqual(got.TotalProfit, want.TotalProfit) {
+		t.Errorf("totals = %d txns, volume %v, fees %v, profit %v; want %d, %v, %v, %v",
+			got.Transactions, got.TotalVolume, got.TotalFees, got.TotalProfit,
+			want.Transactions, want.TotalVolume, want.TotalFees, want.TotalProfit)
+	}
+	for status, n := range want.ByStatus {
+		if got.ByStatus[status] != n {
+			t.Errorf("%s count = %d, want %d", status, got.ByStatus[status], n)
+		}
+	}
+}
+
+func TestPlatformStatsFollowStateChanges(t *testing.T) {
+	ctx := context.Background()
+	store := NewTransactionStore()
+	store.SetSimulator(NewSimulator(1, instantProfile()))
+	route := []string{"USA", "GBR", "DEU"}
+
+	succeeded, _ := store.CreateTransaction("user-1", 100, "USD", "USD", route, nil)
+	failed, _ := store.CreateTransaction("user-1", 200, "USD", "USD", route, nil)
+	held, _ := store.CreateTransaction("user-2", 300, "USD", "USD", route, nil)
+	refunded, _ := store.CreateTransaction("user-2", 400, "USD", "USD", route, nil)
+	retried, _ := store.CreateTransaction("user-2", 500, "USD", "USD", route, nil)
+	assertStats(t, store.PlatformStats(""), scanStats(store.GetAllTransactions()))
+
+	store.ProcessTransaction(ctx, succeeded.ID, nil, 0)
+	store.ProcessTransaction(ctx, failed.ID, nil, 1)
+	store.HoldForReview(held.ID, []string{"large amount"})
+	store.ProcessTransaction(ctx, refunded.ID, nil, 1)
+	store.MarkAsRefunded(refunded.ID, DefaultRefundPolicy().Refund(refunded, RefundUserCancelled))
+	store.ProcessTransaction(ctx, retried.ID, nil, 1)
+	store.ResetTransactionForRetry(retried.ID)
+
+	got := store.PlatformStats("")
+	assertStats(t, got, scanStats(store.GetAllTransactions()))
+	if got.Count(StatusSuccess) != 1 || got.Count(StatusFailed) != 2 || got.Count(StatusPendingReview) != 1 || got.Count(StatusPending) != 1 {
+		t.Errorf("statuses = %v", got.ByStatus)
+	}
+	if got.Refunds != 1 || !approxEqual(got.RetainedFees, refunded.BaseFee) || !approxEqual(got.RefundedAmount+got.RetainedFees, 400) {
+		t.Errorf("refunds = %d returning %v and keeping %v, want 1 keeping the base fee", got.Refunds, got.RefundedAmount, got.RetainedFees)
+	}
+	day := succeeded.CreatedAt.Format("2006-01-02")
+	if got.DailyVolume[day] != 1500 {
+		t.Errorf("daily volume = %v, want 1500 on %s", got.DailyVolume, day)
+	}
+	// Only the successful payment counts toward corridors
+	if c := got.Corridors[Corridor{Source: "GBR", Target: "DEU"}]; c.Count != 1 || c.Volume != 100 || len(got.Corridors) != 2 {
+		t.Errorf("corridors = %v, want USA->GBR and GBR->DEU with the successful payment", got.Corridors)
+	}
+
+	// Returned stats are copies
+	got.ByStatus[StatusSuccess] = 99
+	if store.PlatformStats("").Count(StatusSuccess) != 1 {
+		t.Error("modifying returned stats changed the store's totals")
+	}
+}
+
+func TestPlatformStatsByOrganization(t *testing.T) {
+	store := NewTransactionStore()
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.Restore(&Transaction{ID: "a", OrgID: "org-1", Amount: 10, Status: StatusSuccess, CreatedAt: base})
+	store.Restore(&Transaction{ID: "b", OrgID: "org-1", Amount: 20, Status: StatusFailed, CreatedAt: base.Add(time.Hour)})
+	store.Restore(&Transaction{ID: "c", Amount: 30, Status: StatusPending, CreatedAt: base.Add(2 * time.Hour)})
+
+	if org := store.PlatformStats("org-1"); org.Transactions != 2 || org.TotalVolume != 30 {
+		t.Errorf("org-1 = %d txns, volume %v; want 2, 30", org.Transactions, org.TotalVolume)
+	}
+	if none := store.PlatformStats("org-unknown"); none.Transactions != 0 {
+		t.Errorf("unknown org = %d txns, want 0", none.Transactions)
+	}
+	if recent := store.RecentTransactions("org-1", 10); len(recent) != 2 || recent[0].ID != "b" {
+		t.Errorf("recent org-1 = %v, want b then a", recent)
+	}
+
+	// A restored copy moving to another organization moves its totals
+	store.Restore(&Transaction{ID: "b", OrgID: "org-2", Amount: 20, Status: StatusSuccess, CreatedAt: base.Add(time.Hour)})
+	if org := store.PlatformStats("org-1"); org.Transactions != 1 || org.TotalVolume != 10 || org.Count(StatusFailed) != 0 {
+		t.Errorf("org-1 after move = %d txns, volume %v, statuses %v", org.Transactions, org.TotalVolume, org.ByStatus)
+	}
+	if all := store.PlatformStats(""); all.Transactions != 3 || all.TotalVolume != 60 || all.Count(StatusSuccess) != 2 {
+		t.Errorf("platform = %d txns, volume %v, statuses %v", all.Transactions, all.TotalVolume, all.ByStatus)
+	}
+
+	recent := store.RecentTransactions("", 2)
+	if len(recent) != 2 || recent[0].ID != "c" || recent[1].ID != "b" {
+		t.Errorf("recent = %v, want c then b", recent)
+	}
+}
@@ -0,0 +1,80 @@
+package payments
+
+import (
+	"context"
+	"time"
+
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
+)
+
+// TransactionStorer is the storage contract used by the payment API.
+// Implemented by the in-memory TransactionStore and the Postgres-backed
+// store in storage/postgres.
+type TransactionStorer interface {
+	CreateTransaction(userID string, amount float64, currency, targetCurrency string, route []string, haltedNodes map[string]bool) (*Transaction, error)
+	CreateQuotedTransaction(userID string, q *Quote, haltedNodes map[string]bool) (*Transaction, error)
+	PreviewTransaction(userID string, amount float64, currency, targetCurrency string, route []string, haltedNodes map[string]bool) (*Transaction, error)
+	CreateBatch(userID string, items []BatchItem, haltedNodes map[string]bool) (*Batch, error)
+	ProcessTransaction(ctx context.Context, txnID string, fxRates map[string]float64, failureChance float64) error
+	ProcessTransactionWithRoute(ctx context.Context, txnID string, route []string, fxRates map[string]float64, failureChance float64) error
+	ResetTransactionForRetry(txnID string)
+	MarkAsRefunded(txnID string, refund Refund)
+	MarkPaymentFailed(txnID string, reason string)
+	SetCandidateRoutes(txnID string, routes [][]string)
+	HoldForReview(txnID string, reasons []string) error
+	ResolveReview(txnID string, decision ReviewDecision, reviewer, note string) (*Transaction, error)
+	SetPaymentIntent(txnID, paymentIntentID string)
+	SetPaymentProvider(txnID, provider string)
+	SetPayoutAccount(txnID, account string) error
+	RecordPayout(txnID string, payout Payout) error
+	OpenDispute(txnID, userID string, reason DisputeReason, details string) (*Transaction, error)
+	ResolveDispute(txnID string, decision DisputeStatus, reviewer, note string, refund *Refund) (*Transaction, error)
+	QueueForNetting(txnID string) error
+	CreateNetSettlement(set *NettingSet, amount float64, currency string, route []string) (*Transaction, error)
+	CompleteNettingSet(set *NettingSet) []string
+
+	GetTransaction(txnID string) (*Transaction, error)
+	GetBatch(batchID string) (*Batch, error)
+	GetUserTransactions(userID string) []*Transaction
+	QueryUserTransactions(userID string, q HistoryQuery) (*HistoryPage, error)
+	QueryOrgTransactions(orgID string, q HistoryQuery) (*HistoryPage, error)
+	UserVolume(userID string, since time.Time) float64
+	OrgVolume(orgID string, since time.Time) float64
+	InVolumeCurrency(amount float64, currency string) float64
+	GetAllTransactions() []*Transaction
+	HeldTransactions() []*Transaction
+	Disputes(status DisputeStatus) []*Transaction
+	UnpaidPayouts() []*Transaction
+	GetAdminStats() map[string]interface{}
+	PlatformStats(orgID string) *PlatformStats
+	RecentTransactions(orgID string, limit int) []*Transaction
+	CountryOutcomes(code string, window time.Duration) HopOutcomes
+	GetTrace(txnID string, weight EdgeWeightFunc) (*TransactionTrace, error)
+
+	Idempotent(ctx context.Context, userID, key, requestHash string, create func() (*IdempotencyRecord, error)) (*IdempotencyRecord, bool, error)
+	SetIdempotencyBackend(backend IdempotencyBackend)
+
+	SetFeeSchedule(schedule *FeeSchedule)
+	SetOrgResolver(r OrgResolver)
+	SetCurrencyRates(rates func() map[string]float64)
+	SetCredibilityCallback(cb func(countryCode string, success bool))
+	SetStatusCallback(cb func(event StatusEvent, txn *Transaction))
+	SetCircuitBreaker(cb CircuitBreaker)
+	SetCircuitCallback(cb func(nodeID string, prev, state redisClient.State))
+	SetEventPublisher(p EventPublisher)
+	SetSimulator(sim *Simulator)
+	OpenCircuit(ctx context.Context, route []string) string
+	LockTransaction(ctx context.Context, txnID string) (context.Context, func(), error)
+	SetLocker(l Locker)
+
+	Drain(ctx context.Context) ([]string, error)
+	Draining() bool
+	InterruptedTransactions() []*Transaction
+	ResumeInterrupted(ctx context.Context, txnID string) error
+	StuckTransactions(before time.Time) []*Transaction
+	RequeueStuck(ctx context.Context, txnID string) error
+	EscalateStuck(ctx context.Context, txnID string, reasons []string) error
+}
+
+// Compile-time interface check
+var _ TransactionStorer = (*TransactionStore)(nil)
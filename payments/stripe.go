@@ -7,20 +7,29 @@ import (
 
 	"github.com/stripe/stripe-go/v76"
 	"github.com/stripe/stripe-go/v76/paymentintent"
+	"github.com/stripe/stripe-go/v76/refund"
 )
 
 // StripeClient handles Stripe API interactions
 type StripeClient struct {
 	secretKey     string
 	publishableKey string
+	webhookSecret string
 	isTestMode    bool
+	returnURL     string // Where Stripe sends the payer after 3-D Secure
 }
 
-// NewStripeClient creates a new Stripe client
+// NewStripeClient creates a new Stripe client from STRIPE_* environment variables
 func NewStripeClient() *StripeClient {
-	secretKey := os.Getenv("STRIPE_SECRET_KEY")
-	publishableKey := os.Getenv("STRIPE_PUBLISHABLE_KEY")
-	
+	return NewStripeClientWithKeys(
+		os.Getenv("STRIPE_SECRET_KEY"),
+		os.Getenv("STRIPE_PUBLISHABLE_KEY"),
+		os.Getenv("STRIPE_WEBHOOK_SECRET"),
+	)
+}
+
+// NewStripeClientWithKeys creates a new Stripe client; an empty secretKey selects mock mode
+func NewStripeClientWithKeys(secretKey, publishableKey, webhookSecret string) *StripeClient {
 	// Check if using test keys
 	isTestMode := false
 	if secretKey == "" {
@@ -38,6 +47,7 @@ func NewStripeClient() *StripeClient {
 	return &StripeClient{
 		secretKey:      secretKey,
 		publishableKey: publishableKey,
+		webhookSecret:  webhookSecret,
 		isTestMode:     isTestMode,
 	}
 }
@@ -63,6 +73,7 @@ type PaymentIntentRequest struct {
 	Currency     string            `json:"currency"`      // USD, EUR, etc.
 	Description  string            `json:"description"`
 	Metadata     map[string]string `json:"metadata"`
+	IdempotencyKey string          `json:"-"` // Forwarded to Stripe to dedupe retries
 }
 
 // PaymentIntentResponse represents the response from creating a payment intent
@@ -72,6 +83,7 @@ type PaymentIntentResponse struct {
 	Amount       int64  `json:"amount"`
 	Currency     string `json:"currency"`
 	Status       string `json:"status"`
+	NextAction   *NextAction `json:"next_action,omitempty"` // Set when Status is requires_action
 }
 
 // CreatePaymentIntent creates a Stripe PaymentIntent (Endpoint A)
@@ -104,6 +116,10 @@ func (c *StripeClient) CreatePaymentIntent(req *PaymentIntentRequest) (*PaymentI
 		params.Metadata = req.Metadata
 	}
 	
+	if req.IdempotencyKey != "" {
+		params.SetIdempotencyKey(req.IdempotencyKey)
+	}
+	
 	pi, err := paymentintent.New(params)
 	if err != nil {
 		return nil, fmt.Errorf("stripe error: %w", err)
@@ -118,7 +134,9 @@ func (c *StripeClient) CreatePaymentIntent(req *PaymentIntentRequest) (*PaymentI
 	}, nil
 }
 
-// ConfirmPaymentIntent confirms a payment intent (Endpoint B)
+// ConfirmPaymentIntent confirms a payment intent (Endpoint B). An intent
+// waiting for confirmation is confirmed; one that then needs the payer to
+// authenticate is returned with its next action.
 func (c *StripeClient) ConfirmPaymentIntent(paymentIntentID string) (*PaymentIntentResponse, error) {
 	// If in mock mode, return success
 	if c.IsMockMode() {
@@ -129,17 +147,38 @@ func (c *StripeClient) ConfirmPaymentIntent(paymentIntentID string) (*PaymentInt
 	}
 	
 	// Get real payment intent status
+	pi, err := paymentintent.Get(paymentIntentID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("stripe error: %w", err)
+	}
+	if pi.Status == stripe.PaymentIntentStatusRequiresConfirmation {
+		if pi, err = c.confirmIntent(paymentIntentID); err != nil {
+			return nil, err
+		}
+	}
+	
+	return intentResponse(pi), nil
+}
+
+// GetPaymentIntent retrieves the current state of a payment intent
+func (c *StripeClient) GetPaymentIntent(paymentIntentID string) (*PaymentIntentResponse, error) {
+	if c.IsMockMode() {
+		return &PaymentIntentResponse{
+			ID:     paymentIntentID,
+			Status: "succeeded",
+		}, nil
+	}
+	
 	pi, err := paymentintent.Get(paymentIntentID, nil)
 	if err != nil {
 		return nil, fmt.Errorf("stripe error: %w", err)
 	}
 	
 	return &PaymentIntentResponse{
-		ID:           pi.ID,
-		ClientSecret: pi.ClientSecret,
-		Amount:       pi.Amount,
-		Currency:     string(pi.Currency),
-		Status:       string(pi.Status),
+		ID:       pi.ID,
+		Amount:   pi.Amount,
+		Currency: string(pi.Currency),
+		Status:   string(pi.Status),
 	}, nil
 }
 
@@ -177,13 +216,25 @@ func (c *StripeClient) RefundPayment(paymentIntentID string, amount int64, reaso
 		}, nil
 	}
 	
-	// In real mode, use Stripe Refund API
-	// Note: This would use "github.com/stripe/stripe-go/v76/refund"
+	// Amount may be less than the payment when the refund policy keeps fees
+	params := &stripe.RefundParams{
+		PaymentIntent: stripe.String(paymentIntentID),
+		Amount:        stripe.Int64(amount),
+	}
+	params.AddMetadata("reason", reason)
+	re, err := refund.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("stripe refund error: %w", err)
+	}
+	// A refund Stripe could not make must not be recorded as issued
+	if re.Status == stripe.RefundStatusFailed || re.Status == stripe.RefundStatusCanceled {
+		return nil, fmt.Errorf("stripe refund %s %s: %s", re.ID, re.Status, re.FailureReason)
+	}
 	return &RefundResponse{
-		ID:              fmt.Sprintf("re_%s", paymentIntentID),
+		ID:              re.ID,
 		PaymentIntentID: paymentIntentID,
-		Amount:          amount,
-		Status:          "succeeded",
+		Amount:          re.Amount,
+		Status:          string(re.Status),
 		Reason:          reason,
 	}, nil
 }
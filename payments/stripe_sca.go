@@ -0,0 +1,68 @@
+package payments
+
+import (
+	"fmt"
+
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/paymentintent"
+)
+
+// PaymentIntent statuses the payment flow acts on
+const (
+	IntentSucceeded             = "succeeded"
+	IntentProcessing            = "processing"              // Funds not yet confirmed; the webhook settles the payment
+	IntentRequiresAction        = "requires_action"         // The payer must authenticate, e.g. with 3-D Secure
+	IntentRequiresConfirmation  = "requires_confirmation"   // Confirmed server-side before it can succeed
+	IntentRequiresPaymentMethod = "requires_payment_method" // No usable payment method, or authentication failed
+)
+
+// NextAction is what the payer must do before a PaymentIntent can succeed
+type NextAction struct {
+	Type        string `json:"type"`                   // redirect_to_url | use_stripe_sdk
+	RedirectURL string `json:"redirect_url,omitempty"` // Page the payer authenticates on, for redirect_to_url
+}
+
+// RequiresAction reports whether the PaymentIntent is waiting for the payer
+// to authenticate
+func (r *PaymentIntentResponse) RequiresAction() bool {
+	return r.Status == IntentRequiresAction || r.Status == IntentRequiresConfirmation
+}
+
+// SetReturnURL sets where Stripe sends the payer after authenticating on a
+// redirect (3-D Secure). Required to confirm intents that need one.
+func (c *StripeClient) SetReturnURL(url string) {
+	c.returnURL = url
+}
+
+// confirmIntent confirms an intent waiting for server-side confirmation, which
+// either succeeds or asks the payer to authenticate
+func (c *StripeClient) confirmIntent(paymentIntentID string) (*stripe.PaymentIntent, error) {
+	params := &stripe.PaymentIntentConfirmParams{}
+	if c.returnURL != "" {
+		params.ReturnURL = stripe.String(c.returnURL)
+	}
+	pi, err := paymentintent.Confirm(paymentIntentID, params)
+	if err != nil {
+		return nil, fmt.Errorf("stripe confirm error: %w", err)
+	}
+	return pi, nil
+}
+
+// intentResponse converts a Stripe PaymentIntent, including the action the
+// payer must take next
+func intentResponse(pi *stripe.PaymentIntent) *PaymentIntentResponse {
+	resp := &PaymentIntentResponse{
+		ID:           pi.ID,
+		ClientSecret: pi.ClientSecret,
+		Amount:       pi.Amount,
+		Currency:     string(pi.Currency),
+		Status:       string(pi.Status),
+	}
+	if pi.NextAction != nil {
+		resp.NextAction = &NextAction{Type: string(pi.NextAction.Type)}
+		if pi.NextAction.RedirectToURL != nil {
+			resp.NextAction.RedirectURL = pi.NextAction.RedirectToURL.URL
+		}
+	}
+	return resp
+}
@@ -0,0 +1,92 @@
+package payments
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/transfer"
+	"github.com/stripe/stripe-go/v76/transferreversal"
+)
+
+// TransferRequest represents a Stripe Connect transfer to a connected account
+type TransferRequest struct {
+	Amount         int64             `json:"amount"` // Minor units of Currency
+	Currency       string            `json:"currency"`
+	Destination    string            `json:"destination"` // Connected account, acct_...
+	Description    string            `json:"description"`
+	TransferGroup  string            `json:"transfer_group,omitempty"` // Ties the transfer to the payment it pays out
+	Metadata       map[string]string `json:"metadata"`
+	IdempotencyKey string            `json:"-"` // Forwarded to Stripe so a retried payout is not paid twice
+}
+
+// TransferResponse represents a created Stripe transfer
+type TransferResponse struct {
+	ID          string `json:"id"`
+	Destination string `json:"destination"`
+	Amount      int64  `json:"amount"`
+	Currency    string `json:"currency"`
+}
+
+// CreateTransfer moves funds from the platform balance to a connected account
+func (c *StripeClient) CreateTransfer(req *TransferRequest) (*TransferResponse, error) {
+	if c.IsMockMode() {
+		return &TransferResponse{
+			ID:          fmt.Sprintf("tr_mock_%s", req.TransferGroup),
+			Destination: req.Destination,
+			Amount:      req.Amount,
+			Currency:    req.Currency,
+		}, nil
+	}
+
+	params := &stripe.TransferParams{
+		Amount:      stripe.Int64(req.Amount),
+		Currency:    stripe.String(strings.ToLower(req.Currency)),
+		Destination: stripe.String(req.Destination),
+	}
+	if req.Description != "" {
+		params.Description = stripe.String(req.Description)
+	}
+	if req.TransferGroup != "" {
+		params.TransferGroup = stripe.String(req.TransferGroup)
+	}
+	if len(req.Metadata) > 0 {
+		params.Metadata = req.Metadata
+	}
+	if req.IdempotencyKey != "" {
+		params.SetIdempotencyKey(req.IdempotencyKey)
+	}
+
+	tr, err := transfer.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("stripe transfer error: %w", err)
+	}
+
+	resp := &TransferResponse{
+		ID:       tr.ID,
+		Amount:   tr.Amount,
+		Currency: string(tr.Currency),
+	}
+	if tr.Destination != nil {
+		resp.Destination = tr.Destination.ID
+	}
+	return resp, nil
+}
+
+// ReverseTransfer returns a transfer's full amount from the connected
+// account to the platform balance, returning the reversal ID
+func (c *StripeClient) ReverseTransfer(transferID, idempotencyKey string) (string, error) {
+	if c.IsMockMode() {
+		return fmt.Sprintf("trr_mock_%s", transferID), nil
+	}
+
+	params := &stripe.TransferReversalParams{ID: stripe.String(transferID)}
+	if idempotencyKey != "" {
+		params.SetIdempotencyKey(idempotencyKey)
+	}
+	rev, err := transferreversal.New(params)
+	if err != nil {
+		return "", fmt.Errorf("stripe transfer reversal error: %w", err)
+	}
+	return rev.ID, nil
+}
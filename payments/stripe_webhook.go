@@ -0,0 +1,80 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/stripe/stripe-go/v76"
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+// Stripe webhook event types handled by PLM
+const (
+	StripeEventPaymentSucceeded = string(stripe.EventTypePaymentIntentSucceeded)
+	StripeEventPaymentFailed    = string(stripe.EventTypePaymentIntentPaymentFailed)
+	StripeEventChargeRefunded   = string(stripe.EventTypeChargeRefunded)
+)
+
+// ErrWebhookNotConfigured is returned when STRIPE_WEBHOOK_SECRET is not set
+var ErrWebhookNotConfigured = errors.New("stripe webhook secret not configured")
+
+// StripeEvent is a verified Stripe webhook event reduced to the fields PLM acts on
+type StripeEvent struct {
+	ID              string
+	Type            string
+	TransactionID   string // From the PaymentIntent/Charge metadata set in CreatePaymentIntent
+	PaymentIntentID string
+	RefundID        string
+	RefundAmount    int64 // Cents refunded so far, for charge.refunded
+	FailureMessage  string
+}
+
+// ParseWebhookEvent verifies the Stripe-Signature header and decodes the event
+func (c *StripeClient) ParseWebhookEvent(payload []byte, signature string) (*StripeEvent, error) {
+	if c.webhookSecret == "" {
+		return nil, ErrWebhookNotConfigured
+	}
+
+	event, err := webhook.ConstructEventWithOptions(payload, signature, c.webhookSecret, webhook.ConstructEventOptions{
+		IgnoreAPIVersionMismatch: true, // Account API version may differ from the library's
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid stripe signature: %w", err)
+	}
+
+	result := &StripeEvent{ID: event.ID, Type: string(event.Type)}
+	if event.Data == nil {
+		return result, nil
+	}
+
+	switch result.Type {
+	case StripeEventPaymentSucceeded, StripeEventPaymentFailed:
+		var pi stripe.PaymentIntent
+		if err := json.Unmarshal(event.Data.Raw, &pi); err != nil {
+			return nil, fmt.Errorf("failed to decode payment intent: %w", err)
+		}
+		result.PaymentIntentID = pi.ID
+		result.TransactionID = pi.Metadata["transaction_id"]
+		if pi.LastPaymentError != nil {
+			result.FailureMessage = pi.LastPaymentError.Msg
+		}
+
+	case StripeEventChargeRefunded:
+		var ch stripe.Charge
+		if err := json.Unmarshal(event.Data.Raw, &ch); err != nil {
+			return nil, fmt.Errorf("failed to decode charge: %w", err)
+		}
+		result.TransactionID = ch.Metadata["transaction_id"]
+		if ch.PaymentIntent != nil {
+			result.PaymentIntentID = ch.PaymentIntent.ID
+		}
+		result.RefundID = "charge:" + ch.ID
+		result.RefundAmount = ch.AmountRefunded
+		if ch.Refunds != nil && len(ch.Refunds.Data) > 0 {
+			result.RefundID = ch.Refunds.Data[0].ID
+		}
+	}
+
+	return result, nil
+}
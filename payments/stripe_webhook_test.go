@@ -0,0 +1,42 @@
+package payments
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stripe/stripe-go/v76/webhook"
+)
+
+func TestParseWebhookEventVerifiesSignature(t *testing.T) {
+	client := &StripeClient{webhookSecret: "whsec_test"}
+	payload := []byte(`{
+		"id": "evt_1",
+		"object": "event",
+		"type": "payment_intent.payment_failed",
+		"data": {"object": {
+			"id": "pi_123",
+			"object": "payment_intent",
+			"metadata": {"transaction_id": "txn_abc"},
+			"last_payment_error": {"message": "Your card was declined."}
+		}}
+	}`)
+
+	signed := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: "whsec_test"})
+	event, err := client.ParseWebhookEvent(payload, signed.Header)
+	if err != nil {
+		t.Fatalf("ParseWebhookEvent failed: %v", err)
+	}
+	if event.Type != StripeEventPaymentFailed || event.TransactionID != "txn_abc" ||
+		event.PaymentIntentID != "pi_123" || event.FailureMessage != "Your card was declined." {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+
+	forged := webhook.GenerateTestSignedPayload(&webhook.UnsignedPayload{Payload: payload, Secret: "whsec_other"})
+	if _, err := client.ParseWebhookEvent(payload, forged.Header); err == nil {
+		t.Fatal("expected forged signature to be rejected")
+	}
+
+	if _, err := (&StripeClient{}).ParseWebhookEvent(payload, signed.Header); !errors.Is(err, ErrWebhookNotConfigured) {
+		t.Fatalf("expected ErrWebhookNotConfigured, got %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package payments
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EdgeWeightFunc returns the routing weight between two countries (false if no edge exists)
+type EdgeWeightFunc func(from, to string) (float64, bool)
+
+// TraceEdge is a single edge of a traced route with its routing weight
+type TraceEdge struct {
+	From   string  `json:"from"`
+	To     string  `json:"to"`
+	Weight float64 `json:"weight"`
+	Known  bool    `json:"known"` // false if the edge is not in the routing graph
+}
+
+// TraceRoute is a route considered for a transaction
+type TraceRoute struct {
+	Route       []string    `json:"route"`
+	Edges       []TraceEdge `json:"edges"`
+	TotalWeight float64     `json:"total_weight"`
+	Attempted   bool        `json:"attempted"`
+	Chosen      bool        `json:"chosen"`
+}
+
+// TraceEvent is a single point on the processing timeline
+type TraceEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Attempt   int       `json:"attempt,omitempty"`
+	Event     string    `json:"event"`
+	Detail    string    `json:"detail,omitempty"`
+}
+
+// TransactionTrace is the full routing decision for a transaction
+type TransactionTrace struct {
+	TransactionID   string            `json:"transaction_id"`
+	Status          TransactionStatus `json:"status"`
+	FailedAt        string            `json:"failed_at,omitempty"`
+	CandidateRoutes []TraceRoute      `json:"candidate_routes"`
+	ChosenRoute     *TraceRoute       `json:"chosen_route"`
+	Attempts        []RouteAttempt    `json:"attempts"`
+	Compensations   []Compensation    `json:"compensations,omitempty"`
+	Timeline        []TraceEvent      `json:"timeline"`
+	GeneratedAt     time.Time         `json:"generated_at"`
+}
+
+// GetTrace builds a routing trace for a transaction
+func (s *TransactionStore) GetTrace(txnID string, weight EdgeWeightFunc) (*TransactionTrace, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		return nil, fmt.Errorf("transaction not found")
+	}
+	return buildTrace(txn, weight), nil
+}
+
+// buildTrace assembles the trace document. Caller must hold at least RLock.
+func buildTrace(txn *Transaction, weight EdgeWeightFunc) *TransactionTrace {
+	trace := &TransactionTrace{
+		TransactionID: txn.ID,
+		Status:        txn.Status,
+		FailedAt:      txn.FailedAt,
+		Attempts:      append([]RouteAttempt(nil), txn.Attempts...),
+		Compensations: append([]Compensation(nil), txn.Compensations...),
+		GeneratedAt:   time.Now(),
+	}
+
+	// Chosen route: the successful attempt, else the last attempt, else the requested route
+	chosen := txn.Route
+	for _, a := range txn.Attempts {
+		chosen = a.Route
+		if a.Status == StatusSuccess {
+			break
+		}
+	}
+
+	attempted := make(map[string]bool)
+	for _, a := range txn.Attempts {
+		attempted[fmt.Sprint(a.Route)] = true
+	}
+
+	// Candidates first, then any attempted route that wasn't a candidate
+	seen := make(map[string]bool)
+	routes := append([][]string(nil), txn.CandidateRoutes...)
+	if len(routes) == 0 {
+		routes = append(routes, txn.Route)
+	}
+	for _, a := range txn.Attempts {
+		routes = append(routes, a.Route)
+	}
+
+	for _, r := range routes {
+		key := fmt.Sprint(r)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		tr := traceRoute(r, weight)
+		tr.Attempted = attempted[key]
+		tr.Chosen = key == fmt.Sprint(chosen)
+		trace.CandidateRoutes = append(trace.CandidateRoutes, tr)
+		if tr.Chosen {
+			chosenCopy := tr
+			trace.ChosenRoute = &chosenCopy
+		}
+	}
+
+	trace.Timeline = buildTimeline(txn)
+	return trace
+}
+
+// traceRoute computes per-edge weights for a route
+func traceRoute(route []string, weight EdgeWeightFunc) TraceRoute {
+	tr := TraceRoute{Route: route, Edges: make([]TraceEdge, 0, len(route))}
+	for i := 0; i < len(route)-1; i++ {
+		edge := TraceEdge{From: route[i], To: route[i+1]}
+		if weight != nil {
+			edge.Weight, edge.Known = weight(route[i], route[i+1])
+		}
+		tr.TotalWeight += edge.Weight
+		tr.Edges = append(tr.Edges, edge)
+	}
+	return tr
+}
+
+// buildTimeline flattens attempts and hop results into a time-ordered event list
+func buildTimeline(txn *Transaction) []TraceEvent {
+	events := []TraceEvent{{Timestamp: txn.CreatedAt, Event: "created"}}
+
+	for _, a := range txn.Attempts {
+		events = append(events, TraceEvent{
+			Timestamp: a.StartedAt,
+			Attempt:   a.Attempt,
+			Event:     "attempt_started",
+			Detail:    fmt.Sprintf("route %v", a.Route),
+		})
+		for _, hop := range a.HopResults {
+			ev := TraceEvent{
+				Timestamp: hop.Timestamp,
+				Attempt:   a.Attempt,
+				Event:     "hop_succeeded",
+				Detail:    fmt.Sprintf("%s → %s (%dms)", hop.FromCountry, hop.ToCountry, hop.Latency),
+			}
+			if !hop.Success {
+				ev.Event = "hop_failed"
+				ev.Detail = fmt.Sprintf("%s → %s: %s", hop.FromCountry, hop.ToCountry, hop.Error)
+			}
+			events = append(events, ev)
+		}
+		end := TraceEvent{Timestamp: a.EndedAt, Attempt: a.Attempt, Event: "attempt_succeeded"}
+		if a.Status != StatusSuccess {
+			end.Event = "attempt_failed"
+			end.Detail = a.Error
+			if a.FailedAt != "" {
+				end.Detail = fmt.Sprintf("failed at %s: %s", a.FailedAt, a.Error)
+			}
+		}
+		events = append(events, end)
+	}
+
+	for _, c := range txn.Compensations {
+		for _, step := range c.Steps {
+			switch step.Status {
+			case StepCompensated:
+				events = append(events, TraceEvent{
+					Timestamp: *step.CompensatedAt,
+					Attempt:   c.Attempt,
+					Event:     "hop_compensated",
+					Detail:    step.Action,
+				})
+			case StepCompensationFailed:
+				events = append(events, TraceEvent{
+					Timestamp: c.CompletedAt,
+					Attempt:   c.Attempt,
+					Event:     "hop_compensation_failed",
+					Detail:    fmt.Sprintf("%s: %s", step.Action, step.Error),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+	return events
+}
@@ -0,0 +1,98 @@
+package payments
+
+import (
+	"context"
+	"testing"
+)
+
+// TestTraceIncludesFailedAndReroutedRoutes verifies a rerouted payment's trace keeps both attempts
+func TestTraceIncludesFailedAndReroutedRoutes(t *testing.T) {
+	store := NewTransactionStore()
+
+	original := []string{"IND", "ARE", "GBR"}
+	alternative := []string{"IND", "SGP", "GBR"}
+
+	txn, err := store.CreateTransaction("user-1", 100, "USD", "GBP", original, nil)
+	if err != nil {
+		t.Fatalf("CreateTransaction failed: %v", err)
+	}
+	store.SetCandidateRoutes(txn.ID, [][]string{original, alternative})
+
+	ctx := context.Background()
+
+	// First attempt always fails, second always succeeds
+	if err := store.ProcessTransactionWithRoute(ctx, txn.ID, original, nil, 1.0); err == nil {
+		t.Fatal("Expected first attempt to fail")
+	}
+	store.ResetTransactionForRetry(txn.ID)
+	if err := store.ProcessTransactionWithRoute(ctx, txn.ID, alternative, nil, 0); err != nil {
+		t.Fatalf("Expected reroute to succeed: %v", err)
+	}
+
+	weights := map[string]float64{
+		"IND>ARE": 0.01, "ARE>GBR": 0.02,
+		"IND>SGP": 0.015, "SGP>GBR": 0.01,
+	}
+	trace, err := store.GetTrace(txn.ID, func(from, to string) (float64, bool) {
+		w, ok := weights[from+">"+to]
+		return w, ok
+	})
+	if err != nil {
+		t.Fatalf("GetTrace failed: %v", err)
+	}
+
+	if len(trace.Attempts) != 2 {
+		t.Fatalf("Expected 2 attempts, got %d", len(trace.Attempts))
+	}
+	failed, succeeded := trace.Attempts[0], trace.Attempts[1]
+	if failed.Status != StatusFailed || !pathsMatch(failed.Route, original) || failed.FailedAt == "" {
+		t.Errorf("First attempt should be the failed original route, got %+v", failed)
+	}
+	if len(failed.HopResults) == 0 || failed.HopResults[len(failed.HopResults)-1].Error == "" {
+		t.Error("Failed attempt should keep its hop results with a failure reason")
+	}
+	if succeeded.Status != StatusSuccess || !pathsMatch(succeeded.Route, alternative) {
+		t.Errorf("Second attempt should be the successful reroute, got %+v", succeeded)
+	}
+
+	if trace.ChosenRoute == nil || !pathsMatch(trace.ChosenRoute.Route, alternative) {
+		t.Fatalf("Chosen route should be the alternative, got %+v", trace.ChosenRoute)
+	}
+	if len(trace.ChosenRoute.Edges) != 2 || trace.ChosenRoute.Edges[0].Weight != 0.015 {
+		t.Errorf("Chosen route should carry per-edge weights, got %+v", trace.ChosenRoute.Edges)
+	}
+
+	if len(trace.CandidateRoutes) != 2 {
+		t.Fatalf("Expected 2 candidate routes, got %d", len(trace.CandidateRoutes))
+	}
+	for _, c := range trace.CandidateRoutes {
+		if !c.Attempted {
+			t.Errorf("Route %v should be marked attempted", c.Route)
+		}
+	}
+
+	var sawFailure, sawSuccess bool
+	for _, ev := range trace.Timeline {
+		switch ev.Event {
+		case "attempt_failed":
+			sawFailure = true
+		case "attempt_succeeded":
+			sawSuccess = true
+		}
+	}
+	if !sawFailure || !sawSuccess {
+		t.Error("Timeline should contain both the failed and successful attempts")
+	}
+}
+
+func pathsMatch(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
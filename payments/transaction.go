@@ -6,8 +6,14 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
+
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
+	redisClient "github.com/plm/predictive-liquidity-mesh/storage/redis"
 )
 
 // TransactionStatus represents the status of a payment
@@ -15,15 +21,28 @@ type TransactionStatus string
 
 const (
 	StatusPending   TransactionStatus = "pending"
+	StatusPendingReview TransactionStatus = "pending_review" // Held by compliance screening until an admin approves it
 	StatusProcessing TransactionStatus = "processing"
+	StatusNetting   TransactionStatus = "netting" // Queued to settle with offsetting payments at the end of the netting window
 	StatusSuccess   TransactionStatus = "success"
 	StatusFailed    TransactionStatus = "failed"
+	StatusInterrupted TransactionStatus = "interrupted" // Processing stopped by a shutdown; resumed on the next startup
+)
+
+// StatusEvent is a transaction lifecycle event reported to SetStatusCallback
+type StatusEvent string
+
+const (
+	EventPaymentSucceeded StatusEvent = "payment.succeeded"
+	EventPaymentFailed    StatusEvent = "payment.failed"
+	EventPaymentRefunded  StatusEvent = "payment.refunded"
 )
 
 // Transaction represents a payment transaction through the mesh
 type Transaction struct {
 	ID            string            `json:"id"`
 	UserID        string            `json:"user_id"`
+	OrgID         string            `json:"org_id,omitempty"` // Organization the user transacted for
 	Amount        float64           `json:"amount"`          // Original amount
 	Currency      string            `json:"currency"`        // Source currency
 	TargetCurrency string           `json:"target_currency"` // Target currency
@@ -31,18 +50,31 @@ type Transaction struct {
 	Status        TransactionStatus `json:"status"`
 	
 	// Fee breakdown
-	BaseFee       float64           `json:"base_fee"`        // 1.5% platform fee
-	HopFees       float64           `json:"hop_fees"`        // 0.02% per hop
-	HaltFines     float64           `json:"halt_fines"`      // 0.1% per halted node
+	BaseFee       float64           `json:"base_fee"`        // Platform fee (1.5% by default)
+	HopFees       float64           `json:"hop_fees"`        // Per hop (0.02% by default), scaled by destination risk when priced
+	HopFeeBreakdown []HopFee        `json:"hop_fee_breakdown,omitempty"` // Per-hop pricing (set when a Pricer is configured)
+	HaltFines     float64           `json:"halt_fines"`      // Per halted node (0.1% by default)
 	TotalFees     float64           `json:"total_fees"`
-	FinalAmount   float64           `json:"final_amount"`    // Amount after fees
+	FinalAmount   float64           `json:"final_amount"`    // Amount after fees, in the target currency once processed
 	AdminProfit   float64           `json:"admin_profit"`    // Total fees collected
+	FeeScheduleVersion int          `json:"fee_schedule_version,omitempty"` // Fee schedule active at creation (0 = unversioned)
+	FeeRates      *FeeConfig        `json:"fee_rates,omitempty"`            // Rates the fees were computed from
+	
+	// Quote the transaction was created from (exchange rates locked until expiry)
+	QuoteID        string             `json:"quote_id,omitempty"`
+	QuotedFXRates  map[string]float64 `json:"quoted_fx_rates,omitempty"`
+	QuoteExpiresAt *time.Time         `json:"quote_expires_at,omitempty"`
 	
 	// Mesh simulation
 	HopResults    []HopResult       `json:"hop_results"`     // Result of each hop
 	HopsCompleted int               `json:"hops_completed"`
 	FailedAt      string            `json:"failed_at,omitempty"` // Country code where failed
 	
+	// Routing decision (for dispute support)
+	CandidateRoutes [][]string      `json:"candidate_routes,omitempty"` // Routes considered, in priority order
+	Attempts      []RouteAttempt    `json:"attempts,omitempty"`         // One entry per processing attempt
+	Compensations []Compensation    `json:"compensations,omitempty"`    // Reversal of settled hops, one per attempt that failed mid-route
+	
 	// Timestamps
 	CreatedAt     time.Time         `json:"created_at"`
 	ProcessedAt   *time.Time        `json:"processed_at,omitempty"`
@@ -51,6 +83,30 @@ type Transaction struct {
 	// Mock payment details
 	CardLast4     string            `json:"card_last4,omitempty"`
 	PaymentMethod string            `json:"payment_method"`
+
+	// Batch the transaction was submitted in (empty for single payments)
+	BatchID       string            `json:"batch_id,omitempty"`
+
+	// Compliance hold (set when screening flagged the transaction for review)
+	Review        *Review           `json:"review,omitempty"`
+
+	// Stripe PaymentIntent that paid for the transaction (empty for mock card payments),
+	// or the provider's equivalent when PaymentProvider is not Stripe
+	PaymentIntentID string          `json:"payment_intent_id,omitempty"`
+	PaymentProvider string          `json:"payment_provider,omitempty"` // Empty for payments collected by Stripe before providers were selectable
+
+	// What a refund returned to the payer and what it kept (nil until refunded)
+	Refund        *Refund           `json:"refund,omitempty"`
+
+	// Payer's dispute of the completed payment
+	Dispute       *Dispute          `json:"dispute,omitempty"`
+
+	// Netting set the payment was settled in (also set on the set's net settlement)
+	NettingSetID  string            `json:"netting_set_id,omitempty"`
+
+	// Stripe Connect account the final amount is paid out to, and the payout once started
+	PayoutAccount string            `json:"payout_account,omitempty"`
+	Payout        *Payout           `json:"payout,omitempty"`
 }
 
 // HopResult represents the result of a single hop in the mesh
@@ -59,19 +115,32 @@ type HopResult struct {
 	ToCountry     string    `json:"to_country"`
 	Success       bool      `json:"success"`
 	Latency       int64     `json:"latency_ms"`      // Simulated latency
-	FXRate        float64   `json:"fx_rate"`         // Exchange rate used
-	AmountIn      float64   `json:"amount_in"`       // Amount entering this hop
-	AmountOut     float64   `json:"amount_out"`      // Amount after hop fee
-	HopFee        float64   `json:"hop_fee"`         // Fee for this hop
+	FXRate        float64   `json:"fx_rate"`         // Exchange rate applied (from → to currency)
+	FXFallback    bool      `json:"fx_fallback,omitempty"` // No rate available, hop settled without conversion
+	AmountIn      float64   `json:"amount_in"`       // Amount entering this hop (from currency)
+	AmountOut     float64   `json:"amount_out"`      // Amount after hop fee, converted to the to currency
+	HopFee        float64   `json:"hop_fee"`         // Fee for this hop (from currency)
 	Timestamp     time.Time `json:"timestamp"`
 	Error         string    `json:"error,omitempty"` // Error message if failed
 }
 
+// RouteAttempt records one processing attempt over a single route
+type RouteAttempt struct {
+	Attempt    int               `json:"attempt"`
+	Route      []string          `json:"route"`
+	Status     TransactionStatus `json:"status"`
+	FailedAt   string            `json:"failed_at,omitempty"`
+	Error      string            `json:"error,omitempty"`
+	HopResults []HopResult       `json:"hop_results"`
+	StartedAt  time.Time         `json:"started_at"`
+	EndedAt    time.Time         `json:"ended_at"`
+}
+
 // FeeConfig holds fee configuration
 type FeeConfig struct {
-	BaseFeePercent    float64 // Default 1.5% (0.015)
-	HopFeePercent     float64 // Default 0.02% (0.0002)
-	HaltFinePercent   float64 // Default 0.1% (0.001)
+	BaseFeePercent    float64 `json:"base_fee_percent"`  // Default 1.5% (0.015)
+	HopFeePercent     float64 `json:"hop_fee_percent"`   // Default 0.02% (0.0002)
+	HaltFinePercent   float64 `json:"halt_fine_percent"` // Default 0.1% (0.001)
 }
 
 // DefaultFeeConfig returns the default fee configuration
@@ -88,11 +157,32 @@ type TransactionStore struct {
 	mu              sync.RWMutex
 	transactions    map[string]*Transaction
 	userTxns        map[string][]string // userID -> transaction IDs
+	orgTxns         map[string][]string // orgID -> transaction IDs
+	batches         map[string][]string // batchID -> transaction IDs
+	order           []string            // Transaction IDs in creation order
+	stats           *statsIndex         // Platform totals, updated on every state change
+	outcomes        *hopOutcomes        // Recent hop outcomes per destination country
 	feeConfig       FeeConfig
-	processingLocks map[string]*sync.Mutex // Per-transaction locks to prevent concurrent processing
+	feeVersion      int                    // Fee schedule version of feeConfig (0 = unversioned)
+	locker          Locker                 // Per-transaction processing locks (in-process unless shared)
+	lockTTL         time.Duration          // Processing lock lifetime without renewal
+	lockWait        time.Duration          // How long to wait for a processing lock
+	idempotency     *idempotencyCache      // Idempotency-Key results
+	breaker         CircuitBreaker         // Optional per-node circuit breaker
+	publisher       EventPublisher         // Optional settlement lifecycle event publisher
+	pricer          *Pricer                // Optional credibility-based hop pricing
+	orgs            OrgResolver            // Optional organization fee overrides and spending limits
+	currencyRates   func() map[string]float64 // Rates per USD by currency, for volume in VolumeCurrency
+	compensator     Compensator            // Optional reversal of settled hops (simulated when nil)
+	simulator       *Simulator             // Simulated hop latencies and failures
+	draining        bool                   // Set by Drain; new processing is refused
+	inflight        sync.WaitGroup         // Processing locks held, waited for by Drain
+	processing      map[string]context.CancelCauseFunc // Held processing locks by transaction ID
 	
 	// Callbacks
 	onCredibilityUpdate func(countryCode string, success bool)
+	onStatusChange      func(event StatusEvent, txn *Transaction)
+	onCircuitChange     func(nodeID string, prev, state redisClient.State)
 }
 
 // NewTransactionStore creates a new transaction store
@@ -100,26 +190,67 @@ func NewTransactionStore() *TransactionStore {
 	return &TransactionStore{
 		transactions:    make(map[string]*Transaction),
 		userTxns:        make(map[string][]string),
+		orgTxns:         make(map[string][]string),
+		batches:         make(map[string][]string),
+		stats:           newStatsIndex(),
+		outcomes:        newHopOutcomes(),
 		feeConfig:       DefaultFeeConfig(),
-		processingLocks: make(map[string]*sync.Mutex),
+		locker:          newMemoryLocker(),
+		lockTTL:         processingLockTTL,
+		lockWait:        processingLockWait,
+		idempotency:     newIdempotencyCache(),
+		processing:      make(map[string]context.CancelCauseFunc),
+		simulator:       NewSimulator(time.Now().UnixNano(), nil),
 	}
 }
 
+// SetFeeConfig sets the fee rates applied to new transactions
+func (s *TransactionStore) SetFeeConfig(fees FeeConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feeConfig = fees
+}
+
+// SetFeeSchedule sets the versioned fee rates applied to new transactions.
+// Existing transactions keep the rates they were created under.
+func (s *TransactionStore) SetFeeSchedule(schedule *FeeSchedule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.feeConfig = schedule.FeeConfig
+	s.feeVersion = schedule.Version
+}
+
+// SetPricer enables per-hop pricing: each hop pays the flat hop rate scaled
+// by the pricer's multiplier for its destination
+func (s *TransactionStore) SetPricer(p *Pricer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pricer = p
+}
+
 // SetCredibilityCallback sets the callback for credibility updates
 func (s *TransactionStore) SetCredibilityCallback(cb func(countryCode string, success bool)) {
 	s.onCredibilityUpdate = cb
 }
 
-// GetProcessingLock returns a per-transaction mutex to prevent concurrent processing
-// This prevents race conditions during anti-fragility retry logic
-func (s *TransactionStore) GetProcessingLock(txnID string) *sync.Mutex {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	if _, exists := s.processingLocks[txnID]; !exists {
-		s.processingLocks[txnID] = &sync.Mutex{}
+// SetStatusCallback sets the callback for transaction lifecycle events
+func (s *TransactionStore) SetStatusCallback(cb func(event StatusEvent, txn *Transaction)) {
+	s.onStatusChange = cb
+}
+
+// notifyStatus reports a lifecycle event with a snapshot of the transaction.
+// Must be called without holding s.mu.
+func (s *TransactionStore) notifyStatus(event StatusEvent, txnID string) {
+	metrics.PaymentsTotal.Inc(strings.TrimPrefix(string(event), "payment."))
+	s.publishEvent(settlementEventTypes[event], txnID)
+	if s.onStatusChange == nil {
+		return
 	}
-	return s.processingLocks[txnID]
+	txn, err := s.Snapshot(txnID)
+	if err != nil {
+		return
+	}
+	s.onStatusChange(event, txn)
 }
 
 // generateTxID generates a unique transaction ID
@@ -132,23 +263,68 @@ func generateTxID() string {
 // CreateTransaction creates a new pending transaction
 func (s *TransactionStore) CreateTransaction(userID string, amount float64, currency, targetCurrency string, route []string, haltedNodes map[string]bool) (*Transaction, error) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	if err := s.checkOrgLimitLocked(userID, toVolumeCurrency(s.currencyRatesLocked(), amount, currency)); err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
+	txn, err := s.newTransaction(userID, amount, currency, targetCurrency, route, haltedNodes)
+	if err != nil {
+		s.mu.Unlock()
+		return nil, err
+	}
 
+	s.addLocked(txn)
+	s.mu.Unlock()
+
+	s.publishEvent(natsClient.SettlementCreated, txn.ID)
+	return txn, nil
+}
+
+// newTransaction builds a pending transaction with its fees without storing it.
+// Callers must hold s.mu (read or write).
+func (s *TransactionStore) newTransaction(userID string, amount float64, currency, targetCurrency string, route []string, haltedNodes map[string]bool) (*Transaction, error) {
 	if len(route) < 2 {
 		return nil, fmt.Errorf("route must have at least 2 countries")
 	}
+	// Routes may be mesh node IDs (gRPC settlement), so only currencies are
+	// checked here; the API handlers check country codes
+	for _, code := range []string{currency, targetCurrency} {
+		if code == "" {
+			continue
+		}
+		if err := refdata.CheckCurrency(code); err != nil {
+			return nil, err
+		}
+	}
 
 	hopCount := len(route) - 1
+	fees := s.feeConfig
+	feeVersion := s.feeVersion
+	orgID := ""
+	if terms := s.orgTermsLocked(userID); terms != nil {
+		orgID = terms.OrgID
+		if terms.Fees != nil {
+			fees, feeVersion = *terms.Fees, 0 // Negotiated rates are not part of the schedule
+		}
+	}
 	
 	// Calculate fees
-	baseFee := amount * s.feeConfig.BaseFeePercent
-	hopFees := amount * s.feeConfig.HopFeePercent * float64(hopCount)
+	baseFee := amount * fees.BaseFeePercent
+	hopFees := amount * fees.HopFeePercent * float64(hopCount)
+	var hopBreakdown []HopFee
+	if s.pricer != nil {
+		hopBreakdown = s.pricer.HopFees(amount, fees.HopFeePercent, route)
+		hopFees = 0
+		for _, hop := range hopBreakdown {
+			hopFees += hop.Fee
+		}
+	}
 	
 	// Count halted nodes in route
 	haltFines := 0.0
 	for _, code := range route {
 		if haltedNodes[code] {
-			haltFines += amount * s.feeConfig.HaltFinePercent
+			haltFines += amount * fees.HaltFinePercent
 		}
 	}
 	
@@ -161,6 +337,7 @@ func (s *TransactionStore) CreateTransaction(userID string, amount float64, curr
 	txn := &Transaction{
 		ID:             generateTxID(),
 		UserID:         userID,
+		OrgID:          orgID,
 		Amount:         amount,
 		Currency:       currency,
 		TargetCurrency: targetCurrency,
@@ -168,24 +345,31 @@ func (s *TransactionStore) CreateTransaction(userID string, amount float64, curr
 		Status:         StatusPending,
 		BaseFee:        baseFee,
 		HopFees:        hopFees,
+		HopFeeBreakdown: hopBreakdown,
 		HaltFines:      haltFines,
 		TotalFees:      totalFees,
 		FinalAmount:    finalAmount,
 		AdminProfit:    totalFees,
+		FeeScheduleVersion: feeVersion,
+		FeeRates:       &fees,
 		HopResults:     make([]HopResult, 0),
 		CreatedAt:      time.Now(),
 		CardLast4:      cardLast4,
 		PaymentMethod:  "mock_card",
 	}
 
-	s.transactions[txn.ID] = txn
-	s.userTxns[userID] = append(s.userTxns[userID], txn.ID)
-
 	return txn, nil
 }
 
-// ProcessTransaction simulates the mesh payment flow
+// ProcessTransaction simulates the mesh payment flow. The transaction's
+// processing lock is held throughout (see LockTransaction).
 func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string, fxRates map[string]float64, failureChance float64) error {
+	ctx, unlock, err := s.LockTransaction(ctx, txnID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	s.mu.Lock()
 	txn, ok := s.transactions[txnID]
 	if !ok {
@@ -193,6 +377,10 @@ func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string,
 		return fmt.Errorf("transaction not found: %s", txnID)
 	}
 	
+	if txn.Status == StatusPendingReview {
+		s.mu.Unlock()
+		return ErrHeldForReview
+	}
 	if txn.Status != StatusPending {
 		s.mu.Unlock()
 		return fmt.Errorf("transaction already processed")
@@ -201,15 +389,29 @@ func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string,
 	txn.Status = StatusProcessing
 	now := time.Now()
 	txn.ProcessedAt = &now
+	s.countLocked(txn)
+	route := txn.Route
+	sim := s.simulator
 	s.mu.Unlock()
 
+	// Open circuits block the route before any hop is attempted
+	if blocked := s.checkRouteCircuits(ctx, route); blocked != "" {
+		s.setTransactionFailed(txnID, blocked, "circuit open")
+		return fmt.Errorf("payment blocked at %s: circuit open", blocked)
+	}
+
 	// Simulate mesh hops
 	currentAmount := txn.Amount - txn.TotalFees
-	hopFeePerHop := txn.Amount * s.feeConfig.HopFeePercent
+	hopFeePerHop := txn.Amount * txn.feeRates(s.feeConfig).HopFeePercent
+	fx := newFXConverter(ctx, txn.fxRatesAt(ctx, now, fxRates), route)
 
 	for i := 0; i < len(txn.Route)-1; i++ {
 		select {
 		case <-ctx.Done():
+			if s.interruptIfShuttingDown(ctx, txnID) {
+				return ErrShuttingDown
+			}
+			s.compensate(ctx, txnID, txn.Route[i], "context cancelled")
 			s.setTransactionFailed(txnID, txn.Route[i], "context cancelled")
 			return ctx.Err()
 		default:
@@ -218,28 +420,18 @@ func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string,
 		fromCountry := txn.Route[i]
 		toCountry := txn.Route[i+1]
 
-		// Simulate latency (50-200ms per hop)
-		latency := int64(50 + (time.Now().UnixNano() % 150))
-		time.Sleep(time.Duration(latency) * time.Millisecond)
+		// Simulate the hop's latency and failure (for demo purposes)
+		outcome := sim.Hop(toCountry, failureChance)
+		time.Sleep(outcome.Latency)
+		latency := outcome.Latency.Milliseconds()
 
-		// Get FX rate (default to 1 if not available)
-		fxRate := 1.0
-		if rate, ok := fxRates[toCountry]; ok {
-			fxRate = rate
-		}
+		// Hop fee is charged in the currency the funds are currently held in
+		hopFee := fx.toHeld(txn.pricedHopFee(i, fromCountry, toCountry, hopFeePerHop))
+		fxRate, fxFallback := fx.next(fromCountry, toCountry)
 
-		// Simulate random failure (for demo purposes)
-		failed := false
-		errorMsg := ""
-		if failureChance > 0 {
-			// Simple random failure simulation
-			if time.Now().UnixNano()%100 < int64(failureChance*100) {
-				failed = true
-				errorMsg = "node timeout"
-			}
-		}
+		failed, errorMsg := outcome.Failed, outcome.Error
 
-		amountOut := currentAmount - hopFeePerHop
+		amountOut := (currentAmount - hopFee) * fxRate
 		if failed {
 			amountOut = 0
 		}
@@ -250,9 +442,10 @@ func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string,
 			Success:     !failed,
 			Latency:     latency,
 			FXRate:      fxRate,
+			FXFallback:  fxFallback,
 			AmountIn:    currentAmount,
 			AmountOut:   amountOut,
-			HopFee:      hopFeePerHop,
+			HopFee:      hopFee,
 			Timestamp:   time.Now(),
 			Error:       errorMsg,
 		}
@@ -266,11 +459,16 @@ func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string,
 		if s.onCredibilityUpdate != nil {
 			s.onCredibilityUpdate(toCountry, !failed)
 		}
+		s.outcomes.record(toCountry, !failed, hopResult.Timestamp)
+		s.recordHop(ctx, toCountry, !failed)
 
 		if failed {
+			// Unwind the hops already settled before failing the attempt
+			s.compensate(ctx, txnID, toCountry, errorMsg)
 			s.setTransactionFailed(txnID, toCountry, errorMsg)
 			return fmt.Errorf("payment failed at %s: %s", toCountry, errorMsg)
 		}
+		s.publishEvent(natsClient.SettlementHopComplete, txnID)
 
 		currentAmount = amountOut
 	}
@@ -281,21 +479,60 @@ func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string,
 	now = time.Now()
 	txn.CompletedAt = &now
 	txn.FinalAmount = currentAmount
+	recordAttemptLocked(txn, "")
+	s.countLocked(txn)
 	s.mu.Unlock()
 
+	s.notifyStatus(EventPaymentSucceeded, txnID)
 	return nil
 }
 
 // setTransactionFailed marks a transaction as failed
 func (s *TransactionStore) setTransactionFailed(txnID, failedAt, reason string) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	if txn, ok := s.transactions[txnID]; ok {
+	txn, ok := s.transactions[txnID]
+	if ok {
 		txn.Status = StatusFailed
 		txn.FailedAt = failedAt
 		now := time.Now()
 		txn.CompletedAt = &now
+		recordAttemptLocked(txn, reason)
+		s.countLocked(txn)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.notifyStatus(EventPaymentFailed, txnID)
+	}
+}
+
+// recordAttemptLocked appends the current processing run to the attempt history.
+// Caller must hold s.mu.
+func recordAttemptLocked(txn *Transaction, reason string) {
+	attempt := RouteAttempt{
+		Attempt:    len(txn.Attempts) + 1,
+		Route:      append([]string(nil), txn.Route...),
+		Status:     txn.Status,
+		FailedAt:   txn.FailedAt,
+		Error:      reason,
+		HopResults: append([]HopResult(nil), txn.HopResults...),
+	}
+	if txn.ProcessedAt != nil {
+		attempt.StartedAt = *txn.ProcessedAt
+	}
+	if txn.CompletedAt != nil {
+		attempt.EndedAt = *txn.CompletedAt
+	}
+	txn.Attempts = append(txn.Attempts, attempt)
+}
+
+// SetCandidateRoutes records the routes considered for a transaction
+func (s *TransactionStore) SetCandidateRoutes(txnID string, routes [][]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if txn, ok := s.transactions[txnID]; ok {
+		txn.CandidateRoutes = routes
 	}
 }
 
@@ -311,6 +548,52 @@ func (s *TransactionStore) GetTransaction(txnID string) (*Transaction, error) {
 	return txn, nil
 }
 
+// Snapshot returns a copy of a transaction that is safe to read while it is being processed
+func (s *TransactionStore) Snapshot(txnID string) (*Transaction, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	txn, ok := s.transactions[txnID]
+	if !ok {
+		return nil, fmt.Errorf("transaction not found")
+	}
+
+	cp := *txn
+	cp.Route = append([]string(nil), txn.Route...)
+	cp.HopResults = append([]HopResult(nil), txn.HopResults...)
+	cp.CandidateRoutes = append([][]string(nil), txn.CandidateRoutes...)
+	cp.Attempts = append([]RouteAttempt(nil), txn.Attempts...)
+	cp.Compensations = append([]Compensation(nil), txn.Compensations...)
+	return &cp, nil
+}
+
+// Restore loads a previously persisted transaction into the store
+func (s *TransactionStore) Restore(txn *Transaction) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.transactions[txn.ID]; !exists {
+		s.addLocked(txn)
+		return
+	}
+	s.transactions[txn.ID] = txn
+	s.countLocked(txn)
+}
+
+// addLocked stores a new transaction and indexes it. Callers must hold s.mu.
+func (s *TransactionStore) addLocked(txn *Transaction) {
+	s.transactions[txn.ID] = txn
+	s.order = append(s.order, txn.ID)
+	s.userTxns[txn.UserID] = append(s.userTxns[txn.UserID], txn.ID)
+	if txn.OrgID != "" {
+		s.orgTxns[txn.OrgID] = append(s.orgTxns[txn.OrgID], txn.ID)
+	}
+	if txn.BatchID != "" {
+		s.batches[txn.BatchID] = append(s.batches[txn.BatchID], txn.ID)
+	}
+	s.countLocked(txn)
+}
+
 // GetUserTransactions returns all transactions for a user
 func (s *TransactionStore) GetUserTransactions(userID string) []*Transaction {
 	s.mu.RLock()
@@ -330,38 +613,7 @@ func (s *TransactionStore) GetUserTransactions(userID string) []*Transaction {
 
 // GetAdminStats returns admin profit statistics
 func (s *TransactionStore) GetAdminStats() map[string]interface{} {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	
-	totalProfit := 0.0
-	successCount := 0
-	failedCount := 0
-	pendingCount := 0
-	totalVolume := 0.0
-	
-	for _, txn := range s.transactions {
-		totalVolume += txn.Amount
-		switch txn.Status {
-		case StatusSuccess:
-			successCount++
-			totalProfit += txn.AdminProfit
-		case StatusFailed:
-			failedCount++
-			// Still collect partial fees on failed transactions
-			totalProfit += txn.BaseFee
-		case StatusPending, StatusProcessing:
-			pendingCount++
-		}
-	}
-	
-	return map[string]interface{}{
-		"total_profit":    totalProfit,
-		"total_volume":    totalVolume,
-		"success_count":   successCount,
-		"failed_count":    failedCount,
-		"pending_count":   pendingCount,
-		"total_transactions": len(s.transactions),
-	}
+	return s.PlatformStats("").Summary()
 }
 
 // GetAllTransactions returns all transactions (for admin)
@@ -378,6 +630,12 @@ func (s *TransactionStore) GetAllTransactions() []*Transaction {
 
 // ProcessTransactionWithRoute processes a transaction using a specific route (for anti-fragility retries)
 func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnID string, route []string, fxRates map[string]float64, failureChance float64) error {
+	ctx, unlock, err := s.LockTransaction(ctx, txnID)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	s.mu.Lock()
 	txn, ok := s.transactions[txnID]
 	if !ok {
@@ -385,6 +643,10 @@ func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnI
 		return fmt.Errorf("transaction not found: %s", txnID)
 	}
 	
+	if txn.Status == StatusPendingReview {
+		s.mu.Unlock()
+		return ErrHeldForReview
+	}
 	if txn.Status != StatusPending {
 		s.mu.Unlock()
 		return fmt.Errorf("transaction not in pending state")
@@ -395,15 +657,28 @@ func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnI
 	txn.Status = StatusProcessing
 	now := time.Now()
 	txn.ProcessedAt = &now
+	s.countLocked(txn)
+	sim := s.simulator
 	s.mu.Unlock()
 
+	// Open circuits block the route before any hop is attempted
+	if blocked := s.checkRouteCircuits(ctx, route); blocked != "" {
+		s.setTransactionFailed(txnID, blocked, "circuit open")
+		return fmt.Errorf("payment blocked at %s: circuit open", blocked)
+	}
+
 	// Simulate mesh hops with the new route
 	currentAmount := txn.Amount - txn.TotalFees
-	hopFeePerHop := txn.Amount * s.feeConfig.HopFeePercent
+	hopFeePerHop := txn.Amount * txn.feeRates(s.feeConfig).HopFeePercent
+	fx := newFXConverter(ctx, txn.fxRatesAt(ctx, now, fxRates), route)
 
 	for i := 0; i < len(route)-1; i++ {
 		select {
 		case <-ctx.Done():
+			if s.interruptIfShuttingDown(ctx, txnID) {
+				return ErrShuttingDown
+			}
+			s.compensate(ctx, txnID, route[i], "context cancelled")
 			s.setTransactionFailed(txnID, route[i], "context cancelled")
 			return ctx.Err()
 		default:
@@ -412,27 +687,18 @@ func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnI
 		fromCountry := route[i]
 		toCountry := route[i+1]
 
-		// Simulate latency (50-200ms per hop)
-		latency := int64(50 + (time.Now().UnixNano() % 150))
-		time.Sleep(time.Duration(latency) * time.Millisecond)
+		// Simulate the hop's latency and failure (for demo purposes)
+		outcome := sim.Hop(toCountry, failureChance)
+		time.Sleep(outcome.Latency)
+		latency := outcome.Latency.Milliseconds()
 
-		// Get FX rate
-		fxRate := 1.0
-		if rate, ok := fxRates[toCountry]; ok {
-			fxRate = rate
-		}
+		// Hop fee is charged in the currency the funds are currently held in
+		hopFee := fx.toHeld(txn.pricedHopFee(i, fromCountry, toCountry, hopFeePerHop))
+		fxRate, fxFallback := fx.next(fromCountry, toCountry)
 
-		// Simulate random failure
-		failed := false
-		errorMsg := ""
-		if failureChance > 0 {
-			if time.Now().UnixNano()%100 < int64(failureChance*100) {
-				failed = true
-				errorMsg = "node timeout"
-			}
-		}
+		failed, errorMsg := outcome.Failed, outcome.Error
 
-		amountOut := currentAmount - hopFeePerHop
+		amountOut := (currentAmount - hopFee) * fxRate
 		if failed {
 			amountOut = 0
 		}
@@ -443,9 +709,10 @@ func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnI
 			Success:     !failed,
 			Latency:     latency,
 			FXRate:      fxRate,
+			FXFallback:  fxFallback,
 			AmountIn:    currentAmount,
 			AmountOut:   amountOut,
-			HopFee:      hopFeePerHop,
+			HopFee:      hopFee,
 			Timestamp:   time.Now(),
 			Error:       errorMsg,
 		}
@@ -458,11 +725,16 @@ func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnI
 		if s.onCredibilityUpdate != nil {
 			s.onCredibilityUpdate(toCountry, !failed)
 		}
+		s.outcomes.record(toCountry, !failed, hopResult.Timestamp)
+		s.recordHop(ctx, toCountry, !failed)
 
 		if failed {
+			// Unwind the hops already settled before failing the attempt
+			s.compensate(ctx, txnID, toCountry, errorMsg)
 			s.setTransactionFailed(txnID, toCountry, errorMsg)
 			return fmt.Errorf("payment failed at %s: %s", toCountry, errorMsg)
 		}
+		s.publishEvent(natsClient.SettlementHopComplete, txnID)
 
 		currentAmount = amountOut
 	}
@@ -473,8 +745,11 @@ func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnI
 	now = time.Now()
 	txn.CompletedAt = &now
 	txn.FinalAmount = currentAmount
+	recordAttemptLocked(txn, "")
+	s.countLocked(txn)
 	s.mu.Unlock()
 
+	s.notifyStatus(EventPaymentSucceeded, txnID)
 	return nil
 }
 
@@ -490,18 +765,61 @@ func (s *TransactionStore) ResetTransactionForRetry(txnID string) {
 		txn.FailedAt = ""
 		txn.ProcessedAt = nil
 		txn.CompletedAt = nil
+		s.countLocked(txn)
 	}
 }
 
-// MarkAsRefunded marks a transaction as refunded
-func (s *TransactionStore) MarkAsRefunded(txnID string, refundID string) {
+// refundedPrefix marks the payment method of a refunded transaction
+const refundedPrefix = "refunded:"
+
+// RefundID returns the Stripe refund ID of a refunded transaction ("" if not refunded)
+func (t *Transaction) RefundID() string {
+	if !strings.HasPrefix(t.PaymentMethod, refundedPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(t.PaymentMethod, refundedPrefix)
+}
+
+// MarkAsRefunded marks a transaction as refunded and records what was returned
+func (s *TransactionStore) MarkAsRefunded(txnID string, refund Refund) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	
-	if txn, ok := s.transactions[txnID]; ok {
+	txn, ok := s.transactions[txnID]
+	if ok {
 		txn.Status = StatusFailed // Keep as failed but mark refund
-		txn.PaymentMethod = "refunded:" + refundID
+		txn.PaymentMethod = refundedPrefix + refund.ID
+		if refund.RefundedAt.IsZero() {
+			refund.RefundedAt = time.Now()
+		}
+		txn.Refund = &refund
+		s.countLocked(txn)
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.notifyStatus(EventPaymentRefunded, txnID)
 	}
 }
 
 
+
+// MarkPaymentFailed marks a pending transaction as failed before it entered the mesh
+// (e.g. the card was declined). Transactions already processed are left unchanged.
+func (s *TransactionStore) MarkPaymentFailed(txnID string, reason string) {
+	s.mu.Lock()
+	txn, ok := s.transactions[txnID]
+	if ok && txn.Status == StatusPending {
+		txn.Status = StatusFailed
+		txn.PaymentMethod = "payment_failed"
+		now := time.Now()
+		txn.CompletedAt = &now
+		recordAttemptLocked(txn, reason)
+		s.countLocked(txn)
+	} else {
+		ok = false
+	}
+	s.mu.Unlock()
+
+	if ok {
+		s.notifyStatus(EventPaymentFailed, txnID)
+	}
+}
@@ -0,0 +1,85 @@
+package payments
+
+import "time"
+
+// VolumeCurrency is the currency transfer volume, and the spending limits
+// held against it, are measured in
+const VolumeCurrency = "USD"
+
+// SetCurrencyRates sets the source of exchange rates per USD, keyed by
+// currency, that amounts are converted to VolumeCurrency with. Without a
+// rate for a currency its amounts count unconverted.
+func (s *TransactionStore) SetCurrencyRates(rates func() map[string]float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.currencyRates = rates
+}
+
+// InVolumeCurrency converts amount in currency to VolumeCurrency at the
+// current rate
+func (s *TransactionStore) InVolumeCurrency(amount float64, currency string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return toVolumeCurrency(s.currencyRatesLocked(), amount, currency)
+}
+
+// currencyRatesLocked returns the current rates per USD, nil without a
+// source. Callers must hold s.mu.
+func (s *TransactionStore) currencyRatesLocked() map[string]float64 {
+	if s.currencyRates == nil {
+		return nil
+	}
+	return s.currencyRates()
+}
+
+func toVolumeCurrency(rates map[string]float64, amount float64, currency string) float64 {
+	if rate := rates[currency]; currency != VolumeCurrency && rate > 0 {
+		return amount / rate
+	}
+	return amount
+}
+
+// UserVolume returns the amount of a user's transactions created since the
+// given time, excluding failed ones, in VolumeCurrency
+func (s *TransactionStore) UserVolume(userID string, since time.Time) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.volumeLocked(s.userTxns[userID], since)
+}
+
+// OrgVolume returns the amount of an organization's transactions created
+// since the given time, excluding failed ones, in VolumeCurrency
+func (s *TransactionStore) OrgVolume(orgID string, since time.Time) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.volumeLocked(s.orgTxns[orgID], since)
+}
+
+// volumeLocked sums the transactions among ids created since the given
+// time, excluding failed ones, in VolumeCurrency. Callers must hold s.mu.
+func (s *TransactionStore) volumeLocked(ids []string, since time.Time) float64 {
+	rates := s.currencyRatesLocked()
+	volume := 0.0
+	for _, id := range ids {
+		txn, ok := s.transactions[id]
+		if !ok || txn.Status == StatusFailed || txn.CreatedAt.Before(since) {
+			continue
+		}
+		volume += toVolumeCurrency(rates, txn.Amount, txn.Currency)
+	}
+	return volume
+}
+
+// DayStart returns the start of t's day in UTC, the window daily spending
+// limits apply to
+func DayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// MonthStart returns the start of t's calendar month in UTC, the window
+// monthly spending limits apply to
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
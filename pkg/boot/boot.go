@@ -0,0 +1,193 @@
+// Package boot waits for the server's external dependencies before its
+// components are wired. Each dependency is connected with exponential backoff;
+// required ones must come up within the startup timeout, optional ones are
+// skipped (with the features they back disabled) if they aren't up by the
+// time the required ones are.
+package boot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config holds startup wait settings
+type Config struct {
+	Timeout        time.Duration // How long to wait for required dependencies
+	InitialBackoff time.Duration // Wait after the first failed attempt; doubles per failure
+	MaxBackoff     time.Duration // Cap on the wait between attempts
+}
+
+// DefaultConfig returns sensible defaults
+func DefaultConfig() *Config {
+	return &Config{
+		Timeout:        60 * time.Second,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// ConnectFunc makes one connection attempt
+type ConnectFunc func(ctx context.Context) error
+
+// Dependency is an external service the server connects to at startup
+type Dependency struct {
+	Name     string
+	Required bool     // Startup fails if it isn't up within the timeout
+	Features []string // Features disabled while it is unavailable
+	Connect  ConnectFunc
+}
+
+// Status is the startup outcome of one dependency
+type Status struct {
+	Up       bool
+	Required bool
+	Attempts int
+	Elapsed  time.Duration // Until it came up or was given up on
+	Err      error         // Last attempt's error if not up
+}
+
+// Report is the outcome of a startup wait
+type Report struct {
+	Dependencies map[string]Status
+	disabled     map[string]bool // Features of unavailable dependencies
+}
+
+// Up reports whether a dependency connected
+func (r *Report) Up(name string) bool {
+	return r.Dependencies[name].Up
+}
+
+// Enabled reports whether a feature is available, i.e. no dependency backing
+// it is down
+func (r *Report) Enabled(feature string) bool {
+	return !r.disabled[feature]
+}
+
+// Disabled returns the features disabled by unavailable dependencies, sorted
+func (r *Report) Disabled() []string {
+	features := make([]string, 0, len(r.disabled))
+	for f := range r.disabled {
+		features = append(features, f)
+	}
+	sort.Strings(features)
+	return features
+}
+
+// Orchestrator connects the registered dependencies at startup
+type Orchestrator struct {
+	cfg  *Config
+	deps []Dependency
+}
+
+// NewOrchestrator creates an orchestrator
+func NewOrchestrator(cfg *Config) *Orchestrator {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Orchestrator{cfg: cfg}
+}
+
+// Add registers a dependency
+func (o *Orchestrator) Add(dep Dependency) {
+	o.deps = append(o.deps, dep)
+}
+
+// Wait connects every dependency concurrently, retrying with backoff. It
+// returns once the required dependencies are up, giving optional ones at
+// least one attempt. Returns an error (with the report) if a required
+// dependency isn't up within the timeout.
+func (o *Orchestrator) Wait(ctx context.Context) (*Report, error) {
+	ctx, cancel := context.WithTimeout(ctx, o.cfg.Timeout)
+	defer cancel()
+
+	// Closed once the required dependencies are settled; optional ones stop
+	// retrying then
+	requiredDone := make(chan struct{})
+	statuses := make([]Status, len(o.deps))
+
+	var required, all sync.WaitGroup
+	for i, dep := range o.deps {
+		all.Add(1)
+		if dep.Required {
+			required.Add(1)
+		}
+		go func(i int, dep Dependency) {
+			defer all.Done()
+			if dep.Required {
+				defer required.Done()
+			}
+			statuses[i] = o.connect(ctx, dep, requiredDone)
+		}(i, dep)
+	}
+	required.Wait()
+	close(requiredDone)
+	all.Wait()
+
+	report := &Report{Dependencies: make(map[string]Status, len(o.deps)), disabled: make(map[string]bool)}
+	var failed []string
+	for i, dep := range o.deps {
+		status := statuses[i]
+		report.Dependencies[dep.Name] = status
+		if status.Up {
+			log.Printf("✅ %s ready (%d attempts, %v)", dep.Name, status.Attempts, status.Elapsed.Round(time.Millisecond))
+			continue
+		}
+		for _, f := range dep.Features {
+			report.disabled[f] = true
+		}
+		if dep.Required {
+			failed = append(failed, fmt.Sprintf("%s: %v", dep.Name, status.Err))
+			continue
+		}
+		log.Printf("⚠️  %s not available: %v (optional, disabled: %s)", dep.Name, status.Err, strings.Join(dep.Features, ", "))
+	}
+	if len(failed) > 0 {
+		return report, fmt.Errorf("required dependencies not ready after %v: %s", o.cfg.Timeout, strings.Join(failed, "; "))
+	}
+	return report, nil
+}
+
+// connect attempts dep until it is up, ctx expires or (for an optional
+// dependency past its first attempt) the required ones are settled
+func (o *Orchestrator) connect(ctx context.Context, dep Dependency, requiredDone <-chan struct{}) Status {
+	start := time.Now()
+	status := Status{Required: dep.Required}
+	backoff := o.cfg.InitialBackoff
+	for {
+		status.Attempts++
+		err := dep.Connect(ctx)
+		if err == nil {
+			status.Up, status.Err = true, nil
+			status.Elapsed = time.Since(start)
+			return status
+		}
+		if status.Err == nil || ctx.Err() == nil {
+			status.Err = err // Keep the last real error over the timeout cutting an attempt short
+		}
+		if status.Attempts == 1 {
+			log.Printf("⏳ Waiting for %s: %v", dep.Name, status.Err)
+		}
+
+		var stop <-chan struct{}
+		if !dep.Required {
+			stop = requiredDone
+		}
+		select {
+		case <-ctx.Done():
+			status.Elapsed = time.Since(start)
+			return status
+		case <-stop:
+			status.Elapsed = time.Since(start)
+			return status
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > o.cfg.MaxBackoff {
+			backoff = o.cfg.MaxBackoff
+		}
+	}
+}
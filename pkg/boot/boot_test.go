@@ -0,0 +1,88 @@
+package boot
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testConfig(timeout time.Duration) *Config {
+	return &Config{Timeout: timeout, InitialBackoff: 5 * time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+}
+
+// upAfter returns a ConnectFunc that fails until its nth attempt
+func upAfter(n int32, attempts *int32) ConnectFunc {
+	return func(ctx context.Context) error {
+		if atomic.AddInt32(attempts, 1) < n {
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+}
+
+func TestWaitRetriesUntilRequiredDependenciesAreUp(t *testing.T) {
+	var pgAttempts, redisAttempts int32
+	o := NewOrchestrator(testConfig(5 * time.Second))
+	o.Add(Dependency{Name: "postgres", Required: true, Connect: upAfter(4, &pgAttempts)})
+	o.Add(Dependency{Name: "redis", Required: true, Features: []string{"rate-limiting"}, Connect: upAfter(2, &redisAttempts)})
+
+	report, err := o.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("Wait failed: %v", err)
+	}
+	if !report.Up("postgres") || report.Dependencies["postgres"].Attempts != 4 {
+		t.Errorf("expected postgres up on the 4th attempt, got %+v", report.Dependencies["postgres"])
+	}
+	if !report.Up("redis") || !report.Enabled("rate-limiting") || len(report.Disabled()) != 0 {
+		t.Errorf("expected every feature enabled, disabled: %v", report.Disabled())
+	}
+}
+
+func TestWaitFailsWhenRequiredDependencyTimesOut(t *testing.T) {
+	o := NewOrchestrator(testConfig(50 * time.Millisecond))
+	o.Add(Dependency{Name: "neo4j", Required: true, Features: []string{"country-admin"}, Connect: func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}})
+
+	start := time.Now()
+	report, err := o.Wait(context.Background())
+	if err == nil {
+		t.Fatal("expected error for a required dependency that never comes up")
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Wait ran %v past its timeout", time.Since(start))
+	}
+	if status := report.Dependencies["neo4j"]; status.Up || status.Attempts < 2 || status.Err.Error() != "connection refused" {
+		t.Errorf("unexpected status: %+v", status)
+	}
+	if report.Enabled("country-admin") {
+		t.Error("feature of a down dependency should be disabled")
+	}
+}
+
+func TestOptionalDependencyDoesNotHoldUpStartup(t *testing.T) {
+	var natsAttempts int32
+	o := NewOrchestrator(testConfig(5 * time.Second))
+	o.Add(Dependency{Name: "postgres", Required: true, Connect: func(ctx context.Context) error { return nil }})
+	o.Add(Dependency{Name: "nats", Features: []string{"settlement-events", "graph-sync"}, Connect: func(ctx context.Context) error {
+		atomic.AddInt32(&natsAttempts, 1)
+		return errors.New("no servers available")
+	}})
+
+	start := time.Now()
+	report, err := o.Wait(context.Background())
+	if err != nil {
+		t.Fatalf("optional dependency should not fail startup: %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Errorf("Wait waited %v for an optional dependency", time.Since(start))
+	}
+	if report.Up("nats") || atomic.LoadInt32(&natsAttempts) < 1 {
+		t.Errorf("expected nats attempted and down, got %+v", report.Dependencies["nats"])
+	}
+	if disabled := report.Disabled(); len(disabled) != 2 || disabled[0] != "graph-sync" {
+		t.Errorf("unexpected disabled features: %v", disabled)
+	}
+}
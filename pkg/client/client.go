@@ -0,0 +1,267 @@
+// Package client is a Go client for the payments and routing API. Requests
+// and responses are the types the server's handlers use, so they match the
+// OpenAPI document served at /api/v1/openapi.json.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/handlers"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// DefaultTimeout bounds requests made with the default HTTP client
+const DefaultTimeout = 30 * time.Second
+
+// Client calls the API of one server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string
+}
+
+// New creates a client for the server at baseURL, e.g. https://plm.example.com
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// SetHTTPClient sets the HTTP client used for requests
+func (c *Client) SetHTTPClient(hc *http.Client) {
+	c.httpClient = hc
+}
+
+// SetToken sets the bearer token sent with requests (see Login)
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// APIError is an error response from the server
+type APIError struct {
+	StatusCode int
+	Code       apierror.Code // Empty if the response was not a JSON error
+	Message    string
+	Details    json.RawMessage // Structured context for some codes, e.g. validation_failed
+	RequestID  string          // Quote it when reporting problems
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("plm api: %d %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("plm api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// Login authenticates with email and password and uses the returned token
+// for subsequent requests
+func (c *Client) Login(ctx context.Context, email, password string) (*handlers.LoginResponse, error) {
+	var resp handlers.LoginResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/login", nil, &handlers.LoginRequest{Email: email, Password: password}, &resp); err != nil {
+		return nil, err
+	}
+	c.SetToken(resp.Token)
+	return &resp, nil
+}
+
+// FindRoutes ranks the routes between two countries. An unreachable target is
+// reported in the response (Success false), not as an error.
+func (c *Client) FindRoutes(ctx context.Context, req *handlers.RouteRequest) (*handlers.RouteResponse, error) {
+	var resp handlers.RouteResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/route", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// SimulateRoute compares the current routes with those under hypothetical conditions
+func (c *Client) SimulateRoute(ctx context.Context, req *handlers.RouteSimulationRequest) (*handlers.RouteSimulationResponse, error) {
+	var resp handlers.RouteSimulationResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/route/simulate", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateQuote quotes a payment; pass its QuoteID to CreatePayment to settle
+// at the quoted exchange rates
+func (c *Client) CreateQuote(ctx context.Context, req *handlers.QuoteRequest) (*handlers.QuoteResponse, error) {
+	var resp handlers.QuoteResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/payments/quote", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreatePayment creates a pending payment. Retries with the same non-empty
+// idempotencyKey return the original payment instead of creating another.
+func (c *Client) CreatePayment(ctx context.Context, req *handlers.CreatePaymentRequest, idempotencyKey string) (*handlers.CreatePaymentResponse, error) {
+	var resp handlers.CreatePaymentResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/payments/create", idempotencyHeader(idempotencyKey), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ConfirmPayment pays for a pending payment and settles it. A payment that
+// fails in the mesh is reported in the response (Success false), not as an error.
+func (c *Client) ConfirmPayment(ctx context.Context, req *handlers.ConfirmPaymentRequest) (*handlers.ConfirmPaymentResponse, error) {
+	var resp handlers.ConfirmPaymentResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/payments/confirm", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// CreateBatch creates and settles several payments, with the same
+// idempotency semantics as CreatePayment
+func (c *Client) CreateBatch(ctx context.Context, req *handlers.CreateBatchRequest, idempotencyKey string) (*handlers.CreateBatchResponse, error) {
+	var resp handlers.CreateBatchResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/payments/batch", idempotencyHeader(idempotencyKey), req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetBatch returns the current state of a batch
+func (c *Client) GetBatch(ctx context.Context, id string) (*handlers.BatchResponse, error) {
+	var resp handlers.BatchResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/payments/batch/"+url.PathEscape(id), nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// GetTransaction returns a transaction
+func (c *Client) GetTransaction(ctx context.Context, id string) (*payments.Transaction, error) {
+	var txn payments.Transaction
+	if err := c.do(ctx, http.MethodGet, "/api/v1/payments/transaction?id="+url.QueryEscape(id), nil, nil, &txn); err != nil {
+		return nil, err
+	}
+	return &txn, nil
+}
+
+// HistoryOptions filter and page transaction history; zero values use the server defaults
+type HistoryOptions struct {
+	Limit     int
+	Offset    int
+	From      time.Time // Created at or after
+	To        time.Time // Created before
+	Statuses  []payments.TransactionStatus
+	SortBy    string // created_at, amount or final_amount
+	Ascending bool   // Oldest or smallest first
+}
+
+// values encodes the options as query parameters
+func (o HistoryOptions) values() url.Values {
+	v := url.Values{}
+	if o.Limit > 0 {
+		v.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset > 0 {
+		v.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if !o.From.IsZero() {
+		v.Set("from", o.From.Format(time.RFC3339))
+	}
+	if !o.To.IsZero() {
+		v.Set("to", o.To.Format(time.RFC3339))
+	}
+	if len(o.Statuses) > 0 {
+		statuses := make([]string, len(o.Statuses))
+		for i, s := range o.Statuses {
+			statuses[i] = string(s)
+		}
+		v.Set("status", strings.Join(statuses, ","))
+	}
+	if o.SortBy != "" {
+		v.Set("sort", o.SortBy)
+	}
+	if o.Ascending {
+		v.Set("order", "asc")
+	}
+	return v
+}
+
+// History returns a page of the caller's transactions
+func (c *Client) History(ctx context.Context, opts HistoryOptions) (*handlers.HistoryResponse, error) {
+	path := "/api/v1/payments/history"
+	if q := opts.values().Encode(); q != "" {
+		path += "?" + q
+	}
+	var resp handlers.HistoryResponse
+	if err := c.do(ctx, http.MethodGet, path, nil, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func idempotencyHeader(key string) http.Header {
+	if key == "" {
+		return nil
+	}
+	return http.Header{"Idempotency-Key": {key}}
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		var errBody struct {
+			apierror.ErrorBody
+			Details json.RawMessage `json:"details"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &errBody) == nil && errBody.Error != "" {
+			apiErr.Code = errBody.Code
+			apiErr.Message = errBody.Error
+			apiErr.Details = errBody.Details
+			apiErr.RequestID = errBody.RequestID
+		} else {
+			apiErr.Message = strings.TrimSpace(string(data))
+		}
+		return apiErr
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/handlers"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func TestClientCallsAuthenticatedRoutes(t *testing.T) {
+	tm, err := auth.NewTokenManager(&auth.TokenConfig{
+		SymmetricKey: "0123456789abcdef0123456789abcdef",
+		Issuer:       "plm-test",
+		TokenTTL:     time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	token, _, err := tm.GenerateToken(&auth.User{ID: "user-1", Role: auth.RoleUser})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	routes := handlers.NewRouteHandler(router.BuildCountryGraphWithDefaults())
+	mux.Handle("/api/v1/route", middleware.NewAuthMiddleware(tm).Authenticate(http.HandlerFunc(routes.HandleRouteHTTP)))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.URL + "/")
+	_, err = c.FindRoutes(context.Background(), &handlers.RouteRequest{Source: "USA", Target: "DEU"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Code != apierror.CodeUnauthorized || apiErr.Message != "missing authorization header" {
+		t.Fatalf("expected a 401 APIError without a token, got %v", err)
+	}
+
+	c.SetToken(token)
+	resp, err := c.FindRoutes(context.Background(), &handlers.RouteRequest{Source: "USA", Target: "DEU"})
+	if err != nil {
+		t.Fatalf("FindRoutes failed: %v", err)
+	}
+	if !resp.Success || len(resp.Paths) == 0 || resp.Paths[0].Nodes[0] != "USA" {
+		t.Errorf("unexpected response %+v", resp)
+	}
+}
+
+func TestClientSendsIdempotencyKeyAndQuery(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/payments/create", func(w http.ResponseWriter, r *http.Request) {
+		var req handlers.CreatePaymentRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.Header.Get("Idempotency-Key") != "key-1" || req.Amount != 100 {
+			apierror.Respond(w, http.StatusBadRequest, "unexpected request")
+			return
+		}
+		json.NewEncoder(w).Encode(handlers.CreatePaymentResponse{Transaction: &payments.Transaction{ID: "txn_1", Amount: req.Amount}})
+	})
+	mux.HandleFunc("/api/v1/payments/history", func(w http.ResponseWriter, r *http.Request) {
+		if q := r.URL.Query(); q.Get("limit") != "5" || q.Get("status") != "success,failed" || q.Get("order") != "asc" {
+			apierror.Respond(w, http.StatusBadRequest, "unexpected query "+r.URL.RawQuery)
+			return
+		}
+		json.NewEncoder(w).Encode(handlers.HistoryResponse{HistoryPage: &payments.HistoryPage{Total: 7, Limit: 5}})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c := New(srv.URL)
+
+	created, err := c.CreatePayment(context.Background(), &handlers.CreatePaymentRequest{Amount: 100}, "key-1")
+	if err != nil || created.Transaction.ID != "txn_1" {
+		t.Fatalf("CreatePayment = %+v, %v", created, err)
+	}
+
+	page, err := c.History(context.Background(), HistoryOptions{
+		Limit:     5,
+		Statuses:  []payments.TransactionStatus{payments.StatusSuccess, payments.StatusFailed},
+		Ascending: true,
+	})
+	if err != nil || page.Total != 7 {
+		t.Fatalf("History = %+v, %v", page, err)
+	}
+
+	var apiErr *APIError
+	if _, err := c.GetTransaction(context.Background(), "missing"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected a 404 APIError, got %v", err)
+	} else if apiErr.Code != "" {
+		t.Errorf("a plain-text error should have no code, got %q", apiErr.Code)
+	}
+}
@@ -0,0 +1,98 @@
+// Package logging configures the structured (slog) logger and carries
+// request IDs through contexts so every log line of a request can be correlated.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request ID in requests and responses
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if none
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// NewRequestID generates a request ID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// Detach returns a background context that keeps ctx's request ID, for work
+// that outlives the request (e.g. retries continued after the response)
+func Detach(ctx context.Context) context.Context {
+	if id := RequestID(ctx); id != "" {
+		return WithRequestID(context.Background(), id)
+	}
+	return context.Background()
+}
+
+// contextHandler adds the request ID from the record's context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// New creates a logger writing to w in the given format ("text" or "json")
+// at the given level ("debug", "info", "warn" or "error")
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", level, err)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", "text":
+		handler = slog.NewTextHandler(w, opts)
+	case "json":
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("invalid log format %q (want text or json)", format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// Setup installs a logger as the slog default. The standard log package is
+// routed through it too, so remaining log.Printf calls become structured records.
+func Setup(w io.Writer, format, level string) error {
+	logger, err := New(w, format, level)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+	return nil
+}
@@ -0,0 +1,68 @@
+package metrics
+
+// Mesh metrics, registered in Default and served on /metrics
+var (
+	// HTTPRequestDuration is API latency by method, mux route pattern and status code
+	HTTPRequestDuration = Default.NewHistogramVec("plm_http_request_duration_seconds",
+		"HTTP request latency by method, route pattern and status code.", DefBuckets, "method", "route", "status")
+
+	// APIRequests counts versioned API requests by version and status class (2xx, 4xx, ...)
+	APIRequests = Default.NewCounterVec("plm_api_requests_total",
+		"API requests by version and status class.", "version", "status")
+
+	// APIDeprecatedRequests counts requests to deprecated API versions or endpoints
+	APIDeprecatedRequests = Default.NewCounterVec("plm_api_deprecated_requests_total",
+		"Requests to deprecated API versions or endpoints, by version and route pattern.", "version", "route")
+
+	// RouteComputeDuration is K-shortest-path computation time by router (mesh or country)
+	RouteComputeDuration = Default.NewHistogramVec("plm_route_compute_duration_seconds",
+		"Time spent computing K shortest paths.", DefBuckets, "router")
+
+	// RouteCacheLookups counts country route cache lookups by result (hit or miss)
+	RouteCacheLookups = Default.NewCounterVec("plm_route_cache_lookups_total",
+		"Country route cache lookups by result.", "result")
+
+	// PaymentsTotal counts payments by outcome (succeeded, failed, refunded)
+	PaymentsTotal = Default.NewCounterVec("plm_payments_total",
+		"Payments by final outcome.", "outcome")
+
+	// NATSPublishErrors counts failed JetStream publishes by event kind
+	NATSPublishErrors = Default.NewCounterVec("plm_nats_publish_errors_total",
+		"Failed NATS JetStream publishes.", "event")
+
+	// NATSDeadLetters counts messages moved to the dead letter stream by consumer
+	NATSDeadLetters = Default.NewCounterVec("plm_nats_dead_letters_total",
+		"Messages moved to the dead letter stream after failing their maximum deliveries.", "consumer")
+
+	// WebSocketDropped counts messages not delivered to slow WebSocket clients by message type
+	WebSocketDropped = Default.NewCounterVec("plm_websocket_messages_dropped_total",
+		"WebSocket messages dropped because a client's send buffer was full.", "type")
+
+	// WebSocketCoalesced counts pending WebSocket updates replaced by a newer update for the same entity
+	WebSocketCoalesced = Default.NewCounterVec("plm_websocket_messages_coalesced_total",
+		"WebSocket updates collapsed into a newer update for the same node or edge.", "type")
+
+	// WebSocketEvictions counts WebSocket clients disconnected for falling too far behind
+	WebSocketEvictions = Default.NewCounterVec("plm_websocket_evictions_total",
+		"WebSocket clients disconnected after too many consecutive dropped messages.")
+
+	// FXFetches counts FX rate fetches by provider and result (success, error, rate_limited)
+	FXFetches = Default.NewCounterVec("plm_fx_fetches_total",
+		"FX rate fetches by provider and result.", "provider", "result")
+
+	// Neo4jQueryDuration is Neo4j query latency by operation
+	Neo4jQueryDuration = Default.NewHistogramVec("plm_neo4j_query_duration_seconds",
+		"Neo4j query latency by operation.", DefBuckets, "operation")
+)
+
+// RegisterWebSocketClients exposes the connected WebSocket client count
+func RegisterWebSocketClients(count func() int) {
+	Default.NewGaugeFunc("plm_websocket_clients", "Connected WebSocket clients.", func() float64 {
+		return float64(count())
+	})
+}
+
+// RegisterDeadLetterDepth exposes the number of messages waiting in the dead letter stream
+func RegisterDeadLetterDepth(depth func() float64) {
+	Default.NewGaugeFunc("plm_nats_dead_letter_depth", "Messages waiting in the NATS dead letter stream.", depth)
+}
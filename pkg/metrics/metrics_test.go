@@ -0,0 +1,58 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExpositionFormat(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounterVec("test_requests_total", "Requests.", "code")
+	latency := reg.NewHistogramVec("test_latency_seconds", "Latency.", []float64{0.1, 1}, "op")
+	reg.NewGaugeFunc("test_clients", "Clients.", func() float64 { return 3 })
+
+	requests.Inc("200")
+	requests.Add(2, "200")
+	requests.Inc(`say "hi"`)
+	latency.Observe(0.05, "read")
+	latency.Observe(0.5, "read")
+	latency.Observe(5, "read")
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE test_requests_total counter",
+		`test_requests_total{code="200"} 3`,
+		`test_requests_total{code="say \"hi\""} 1`,
+		"# TYPE test_clients gauge\ntest_clients 3",
+		`test_latency_seconds_bucket{op="read",le="0.1"} 1`,
+		`test_latency_seconds_bucket{op="read",le="1"} 2`,
+		`test_latency_seconds_bucket{op="read",le="+Inf"} 3`,
+		`test_latency_seconds_sum{op="read"} 5.55`,
+		`test_latency_seconds_count{op="read"} 3`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+
+	// Families are sorted by name
+	if strings.Index(body, "test_clients") > strings.Index(body, "test_latency_seconds") {
+		t.Error("families not sorted by name")
+	}
+}
+
+func TestDuplicateRegistrationPanics(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounterVec("dup_total", "Dup.")
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic on duplicate registration")
+		}
+	}()
+	reg.NewCounterVec("dup_total", "Dup.")
+}
@@ -0,0 +1,333 @@
+// Package openapi builds OpenAPI 3 documents whose schemas are derived from
+// Go types by reflection, following encoding/json: fields are named by their
+// JSON tags, embedded structs are flattened and fields without omitempty are
+// required, since they are always encoded. Named struct types become shared
+// component schemas.
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Version is the OpenAPI version of generated documents
+const Version = "3.0.3"
+
+// Document is an OpenAPI document
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+
+	errorBody interface{} // Body of error responses (ErrorBody by default)
+
+	// Go type -> component name, and the reverse to resolve name clashes
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+// Info describes the API
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Components holds the shared schemas and security schemes
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is an authentication method
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// PathItem holds the operations of a path by lower-case HTTP method
+type PathItem map[string]*Operation
+
+// Operation is an API operation
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a path, query or header parameter
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query or header
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is a JSON request body
+type RequestBody struct {
+	Required bool                  `json:"required"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response is a response to an operation
+type Response struct {
+	Description string                `json:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// MediaType holds the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema is a JSON schema, as far as OpenAPI uses it
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+// Endpoint describes an operation to add to a document
+type Endpoint struct {
+	Method      string
+	Path        string // Path parameters in braces, e.g. /api/v1/payments/batch/{id}
+	ID          string // operationId
+	Summary     string
+	Description string
+	Tag         string
+	Auth        bool // Requires a bearer token
+	Params      []*Parameter
+	Request     interface{} // Zero value of the JSON request body type, or nil
+	Response    interface{} // Zero value of the JSON response body type, or nil
+	Errors      []int       // Documented error statuses
+}
+
+// ErrorBody is the default body of error responses
+type ErrorBody struct {
+	Error string `json:"error"`
+}
+
+// New creates an empty document
+func New(title, version, description string) *Document {
+	return &Document{
+		OpenAPI: Version,
+		Info:    Info{Title: title, Version: version, Description: description},
+		Paths:   make(map[string]*PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]*SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "PASETO"},
+			},
+		},
+		errorBody: ErrorBody{},
+		names:     make(map[reflect.Type]string),
+		types:     make(map[string]reflect.Type),
+	}
+}
+
+// SetErrorBody sets the type of error response bodies for endpoints added
+// afterwards, e.g. SetErrorBody(MyError{})
+func (d *Document) SetErrorBody(v interface{}) {
+	d.errorBody = v
+}
+
+// Add adds an endpoint, registering the schemas of its bodies
+func (d *Document) Add(e Endpoint) {
+	op := &Operation{
+		OperationID: e.ID,
+		Summary:     e.Summary,
+		Description: e.Description,
+		Parameters:  e.Params,
+		Responses:   make(map[string]*Response),
+	}
+	if e.Tag != "" {
+		op.Tags = []string{e.Tag}
+	}
+	if e.Auth {
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+	}
+	if e.Request != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: jsonContent(d.SchemaOf(e.Request))}
+	}
+
+	success := &Response{Description: "OK"}
+	if e.Response != nil {
+		success.Content = jsonContent(d.SchemaOf(e.Response))
+	}
+	op.Responses["200"] = success
+
+	errorSchema := d.SchemaOf(d.errorBody)
+	statuses := append([]int(nil), e.Errors...)
+	if e.Auth {
+		statuses = append(statuses, 401)
+	}
+	for _, status := range statuses {
+		op.Responses[strconv.Itoa(status)] = &Response{Description: http.StatusText(status), Content: jsonContent(errorSchema)}
+	}
+
+	item, ok := d.Paths[e.Path]
+	if !ok {
+		item = &PathItem{}
+		d.Paths[e.Path] = item
+	}
+	(*item)[strings.ToLower(e.Method)] = op
+}
+
+// SchemaOf returns the schema of v's type; named structs are registered as
+// components and referenced
+func (d *Document) SchemaOf(v interface{}) *Schema {
+	return d.schema(reflect.TypeOf(v))
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (d *Document) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{} // interface{}: any value
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case implements(t, textMarshalerType) && !implements(t, jsonMarshalerType):
+		return &Schema{Type: "string"}
+	case implements(t, jsonMarshalerType):
+		return &Schema{} // Custom encoding: shape unknown
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		if t.PkgPath() == "time" && t.Name() == "Duration" {
+			return &Schema{Type: "integer", Format: "int64", Description: "nanoseconds"}
+		}
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: d.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return d.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + d.component(t)}
+	}
+	return &Schema{}
+}
+
+// component registers a named struct type and returns its component name
+func (d *Document) component(t reflect.Type) string {
+	if name, ok := d.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if other, taken := d.types[name]; taken && other != t {
+		// Same name in another package: qualify with the package name
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	d.names[t] = name
+	d.types[name] = t
+	// Registered before its fields are walked, so recursive types terminate
+	d.Components.Schemas[name] = d.structSchema(t)
+	return name
+}
+
+// structSchema describes the JSON object of a struct type
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	d.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+// addFields adds the JSON fields of t to s, flattening embedded structs
+func (d *Document) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				d.addFields(s, ft)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := d.schema(f.Type)
+		if strings.Contains(opts, "string") {
+			fs = &Schema{Type: "string"}
+		}
+		if f.Type.Kind() == reflect.Pointer && fs.Ref == "" {
+			fs.Nullable = true
+		}
+		s.Properties[name] = fs
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+func implements(t, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PointerTo(t).Implements(iface)
+}
+
+func jsonContent(s *Schema) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: s}}
+}
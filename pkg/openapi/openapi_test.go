@@ -0,0 +1,93 @@
+package openapi
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+type testBase struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type testNode struct {
+	testBase
+	Name     string             `json:"name,omitempty"`
+	Score    *float64           `json:"score"`
+	Tags     []string           `json:"tags"`
+	Weights  map[string]float64 `json:"weights"`
+	Children []*testNode        `json:"children,omitempty"`
+	Extra    interface{}        `json:"extra"`
+	Secret   string             `json:"-"`
+	internal int
+}
+
+func TestSchemaFollowsJSONEncoding(t *testing.T) {
+	doc := New("Test", "1.0.0", "")
+	ref := doc.SchemaOf(&testNode{})
+	if ref.Ref != "#/components/schemas/testNode" {
+		t.Fatalf("expected a component reference, got %+v", ref)
+	}
+
+	s := doc.Components.Schemas["testNode"]
+	var names []string
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if want := []string{"children", "created_at", "extra", "id", "name", "score", "tags", "weights"}; !slices.Equal(names, want) {
+		t.Errorf("properties = %v, want %v", names, want)
+	}
+	if want := []string{"created_at", "extra", "id", "score", "tags", "weights"}; !slices.Equal(s.Required, want) {
+		t.Errorf("required = %v, want %v", s.Required, want)
+	}
+
+	for name, want := range map[string]Schema{
+		"created_at": {Type: "string", Format: "date-time"},
+		"score":      {Type: "number", Format: "double", Nullable: true},
+		"extra":      {},
+	} {
+		if got := *s.Properties[name]; got.Type != want.Type || got.Format != want.Format || got.Nullable != want.Nullable {
+			t.Errorf("%s = %+v, want %+v", name, got, want)
+		}
+	}
+	if items := s.Properties["children"].Items; items == nil || items.Ref != ref.Ref {
+		t.Errorf("recursive children should reference the component, got %+v", s.Properties["children"])
+	}
+	if ap := s.Properties["weights"].AdditionalProperties; ap == nil || ap.Type != "number" {
+		t.Errorf("weights = %+v", s.Properties["weights"])
+	}
+}
+
+func TestAddDocumentsOperation(t *testing.T) {
+	doc := New("Test", "1.0.0", "")
+	doc.Add(Endpoint{
+		Method: "POST", Path: "/nodes", ID: "createNode", Tag: "nodes", Auth: true,
+		Request: testNode{}, Response: testNode{}, Errors: []int{400},
+	})
+
+	op := (*doc.Paths["/nodes"])["post"]
+	if op == nil || op.OperationID != "createNode" || len(op.Security) != 1 {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+	for _, status := range []string{"200", "400", "401"} {
+		if op.Responses[status] == nil {
+			t.Errorf("missing %s response", status)
+		}
+	}
+	if op.Responses["400"].Content["application/json"].Schema.Ref != "#/components/schemas/ErrorBody" {
+		t.Error("errors should use the error body schema")
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded map[string]interface{}
+	json.Unmarshal(data, &decoded)
+	if decoded["openapi"] != Version {
+		t.Errorf("openapi = %v", decoded["openapi"])
+	}
+}
@@ -0,0 +1,43 @@
+// Package refdata holds the ISO reference data that user input is checked
+// against: ISO 4217 currency codes and ISO 3166-1 alpha-3 country codes.
+// Codes reach Neo4j queries and the ledger, so anything not on these
+// allow-lists is rejected rather than passed through.
+package refdata
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnknownCurrency is returned for codes that are not ISO 4217 currencies
+	ErrUnknownCurrency = errors.New("unknown currency code")
+	// ErrUnknownCountry is returned for codes that are not ISO 3166-1 alpha-3 countries
+	ErrUnknownCountry = errors.New("unknown country code")
+)
+
+// CheckCurrency returns ErrUnknownCurrency unless code is an ISO 4217 code
+func CheckCurrency(code string) error {
+	if !IsCurrency(code) {
+		return fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return nil
+}
+
+// CheckCountry returns ErrUnknownCountry unless code is an ISO 3166-1 alpha-3 code
+func CheckCountry(code string) error {
+	if !IsCountry(code) {
+		return fmt.Errorf("%w %q", ErrUnknownCountry, code)
+	}
+	return nil
+}
+
+// CheckRoute checks every country code of a route
+func CheckRoute(route []string) error {
+	for _, code := range route {
+		if err := CheckCountry(code); err != nil {
+			return err
+		}
+	}
+	return nil
+}
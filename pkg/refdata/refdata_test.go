@@ -0,0 +1,42 @@
+package refdata
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestAllowLists(t *testing.T) {
+	if len(countries) != 249 {
+		t.Errorf("%d country codes, want the 249 of ISO 3166-1", len(countries))
+	}
+	for _, code := range []string{"USA", "DEU", "TWN", "SGP", "ZWE"} {
+		if !IsCountry(code) {
+			t.Errorf("IsCountry(%q) = false", code)
+		}
+	}
+	for _, code := range []string{"", "usa", "US", "XYZ", "USA}) DETACH DELETE (n", "EUR"} {
+		if IsCountry(code) {
+			t.Errorf("IsCountry(%q) = true", code)
+		}
+	}
+
+	if units, ok := MinorUnits("JPY"); !ok || units != 0 {
+		t.Errorf("MinorUnits(JPY) = %d, %v", units, ok)
+	}
+	if !IsCurrency("EUR") || IsCurrency("eur") || IsCurrency("USA") || IsCurrency("BTC") {
+		t.Error("unexpected currency allow-list membership")
+	}
+}
+
+func TestCheckRoute(t *testing.T) {
+	if err := CheckRoute([]string{"USA", "GBR", "IND"}); err != nil {
+		t.Errorf("CheckRoute: %v", err)
+	}
+	err := CheckRoute([]string{"USA", "GB"})
+	if !errors.Is(err, ErrUnknownCountry) || err.Error() != `unknown country code "GB"` {
+		t.Errorf("CheckRoute error = %v", err)
+	}
+	if err := CheckCurrency("GBX"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("CheckCurrency error = %v", err)
+	}
+}
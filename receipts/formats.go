@@ -0,0 +1,220 @@
+package receipts
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// Format is a receipt output format
+type Format string
+
+// Receipt formats
+const (
+	FormatPDF  Format = "pdf"
+	FormatJSON Format = "json"
+	FormatHTML Format = "html"
+	FormatCSV  Format = "csv"
+)
+
+// formatTypes maps each format to its media type, in preference order for
+// wildcard Accept headers
+var formatTypes = []struct {
+	format    Format
+	mediaType string
+}{
+	{FormatPDF, "application/pdf"},
+	{FormatJSON, "application/json"},
+	{FormatHTML, "text/html"},
+	{FormatCSV, "text/csv"},
+}
+
+// ParseFormat returns the format named s (pdf, json, html or csv)
+func ParseFormat(s string) (Format, bool) {
+	for _, ft := range formatTypes {
+		if string(ft.format) == strings.ToLower(s) {
+			return ft.format, true
+		}
+	}
+	return "", false
+}
+
+// ContentType returns the format's Content-Type header value
+func (f Format) ContentType() string {
+	for _, ft := range formatTypes {
+		if ft.format == f {
+			if f == FormatPDF {
+				return ft.mediaType
+			}
+			return ft.mediaType + "; charset=utf-8"
+		}
+	}
+	return "application/octet-stream"
+}
+
+// Negotiate picks the format best matching an Accept header. An empty header
+// or a wildcard selects PDF; ok is false when no format is acceptable.
+func Negotiate(accept string) (format Format, ok bool) {
+	if strings.TrimSpace(accept) == "" {
+		return FormatPDF, true
+	}
+
+	bestQ := 0.0
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, q := parseAcceptPart(part)
+		for _, ft := range formatTypes {
+			if q > bestQ && mediaTypeMatches(mediaType, ft.mediaType) {
+				format, bestQ, ok = ft.format, q, true
+				break
+			}
+		}
+	}
+	return format, ok
+}
+
+// parseAcceptPart splits one Accept entry into its media type and q value
+func parseAcceptPart(part string) (string, float64) {
+	params := strings.Split(part, ";")
+	mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+	q := 1.0
+	for _, param := range params[1:] {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if strings.EqualFold(key, "q") {
+			if v, err := strconv.ParseFloat(value, 64); err == nil {
+				q = v
+			}
+		}
+	}
+	return mediaType, q
+}
+
+// mediaTypeMatches reports whether an Accept media range covers mediaType
+func mediaTypeMatches(accepted, mediaType string) bool {
+	if accepted == "*/*" || accepted == mediaType {
+		return true
+	}
+	group, _, _ := strings.Cut(mediaType, "/")
+	return accepted == group+"/*"
+}
+
+// Render produces the receipt of kind for txn in format
+func (g *Generator) Render(txn *payments.Transaction, kind Kind, format Format) ([]byte, error) {
+	if format == FormatPDF {
+		if kind == KindRefund {
+			return g.GenerateRefundPDF(txn)
+		}
+		return g.GeneratePDF(txn)
+	}
+
+	receipt, err := g.NewReceipt(txn, kind)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return json.MarshalIndent(receipt, "", "  ")
+	case FormatHTML:
+		return receipt.HTML()
+	case FormatCSV:
+		return receipt.CSV()
+	}
+	return nil, fmt.Errorf("unknown receipt format %q", format)
+}
+
+// CSV renders the receipt as line items for reconciliation:
+// record,transaction_id,description,rate,amount,currency
+func (r *Receipt) CSV() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	money := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	row := func(record, description, rate, amount, currency string) {
+		w.Write([]string{record, r.TransactionID, description, rate, amount, currency})
+	}
+
+	w.Write([]string{"record", "transaction_id", "description", "rate", "amount", "currency"})
+	row("amount", "Original Amount", "", money(r.Amount), r.Currency)
+	for _, fee := range r.Fees {
+		row("fee", fee.Description, fee.Rate, money(fee.Amount), r.Currency)
+	}
+	for _, hop := range r.HopFees {
+		row("hop_fee", hop.From+" -> "+hop.To, strconv.FormatFloat(hop.Rate*100, 'g', 4, 64)+"%",
+			strconv.FormatFloat(hop.Fee, 'f', 4, 64), r.Currency)
+	}
+	row("total_fees", "Total Fees", "", money(r.TotalFees), r.Currency)
+	row("fx_rate", r.Currency+" -> "+r.TargetCurrency, strconv.FormatFloat(r.FXRate, 'f', 6, 64), "", "")
+	row("received", "Amount Received", "", money(r.FinalAmount), r.TargetCurrency)
+	if r.Refund != nil {
+		row("refund", valueOr(r.Refund.RefundID, "not refunded"), "", money(r.Refund.Amount), r.Currency)
+		if r.Refund.Retained > 0 {
+			row("refund_retained", "Fees Retained ("+string(r.Refund.Policy)+")", "", money(r.Refund.Retained), r.Currency)
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// HTML renders the receipt as a self-contained page with inline styles,
+// suitable for embedding in email
+func (r *Receipt) HTML() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := receiptTemplate.Execute(&buf, r); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var receiptTemplate = template.Must(template.New("receipt").Funcs(template.FuncMap{
+	"money": func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) },
+	"route": func(codes []string) string { return strings.Join(codes, " → ") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Company}} receipt {{.TransactionID}}</title></head>
+<body style="font-family:Helvetica,Arial,sans-serif;color:#0f172a;max-width:640px;margin:0 auto;padding:24px">
+  <h1 style="color:#10b981;text-align:center;margin-bottom:0">{{.Company}}</h1>
+  <p style="color:#64748b;text-align:center;margin-top:4px">{{if .Refund}}Refund Receipt{{else}}Transaction Receipt{{end}}</p>
+  <p style="text-align:center;font-weight:bold">{{if .Refund}}{{if .Refund.RefundID}}PAYMENT REFUNDED{{else}}PAYMENT FAILED - NO REFUND ISSUED{{end}}{{else}}PAYMENT {{.Status}}{{end}}</p>
+  <table style="width:100%;border-collapse:collapse;background:#f8fafc" cellpadding="6">
+    <tr><th align="left">Transaction ID</th><td>{{.TransactionID}}</td></tr>
+    <tr><th align="left">Date</th><td>{{.CreatedAt.Format "January 2, 2006 at 3:04 PM"}}</td></tr>
+    <tr><th align="left">Payment Method</th><td>{{.PaymentMethod}}</td></tr>
+    <tr><th align="left">Route</th><td>{{route .Route}}</td></tr>
+  </table>
+  <h2>Payment Summary</h2>
+  <table style="width:100%;border-collapse:collapse" border="1" cellpadding="6">
+    <tr><td>Original Amount</td><td align="right">{{money .Amount}} {{.Currency}}</td></tr>
+    {{range .Fees}}<tr><td>{{.Description}} ({{.Rate}})</td><td align="right" style="color:#ef4444">-{{money .Amount}}</td></tr>
+    {{end}}<tr style="background:#10b981;color:#fff;font-weight:bold"><td>Amount Received</td><td align="right">{{money .FinalAmount}} {{.TargetCurrency}}</td></tr>
+  </table>
+  {{if .HopFees}}<h2>Hop Fee Breakdown</h2>
+  <table style="width:100%;border-collapse:collapse" border="1" cellpadding="4">
+    <tr><th>Hop</th><th>Multiplier</th><th>Fee</th></tr>
+    {{range .HopFees}}<tr><td>{{.From}} → {{.To}}</td><td align="center">{{printf "%.2f" .Multiplier}}×</td><td align="right">{{printf "%.4f" .Fee}}</td></tr>
+    {{end}}</table>{{end}}
+  {{if .Hops}}<h2>Route Details</h2>
+  <table style="width:100%;border-collapse:collapse" border="1" cellpadding="4">
+    <tr><th>From</th><th>To</th><th>Status</th><th>FX Rate</th><th>Amount In</th><th>Amount Out</th></tr>
+    {{range .Hops}}<tr><td>{{.From}}</td><td>{{.To}}</td><td>{{if .Success}}OK{{else}}FAILED{{end}}</td><td align="right">{{printf "%.4f" .FXRate}}</td><td align="right">{{money .AmountIn}}</td><td align="right">{{money .AmountOut}}</td></tr>
+    {{end}}</table>{{end}}
+  {{with .Refund}}<h2>Refund</h2>
+  <table style="width:100%;border-collapse:collapse" border="1" cellpadding="6">
+    <tr><th align="left">Stripe Refund ID</th><td>{{or .RefundID "-"}}</td></tr>
+    <tr><th align="left">Refund Amount</th><td>{{money .Amount}}</td></tr>
+    {{if .Retained}}<tr><th align="left">Fees Retained</th><td>{{money .Retained}} ({{.Policy}})</td></tr>{{end}}
+    <tr><th align="left">Failed At</th><td>{{or .FailedAt "-"}}</td></tr>
+    <tr><th align="left">Reason</th><td>{{or .Reason "-"}}</td></tr>
+  </table>
+  {{if .Attempts}}<table style="width:100%;border-collapse:collapse;margin-top:8px" border="1" cellpadding="4">
+    <tr><th>#</th><th>Route</th><th>Status</th><th>Error</th></tr>
+    {{range .Attempts}}<tr><td>{{.Attempt}}</td><td>{{route .Route}}</td><td>{{.Status}}</td><td>{{or .Error "-"}}</td></tr>
+    {{end}}</table>{{end}}{{end}}
+  <p style="font-size:11px;color:#64748b;margin-top:24px">Verification code: {{.VerificationCode}}<br>Signature: <code>{{.Signature}}</code></p>
+</body>
+</html>
+`))
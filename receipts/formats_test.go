@@ -0,0 +1,84 @@
+package receipts
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		accept string
+		want   Format
+		ok     bool
+	}{
+		{"", FormatPDF, true},
+		{"*/*", FormatPDF, true},
+		{"application/json", FormatJSON, true},
+		{"text/*", FormatHTML, true},
+		{"text/csv;q=0.9, application/json;q=0.5", FormatCSV, true},
+		{"text/html,application/xhtml+xml,*/*;q=0.8", FormatHTML, true},
+		{"image/png", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := Negotiate(tt.accept)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Negotiate(%q) = %q, %v; want %q, %v", tt.accept, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRenderFormats(t *testing.T) {
+	g := NewGenerator("Test")
+	txn := &payments.Transaction{
+		ID:             "txn_1",
+		Amount:         100,
+		Currency:       "USD",
+		TargetCurrency: "GBP",
+		Route:          []string{"USA", "GBR"},
+		Status:         payments.StatusSuccess,
+		BaseFee:        1.5,
+		HopFees:        0.5,
+		TotalFees:      2,
+		FinalAmount:    77.4,
+		CreatedAt:      time.Now(),
+	}
+
+	body, err := g.Render(txn, KindPayment, FormatJSON)
+	if err != nil {
+		t.Fatalf("Render json: %v", err)
+	}
+	var receipt Receipt
+	if err := json.Unmarshal(body, &receipt); err != nil {
+		t.Fatalf("json receipt: %v", err)
+	}
+	if receipt.TransactionID != "txn_1" || len(receipt.Fees) != 2 || receipt.Refund != nil {
+		t.Errorf("json receipt = %+v", receipt)
+	}
+
+	body, err = g.Render(txn, KindPayment, FormatCSV)
+	if err != nil {
+		t.Fatalf("Render csv: %v", err)
+	}
+	rows, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	if err != nil {
+		t.Fatalf("csv receipt: %v", err)
+	}
+	if last := rows[len(rows)-1]; last[0] != "received" || last[4] != "77.40" || last[5] != "GBP" {
+		t.Errorf("last csv row = %v, want amount received", last)
+	}
+
+	body, err = g.Render(txn, KindPayment, FormatHTML)
+	if err != nil || !strings.Contains(string(body), "txn_1") {
+		t.Errorf("Render html = %v; body contains transaction ID: %v", err, strings.Contains(string(body), "txn_1"))
+	}
+
+	if _, err := g.Render(txn, KindRefund, FormatJSON); err != ErrNotRefundable {
+		t.Errorf("refund of successful transaction: err = %v, want ErrNotRefundable", err)
+	}
+}
@@ -51,19 +51,7 @@ func NewGenerator(companyName string) *Generator {
 
 // GeneratePDF generates a PDF receipt for a transaction
 func (g *Generator) GeneratePDF(txn *payments.Transaction) ([]byte, error) {
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-
-	// Header
-	pdf.SetFont("Helvetica", "B", 24)
-	pdf.SetTextColor(16, 185, 129) // Emerald color
-	pdf.CellFormat(190, 15, g.companyName, "", 1, "C", false, 0, "")
-
-	pdf.SetFont("Helvetica", "", 12)
-	pdf.SetTextColor(100, 100, 100)
-	pdf.CellFormat(190, 8, "Transaction Receipt", "", 1, "C", false, 0, "")
-
-	pdf.Ln(10)
+	pdf := g.newDocument("Transaction Receipt")
 
 	// Status badge
 	pdf.SetFont("Helvetica", "B", 14)
@@ -148,18 +136,22 @@ func (g *Generator) GeneratePDF(txn *payments.Transaction) ([]byte, error) {
 	pdf.CellFormat(120, 8, "Original Amount", "1", 0, "L", false, 0, "")
 	pdf.CellFormat(70, 8, fmt.Sprintf("$%.2f %s", txn.Amount, txn.Currency), "1", 1, "R", false, 0, "")
 
-	pdf.CellFormat(120, 8, "Platform Fee (1.5%)", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(120, 8, fmt.Sprintf("Platform Fee (%s)", percent(txn.BaseFee, txn.Amount)), "1", 0, "L", false, 0, "")
 	pdf.SetTextColor(239, 68, 68)
 	pdf.CellFormat(70, 8, fmt.Sprintf("-$%.2f", txn.BaseFee), "1", 1, "R", false, 0, "")
 	pdf.SetTextColor(0, 0, 0)
 
-	pdf.CellFormat(120, 8, fmt.Sprintf("Hop Fees (0.02%% × %d hops)", len(txn.Route)-1), "1", 0, "L", false, 0, "")
+	hopLabel := fmt.Sprintf("Hop Fees (%s × %d hops)", percent(txn.HopFees/float64(len(txn.Route)-1), txn.Amount), len(txn.Route)-1)
+	if len(txn.HopFeeBreakdown) > 0 {
+		hopLabel = fmt.Sprintf("Hop Fees (%d hops, priced per destination)", len(txn.HopFeeBreakdown))
+	}
+	pdf.CellFormat(120, 8, hopLabel, "1", 0, "L", false, 0, "")
 	pdf.SetTextColor(239, 68, 68)
 	pdf.CellFormat(70, 8, fmt.Sprintf("-$%.2f", txn.HopFees), "1", 1, "R", false, 0, "")
 	pdf.SetTextColor(0, 0, 0)
 
 	if txn.HaltFines > 0 {
-		pdf.CellFormat(120, 8, "Halt Fines (0.1%)", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(120, 8, fmt.Sprintf("Halt Fines (%s)", percent(txn.HaltFines, txn.Amount)), "1", 0, "L", false, 0, "")
 		pdf.SetTextColor(239, 68, 68)
 		pdf.CellFormat(70, 8, fmt.Sprintf("-$%.2f", txn.HaltFines), "1", 1, "R", false, 0, "")
 		pdf.SetTextColor(0, 0, 0)
@@ -170,11 +162,62 @@ func (g *Generator) GeneratePDF(txn *payments.Transaction) ([]byte, error) {
 	pdf.SetFillColor(16, 185, 129)
 	pdf.SetTextColor(255, 255, 255)
 	pdf.CellFormat(120, 10, "Amount Received", "1", 0, "L", true, 0, "")
-	pdf.CellFormat(70, 10, fmt.Sprintf("$%.2f %s", txn.FinalAmount, txn.TargetCurrency), "1", 1, "R", true, 0, "")
+	pdf.CellFormat(70, 10, fmt.Sprintf("%.2f %s", txn.FinalAmount, txn.TargetCurrency), "1", 1, "R", true, 0, "")
 
 	pdf.SetTextColor(0, 0, 0)
 	pdf.Ln(10)
 
+	// Dynamic hop fees: each hop is priced from its destination's credibility and success rate
+	if len(txn.HopFeeBreakdown) > 0 {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(190, 10, "Hop Fee Breakdown", "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(229, 231, 235)
+		pdf.CellFormat(35, 7, "Hop", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(30, 7, "Credibility", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(30, 7, "Success Rate", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(30, 7, "Multiplier", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(30, 7, "Rate", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(35, 7, "Fee", "1", 1, "C", true, 0, "")
+
+		pdf.SetFont("Helvetica", "", 9)
+		for _, hop := range txn.HopFeeBreakdown {
+			pdf.CellFormat(35, 7, hop.From+" -> "+hop.To, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(30, 7, fmt.Sprintf("%.2f", hop.Credibility), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(30, 7, fmt.Sprintf("%.1f%%", hop.SuccessRate*100), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(30, 7, fmt.Sprintf("%.2fx", hop.Multiplier), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(30, 7, fmt.Sprintf("%.4f%%", hop.Rate*100), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(35, 7, fmt.Sprintf("$%.4f", hop.Fee), "1", 1, "R", false, 0, "")
+		}
+		pdf.Ln(6)
+	}
+
+	// Conversion summary (only when funds changed currency along the route)
+	if rate := txn.EffectiveFXRate(); txn.Status == payments.StatusSuccess && (rate != 1.0 || txn.Currency != txn.TargetCurrency) {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(190, 10, "Currency Conversion", "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(120, 8, "Amount Converted (after fees)", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(70, 8, fmt.Sprintf("%.2f %s", txn.Amount-txn.TotalFees, txn.Currency), "1", 1, "R", false, 0, "")
+		pdf.CellFormat(120, 8, "Effective Exchange Rate", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(70, 8, fmt.Sprintf("1 %s = %.4f %s", txn.Currency, rate, txn.TargetCurrency), "1", 1, "R", false, 0, "")
+		pdf.CellFormat(120, 8, "Amount Received", "1", 0, "L", false, 0, "")
+		pdf.CellFormat(70, 8, fmt.Sprintf("%.2f %s", txn.FinalAmount, txn.TargetCurrency), "1", 1, "R", false, 0, "")
+
+		for _, hop := range txn.HopResults {
+			if hop.FXFallback {
+				pdf.SetFont("Helvetica", "I", 8)
+				pdf.SetTextColor(128, 128, 128)
+				pdf.CellFormat(190, 6, "Some hops had no exchange rate available and settled without conversion.", "", 1, "L", false, 0, "")
+				pdf.SetTextColor(0, 0, 0)
+				break
+			}
+		}
+		pdf.Ln(6)
+	}
+
 	// Hop Details (if available)
 	if len(txn.HopResults) > 0 {
 		pdf.SetFont("Helvetica", "B", 14)
@@ -182,35 +225,60 @@ func (g *Generator) GeneratePDF(txn *payments.Transaction) ([]byte, error) {
 
 		pdf.SetFont("Helvetica", "B", 9)
 		pdf.SetFillColor(229, 231, 235)
-		pdf.CellFormat(30, 7, "From", "1", 0, "C", true, 0, "")
-		pdf.CellFormat(30, 7, "To", "1", 0, "C", true, 0, "")
-		pdf.CellFormat(25, 7, "Status", "1", 0, "C", true, 0, "")
-		pdf.CellFormat(30, 7, "Latency", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(25, 7, "From", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(25, 7, "To", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(20, 7, "Status", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(25, 7, "Latency", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(25, 7, "FX Rate", "1", 0, "C", true, 0, "")
 		pdf.CellFormat(35, 7, "Amount In", "1", 0, "C", true, 0, "")
 		pdf.CellFormat(35, 7, "Amount Out", "1", 1, "C", true, 0, "")
 
 		pdf.SetFont("Helvetica", "", 9)
 		for _, hop := range txn.HopResults {
-			pdf.CellFormat(30, 7, hop.FromCountry, "1", 0, "C", false, 0, "")
-			pdf.CellFormat(30, 7, hop.ToCountry, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(25, 7, hop.FromCountry, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(25, 7, hop.ToCountry, "1", 0, "C", false, 0, "")
 			
 			if hop.Success {
 				pdf.SetTextColor(16, 185, 129)
-				pdf.CellFormat(25, 7, "OK", "1", 0, "C", false, 0, "")
+				pdf.CellFormat(20, 7, "OK", "1", 0, "C", false, 0, "")
 			} else {
 				pdf.SetTextColor(239, 68, 68)
-				pdf.CellFormat(25, 7, "FAILED", "1", 0, "C", false, 0, "")
+				pdf.CellFormat(20, 7, "FAILED", "1", 0, "C", false, 0, "")
 			}
 			pdf.SetTextColor(0, 0, 0)
 			
-			pdf.CellFormat(30, 7, fmt.Sprintf("%dms", hop.Latency), "1", 0, "C", false, 0, "")
-			pdf.CellFormat(35, 7, fmt.Sprintf("$%.2f", hop.AmountIn), "1", 0, "C", false, 0, "")
-			pdf.CellFormat(35, 7, fmt.Sprintf("$%.2f", hop.AmountOut), "1", 1, "C", false, 0, "")
+			pdf.CellFormat(25, 7, fmt.Sprintf("%dms", hop.Latency), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(25, 7, fmt.Sprintf("%.4f", hop.FXRate), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(35, 7, fmt.Sprintf("%.2f", hop.AmountIn), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(35, 7, fmt.Sprintf("%.2f", hop.AmountOut), "1", 1, "C", false, 0, "")
 		}
 	}
 
 	pdf.Ln(10)
 
+	return finishDocument(pdf, txn)
+}
+
+// newDocument starts a receipt page with the company header and title
+func (g *Generator) newDocument(title string) *gofpdf.Fpdf {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	// Header
+	pdf.SetFont("Helvetica", "B", 24)
+	pdf.SetTextColor(16, 185, 129) // Emerald color
+	pdf.CellFormat(190, 15, g.companyName, "", 1, "C", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(190, 8, title, "", 1, "C", false, 0, "")
+
+	pdf.Ln(10)
+	return pdf
+}
+
+// finishDocument adds the footer and digital signature and renders the PDF
+func finishDocument(pdf *gofpdf.Fpdf, txn *payments.Transaction) ([]byte, error) {
 	// Footer
 	pdf.SetFont("Helvetica", "I", 9)
 	pdf.SetTextColor(128, 128, 128)
@@ -255,6 +323,14 @@ func (g *Generator) GeneratePDF(txn *payments.Transaction) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// percent formats part as a percentage of whole (e.g. "1.5%")
+func percent(part, whole float64) string {
+	if whole == 0 {
+		return "0%"
+	}
+	return fmt.Sprintf("%.4g%%", part/whole*100)
+}
+
 // generateDigitalSignature creates an HMAC-SHA256 signature for anonymous verification
 // This proves ownership without revealing the user ID to others
 func generateDigitalSignature(txn *payments.Transaction) string {
@@ -272,6 +348,11 @@ func generateDigitalSignature(txn *payments.Transaction) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// Sign returns the receipt signature of a transaction, as printed on its PDF receipt
+func Sign(txn *payments.Transaction) string {
+	return generateDigitalSignature(txn)
+}
+
 // generateVerificationCode creates a short code for quick verification
 func generateVerificationCode(txn *payments.Transaction) string {
 	data := fmt.Sprintf("%s|%s", txn.ID, txn.CreatedAt.Format("20060102150405"))
@@ -0,0 +1,140 @@
+package receipts
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// Kind selects the receipt variant
+type Kind string
+
+// Receipt kinds
+const (
+	KindPayment Kind = "payment"
+	KindRefund  Kind = "refund" // Failed or refunded transactions only
+)
+
+// Receipt is the data shown on a receipt, shared by every output format
+type Receipt struct {
+	Kind             Kind                       `json:"type"`
+	Company          string                     `json:"company"`
+	TransactionID    string                     `json:"transaction_id"`
+	Status           payments.TransactionStatus `json:"status"`
+	CreatedAt        time.Time                  `json:"created_at"`
+	CompletedAt      *time.Time                 `json:"completed_at,omitempty"`
+	PaymentMethod    string                     `json:"payment_method"`
+	Route            []string                   `json:"route"`
+	Amount           float64                    `json:"amount"`
+	Currency         string                     `json:"currency"`
+	TargetCurrency   string                     `json:"target_currency"`
+	Fees             []FeeLine                  `json:"fees"`
+	TotalFees        float64                    `json:"total_fees"`
+	FinalAmount      float64                    `json:"final_amount"` // In the target currency
+	FXRate           float64                    `json:"fx_rate"`      // Effective source → target rate
+	HopFees          []payments.HopFee          `json:"hop_fee_breakdown,omitempty"`
+	Hops             []Hop                      `json:"hops,omitempty"`
+	Refund           *Refund                    `json:"refund,omitempty"`
+	Signature        string                     `json:"signature"`
+	VerificationCode string                     `json:"verification_code"`
+	GeneratedAt      time.Time                  `json:"generated_at"`
+}
+
+// FeeLine is one fee charged on a receipt
+type FeeLine struct {
+	Description string  `json:"description"`
+	Rate        string  `json:"rate"` // Percentage of the amount (e.g. "1.5%")
+	Amount      float64 `json:"amount"`
+}
+
+// Hop is one settled hop of the route
+type Hop struct {
+	From      string  `json:"from"`
+	To        string  `json:"to"`
+	Success   bool    `json:"success"`
+	LatencyMS int64   `json:"latency_ms"`
+	FXRate    float64 `json:"fx_rate"`
+	AmountIn  float64 `json:"amount_in"`
+	AmountOut float64 `json:"amount_out"`
+	HopFee    float64 `json:"hop_fee"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Refund describes the refund of a failed or disputed transaction
+type Refund struct {
+	RefundID string                  `json:"refund_id,omitempty"` // Empty when no refund was issued
+	Amount   float64                 `json:"amount"`
+	Retained float64                 `json:"retained,omitempty"`  // Fees kept under the refund policy, e.g. when the payer cancelled
+	Policy   payments.RefundReason   `json:"policy,omitempty"`    // Why it was refunded, which decides what was kept
+	FailedAt string                  `json:"failed_at,omitempty"` // Hop ("USA -> GBR") or country the payment failed at
+	Reason   string                  `json:"reason,omitempty"`
+	Attempts []payments.RouteAttempt `json:"attempts,omitempty"`
+}
+
+// NewReceipt builds the receipt of kind for txn
+func (g *Generator) NewReceipt(txn *payments.Transaction, kind Kind) (*Receipt, error) {
+	if kind == KindRefund && txn.Status != payments.StatusFailed && txn.Refund == nil {
+		return nil, ErrNotRefundable
+	}
+
+	r := &Receipt{
+		Kind:             kind,
+		Company:          g.companyName,
+		TransactionID:    txn.ID,
+		Status:           txn.Status,
+		CreatedAt:        txn.CreatedAt,
+		CompletedAt:      txn.CompletedAt,
+		PaymentMethod:    fmt.Sprintf("Card ending in %s", txn.CardLast4),
+		Route:            txn.Route,
+		Amount:           txn.Amount,
+		Currency:         txn.Currency,
+		TargetCurrency:   txn.TargetCurrency,
+		TotalFees:        txn.TotalFees,
+		FinalAmount:      txn.FinalAmount,
+		FXRate:           txn.EffectiveFXRate(),
+		HopFees:          txn.HopFeeBreakdown,
+		Signature:        generateDigitalSignature(txn),
+		VerificationCode: generateVerificationCode(txn),
+		GeneratedAt:      time.Now().UTC(),
+	}
+
+	r.Fees = []FeeLine{
+		{Description: "Platform Fee", Rate: percent(txn.BaseFee, txn.Amount), Amount: txn.BaseFee},
+		{Description: fmt.Sprintf("Hop Fees (%d hops)", len(txn.Route)-1), Rate: percent(txn.HopFees, txn.Amount), Amount: txn.HopFees},
+	}
+	if txn.HaltFines > 0 {
+		r.Fees = append(r.Fees, FeeLine{Description: "Halt Fines", Rate: percent(txn.HaltFines, txn.Amount), Amount: txn.HaltFines})
+	}
+
+	for _, hop := range txn.HopResults {
+		r.Hops = append(r.Hops, Hop{
+			From:      hop.FromCountry,
+			To:        hop.ToCountry,
+			Success:   hop.Success,
+			LatencyMS: hop.Latency,
+			FXRate:    hop.FXRate,
+			AmountIn:  hop.AmountIn,
+			AmountOut: hop.AmountOut,
+			HopFee:    hop.HopFee,
+			Error:     hop.Error,
+		})
+	}
+
+	if kind == KindRefund {
+		refund := &Refund{RefundID: txn.RefundID(), FailedAt: txn.FailedAt, Attempts: txn.Attempts}
+		switch {
+		case txn.Refund != nil:
+			refund.Amount, refund.Retained, refund.Policy = txn.Refund.Amount, txn.Refund.Retained, txn.Refund.Reason
+			refund.RefundID = txn.Refund.ID // Disputed payments keep their payment method
+		case refund.RefundID != "":
+			refund.Amount = txn.Amount // Refunded before breakdowns were recorded: in full
+		}
+		if hop := failedHop(txn); hop != nil {
+			refund.FailedAt = hop.FromCountry + " -> " + hop.ToCountry
+			refund.Reason = hop.Error
+		}
+		r.Refund = refund
+	}
+	return r, nil
+}
@@ -0,0 +1,116 @@
+package receipts
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// ErrNotRefundable is returned for refund receipts of transactions that have not failed
+var ErrNotRefundable = errors.New("refund receipts are only available for failed or refunded transactions")
+
+// GenerateRefundPDF generates a refund receipt for a failed or disputed
+// transaction: the Stripe refund, the hop the payment failed at and every
+// routing attempt
+func (g *Generator) GenerateRefundPDF(txn *payments.Transaction) ([]byte, error) {
+	receipt, err := g.NewReceipt(txn, KindRefund)
+	if err != nil {
+		return nil, err
+	}
+	refund := receipt.Refund
+
+	pdf := g.newDocument("Refund Receipt")
+
+	// Status badge
+	pdf.SetFont("Helvetica", "B", 14)
+	if refund.RefundID != "" {
+		pdf.SetTextColor(59, 130, 246)
+		pdf.CellFormat(190, 10, "PAYMENT REFUNDED", "", 1, "C", false, 0, "")
+	} else {
+		pdf.SetTextColor(239, 68, 68)
+		pdf.CellFormat(190, 10, "PAYMENT FAILED - NO REFUND ISSUED", "", 1, "C", false, 0, "")
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(6)
+
+	// Refund summary
+	completed := "-"
+	if receipt.CompletedAt != nil {
+		completed = receipt.CompletedAt.Format("January 2, 2006 at 3:04 PM")
+	}
+	completedLabel := "Failed"
+	if receipt.Status == payments.StatusSuccess {
+		completedLabel = "Settled" // Refunded after a dispute
+	}
+	rows := [][2]string{
+		{"Transaction ID", receipt.TransactionID},
+		{"Date", receipt.CreatedAt.Format("January 2, 2006 at 3:04 PM")},
+		{completedLabel, completed},
+		{"Stripe Refund ID", valueOr(refund.RefundID, "-")},
+		{"Original Amount", fmt.Sprintf("%.2f %s", receipt.Amount, receipt.Currency)},
+		{"Refund Amount", fmt.Sprintf("%.2f %s", refund.Amount, receipt.Currency)},
+	}
+	if refund.Retained > 0 {
+		rows = append(rows, [2]string{"Fees Retained", fmt.Sprintf("%.2f %s (%s)", refund.Retained, receipt.Currency, refund.Policy)})
+	}
+	rows = append(rows, [2]string{"Failed At", valueOr(refund.FailedAt, "-")})
+	if refund.Reason != "" {
+		rows = append(rows, [2]string{"Failure Reason", refund.Reason})
+	}
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(190, 10, "Refund Summary", "", 1, "L", false, 0, "")
+	for _, row := range rows {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(60, 8, row[0], "1", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(130, 8, row[1], "1", 1, "L", false, 0, "")
+	}
+	pdf.Ln(6)
+
+	// Retry attempts
+	if len(refund.Attempts) > 0 {
+		pdf.SetFont("Helvetica", "B", 14)
+		pdf.CellFormat(190, 10, fmt.Sprintf("Routing Attempts (%d)", len(refund.Attempts)), "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(229, 231, 235)
+		pdf.CellFormat(15, 7, "#", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(70, 7, "Route", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(25, 7, "Status", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(25, 7, "Failed At", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(55, 7, "Error", "1", 1, "C", true, 0, "")
+
+		pdf.SetFont("Helvetica", "", 9)
+		for _, attempt := range refund.Attempts {
+			pdf.CellFormat(15, 7, fmt.Sprintf("%d", attempt.Attempt), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(70, 7, strings.Join(attempt.Route, " -> "), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(25, 7, strings.ToUpper(string(attempt.Status)), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(25, 7, valueOr(attempt.FailedAt, "-"), "1", 0, "C", false, 0, "")
+			pdf.CellFormat(55, 7, valueOr(attempt.Error, "-"), "1", 1, "C", false, 0, "")
+		}
+		pdf.Ln(6)
+	}
+
+	return finishDocument(pdf, txn)
+}
+
+// failedHop returns the hop the transaction's last attempt failed at, if any
+func failedHop(txn *payments.Transaction) *payments.HopResult {
+	for i := len(txn.HopResults) - 1; i >= 0; i-- {
+		if !txn.HopResults[i].Success {
+			return &txn.HopResults[i]
+		}
+	}
+	return nil
+}
+
+// valueOr returns v, or fallback when v is empty
+func valueOr(v, fallback string) string {
+	if v == "" {
+		return fallback
+	}
+	return v
+}
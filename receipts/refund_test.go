@@ -0,0 +1,70 @@
+package receipts
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func TestGenerateRefundPDF(t *testing.T) {
+	g := NewGenerator("Test")
+	txn := &payments.Transaction{
+		ID:            "txn_1",
+		Amount:        100,
+		Currency:      "USD",
+		Route:         []string{"USA", "GBR"},
+		Status:        payments.StatusSuccess,
+		CreatedAt:     time.Now(),
+		PaymentMethod: "mock_card",
+	}
+	if _, err := g.GenerateRefundPDF(txn); !errors.Is(err, ErrNotRefundable) {
+		t.Fatalf("successful transaction: err = %v, want ErrNotRefundable", err)
+	}
+
+	txn.Status = payments.StatusFailed
+	txn.PaymentMethod = "refunded:re_123"
+	txn.HopResults = []payments.HopResult{{FromCountry: "USA", ToCountry: "GBR", Error: "node timeout"}}
+	txn.Attempts = []payments.RouteAttempt{{Attempt: 1, Route: txn.Route, Status: payments.StatusFailed, FailedAt: "GBR", Error: "node timeout"}}
+	if txn.RefundID() != "re_123" {
+		t.Errorf("RefundID = %q, want re_123", txn.RefundID())
+	}
+	if hop := failedHop(txn); hop == nil || hop.ToCountry != "GBR" {
+		t.Errorf("failed hop = %+v, want USA -> GBR", hop)
+	}
+
+	pdf, err := g.GenerateRefundPDF(txn)
+	if err != nil {
+		t.Fatalf("GenerateRefundPDF: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF")) {
+		t.Error("refund receipt is not a PDF")
+	}
+}
+
+func TestDisputedPaymentHasRefundReceipt(t *testing.T) {
+	g := NewGenerator("Test")
+	txn := &payments.Transaction{
+		ID:            "txn_2",
+		Amount:        250,
+		Currency:      "USD",
+		Route:         []string{"USA", "GBR"},
+		Status:        payments.StatusSuccess,
+		CreatedAt:     time.Now(),
+		PaymentMethod: "mock_card",
+		Refund:        &payments.Refund{ID: "re_456", Reason: payments.RefundDisputed, Amount: 250, Currency: "USD"},
+	}
+
+	receipt, err := g.NewReceipt(txn, KindRefund)
+	if err != nil {
+		t.Fatalf("NewReceipt: %v", err)
+	}
+	if receipt.Refund.RefundID != "re_456" || receipt.Refund.Amount != 250 || receipt.Refund.Policy != payments.RefundDisputed {
+		t.Errorf("refund = %+v, want re_456 returning 250 for the dispute", receipt.Refund)
+	}
+	if _, err := g.GenerateRefundPDF(txn); err != nil {
+		t.Errorf("GenerateRefundPDF: %v", err)
+	}
+}
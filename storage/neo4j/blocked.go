@@ -0,0 +1,90 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	neo4jdriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+// blockedCountriesPolicy names the RoutingPolicy node holding the block list.
+// Blocked countries need not be Country nodes, so the list is kept apart from them.
+const blockedCountriesPolicy = "blocked_countries"
+
+// LoadBlockedCountries returns the persisted block list, sorted. Empty if
+// none was saved.
+func LoadBlockedCountries(ctx context.Context, driver neo4jdriver.DriverWithContext, database string) ([]string, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "load_blocked_countries")
+
+	session := driver.NewSession(ctx, neo4jdriver.SessionConfig{
+		DatabaseName: database,
+		AccessMode:   neo4jdriver.AccessModeRead,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (p:RoutingPolicy {name: $name})
+		RETURN p.codes AS codes
+	`, map[string]interface{}{"name": blockedCountriesPolicy})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blocked countries: %w", err)
+	}
+	if !result.Next(ctx) {
+		if err := result.Err(); err != nil {
+			return nil, fmt.Errorf("failed to load blocked countries: %w", err)
+		}
+		return []string{}, nil
+	}
+	codes, _ := result.Record().Get("codes")
+	return codeList(codes), nil
+}
+
+// SaveBlockedCountries replaces the persisted block list and returns the
+// previous one
+func SaveBlockedCountries(ctx context.Context, driver neo4jdriver.DriverWithContext, database string, codes []string, changedBy string) ([]string, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "save_blocked_countries")
+
+	session := driver.NewSession(ctx, neo4jdriver.SessionConfig{
+		DatabaseName: database,
+		AccessMode:   neo4jdriver.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MERGE (p:RoutingPolicy {name: $name})
+		WITH p, coalesce(p.codes, []) AS previous
+		SET p.codes = $codes, p.updated_at = datetime(), p.updated_by = $changedBy
+		RETURN previous
+	`, map[string]interface{}{
+		"name":      blockedCountriesPolicy,
+		"codes":     codes,
+		"changedBy": changedBy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to save blocked countries: %w", err)
+	}
+	if !result.Next(ctx) {
+		if err := result.Err(); err != nil {
+			return nil, fmt.Errorf("failed to save blocked countries: %w", err)
+		}
+		return []string{}, nil
+	}
+	previous, _ := result.Record().Get("previous")
+	return codeList(previous), nil
+}
+
+// codeList converts a Neo4j list of strings, sorted
+func codeList(v interface{}) []string {
+	items, _ := v.([]interface{})
+	codes := make([]string, 0, len(items))
+	for _, item := range items {
+		if code, ok := item.(string); ok {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
@@ -4,13 +4,13 @@ package neo4j
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
 	"regexp"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
 )
 
 // Config holds Neo4j connection configuration
@@ -104,6 +104,11 @@ func (c *Client) Driver() neo4j.DriverWithContext {
 	return c.driver
 }
 
+// Database returns the name of the database the client uses
+func (c *Client) Database() string {
+	return c.database
+}
+
 // Node represents a mesh node
 type Node struct {
 	ID       string
@@ -134,14 +139,13 @@ type Path struct {
 	TotalLatency int64
 }
 
-// FindPaths finds paths between two nodes (for Yen's K-shortest paths algorithm input)
-func (c *Client) FindPaths(ctx context.Context, sourceID, targetID string, maxHops int) ([]Path, error) {
-	// Validate maxHops to prevent query manipulation (defense in depth)
-	if maxHops < 1 {
-		maxHops = 1
-	}
-	if maxHops > 10 {
-		maxHops = 10 // Cap at reasonable maximum to prevent DoS
+// FindPaths finds active paths between two nodes (for Yen's K-shortest paths algorithm input)
+func (c *Client) FindPaths(ctx context.Context, q PathQuery) ([]Path, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "find_paths")
+
+	query, err := q.Build()
+	if err != nil {
+		return nil, err
 	}
 
 	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
@@ -150,22 +154,7 @@ func (c *Client) FindPaths(ctx context.Context, sourceID, targetID string, maxHo
 	})
 	defer session.Close(ctx)
 
-	// Build query with validated integer (safe since we've bounds-checked maxHops)
-	query := fmt.Sprintf(`
-		MATCH path = (source {id: $sourceId})-[*1..%d]->(target {id: $targetId})
-		WHERE all(r IN relationships(path) WHERE r.is_active = true)
-		  AND all(n IN nodes(path) WHERE n.is_active = true)
-		RETURN path,
-		       reduce(fee = 0.0, r IN relationships(path) | fee + coalesce(r.base_fee, 0)) AS totalFee,
-		       reduce(lat = 0, r IN relationships(path) | lat + coalesce(r.latency, 0)) AS totalLatency
-		ORDER BY totalFee ASC, totalLatency ASC
-		LIMIT 10
-	`, maxHops)
-
-	result, err := session.Run(ctx, query, map[string]interface{}{
-		"sourceId": sourceID,
-		"targetId": targetID,
-	})
+	result, err := session.Run(ctx, query.Cypher, query.Params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute path query: %w", err)
 	}
@@ -220,6 +209,8 @@ func (c *Client) FindPaths(ctx context.Context, sourceID, targetID string, maxHo
 
 // GetNode retrieves a single node by ID
 func (c *Client) GetNode(ctx context.Context, nodeID string) (*Node, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "get_node")
+
 	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
 		DatabaseName: c.database,
 		AccessMode:   neo4j.AccessModeRead,
@@ -255,6 +246,8 @@ func (c *Client) GetNode(ctx context.Context, nodeID string) (*Node, error) {
 
 // UpdateEdge updates edge properties (for real-time mesh updates)
 func (c *Client) UpdateEdge(ctx context.Context, sourceID, targetID string, updates map[string]interface{}) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "update_edge")
+
 	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
 		DatabaseName: c.database,
 		AccessMode:   neo4j.AccessModeWrite,
@@ -276,8 +269,109 @@ func (c *Client) UpdateEdge(ctx context.Context, sourceID, targetID string, upda
 	return err
 }
 
+// CreateEdge creates a relationship of type relType between two mesh nodes,
+// or updates the properties of the existing one. Both nodes must exist.
+func (c *Client) CreateEdge(ctx context.Context, sourceID, targetID, relType string, props map[string]interface{}) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "create_edge")
+
+	rel, err := relTypeOf(relType)
+	if err != nil {
+		return err
+	}
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	// rel is allow-listed, safe to use in query
+	query := `
+		MATCH (a {id: $sourceId}) WHERE a:SME OR a:LiquidityProvider OR a:Hub
+		MATCH (b {id: $targetId}) WHERE b:SME OR b:LiquidityProvider OR b:Hub
+		MERGE (a)-[r:` + rel + `]->(b)
+		SET r += $props
+		RETURN count(r) AS edges
+	`
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"sourceId": sourceID,
+		"targetId": targetID,
+		"props":    storableProps(props),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create edge: %w", err)
+	}
+	record, err := result.Single(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create edge: %w", err)
+	}
+	if n, _ := record.Get("edges"); n == int64(0) {
+		return fmt.Errorf("%w: %s or %s", ErrNodeNotFound, sourceID, targetID)
+	}
+	return nil
+}
+
+// DeleteEdge deletes the relationships from sourceID to targetID, only those
+// of type relType unless it is empty. It returns ErrEdgeNotFound if there were none.
+func (c *Client) DeleteEdge(ctx context.Context, sourceID, targetID, relType string) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "delete_edge")
+
+	pattern := ""
+	if relType != "" {
+		rel, err := relTypeOf(relType)
+		if err != nil {
+			return err
+		}
+		pattern = ":" + rel
+	}
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (a {id: $sourceId})-[r` + pattern + `]->(b {id: $targetId})
+		WHERE (a:SME OR a:LiquidityProvider OR a:Hub) AND (b:SME OR b:LiquidityProvider OR b:Hub)
+		DELETE r
+		RETURN count(r) AS edges
+	`
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"sourceId": sourceID,
+		"targetId": targetID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete edge: %w", err)
+	}
+	record, err := result.Single(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete edge: %w", err)
+	}
+	if n, _ := record.Get("edges"); n == int64(0) {
+		return fmt.Errorf("%w: %s -> %s", ErrEdgeNotFound, sourceID, targetID)
+	}
+	return nil
+}
+
+// storableProps keeps the property values that are Neo4j primitives
+func storableProps(props map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(props))
+	for k, v := range props {
+		switch v := v.(type) {
+		case string, bool, int64, float64:
+			out[k] = v
+		case int:
+			out[k] = int64(v)
+		}
+	}
+	return out
+}
+
 // SetNodeActive updates the active status of a node (for circuit breaker integration)
 func (c *Client) SetNodeActive(ctx context.Context, nodeID string, isActive bool) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "set_node_active")
+
 	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
 		DatabaseName: c.database,
 		AccessMode:   neo4j.AccessModeWrite,
@@ -298,6 +392,39 @@ func (c *Client) SetNodeActive(ctx context.Context, nodeID string, isActive bool
 	return err
 }
 
+// UpdateNode sets properties of a mesh node; a nil value removes the property.
+// It returns ErrNodeNotFound if there is no such node.
+func (c *Client) UpdateNode(ctx context.Context, nodeID string, updates map[string]interface{}) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "update_node")
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	query := `
+		MATCH (n {id: $nodeId}) WHERE n:SME OR n:LiquidityProvider OR n:Hub
+		SET n += $updates
+		RETURN count(n) AS nodes
+	`
+	result, err := session.Run(ctx, query, map[string]interface{}{
+		"nodeId":  nodeID,
+		"updates": updates,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	record, err := result.Single(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to update node: %w", err)
+	}
+	if n, _ := record.Get("nodes"); n == int64(0) {
+		return fmt.Errorf("%w: %s", ErrNodeNotFound, nodeID)
+	}
+	return nil
+}
+
 // allowedNodeLabels defines the whitelist of valid node types for CreateNode
 var allowedNodeLabels = map[string]bool{
 	"Country":           true,
@@ -312,13 +439,12 @@ var validLabelPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
 
 // CreateNode creates a new node in Neo4j (for admin API)
 func (c *Client) CreateNode(ctx context.Context, nodeType string, props map[string]interface{}) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "create_node")
+
 	// Validate nodeType against allowlist to prevent Cypher injection
-	if !allowedNodeLabels[nodeType] {
-		return errors.New("invalid node type: must be one of Country, SME, LiquidityProvider, Hub, Node")
-	}
-	// Extra safety: validate label format (alphanumeric starting with letter)
-	if !validLabelPattern.MatchString(nodeType) {
-		return errors.New("invalid node type format")
+	label, err := nodeLabel(nodeType)
+	if err != nil {
+		return fmt.Errorf("%w: must be one of Country, SME, LiquidityProvider, Hub, Node", err)
 	}
 
 	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
@@ -327,19 +453,205 @@ func (c *Client) CreateNode(ctx context.Context, nodeType string, props map[stri
 	})
 	defer session.Close(ctx)
 
-	// nodeType is now validated, safe to use in query
-	query := fmt.Sprintf(`
-		CREATE (n:%s $props)
-		RETURN n
-	`, nodeType)
+	// label is allow-listed, safe to use in query
+	query := `CREATE (n:` + label + ` $props) RETURN n`
 
-	_, err := session.Run(ctx, query, map[string]interface{}{
+	_, err = session.Run(ctx, query, map[string]interface{}{
 		"props": props,
 	})
 
 	return err
 }
 
+// meshLabels are the node labels replaced by ReplaceMesh
+var meshLabels = []string{"SME", "LiquidityProvider", "Hub"}
+
+// MeshRelationship returns the relationship type for an edge between two node
+// types, following the seed data in 001_init_mesh.cypher
+func MeshRelationship(sourceType, targetType string) string {
+	switch {
+	case sourceType == "SME" && targetType == "LiquidityProvider":
+		return "HAS_ACCESS"
+	case sourceType == "LiquidityProvider" && targetType == "Hub":
+		return "PROVIDES_LIQUIDITY"
+	case sourceType == "Hub" && targetType == "Hub":
+		return "INTERCONNECT"
+	}
+	return "CONNECTS_TO"
+}
+
+// ReplaceMesh replaces every SME, LiquidityProvider and Hub node and their
+// relationships with nodes and edges in a single transaction (for graph import)
+func (c *Client) ReplaceMesh(ctx context.Context, nodes []Node, edges []Edge) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "replace_mesh")
+
+	nodesByLabel := make(map[string][]map[string]interface{})
+	types := make(map[string]string, len(nodes))
+	for _, node := range nodes {
+		if !allowedNodeLabels[node.Type] || node.Type == "Country" || node.Type == "Node" {
+			return fmt.Errorf("invalid mesh node type %q", node.Type)
+		}
+		props := make(map[string]interface{}, len(node.Props)+3)
+		for k, v := range node.Props {
+			switch v.(type) {
+			case string, bool, int64, float64:
+				props[k] = v
+			}
+		}
+		props["id"] = node.ID
+		props["region"] = node.Region
+		props["is_active"] = node.IsActive
+		nodesByLabel[node.Type] = append(nodesByLabel[node.Type], props)
+		types[node.ID] = node.Type
+	}
+
+	edgesByType := make(map[string][]map[string]interface{})
+	for _, edge := range edges {
+		relType := edge.Type
+		if relType == "" {
+			relType = MeshRelationship(types[edge.SourceID], types[edge.TargetID])
+		}
+		if !validLabelPattern.MatchString(relType) {
+			return fmt.Errorf("invalid relationship type %q", relType)
+		}
+		edgesByType[relType] = append(edgesByType[relType], map[string]interface{}{
+			"source":           edge.SourceID,
+			"target":           edge.TargetID,
+			"base_fee":         edge.BaseFee,
+			"latency":          edge.Latency,
+			"liquidity_volume": edge.LiquidityVolume,
+			"is_active":        edge.IsActive,
+		})
+	}
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.database,
+		AccessMode:   neo4j.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		if _, err := tx.Run(ctx, `MATCH (n) WHERE n:SME OR n:LiquidityProvider OR n:Hub DETACH DELETE n`, nil); err != nil {
+			return nil, err
+		}
+		// Labels and relationship types are validated above, safe to use in queries
+		for _, label := range meshLabels {
+			if len(nodesByLabel[label]) == 0 {
+				continue
+			}
+			query := fmt.Sprintf(`UNWIND $nodes AS props CREATE (n:%s) SET n = props`, label)
+			if _, err := tx.Run(ctx, query, map[string]interface{}{"nodes": nodesByLabel[label]}); err != nil {
+				return nil, err
+			}
+		}
+		for relType, batch := range edgesByType {
+			query := fmt.Sprintf(`
+				UNWIND $edges AS e
+				MATCH (a {id: e.source}) WHERE a:SME OR a:LiquidityProvider OR a:Hub
+				MATCH (b {id: e.target}) WHERE b:SME OR b:LiquidityProvider OR b:Hub
+				CREATE (a)-[r:%s]->(b)
+				SET r.base_fee = e.base_fee, r.latency = e.latency,
+				    r.liquidity_volume = e.liquidity_volume, r.is_active = e.is_active
+			`, relType)
+			if _, err := tx.Run(ctx, query, map[string]interface{}{"edges": batch}); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to replace mesh: %w", err)
+	}
+	return nil
+}
+
+// LoadMesh reads every SME, LiquidityProvider and Hub node and the
+// relationships between them, for rebuilding the routing graph at startup.
+// An empty mesh returns no nodes and no error.
+func (c *Client) LoadMesh(ctx context.Context) ([]Node, []Edge, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "load_mesh")
+
+	session := c.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: c.database,
+		AccessMode:   neo4j.AccessModeRead,
+	})
+	defer session.Close(ctx)
+
+	var nodes []Node
+	var edges []Edge
+	_, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		nodes, edges = nil, nil // The transaction may be retried
+
+		result, err := tx.Run(ctx, `
+			MATCH (n) WHERE n:SME OR n:LiquidityProvider OR n:Hub
+			RETURN n ORDER BY n.id
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+		for result.Next(ctx) {
+			value, _ := result.Record().Get("n")
+			n, ok := value.(neo4j.Node)
+			if !ok || getStringProp(n.Props, "id") == "" {
+				continue
+			}
+			nodes = append(nodes, Node{
+				ID:       getStringProp(n.Props, "id"),
+				Type:     meshLabel(n.Labels),
+				Name:     getStringProp(n.Props, "name"),
+				Region:   getStringProp(n.Props, "region"),
+				IsActive: getBoolProp(n.Props, "is_active"),
+				Props:    storableProps(n.Props), // Drops temporal values such as created_at
+			})
+		}
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err = tx.Run(ctx, `
+			MATCH (a)-[r]->(b)
+			WHERE (a:SME OR a:LiquidityProvider OR a:Hub) AND (b:SME OR b:LiquidityProvider OR b:Hub)
+			RETURN a.id AS source, b.id AS target, type(r) AS type, elementId(r) AS id,
+			       r.base_fee AS base_fee, r.latency AS latency,
+			       r.liquidity_volume AS liquidity_volume, r.is_active AS is_active
+			ORDER BY source, target
+		`, nil)
+		if err != nil {
+			return nil, err
+		}
+		for result.Next(ctx) {
+			props := result.Record().AsMap()
+			edges = append(edges, Edge{
+				ID:              getStringProp(props, "id"),
+				Type:            getStringProp(props, "type"),
+				SourceID:        getStringProp(props, "source"),
+				TargetID:        getStringProp(props, "target"),
+				BaseFee:         getFloatProp(props, "base_fee"),
+				Latency:         getIntProp(props, "latency"),
+				LiquidityVolume: getIntProp(props, "liquidity_volume"),
+				IsActive:        getBoolProp(props, "is_active"),
+			})
+		}
+		return nil, result.Err()
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load mesh: %w", err)
+	}
+	return nodes, edges, nil
+}
+
+// meshLabel returns the mesh node type among a node's labels
+func meshLabel(labels []string) string {
+	for _, label := range labels {
+		for _, mesh := range meshLabels {
+			if label == mesh {
+				return label
+			}
+		}
+	}
+	return ""
+}
+
 // Helper functions for property extraction
 func getStringProp(props map[string]interface{}, key string) string {
 	if val, ok := props[key]; ok {
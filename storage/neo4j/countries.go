@@ -5,8 +5,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"time"
 
 	neo4jdriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+	"github.com/plm/predictive-liquidity-mesh/pkg/refdata"
 )
 
 // Country represents a country node with credibility metrics
@@ -78,6 +81,8 @@ var Top50GDPCountries = []Country{
 
 // BootstrapCountries creates all country nodes in Neo4j if they don't exist
 func BootstrapCountries(ctx context.Context, driver neo4jdriver.DriverWithContext, database string) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "bootstrap_countries")
+
 	session := driver.NewSession(ctx, neo4jdriver.SessionConfig{
 		DatabaseName: database,
 		AccessMode:   neo4jdriver.AccessModeWrite,
@@ -142,6 +147,61 @@ func GetAllCurrencies() []string {
 	return currencies
 }
 
+// ListActiveCountries returns the countries that are not halted, by GDP rank
+func ListActiveCountries(ctx context.Context, driver neo4jdriver.DriverWithContext, database string) ([]Country, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "list_countries")
+
+	session := driver.NewSession(ctx, neo4jdriver.SessionConfig{
+		DatabaseName: database,
+		AccessMode:   neo4jdriver.AccessModeRead,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, `
+		MATCH (c:Country)
+		WHERE coalesce(c.is_active, true)
+		RETURN c.code AS code, c.name AS name, c.currency AS currency,
+		       c.base_credibility AS base_credibility, c.success_rate AS success_rate,
+		       c.gdp_rank AS gdp_rank, c.fx_rate AS fx_rate
+		ORDER BY c.gdp_rank ASC, c.code ASC
+	`, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list countries: %w", err)
+	}
+
+	countries := make([]Country, 0)
+	for result.Next(ctx) {
+		record := result.Record()
+		country := Country{}
+		if v, ok := record.Get("code"); ok && v != nil {
+			country.Code = v.(string)
+		}
+		if v, ok := record.Get("name"); ok && v != nil {
+			country.Name = v.(string)
+		}
+		if v, ok := record.Get("currency"); ok && v != nil {
+			country.Currency = v.(string)
+		}
+		if v, ok := record.Get("base_credibility"); ok && v != nil {
+			country.BaseCredibility = v.(float64)
+		}
+		if v, ok := record.Get("success_rate"); ok && v != nil {
+			country.SuccessRate = v.(float64)
+		}
+		if v, ok := record.Get("gdp_rank"); ok && v != nil {
+			country.GDPRank = int(v.(int64))
+		}
+		if v, ok := record.Get("fx_rate"); ok && v != nil {
+			country.FXRate = v.(float64)
+		}
+		countries = append(countries, country)
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list countries: %w", err)
+	}
+	return countries, nil
+}
+
 // CredibilityUpdater provides credibility update functionality
 type CredibilityUpdater struct {
 	driver   neo4jdriver.DriverWithContext
@@ -156,17 +216,23 @@ func NewCredibilityUpdater(driver neo4jdriver.DriverWithContext, database string
 	}
 }
 
-// UpdateCredibility updates a country's credibility based on transaction success/failure
+// UpdateCredibility updates a country's credibility based on transaction success/failure.
+// Payment hops go through a CredibilityBatcher instead, which writes many at once.
 // Success: +0.01% (0.0001)
 // Failure: -0.0075% (0.000075)
 // Credibility is clamped between 0.5 and 1.0
 func (u *CredibilityUpdater) UpdateCredibility(ctx context.Context, countryCode string, success bool) error {
+	if err := refdata.CheckCountry(countryCode); err != nil {
+		return err
+	}
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "update_credibility")
+
 	session := u.driver.NewSession(ctx, neo4jdriver.SessionConfig{DatabaseName: u.database})
 	defer session.Close(ctx)
 
-	delta := 0.0001 // +0.01% for success
+	delta := CredibilitySuccessDelta
 	if !success {
-		delta = -0.000075 // -0.0075% for failure
+		delta = CredibilityFailureDelta
 	}
 
 	query := `
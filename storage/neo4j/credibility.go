@@ -0,0 +1,367 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	neo4jdriver "github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+// Credibility moves by these deltas per hop and stays within these bounds.
+// Between hops it decays toward the country's baseline, which is
+// CredibilityBaseline unless an admin set the country's credibility.
+const (
+	CredibilitySuccessDelta = 0.0001    // +0.01% per successful hop
+	CredibilityFailureDelta = -0.000075 // -0.0075% per failed hop
+	MinCredibility          = 0.5
+	MaxCredibility          = 1.0
+	CredibilityBaseline     = 0.85
+)
+
+// CredibilityChange is a country's credibility before and after a write
+type CredibilityChange struct {
+	Before float64
+	After  float64
+}
+
+// credibilityDecayQuery decays c.base_credibility toward the baseline for the
+// time since it was last updated, adds delta and clamps the result. It
+// expects c and delta in scope.
+const credibilityDecayQuery = `
+	WITH c, delta, c.base_credibility AS before,
+		coalesce(c.credibility_baseline, $baseline) AS baseline,
+		CASE WHEN c.credibility_updated_at IS NULL OR $halfLife <= 0 THEN 1.0
+			ELSE exp(-0.6931471805599453 * duration.inSeconds(c.credibility_updated_at, datetime()).seconds / $halfLife)
+		END AS keep
+	WITH c, before, baseline + (before - baseline) * keep + delta AS next
+	SET c.base_credibility = CASE
+		WHEN next > $max THEN $max
+		WHEN next < $min THEN $min
+		ELSE next
+	END,
+	c.credibility_updated_at = datetime()
+	RETURN c.code AS code, before, c.base_credibility AS credibility
+`
+
+// ApplyCredibility adds a delta to the credibility of each country in one
+// query, after decaying it for the time since its last update (no decay when
+// halfLife is 0). Returns the change of each country found.
+func (u *CredibilityUpdater) ApplyCredibility(ctx context.Context, deltas map[string]float64, halfLife time.Duration) (map[string]CredibilityChange, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "apply_credibility")
+
+	updates := make([]map[string]interface{}, 0, len(deltas))
+	for code, delta := range deltas {
+		updates = append(updates, map[string]interface{}{"code": code, "delta": delta})
+	}
+	return u.runCredibility(ctx, `
+		UNWIND $updates AS u
+		MATCH (c:Country {code: u.code})
+		WITH c, u.delta AS delta
+	`+credibilityDecayQuery, map[string]interface{}{"updates": updates}, halfLife)
+}
+
+// DecayCredibility decays the credibility of every country toward its
+// baseline for the time since its last update. Returns the change of each.
+func (u *CredibilityUpdater) DecayCredibility(ctx context.Context, halfLife time.Duration) (map[string]CredibilityChange, error) {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "decay_credibility")
+
+	return u.runCredibility(ctx, `
+		MATCH (c:Country)
+		WHERE c.base_credibility IS NOT NULL
+		WITH c, 0.0 AS delta
+	`+credibilityDecayQuery, map[string]interface{}{}, halfLife)
+}
+
+func (u *CredibilityUpdater) runCredibility(ctx context.Context, query string, params map[string]interface{}, halfLife time.Duration) (map[string]CredibilityChange, error) {
+	params["baseline"] = CredibilityBaseline
+	params["halfLife"] = halfLife.Seconds()
+	params["min"], params["max"] = MinCredibility, MaxCredibility
+
+	session := u.driver.NewSession(ctx, neo4jdriver.SessionConfig{
+		DatabaseName: u.database,
+		AccessMode:   neo4jdriver.AccessModeWrite,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, query, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update credibility: %w", err)
+	}
+
+	changes := make(map[string]CredibilityChange)
+	for result.Next(ctx) {
+		record := result.Record()
+		code, _ := record.Get("code")
+		before, _ := record.Get("before")
+		after, _ := record.Get("credibility")
+		c, _ := code.(string)
+		change := CredibilityChange{}
+		change.Before, _ = before.(float64)
+		change.After, _ = after.(float64)
+		changes[c] = change
+	}
+	if err := result.Err(); err != nil {
+		return nil, fmt.Errorf("failed to update credibility: %w", err)
+	}
+	return changes, nil
+}
+
+// CredibilityWriter persists credibility changes; implemented by CredibilityUpdater
+type CredibilityWriter interface {
+	ApplyCredibility(ctx context.Context, deltas map[string]float64, halfLife time.Duration) (map[string]CredibilityChange, error)
+	DecayCredibility(ctx context.Context, halfLife time.Duration) (map[string]CredibilityChange, error)
+}
+
+// CredibilityBatchConfig configures a CredibilityBatcher
+type CredibilityBatchConfig struct {
+	FlushInterval time.Duration // Time between writes to Neo4j
+	Timeout       time.Duration // Limit on one write attempt
+	MaxRetries    int           // Further attempts after a failed write
+	RetryBackoff  time.Duration // Wait before the first retry, doubling after each
+	HalfLife      time.Duration // Time for credibility to move halfway back to baseline; 0 = no decay
+	DecayInterval time.Duration // Time between decaying every country
+}
+
+// DefaultCredibilityBatchConfig returns default configuration
+func DefaultCredibilityBatchConfig() *CredibilityBatchConfig {
+	return &CredibilityBatchConfig{
+		FlushInterval: time.Second,
+		Timeout:       5 * time.Second,
+		MaxRetries:    3,
+		RetryBackoff:  200 * time.Millisecond,
+		HalfLife:      7 * 24 * time.Hour,
+		DecayInterval: time.Hour,
+	}
+}
+
+// pendingCredibility is the hop outcomes of a country not yet written
+type pendingCredibility struct {
+	delta     float64
+	successes int
+	failures  int
+}
+
+// CredibilityBatcher sums the credibility deltas of hop outcomes per country
+// and writes them to Neo4j every flush interval in a single query, instead
+// of one session per hop, and decays every country toward its baseline each
+// decay interval. Writes happen one at a time; deltas of a write that fails
+// after its retries are kept for the next flush. The latest written
+// credibility of each country is cached, and each change is recorded in the
+// history when one is set.
+type CredibilityBatcher struct {
+	cfg     *CredibilityBatchConfig
+	writer  atomic.Pointer[CredibilityWriter]
+	history CredibilityHistory
+
+	mu      sync.Mutex
+	pending map[string]*pendingCredibility // Country code -> outcomes not yet written
+	cached  map[string]float64             // Country code -> credibility after the last write
+
+	flushMu sync.Mutex // Serializes writes
+}
+
+// NewCredibilityBatcher creates a batcher; it writes nothing until SetWriter
+func NewCredibilityBatcher(cfg *CredibilityBatchConfig) *CredibilityBatcher {
+	if cfg == nil {
+		cfg = DefaultCredibilityBatchConfig()
+	}
+	return &CredibilityBatcher{
+		cfg:     cfg,
+		pending: make(map[string]*pendingCredibility),
+		cached:  make(map[string]float64),
+	}
+}
+
+// SetWriter sets where deltas are written, e.g. when Neo4j becomes available
+func (b *CredibilityBatcher) SetWriter(w CredibilityWriter) {
+	b.writer.Store(&w)
+}
+
+// SetHistory sets where credibility changes are recorded. Call before Start.
+func (b *CredibilityBatcher) SetHistory(h CredibilityHistory) {
+	b.history = h
+}
+
+// Record adds the outcome of a hop into a country. It never blocks on Neo4j.
+func (b *CredibilityBatcher) Record(countryCode string, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	p := b.pending[countryCode]
+	if p == nil {
+		p = &pendingCredibility{}
+		b.pending[countryCode] = p
+	}
+	if success {
+		p.delta += CredibilitySuccessDelta
+		p.successes++
+	} else {
+		p.delta += CredibilityFailureDelta
+		p.failures++
+	}
+}
+
+// Credibility returns a country's credibility as of the last write
+func (b *CredibilityBatcher) Credibility(countryCode string) (float64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cred, ok := b.cached[countryCode]
+	return cred, ok
+}
+
+// Pending returns the number of countries with deltas not yet written
+func (b *CredibilityBatcher) Pending() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Start flushes every flush interval and decays every decay interval until
+// ctx is done, then flushes once more
+func (b *CredibilityBatcher) Start(ctx context.Context) {
+	ticker := time.NewTicker(b.cfg.FlushInterval)
+	defer ticker.Stop()
+	var decay <-chan time.Time
+	if b.cfg.HalfLife > 0 && b.cfg.DecayInterval > 0 {
+		decayTicker := time.NewTicker(b.cfg.DecayInterval)
+		defer decayTicker.Stop()
+		decay = decayTicker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			finalCtx, cancel := context.WithTimeout(context.Background(), b.cfg.Timeout)
+			b.Flush(finalCtx)
+			cancel()
+			return
+		case <-ticker.C:
+			b.Flush(ctx)
+		case <-decay:
+			b.Decay(ctx)
+		}
+	}
+}
+
+// Flush writes the pending deltas, retrying with backoff. Deltas that could
+// not be written are put back for the next flush.
+func (b *CredibilityBatcher) Flush(ctx context.Context) error {
+	w := b.writer.Load()
+	if w == nil {
+		return nil
+	}
+
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	pending := b.pending
+	if len(pending) == 0 {
+		b.mu.Unlock()
+		return nil
+	}
+	b.pending = make(map[string]*pendingCredibility)
+	b.mu.Unlock()
+
+	deltas := make(map[string]float64, len(pending))
+	for code, p := range pending {
+		deltas[code] = p.delta
+	}
+	changes, err := b.write(ctx, func(ctx context.Context) (map[string]CredibilityChange, error) {
+		return (*w).ApplyCredibility(ctx, deltas, b.cfg.HalfLife)
+	})
+	if err != nil {
+		b.mu.Lock()
+		for code, p := range pending {
+			if q := b.pending[code]; q != nil {
+				p.delta += q.delta
+				p.successes += q.successes
+				p.failures += q.failures
+			}
+			b.pending[code] = p
+		}
+		b.mu.Unlock()
+		log.Printf("⚠️ Failed to write credibility for %d countries, retrying next flush: %v", len(pending), err)
+		return err
+	}
+
+	b.applied(ctx, changes, CredibilityReasonHops, pending)
+	return nil
+}
+
+// Decay moves every country's credibility toward its baseline for the time
+// since its last update
+func (b *CredibilityBatcher) Decay(ctx context.Context) error {
+	w := b.writer.Load()
+	if w == nil || b.cfg.HalfLife <= 0 {
+		return nil
+	}
+
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	changes, err := b.write(ctx, func(ctx context.Context) (map[string]CredibilityChange, error) {
+		return (*w).DecayCredibility(ctx, b.cfg.HalfLife)
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to decay credibility: %v", err)
+		return err
+	}
+	b.applied(ctx, changes, CredibilityReasonDecay, nil)
+	return nil
+}
+
+// applied caches written credibility and records the changes in the history
+func (b *CredibilityBatcher) applied(ctx context.Context, changes map[string]CredibilityChange, reason string, pending map[string]*pendingCredibility) {
+	now := time.Now().UTC()
+	points := make([]CredibilityPoint, 0, len(changes))
+
+	b.mu.Lock()
+	for code, c := range changes {
+		b.cached[code] = c.After
+		if c.After == c.Before {
+			continue
+		}
+		point := CredibilityPoint{Code: code, Credibility: c.After, Change: c.After - c.Before, Reason: reason, At: now}
+		if p := pending[code]; p != nil {
+			point.Successes, point.Failures = p.successes, p.failures
+		}
+		points = append(points, point)
+	}
+	b.mu.Unlock()
+
+	if b.history == nil || len(points) == 0 {
+		return
+	}
+	if err := b.history.Append(ctx, points); err != nil {
+		log.Printf("⚠️ Failed to record credibility history: %v", err)
+	}
+}
+
+// write attempts a write up to 1+MaxRetries times
+func (b *CredibilityBatcher) write(ctx context.Context, run func(ctx context.Context) (map[string]CredibilityChange, error)) (map[string]CredibilityChange, error) {
+	backoff := b.cfg.RetryBackoff
+	var err error
+	for attempt := 0; attempt <= b.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		attemptCtx, cancel := context.WithTimeout(ctx, b.cfg.Timeout)
+		var changes map[string]CredibilityChange
+		changes, err = run(attemptCtx)
+		cancel()
+		if err == nil {
+			return changes, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,75 @@
+package neo4j
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCredibilityRetention is how long a MemoryCredibilityHistory keeps changes
+const DefaultCredibilityRetention = 90 * 24 * time.Hour
+
+// Reasons for a credibility change
+const (
+	CredibilityReasonHops  = "hops"  // Payment hop outcomes, with decay since the last update
+	CredibilityReasonDecay = "decay" // Drift toward the baseline alone
+)
+
+// CredibilityPoint is one change of a country's credibility
+type CredibilityPoint struct {
+	Code        string    `json:"-"`
+	Credibility float64   `json:"credibility"`
+	Change      float64   `json:"change"`
+	Reason      string    `json:"reason"`
+	Successes   int       `json:"successes,omitempty"` // Successful hops in a hops change
+	Failures    int       `json:"failures,omitempty"`  // Failed hops in a hops change
+	At          time.Time `json:"at"`
+}
+
+// CredibilityHistory persists credibility changes. Implemented by
+// MemoryCredibilityHistory and the Postgres-backed store in storage/postgres.
+type CredibilityHistory interface {
+	// Append saves changes, each for its Code
+	Append(ctx context.Context, points []CredibilityPoint) error
+	// History returns a country's changes at or after since, oldest first
+	History(ctx context.Context, code string, since time.Time) ([]CredibilityPoint, error)
+}
+
+// MemoryCredibilityHistory is an in-memory CredibilityHistory that drops
+// changes older than its retention
+type MemoryCredibilityHistory struct {
+	mu        sync.RWMutex
+	points    map[string][]CredibilityPoint
+	retention time.Duration
+}
+
+// NewMemoryCredibilityHistory creates a history keeping changes for retention
+func NewMemoryCredibilityHistory(retention time.Duration) *MemoryCredibilityHistory {
+	if retention <= 0 {
+		retention = DefaultCredibilityRetention
+	}
+	return &MemoryCredibilityHistory{points: make(map[string][]CredibilityPoint), retention: retention}
+}
+
+// Append implements CredibilityHistory
+func (h *MemoryCredibilityHistory) Append(ctx context.Context, points []CredibilityPoint) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, p := range points {
+		cutoff := p.At.Add(-h.retention)
+		series := append(h.points[p.Code], p)
+		drop := sort.Search(len(series), func(i int) bool { return !series[i].At.Before(cutoff) })
+		h.points[p.Code] = series[drop:]
+	}
+	return nil
+}
+
+// History implements CredibilityHistory
+func (h *MemoryCredibilityHistory) History(ctx context.Context, code string, since time.Time) ([]CredibilityPoint, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	series := h.points[code]
+	from := sort.Search(len(series), func(i int) bool { return !series[i].At.Before(since) })
+	return append([]CredibilityPoint{}, series[from:]...), nil
+}
@@ -0,0 +1,160 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeCredibilityWriter applies deltas to in-memory credibility, failing the
+// first failures writes. Decay moves every country halfway to the baseline.
+type fakeCredibilityWriter struct {
+	failures int
+	writes   []map[string]float64
+	cred     map[string]float64
+}
+
+func (w *fakeCredibilityWriter) ApplyCredibility(ctx context.Context, deltas map[string]float64, halfLife time.Duration) (map[string]CredibilityChange, error) {
+	w.writes = append(w.writes, deltas)
+	if w.failures > 0 {
+		w.failures--
+		return nil, errors.New("session expired")
+	}
+	changes := make(map[string]CredibilityChange, len(deltas))
+	for code, delta := range deltas {
+		before := w.cred[code]
+		w.cred[code] = math.Max(MinCredibility, math.Min(MaxCredibility, before+delta))
+		changes[code] = CredibilityChange{Before: before, After: w.cred[code]}
+	}
+	return changes, nil
+}
+
+func (w *fakeCredibilityWriter) DecayCredibility(ctx context.Context, halfLife time.Duration) (map[string]CredibilityChange, error) {
+	changes := make(map[string]CredibilityChange, len(w.cred))
+	for code, before := range w.cred {
+		w.cred[code] = CredibilityBaseline + (before-CredibilityBaseline)/2
+		changes[code] = CredibilityChange{Before: before, After: w.cred[code]}
+	}
+	return changes, nil
+}
+
+func testBatcher() *CredibilityBatcher {
+	return NewCredibilityBatcher(&CredibilityBatchConfig{
+		FlushInterval: time.Hour,
+		Timeout:       time.Second,
+		MaxRetries:    1,
+		RetryBackoff:  time.Millisecond,
+		HalfLife:      time.Hour,
+	})
+}
+
+// TestCredibilityBatcherWritesOncePerFlush verifies hop outcomes are summed
+// per country and written in a single call
+func TestCredibilityBatcherWritesOncePerFlush(t *testing.T) {
+	b := testBatcher()
+	ctx := context.Background()
+
+	// Without a writer the deltas wait
+	b.Record("USA", true)
+	if err := b.Flush(ctx); err != nil || b.Pending() != 1 {
+		t.Fatalf("flush without writer: err %v, %d pending; want the delta kept", err, b.Pending())
+	}
+
+	w := &fakeCredibilityWriter{cred: map[string]float64{"USA": 0.9, "GBR": 0.9}}
+	b.SetWriter(w)
+	b.Record("USA", true)
+	b.Record("GBR", false)
+	b.Record("GBR", false)
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if len(w.writes) != 1 || len(w.writes[0]) != 2 {
+		t.Fatalf("writes = %v, want one write covering both countries", w.writes)
+	}
+	if got, want := w.writes[0]["GBR"], 2*CredibilityFailureDelta; math.Abs(got-want) > 1e-12 {
+		t.Errorf("GBR delta = %v, want %v", got, want)
+	}
+	if cred, ok := b.Credibility("USA"); !ok || math.Abs(cred-(0.9+2*CredibilitySuccessDelta)) > 1e-12 {
+		t.Errorf("cached USA credibility = %v, %v", cred, ok)
+	}
+	if b.Pending() != 0 {
+		t.Errorf("%d countries still pending after a successful flush", b.Pending())
+	}
+
+	if err := b.Flush(ctx); err != nil || len(w.writes) != 1 {
+		t.Errorf("flush with nothing pending: err %v, %d writes; want no write", err, len(w.writes))
+	}
+}
+
+// TestCredibilityBatcherRetries verifies a failed write is retried, and that
+// deltas of a write failing every attempt are kept for the next flush
+func TestCredibilityBatcherRetries(t *testing.T) {
+	b := testBatcher()
+	ctx := context.Background()
+	w := &fakeCredibilityWriter{failures: 1, cred: map[string]float64{"USA": 0.9}}
+	b.SetWriter(w)
+
+	b.Record("USA", true)
+	if err := b.Flush(ctx); err != nil || len(w.writes) != 2 {
+		t.Fatalf("flush after one failure: err %v, %d writes; want success on the retry", err, len(w.writes))
+	}
+
+	w.failures = 2
+	b.Record("USA", false)
+	if err := b.Flush(ctx); err == nil {
+		t.Fatal("flush succeeded although every attempt failed")
+	}
+	b.Record("USA", false)
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got, want := w.writes[len(w.writes)-1]["USA"], 2*CredibilityFailureDelta; math.Abs(got-want) > 1e-12 {
+		t.Errorf("delta after a failed flush = %v, want the kept and new deltas %v", got, want)
+	}
+}
+
+// TestCredibilityBatcherRecordsHistory verifies hop and decay changes are
+// recorded per country, skipping countries that did not move
+func TestCredibilityBatcherRecordsHistory(t *testing.T) {
+	b := testBatcher()
+	ctx := context.Background()
+	history := NewMemoryCredibilityHistory(0)
+	b.SetHistory(history)
+	b.SetWriter(&fakeCredibilityWriter{cred: map[string]float64{"USA": 0.9, "GBR": CredibilityBaseline}})
+
+	b.Record("USA", true)
+	b.Record("USA", false)
+	b.Record("USA", true)
+	if err := b.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if err := b.Decay(ctx); err != nil {
+		t.Fatalf("Decay: %v", err)
+	}
+
+	points, _ := history.History(ctx, "USA", time.Time{})
+	if len(points) != 2 {
+		t.Fatalf("USA history = %+v, want a hops and a decay change", points)
+	}
+	hops, decay := points[0], points[1]
+	if hops.Reason != CredibilityReasonHops || hops.Successes != 2 || hops.Failures != 1 {
+		t.Errorf("hops change = %+v, want 2 successes and 1 failure", hops)
+	}
+	if want := 0.9 + 2*CredibilitySuccessDelta + CredibilityFailureDelta; math.Abs(hops.Credibility-want) > 1e-12 {
+		t.Errorf("credibility after hops = %v, want %v", hops.Credibility, want)
+	}
+	if decay.Reason != CredibilityReasonDecay || decay.Change >= 0 || decay.Credibility <= CredibilityBaseline {
+		t.Errorf("decay change = %+v, want a drop toward the baseline", decay)
+	}
+	if cred, _ := b.Credibility("USA"); cred != decay.Credibility {
+		t.Errorf("cached credibility = %v, want %v after decay", cred, decay.Credibility)
+	}
+
+	// GBR sits at its baseline, so decay does not move it
+	if points, _ := history.History(ctx, "GBR", time.Time{}); len(points) != 0 {
+		t.Errorf("GBR history = %+v, want none", points)
+	}
+}
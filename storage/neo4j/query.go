@@ -0,0 +1,158 @@
+package neo4j
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Path query bounds. Variable-length patterns cannot take their bounds as
+// parameters, so they come from hopPatterns rather than from formatting
+// caller input into the query.
+const (
+	MinPathHops     = 1
+	MaxPathHops     = 10
+	DefaultPathHops = 6
+	MaxPathResults  = 100
+)
+
+// hopPatterns are the only variable-length patterns queries may contain
+var hopPatterns = func() map[int]string {
+	patterns := make(map[int]string, MaxPathHops)
+	for hops := MinPathHops; hops <= MaxPathHops; hops++ {
+		patterns[hops] = fmt.Sprintf("*%d..%d", MinPathHops, hops)
+	}
+	return patterns
+}()
+
+// allowedRelTypes are the relationship types of the mesh and country graphs
+var allowedRelTypes = map[string]bool{
+	"HAS_ACCESS":         true,
+	"PROVIDES_LIQUIDITY": true,
+	"INTERCONNECT":       true,
+	"CONNECTS_TO":        true,
+	"TRADES_WITH":        true,
+}
+
+var (
+	// ErrInvalidLabel is returned for node labels outside allowedNodeLabels
+	ErrInvalidLabel = errors.New("invalid node label")
+	// ErrInvalidRelType is returned for relationship types outside allowedRelTypes
+	ErrInvalidRelType = errors.New("invalid relationship type")
+	// ErrNodeNotFound is returned when an edge endpoint does not exist
+	ErrNodeNotFound = errors.New("node not found")
+	// ErrEdgeNotFound is returned when deleting an edge that does not exist
+	ErrEdgeNotFound = errors.New("edge not found")
+)
+
+// Query is a Cypher statement and its parameters. Values supplied by callers
+// are always parameters; only allow-listed identifiers appear in Cypher.
+type Query struct {
+	Cypher string
+	Params map[string]interface{}
+}
+
+// NodeRef identifies a node by label and id property. The label is required
+// so queries use the label's index instead of scanning every node.
+type NodeRef struct {
+	Label string
+	ID    string
+}
+
+// nodeLabel returns label if it is allow-listed
+func nodeLabel(label string) (string, error) {
+	if !allowedNodeLabels[label] || !validLabelPattern.MatchString(label) {
+		return "", fmt.Errorf("%w %q", ErrInvalidLabel, label)
+	}
+	return label, nil
+}
+
+// relTypeOf returns t if it is allow-listed
+func relTypeOf(t string) (string, error) {
+	if !allowedRelTypes[t] || !validLabelPattern.MatchString(t) {
+		return "", fmt.Errorf("%w %q", ErrInvalidRelType, t)
+	}
+	return t, nil
+}
+
+// IsRelType reports whether t is an allow-listed relationship type
+func IsRelType(t string) bool {
+	_, err := relTypeOf(t)
+	return err == nil
+}
+
+// ClampHops bounds a hop count to [MinPathHops, MaxPathHops]; zero or less
+// uses DefaultPathHops
+func ClampHops(hops int) int {
+	switch {
+	case hops <= 0:
+		return DefaultPathHops
+	case hops > MaxPathHops:
+		return MaxPathHops
+	}
+	return hops
+}
+
+// PathQuery finds active paths between two nodes
+type PathQuery struct {
+	Source   NodeRef
+	Target   NodeRef
+	MaxHops  int      // Clamped with ClampHops
+	RelTypes []string // Relationship types to follow; empty follows any
+	Limit    int      // Paths returned, at most MaxPathResults (default 10)
+}
+
+// Build validates the query and renders it as Cypher
+func (q PathQuery) Build() (Query, error) {
+	sourceLabel, err := nodeLabel(q.Source.Label)
+	if err != nil {
+		return Query{}, fmt.Errorf("source: %w", err)
+	}
+	targetLabel, err := nodeLabel(q.Target.Label)
+	if err != nil {
+		return Query{}, fmt.Errorf("target: %w", err)
+	}
+	if q.Source.ID == "" || q.Target.ID == "" {
+		return Query{}, errors.New("source and target ids are required")
+	}
+
+	rels := make([]string, 0, len(q.RelTypes))
+	for _, t := range q.RelTypes {
+		rel, err := relTypeOf(t)
+		if err != nil {
+			return Query{}, err
+		}
+		rels = append(rels, rel)
+	}
+	relPattern := ""
+	if len(rels) > 0 {
+		relPattern = ":" + strings.Join(rels, "|")
+	}
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = 10
+	}
+	if limit > MaxPathResults {
+		limit = MaxPathResults
+	}
+
+	cypher := `
+		MATCH path = (source:` + sourceLabel + ` {id: $sourceId})-[` + relPattern + hopPatterns[ClampHops(q.MaxHops)] + `]->(target:` + targetLabel + ` {id: $targetId})
+		WHERE all(r IN relationships(path) WHERE r.is_active = true)
+		  AND all(n IN nodes(path) WHERE n.is_active = true)
+		RETURN path,
+		       reduce(fee = 0.0, r IN relationships(path) | fee + coalesce(r.base_fee, 0)) AS totalFee,
+		       reduce(lat = 0, r IN relationships(path) | lat + coalesce(r.latency, 0)) AS totalLatency
+		ORDER BY totalFee ASC, totalLatency ASC
+		LIMIT $limit
+	`
+	return Query{
+		Cypher: cypher,
+		Params: map[string]interface{}{
+			"sourceId": q.Source.ID,
+			"targetId": q.Target.ID,
+			"limit":    int64(limit),
+		},
+	}, nil
+}
@@ -0,0 +1,107 @@
+package neo4j
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPathQueryBuild(t *testing.T) {
+	q, err := PathQuery{
+		Source:   NodeRef{Label: "SME", ID: "sme_1"},
+		Target:   NodeRef{Label: "Hub", ID: "hub_primary"},
+		MaxHops:  4,
+		RelTypes: []string{"HAS_ACCESS", "PROVIDES_LIQUIDITY"},
+	}.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	want := "(source:SME {id: $sourceId})-[:HAS_ACCESS|PROVIDES_LIQUIDITY*1..4]->(target:Hub {id: $targetId})"
+	if !strings.Contains(q.Cypher, want) {
+		t.Errorf("query does not contain %s:\n%s", want, q.Cypher)
+	}
+	if q.Params["sourceId"] != "sme_1" || q.Params["targetId"] != "hub_primary" || q.Params["limit"] != int64(10) {
+		t.Errorf("params = %v", q.Params)
+	}
+}
+
+func TestPathQueryClampsHops(t *testing.T) {
+	tests := []struct {
+		hops int
+		want string
+	}{
+		{-1 << 62, "*1..6"},
+		{0, "*1..6"},
+		{1, "*1..1"},
+		{10, "*1..10"},
+		{11, "*1..10"},
+		{1 << 62, "*1..10"},
+	}
+	for _, tt := range tests {
+		q, err := PathQuery{Source: NodeRef{"Hub", "a"}, Target: NodeRef{"Hub", "b"}, MaxHops: tt.hops}.Build()
+		if err != nil || !strings.Contains(q.Cypher, "-["+tt.want+"]->") {
+			t.Errorf("MaxHops %d: want %s, got %v\n%s", tt.hops, tt.want, err, q.Cypher)
+		}
+	}
+}
+
+func TestPathQueryRejectsMaliciousIdentifiers(t *testing.T) {
+	valid := NodeRef{Label: "Hub", ID: "hub_primary"}
+	tests := []struct {
+		name  string
+		query PathQuery
+		want  error
+	}{
+		{"missing label", PathQuery{Source: NodeRef{ID: "a"}, Target: valid}, ErrInvalidLabel},
+		{"unknown label", PathQuery{Source: valid, Target: NodeRef{Label: "User", ID: "b"}}, ErrInvalidLabel},
+		{"label injection", PathQuery{Source: NodeRef{Label: "Hub) DETACH DELETE (n", ID: "a"}, Target: valid}, ErrInvalidLabel},
+		{"backtick label", PathQuery{Source: NodeRef{Label: "Hub`", ID: "a"}, Target: valid}, ErrInvalidLabel},
+		{"unknown relationship", PathQuery{Source: valid, Target: valid, RelTypes: []string{"OWNS"}}, ErrInvalidRelType},
+		{"relationship injection", PathQuery{Source: valid, Target: valid, RelTypes: []string{"TRADES_WITH*]->() DETACH DELETE (x)//"}}, ErrInvalidRelType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.query.Build(); !errors.Is(err, tt.want) {
+				t.Errorf("Build error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPathQueryKeepsIDsOutOfCypher(t *testing.T) {
+	evil := "x'}) MATCH (n) DETACH DELETE n //"
+	q, err := PathQuery{Source: NodeRef{Label: "Country", ID: evil}, Target: NodeRef{Label: "Country", ID: "USA"}, Limit: 1 << 20}.Build()
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if strings.Contains(q.Cypher, "DELETE") || q.Params["sourceId"] != evil {
+		t.Errorf("ids must only be parameters:\n%s", q.Cypher)
+	}
+	if q.Params["limit"] != int64(MaxPathResults) {
+		t.Errorf("limit = %v, want %d", q.Params["limit"], MaxPathResults)
+	}
+}
+
+func TestStorableProps(t *testing.T) {
+	props := storableProps(map[string]interface{}{
+		"base_fee": 0.01, "latency": 20, "is_active": true, "created_by": "admin", "tags": []string{"x"},
+	})
+	if props["latency"] != int64(20) || props["base_fee"] != 0.01 || props["created_by"] != "admin" {
+		t.Errorf("props = %v", props)
+	}
+	if _, ok := props["tags"]; ok {
+		t.Error("values Neo4j cannot store as properties must be dropped")
+	}
+	if !IsRelType("HAS_ACCESS") || IsRelType("HAS_ACCESS]->() DETACH DELETE (x)//") {
+		t.Error("IsRelType must only accept allow-listed types")
+	}
+}
+
+func TestMeshLabel(t *testing.T) {
+	if got := meshLabel([]string{"Tracked", "Hub"}); got != "Hub" {
+		t.Errorf("meshLabel = %q, want Hub", got)
+	}
+	if got := meshLabel([]string{"Country"}); got != "" {
+		t.Errorf("meshLabel(Country) = %q, want none", got)
+	}
+}
@@ -0,0 +1,221 @@
+package neo4j
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// SupervisorConfig holds connection supervision settings
+type SupervisorConfig struct {
+	InitialBackoff time.Duration // Wait before the first reconnect attempt; doubles per failure
+	MaxBackoff     time.Duration // Cap on the wait between attempts
+	HealthInterval time.Duration // How often a connected driver is checked
+	HealthTimeout  time.Duration // Bounds each connection attempt and check
+}
+
+// DefaultSupervisorConfig returns sensible defaults
+func DefaultSupervisorConfig() *SupervisorConfig {
+	return &SupervisorConfig{
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		HealthInterval: 15 * time.Second,
+		HealthTimeout:  5 * time.Second,
+	}
+}
+
+// Health is the state of the Neo4j connection
+type Health struct {
+	Status    string    `json:"status"` // "up" or "down"
+	Connected bool      `json:"connected"`
+	Since     time.Time `json:"since"` // When Status last changed
+	LastCheck time.Time `json:"last_check,omitempty"`
+	LastError string    `json:"last_error,omitempty"`
+	Attempts  int       `json:"attempts,omitempty"` // Failed attempts since the last success
+}
+
+// Supervisor keeps the server's Neo4j connection. Without a client it
+// retries with exponential backoff and hands the new client to OnConnect
+// callbacks; with one it checks connectivity periodically (the driver
+// re-establishes pooled connections itself) and reports the result.
+type Supervisor struct {
+	cfg  *Config
+	scfg *SupervisorConfig
+
+	// dial and verify are replaced in tests
+	dial   func(ctx context.Context, cfg *Config) (*Client, error)
+	verify func(ctx context.Context, c *Client) error
+
+	mu        sync.RWMutex
+	client    *Client
+	health    Health
+	onConnect []func(*Client)
+}
+
+// NewSupervisor creates a supervisor for the database in cfg
+func NewSupervisor(cfg *Config, scfg *SupervisorConfig) *Supervisor {
+	if scfg == nil {
+		scfg = DefaultSupervisorConfig()
+	}
+	return &Supervisor{
+		cfg:    cfg,
+		scfg:   scfg,
+		dial:   dial,
+		verify: func(ctx context.Context, c *Client) error { return c.driver.VerifyConnectivity(ctx) },
+		health: Health{Status: "down", Since: time.Now()},
+	}
+}
+
+// SetClient hands the supervisor a client connected at boot
+func (s *Supervisor) SetClient(c *Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = c
+	s.setHealthLocked(nil)
+}
+
+// OnConnect registers a callback run when a client is first connected by
+// the supervisor, i.e. after the server booted without Neo4j
+func (s *Supervisor) OnConnect(fn func(*Client)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onConnect = append(s.onConnect, fn)
+}
+
+// Client returns the connected client, or nil if Neo4j has not been reached
+func (s *Supervisor) Client() *Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// Health returns the current connection health
+func (s *Supervisor) Health() Health {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.health
+}
+
+// Start connects (if needed) and then checks the connection until ctx is cancelled
+func (s *Supervisor) Start(ctx context.Context) {
+	backoff := s.scfg.InitialBackoff
+	for s.Client() == nil {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		if s.connect(ctx) == nil {
+			break
+		}
+		if backoff *= 2; backoff > s.scfg.MaxBackoff {
+			backoff = s.scfg.MaxBackoff
+		}
+	}
+
+	ticker := time.NewTicker(s.scfg.HealthInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.Check(ctx)
+		}
+	}
+}
+
+// Connect makes one connection attempt unless a client is already connected,
+// e.g. while startup waits for Neo4j
+func (s *Supervisor) Connect(ctx context.Context) error {
+	if s.Client() != nil {
+		return nil
+	}
+	return s.connect(ctx)
+}
+
+// connect makes one connection attempt, running the OnConnect callbacks on success
+func (s *Supervisor) connect(ctx context.Context) error {
+	dialCtx, cancel := context.WithTimeout(ctx, s.scfg.HealthTimeout)
+	client, err := s.dial(dialCtx, s.cfg)
+	cancel()
+
+	s.mu.Lock()
+	if err != nil {
+		s.setHealthLocked(err)
+		attempts := s.health.Attempts
+		s.mu.Unlock()
+		if attempts == 1 || attempts%10 == 0 {
+			log.Printf("⚠️  Neo4j still unavailable after %d attempts: %v", attempts, err)
+		}
+		return err
+	}
+	s.client = client
+	s.setHealthLocked(nil)
+	callbacks := make([]func(*Client), len(s.onConnect))
+	copy(callbacks, s.onConnect)
+	s.mu.Unlock()
+
+	log.Println("✅ Neo4j connection established")
+	for _, fn := range callbacks {
+		fn(client)
+	}
+	return nil
+}
+
+// Check verifies connectivity of the connected client and updates the health
+func (s *Supervisor) Check(ctx context.Context) {
+	client := s.Client()
+	if client == nil {
+		return
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, s.scfg.HealthTimeout)
+	err := s.verify(checkCtx, client)
+	cancel()
+
+	s.mu.Lock()
+	wasUp := s.health.Connected
+	s.setHealthLocked(err)
+	s.mu.Unlock()
+
+	switch {
+	case err != nil && wasUp:
+		log.Printf("⚠️  Neo4j connectivity lost: %v", err)
+	case err == nil && !wasUp:
+		log.Println("💚 Neo4j connectivity restored")
+	}
+}
+
+// setHealthLocked records the outcome of an attempt or check
+func (s *Supervisor) setHealthLocked(err error) {
+	now := time.Now()
+	connected := err == nil
+	if connected != s.health.Connected {
+		s.health.Since = now
+	}
+	s.health.Connected = connected
+	s.health.LastCheck = now
+	if connected {
+		s.health.Status, s.health.LastError, s.health.Attempts = "up", "", 0
+		return
+	}
+	s.health.Status, s.health.LastError = "down", err.Error()
+	s.health.Attempts++
+}
+
+// dial makes a single connection attempt
+func dial(ctx context.Context, cfg *Config) (*Client, error) {
+	driver, err := neo4j.NewDriverWithContext(cfg.URI, neo4j.BasicAuth(cfg.Username, cfg.Password, ""))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Neo4j driver: %w", err)
+	}
+	if err := driver.VerifyConnectivity(ctx); err != nil {
+		driver.Close(ctx)
+		return nil, fmt.Errorf("failed to connect to Neo4j: %w", err)
+	}
+	return &Client{driver: driver, database: cfg.Database}, nil
+}
@@ -0,0 +1,91 @@
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testSupervisor() *Supervisor {
+	return NewSupervisor(DefaultConfig(), &SupervisorConfig{
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+		HealthInterval: 5 * time.Millisecond,
+		HealthTimeout:  time.Second,
+	})
+}
+
+// TestSupervisorConnectsAfterBootFailure verifies the supervisor retries
+// until Neo4j is reachable and hands the client to OnConnect callbacks once
+func TestSupervisorConnectsAfterBootFailure(t *testing.T) {
+	s := testSupervisor()
+	var attempts atomic.Int32
+	s.dial = func(ctx context.Context, cfg *Config) (*Client, error) {
+		if attempts.Add(1) < 3 {
+			return nil, errors.New("connection refused")
+		}
+		return &Client{database: cfg.Database}, nil
+	}
+	s.verify = func(ctx context.Context, c *Client) error { return nil }
+
+	connected := make(chan *Client, 2)
+	s.OnConnect(func(c *Client) { connected <- c })
+	if h := s.Health(); h.Connected || h.Status != "down" {
+		t.Fatalf("expected down before connecting, got %+v", h)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Start(ctx)
+
+	select {
+	case c := <-connected:
+		if c != s.Client() {
+			t.Error("callback client differs from the supervisor's")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("supervisor never connected")
+	}
+	if h := s.Health(); !h.Connected || h.Status != "up" || h.Attempts != 0 {
+		t.Errorf("expected up after connecting, got %+v", h)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if len(connected) != 0 || attempts.Load() != 3 {
+		t.Errorf("expected a single connection after 3 attempts, got %d attempts", attempts.Load())
+	}
+}
+
+// TestSupervisorReportsLostConnectivity verifies health checks track a
+// connected client going down and recovering without redialing
+func TestSupervisorReportsLostConnectivity(t *testing.T) {
+	s := testSupervisor()
+	s.dial = func(ctx context.Context, cfg *Config) (*Client, error) {
+		t.Error("a supervisor with a client must not redial")
+		return nil, errors.New("unexpected dial")
+	}
+	var down atomic.Bool
+	s.verify = func(ctx context.Context, c *Client) error {
+		if down.Load() {
+			return errors.New("routing table unavailable")
+		}
+		return nil
+	}
+	s.SetClient(&Client{})
+
+	ctx := context.Background()
+	down.Store(true)
+	s.Check(ctx)
+	s.Check(ctx)
+	if h := s.Health(); h.Connected || h.Attempts != 2 || h.LastError != "routing table unavailable" {
+		t.Errorf("expected down after failed checks, got %+v", h)
+	}
+
+	down.Store(false)
+	s.Check(ctx)
+	if h := s.Health(); !h.Connected || h.LastError != "" || h.Attempts != 0 {
+		t.Errorf("expected up after recovery, got %+v", h)
+	}
+}
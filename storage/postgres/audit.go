@@ -0,0 +1,145 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/plm/predictive-liquidity-mesh/audit"
+)
+
+// AuditStore persists the admin audit trail in the audit_log table
+type AuditStore struct {
+	client *Client
+}
+
+// NewAuditStore creates a Postgres-backed audit store
+func NewAuditStore(client *Client) *AuditStore {
+	return &AuditStore{client: client}
+}
+
+// Record inserts an entry, assigning its ID
+func (s *AuditStore) Record(ctx context.Context, e *audit.Entry) error {
+	query := `
+		INSERT INTO audit_log (
+			timestamp, user_id, actor_email, action, resource_type, resource_id,
+			before_state, after_state, path, status, request_id, ip_address, user_agent, success
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING id
+	`
+
+	var id int64
+	err := s.client.db.QueryRowContext(ctx, query,
+		e.Timestamp, nullString(e.ActorID), nullString(e.ActorEmail), e.Action, nullString(e.ResourceType), nullString(e.ResourceID),
+		nullJSON(e.Before), nullJSON(e.After), nullString(e.Target), e.Status, nullString(e.RequestID), nullString(remoteIP(e.RemoteAddr)),
+		nullString(e.UserAgent), e.Status < 400,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to record audit entry: %w", err)
+	}
+	e.ID = strconv.FormatInt(id, 10)
+	return nil
+}
+
+// List returns entries matching f, newest first
+func (s *AuditStore) List(ctx context.Context, f audit.Filter) ([]*audit.Entry, error) {
+	var where []string
+	var args []interface{}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if f.Actor != "" {
+		p := arg(f.Actor)
+		where = append(where, "(user_id = "+p+" OR LOWER(actor_email) = LOWER("+p+"))")
+	}
+	if strings.HasSuffix(f.Action, ".") {
+		where = append(where, "action LIKE "+arg(f.Action+"%"))
+	} else if f.Action != "" {
+		where = append(where, "action = "+arg(f.Action))
+	}
+	if f.ResourceType != "" {
+		where = append(where, "resource_type = "+arg(f.ResourceType))
+	}
+	if f.ResourceID != "" {
+		where = append(where, "resource_id = "+arg(f.ResourceID))
+	}
+	if !f.Since.IsZero() {
+		where = append(where, "timestamp >= "+arg(f.Since))
+	}
+	if !f.Until.IsZero() {
+		where = append(where, "timestamp < "+arg(f.Until))
+	}
+
+	limit := f.Limit
+	if limit <= 0 {
+		limit = audit.DefaultCapacity
+	}
+
+	query := `
+		SELECT id, timestamp, COALESCE(user_id, ''), COALESCE(actor_email, ''), action,
+			COALESCE(resource_type, ''), COALESCE(resource_id, ''), before_state, after_state,
+			COALESCE(path, ''), COALESCE(status, 0), COALESCE(request_id, ''),
+			COALESCE(host(ip_address), ''), COALESCE(user_agent, '')
+		FROM audit_log`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY id DESC LIMIT " + arg(limit)
+
+	rows, err := s.client.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]*audit.Entry, 0)
+	for rows.Next() {
+		var e audit.Entry
+		var id int64
+		var before, after []byte
+		err := rows.Scan(
+			&id, &e.Timestamp, &e.ActorID, &e.ActorEmail, &e.Action,
+			&e.ResourceType, &e.ResourceID, &before, &after,
+			&e.Target, &e.Status, &e.RequestID,
+			&e.RemoteAddr, &e.UserAgent,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit entry: %w", err)
+		}
+		e.ID = strconv.FormatInt(id, 10)
+		e.Before, e.After = before, after
+		entries = append(entries, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// nullJSON converts an absent JSON document to SQL NULL
+func nullJSON(data []byte) interface{} {
+	if len(data) == 0 {
+		return nil
+	}
+	return string(data)
+}
+
+// remoteIP strips the port from a request's remote address for the INET column,
+// returning "" if what remains is not an IP address
+func remoteIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	if net.ParseIP(addr) == nil {
+		return ""
+	}
+	return addr
+}
+
+// Compile-time interface check
+var _ audit.Store = (*AuditStore)(nil)
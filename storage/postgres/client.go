@@ -9,6 +9,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strconv"
 	"sync"
 
 	_ "github.com/lib/pq"
@@ -42,6 +44,30 @@ func DefaultConfig() *Config {
 	}
 }
 
+// ConfigFromEnv returns the default configuration overridden by
+// POSTGRES_HOST, POSTGRES_PORT, POSTGRES_USER, POSTGRES_PASSWORD and POSTGRES_DB
+func ConfigFromEnv() *Config {
+	cfg := DefaultConfig()
+	if v := os.Getenv("POSTGRES_HOST"); v != "" {
+		cfg.Host = v
+	}
+	if v := os.Getenv("POSTGRES_PORT"); v != "" {
+		if port, err := strconv.Atoi(v); err == nil {
+			cfg.Port = port
+		}
+	}
+	if v := os.Getenv("POSTGRES_USER"); v != "" {
+		cfg.User = v
+	}
+	if v := os.Getenv("POSTGRES_PASSWORD"); v != "" {
+		cfg.Password = v
+	}
+	if v := os.Getenv("POSTGRES_DB"); v != "" {
+		cfg.Database = v
+	}
+	return cfg
+}
+
 // Client wraps PostgreSQL connection with ledger operations
 type Client struct {
 	db *sql.DB
@@ -66,6 +92,7 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 
 	// Verify connection
 	if err := db.PingContext(ctx); err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
@@ -80,6 +107,7 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 	}
 	_, err = db.ExecContext(ctx, setSyncQuery)
 	if err != nil {
+		db.Close()
 		return nil, fmt.Errorf("failed to set synchronous_commit: %w", err)
 	}
 
@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	neo4jstore "github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+)
+
+// CredibilityHistoryStore persists credibility changes in the credibility_history table
+type CredibilityHistoryStore struct {
+	client *Client
+}
+
+// NewCredibilityHistoryStore creates a Postgres-backed credibility history store
+func NewCredibilityHistoryStore(client *Client) *CredibilityHistoryStore {
+	return &CredibilityHistoryStore{client: client}
+}
+
+// Append inserts one row per change
+func (s *CredibilityHistoryStore) Append(ctx context.Context, points []neo4jstore.CredibilityPoint) error {
+	tx, err := s.client.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO credibility_history (country_code, credibility, change, reason, successes, failures, recorded_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare credibility history insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, p := range points {
+		if _, err := stmt.ExecContext(ctx, p.Code, p.Credibility, p.Change, p.Reason, p.Successes, p.Failures, p.At); err != nil {
+			return fmt.Errorf("failed to record %s credibility: %w", p.Code, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit credibility history: %w", err)
+	}
+	return nil
+}
+
+// History returns a country's changes recorded at or after since, oldest first
+func (s *CredibilityHistoryStore) History(ctx context.Context, code string, since time.Time) ([]neo4jstore.CredibilityPoint, error) {
+	rows, err := s.client.db.QueryContext(ctx, `
+		SELECT credibility, change, reason, successes, failures, recorded_at FROM credibility_history
+		WHERE country_code = $1 AND recorded_at >= $2
+		ORDER BY recorded_at ASC
+	`, code, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credibility history: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]neo4jstore.CredibilityPoint, 0)
+	for rows.Next() {
+		p := neo4jstore.CredibilityPoint{Code: code}
+		if err := rows.Scan(&p.Credibility, &p.Change, &p.Reason, &p.Successes, &p.Failures, &p.At); err != nil {
+			return nil, fmt.Errorf("failed to scan credibility history: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
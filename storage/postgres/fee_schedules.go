@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// FeeScheduleStore persists versioned fee schedules in the fee_schedules table
+type FeeScheduleStore struct {
+	client *Client
+}
+
+// NewFeeScheduleStore creates a Postgres-backed fee schedule store
+func NewFeeScheduleStore(client *Client) *FeeScheduleStore {
+	return &FeeScheduleStore{client: client}
+}
+
+const feeScheduleColumns = `version, base_fee_percent, hop_fee_percent, halt_fine_percent, created_at, COALESCE(created_by, '')`
+
+// Current returns the latest schedule, or nil if none has been published
+func (s *FeeScheduleStore) Current(ctx context.Context) (*payments.FeeSchedule, error) {
+	row := s.client.db.QueryRowContext(ctx, `
+		SELECT `+feeScheduleColumns+` FROM fee_schedules
+		ORDER BY version DESC LIMIT 1
+	`)
+	schedule, err := scanFeeSchedule(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fee schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// Publish saves fees as the next version
+func (s *FeeScheduleStore) Publish(ctx context.Context, fees payments.FeeConfig, createdBy string) (*payments.FeeSchedule, error) {
+	if err := fees.Validate(); err != nil {
+		return nil, err
+	}
+	row := s.client.db.QueryRowContext(ctx, `
+		INSERT INTO fee_schedules (version, base_fee_percent, hop_fee_percent, halt_fine_percent, created_by)
+		SELECT COALESCE(MAX(version), 0) + 1, $1, $2, $3, $4 FROM fee_schedules
+		RETURNING `+feeScheduleColumns,
+		fees.BaseFeePercent, fees.HopFeePercent, fees.HaltFinePercent, nullString(createdBy),
+	)
+	schedule, err := scanFeeSchedule(row)
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish fee schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// List returns up to limit schedules, newest first
+func (s *FeeScheduleStore) List(ctx context.Context, limit int) ([]*payments.FeeSchedule, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.client.db.QueryContext(ctx, `
+		SELECT `+feeScheduleColumns+` FROM fee_schedules
+		ORDER BY version DESC LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fee schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]*payments.FeeSchedule, 0)
+	for rows.Next() {
+		schedule, err := scanFeeSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan fee schedule: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, rows.Err()
+}
+
+// scanner is implemented by *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanFeeSchedule(row scanner) (*payments.FeeSchedule, error) {
+	var schedule payments.FeeSchedule
+	err := row.Scan(&schedule.Version, &schedule.BaseFeePercent, &schedule.HopFeePercent,
+		&schedule.HaltFinePercent, &schedule.CreatedAt, &schedule.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &schedule, nil
+}
+
+// Compile-time interface check
+var _ payments.FeeScheduleStore = (*FeeScheduleStore)(nil)
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/workers/fxrates"
+)
+
+// FXHistoryStore persists fetched FX rate snapshots in the fx_rate_history table
+type FXHistoryStore struct {
+	client *Client
+}
+
+// NewFXHistoryStore creates a Postgres-backed FX history store
+func NewFXHistoryStore(client *Client) *FXHistoryStore {
+	return &FXHistoryStore{client: client}
+}
+
+// Append inserts one row per currency in u
+func (s *FXHistoryStore) Append(ctx context.Context, u *fxrates.Update) error {
+	tx, err := s.client.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO fx_rate_history (currency, base, rate, provider, fetched_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare fx history insert: %w", err)
+	}
+	defer stmt.Close()
+
+	for currency, rate := range u.Rates {
+		if rate <= 0 {
+			continue
+		}
+		if _, err := stmt.ExecContext(ctx, currency, u.Base, rate, nullString(u.Provider), u.FetchedAt); err != nil {
+			return fmt.Errorf("failed to record %s rate: %w", currency, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit fx history: %w", err)
+	}
+	return nil
+}
+
+// History returns currency's rates fetched at or after since, oldest first
+func (s *FXHistoryStore) History(ctx context.Context, currency string, since time.Time) ([]fxrates.Point, error) {
+	rows, err := s.client.db.QueryContext(ctx, `
+		SELECT rate, fetched_at FROM fx_rate_history
+		WHERE currency = $1 AND fetched_at >= $2
+		ORDER BY fetched_at ASC
+	`, currency, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fx history: %w", err)
+	}
+	defer rows.Close()
+
+	points := make([]fxrates.Point, 0)
+	for rows.Next() {
+		var p fxrates.Point
+		if err := rows.Scan(&p.Rate, &p.At); err != nil {
+			return nil, fmt.Errorf("failed to scan fx history: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
@@ -0,0 +1,103 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+// SetLedgerSigner enables ledger writes: every successful settlement is
+// appended to the hash-chained ledger, signed with sign (the receipt signature)
+func (s *TransactionStore) SetLedgerSigner(sign func(txn *payments.Transaction) string) {
+	s.sign = sign
+}
+
+// appendLedger records a settled transaction in the ledger, logging (not
+// returning) failures since the payment has already completed
+func (s *TransactionStore) appendLedger(ctx context.Context, txnID string) {
+	if s.sign == nil {
+		return
+	}
+	txn, err := s.Snapshot(txnID)
+	if err != nil || txn.Status != payments.StatusSuccess {
+		return
+	}
+	if txn.NettingSetID != "" {
+		return // Recorded with its netting set by appendNettingLedger
+	}
+
+	ctx, cancel := context.WithTimeout(logging.Detach(ctx), s.timeout)
+	defer cancel()
+
+	entry, err := s.client.InsertLedgerEntry(ctx, minorUnits(txn.Amount), txn.Route, s.sign(txn), ledgerMetadata(txn))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to write ledger entry", "transaction_id", txnID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "ledger entry written", "transaction_id", txnID, "ledger_id", entry.ID, "sequence_num", entry.SequenceNum)
+}
+
+// appendNettingLedger records a settled netting set as a single ledger entry
+// for its net amount, logging (not returning) failures
+func (s *TransactionStore) appendNettingLedger(ctx context.Context, set *payments.NettingSet) {
+	if s.sign == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(logging.Detach(ctx), s.timeout)
+	defer cancel()
+
+	path := set.Route
+	if len(path) == 0 {
+		path = []string{set.Source, set.Target}
+	}
+	// The set is signed like a transaction of its net amount
+	signed := &payments.Transaction{
+		ID:        set.ID,
+		UserID:    payments.NettingUserID,
+		Amount:    math.Abs(set.Net),
+		Currency:  set.Currency,
+		CreatedAt: set.CreatedAt,
+	}
+	entry, err := s.client.InsertLedgerEntry(ctx, minorUnits(signed.Amount), path, s.sign(signed), nettingMetadata(set))
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to write netting ledger entry", "netting_set_id", set.ID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "netting ledger entry written", "netting_set_id", set.ID, "ledger_id", entry.ID, "sequence_num", entry.SequenceNum)
+}
+
+// minorUnits converts an amount to the smallest currency unit stored in the ledger
+func minorUnits(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+// ledgerMetadata is the transaction context stored alongside a ledger entry
+func ledgerMetadata(txn *payments.Transaction) map[string]interface{} {
+	return map[string]interface{}{
+		"transaction_id":  txn.ID,
+		"user_id":         txn.UserID,
+		"currency":        txn.Currency,
+		"target_currency": txn.TargetCurrency,
+		"total_fees":      fmt.Sprintf("%.2f", txn.TotalFees),
+		"final_amount":    fmt.Sprintf("%.2f", txn.FinalAmount),
+	}
+}
+
+// nettingMetadata is the netting set stored alongside its ledger entry
+func nettingMetadata(set *payments.NettingSet) map[string]interface{} {
+	return map[string]interface{}{
+		"netting_set_id":     set.ID,
+		"transaction_ids":    set.TransactionIDs,
+		"net_transaction_id": set.NetTransactionID,
+		"corridor":           set.Source + "-" + set.Target,
+		"currency":           set.Currency,
+		"gross":              fmt.Sprintf("%.2f", set.Gross),
+		"net":                fmt.Sprintf("%.2f", set.Net),
+		"fx_rate":            set.FXRate,
+	}
+}
@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/plm/predictive-liquidity-mesh/notifications"
+)
+
+// NotificationPreferenceStore persists notification preferences in the
+// notification_preferences table
+type NotificationPreferenceStore struct {
+	client *Client
+}
+
+// NewNotificationPreferenceStore creates a Postgres-backed preference store
+func NewNotificationPreferenceStore(client *Client) *NotificationPreferenceStore {
+	return &NotificationPreferenceStore{client: client}
+}
+
+// Get returns userID's preferences, or the defaults if none were saved
+func (s *NotificationPreferenceStore) Get(ctx context.Context, userID string) (*notifications.Preferences, error) {
+	prefs := notifications.Preferences{UserID: userID}
+	err := s.client.db.QueryRowContext(ctx, `
+		SELECT email, payment_succeeded, payment_refunded, attach_receipts, updated_at
+		FROM notification_preferences WHERE user_id = $1
+	`, userID).Scan(&prefs.Email, &prefs.PaymentSucceeded, &prefs.PaymentRefunded, &prefs.AttachReceipts, &prefs.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return notifications.DefaultPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification preferences: %w", err)
+	}
+	return &prefs, nil
+}
+
+// Save upserts prefs, stamping UpdatedAt
+func (s *NotificationPreferenceStore) Save(ctx context.Context, prefs *notifications.Preferences) error {
+	err := s.client.db.QueryRowContext(ctx, `
+		INSERT INTO notification_preferences (user_id, email, payment_succeeded, payment_refunded, attach_receipts)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			email = EXCLUDED.email,
+			payment_succeeded = EXCLUDED.payment_succeeded,
+			payment_refunded = EXCLUDED.payment_refunded,
+			attach_receipts = EXCLUDED.attach_receipts,
+			updated_at = NOW()
+		RETURNING updated_at
+	`, prefs.UserID, prefs.Email, prefs.PaymentSucceeded, prefs.PaymentRefunded, prefs.AttachReceipts).Scan(&prefs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
+
+// Compile-time interface check
+var _ notifications.PreferenceStore = (*NotificationPreferenceStore)(nil)
@@ -0,0 +1,140 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/plm/predictive-liquidity-mesh/organizations"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// OrganizationStore persists organizations in the organizations and
+// organization_members tables. The embedded in-memory store serves reads
+// (including the OrgTerms lookup on every new transaction); changes are
+// written through and the tables are loaded back into memory on startup.
+type OrganizationStore struct {
+	*organizations.MemoryStore
+	client *Client
+}
+
+// NewOrganizationStore creates a Postgres-backed organization store and loads existing rows
+func NewOrganizationStore(ctx context.Context, client *Client) (*OrganizationStore, error) {
+	store := &OrganizationStore{MemoryStore: organizations.NewMemoryStore(), client: client}
+
+	rows, err := client.db.QueryContext(ctx, `
+		SELECT id, name, fee_override, monthly_spend_limit, created_at, updated_at FROM organizations
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organizations: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var org organizations.Organization
+		var feeOverride []byte
+		if err := rows.Scan(&org.ID, &org.Name, &feeOverride, &org.MonthlySpendLimit, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization: %w", err)
+		}
+		if err := unmarshalJSONColumns(feeOverride, &org.FeeOverride); err != nil {
+			return nil, fmt.Errorf("failed to decode organization %s: %w", org.ID, err)
+		}
+		store.RestoreOrg(&org)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate organizations: %w", err)
+	}
+
+	members, err := client.db.QueryContext(ctx, `
+		SELECT org_id, user_id, role, joined_at FROM organization_members
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query organization members: %w", err)
+	}
+	defer members.Close()
+	for members.Next() {
+		var m organizations.Member
+		var role string
+		if err := members.Scan(&m.OrgID, &m.UserID, &role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan organization member: %w", err)
+		}
+		m.Role = organizations.Role(role)
+		store.RestoreMember(&m)
+	}
+	if err := members.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate organization members: %w", err)
+	}
+
+	return store, nil
+}
+
+// Create stores a new organization and persists it
+func (s *OrganizationStore) Create(ctx context.Context, org *organizations.Organization) error {
+	if err := s.MemoryStore.Create(ctx, org); err != nil {
+		return err
+	}
+	return s.saveOrg(ctx, org)
+}
+
+// Update replaces an organization's settings and persists them
+func (s *OrganizationStore) Update(ctx context.Context, org *organizations.Organization) error {
+	if err := s.MemoryStore.Update(ctx, org); err != nil {
+		return err
+	}
+	return s.saveOrg(ctx, org)
+}
+
+// AddMember adds or re-roles a member and persists the membership
+func (s *OrganizationStore) AddMember(ctx context.Context, m *organizations.Member) error {
+	if err := s.MemoryStore.AddMember(ctx, m); err != nil {
+		return err
+	}
+	_, err := s.client.db.ExecContext(ctx, `
+		INSERT INTO organization_members (user_id, org_id, role, joined_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE SET role = EXCLUDED.role
+	`, m.UserID, m.OrgID, string(m.Role), m.JoinedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save organization member: %w", err)
+	}
+	return nil
+}
+
+// RemoveMember removes a member and deletes the membership row
+func (s *OrganizationStore) RemoveMember(ctx context.Context, orgID, userID string) error {
+	if err := s.MemoryStore.RemoveMember(ctx, orgID, userID); err != nil {
+		return err
+	}
+	_, err := s.client.db.ExecContext(ctx, `
+		DELETE FROM organization_members WHERE org_id = $1 AND user_id = $2
+	`, orgID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete organization member: %w", err)
+	}
+	return nil
+}
+
+func (s *OrganizationStore) saveOrg(ctx context.Context, org *organizations.Organization) error {
+	feeOverride, err := json.Marshal(org.FeeOverride)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fee override: %w", err)
+	}
+	_, err = s.client.db.ExecContext(ctx, `
+		INSERT INTO organizations (id, name, fee_override, monthly_spend_limit, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			fee_override = EXCLUDED.fee_override,
+			monthly_spend_limit = EXCLUDED.monthly_spend_limit,
+			updated_at = EXCLUDED.updated_at
+	`, org.ID, org.Name, feeOverride, org.MonthlySpendLimit, org.CreatedAt, org.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save organization: %w", err)
+	}
+	return nil
+}
+
+// Compile-time interface checks
+var (
+	_ organizations.Store  = (*OrganizationStore)(nil)
+	_ payments.OrgResolver = (*OrganizationStore)(nil)
+)
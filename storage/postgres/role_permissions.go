@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+// RolePermissionStore persists role permission grants in the role_permissions table
+type RolePermissionStore struct {
+	client *Client
+}
+
+// NewRolePermissionStore creates a Postgres-backed role permission store
+func NewRolePermissionStore(client *Client) *RolePermissionStore {
+	return &RolePermissionStore{client: client}
+}
+
+// LoadGrants returns the saved grants; roles never saved are absent
+func (s *RolePermissionStore) LoadGrants(ctx context.Context) (map[auth.Role][]auth.Permission, error) {
+	rows, err := s.client.db.QueryContext(ctx, `SELECT role, permissions FROM role_permissions`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query role permissions: %w", err)
+	}
+	defer rows.Close()
+
+	grants := make(map[auth.Role][]auth.Permission)
+	for rows.Next() {
+		var role string
+		var names []string
+		if err := rows.Scan(&role, pq.Array(&names)); err != nil {
+			return nil, fmt.Errorf("failed to scan role permissions: %w", err)
+		}
+		perms := make([]auth.Permission, len(names))
+		for i, name := range names {
+			perms[i] = auth.Permission(name)
+		}
+		grants[auth.Role(role)] = perms
+	}
+	return grants, rows.Err()
+}
+
+// SaveGrants upserts the permissions of role
+func (s *RolePermissionStore) SaveGrants(ctx context.Context, role auth.Role, perms []auth.Permission, updatedBy string) error {
+	names := make([]string, len(perms))
+	for i, perm := range perms {
+		names[i] = string(perm)
+	}
+	_, err := s.client.db.ExecContext(ctx, `
+		INSERT INTO role_permissions (role, permissions, updated_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (role) DO UPDATE SET
+			permissions = EXCLUDED.permissions,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+	`, string(role), pq.Array(names), nullString(updatedBy))
+	if err != nil {
+		return fmt.Errorf("failed to save role permissions: %w", err)
+	}
+	return nil
+}
+
+// Compile-time interface check
+var _ auth.PolicyStore = (*RolePermissionStore)(nil)
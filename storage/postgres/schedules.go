@@ -0,0 +1,122 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments/scheduler"
+)
+
+// ScheduleStore persists payment schedules in PostgreSQL
+type ScheduleStore struct {
+	client *Client
+}
+
+// NewScheduleStore creates a Postgres-backed schedule store
+func NewScheduleStore(client *Client) *ScheduleStore {
+	return &ScheduleStore{client: client}
+}
+
+const scheduleColumns = `
+	id, user_id, source, target, amount, COALESCE(currency, ''), COALESCE(target_currency, ''),
+	frequency, start_at, end_at, status, next_run_at, occurrence, run_count,
+	last_run_at, COALESCE(last_transaction_id, ''), COALESCE(last_error, ''), created_at, updated_at
+`
+
+// Save upserts a schedule
+func (s *ScheduleStore) Save(ctx context.Context, sched *scheduler.Schedule) error {
+	query := `
+		INSERT INTO scheduled_payments (
+			id, user_id, source, target, amount, currency, target_currency,
+			frequency, start_at, end_at, status, next_run_at, occurrence, run_count,
+			last_run_at, last_transaction_id, last_error, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+		ON CONFLICT (id) DO UPDATE SET
+			status = EXCLUDED.status,
+			next_run_at = EXCLUDED.next_run_at,
+			occurrence = EXCLUDED.occurrence,
+			run_count = EXCLUDED.run_count,
+			last_run_at = EXCLUDED.last_run_at,
+			last_transaction_id = EXCLUDED.last_transaction_id,
+			last_error = EXCLUDED.last_error,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err := s.client.db.ExecContext(ctx, query,
+		sched.ID, sched.UserID, sched.Source, sched.Target, sched.Amount, nullString(sched.Currency), nullString(sched.TargetCurrency),
+		string(sched.Frequency), sched.StartAt, sched.EndAt, string(sched.Status), sched.NextRunAt, sched.Occurrence, sched.RunCount,
+		sched.LastRunAt, nullString(sched.LastTransactionID), nullString(sched.LastError), sched.CreatedAt, sched.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return nil
+}
+
+// Get returns a schedule by ID
+func (s *ScheduleStore) Get(ctx context.Context, id string) (*scheduler.Schedule, error) {
+	rows, err := s.query(ctx, `SELECT `+scheduleColumns+` FROM scheduled_payments WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, scheduler.ErrNotFound
+	}
+	return rows[0], nil
+}
+
+// ListByUser returns a user's schedules, newest first
+func (s *ScheduleStore) ListByUser(ctx context.Context, userID string) ([]*scheduler.Schedule, error) {
+	return s.query(ctx, `SELECT `+scheduleColumns+` FROM scheduled_payments WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+}
+
+// Due returns active schedules whose next run is at or before now, oldest first
+func (s *ScheduleStore) Due(ctx context.Context, now time.Time) ([]*scheduler.Schedule, error) {
+	return s.query(ctx, `SELECT `+scheduleColumns+` FROM scheduled_payments WHERE status = 'active' AND next_run_at <= $1 ORDER BY next_run_at ASC`, now)
+}
+
+func (s *ScheduleStore) query(ctx context.Context, query string, args ...interface{}) ([]*scheduler.Schedule, error) {
+	rows, err := s.client.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schedules: %w", err)
+	}
+	defer rows.Close()
+
+	schedules := make([]*scheduler.Schedule, 0)
+	for rows.Next() {
+		var sched scheduler.Schedule
+		var frequency, status string
+		var endAt, lastRunAt sql.NullTime
+
+		err := rows.Scan(
+			&sched.ID, &sched.UserID, &sched.Source, &sched.Target, &sched.Amount, &sched.Currency, &sched.TargetCurrency,
+			&frequency, &sched.StartAt, &endAt, &status, &sched.NextRunAt, &sched.Occurrence, &sched.RunCount,
+			&lastRunAt, &sched.LastTransactionID, &sched.LastError, &sched.CreatedAt, &sched.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan schedule: %w", err)
+		}
+
+		sched.Frequency = scheduler.Frequency(frequency)
+		sched.Status = scheduler.Status(status)
+		if endAt.Valid {
+			t := endAt.Time
+			sched.EndAt = &t
+		}
+		if lastRunAt.Valid {
+			t := lastRunAt.Time
+			sched.LastRunAt = &t
+		}
+		schedules = append(schedules, &sched)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate schedules: %w", err)
+	}
+	return schedules, nil
+}
+
+// Compile-time interface check
+var _ scheduler.Store = (*ScheduleStore)(nil)
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/plm/predictive-liquidity-mesh/limits"
+)
+
+// SpendingLimitStore persists limit rules in the spending_limits table
+type SpendingLimitStore struct {
+	client *Client
+}
+
+// NewSpendingLimitStore creates a Postgres-backed limit rule store
+func NewSpendingLimitStore(client *Client) *SpendingLimitStore {
+	return &SpendingLimitStore{client: client}
+}
+
+const spendingLimitColumns = `scope, subject_id, per_transaction, daily, monthly, COALESCE(updated_by, ''), updated_at`
+
+// Get returns the rule for a subject, or nil if none is configured
+func (s *SpendingLimitStore) Get(ctx context.Context, scope limits.Scope, subjectID string) (*limits.Rule, error) {
+	row := s.client.db.QueryRowContext(ctx, `
+		SELECT `+spendingLimitColumns+` FROM spending_limits
+		WHERE scope = $1 AND subject_id = $2
+	`, string(scope), subjectID)
+	rule, err := scanSpendingLimit(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spending limit: %w", err)
+	}
+	return rule, nil
+}
+
+// List returns every configured rule, by scope and subject
+func (s *SpendingLimitStore) List(ctx context.Context) ([]*limits.Rule, error) {
+	rows, err := s.client.db.QueryContext(ctx, `
+		SELECT `+spendingLimitColumns+` FROM spending_limits
+		ORDER BY scope, subject_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query spending limits: %w", err)
+	}
+	defer rows.Close()
+
+	rules := make([]*limits.Rule, 0)
+	for rows.Next() {
+		rule, err := scanSpendingLimit(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan spending limit: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+// Set creates or replaces a rule
+func (s *SpendingLimitStore) Set(ctx context.Context, rule *limits.Rule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+	row := s.client.db.QueryRowContext(ctx, `
+		INSERT INTO spending_limits (scope, subject_id, per_transaction, daily, monthly, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (scope, subject_id) DO UPDATE SET
+			per_transaction = EXCLUDED.per_transaction,
+			daily = EXCLUDED.daily,
+			monthly = EXCLUDED.monthly,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, string(rule.Scope), rule.SubjectID, rule.PerTransaction, rule.Daily, rule.Monthly, nullString(rule.UpdatedBy))
+	if err := row.Scan(&rule.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save spending limit: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a rule
+func (s *SpendingLimitStore) Delete(ctx context.Context, scope limits.Scope, subjectID string) error {
+	_, err := s.client.db.ExecContext(ctx, `
+		DELETE FROM spending_limits WHERE scope = $1 AND subject_id = $2
+	`, string(scope), subjectID)
+	if err != nil {
+		return fmt.Errorf("failed to delete spending limit: %w", err)
+	}
+	return nil
+}
+
+func scanSpendingLimit(row scanner) (*limits.Rule, error) {
+	var rule limits.Rule
+	var scope string
+	if err := row.Scan(&scope, &rule.SubjectID, &rule.PerTransaction, &rule.Daily, &rule.Monthly, &rule.UpdatedBy, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	rule.Scope = limits.Scope(scope)
+	return &rule, nil
+}
+
+// Compile-time interface check
+var _ limits.Store = (*SpendingLimitStore)(nil)
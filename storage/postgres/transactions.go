@@ -0,0 +1,533 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/pkg/logging"
+)
+
+// TransactionStore persists payment transactions in PostgreSQL.
+// The embedded in-memory store owns the mesh simulation and serves reads;
+// every state change is written through to the transactions table and the
+// table is loaded back into memory on startup.
+type TransactionStore struct {
+	*payments.TransactionStore
+	client  *Client
+	timeout time.Duration
+	sign    func(txn *payments.Transaction) string // Ledger signer; nil = no ledger writes
+}
+
+// NewTransactionStore creates a Postgres-backed transaction store and loads existing rows
+func NewTransactionStore(ctx context.Context, client *Client) (*TransactionStore, error) {
+	store := &TransactionStore{
+		TransactionStore: payments.NewTransactionStore(),
+		client:           client,
+		timeout:          5 * time.Second,
+	}
+
+	txns, err := client.LoadTransactions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load transactions: %w", err)
+	}
+	for _, txn := range txns {
+		store.Restore(txn)
+	}
+
+	return store, nil
+}
+
+// CreateTransaction creates a new pending transaction and persists it
+func (s *TransactionStore) CreateTransaction(userID string, amount float64, currency, targetCurrency string, route []string, haltedNodes map[string]bool) (*payments.Transaction, error) {
+	txn, err := s.TransactionStore.CreateTransaction(userID, amount, currency, targetCurrency, route, haltedNodes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.persist(context.Background(), txn.ID); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// CreateQuotedTransaction creates a pending transaction from a quote and persists it
+func (s *TransactionStore) CreateQuotedTransaction(userID string, q *payments.Quote, haltedNodes map[string]bool) (*payments.Transaction, error) {
+	txn, err := s.TransactionStore.CreateQuotedTransaction(userID, q, haltedNodes)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.persist(context.Background(), txn.ID); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// CreateBatch creates a batch of pending transactions, persisting them in a
+// single database transaction before they become visible
+func (s *TransactionStore) CreateBatch(userID string, items []payments.BatchItem, haltedNodes map[string]bool) (*payments.Batch, error) {
+	txns, err := s.PrepareBatch(userID, items, haltedNodes)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if err := s.client.SaveTransactions(ctx, txns); err != nil {
+		return nil, err
+	}
+
+	s.AddBatch(txns)
+	return s.GetBatch(txns[0].BatchID)
+}
+
+// ProcessTransaction runs the mesh flow, persists the outcome and records a
+// successful settlement in the ledger
+func (s *TransactionStore) ProcessTransaction(ctx context.Context, txnID string, fxRates map[string]float64, failureChance float64) error {
+	err := s.TransactionStore.ProcessTransaction(ctx, txnID, fxRates, failureChance)
+	s.persistLogged(ctx, txnID)
+	if err == nil {
+		s.appendLedger(ctx, txnID)
+	}
+	return err
+}
+
+// ProcessTransactionWithRoute runs the mesh flow on a specific route, persists
+// the outcome and records a successful settlement in the ledger
+func (s *TransactionStore) ProcessTransactionWithRoute(ctx context.Context, txnID string, route []string, fxRates map[string]float64, failureChance float64) error {
+	err := s.TransactionStore.ProcessTransactionWithRoute(ctx, txnID, route, fxRates, failureChance)
+	s.persistLogged(ctx, txnID)
+	if err == nil {
+		s.appendLedger(ctx, txnID)
+	}
+	return err
+}
+
+// Drain waits for processing to finish and persists the transactions it
+// interrupted, so they are resumed on the next startup
+func (s *TransactionStore) Drain(ctx context.Context) ([]string, error) {
+	interrupted, err := s.TransactionStore.Drain(ctx)
+	for _, txnID := range interrupted {
+		s.persistLogged(ctx, txnID)
+	}
+	return interrupted, err
+}
+
+// ResumeInterrupted compensates and resets an interrupted transaction and persists it
+func (s *TransactionStore) ResumeInterrupted(ctx context.Context, txnID string) error {
+	if err := s.TransactionStore.ResumeInterrupted(ctx, txnID); err != nil {
+		return err
+	}
+	return s.persist(ctx, txnID)
+}
+
+// RequeueStuck compensates and resets a stuck transaction and persists it
+func (s *TransactionStore) RequeueStuck(ctx context.Context, txnID string) error {
+	if err := s.TransactionStore.RequeueStuck(ctx, txnID); err != nil {
+		return err
+	}
+	return s.persist(ctx, txnID)
+}
+
+// EscalateStuck holds a stuck transaction for admin review and persists it
+func (s *TransactionStore) EscalateStuck(ctx context.Context, txnID string, reasons []string) error {
+	if err := s.TransactionStore.EscalateStuck(ctx, txnID, reasons); err != nil {
+		return err
+	}
+	return s.persist(ctx, txnID)
+}
+
+// ResetTransactionForRetry resets a transaction to pending and persists it
+func (s *TransactionStore) ResetTransactionForRetry(txnID string) {
+	s.TransactionStore.ResetTransactionForRetry(txnID)
+	s.persistLogged(context.Background(), txnID)
+}
+
+// MarkAsRefunded marks a transaction as refunded and persists it
+func (s *TransactionStore) MarkAsRefunded(txnID string, refund payments.Refund) {
+	s.TransactionStore.MarkAsRefunded(txnID, refund)
+	s.persistLogged(context.Background(), txnID)
+}
+
+// MarkPaymentFailed marks a pending transaction as failed and persists it
+func (s *TransactionStore) MarkPaymentFailed(txnID string, reason string) {
+	s.TransactionStore.MarkPaymentFailed(txnID, reason)
+	s.persistLogged(context.Background(), txnID)
+}
+
+// HoldForReview holds a pending transaction for compliance review and persists it
+func (s *TransactionStore) HoldForReview(txnID string, reasons []string) error {
+	if err := s.TransactionStore.HoldForReview(txnID, reasons); err != nil {
+		return err
+	}
+	return s.persist(context.Background(), txnID)
+}
+
+// ResolveReview approves or rejects a held transaction and persists it
+func (s *TransactionStore) ResolveReview(txnID string, decision payments.ReviewDecision, reviewer, note string) (*payments.Transaction, error) {
+	txn, err := s.TransactionStore.ResolveReview(txnID, decision, reviewer, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.persist(context.Background(), txnID); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// SetPaymentIntent records the Stripe PaymentIntent of a transaction and persists it
+func (s *TransactionStore) SetPaymentIntent(txnID, paymentIntentID string) {
+	s.TransactionStore.SetPaymentIntent(txnID, paymentIntentID)
+	s.persistLogged(context.Background(), txnID)
+}
+
+// SetPaymentProvider records the provider collecting a transaction's payment and persists it
+func (s *TransactionStore) SetPaymentProvider(txnID, provider string) {
+	s.TransactionStore.SetPaymentProvider(txnID, provider)
+	s.persistLogged(context.Background(), txnID)
+}
+
+// SetPayoutAccount sets the account a transaction is paid out to and persists it
+func (s *TransactionStore) SetPayoutAccount(txnID, account string) error {
+	if err := s.TransactionStore.SetPayoutAccount(txnID, account); err != nil {
+		return err
+	}
+	return s.persist(context.Background(), txnID)
+}
+
+// RecordPayout saves the payout state of a transaction and persists it
+func (s *TransactionStore) RecordPayout(txnID string, payout payments.Payout) error {
+	if err := s.TransactionStore.RecordPayout(txnID, payout); err != nil {
+		return err
+	}
+	return s.persist(context.Background(), txnID)
+}
+
+// OpenDispute opens a dispute against a completed payment and persists it
+func (s *TransactionStore) OpenDispute(txnID, userID string, reason payments.DisputeReason, details string) (*payments.Transaction, error) {
+	txn, err := s.TransactionStore.OpenDispute(txnID, userID, reason, details)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.persist(context.Background(), txnID); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// ResolveDispute accepts or rejects an open dispute and persists it
+func (s *TransactionStore) ResolveDispute(txnID string, decision payments.DisputeStatus, reviewer, note string, refund *payments.Refund) (*payments.Transaction, error) {
+	txn, err := s.TransactionStore.ResolveDispute(txnID, decision, reviewer, note, refund)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.persist(context.Background(), txnID); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// QueueForNetting queues a pending payment for netting and persists it
+func (s *TransactionStore) QueueForNetting(txnID string) error {
+	if err := s.TransactionStore.QueueForNetting(txnID); err != nil {
+		return err
+	}
+	s.persistLogged(context.Background(), txnID)
+	return nil
+}
+
+// CreateNetSettlement creates a netting set's net settlement and persists it
+func (s *TransactionStore) CreateNetSettlement(set *payments.NettingSet, amount float64, currency string, route []string) (*payments.Transaction, error) {
+	txn, err := s.TransactionStore.CreateNetSettlement(set, amount, currency, route)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.persist(context.Background(), txn.ID); err != nil {
+		return nil, err
+	}
+	return txn, nil
+}
+
+// CompleteNettingSet settles or fails the set's members, persists them
+// together and records a settled set in the ledger
+func (s *TransactionStore) CompleteNettingSet(set *payments.NettingSet) []string {
+	completed := s.TransactionStore.CompleteNettingSet(set)
+
+	txns := make([]*payments.Transaction, 0, len(completed))
+	for _, id := range completed {
+		if txn, err := s.Snapshot(id); err == nil {
+			txns = append(txns, txn)
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	if err := s.client.SaveTransactions(ctx, txns); err != nil {
+		slog.ErrorContext(ctx, "failed to persist netting set", "netting_set_id", set.ID, "error", err)
+	}
+
+	if set.Status == payments.NettingSettled {
+		s.appendNettingLedger(ctx, set)
+	}
+	return completed
+}
+
+// SetCandidateRoutes records the routes considered and persists them
+func (s *TransactionStore) SetCandidateRoutes(txnID string, routes [][]string) {
+	s.TransactionStore.SetCandidateRoutes(txnID, routes)
+	s.persistLogged(context.Background(), txnID)
+}
+
+// persist writes the current state of a transaction to Postgres.
+// The write outlives ctx's cancellation so a timed-out payment is still recorded.
+func (s *TransactionStore) persist(ctx context.Context, txnID string) error {
+	txn, err := s.Snapshot(txnID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(logging.Detach(ctx), s.timeout)
+	defer cancel()
+
+	return s.client.SaveTransaction(ctx, txn)
+}
+
+// persistLogged persists a transaction, logging (not returning) failures
+func (s *TransactionStore) persistLogged(ctx context.Context, txnID string) {
+	if err := s.persist(ctx, txnID); err != nil {
+		slog.ErrorContext(ctx, "failed to persist transaction", "transaction_id", txnID, "error", err)
+	}
+}
+
+// SaveTransaction upserts a payment transaction
+func (c *Client) SaveTransaction(ctx context.Context, txn *payments.Transaction) error {
+	return saveTransaction(ctx, c.db, txn)
+}
+
+// SaveTransactions upserts several transactions atomically
+func (c *Client) SaveTransactions(ctx context.Context, txns []*payments.Transaction) error {
+	tx, err := c.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, txn := range txns {
+		if err := saveTransaction(ctx, tx, txn); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transactions: %w", err)
+	}
+	return nil
+}
+
+// execer is implemented by *sql.DB and *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+func saveTransaction(ctx context.Context, db execer, txn *payments.Transaction) error {
+	route, err := json.Marshal(txn.Route)
+	if err != nil {
+		return fmt.Errorf("failed to marshal route: %w", err)
+	}
+	hopResults, err := json.Marshal(txn.HopResults)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hop results: %w", err)
+	}
+	candidates, err := json.Marshal(txn.CandidateRoutes)
+	if err != nil {
+		return fmt.Errorf("failed to marshal candidate routes: %w", err)
+	}
+	attempts, err := json.Marshal(txn.Attempts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal attempts: %w", err)
+	}
+	hopFeeBreakdown, err := json.Marshal(txn.HopFeeBreakdown)
+	if err != nil {
+		return fmt.Errorf("failed to marshal hop fee breakdown: %w", err)
+	}
+	feeRates, err := json.Marshal(txn.FeeRates)
+	if err != nil {
+		return fmt.Errorf("failed to marshal fee rates: %w", err)
+	}
+	quotedRates, err := json.Marshal(txn.QuotedFXRates)
+	if err != nil {
+		return fmt.Errorf("failed to marshal quoted fx rates: %w", err)
+	}
+	review, err := json.Marshal(txn.Review)
+	if err != nil {
+		return fmt.Errorf("failed to marshal review: %w", err)
+	}
+	dispute, err := json.Marshal(txn.Dispute)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dispute: %w", err)
+	}
+	compensations, err := json.Marshal(txn.Compensations)
+	if err != nil {
+		return fmt.Errorf("failed to marshal compensations: %w", err)
+	}
+	payout, err := json.Marshal(txn.Payout)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payout: %w", err)
+	}
+	refund, err := json.Marshal(txn.Refund)
+	if err != nil {
+		return fmt.Errorf("failed to marshal refund: %w", err)
+	}
+
+	query := `
+		INSERT INTO transactions (
+			id, user_id, amount, currency, target_currency, route, status,
+			base_fee, hop_fees, halt_fines, total_fees, final_amount, admin_profit,
+			hop_results, hops_completed, failed_at, candidate_routes, attempts,
+			card_last4, payment_method, created_at, processed_at, completed_at, batch_id, hop_fee_breakdown,
+			fee_schedule_version, fee_rates, quote_id, quoted_fx_rates, quote_expires_at, org_id, review,
+			payment_intent_id, dispute, netting_set_id, compensations, payout_account, payout,
+			payment_provider, refund
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33, $34, $35, $36, $37, $38, $39, $40)
+		ON CONFLICT (id) DO UPDATE SET
+			route = EXCLUDED.route,
+			status = EXCLUDED.status,
+			final_amount = EXCLUDED.final_amount,
+			hop_results = EXCLUDED.hop_results,
+			hops_completed = EXCLUDED.hops_completed,
+			failed_at = EXCLUDED.failed_at,
+			candidate_routes = EXCLUDED.candidate_routes,
+			attempts = EXCLUDED.attempts,
+			payment_method = EXCLUDED.payment_method,
+			processed_at = EXCLUDED.processed_at,
+			completed_at = EXCLUDED.completed_at,
+			review = EXCLUDED.review,
+			payment_intent_id = EXCLUDED.payment_intent_id,
+			dispute = EXCLUDED.dispute,
+			netting_set_id = EXCLUDED.netting_set_id,
+			compensations = EXCLUDED.compensations,
+			payout_account = EXCLUDED.payout_account,
+			payout = EXCLUDED.payout,
+			payment_provider = EXCLUDED.payment_provider,
+			refund = EXCLUDED.refund
+	`
+
+	_, err = db.ExecContext(ctx, query,
+		txn.ID, txn.UserID, txn.Amount, txn.Currency, txn.TargetCurrency, route, string(txn.Status),
+		txn.BaseFee, txn.HopFees, txn.HaltFines, txn.TotalFees, txn.FinalAmount, txn.AdminProfit,
+		hopResults, txn.HopsCompleted, nullString(txn.FailedAt), candidates, attempts,
+		txn.CardLast4, txn.PaymentMethod, txn.CreatedAt, txn.ProcessedAt, txn.CompletedAt, nullString(txn.BatchID), hopFeeBreakdown,
+		txn.FeeScheduleVersion, feeRates, nullString(txn.QuoteID), quotedRates, txn.QuoteExpiresAt, nullString(txn.OrgID), review,
+		nullString(txn.PaymentIntentID), dispute, nullString(txn.NettingSetID), compensations,
+		nullString(txn.PayoutAccount), payout,
+		nullString(txn.PaymentProvider), refund,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save transaction: %w", err)
+	}
+	return nil
+}
+
+// LoadTransactions retrieves all payment transactions in creation order
+func (c *Client) LoadTransactions(ctx context.Context) ([]*payments.Transaction, error) {
+	query := `
+		SELECT id, user_id, amount, COALESCE(currency, ''), COALESCE(target_currency, ''), route, status,
+			base_fee, hop_fees, halt_fines, total_fees, final_amount, admin_profit,
+			hop_results, hops_completed, COALESCE(failed_at, ''), candidate_routes, attempts,
+			COALESCE(card_last4, ''), COALESCE(payment_method, ''), created_at, processed_at, completed_at,
+			COALESCE(batch_id, ''), hop_fee_breakdown, COALESCE(fee_schedule_version, 0), fee_rates,
+			COALESCE(quote_id, ''), quoted_fx_rates, quote_expires_at, COALESCE(org_id, ''), review,
+			COALESCE(payment_intent_id, ''), dispute, COALESCE(netting_set_id, ''), compensations,
+			COALESCE(payout_account, ''), payout, COALESCE(payment_provider, ''), refund
+		FROM transactions
+		ORDER BY created_at ASC
+	`
+
+	rows, err := c.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var txns []*payments.Transaction
+	for rows.Next() {
+		var txn payments.Transaction
+		var status string
+		var route, hopResults, candidates, attempts, hopFeeBreakdown, feeRates, quotedRates, review, dispute, compensations, payout, refund []byte
+		var processedAt, completedAt, quoteExpiresAt sql.NullTime
+
+		err := rows.Scan(
+			&txn.ID, &txn.UserID, &txn.Amount, &txn.Currency, &txn.TargetCurrency, &route, &status,
+			&txn.BaseFee, &txn.HopFees, &txn.HaltFines, &txn.TotalFees, &txn.FinalAmount, &txn.AdminProfit,
+			&hopResults, &txn.HopsCompleted, &txn.FailedAt, &candidates, &attempts,
+			&txn.CardLast4, &txn.PaymentMethod, &txn.CreatedAt, &processedAt, &completedAt,
+			&txn.BatchID, &hopFeeBreakdown, &txn.FeeScheduleVersion, &feeRates,
+			&txn.QuoteID, &quotedRates, &quoteExpiresAt, &txn.OrgID, &review,
+			&txn.PaymentIntentID, &dispute, &txn.NettingSetID, &compensations,
+			&txn.PayoutAccount, &payout, &txn.PaymentProvider, &refund,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+
+		txn.Status = payments.TransactionStatus(status)
+		if err := unmarshalJSONColumns(
+			route, &txn.Route,
+			hopResults, &txn.HopResults,
+			candidates, &txn.CandidateRoutes,
+			attempts, &txn.Attempts,
+			hopFeeBreakdown, &txn.HopFeeBreakdown,
+			feeRates, &txn.FeeRates,
+			quotedRates, &txn.QuotedFXRates,
+			review, &txn.Review,
+			dispute, &txn.Dispute,
+			compensations, &txn.Compensations,
+			payout, &txn.Payout,
+			refund, &txn.Refund,
+		); err != nil {
+			return nil, fmt.Errorf("failed to decode transaction %s: %w", txn.ID, err)
+		}
+		if processedAt.Valid {
+			t := processedAt.Time
+			txn.ProcessedAt = &t
+		}
+		if completedAt.Valid {
+			t := completedAt.Time
+			txn.CompletedAt = &t
+		}
+		if quoteExpiresAt.Valid {
+			t := quoteExpiresAt.Time
+			txn.QuoteExpiresAt = &t
+		}
+
+		txns = append(txns, &txn)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate transactions: %w", err)
+	}
+	return txns, nil
+}
+
+// unmarshalJSONColumns decodes pairs of (raw JSON, destination)
+func unmarshalJSONColumns(pairs ...interface{}) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		raw, _ := pairs[i].([]byte)
+		if len(raw) == 0 {
+			continue
+		}
+		if err := json.Unmarshal(raw, pairs[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nullString converts an empty string to SQL NULL
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// Compile-time interface check
+var _ payments.TransactionStorer = (*TransactionStore)(nil)
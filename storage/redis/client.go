@@ -5,6 +5,10 @@ package redis
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -46,6 +50,40 @@ func DefaultConfig() *Config {
 	}
 }
 
+// ConfigFromEnv returns a standalone configuration from REDIS_URL
+// (e.g. redis://:password@redis:6379/0). Returns nil if REDIS_URL is unset.
+func ConfigFromEnv() (*Config, error) {
+	raw := os.Getenv("REDIS_URL")
+	if raw == "" {
+		return nil, nil
+	}
+	return ConfigFromURL(raw)
+}
+
+// ConfigFromURL returns a standalone configuration from a redis:// URL
+func ConfigFromURL(raw string) (*Config, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_URL: %w", err)
+	}
+
+	cfg := DefaultConfig()
+	cfg.MasterName = ""
+	cfg.SentinelAddrs = nil
+	cfg.Addr = u.Host
+	if password, ok := u.User.Password(); ok {
+		cfg.Password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		n, err := strconv.Atoi(db)
+		if err != nil {
+			return nil, fmt.Errorf("invalid REDIS_URL database: %w", err)
+		}
+		cfg.DB = n
+	}
+	return cfg, nil
+}
+
 // Client wraps Redis client with rate limiting and circuit breaker capabilities
 type Client struct {
 	rdb          redis.UniversalClient
@@ -84,6 +122,7 @@ func NewClient(ctx context.Context, cfg *Config) (*Client, error) {
 
 	// Verify connection
 	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
@@ -111,6 +150,21 @@ func (c *Client) RateLimiter() *RateLimiter {
 	return c.rateLimiter
 }
 
+// Idempotency returns an idempotency key store backed by this client
+func (c *Client) Idempotency() *IdempotencyStore {
+	return NewIdempotencyStore(c.rdb)
+}
+
+// NodeRegistry returns a mesh node registry backed by this client
+func (c *Client) NodeRegistry() *NodeRegistry {
+	return NewNodeRegistry(c.rdb)
+}
+
+// Locker returns a distributed locker backed by this client
+func (c *Client) Locker() *Locker {
+	return NewLocker(c.rdb)
+}
+
 // CircuitBreaker returns the circuit breaker instance
 func (c *Client) CircuitBreaker() *CircuitBreaker {
 	return c.circuitBreaker
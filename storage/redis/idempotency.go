@@ -0,0 +1,60 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyStore keeps Idempotency-Key results in Redis so retries are
+// deduplicated across server instances. A request reserves its key with
+// SET NX before running and replaces the reservation with its result.
+type IdempotencyStore struct {
+	rdb redis.UniversalClient
+}
+
+// NewIdempotencyStore creates a new Redis-backed idempotency store
+func NewIdempotencyStore(rdb redis.UniversalClient) *IdempotencyStore {
+	return &IdempotencyStore{rdb: rdb}
+}
+
+// Get returns the stored value for a key
+func (s *IdempotencyStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := s.rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+	}
+	return data, true, nil
+}
+
+// Reserve stores a value for a key if it is not already set (first writer
+// wins), reporting whether it did
+func (s *IdempotencyStore) Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	ok, err := s.rdb.SetNX(ctx, key, value, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	return ok, nil
+}
+
+// Set stores a value for a key, replacing its reservation
+func (s *IdempotencyStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := s.rdb.Set(ctx, key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to set idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Delete removes a key, releasing its reservation
+func (s *IdempotencyStore) Delete(ctx context.Context, key string) error {
+	if err := s.rdb.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to delete idempotency key: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// lockPrefix namespaces lock keys
+const lockPrefix = "plm:lock:"
+
+// extendLockScript refreshes a lock's TTL only while the caller's token holds it
+const extendLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`
+
+// releaseLockScript deletes a lock only while the caller's token holds it, so
+// a holder whose lock expired can't release its successor's
+const releaseLockScript = `
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+    return redis.call('DEL', KEYS[1])
+end
+return 0
+`
+
+// Locker implements distributed locks shared by every server instance. A
+// lock is a key set with SET NX to its holder's random token and a TTL, so a
+// crashed holder's lock expires; the holder extends it while still working.
+type Locker struct {
+	rdb redis.UniversalClient
+}
+
+// NewLocker creates a new Redis-backed locker
+func NewLocker(rdb redis.UniversalClient) *Locker {
+	return &Locker{rdb: rdb}
+}
+
+// TryLock acquires key for token unless another token holds it
+func (l *Locker) TryLock(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	ok, err := l.rdb.SetNX(ctx, lockPrefix+key, token, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock: %w", err)
+	}
+	return ok, nil
+}
+
+// Extend resets the TTL of key, reporting false if token no longer holds it
+func (l *Locker) Extend(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := l.rdb.Eval(ctx, extendLockScript, []string{lockPrefix + key}, token, ttl.Milliseconds()).Int64()
+	if err != nil {
+		return false, fmt.Errorf("failed to extend lock: %w", err)
+	}
+	return n == 1, nil
+}
+
+// Unlock releases key if token still holds it
+func (l *Locker) Unlock(ctx context.Context, key, token string) error {
+	if err := l.rdb.Eval(ctx, releaseLockScript, []string{lockPrefix + key}, token).Err(); err != nil {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
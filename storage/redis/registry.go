@@ -0,0 +1,67 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// registryPrefix namespaces node registry records
+const registryPrefix = "plm:registry:node:"
+
+// NodeRegistry stores mesh node records shared by all server instances.
+// Each record expires unless its instance refreshes it.
+type NodeRegistry struct {
+	rdb redis.UniversalClient
+}
+
+// NewNodeRegistry creates a new Redis-backed node registry
+func NewNodeRegistry(rdb redis.UniversalClient) *NodeRegistry {
+	return &NodeRegistry{rdb: rdb}
+}
+
+// Put stores a node's record for ttl
+func (r *NodeRegistry) Put(ctx context.Context, nodeID string, value []byte, ttl time.Duration) error {
+	if err := r.rdb.Set(ctx, registryPrefix+nodeID, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to register node %s: %w", nodeID, err)
+	}
+	return nil
+}
+
+// List returns every unexpired record
+func (r *NodeRegistry) List(ctx context.Context) ([][]byte, error) {
+	var keys []string
+	iter := r.rdb.Scan(ctx, 0, registryPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list registry: %w", err)
+	}
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := r.rdb.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry: %w", err)
+	}
+	records := make([][]byte, 0, len(values))
+	for _, v := range values {
+		// Keys that expired between SCAN and MGET come back nil
+		if s, ok := v.(string); ok {
+			records = append(records, []byte(s))
+		}
+	}
+	return records, nil
+}
+
+// Delete removes a node's record
+func (r *NodeRegistry) Delete(ctx context.Context, nodeID string) error {
+	if err := r.rdb.Del(ctx, registryPrefix+nodeID).Err(); err != nil {
+		return fmt.Errorf("failed to deregister node %s: %w", nodeID, err)
+	}
+	return nil
+}
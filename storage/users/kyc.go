@@ -0,0 +1,170 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// KYCStatus is where a user is in identity verification
+type KYCStatus string
+
+const (
+	KYCUnverified KYCStatus = "unverified" // Nothing submitted yet
+	KYCPending    KYCStatus = "pending"    // Documents submitted, waiting for an admin
+	KYCVerified   KYCStatus = "verified"
+	KYCRejected   KYCStatus = "rejected" // The user may submit new documents
+)
+
+// Maximum documents accepted in one submission
+const maxKYCDocuments = 10
+
+// KYC errors
+var (
+	ErrInvalidDocument    = errors.New("invalid KYC document")
+	ErrKYCNotPending      = errors.New("no KYC submission is awaiting review")
+	ErrKYCAlreadyVerified = errors.New("identity is already verified")
+	ErrInvalidKYCDecision = errors.New("KYC decision must be verified or rejected")
+)
+
+// documentTypes are the accepted identity documents
+var documentTypes = map[string]bool{
+	"passport":         true,
+	"national_id":      true,
+	"drivers_license":  true,
+	"proof_of_address": true,
+}
+
+// KYCDocument is the metadata of an identity document. The file itself is
+// kept in external storage; Reference locates it there.
+type KYCDocument struct {
+	Type        string    `json:"type"` // passport, national_id, drivers_license or proof_of_address
+	Reference   string    `json:"reference"`
+	FileName    string    `json:"file_name,omitempty"`
+	ContentType string    `json:"content_type,omitempty"`
+	Size        int64     `json:"size,omitempty"`   // Bytes
+	SHA256      string    `json:"sha256,omitempty"` // Hex digest of the file, to detect tampering
+	UploadedAt  time.Time `json:"uploaded_at"`
+}
+
+// KYC is a user's identity verification state
+type KYC struct {
+	Status      KYCStatus     `json:"status"`
+	Documents   []KYCDocument `json:"documents,omitempty"`
+	SubmittedAt *time.Time    `json:"submitted_at,omitempty"`
+	ReviewedBy  string        `json:"reviewed_by,omitempty"`
+	ReviewedAt  *time.Time    `json:"reviewed_at,omitempty"`
+	Note        string        `json:"note,omitempty"` // Reviewer's note, shown to the user on rejection
+}
+
+// Verified reports whether the user's identity has been verified
+func (k *KYC) Verified() bool {
+	return k.Status == KYCVerified
+}
+
+// status returns the KYC status, treating an unset status as unverified
+func (k *KYC) status() KYCStatus {
+	if k.Status == "" {
+		return KYCUnverified
+	}
+	return k.Status
+}
+
+// validateDocuments checks a submission and stamps each document's upload time
+func validateDocuments(docs []KYCDocument, now time.Time) error {
+	if len(docs) == 0 || len(docs) > maxKYCDocuments {
+		return fmt.Errorf("%w: submit between 1 and %d documents", ErrInvalidDocument, maxKYCDocuments)
+	}
+	for i := range docs {
+		doc := &docs[i]
+		doc.Type = strings.ToLower(strings.TrimSpace(doc.Type))
+		switch {
+		case !documentTypes[doc.Type]:
+			return fmt.Errorf("%w: type must be passport, national_id, drivers_license or proof_of_address", ErrInvalidDocument)
+		case strings.TrimSpace(doc.Reference) == "":
+			return fmt.Errorf("%w: reference is required", ErrInvalidDocument)
+		case doc.Size < 0:
+			return fmt.Errorf("%w: size must not be negative", ErrInvalidDocument)
+		}
+		doc.UploadedAt = now
+	}
+	return nil
+}
+
+// SubmitKYC records identity documents for a user and puts them in the
+// review queue. Users can resubmit after a rejection or add documents while
+// pending, but not once verified.
+func (s *Store) SubmitKYC(id string, docs []KYCDocument) (*KYC, error) {
+	now := time.Now().UTC()
+	if err := validateDocuments(docs, now); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	if existing.KYC.Verified() {
+		return nil, ErrKYCAlreadyVerified
+	}
+
+	// Copy on write: Authenticate reads users outside the lock
+	user := *existing
+	user.KYC = KYC{
+		Status:      KYCPending,
+		Documents:   append(append([]KYCDocument(nil), existing.KYC.Documents...), docs...),
+		SubmittedAt: &now,
+	}
+	user.UpdatedAt = now
+	s.users[id] = &user
+
+	kyc := user.KYC
+	return &kyc, nil
+}
+
+// ReviewKYC approves (KYCVerified) or rejects (KYCRejected) a pending submission
+func (s *Store) ReviewKYC(id string, decision KYCStatus, reviewer, note string) (*KYC, error) {
+	if decision != KYCVerified && decision != KYCRejected {
+		return nil, ErrInvalidKYCDecision
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+	if existing.KYC.status() != KYCPending {
+		return nil, ErrKYCNotPending
+	}
+
+	now := time.Now().UTC()
+	user := *existing
+	user.KYC.Status = decision
+	user.KYC.ReviewedBy = reviewer
+	user.KYC.ReviewedAt = &now
+	user.KYC.Note = note
+	user.UpdatedAt = now
+	s.users[id] = &user
+
+	kyc := user.KYC
+	return &kyc, nil
+}
+
+// KYCVerified reports whether a user's identity has been verified; unknown
+// users are not
+func (s *Store) KYCVerified(id string) (bool, error) {
+	user, err := s.GetByID(id)
+	if errors.Is(err, ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.KYC.Verified(), nil
+}
@@ -0,0 +1,391 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+// Storer is the user storage contract shared by the in-memory and Postgres stores
+type Storer interface {
+	CreateUser(email, password, username string, role auth.Role) (UserWithToUser, error)
+	Authenticate(email, password string) (UserWithToUser, error)
+	GetByEmail(email string) (UserWithToUser, error)
+	GetByID(id string) (*StoredUser, error)
+	ListUsers() []*auth.User
+	UpdateUser(id string, update UserUpdate) (*auth.User, error)
+	SetPassword(id, password string) error
+	SubmitKYC(id string, docs []KYCDocument) (*KYC, error)
+	ReviewKYC(id string, decision KYCStatus, reviewer, note string) (*KYC, error)
+	KYCVerified(id string) (bool, error)
+}
+
+// Compile-time interface checks
+var (
+	_ Storer = (*Store)(nil)
+	_ Storer = (*PostgresStore)(nil)
+)
+
+// placeholderHashPrefix marks the seed admin row from 002_rbac_users.sql
+const placeholderHashPrefix = "$argon2id$v=19$m=65536,t=3,p=4$placeholder"
+
+// queryTimeout bounds every user store query
+const queryTimeout = 5 * time.Second
+
+// PostgresStore provides durable user storage backed by the users table
+type PostgresStore struct {
+	db *sql.DB
+}
+
+// NewPostgresStore creates a Postgres-backed user store.
+// Default admin/user accounts are created only if they don't exist yet,
+// so passwords are no longer regenerated on every boot.
+func NewPostgresStore(ctx context.Context, db *sql.DB) (*PostgresStore, error) {
+	store := &PostgresStore{db: db}
+
+	if err := store.ensureDefaultUser(ctx, "admin@plm.local", "admin", "ADMIN_PASSWORD", auth.RoleAdmin); err != nil {
+		return nil, fmt.Errorf("failed to seed admin user: %w", err)
+	}
+	if err := store.ensureDefaultUser(ctx, "user@plm.local", "user", "USER_PASSWORD", auth.RoleUser); err != nil {
+		return nil, fmt.Errorf("failed to seed default user: %w", err)
+	}
+
+	return store, nil
+}
+
+// ensureDefaultUser creates a default account, or replaces the migration's placeholder hash
+func (s *PostgresStore) ensureDefaultUser(ctx context.Context, email, username, envVar string, role auth.Role) error {
+	var hash string
+	err := s.db.QueryRowContext(ctx, `SELECT password_hash FROM users WHERE email = $1`, email).Scan(&hash)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		_, err = s.CreateUser(email, getPasswordFromEnv(envVar, email), username, role)
+		return err
+	case err != nil:
+		return err
+	case strings.HasPrefix(hash, placeholderHashPrefix):
+		newHash, err := auth.HashPassword(getPasswordFromEnv(envVar, email))
+		if err != nil {
+			return err
+		}
+		_, err = s.db.ExecContext(ctx, `UPDATE users SET password_hash = $1 WHERE email = $2`, newHash, email)
+		return err
+	default:
+		return nil
+	}
+}
+
+// userColumns is the column list scanned by scanUser
+const userColumns = `id, email, username, password_hash, role, COALESCE(full_name, ''),
+	COALESCE(organization, ''), is_active, created_at, updated_at,
+	kyc_status, kyc_documents, kyc_submitted_at, COALESCE(kyc_reviewed_by, ''), kyc_reviewed_at, COALESCE(kyc_note, '')`
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanUser scans a users row into a StoredUser
+func scanUser(row rowScanner) (*StoredUser, error) {
+	var u StoredUser
+	var role, kycStatus string
+	var documents []byte
+	var submittedAt, reviewedAt sql.NullTime
+	err := row.Scan(&u.ID, &u.Email, &u.Username, &u.PasswordHash, &role,
+		&u.FullName, &u.Organization, &u.IsActive, &u.CreatedAt, &u.UpdatedAt,
+		&kycStatus, &documents, &submittedAt, &u.KYC.ReviewedBy, &reviewedAt, &u.KYC.Note)
+	if err != nil {
+		return nil, err
+	}
+	u.Role = auth.Role(role)
+	u.KYC.Status = KYCStatus(kycStatus)
+	if err := json.Unmarshal(documents, &u.KYC.Documents); err != nil {
+		return nil, fmt.Errorf("failed to decode KYC documents: %w", err)
+	}
+	if submittedAt.Valid {
+		u.KYC.SubmittedAt = &submittedAt.Time
+	}
+	if reviewedAt.Valid {
+		u.KYC.ReviewedAt = &reviewedAt.Time
+	}
+	return &u, nil
+}
+
+// CreateUser creates a new user with hashed password
+func (s *PostgresStore) CreateUser(email, password, username string, role auth.Role) (UserWithToUser, error) {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO users (email, username, password_hash, role)
+		VALUES ($1, $2, $3, $4::user_role)
+		RETURNING ` + userColumns
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, query, email, username, hash, string(role)))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" { // unique_violation
+			if strings.Contains(pqErr.Constraint, "username") {
+				return nil, ErrUsernameExists
+			}
+			return nil, ErrEmailExists
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email
+func (s *PostgresStore) GetByEmail(email string) (UserWithToUser, error) {
+	user, err := s.getBy("email", email)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetByID retrieves a user by ID
+func (s *PostgresStore) GetByID(id string) (*StoredUser, error) {
+	return s.getBy("id", id)
+}
+
+// getBy loads a single user by an allowlisted column
+func (s *PostgresStore) getBy(column, value string) (*StoredUser, error) {
+	var where string
+	switch column {
+	case "email":
+		where = "email = $1"
+	case "id":
+		where = "id::text = $1" // Avoid UUID parse errors on malformed IDs
+	default:
+		return nil, fmt.Errorf("invalid lookup column: %s", column)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + where
+	user, err := scanUser(s.db.QueryRowContext(ctx, query, value))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// Authenticate verifies credentials and returns the user
+func (s *PostgresStore) Authenticate(email, password string) (UserWithToUser, error) {
+	user, err := s.getBy("email", email)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	if !user.IsActive {
+		return nil, ErrInvalidCredentials
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	// Verify password with Argon2id
+	if err := auth.VerifyPassword(password, user.PasswordHash); err != nil {
+		if _, err := s.db.ExecContext(ctx,
+			`UPDATE users SET failed_attempts = failed_attempts + 1 WHERE id = $1`, user.ID); err != nil {
+			slog.WarnContext(ctx, "failed to record failed login", "user_id", user.ID, "error", err)
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if _, err := s.db.ExecContext(ctx,
+		`UPDATE users SET failed_attempts = 0, last_login_at = NOW() WHERE id = $1`, user.ID); err != nil {
+		slog.WarnContext(ctx, "failed to record login", "user_id", user.ID, "error", err)
+	}
+
+	return user, nil
+}
+
+// SetPassword replaces a user's password with a new Argon2id hash and
+// clears their failed login count and lockout
+func (s *PostgresStore) SetPassword(id, password string) error {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	result, err := s.db.ExecContext(ctx,
+		`UPDATE users SET password_hash = $1, failed_attempts = 0, locked_until = NULL WHERE id::text = $2`, hash, id)
+	if err != nil {
+		return fmt.Errorf("failed to update password: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// ListUsers returns all users (for admin)
+func (s *PostgresStore) ListUsers() []*auth.User {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+userColumns+` FROM users ORDER BY created_at ASC`)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to list users", "error", err)
+		return []*auth.User{}
+	}
+	defer rows.Close()
+
+	result := make([]*auth.User, 0)
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to scan user", "error", err)
+			continue
+		}
+		result = append(result, user.ToUser())
+	}
+	return result
+}
+
+// UpdateUser applies an admin update (activation or role change) to a user
+func (s *PostgresStore) UpdateUser(id string, update UserUpdate) (*auth.User, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+
+	var isActive sql.NullBool
+	if update.IsActive != nil {
+		isActive = sql.NullBool{Bool: *update.IsActive, Valid: true}
+	}
+	var role sql.NullString
+	if update.Role != nil {
+		role = sql.NullString{String: string(*update.Role), Valid: true}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE users SET
+			is_active = COALESCE($2, is_active),
+			role = COALESCE($3::user_role, role)
+		WHERE id::text = $1
+		RETURNING ` + userColumns
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, query, id, isActive, role))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+	return user.ToUser(), nil
+}
+
+// SubmitKYC records identity documents for a user and puts them in the
+// review queue. Users can resubmit after a rejection or add documents while
+// pending, but not once verified.
+func (s *PostgresStore) SubmitKYC(id string, docs []KYCDocument) (*KYC, error) {
+	if err := validateDocuments(docs, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+	documents, err := json.Marshal(docs)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE users SET
+			kyc_status = 'pending',
+			kyc_documents = kyc_documents || $2::jsonb,
+			kyc_submitted_at = NOW(),
+			kyc_reviewed_by = NULL,
+			kyc_reviewed_at = NULL,
+			kyc_note = NULL
+		WHERE id::text = $1 AND kyc_status <> 'verified'
+		RETURNING ` + userColumns
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, query, id, string(documents)))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.kycConflict(id, ErrKYCAlreadyVerified)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit KYC documents: %w", err)
+	}
+	return &user.KYC, nil
+}
+
+// ReviewKYC approves (KYCVerified) or rejects (KYCRejected) a pending submission
+func (s *PostgresStore) ReviewKYC(id string, decision KYCStatus, reviewer, note string) (*KYC, error) {
+	if decision != KYCVerified && decision != KYCRejected {
+		return nil, ErrInvalidKYCDecision
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+
+	query := `
+		UPDATE users SET
+			kyc_status = $2,
+			kyc_reviewed_by = $3,
+			kyc_reviewed_at = NOW(),
+			kyc_note = NULLIF($4, '')
+		WHERE id::text = $1 AND kyc_status = 'pending'
+		RETURNING ` + userColumns
+
+	user, err := scanUser(s.db.QueryRowContext(ctx, query, id, string(decision), reviewer, note))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, s.kycConflict(id, ErrKYCNotPending)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review KYC submission: %w", err)
+	}
+	return &user.KYC, nil
+}
+
+// kycConflict explains a KYC update that matched no row: the user is
+// missing, or in a state that does not allow the change
+func (s *PostgresStore) kycConflict(id string, conflict error) error {
+	if _, err := s.GetByID(id); err != nil {
+		return err
+	}
+	return conflict
+}
+
+// KYCVerified reports whether a user's identity has been verified; unknown
+// users are not
+func (s *PostgresStore) KYCVerified(id string) (bool, error) {
+	user, err := s.GetByID(id)
+	if errors.Is(err, ErrUserNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return user.KYC.Verified(), nil
+}
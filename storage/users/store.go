@@ -1,5 +1,5 @@
-// Package users provides in-memory user storage with Argon2id password hashing.
-// This can be upgraded to PostgreSQL persistence as needed.
+// Package users provides user storage with Argon2id password hashing.
+// Store keeps users in memory; PostgresStore persists them to the users table.
 package users
 
 import (
@@ -21,8 +21,26 @@ var (
 	ErrEmailExists       = errors.New("email already exists")
 	ErrUsernameExists    = errors.New("username already exists")
 	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidRole       = errors.New("invalid role")
 )
 
+// UserUpdate holds admin changes to a user; nil fields are left unchanged
+type UserUpdate struct {
+	IsActive *bool      `json:"is_active,omitempty"`
+	Role     *auth.Role `json:"role,omitempty"`
+}
+
+// validate checks the update against the known roles
+func (u UserUpdate) validate() error {
+	if u.Role == nil {
+		return nil
+	}
+	if !u.Role.Valid() {
+		return ErrInvalidRole
+	}
+	return nil
+}
+
 // StoredUser represents a user with hashed password
 type StoredUser struct {
 	ID           string    `json:"id"`
@@ -33,6 +51,7 @@ type StoredUser struct {
 	FullName     string    `json:"full_name,omitempty"`
 	Organization string    `json:"organization,omitempty"`
 	IsActive     bool      `json:"is_active"`
+	KYC          KYC       `json:"kyc"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
 }
@@ -47,6 +66,7 @@ func (su *StoredUser) ToUser() *auth.User {
 		FullName:     su.FullName,
 		Organization: su.Organization,
 		IsActive:     su.IsActive,
+		KYCStatus:    string(su.KYC.status()),
 		CreatedAt:    su.CreatedAt,
 	}
 }
@@ -107,6 +127,7 @@ func NewStore() *Store {
 		PasswordHash: adminHash,
 		Role:         auth.RoleAdmin,
 		IsActive:     true,
+		KYC:          KYC{Status: KYCUnverified},
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -123,6 +144,7 @@ func NewStore() *Store {
 		PasswordHash: userHash,
 		Role:         auth.RoleUser,
 		IsActive:     true,
+		KYC:          KYC{Status: KYCUnverified},
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -161,6 +183,7 @@ func (s *Store) CreateUser(email, password, username string, role auth.Role) (Us
 		PasswordHash: hash,
 		Role:         role,
 		IsActive:     true,
+		KYC:          KYC{Status: KYCUnverified},
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
@@ -221,6 +244,29 @@ func (s *Store) Authenticate(email, password string) (UserWithToUser, error) {
 	return user, nil
 }
 
+// SetPassword replaces a user's password with a new Argon2id hash
+func (s *Store) SetPassword(id, password string) error {
+	hash, err := auth.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[id]
+	if !exists {
+		return ErrUserNotFound
+	}
+
+	// Copy on write: Authenticate reads users outside the lock
+	user := *existing
+	user.PasswordHash = hash
+	user.UpdatedAt = time.Now()
+	s.users[id] = &user
+	return nil
+}
+
 // ListUsers returns all users (for admin)
 func (s *Store) ListUsers() []*auth.User {
 	s.mu.RLock()
@@ -232,3 +278,31 @@ func (s *Store) ListUsers() []*auth.User {
 	}
 	return result
 }
+
+// UpdateUser applies an admin update (activation or role change) to a user
+func (s *Store) UpdateUser(id string, update UserUpdate) (*auth.User, error) {
+	if err := update.validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.users[id]
+	if !exists {
+		return nil, ErrUserNotFound
+	}
+
+	// Copy on write: Authenticate reads users outside the lock
+	user := *existing
+	if update.IsActive != nil {
+		user.IsActive = *update.IsActive
+	}
+	if update.Role != nil {
+		user.Role = *update.Role
+	}
+	user.UpdatedAt = time.Now()
+	s.users[id] = &user
+
+	return user.ToUser(), nil
+}
@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/plm/predictive-liquidity-mesh/messaging/consumers"
+	natsClient "github.com/plm/predictive-liquidity-mesh/messaging/nats"
+)
+
+// TestCheckpoint3_DeadLetterQueue verifies a poison liquidity update is moved
+// to the dead letter stream, can be replayed and discarded
+func TestCheckpoint3_DeadLetterQueue(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	nats, err := natsClient.NewClient(ctx, natsClient.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer nats.Close()
+	if err := nats.SetupStreams(ctx); err != nil {
+		t.Fatalf("Failed to setup streams: %v", err)
+	}
+
+	// Malformed updates fail before touching Neo4j
+	consumerCfg := consumers.DefaultGraphSyncConfig()
+	consumerCfg.Workers = 1
+	graphSync, err := consumers.NewGraphSyncConsumer(ctx, nats, nil, consumerCfg)
+	if err != nil {
+		t.Fatalf("Failed to create graph sync consumer: %v", err)
+	}
+	graphSync.Start()
+	defer graphSync.Stop()
+
+	nodeID := "dlq_node_" + uuid.New().String()[:8]
+	if err := nats.PublishLiquidityUpdate(ctx, &natsClient.LiquidityUpdateEvent{
+		EventID:   uuid.New().String(),
+		NodeID:    nodeID,
+		EventType: "volume_change", // No source or target: can never be applied
+		NewValue:  1,
+		Timestamp: time.Now(),
+	}); err != nil {
+		t.Fatalf("Failed to publish: %v", err)
+	}
+
+	find := func() *natsClient.DeadLetter {
+		deadline := time.Now().Add(10 * time.Second)
+		for time.Now().Before(deadline) {
+			letters, err := nats.DeadLetters(ctx, consumers.GraphSyncConsumerName, 500)
+			if err != nil {
+				t.Fatalf("Failed to list dead letters: %v", err)
+			}
+			for _, l := range letters {
+				if l.Subject == "liquidity.updates."+nodeID {
+					return l
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		t.Fatal("poison message was not dead-lettered")
+		return nil
+	}
+
+	letter := find()
+	if letter.Stream != natsClient.LiquidityUpdatesStream || letter.Error == "" {
+		t.Errorf("unexpected dead letter: %+v", letter)
+	}
+	t.Logf("✅ Poison message dead-lettered as %d: %s", letter.ID, letter.Error)
+
+	// Replaying removes it; the consumer dead-letters it again as a new message
+	if _, err := nats.ReplayDeadLetter(ctx, letter.ID); err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+	again := find()
+	if again.ID == letter.ID {
+		t.Fatalf("replayed dead letter %d was not removed", letter.ID)
+	}
+
+	if _, err := nats.DiscardDeadLetter(ctx, again.ID); err != nil {
+		t.Fatalf("Failed to discard: %v", err)
+	}
+	if _, err := nats.DiscardDeadLetter(ctx, again.ID); err != natsClient.ErrDeadLetterNotFound {
+		t.Errorf("expected ErrDeadLetterNotFound for a discarded letter, got %v", err)
+	}
+	t.Log("✅ Dead letter replayed and discarded")
+}
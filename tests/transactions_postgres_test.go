@@ -0,0 +1,156 @@
+package tests
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/storage/postgres"
+)
+
+// TestTransactionsRoundTrip verifies a transaction saved to the transactions
+// table loads back unchanged, both with every JSON column and nullable field
+// set and with all of them empty
+func TestTransactionsRoundTrip(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := postgres.NewClient(ctx, postgres.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer client.Close()
+
+	// Postgres stores microseconds; nested times round-trip through JSON as UTC
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	later := now.Add(time.Minute)
+	full := &payments.Transaction{
+		ID:                 "txn_test_" + uuid.New().String()[:8],
+		UserID:             "user_roundtrip",
+		OrgID:              "org_roundtrip",
+		Amount:             250,
+		Currency:           "USD",
+		TargetCurrency:     "EUR",
+		Route:              []string{"USA", "GBR", "DEU"},
+		Status:             payments.StatusSuccess,
+		BaseFee:            3.75,
+		HopFees:            0.1,
+		HopFeeBreakdown:    []payments.HopFee{{From: "USA", To: "GBR", Multiplier: 1, Rate: 0.0002, Fee: 0.05}},
+		HaltFines:          0.25,
+		TotalFees:          4.1,
+		FinalAmount:        225.5,
+		AdminProfit:        4.1,
+		FeeScheduleVersion: 3,
+		FeeRates:           &payments.FeeConfig{BaseFeePercent: 0.015, HopFeePercent: 0.0002, HaltFinePercent: 0.001},
+		QuoteID:            "quote_roundtrip",
+		QuotedFXRates:      map[string]float64{"EUR": 0.92},
+		QuoteExpiresAt:     &later,
+		HopResults: []payments.HopResult{
+			{FromCountry: "USA", ToCountry: "GBR", Success: true, Latency: 40, FXRate: 0.79, AmountIn: 246, AmountOut: 194, HopFee: 0.05, Timestamp: now},
+		},
+		HopsCompleted:   2,
+		FailedAt:        "GBR",
+		CandidateRoutes: [][]string{{"USA", "GBR", "DEU"}, {"USA", "DEU"}},
+		Attempts: []payments.RouteAttempt{
+			{Attempt: 1, Route: []string{"USA", "DEU"}, Status: payments.StatusFailed, FailedAt: "DEU", Error: "halted", StartedAt: now, EndedAt: now},
+		},
+		Compensations: []payments.Compensation{
+			{Attempt: 1, Reason: "halted", FailedAt: "DEU", Status: payments.CompensationCompleted, StartedAt: now, CompletedAt: now},
+		},
+		CardLast4:       "4242",
+		PaymentMethod:   "card",
+		BatchID:         "batch_roundtrip",
+		Review:          &payments.Review{Reasons: []string{"large_amount"}, HeldAt: now, Decision: payments.ReviewApproved, ReviewedBy: "admin", ReviewedAt: &now},
+		PaymentIntentID: "pi_roundtrip",
+		PaymentProvider: "stripe",
+		Dispute:         &payments.Dispute{Status: payments.DisputeAccepted, Reason: payments.DisputeNotReceived, OpenedAt: now, ResolvedBy: "admin", ResolvedAt: &now, RefundID: "re_roundtrip"},
+		NettingSetID:    "net_roundtrip",
+		PayoutAccount:   "acct_roundtrip",
+		Payout:          &payments.Payout{ID: "tr_roundtrip", Destination: "acct_roundtrip", Amount: 22550, Currency: "eur", Status: payments.PayoutReversed, Attempts: 1, ReversalID: "trr_roundtrip", UpdatedAt: now},
+		Refund:          &payments.Refund{ID: "re_roundtrip", Reason: payments.RefundDisputed, Amount: 246.25, Retained: 3.75, Currency: "USD", RefundedAt: now},
+		CreatedAt:       now,
+		ProcessedAt:     &now,
+		CompletedAt:     &later,
+	}
+	empty := &payments.Transaction{
+		ID:        "txn_test_" + uuid.New().String()[:8],
+		UserID:    "user_roundtrip",
+		Amount:    10,
+		Status:    payments.StatusPending,
+		CreatedAt: now,
+	}
+	defer client.DB().ExecContext(context.Background(), `DELETE FROM transactions WHERE id IN ($1, $2)`, full.ID, empty.ID)
+
+	if err := client.SaveTransactions(ctx, []*payments.Transaction{full, empty}); err != nil {
+		t.Fatalf("Failed to save transactions: %v", err)
+	}
+
+	t.Run("AllFieldsSet", func(t *testing.T) {
+		assertTransactionRoundTrip(t, loadTransaction(ctx, t, client, full.ID), full)
+	})
+
+	t.Run("NullableFieldsEmpty", func(t *testing.T) {
+		got := loadTransaction(ctx, t, client, empty.ID)
+		if got.ProcessedAt != nil || got.CompletedAt != nil || got.QuoteExpiresAt != nil {
+			t.Errorf("Expected no timestamps, got processed %v, completed %v, quote expiry %v", got.ProcessedAt, got.CompletedAt, got.QuoteExpiresAt)
+		}
+		if got.FeeRates != nil || got.Review != nil || got.Dispute != nil || got.Payout != nil || got.Refund != nil {
+			t.Errorf("Expected no JSON objects, got %+v", got)
+		}
+		assertTransactionRoundTrip(t, got, empty)
+	})
+
+	t.Run("UpsertUpdatesState", func(t *testing.T) {
+		updated := *empty
+		updated.Status = payments.StatusFailed
+		updated.Refund = &payments.Refund{ID: "re_update", Reason: payments.RefundUserCancelled, Amount: 10, Currency: "USD", RefundedAt: now}
+		updated.ProcessedAt = &now
+		if err := client.SaveTransaction(ctx, &updated); err != nil {
+			t.Fatalf("Failed to update transaction: %v", err)
+		}
+		assertTransactionRoundTrip(t, loadTransaction(ctx, t, client, empty.ID), &updated)
+	})
+}
+
+// loadTransaction loads every transaction and returns the one with id
+func loadTransaction(ctx context.Context, t *testing.T, client *postgres.Client, id string) *payments.Transaction {
+	t.Helper()
+	txns, err := client.LoadTransactions(ctx)
+	if err != nil {
+		t.Fatalf("Failed to load transactions: %v", err)
+	}
+	for _, txn := range txns {
+		if txn.ID == id {
+			return txn
+		}
+	}
+	t.Fatalf("Transaction %s was not loaded", id)
+	return nil
+}
+
+// assertTransactionRoundTrip compares a loaded transaction with the one saved.
+// Column timestamps come back in the session's time zone, so they are
+// compared as instants.
+func assertTransactionRoundTrip(t *testing.T, got, want *payments.Transaction) {
+	t.Helper()
+	sameInstant := func(name string, got, want *time.Time) {
+		if (got == nil) != (want == nil) || (got != nil && !got.Equal(*want)) {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	sameInstant("created_at", &got.CreatedAt, &want.CreatedAt)
+	sameInstant("processed_at", got.ProcessedAt, want.ProcessedAt)
+	sameInstant("completed_at", got.CompletedAt, want.CompletedAt)
+	sameInstant("quote_expires_at", got.QuoteExpiresAt, want.QuoteExpiresAt)
+
+	normalized := *got
+	normalized.CreatedAt = want.CreatedAt
+	normalized.ProcessedAt, normalized.CompletedAt, normalized.QuoteExpiresAt = want.ProcessedAt, want.CompletedAt, want.QuoteExpiresAt
+	if !reflect.DeepEqual(&normalized, want) {
+		t.Errorf("Loaded transaction differs\ngot  %+v\nwant %+v", &normalized, want)
+	}
+}
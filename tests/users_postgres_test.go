@@ -0,0 +1,116 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/storage/postgres"
+	"github.com/plm/predictive-liquidity-mesh/storage/users"
+)
+
+// TestPostgresUserStore verifies users are created, looked up, kept unique
+// and updated in the users table
+func TestPostgresUserStore(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client, err := postgres.NewClient(ctx, postgres.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer client.Close()
+
+	store, err := users.NewPostgresStore(ctx, client.DB())
+	if err != nil {
+		t.Fatalf("Failed to create user store: %v", err)
+	}
+
+	suffix := uuid.New().String()[:8]
+	email, username, password := "store_"+suffix+"@plm.test", "store_"+suffix, "correct-horse-"+suffix
+	defer client.DB().ExecContext(context.Background(), `DELETE FROM users WHERE email LIKE $1`, "%_"+suffix+"@plm.test")
+
+	created, err := store.CreateUser(email, password, username, auth.RoleUser)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	id := created.ToUser().ID
+
+	t.Run("Lookup", func(t *testing.T) {
+		byID, err := store.GetByID(id)
+		if err != nil {
+			t.Fatalf("GetByID failed: %v", err)
+		}
+		if byID.Email != email || byID.Username != username || byID.Role != auth.RoleUser || !byID.IsActive {
+			t.Errorf("Unexpected user %+v", byID)
+		}
+		if byID.PasswordHash == "" || byID.PasswordHash == password {
+			t.Error("Expected the password to be stored hashed")
+		}
+
+		byEmail, err := store.GetByEmail(email)
+		if err != nil {
+			t.Fatalf("GetByEmail failed: %v", err)
+		}
+		if got := byEmail.ToUser().ID; got != id {
+			t.Errorf("GetByEmail returned %s, want %s", got, id)
+		}
+
+		if _, err := store.Authenticate(email, password); err != nil {
+			t.Errorf("Authenticate failed: %v", err)
+		}
+		if _, err := store.Authenticate(email, "wrong"); !errors.Is(err, users.ErrInvalidCredentials) {
+			t.Errorf("Expected ErrInvalidCredentials for a wrong password, got %v", err)
+		}
+
+		if _, err := store.GetByID("not-a-uuid"); !errors.Is(err, users.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound for a malformed ID, got %v", err)
+		}
+		if _, err := store.GetByEmail("missing_" + suffix + "@plm.test"); !errors.Is(err, users.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound for an unknown email, got %v", err)
+		}
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		if _, err := store.CreateUser("other_"+suffix+"@plm.test", password, username, auth.RoleUser); !errors.Is(err, users.ErrUsernameExists) {
+			t.Errorf("Expected ErrUsernameExists, got %v", err)
+		}
+		if _, err := store.CreateUser(email, password, "other_"+suffix, auth.RoleUser); !errors.Is(err, users.ErrEmailExists) {
+			t.Errorf("Expected ErrEmailExists, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		role := auth.RoleAuditor
+		updated, err := store.UpdateUser(id, users.UserUpdate{Role: &role})
+		if err != nil {
+			t.Fatalf("UpdateUser failed: %v", err)
+		}
+		if updated.Role != auth.RoleAuditor || !updated.IsActive {
+			t.Errorf("Expected an active auditor, got %+v", updated)
+		}
+
+		inactive := false
+		if updated, err = store.UpdateUser(id, users.UserUpdate{IsActive: &inactive}); err != nil {
+			t.Fatalf("UpdateUser failed: %v", err)
+		}
+		if updated.Role != auth.RoleAuditor || updated.IsActive {
+			t.Errorf("Expected the role kept and the user deactivated, got %+v", updated)
+		}
+		if _, err := store.Authenticate(email, password); !errors.Is(err, users.ErrInvalidCredentials) {
+			t.Errorf("Expected a deactivated user to be refused, got %v", err)
+		}
+
+		invalid := auth.Role("ROOT")
+		if _, err := store.UpdateUser(id, users.UserUpdate{Role: &invalid}); !errors.Is(err, users.ErrInvalidRole) {
+			t.Errorf("Expected ErrInvalidRole, got %v", err)
+		}
+		if _, err := store.UpdateUser(uuid.New().String(), users.UserUpdate{IsActive: &inactive}); !errors.Is(err, users.ErrUserNotFound) {
+			t.Errorf("Expected ErrUserNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,366 @@
+// Package webhooks delivers payment lifecycle events to integrator endpoints.
+// Deliveries are HMAC-SHA256 signed and retried with exponential backoff.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Delivery headers sent with every webhook POST
+const (
+	SignatureHeader = "X-PLM-Signature" // t=<unix>,v1=<hex hmac>
+	EventHeader     = "X-PLM-Event"
+	DeliveryHeader  = "X-PLM-Delivery"
+)
+
+// maxDeliveryLog is the number of deliveries kept per endpoint
+const maxDeliveryLog = 100
+
+// ErrEndpointNotFound is returned for unknown or foreign endpoint IDs
+var ErrEndpointNotFound = errors.New("webhook endpoint not found")
+
+// Config holds dispatcher configuration
+type Config struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Timeout        time.Duration // Per-request timeout
+	Workers        int
+	QueueSize      int
+}
+
+// DefaultConfig returns the default dispatcher configuration
+func DefaultConfig() *Config {
+	return &Config{
+		MaxAttempts:    5,
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     1 * time.Minute,
+		Timeout:        10 * time.Second,
+		Workers:        4,
+		QueueSize:      256,
+	}
+}
+
+// Endpoint is a registered webhook receiver
+type Endpoint struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`    // Empty = all events
+	AllUsers  bool      `json:"all_users"` // Admin endpoints receive every user's events
+	Secret    string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Event is the JSON body POSTed to endpoints
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// Delivery records a single delivery attempt
+type Delivery struct {
+	ID         string    `json:"id"`
+	EndpointID string    `json:"endpoint_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Success    bool      `json:"success"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// job is a queued event for a single endpoint
+type job struct {
+	endpoint *Endpoint
+	event    *Event
+	body     []byte
+}
+
+// Dispatcher stores endpoints and delivers events to them
+type Dispatcher struct {
+	cfg        *Config
+	client     *http.Client
+	queue      chan job
+	mu         sync.RWMutex
+	endpoints  map[string]*Endpoint
+	deliveries map[string][]*Delivery // endpointID -> most recent deliveries
+}
+
+// NewDispatcher creates a new webhook dispatcher
+func NewDispatcher(cfg *Config) *Dispatcher {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Dispatcher{
+		cfg:        cfg,
+		client:     &http.Client{Timeout: cfg.Timeout},
+		queue:      make(chan job, cfg.QueueSize),
+		endpoints:  make(map[string]*Endpoint),
+		deliveries: make(map[string][]*Delivery),
+	}
+}
+
+// Start runs the delivery workers until ctx is cancelled
+func (d *Dispatcher) Start(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-d.queue:
+					d.deliver(ctx, j)
+				}
+			}
+		}()
+	}
+	log.Printf("🪝 Webhook dispatcher started (%d workers)", d.cfg.Workers)
+	wg.Wait()
+}
+
+// Register creates a new endpoint and returns it with its signing secret
+func (d *Dispatcher) Register(userID, url string, events []string, allUsers bool) (*Endpoint, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate secret: %w", err)
+	}
+
+	ep := &Endpoint{
+		ID:        "wh_" + uuid.New().String()[:12],
+		UserID:    userID,
+		URL:       url,
+		Events:    events,
+		AllUsers:  allUsers,
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	d.mu.Lock()
+	d.endpoints[ep.ID] = ep
+	d.mu.Unlock()
+
+	log.Printf("🪝 Webhook %s registered for user %s → %s", ep.ID, userID, url)
+	return ep, nil
+}
+
+// Remove deletes an endpoint owned by userID
+func (d *Dispatcher) Remove(userID, endpointID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ep, ok := d.endpoints[endpointID]
+	if !ok || ep.UserID != userID {
+		return ErrEndpointNotFound
+	}
+	delete(d.endpoints, endpointID)
+	delete(d.deliveries, endpointID)
+	return nil
+}
+
+// List returns the endpoints owned by userID, oldest first
+func (d *Dispatcher) List(userID string) []*Endpoint {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	result := make([]*Endpoint, 0)
+	for _, ep := range d.endpoints {
+		if ep.UserID == userID {
+			result = append(result, ep)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+	return result
+}
+
+// Deliveries returns the delivery log for an endpoint owned by userID, newest first
+func (d *Dispatcher) Deliveries(userID, endpointID string) ([]*Delivery, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ep, ok := d.endpoints[endpointID]
+	if !ok || ep.UserID != userID {
+		return nil, ErrEndpointNotFound
+	}
+
+	entries := d.deliveries[endpointID]
+	result := make([]*Delivery, len(entries))
+	for i, del := range entries {
+		result[len(entries)-1-i] = del
+	}
+	return result, nil
+}
+
+// Dispatch queues an event for every endpoint subscribed to it.
+// userID is the owner of the resource the event is about.
+func (d *Dispatcher) Dispatch(eventType, userID string, data interface{}) {
+	event := &Event{
+		ID:        "evt_" + uuid.New().String()[:12],
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Data:      data,
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode webhook event %s: %v", eventType, err)
+		return
+	}
+
+	d.mu.RLock()
+	var targets []*Endpoint
+	for _, ep := range d.endpoints {
+		if (ep.UserID == userID || ep.AllUsers) && ep.subscribed(eventType) {
+			targets = append(targets, ep)
+		}
+	}
+	d.mu.RUnlock()
+
+	for _, ep := range targets {
+		select {
+		case d.queue <- job{endpoint: ep, event: event, body: body}:
+		default:
+			log.Printf("⚠️ Webhook queue full, dropping %s for %s", event.ID, ep.ID)
+		}
+	}
+}
+
+// subscribed reports whether the endpoint wants an event type
+func (ep *Endpoint) subscribed(eventType string) bool {
+	if len(ep.Events) == 0 {
+		return true
+	}
+	for _, e := range ep.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// deliver POSTs an event, retrying with exponential backoff until it succeeds
+func (d *Dispatcher) deliver(ctx context.Context, j job) {
+	backoff := d.cfg.InitialBackoff
+
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		del := d.attempt(ctx, j, attempt)
+		d.record(del)
+		if del.Success {
+			return
+		}
+
+		if attempt == d.cfg.MaxAttempts {
+			log.Printf("⚠️ Webhook %s to %s failed after %d attempts: %s", j.event.ID, j.endpoint.ID, attempt, del.Error)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > d.cfg.MaxBackoff {
+			backoff = d.cfg.MaxBackoff
+		}
+	}
+}
+
+// attempt makes a single signed POST
+func (d *Dispatcher) attempt(ctx context.Context, j job, attempt int) *Delivery {
+	del := &Delivery{
+		ID:         uuid.New().String(),
+		EndpointID: j.endpoint.ID,
+		EventID:    j.event.ID,
+		EventType:  j.event.Type,
+		Attempt:    attempt,
+		Timestamp:  time.Now(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.endpoint.URL, bytes.NewReader(j.body))
+	if err != nil {
+		del.Error = err.Error()
+		return del
+	}
+
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "PLM-Webhooks/1.0")
+	req.Header.Set(EventHeader, j.event.Type)
+	req.Header.Set(DeliveryHeader, del.ID)
+	req.Header.Set(SignatureHeader, fmt.Sprintf("t=%d,v1=%s", ts, Sign(j.endpoint.Secret, ts, j.body)))
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	del.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		del.Error = err.Error()
+		return del
+	}
+	resp.Body.Close()
+
+	del.StatusCode = resp.StatusCode
+	del.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !del.Success {
+		del.Error = "unexpected status " + strconv.Itoa(resp.StatusCode)
+	}
+	return del
+}
+
+// record appends to the endpoint's delivery log
+func (d *Dispatcher) record(del *Delivery) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.endpoints[del.EndpointID]; !ok {
+		return // Endpoint removed mid-delivery
+	}
+	entries := append(d.deliveries[del.EndpointID], del)
+	if len(entries) > maxDeliveryLog {
+		entries = entries[len(entries)-maxDeliveryLog:]
+	}
+	d.deliveries[del.EndpointID] = entries
+}
+
+// Sign computes the hex HMAC-SHA256 of "<timestamp>.<body>" with the endpoint secret.
+// Receivers recompute this to verify the X-PLM-Signature header.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// generateSecret returns a random signing secret
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
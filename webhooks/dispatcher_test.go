@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeliveryIsSignedAndRetried(t *testing.T) {
+	var calls int32
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	d := NewDispatcher(&Config{
+		MaxAttempts:    4,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+		Timeout:        time.Second,
+		Workers:        1,
+		QueueSize:      8,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go d.Start(ctx)
+
+	ep, err := d.Register("user-1", server.URL, []string{"payment.succeeded"}, false)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	d.Dispatch("payment.failed", "user-1", nil)                               // not subscribed
+	d.Dispatch("payment.succeeded", "user-2", nil)                            // other user
+	d.Dispatch("payment.succeeded", "user-1", map[string]string{"id": "txn"}) // delivered
+
+	var req *http.Request
+	var body []byte
+	select {
+	case req = <-received:
+		body = <-bodies
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	var ts int64
+	var sig string
+	if _, err := fmt.Sscanf(req.Header.Get(SignatureHeader), "t=%d,v1=%s", &ts, &sig); err != nil {
+		t.Fatalf("bad signature header %q: %v", req.Header.Get(SignatureHeader), err)
+	}
+	if sig != Sign(ep.Secret, ts, body) {
+		t.Fatal("signature does not match body")
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil || event.Type != "payment.succeeded" {
+		t.Fatalf("unexpected event %s (%v)", body, err)
+	}
+
+	// Delivery log is recorded after the successful response returns
+	deadline := time.Now().Add(time.Second)
+	var log []*Delivery
+	for time.Now().Before(deadline) {
+		log, _ = d.Deliveries("user-1", ep.ID)
+		if len(log) == 3 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if len(log) != 3 {
+		t.Fatalf("expected 3 delivery attempts, got %d", len(log))
+	}
+	if !log[0].Success || log[0].Attempt != 3 || log[2].Success || log[2].StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("unexpected delivery log: %+v %+v %+v", log[0], log[1], log[2])
+	}
+
+	if _, err := d.Deliveries("user-2", ep.ID); err != ErrEndpointNotFound {
+		t.Fatalf("other users must not see the delivery log, got %v", err)
+	}
+}
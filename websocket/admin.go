@@ -0,0 +1,50 @@
+package websocket
+
+import (
+	"net/http"
+
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+// MsgTypeAdminStats carries the aggregated platform stats pushed to admin dashboards
+const MsgTypeAdminStats MessageType = "ADMIN_STATS"
+
+// ServeAdminWS handles the admin dashboard channel. Unlike ServeWS it requires
+// an admin token on upgrade, and the client receives only ADMIN_STATS until it
+// subscribes to other types.
+func (h *Hub) ServeAdminWS(w http.ResponseWriter, r *http.Request) {
+	var user *auth.User
+	if h.tokens != nil {
+		var err error
+		if user, err = h.authenticate(middleware.TokenFromRequest(r)); err != nil {
+			apierror.Respond(w, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		if !user.IsAdmin() {
+			apierror.Respond(w, http.StatusForbidden, "insufficient permissions")
+			return
+		}
+	}
+
+	filter := newTopicFilter()
+	filter.types[MsgTypeAdminStats] = true
+	h.serve(w, r, user, filter)
+}
+
+// AdminCount returns the number of connected clients receiving admin stats,
+// so publishers can skip collecting them while no dashboard is open
+func (h *Hub) AdminCount() int {
+	probe := &Message{Type: MsgTypeAdminStats}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	count := 0
+	for client := range h.clients {
+		if client.allowed(probe) && client.filter.matches(probe) {
+			count++
+		}
+	}
+	return count
+}
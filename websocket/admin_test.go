@@ -0,0 +1,69 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+func TestServeAdminWSRequiresAdmin(t *testing.T) {
+	tm := newTestTokenManager(t)
+	hub := NewHub()
+	hub.SetTokenManager(tm)
+
+	userToken, _, err := tm.GenerateToken(&auth.User{ID: "user-alice", Username: "alice", Role: auth.RoleUser})
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"no token", "", http.StatusUnauthorized},
+		{"invalid token", "not-a-token", http.StatusUnauthorized},
+		{"non-admin", userToken, http.StatusForbidden},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/ws/admin", nil)
+		if tc.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+		}
+		rec := httptest.NewRecorder()
+		hub.ServeAdminWS(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s: status = %d, want %d", tc.name, rec.Code, tc.want)
+		}
+	}
+}
+
+func TestHubAdminCount(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHub()
+	hub.SetTokenManager(newTestTokenManager(t))
+	go hub.Run(ctx)
+
+	adminFilter := newTopicFilter()
+	adminFilter.types[MsgTypeAdminStats] = true
+	dashboard := &Client{hub: hub, send: make(chan *Message, 16), filter: adminFilter}
+	dashboard.user.Store(&auth.User{ID: "user-admin", Username: "admin", Role: auth.RoleAdmin})
+	unauthenticated := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	user := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	user.user.Store(&auth.User{ID: "user-alice", Username: "alice", Role: auth.RoleUser})
+
+	for _, c := range []*Client{dashboard, unauthenticated, user} {
+		hub.register <- c
+	}
+	for hub.ClientCount() < 3 {
+		time.Sleep(time.Millisecond)
+	}
+	if got := hub.AdminCount(); got != 1 {
+		t.Fatalf("AdminCount() = %d, want 1", got)
+	}
+}
@@ -0,0 +1,124 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+// MsgTypeAuthenticated acknowledges a successful auth request
+const MsgTypeAuthenticated MessageType = "AUTHENTICATED"
+
+// authTimeout is how long a client that connected without a token has to authenticate
+const authTimeout = 10 * time.Second
+
+// adminOnlyTypes are only delivered to admins: topology changes and node health
+var adminOnlyTypes = map[MessageType]bool{
+	MsgTypeCircuitBreaker:     true,
+	MsgTypeLiquidity:          true,
+	MsgTypeNodeStatus:         true,
+	"NODE_CREATED":            true,
+	"NODE_UPDATED":            true,
+	"NODE_DELETED":            true,
+	"EDGE_UPDATED":            true,
+	"EDGE_DELETED":            true,
+	"COUNTRY_EDGE_CREATED":    true,
+	"COUNTRY_EDGE_DELETED":    true,
+	"COUNTRY_GRAPH_REFRESHED": true,
+	"COUNTRY_STATUS":          true,
+	"COUNTRY_HOURS":           true,
+	"GRAPH_IMPORTED":          true,
+	"LEDGER_INTEGRITY_ALERT":  true,
+	MsgTypeAdminStats:         true,
+}
+
+// AuthRequest is the first message of a client that did not pass a token on upgrade.
+//
+//	{"action":"auth","token":"v2.local...."}
+type AuthRequest struct {
+	Action string `json:"action"`
+	Token  string `json:"token"`
+}
+
+// SetTokenManager requires clients to authenticate with an access token, either
+// as a token query parameter or Authorization header on upgrade, or in an auth
+// request within authTimeout of connecting. Without it every client is trusted.
+func (h *Hub) SetTokenManager(tm *auth.TokenManager) {
+	h.tokens = tm
+}
+
+// authenticate verifies token and returns the user it was issued to
+func (h *Hub) authenticate(token string) (*auth.User, error) {
+	if token == "" {
+		return nil, errors.New("missing token")
+	}
+	claims, err := h.tokens.VerifyToken(token)
+	if err != nil {
+		return nil, err
+	}
+	return middleware.UserFromClaims(claims), nil
+}
+
+// allowed reports whether msg may be delivered to the client.
+// Unauthenticated clients receive nothing, admin-only types go to admins and
+// user-scoped messages go to their user and admins.
+func (c *Client) allowed(msg *Message) bool {
+	if c.hub.tokens == nil {
+		return true
+	}
+	return msg.VisibleTo(c.user.Load())
+}
+
+// VisibleTo reports whether user may see msg: admin-only types go to admins
+// and user-scoped messages go to their user and admins. Nothing is visible
+// to a nil user.
+func (msg *Message) VisibleTo(user *auth.User) bool {
+	switch {
+	case user == nil:
+		return false
+	case user.IsAdmin():
+		return true
+	case adminOnlyTypes[msg.Type]:
+		return false
+	default:
+		return msg.userID == "" || msg.userID == user.ID
+	}
+}
+
+// authenticated reports whether the client may receive messages and subscribe
+func (c *Client) authenticated() bool {
+	return c.hub.tokens == nil || c.user.Load() != nil
+}
+
+// handleAuth authenticates the client from an auth request and acknowledges it
+func (c *Client) handleAuth(data []byte) error {
+	var req AuthRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.Action != "auth" {
+		return errors.New("authentication required")
+	}
+	user, err := c.hub.authenticate(req.Token)
+	if err != nil {
+		return err
+	}
+	c.user.Store(user)
+
+	c.hub.sendTo(c, &Message{
+		Type: MsgTypeAuthenticated,
+		Data: map[string]interface{}{
+			"user_id": user.ID,
+			"role":    user.Role,
+		},
+	})
+	return nil
+}
+
+// closeWithPolicyViolation tells the client why it is being disconnected
+func (c *Client) closeWithPolicyViolation(reason string) {
+	c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+		time.Now().Add(time.Second))
+}
@@ -0,0 +1,94 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+func newTestTokenManager(t *testing.T) *auth.TokenManager {
+	t.Helper()
+	tm, err := auth.NewTokenManager(&auth.TokenConfig{
+		SymmetricKey: "0123456789abcdef0123456789abcdef",
+		Issuer:       "plm-test",
+		TokenTTL:     time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewTokenManager: %v", err)
+	}
+	return tm
+}
+
+func TestHubRestrictsMessagesByRole(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tm := newTestTokenManager(t)
+	hub := NewHub()
+	hub.SetTokenManager(tm)
+	go hub.Run(ctx)
+
+	anonymous := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	alice := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	bob := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	admin := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	for _, c := range []*Client{anonymous, alice, bob, admin} {
+		hub.register <- c
+	}
+	for hub.ClientCount() < 4 {
+		time.Sleep(time.Millisecond)
+	}
+
+	for client, user := range map[*Client]*auth.User{
+		alice: {ID: "user-alice", Username: "alice", Role: auth.RoleUser},
+		bob:   {ID: "user-bob", Username: "bob", Role: auth.RoleUser},
+		admin: {ID: "user-admin", Username: "admin", Role: auth.RoleAdmin},
+	} {
+		token, _, err := tm.GenerateToken(user)
+		if err != nil {
+			t.Fatalf("GenerateToken: %v", err)
+		}
+		if err := client.handleAuth([]byte(`{"action":"auth","token":"` + token + `"}`)); err != nil {
+			t.Fatalf("handleAuth(%s): %v", user.Username, err)
+		}
+		if got := drain(client.send, 1); len(got) != 1 || got[0].Type != MsgTypeAuthenticated {
+			t.Fatalf("%s did not receive an auth acknowledgement", user.Username)
+		}
+	}
+
+	if err := anonymous.handleAuth([]byte(`{"action":"auth","token":"not-a-token"}`)); err == nil {
+		t.Fatal("handleAuth accepted an invalid token")
+	}
+	if err := anonymous.handleAuth([]byte(`{"action":"subscribe"}`)); err == nil {
+		t.Fatal("handleAuth accepted a non-auth message")
+	}
+
+	hub.BroadcastFXRates(map[string]float64{"EUR": 0.92})
+	hub.BroadcastJSON(map[string]interface{}{"type": "NODE_CREATED", "data": map[string]interface{}{"id": "NODE-X"}})
+	hub.BroadcastToUser("user-alice", map[string]interface{}{"type": "SCHEDULED_PAYMENT", "data": map[string]interface{}{"schedule_id": "sch-1"}})
+
+	cases := []struct {
+		name   string
+		client *Client
+		want   []MessageType
+	}{
+		{"anonymous", anonymous, nil},
+		{"alice", alice, []MessageType{MsgTypeFXUpdate, "SCHEDULED_PAYMENT"}},
+		{"bob", bob, []MessageType{MsgTypeFXUpdate}},
+		{"admin", admin, []MessageType{MsgTypeFXUpdate, "NODE_CREATED", "SCHEDULED_PAYMENT"}},
+	}
+	for _, tc := range cases {
+		got := drain(tc.client.send, len(tc.want)+1)
+		if len(got) != len(tc.want) {
+			t.Errorf("%s got %d messages, want %d", tc.name, len(got), len(tc.want))
+			continue
+		}
+		for i, msg := range got {
+			if msg.Type != tc.want[i] {
+				t.Errorf("%s message %d = %s, want %s", tc.name, i, msg.Type, tc.want[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+// HubConfig configures per-client buffering and slow-consumer eviction
+type HubConfig struct {
+	BroadcastBuffer int // Messages queued for fan-out to clients
+	SendBuffer      int // Messages queued per client
+	MaxDropped      int // Consecutive dropped messages before a client is disconnected (0 = never)
+}
+
+// DefaultHubConfig returns the default hub configuration
+func DefaultHubConfig() HubConfig {
+	return HubConfig{
+		BroadcastBuffer: 256,
+		SendBuffer:      64,
+		MaxDropped:      32,
+	}
+}
+
+// coalesceKey returns the entity msg describes when only its latest state
+// matters, or "" when every message must be delivered
+func coalesceKey(msg *Message) string {
+	switch data := msg.Data.(type) {
+	case *NodeStatusUpdate:
+		if msg.Type == MsgTypeNodeStatus {
+			return "node:" + data.NodeID
+		}
+	case *LiquidityUpdate:
+		if msg.Type == MsgTypeLiquidity {
+			return "edge:" + data.SourceID + "->" + data.TargetID
+		}
+	case *FXRateUpdate:
+		if msg.Type == MsgTypeFXRate {
+			return "fx"
+		}
+	}
+	return ""
+}
+
+// deliver queues msg for client without blocking the hub. Updates that can be
+// coalesced wait in the client's pending set when its buffer is full, replacing
+// any older update for the same entity; other messages are dropped. It reports
+// false once the client has dropped MaxDropped messages in a row.
+func (h *Hub) deliver(client *Client, msg *Message) bool {
+	key := coalesceKey(msg)
+	if key != "" && client.replacePending(key, msg) {
+		metrics.WebSocketCoalesced.Inc(string(msg.Type))
+		return true
+	}
+
+	select {
+	case client.send <- msg:
+		client.dropped = 0
+		return true
+	default:
+	}
+
+	if key != "" {
+		client.addPending(key, msg)
+		return true
+	}
+
+	metrics.WebSocketDropped.Inc(string(msg.Type))
+	client.dropped++
+	return h.config.MaxDropped == 0 || client.dropped < h.config.MaxDropped
+}
+
+// replacePending swaps in msg if an update for key is already pending, so a
+// newer update never overtakes an older one
+func (c *Client) replacePending(key string, msg *Message) bool {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	if _, ok := c.pending[key]; !ok {
+		return false
+	}
+	c.pending[key] = msg
+	return true
+}
+
+// addPending holds msg until the write pump has room, waking it up
+func (c *Client) addPending(key string, msg *Message) {
+	c.pendingMu.Lock()
+	if c.pending == nil {
+		c.pending = make(map[string]*Message)
+	}
+	if _, ok := c.pending[key]; !ok {
+		c.pendingOrder = append(c.pendingOrder, key)
+	}
+	c.pending[key] = msg
+	c.pendingMu.Unlock()
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// takePending removes and returns the pending updates in the order their entities were first queued
+func (c *Client) takePending() []*Message {
+	c.pendingMu.Lock()
+	defer c.pendingMu.Unlock()
+	msgs := make([]*Message, 0, len(c.pendingOrder))
+	for _, key := range c.pendingOrder {
+		msgs = append(msgs, c.pending[key])
+	}
+	c.pending = nil
+	c.pendingOrder = nil
+	return msgs
+}
@@ -0,0 +1,60 @@
+package websocket
+
+import (
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
+)
+
+func TestDeliverCoalescesAndEvictsSlowClients(t *testing.T) {
+	hub := NewHubWithConfig(HubConfig{BroadcastBuffer: 16, SendBuffer: 1, MaxDropped: 2})
+	client := &Client{hub: hub, send: make(chan *Message, 1), wake: make(chan struct{}, 1), filter: newTopicFilter()}
+
+	if !hub.deliver(client, &Message{Type: MsgTypePathUpdate, Data: &PathUpdate{TransactionID: "txn-1"}}) {
+		t.Fatal("client evicted with room in its buffer")
+	}
+
+	// The buffer is full: status updates wait, collapsed per node
+	coalesced := metrics.WebSocketCoalesced.Value(string(MsgTypeNodeStatus))
+	for _, update := range []*NodeStatusUpdate{
+		{NodeID: "NODE-A", IsActive: true, Load: 10},
+		{NodeID: "NODE-B", IsActive: true, Load: 20},
+		{NodeID: "NODE-A", IsActive: false, Load: 30},
+		{NodeID: "NODE-A", IsActive: true, Load: 40},
+	} {
+		if !hub.deliver(client, &Message{Type: MsgTypeNodeStatus, Data: update}) {
+			t.Fatal("client evicted for a coalesced update")
+		}
+	}
+	if got := metrics.WebSocketCoalesced.Value(string(MsgTypeNodeStatus)) - coalesced; got != 2 {
+		t.Errorf("coalesced %v updates, want 2", got)
+	}
+	select {
+	case <-client.wake:
+	default:
+		t.Error("write pump was not woken for pending updates")
+	}
+
+	pending := client.takePending()
+	if len(pending) != 2 {
+		t.Fatalf("got %d pending updates, want 2", len(pending))
+	}
+	if a := pending[0].Data.(*NodeStatusUpdate); a.NodeID != "NODE-A" || a.Load != 40 {
+		t.Errorf("first pending update = %+v, want latest NODE-A", a)
+	}
+	if b := pending[1].Data.(*NodeStatusUpdate); b.NodeID != "NODE-B" {
+		t.Errorf("second pending update = %+v, want NODE-B", b)
+	}
+
+	// Other messages are dropped, and the client is evicted after MaxDropped in a row
+	dropped := metrics.WebSocketDropped.Value(string(MsgTypePathUpdate))
+	if !hub.deliver(client, &Message{Type: MsgTypePathUpdate, Data: &PathUpdate{TransactionID: "txn-2"}}) {
+		t.Fatal("client evicted after one dropped message")
+	}
+	if hub.deliver(client, &Message{Type: MsgTypePathUpdate, Data: &PathUpdate{TransactionID: "txn-3"}}) {
+		t.Error("client not evicted after MaxDropped dropped messages")
+	}
+	if got := metrics.WebSocketDropped.Value(string(MsgTypePathUpdate)) - dropped; got != 2 {
+		t.Errorf("dropped %v messages, want 2", got)
+	}
+}
@@ -0,0 +1,33 @@
+package websocket
+
+import "context"
+
+// Listen returns a channel receiving every broadcast message until ctx is
+// done, when it is closed. It is for in-process consumers such as GraphQL
+// subscriptions, which filter by Message.VisibleTo themselves. Messages are
+// dropped while the listener is more than buffer behind.
+func (h *Hub) Listen(ctx context.Context, buffer int) <-chan *Message {
+	ch := make(chan *Message, buffer)
+	h.mu.Lock()
+	h.listeners[ch] = true
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.listeners, ch)
+		close(ch)
+		h.mu.Unlock()
+	}()
+	return ch
+}
+
+// notifyListeners hands msg to the listeners. Caller must hold at least h.mu.RLock.
+func (h *Hub) notifyListeners(msg *Message) {
+	for ch := range h.listeners {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
@@ -8,10 +8,14 @@ import (
 	"log"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/plm/predictive-liquidity-mesh/api/apierror"
 	"github.com/plm/predictive-liquidity-mesh/api/middleware"
+	"github.com/plm/predictive-liquidity-mesh/auth"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
 )
 
 // MessageType represents the type of WebSocket message
@@ -24,10 +28,14 @@ const (
 	MsgTypeCircuitBreaker MessageType = "CIRCUIT_BREAKER"
 	// MsgTypeLiquidity indicates liquidity volume change
 	MsgTypeLiquidity MessageType = "LIQUIDITY_UPDATE"
+	// MsgTypeLiquidityAlert indicates an edge's utilization crossed the rebalancing threshold
+	MsgTypeLiquidityAlert MessageType = "LIQUIDITY_ALERT"
 	// MsgTypeNodeStatus indicates node status change
 	MsgTypeNodeStatus MessageType = "NODE_STATUS"
 	// MsgTypeFXUpdate indicates FX rate update
 	MsgTypeFXUpdate MessageType = "fx_update"
+	// MsgTypeFXRate indicates freshly fetched FX rates with their timestamps
+	MsgTypeFXRate MessageType = "FX_RATE"
 )
 
 // Message represents a WebSocket message to the frontend
@@ -35,6 +43,8 @@ type Message struct {
 	Type      MessageType `json:"type"`
 	Timestamp int64       `json:"timestamp"`
 	Data      interface{} `json:"data"`
+
+	userID string // When set, only this user and admins receive the message
 }
 
 // PathUpdate represents a transaction path event
@@ -63,6 +73,19 @@ type LiquidityUpdate struct {
 	Change    float64 `json:"change_percent"`
 }
 
+// LiquidityAlert warns that an edge is running out of liquidity
+type LiquidityAlert struct {
+	SourceID    string  `json:"source_id"`
+	TargetID    string  `json:"target_id"`
+	Utilization float64 `json:"utilization_percent"`
+	Threshold   float64 `json:"threshold_percent"`
+	Available   int64   `json:"available"`
+	// Suggested transfer from a less utilized edge, when one can cover it
+	FromSourceID string `json:"from_source_id,omitempty"`
+	FromTargetID string `json:"from_target_id,omitempty"`
+	Amount       int64  `json:"amount,omitempty"`
+}
+
 // NodeStatusUpdate represents a node status change
 type NodeStatusUpdate struct {
 	NodeID   string `json:"node_id"`
@@ -70,20 +93,34 @@ type NodeStatusUpdate struct {
 	Load     int    `json:"load_percent,omitempty"`
 }
 
-// Hub manages WebSocket connections and broadcasts
+// Hub manages WebSocket connections and broadcasts.
+// Clients may narrow what they receive by sending a SubscriptionRequest.
+// Slow clients have messages dropped, and are disconnected only after
+// HubConfig.MaxDropped drops in a row.
 type Hub struct {
 	clients    map[*Client]bool
 	broadcast  chan *Message
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
+	tokens     *auth.TokenManager // nil = authentication disabled
+	config     HubConfig
+	listeners  map[chan *Message]bool // In-process consumers (see Listen)
 }
 
 // Client represents a connected WebSocket client
 type Client struct {
-	hub  *Hub
-	conn *websocket.Conn
-	send chan *Message
+	hub    *Hub
+	conn   *websocket.Conn
+	send   chan *Message
+	filter *topicFilter
+	user   atomic.Pointer[auth.User] // nil until authenticated
+
+	dropped      int                 // Consecutive dropped messages, owned by Hub.Run
+	wake         chan struct{}       // Signals the write pump that updates are pending
+	pendingMu    sync.Mutex
+	pending      map[string]*Message // Latest coalesced update per entity
+	pendingOrder []string
 }
 
 // upgrader configures the WebSocket upgrade
@@ -96,13 +133,20 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-// NewHub creates a new WebSocket hub
+// NewHub creates a new WebSocket hub with the default configuration
 func NewHub() *Hub {
+	return NewHubWithConfig(DefaultHubConfig())
+}
+
+// NewHubWithConfig creates a new WebSocket hub
+func NewHubWithConfig(cfg HubConfig) *Hub {
 	return &Hub{
 		clients:    make(map[*Client]bool),
-		broadcast:  make(chan *Message, 256),
+		broadcast:  make(chan *Message, cfg.BroadcastBuffer),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
+		config:     cfg,
+		listeners:  make(map[chan *Message]bool),
 	}
 }
 
@@ -127,10 +171,14 @@ func (h *Hub) Run(ctx context.Context) {
 			log.Printf("WebSocket client disconnected (total: %d)", len(h.clients))
 		case message := <-h.broadcast:
 			h.mu.RLock()
+			h.notifyListeners(message)
 			for client := range h.clients {
-				select {
-				case client.send <- message:
-				default:
+				if !client.allowed(message) || (client.filter != nil && !client.filter.matches(message)) {
+					continue
+				}
+				if !h.deliver(client, message) {
+					log.Printf("WebSocket client evicted after %d dropped messages", client.dropped)
+					metrics.WebSocketEvictions.Inc()
 					h.mu.RUnlock()
 					h.mu.Lock()
 					delete(h.clients, client)
@@ -150,6 +198,21 @@ func (h *Hub) Broadcast(msg *Message) {
 	h.broadcast <- msg
 }
 
+// sendTo sends a message to a single client if it is still connected
+func (h *Hub) sendTo(client *Client, msg *Message) {
+	msg.Timestamp = time.Now().UnixMilli()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !h.clients[client] {
+		return
+	}
+	select {
+	case client.send <- msg:
+	default:
+	}
+}
+
 // BroadcastPathUpdate sends a path update to all clients
 func (h *Hub) BroadcastPathUpdate(update *PathUpdate) {
 	h.Broadcast(&Message{
@@ -174,6 +237,14 @@ func (h *Hub) BroadcastLiquidity(update *LiquidityUpdate) {
 	})
 }
 
+// BroadcastLiquidityAlert sends a liquidity utilization alert
+func (h *Hub) BroadcastLiquidityAlert(alert *LiquidityAlert) {
+	h.Broadcast(&Message{
+		Type: MsgTypeLiquidityAlert,
+		Data: alert,
+	})
+}
+
 // BroadcastNodeStatus sends a node status update
 func (h *Hub) BroadcastNodeStatus(update *NodeStatusUpdate) {
 	h.Broadcast(&Message{
@@ -184,7 +255,18 @@ func (h *Hub) BroadcastNodeStatus(update *NodeStatusUpdate) {
 
 // FXRateUpdate represents FX rate data for broadcasting
 type FXRateUpdate struct {
-	Rates map[string]float64 `json:"rates"`
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"` // Keyed by currency
+	Provider  string             `json:"provider,omitempty"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// BroadcastFXRate sends freshly fetched FX rates to all clients
+func (h *Hub) BroadcastFXRate(update *FXRateUpdate) {
+	h.Broadcast(&Message{
+		Type: MsgTypeFXRate,
+		Data: update,
+	})
 }
 
 // BroadcastFXRates sends FX rate updates to all clients
@@ -216,8 +298,36 @@ func (h *Hub) BroadcastJSON(data map[string]interface{}) {
 	h.broadcast <- msg
 }
 
-// ServeWS handles WebSocket upgrade requests
+// BroadcastToUser sends a generic JSON object to the clients of one user and to admins
+func (h *Hub) BroadcastToUser(userID string, data map[string]interface{}) {
+	h.broadcast <- &Message{
+		Type:      MessageType(data["type"].(string)),
+		Timestamp: time.Now().UnixMilli(),
+		Data:      data["data"],
+		userID:    userID,
+	}
+}
+
+// ServeWS handles WebSocket upgrade requests.
+// When authentication is enabled, a token passed on upgrade must be valid.
 func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
+	var user *auth.User
+	if h.tokens != nil {
+		if token := middleware.TokenFromRequest(r); token != "" {
+			var err error
+			if user, err = h.authenticate(token); err != nil {
+				apierror.Respond(w, http.StatusUnauthorized, "invalid token")
+				return
+			}
+		}
+	}
+
+	h.serve(w, r, user, newTopicFilter())
+}
+
+// serve upgrades the connection and registers a client for user (nil until
+// it authenticates) receiving what filter matches
+func (h *Hub) serve(w http.ResponseWriter, r *http.Request, user *auth.User, filter *topicFilter) {
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
 		log.Printf("WebSocket upgrade failed: %v", err)
@@ -225,10 +335,13 @@ func (h *Hub) ServeWS(w http.ResponseWriter, r *http.Request) {
 	}
 
 	client := &Client{
-		hub:  h,
-		conn: conn,
-		send: make(chan *Message, 64),
+		hub:    h,
+		conn:   conn,
+		send:   make(chan *Message, h.config.SendBuffer),
+		wake:   make(chan struct{}, 1),
+		filter: filter,
 	}
+	client.user.Store(user)
 
 	h.register <- client
 
@@ -253,16 +366,16 @@ func (c *Client) writePump() {
 				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
 				return
 			}
-
-			data, err := json.Marshal(message)
-			if err != nil {
-				log.Printf("Failed to marshal message: %v", err)
-				continue
-			}
-
-			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+			if err := c.write(message); err != nil {
 				return
 			}
+		case <-c.wake:
+			for _, message := range c.takePending() {
+				c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+				if err := c.write(message); err != nil {
+					return
+				}
+			}
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
@@ -272,6 +385,16 @@ func (c *Client) writePump() {
 	}
 }
 
+// write sends a single message to the websocket connection
+func (c *Client) write(message *Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Printf("Failed to marshal message: %v", err)
+		return nil
+	}
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
 // readPump pumps messages from the websocket connection to hub
 func (c *Client) readPump() {
 	defer func() {
@@ -279,24 +402,52 @@ func (c *Client) readPump() {
 		c.conn.Close()
 	}()
 
-	c.conn.SetReadLimit(512)
-	c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	c.conn.SetReadLimit(4096)
+	if c.authenticated() {
+		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+	} else {
+		c.conn.SetReadDeadline(time.Now().Add(authTimeout))
+	}
 	c.conn.SetPongHandler(func(string) error {
 		c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
 		return nil
 	})
 
 	for {
-		_, _, err := c.conn.ReadMessage()
+		_, data, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+		if !c.authenticated() {
+			if err := c.handleAuth(data); err != nil {
+				log.Printf("WebSocket: authentication failed: %v", err)
+				c.closeWithPolicyViolation("authentication required")
+				break
+			}
+			c.conn.SetReadDeadline(time.Now().Add(60 * time.Second))
+			continue
+		}
+		c.handleSubscription(data)
 	}
 }
 
+// handleSubscription applies a subscribe/unsubscribe request and acknowledges it
+func (c *Client) handleSubscription(data []byte) {
+	req, err := parseSubscription(data)
+	if err != nil || !c.filter.apply(req) {
+		log.Printf("WebSocket: ignoring invalid subscription message")
+		return
+	}
+
+	c.hub.sendTo(c, &Message{
+		Type: MsgTypeSubscribed,
+		Data: c.filter.snapshot(),
+	})
+}
+
 // Server provides the HTTP server for WebSocket connections
 type Server struct {
 	hub    *Hub
@@ -304,8 +455,8 @@ type Server struct {
 }
 
 // NewServer creates a new WebSocket server
-func NewServer(addr string) *Server {
-	hub := NewHub()
+func NewServer(addr string, cfg HubConfig) *Server {
+	hub := NewHubWithConfig(cfg)
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/ws", hub.ServeWS)
@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// MsgTypeSubscribed acknowledges a subscribe/unsubscribe request
+const MsgTypeSubscribed MessageType = "SUBSCRIBED"
+
+// SubscriptionRequest is sent by clients to change which events they receive.
+//
+//	{"action":"subscribe","types":["PATH_UPDATE"],"transaction_ids":["txn_123"]}
+//	{"action":"unsubscribe","node_ids":["NODE-A"]}
+//	{"action":"reset"}
+type SubscriptionRequest struct {
+	Action         string        `json:"action"` // "subscribe", "unsubscribe", "reset"
+	Types          []MessageType `json:"types,omitempty"`
+	NodeIDs        []string      `json:"node_ids,omitempty"`
+	TransactionIDs []string      `json:"transaction_ids,omitempty"`
+}
+
+// Subscription is the set of topics a client is subscribed to
+type Subscription struct {
+	Types          []MessageType `json:"types"`
+	NodeIDs        []string      `json:"node_ids"`
+	TransactionIDs []string      `json:"transaction_ids"`
+}
+
+// topicFilter holds a client's subscriptions.
+// An empty filter receives everything, so existing clients are unaffected.
+type topicFilter struct {
+	mu           sync.RWMutex
+	types        map[MessageType]bool
+	nodes        map[string]bool
+	transactions map[string]bool
+}
+
+func newTopicFilter() *topicFilter {
+	return &topicFilter{
+		types:        make(map[MessageType]bool),
+		nodes:        make(map[string]bool),
+		transactions: make(map[string]bool),
+	}
+}
+
+// apply updates the filter from a subscription request
+func (f *topicFilter) apply(req *SubscriptionRequest) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch req.Action {
+	case "subscribe":
+		for _, t := range req.Types {
+			f.types[t] = true
+		}
+		for _, id := range req.NodeIDs {
+			f.nodes[id] = true
+		}
+		for _, id := range req.TransactionIDs {
+			f.transactions[id] = true
+		}
+	case "unsubscribe":
+		for _, t := range req.Types {
+			delete(f.types, t)
+		}
+		for _, id := range req.NodeIDs {
+			delete(f.nodes, id)
+		}
+		for _, id := range req.TransactionIDs {
+			delete(f.transactions, id)
+		}
+	case "reset":
+		f.types = make(map[MessageType]bool)
+		f.nodes = make(map[string]bool)
+		f.transactions = make(map[string]bool)
+	default:
+		return false
+	}
+	return true
+}
+
+// snapshot returns the current subscriptions
+func (f *topicFilter) snapshot() Subscription {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	sub := Subscription{
+		Types:          make([]MessageType, 0, len(f.types)),
+		NodeIDs:        make([]string, 0, len(f.nodes)),
+		TransactionIDs: make([]string, 0, len(f.transactions)),
+	}
+	for t := range f.types {
+		sub.Types = append(sub.Types, t)
+	}
+	for id := range f.nodes {
+		sub.NodeIDs = append(sub.NodeIDs, id)
+	}
+	for id := range f.transactions {
+		sub.TransactionIDs = append(sub.TransactionIDs, id)
+	}
+	return sub
+}
+
+// matches reports whether a message passes the filter.
+// Type filters and entity (node/transaction) filters must both pass when set.
+func (f *topicFilter) matches(msg *Message) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if len(f.types) > 0 && !f.types[msg.Type] {
+		return false
+	}
+	if len(f.nodes) == 0 && len(f.transactions) == 0 {
+		return true
+	}
+
+	nodes, txnID := messageTopics(msg)
+	if txnID != "" && f.transactions[txnID] {
+		return true
+	}
+	for _, id := range nodes {
+		if f.nodes[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// messageTopics extracts the node IDs and transaction ID a message is about
+func messageTopics(msg *Message) ([]string, string) {
+	switch data := msg.Data.(type) {
+	case *PathUpdate:
+		return data.Path, data.TransactionID
+	case *CircuitBreakerEvent:
+		return []string{data.NodeID}, ""
+	case *LiquidityUpdate:
+		return []string{data.SourceID, data.TargetID}, ""
+	case *LiquidityAlert:
+		return []string{data.SourceID, data.TargetID}, ""
+	case *NodeStatusUpdate:
+		return []string{data.NodeID}, ""
+	case map[string]interface{}:
+		var nodes []string
+		if id, ok := data["node_id"].(string); ok {
+			nodes = append(nodes, id)
+		}
+		txnID, _ := data["transaction_id"].(string)
+		return nodes, txnID
+	}
+	return nil, ""
+}
+
+// parseSubscription decodes a client message into a subscription request
+func parseSubscription(data []byte) (*SubscriptionRequest, error) {
+	var req SubscriptionRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
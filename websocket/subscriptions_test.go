@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/auth"
+)
+
+func TestHubDeliversOnlySubscribedTopics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hub := NewHub()
+	go hub.Run(ctx)
+
+	all := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	txnOnly := &Client{hub: hub, send: make(chan *Message, 16), filter: newTopicFilter()}
+	hub.register <- all
+	hub.register <- txnOnly
+
+	txnOnly.filter.apply(&SubscriptionRequest{
+		Action:         "subscribe",
+		Types:          []MessageType{MsgTypePathUpdate},
+		TransactionIDs: []string{"txn-1"},
+	})
+
+	hub.BroadcastNodeStatus(&NodeStatusUpdate{NodeID: "NODE-A", IsActive: false})
+	hub.BroadcastPathUpdate(&PathUpdate{TransactionID: "txn-2", Path: []string{"NODE-A", "NODE-B"}})
+	hub.BroadcastPathUpdate(&PathUpdate{TransactionID: "txn-1", Path: []string{"NODE-A", "NODE-B"}})
+
+	if got := drain(all.send, 3); len(got) != 3 {
+		t.Fatalf("unfiltered client got %d messages, want 3", len(got))
+	}
+
+	got := drain(txnOnly.send, 1)
+	if len(got) != 1 {
+		t.Fatalf("filtered client got %d messages, want 1", len(got))
+	}
+	if update, ok := got[0].Data.(*PathUpdate); !ok || update.TransactionID != "txn-1" {
+		t.Fatalf("filtered client got %+v, want txn-1 path update", got[0].Data)
+	}
+	if extra := drain(txnOnly.send, 1); len(extra) != 0 {
+		t.Fatalf("filtered client got unexpected message %+v", extra[0].Data)
+	}
+}
+
+func TestTopicFilterNodeSubscription(t *testing.T) {
+	f := newTopicFilter()
+	f.apply(&SubscriptionRequest{Action: "subscribe", NodeIDs: []string{"NODE-B"}})
+
+	cases := []struct {
+		msg  *Message
+		want bool
+	}{
+		{&Message{Type: MsgTypeNodeStatus, Data: &NodeStatusUpdate{NodeID: "NODE-B"}}, true},
+		{&Message{Type: MsgTypeNodeStatus, Data: &NodeStatusUpdate{NodeID: "NODE-C"}}, false},
+		{&Message{Type: MsgTypeLiquidity, Data: &LiquidityUpdate{SourceID: "NODE-A", TargetID: "NODE-B"}}, true},
+		{&Message{Type: MsgTypePathUpdate, Data: &PathUpdate{Path: []string{"NODE-A", "NODE-B", "NODE-D"}}}, true},
+		{&Message{Type: MsgTypeFXUpdate, Data: map[string]interface{}{"rates": nil}}, false},
+	}
+	for i, c := range cases {
+		if got := f.matches(c.msg); got != c.want {
+			t.Errorf("case %d: matches = %v, want %v", i, got, c.want)
+		}
+	}
+
+	f.apply(&SubscriptionRequest{Action: "unsubscribe", NodeIDs: []string{"NODE-B"}})
+	if !f.matches(cases[1].msg) {
+		t.Error("empty filter should receive everything")
+	}
+}
+
+// drain reads up to n messages, waiting briefly for each
+func drain(ch <-chan *Message, n int) []*Message {
+	var msgs []*Message
+	for i := 0; i < n; i++ {
+		select {
+		case msg := <-ch:
+			msgs = append(msgs, msg)
+		case <-time.After(100 * time.Millisecond):
+			return msgs
+		}
+	}
+	return msgs
+}
+
+func TestListenReceivesBroadcastsUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	hub := NewHub()
+	go hub.Run(ctx)
+
+	listenCtx, stop := context.WithCancel(ctx)
+	events := hub.Listen(listenCtx, 4)
+	hub.BroadcastToUser("user-1", map[string]interface{}{"type": "PAYMENT_STATUS", "data": "txn-1"})
+
+	got := drain(events, 1)
+	if len(got) != 1 || got[0].Type != "PAYMENT_STATUS" {
+		t.Fatalf("listener got %+v", got)
+	}
+	if !got[0].VisibleTo(&auth.User{ID: "user-1", Role: auth.RoleUser}) || got[0].VisibleTo(&auth.User{ID: "user-2", Role: auth.RoleUser}) {
+		t.Error("user-scoped message should only be visible to its user")
+	}
+
+	stop()
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Error("expected the listener channel to close")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("listener channel not closed")
+	}
+}
@@ -0,0 +1,177 @@
+// Package adminstats aggregates platform stats and pushes them to admin
+// WebSocket clients every few seconds, so the admin dashboard does not have
+// to poll the stats endpoint.
+package adminstats
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/websocket"
+)
+
+// Config configures the publisher
+type Config struct {
+	Interval     time.Duration // Time between pushes
+	Timeout      time.Duration // Limit on reading circuit states and NATS lag
+	TopCorridors int           // Corridors listed by volume
+}
+
+// DefaultConfig returns default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:     5 * time.Second,
+		Timeout:      2 * time.Second,
+		TopCorridors: 5,
+	}
+}
+
+// Stats is one push to the admin dashboard
+type Stats struct {
+	At            time.Time         `json:"at"`
+	TotalVolume   float64           `json:"total_volume"`
+	TotalFees     float64           `json:"total_fees"`
+	Transactions  int               `json:"total_transactions"`
+	SuccessCount  int               `json:"success_count"`
+	FailedCount   int               `json:"failed_count"`
+	PendingCount  int               `json:"pending_count"`
+	SuccessRate   float64           `json:"success_rate"` // Percent of finished payments that succeeded
+	TopCorridors  []Corridor        `json:"top_corridors"`
+	Circuits      map[string]string `json:"circuits,omitempty"` // Node -> closed, open or half_open; omitted without Redis
+	ActiveClients int               `json:"active_clients"`     // Connected WebSocket clients
+	NATSLag       *uint64           `json:"nats_lag,omitempty"` // Liquidity updates not yet synced to Neo4j; omitted without NATS
+}
+
+// Corridor is the settled volume between two adjacent countries of payment routes
+type Corridor struct {
+	Source string  `json:"source"`
+	Target string  `json:"target"`
+	Count  int     `json:"count"`
+	Volume float64 `json:"volume"`
+}
+
+// StatsSource provides the platform totals; implemented by payments.TransactionStore
+type StatsSource interface {
+	PlatformStats(orgID string) *payments.PlatformStats
+}
+
+// Hub delivers the stats; implemented by websocket.Hub
+type Hub interface {
+	Broadcast(msg *websocket.Message)
+	AdminCount() int
+	ClientCount() int
+}
+
+// Publisher collects Stats and broadcasts them while an admin is connected
+type Publisher struct {
+	txns         StatsSource
+	hub          Hub
+	interval     time.Duration
+	timeout      time.Duration
+	topCorridors int
+
+	circuits func(ctx context.Context) (map[string]string, error)
+	lag      func(ctx context.Context) (uint64, error)
+}
+
+// NewPublisher creates a publisher of stats about txns to hub
+func NewPublisher(txns StatsSource, hub Hub, cfg *Config) *Publisher {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Publisher{
+		txns:         txns,
+		hub:          hub,
+		interval:     cfg.Interval,
+		timeout:      cfg.Timeout,
+		topCorridors: cfg.TopCorridors,
+	}
+}
+
+// SetCircuitSource sets where circuit breaker states are read from
+func (p *Publisher) SetCircuitSource(circuits func(ctx context.Context) (map[string]string, error)) {
+	p.circuits = circuits
+}
+
+// SetLagSource sets where the NATS consumer lag is read from
+func (p *Publisher) SetLagSource(lag func(ctx context.Context) (uint64, error)) {
+	p.lag = lag
+}
+
+// Start publishes every interval until ctx is done. Nothing is collected
+// while no admin is connected.
+func (p *Publisher) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if p.hub.AdminCount() == 0 {
+				continue
+			}
+			p.hub.Broadcast(&websocket.Message{Type: websocket.MsgTypeAdminStats, Data: p.Collect(ctx)})
+		}
+	}
+}
+
+// Collect gathers the current stats. Sources that fail are left out.
+func (p *Publisher) Collect(ctx context.Context) *Stats {
+	stats := p.summarize(p.txns.PlatformStats(""))
+	stats.At = time.Now().UTC()
+	stats.ActiveClients = p.hub.ClientCount()
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	if p.circuits != nil {
+		circuits, err := p.circuits(ctx)
+		if err != nil {
+			slog.WarnContext(ctx, "admin stats: failed to read circuit states", "error", err)
+		}
+		stats.Circuits = circuits
+	}
+	if p.lag != nil {
+		if lag, err := p.lag(ctx); err != nil {
+			slog.WarnContext(ctx, "admin stats: failed to read NATS lag", "error", err)
+		} else {
+			stats.NATSLag = &lag
+		}
+	}
+	return stats
+}
+
+// summarize turns the platform totals into a push
+func (p *Publisher) summarize(totals *payments.PlatformStats) *Stats {
+	stats := &Stats{
+		TotalVolume:  totals.TotalVolume,
+		TotalFees:    totals.TotalFees,
+		Transactions: totals.Transactions,
+		SuccessCount: totals.Count(payments.StatusSuccess),
+		FailedCount:  totals.Count(payments.StatusFailed),
+		TopCorridors: make([]Corridor, 0, len(totals.Corridors)),
+	}
+	stats.PendingCount = stats.Transactions - stats.SuccessCount - stats.FailedCount
+	if finished := stats.SuccessCount + stats.FailedCount; finished > 0 {
+		stats.SuccessRate = float64(stats.SuccessCount) / float64(finished) * 100
+	}
+
+	for key, c := range totals.Corridors {
+		stats.TopCorridors = append(stats.TopCorridors, Corridor{Source: key.Source, Target: key.Target, Count: c.Count, Volume: c.Volume})
+	}
+	sort.Slice(stats.TopCorridors, func(i, j int) bool {
+		a, b := stats.TopCorridors[i], stats.TopCorridors[j]
+		if a.Volume != b.Volume {
+			return a.Volume > b.Volume
+		}
+		return a.Source+a.Target < b.Source+b.Target
+	})
+	if len(stats.TopCorridors) > p.topCorridors {
+		stats.TopCorridors = stats.TopCorridors[:p.topCorridors]
+	}
+	return stats
+}
@@ -0,0 +1,74 @@
+package adminstats
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+	"github.com/plm/predictive-liquidity-mesh/websocket"
+)
+
+type fakeHub struct {
+	admins, clients int
+	sent            []*websocket.Message
+}
+
+func (h *fakeHub) Broadcast(msg *websocket.Message) { h.sent = append(h.sent, msg) }
+func (h *fakeHub) AdminCount() int                  { return h.admins }
+func (h *fakeHub) ClientCount() int                 { return h.clients }
+
+func TestSummarize(t *testing.T) {
+	store := payments.NewTransactionStore()
+	for _, txn := range []*payments.Transaction{
+		{ID: "txn-1", Amount: 100, TotalFees: 1, Status: payments.StatusSuccess, Route: []string{"USA", "GBR", "IND"}},
+		{ID: "txn-2", Amount: 50, TotalFees: 0.5, Status: payments.StatusSuccess, Route: []string{"USA", "GBR"}},
+		{ID: "txn-3", Amount: 20, Status: payments.StatusFailed, Route: []string{"USA", "DEU"}},
+		{ID: "txn-4", Amount: 10, Status: payments.StatusPending, Route: []string{"USA", "DEU"}},
+	} {
+		store.Restore(txn)
+	}
+	p := NewPublisher(store, &fakeHub{}, &Config{TopCorridors: 1})
+	stats := p.summarize(store.PlatformStats(""))
+
+	if stats.Transactions != 4 || stats.TotalVolume != 180 || stats.TotalFees != 1.5 {
+		t.Errorf("totals = %d txns, %v volume, %v fees", stats.Transactions, stats.TotalVolume, stats.TotalFees)
+	}
+	if stats.SuccessCount != 2 || stats.FailedCount != 1 || stats.PendingCount != 1 {
+		t.Errorf("counts = %d/%d/%d", stats.SuccessCount, stats.FailedCount, stats.PendingCount)
+	}
+	if want := 200.0 / 3; math.Abs(stats.SuccessRate-want) > 1e-9 {
+		t.Errorf("SuccessRate = %v, want %v", stats.SuccessRate, want)
+	}
+	// Only settled payments count towards corridors
+	want := Corridor{Source: "USA", Target: "GBR", Count: 2, Volume: 150}
+	if len(stats.TopCorridors) != 1 || stats.TopCorridors[0] != want {
+		t.Errorf("TopCorridors = %+v, want [%+v]", stats.TopCorridors, want)
+	}
+}
+
+func TestCollectSkipsFailedSources(t *testing.T) {
+	hub := &fakeHub{admins: 1, clients: 3}
+	p := NewPublisher(payments.NewTransactionStore(), hub, nil)
+	p.SetCircuitSource(func(context.Context) (map[string]string, error) {
+		return map[string]string{"NODE-A": "open"}, nil
+	})
+	p.SetLagSource(func(context.Context) (uint64, error) { return 0, errors.New("nats down") })
+
+	stats := p.Collect(context.Background())
+	if stats.ActiveClients != 3 {
+		t.Errorf("ActiveClients = %d, want 3", stats.ActiveClients)
+	}
+	if stats.Circuits["NODE-A"] != "open" {
+		t.Errorf("Circuits = %v", stats.Circuits)
+	}
+	if stats.NATSLag != nil {
+		t.Errorf("NATSLag = %d, want omitted", *stats.NATSLag)
+	}
+
+	p.SetLagSource(func(context.Context) (uint64, error) { return 7, nil })
+	if stats := p.Collect(context.Background()); stats.NATSLag == nil || *stats.NATSLag != 7 {
+		t.Errorf("NATSLag = %v, want 7", stats.NATSLag)
+	}
+}
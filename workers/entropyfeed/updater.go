@@ -0,0 +1,162 @@
+// Package entropyfeed derives mesh node entropy from settled payment flows.
+// Each completed settlement adds its amount to the outflow of every hop on
+// its path; the updater periodically turns each node's outflow over a sliding
+// window into a distribution and applies it with Graph.UpdateNodeEntropy.
+package entropyfeed
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// maxFlows bounds the flows held in the window; the oldest are dropped first
+const maxFlows = 100000
+
+// Config configures the entropy updater
+type Config struct {
+	Interval time.Duration // How often entropy is recomputed
+	Window   time.Duration // How far back settled flows count
+}
+
+// DefaultConfig returns default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Interval: time.Minute,
+		Window:   24 * time.Hour,
+	}
+}
+
+// flow is one settled hop from a node to its next hop
+type flow struct {
+	from, to string
+	amount   float64
+	at       time.Time
+}
+
+// Updater keeps a sliding window of settled flows and feeds their outflow
+// distributions into the mesh graph
+type Updater struct {
+	graph    *router.Graph
+	interval time.Duration
+	window   time.Duration
+
+	mu      sync.Mutex
+	flows   []flow          // Oldest first
+	applied map[string]bool // Nodes whose entropy the updater has set
+}
+
+// NewUpdater creates an updater for graph
+func NewUpdater(graph *router.Graph, cfg *Config) *Updater {
+	return &Updater{
+		graph:    graph,
+		interval: cfg.Interval,
+		window:   cfg.Window,
+		applied:  make(map[string]bool),
+	}
+}
+
+// Observe records a settlement of amount along path. Amounts of zero or less
+// count as one unit so every settlement shapes the distribution.
+func (u *Updater) Observe(path []string, amount float64, at time.Time) {
+	if len(path) < 2 {
+		return
+	}
+	if amount <= 0 {
+		amount = 1
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for i := 0; i+1 < len(path); i++ {
+		u.flows = append(u.flows, flow{from: path[i], to: path[i+1], amount: amount, at: at})
+	}
+	if len(u.flows) > maxFlows {
+		u.flows = u.flows[len(u.flows)-maxFlows:]
+	}
+}
+
+// ObserveTransaction records succeeded transactions. Its signature matches
+// TransactionStore.SetStatusCallback.
+func (u *Updater) ObserveTransaction(event payments.StatusEvent, txn *payments.Transaction) {
+	if event != payments.EventPaymentSucceeded {
+		return
+	}
+	at := time.Now()
+	if txn.CompletedAt != nil {
+		at = *txn.CompletedAt
+	}
+	u.Observe(txn.Route, txn.Amount, at)
+}
+
+// Distributions returns each node's outflow by next hop over the window
+// ending at now, dropping flows that have left the window
+func (u *Updater) Distributions(now time.Time) map[string]map[string]float64 {
+	cutoff := now.Add(-u.window)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	drop := 0
+	for drop < len(u.flows) && u.flows[drop].at.Before(cutoff) {
+		drop++
+	}
+	u.flows = u.flows[drop:]
+
+	distributions := make(map[string]map[string]float64)
+	for _, f := range u.flows {
+		if distributions[f.from] == nil {
+			distributions[f.from] = make(map[string]float64)
+		}
+		distributions[f.from][f.to] += f.amount
+	}
+	return distributions
+}
+
+// Refresh applies the current distributions to mesh nodes and clears the
+// entropy of nodes with no flows left in the window. Nodes that are not in
+// the mesh graph (e.g. country codes on payment routes) are ignored.
+// Returns the number of nodes updated.
+func (u *Updater) Refresh(now time.Time) int {
+	distributions := u.Distributions(now)
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	updated := 0
+	for nodeID, distribution := range distributions {
+		if u.graph.GetNode(nodeID) == nil {
+			continue
+		}
+		u.graph.UpdateNodeEntropy(nodeID, distribution)
+		u.applied[nodeID] = true
+		updated++
+	}
+	for nodeID := range u.applied {
+		if _, ok := distributions[nodeID]; !ok {
+			u.graph.ClearNodeEntropy(nodeID)
+			delete(u.applied, nodeID)
+		}
+	}
+	return updated
+}
+
+// Start recomputes entropy every interval until ctx is cancelled
+func (u *Updater) Start(ctx context.Context) {
+	log.Printf("🎲 Entropy updater started (every %v over %v of settlements)", u.interval, u.window)
+
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🎲 Entropy updater stopped")
+			return
+		case <-ticker.C:
+			u.Refresh(time.Now())
+		}
+	}
+}
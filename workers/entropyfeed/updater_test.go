@@ -0,0 +1,63 @@
+package entropyfeed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/engine/router"
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func testGraph() (*router.Graph, *router.Edge) {
+	graph := router.NewGraph()
+	for _, id := range []string{"A", "B", "C"} {
+		graph.AddNode(&router.Node{ID: id, IsActive: true})
+	}
+	edge := &router.Edge{SourceID: "A", TargetID: "B", BaseFee: 0.001, IsActive: true}
+	graph.AddEdge(edge)
+	graph.AddEdge(&router.Edge{SourceID: "A", TargetID: "C", BaseFee: 0.001, IsActive: true})
+	return graph, edge
+}
+
+func TestDistributionsFollowSettledFlows(t *testing.T) {
+	graph, _ := testGraph()
+	updater := NewUpdater(graph, &Config{Interval: time.Minute, Window: time.Hour})
+	now := time.Now()
+
+	updater.Observe([]string{"A", "B", "C"}, 100, now.Add(-2*time.Hour)) // Outside the window
+	updater.Observe([]string{"A", "B"}, 30, now.Add(-time.Minute))
+	updater.Observe([]string{"A", "C"}, 10, now)
+	updater.ObserveTransaction(payments.EventPaymentFailed, &payments.Transaction{Route: []string{"A", "C"}, Amount: 50})
+
+	dist := updater.Distributions(now)
+	if dist["A"]["B"] != 30 || dist["A"]["C"] != 10 {
+		t.Errorf("A outflow = %v, want B 30 and C 10", dist["A"])
+	}
+	if _, ok := dist["B"]; ok {
+		t.Errorf("B outflow = %v, want none inside the window", dist["B"])
+	}
+}
+
+func TestRefreshUpdatesAndClearsEntropy(t *testing.T) {
+	graph, edge := testGraph()
+	updater := NewUpdater(graph, &Config{Interval: time.Minute, Window: time.Hour})
+	base := graph.GetEdgeWeight(edge)
+	now := time.Now()
+
+	// An even split over two next hops is one bit of entropy
+	updater.Observe([]string{"A", "B"}, 20, now)
+	updater.Observe([]string{"A", "C"}, 20, now)
+	updater.Observe([]string{"USA", "DEU"}, 20, now) // Country route, not a mesh node
+	if n := updater.Refresh(now); n != 1 {
+		t.Fatalf("updated %d nodes, want only A", n)
+	}
+	if got := graph.GetEdgeWeight(edge); got <= base {
+		t.Fatalf("weight %v after an even split, want more than %v", got, base)
+	}
+
+	// Once the flows leave the window, A is weighted by fee alone again
+	updater.Refresh(now.Add(2 * time.Hour))
+	if got := graph.GetEdgeWeight(edge); got != base {
+		t.Errorf("weight %v after flows expired, want %v", got, base)
+	}
+}
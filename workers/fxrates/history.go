@@ -0,0 +1,131 @@
+package fxrates
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHistoryRetention is how long a MemoryHistory keeps snapshots
+const DefaultHistoryRetention = 90 * 24 * time.Hour
+
+// Point is one currency's rate at one fetch
+type Point struct {
+	Rate float64   `json:"rate"`
+	At   time.Time `json:"at"`
+}
+
+// HistoryStore persists fetched rate snapshots. Implemented by MemoryHistory
+// and the Postgres-backed store in storage/postgres.
+type HistoryStore interface {
+	// Append saves every rate in u as of u.FetchedAt
+	Append(ctx context.Context, u *Update) error
+	// History returns currency's rates fetched at or after since, oldest first
+	History(ctx context.Context, currency string, since time.Time) ([]Point, error)
+}
+
+// MemoryHistory is an in-memory HistoryStore that drops snapshots older than
+// its retention
+type MemoryHistory struct {
+	mu        sync.RWMutex
+	points    map[string][]Point
+	retention time.Duration
+}
+
+// NewMemoryHistory creates a history keeping snapshots for retention
+func NewMemoryHistory(retention time.Duration) *MemoryHistory {
+	if retention <= 0 {
+		retention = DefaultHistoryRetention
+	}
+	return &MemoryHistory{points: make(map[string][]Point), retention: retention}
+}
+
+// Append implements HistoryStore
+func (h *MemoryHistory) Append(ctx context.Context, u *Update) error {
+	cutoff := u.FetchedAt.Add(-h.retention)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for currency, rate := range u.Rates {
+		points := append(h.points[currency], Point{Rate: rate, At: u.FetchedAt})
+		drop := sort.Search(len(points), func(i int) bool { return !points[i].At.Before(cutoff) })
+		h.points[currency] = points[drop:]
+	}
+	return nil
+}
+
+// History implements HistoryStore
+func (h *MemoryHistory) History(ctx context.Context, currency string, since time.Time) ([]Point, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	points := h.points[currency]
+	from := sort.Search(len(points), func(i int) bool { return !points[i].At.Before(since) })
+	return append([]Point{}, points[from:]...), nil
+}
+
+// Stats summarizes a rate series for charting and risk estimates
+type Stats struct {
+	Points     int     `json:"points"`
+	First      float64 `json:"first"`
+	Last       float64 `json:"last"`
+	Min        float64 `json:"min"`
+	Max        float64 `json:"max"`
+	Mean       float64 `json:"mean"`
+	Change     float64 `json:"change"`     // Fractional change from first to last (0.01 = +1%)
+	Volatility float64 `json:"volatility"` // Standard deviation of log returns between snapshots
+}
+
+// Summarize computes Stats for points ordered oldest first
+func Summarize(points []Point) Stats {
+	if len(points) == 0 {
+		return Stats{}
+	}
+
+	stats := Stats{
+		Points: len(points),
+		First:  points[0].Rate,
+		Last:   points[len(points)-1].Rate,
+		Min:    points[0].Rate,
+		Max:    points[0].Rate,
+	}
+	var sum float64
+	for _, p := range points {
+		sum += p.Rate
+		stats.Min = math.Min(stats.Min, p.Rate)
+		stats.Max = math.Max(stats.Max, p.Rate)
+	}
+	stats.Mean = sum / float64(len(points))
+	if stats.First > 0 {
+		stats.Change = stats.Last/stats.First - 1
+	}
+	stats.Volatility = Volatility(points)
+	return stats
+}
+
+// Volatility returns the standard deviation of log returns between
+// consecutive snapshots, or 0 with fewer than two usable returns
+func Volatility(points []Point) float64 {
+	var returns []float64
+	for i := 1; i < len(points); i++ {
+		if points[i-1].Rate > 0 && points[i].Rate > 0 {
+			returns = append(returns, math.Log(points[i].Rate/points[i-1].Rate))
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	return math.Sqrt(variance / float64(len(returns)-1))
+}
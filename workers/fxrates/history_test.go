@@ -0,0 +1,52 @@
+package fxrates
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryHistoryRangeAndRetention(t *testing.T) {
+	history := NewMemoryHistory(48 * time.Hour)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for day, rate := range []float64{0.90, 0.91, 0.93, 0.92} {
+		history.Append(context.Background(), &Update{
+			Rates:     map[string]float64{"EUR": rate},
+			FetchedAt: start.Add(time.Duration(day) * 24 * time.Hour),
+		})
+	}
+
+	all, _ := history.History(context.Background(), "EUR", time.Time{})
+	if len(all) != 3 || all[0].Rate != 0.91 {
+		t.Fatalf("history = %v, want the 3 snapshots within retention", all)
+	}
+	recent, _ := history.History(context.Background(), "EUR", start.Add(60*time.Hour))
+	if len(recent) != 1 || recent[0].Rate != 0.92 {
+		t.Errorf("history since day 2.5 = %v, want the last snapshot", recent)
+	}
+	if none, _ := history.History(context.Background(), "JPY", time.Time{}); len(none) != 0 {
+		t.Errorf("JPY history = %v, want none", none)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	points := []Point{{Rate: 100}, {Rate: 110}, {Rate: 99}, {Rate: 105}}
+	stats := Summarize(points)
+	if stats.Points != 4 || stats.Min != 99 || stats.Max != 110 || stats.Mean != 103.5 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if diff := stats.Change - 0.05; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("change = %v, want 0.05", stats.Change)
+	}
+	if stats.Volatility <= 0 {
+		t.Errorf("volatility = %v, want positive for a moving series", stats.Volatility)
+	}
+
+	flat := Summarize([]Point{{Rate: 1}, {Rate: 1}, {Rate: 1}})
+	if flat.Volatility != 0 || flat.Change != 0 {
+		t.Errorf("flat series = %+v, want no change or volatility", flat)
+	}
+	if empty := Summarize(nil); empty.Points != 0 {
+		t.Errorf("empty series = %+v", empty)
+	}
+}
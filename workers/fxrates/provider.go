@@ -0,0 +1,246 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	neo4jstore "github.com/plm/predictive-liquidity-mesh/storage/neo4j"
+)
+
+// Provider names accepted in Config.Providers
+const (
+	ProviderExchangeRateAPI = "exchangerate-api" // ExchangeRate-API, needs an API key
+	ProviderECB             = "ecb"              // European Central Bank daily reference rates
+	ProviderStatic          = "static"           // Rates file, or the bundled demo rates
+)
+
+// ErrRateLimited is returned by providers that have exhausted their quota.
+// The worker skips a rate-limited provider until its backoff expires.
+var ErrRateLimited = errors.New("fx provider rate limited")
+
+// Provider fetches exchange rates quoted against BaseCurrency
+type Provider interface {
+	// Name identifies the provider in logs and metrics
+	Name() string
+	// FetchRates returns the amount of each currency per 1 USD
+	FetchRates(ctx context.Context) (map[string]float64, error)
+}
+
+// KnownProvider reports whether name is a provider NewProvider can build
+func KnownProvider(name string) bool {
+	switch name {
+	case ProviderExchangeRateAPI, ProviderECB, ProviderStatic:
+		return true
+	}
+	return false
+}
+
+// NewProvider builds the named provider from cfg. It returns nil for
+// ExchangeRate-API when no API key is configured.
+func NewProvider(name string, cfg *Config, client *http.Client) (Provider, error) {
+	switch name {
+	case ProviderExchangeRateAPI:
+		if cfg.APIKey == "" || cfg.APIKey == "YOUR_KEY_HERE" {
+			return nil, nil
+		}
+		return NewExchangeRateAPIProvider(cfg.APIKey, client), nil
+	case ProviderECB:
+		return NewECBProvider(client), nil
+	case ProviderStatic:
+		return NewStaticProvider(cfg.RatesFile), nil
+	}
+	return nil, fmt.Errorf("unknown fx provider %q", name)
+}
+
+// ExchangeRateAPIProvider fetches rates from ExchangeRate-API.
+// Free tier: 1,500 requests/month.
+type ExchangeRateAPIProvider struct {
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewExchangeRateAPIProvider creates an ExchangeRate-API provider
+func NewExchangeRateAPIProvider(apiKey string, client *http.Client) *ExchangeRateAPIProvider {
+	return &ExchangeRateAPIProvider{apiKey: apiKey, httpClient: client}
+}
+
+// Name implements Provider
+func (p *ExchangeRateAPIProvider) Name() string { return ProviderExchangeRateAPI }
+
+// FetchRates implements Provider
+func (p *ExchangeRateAPIProvider) FetchRates(ctx context.Context) (map[string]float64, error) {
+	url := fmt.Sprintf("https://v6.exchangerate-api.com/v6/%s/latest/USD", p.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, ErrRateLimited
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	}
+
+	var apiResp ExchangeRateAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if apiResp.Result != "success" {
+		if apiResp.ErrorType == "quota-reached" {
+			return nil, ErrRateLimited
+		}
+		return nil, fmt.Errorf("API error: %s %s", apiResp.Result, apiResp.ErrorType)
+	}
+
+	return apiResp.ConversionRates, nil
+}
+
+// ecbDailyURL serves the ECB's euro reference rates, updated around 16:00 CET
+// on TARGET working days
+const ecbDailyURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+
+// ecbEnvelope is the subset of the ECB daily reference rate XML we read
+type ecbEnvelope struct {
+	Cube struct {
+		Cube struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string  `xml:"currency,attr"`
+				Rate     float64 `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// ECBProvider fetches the European Central Bank's daily reference rates.
+// No API key is needed; rates are quoted in EUR and rebased to USD.
+type ECBProvider struct {
+	url        string
+	httpClient *http.Client
+}
+
+// NewECBProvider creates an ECB provider
+func NewECBProvider(client *http.Client) *ECBProvider {
+	return &ECBProvider{url: ecbDailyURL, httpClient: client}
+}
+
+// Name implements Provider
+func (p *ECBProvider) Name() string { return ProviderECB }
+
+// FetchRates implements Provider
+func (p *ECBProvider) FetchRates(ctx context.Context) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, ErrRateLimited
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ECB returned status %d", resp.StatusCode)
+	}
+
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	perEUR := map[string]float64{"EUR": 1}
+	for _, r := range envelope.Cube.Cube.Rates {
+		if r.Rate > 0 {
+			perEUR[r.Currency] = r.Rate
+		}
+	}
+	return rebase(perEUR, BaseCurrency)
+}
+
+// rebase converts rates quoted per 1 unit of one currency to rates per 1 unit of base
+func rebase(rates map[string]float64, base string) (map[string]float64, error) {
+	baseRate, ok := rates[base]
+	if !ok || baseRate <= 0 {
+		return nil, fmt.Errorf("no %s rate to rebase on", base)
+	}
+	rebased := make(map[string]float64, len(rates))
+	for currency, rate := range rates {
+		rebased[currency] = rate / baseRate
+	}
+	rebased[base] = 1
+	return rebased, nil
+}
+
+// StaticProvider serves rates from a JSON file, or the bundled demo rates
+// when no file is set. The file is re-read on every fetch so edits apply
+// without a restart. It is meant as the last provider in a failover chain.
+type StaticProvider struct {
+	path string
+}
+
+// NewStaticProvider creates a static provider reading path (empty for the bundled rates)
+func NewStaticProvider(path string) *StaticProvider {
+	return &StaticProvider{path: path}
+}
+
+// Name implements Provider
+func (p *StaticProvider) Name() string { return ProviderStatic }
+
+// FetchRates implements Provider. The file holds either a currency-to-rate
+// object or {"base": "EUR", "rates": {...}}; a non-USD base is rebased.
+func (p *StaticProvider) FetchRates(ctx context.Context) (map[string]float64, error) {
+	if p.path == "" {
+		rates := make(map[string]float64)
+		for _, c := range neo4jstore.Top50GDPCountries {
+			if c.FXRate > 0 {
+				rates[c.Currency] = c.FXRate
+			}
+		}
+		return rates, nil
+	}
+
+	data, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var file struct {
+		Base  string             `json:"base"`
+		Rates map[string]float64 `json:"rates"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil || file.Rates == nil {
+		// Plain currency-to-rate object
+		file.Base = ""
+		if err := json.Unmarshal(data, &file.Rates); err != nil {
+			return nil, fmt.Errorf("failed to decode rates file: %w", err)
+		}
+	}
+	if len(file.Rates) == 0 {
+		return nil, fmt.Errorf("rates file %s has no rates", p.path)
+	}
+
+	if base := strings.ToUpper(file.Base); base != "" && base != BaseCurrency {
+		file.Rates[base] = 1
+		return rebase(file.Rates, BaseCurrency)
+	}
+	return file.Rates, nil
+}
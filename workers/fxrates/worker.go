@@ -1,18 +1,21 @@
-// Package fxrates provides a background worker to fetch live exchange rates from ExchangeRate-API.
-// Free tier: 1,500 requests/month - perfect for development.
-// API endpoint: GET https://v6.exchangerate-api.com/v6/YOUR-API-KEY/latest/USD
+// Package fxrates provides a background worker to fetch live exchange rates.
+// Rates come from an ordered list of providers (ExchangeRate-API, ECB daily
+// reference rates, a static rates file); when one fails or is rate limited
+// the next is tried.
 package fxrates
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/plm/predictive-liquidity-mesh/pkg/metrics"
 )
 
 // ExchangeRateAPIResponse represents the API response structure
@@ -24,25 +27,52 @@ type ExchangeRateAPIResponse struct {
 	TimeNextUpdateUnix int64              `json:"time_next_update_unix"`
 	BaseCode           string             `json:"base_code"`
 	ConversionRates    map[string]float64 `json:"conversion_rates"`
+	ErrorType          string             `json:"error-type"` // Set when Result is "error", e.g. quota-reached
 }
 
-// Worker fetches FX rates and updates Neo4j country nodes
+// BaseCurrency is the currency all rates are quoted against
+const BaseCurrency = "USD"
+
+// Rate is one currency's exchange rate to BaseCurrency
+type Rate struct {
+	Rate      float64   `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Update is a set of freshly fetched rates, keyed by currency
+type Update struct {
+	Base      string             `json:"base"`
+	Rates     map[string]float64 `json:"rates"`
+	Provider  string             `json:"provider"`
+	FetchedAt time.Time          `json:"fetched_at"`
+}
+
+// Worker fetches FX rates, keeps the latest rate per currency, notifies
+// subscribers and updates Neo4j country nodes
 type Worker struct {
-	apiKey     string
-	httpClient *http.Client
-	driver     neo4j.DriverWithContext
-	database   string
-	interval   time.Duration
-	currencies []string
+	providers        []Provider
+	rateLimitBackoff time.Duration
+	driver           neo4j.DriverWithContext
+	database         string
+	interval         time.Duration
+	currencies       []string
+
+	mu           sync.RWMutex
+	rates        map[string]Rate
+	onUpdate     []func(*Update)
+	limitedUntil map[string]time.Time // Rate-limited providers, skipped until the time passes
 }
 
 // Config configures the FX rate worker
 type Config struct {
-	APIKey     string
-	Driver     neo4j.DriverWithContext
-	Database   string
-	Interval   time.Duration
-	Currencies []string
+	APIKey           string
+	Providers        []string      // Provider names in failover order
+	RatesFile        string        // JSON rates file for the static provider
+	RateLimitBackoff time.Duration // How long a rate-limited provider is skipped
+	Driver           neo4j.DriverWithContext
+	Database         string
+	Interval         time.Duration
+	Currencies       []string
 }
 
 // DefaultConfig returns default configuration
@@ -53,22 +83,100 @@ func DefaultConfig() *Config {
 	}
 
 	return &Config{
-		APIKey:   apiKey,
-		Interval: 1 * time.Hour,
+		APIKey:           apiKey,
+		Providers:        []string{ProviderExchangeRateAPI, ProviderECB},
+		RateLimitBackoff: 6 * time.Hour,
+		Interval:         1 * time.Hour,
 	}
 }
 
-// NewWorker creates a new FX rate worker
+// NewWorker creates a new FX rate worker. Unknown providers and
+// ExchangeRate-API without an API key are left out of the failover chain.
 func NewWorker(cfg *Config) *Worker {
+	client := &http.Client{
+		Timeout: 30 * time.Second,
+	}
+	var providers []Provider
+	for _, name := range cfg.Providers {
+		provider, err := NewProvider(name, cfg, client)
+		if err != nil {
+			log.Printf("⚠️  %v", err)
+			continue
+		}
+		if provider != nil {
+			providers = append(providers, provider)
+		}
+	}
+	return NewWorkerWithProviders(cfg, providers...)
+}
+
+// NewWorkerWithProviders creates a worker fetching from providers in order,
+// ignoring cfg.Providers
+func NewWorkerWithProviders(cfg *Config, providers ...Provider) *Worker {
 	return &Worker{
-		apiKey: cfg.APIKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
-		driver:     cfg.Driver,
-		database:   cfg.Database,
-		interval:   cfg.Interval,
-		currencies: cfg.Currencies,
+		providers:        providers,
+		rateLimitBackoff: cfg.RateLimitBackoff,
+		driver:           cfg.Driver,
+		database:         cfg.Database,
+		interval:         cfg.Interval,
+		currencies:       cfg.Currencies,
+		rates:            make(map[string]Rate),
+		limitedUntil:     make(map[string]time.Time),
+	}
+}
+
+// OnUpdate registers a callback run after each successful fetch
+func (w *Worker) OnUpdate(fn func(*Update)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onUpdate = append(w.onUpdate, fn)
+}
+
+// Seed records rates known before the first fetch (e.g. loaded from Neo4j),
+// without overwriting currencies that have been fetched since
+func (w *Worker) Seed(rates map[string]float64, at time.Time) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for currency, rate := range rates {
+		if _, ok := w.rates[currency]; !ok {
+			w.rates[currency] = Rate{Rate: rate, UpdatedAt: at}
+		}
+	}
+}
+
+// Rates returns a copy of the latest rate for each currency
+func (w *Worker) Rates() map[string]Rate {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	rates := make(map[string]Rate, len(w.rates))
+	for currency, rate := range w.rates {
+		rates[currency] = rate
+	}
+	return rates
+}
+
+// record stores fetched rates (limited to the configured currencies, if any)
+// and notifies subscribers
+func (w *Worker) record(rates map[string]float64, provider string, fetchedAt time.Time) {
+	update := &Update{Base: BaseCurrency, Rates: rates, Provider: provider, FetchedAt: fetchedAt}
+	if len(w.currencies) > 0 {
+		update.Rates = make(map[string]float64, len(w.currencies))
+		for _, currency := range w.currencies {
+			if rate, ok := rates[currency]; ok {
+				update.Rates[currency] = rate
+			}
+		}
+	}
+
+	w.mu.Lock()
+	for currency, rate := range update.Rates {
+		w.rates[currency] = Rate{Rate: rate, UpdatedAt: fetchedAt}
+	}
+	callbacks := append([]func(*Update){}, w.onUpdate...)
+	w.mu.Unlock()
+
+	for _, fn := range callbacks {
+		fn(update)
 	}
 }
 
@@ -76,9 +184,9 @@ func NewWorker(cfg *Config) *Worker {
 func (w *Worker) Start(ctx context.Context) {
 	log.Println("💱 Starting FX Rate Worker...")
 
-	if w.apiKey == "" || w.apiKey == "YOUR_KEY_HERE" {
-		log.Println("⚠️  EXCHANGE_RATE_API_KEY not set - FX worker running in dry-run mode")
-		log.Println("   Get your free API key at: https://app.exchangerate-api.com/dashboard")
+	if len(w.providers) == 0 {
+		log.Println("⚠️  No FX providers available - FX worker running in dry-run mode")
+		log.Println("   Set EXCHANGE_RATE_API_KEY (https://app.exchangerate-api.com/dashboard) or enable the ecb provider")
 		return
 	}
 
@@ -100,61 +208,91 @@ func (w *Worker) Start(ctx context.Context) {
 	}
 }
 
-// fetchAndUpdate fetches rates from API and updates Neo4j
+// fetchAndUpdate fetches rates from the first working provider and updates Neo4j
 func (w *Worker) fetchAndUpdate(ctx context.Context) {
-	log.Println("💱 Fetching FX rates from ExchangeRate-API...")
-
-	rates, err := w.fetchRates(ctx)
+	rates, provider, err := w.fetchRates(ctx)
 	if err != nil {
 		log.Printf("❌ Failed to fetch FX rates: %v", err)
 		return
 	}
 
-	log.Printf("✅ Fetched %d exchange rates (base: USD)", len(rates))
+	log.Printf("✅ Fetched %d exchange rates from %s (base: USD)", len(rates), provider)
+	w.record(rates, provider, time.Now().UTC())
 
 	// Update Neo4j if driver is configured
-	if w.driver != nil {
-		if err := w.updateNeo4j(ctx, rates); err != nil {
+	w.mu.RLock()
+	driver, database := w.driver, w.database
+	w.mu.RUnlock()
+	if driver != nil {
+		if err := updateNeo4j(ctx, driver, database, rates); err != nil {
 			log.Printf("❌ Failed to update Neo4j with FX rates: %v", err)
 		}
 	}
 }
 
-// fetchRates calls the ExchangeRate-API
-func (w *Worker) fetchRates(ctx context.Context) (map[string]float64, error) {
-	url := fmt.Sprintf("https://v6.exchangerate-api.com/v6/%s/latest/USD", w.apiKey)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+// fetchRates tries each provider in order, skipping rate-limited ones, and
+// returns the first successful result with the provider's name
+func (w *Worker) fetchRates(ctx context.Context) (map[string]float64, string, error) {
+	var errs []error
+	for _, provider := range w.providers {
+		name := provider.Name()
+		if w.isRateLimited(name) {
+			continue
+		}
 
-	resp, err := w.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch rates: %w", err)
+		log.Printf("💱 Fetching FX rates from %s...", name)
+		rates, err := provider.FetchRates(ctx)
+		switch {
+		case errors.Is(err, ErrRateLimited):
+			metrics.FXFetches.Inc(name, "rate_limited")
+			w.setRateLimited(name)
+			log.Printf("⚠️  FX provider %s rate limited, skipping it for %v", name, w.rateLimitBackoff)
+		case err != nil:
+			metrics.FXFetches.Inc(name, "error")
+			log.Printf("⚠️  FX provider %s failed: %v", name, err)
+		case len(rates) == 0:
+			metrics.FXFetches.Inc(name, "error")
+			err = errors.New("no rates returned")
+			log.Printf("⚠️  FX provider %s returned no rates", name)
+		default:
+			metrics.FXFetches.Inc(name, "success")
+			return rates, name, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API returned status %d", resp.StatusCode)
+	if len(errs) == 0 {
+		return nil, "", errors.New("all FX providers are rate limited")
 	}
+	return nil, "", errors.Join(errs...)
+}
 
-	var apiResp ExchangeRateAPIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
-	}
+func (w *Worker) isRateLimited(provider string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return time.Now().Before(w.limitedUntil[provider])
+}
 
-	if apiResp.Result != "success" {
-		return nil, fmt.Errorf("API error: %s", apiResp.Result)
-	}
+// SetDriver sets the Neo4j driver country FX rates are written to, for a
+// connection made after the worker was created
+func (w *Worker) SetDriver(driver neo4j.DriverWithContext, database string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.driver = driver
+	w.database = database
+}
 
-	return apiResp.ConversionRates, nil
+func (w *Worker) setRateLimited(provider string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.limitedUntil[provider] = time.Now().Add(w.rateLimitBackoff)
 }
 
 // updateNeo4j updates country nodes with current FX rates
-func (w *Worker) updateNeo4j(ctx context.Context, rates map[string]float64) error {
-	session := w.driver.NewSession(ctx, neo4j.SessionConfig{
-		DatabaseName: w.database,
+func updateNeo4j(ctx context.Context, driver neo4j.DriverWithContext, database string, rates map[string]float64) error {
+	defer metrics.Neo4jQueryDuration.ObserveSince(time.Now(), "update_fx_rates")
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: database,
 		AccessMode:   neo4j.AccessModeWrite,
 	})
 	defer session.Close(ctx)
@@ -194,5 +332,6 @@ func (w *Worker) updateNeo4j(ctx context.Context, rates map[string]float64) erro
 
 // FetchOnce performs a single fetch (for testing/manual trigger)
 func (w *Worker) FetchOnce(ctx context.Context) (map[string]float64, error) {
-	return w.fetchRates(ctx)
+	rates, _, err := w.fetchRates(ctx)
+	return rates, err
 }
@@ -0,0 +1,136 @@
+package fxrates
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeProvider returns canned rates or an error and counts its calls
+type fakeProvider struct {
+	name  string
+	rates map[string]float64
+	err   error
+	calls int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) FetchRates(ctx context.Context) (map[string]float64, error) {
+	p.calls++
+	return p.rates, p.err
+}
+
+func TestFetchFailsOverToNextProvider(t *testing.T) {
+	primary := &fakeProvider{name: "primary", err: ErrRateLimited}
+	secondary := &fakeProvider{name: "secondary", err: errors.New("connection refused")}
+	fallback := &fakeProvider{name: "fallback", rates: map[string]float64{"EUR": 0.9}}
+	worker := NewWorkerWithProviders(&Config{RateLimitBackoff: time.Hour}, primary, secondary, fallback)
+
+	rates, provider, err := worker.fetchRates(context.Background())
+	if err != nil || provider != "fallback" || rates["EUR"] != 0.9 {
+		t.Fatalf("fetchRates = %v, %q, %v; want fallback rates", rates, provider, err)
+	}
+
+	// The rate-limited primary is skipped during its backoff; the failed
+	// secondary is retried
+	worker.fetchRates(context.Background())
+	if primary.calls != 1 || secondary.calls != 2 {
+		t.Errorf("calls: primary %d, secondary %d; want 1 and 2", primary.calls, secondary.calls)
+	}
+
+	fallback.err = errors.New("file missing")
+	if _, _, err := worker.fetchRates(context.Background()); err == nil {
+		t.Error("expected an error when every provider fails")
+	}
+}
+
+func TestECBProviderRebasesToUSD(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2026-10-15">
+			<Cube currency="USD" rate="1.25"/>
+			<Cube currency="JPY" rate="187.5"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`))
+	}))
+	defer server.Close()
+
+	provider := NewECBProvider(server.Client())
+	provider.url = server.URL
+	rates, err := provider.FetchRates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]float64{"USD": 1, "EUR": 0.8, "JPY": 150}
+	for currency, rate := range want {
+		if rates[currency] != rate {
+			t.Errorf("%s = %v, want %v", currency, rates[currency], rate)
+		}
+	}
+}
+
+func TestStaticProviderReadsRatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	os.WriteFile(path, []byte(`{"base": "EUR", "rates": {"USD": 1.25, "GBP": 0.5}}`), 0o600)
+
+	rates, err := NewStaticProvider(path).FetchRates(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rates["USD"] != 1 || rates["EUR"] != 0.8 || rates["GBP"] != 0.4 {
+		t.Errorf("rates = %v, want rebased to USD", rates)
+	}
+
+	os.WriteFile(path, []byte(`{"EUR": 0.9}`), 0o600)
+	if rates, err := NewStaticProvider(path).FetchRates(context.Background()); err != nil || rates["EUR"] != 0.9 {
+		t.Errorf("plain rates file = %v, %v; want EUR 0.9", rates, err)
+	}
+
+	if rates, err := NewStaticProvider("").FetchRates(context.Background()); err != nil || rates["USD"] != 1 {
+		t.Errorf("bundled rates = %v, %v; want USD 1", rates, err)
+	}
+}
+
+func TestRecordKeepsLatestRatesAndNotifies(t *testing.T) {
+	worker := NewWorker(&Config{Currencies: []string{"EUR", "JPY"}})
+	seededAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	worker.Seed(map[string]float64{"EUR": 0.9, "GBP": 0.8}, seededAt)
+
+	var updates []*Update
+	worker.OnUpdate(func(u *Update) { updates = append(updates, u) })
+
+	fetchedAt := seededAt.Add(time.Hour)
+	worker.record(map[string]float64{"EUR": 0.95, "JPY": 150, "CHF": 0.88}, ProviderECB, fetchedAt)
+
+	if len(updates) != 1 {
+		t.Fatalf("updates = %d, want 1", len(updates))
+	}
+	if u := updates[0]; u.Base != BaseCurrency || u.Provider != ProviderECB || len(u.Rates) != 2 || u.Rates["EUR"] != 0.95 {
+		t.Errorf("update = %+v, want EUR and JPY only", u)
+	}
+
+	rates := worker.Rates()
+	if got := rates["EUR"]; got.Rate != 0.95 || !got.UpdatedAt.Equal(fetchedAt) {
+		t.Errorf("EUR = %+v, want fetched rate", got)
+	}
+	if got := rates["GBP"]; got.Rate != 0.8 || !got.UpdatedAt.Equal(seededAt) {
+		t.Errorf("GBP = %+v, want seeded rate", got)
+	}
+	if _, ok := rates["CHF"]; ok {
+		t.Error("CHF recorded despite not being a configured currency")
+	}
+
+	worker.Seed(map[string]float64{"EUR": 0.5}, fetchedAt)
+	if got := worker.Rates()["EUR"]; got.Rate != 0.95 {
+		t.Errorf("Seed overwrote fetched EUR rate: %+v", got)
+	}
+}
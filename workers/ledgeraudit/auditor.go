@@ -0,0 +1,152 @@
+// Package ledgeraudit periodically verifies the hash chain of the settlement ledger
+// and keeps the outcome of the most recent audit.
+package ledgeraudit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/storage/postgres"
+)
+
+// Verifier checks every link of the ledger's hash chain; implemented by postgres.Client
+type Verifier interface {
+	VerifyIntegrity(ctx context.Context) ([]postgres.IntegrityResult, error)
+}
+
+// Segment is a run of consecutive ledger entries whose previous_hash does not match the chain
+type Segment struct {
+	FromSequence int64                      `json:"from_sequence"`
+	ToSequence   int64                      `json:"to_sequence"`
+	Entries      []postgres.IntegrityResult `json:"entries"`
+}
+
+// Result is the outcome of one audit
+type Result struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Duration  string    `json:"duration"`
+	Valid     bool      `json:"valid"`
+	Checked   int       `json:"checked"`         // Ledger entries verified
+	Broken    []Segment `json:"broken"`          // Broken chain segments, in sequence order
+	Error     string    `json:"error,omitempty"` // Set when verification could not run
+}
+
+// Config configures the auditor
+type Config struct {
+	Interval time.Duration // Time between scheduled audits (0 = manual audits only)
+	Timeout  time.Duration // Limit on a single verification
+}
+
+// DefaultConfig returns default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Interval: time.Hour,
+		Timeout:  time.Minute,
+	}
+}
+
+// Auditor verifies the ledger on a schedule or on demand
+type Auditor struct {
+	verifier Verifier
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.RWMutex
+	last    *Result
+	onAlert func(result *Result)
+}
+
+// NewAuditor creates a new ledger auditor
+func NewAuditor(verifier Verifier, cfg *Config) *Auditor {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Auditor{
+		verifier: verifier,
+		interval: cfg.Interval,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// SetAlertCallback sets the callback for audits that find a broken chain or fail to run
+func (a *Auditor) SetAlertCallback(cb func(result *Result)) {
+	a.onAlert = cb
+}
+
+// Start runs an audit immediately and then every interval until ctx is done
+func (a *Auditor) Start(ctx context.Context) {
+	if a.interval <= 0 {
+		return
+	}
+	slog.InfoContext(ctx, "ledger auditor started", "interval", a.interval)
+
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+	for {
+		a.Run(ctx, true)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run verifies the ledger, records the result as the last audit and, when
+// alert is set, reports a broken chain or failed verification to the alert callback
+func (a *Auditor) Run(ctx context.Context, alert bool) *Result {
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	start := time.Now()
+	results, err := a.verifier.VerifyIntegrity(ctx)
+	result := Evaluate(results)
+	result.CheckedAt = start.UTC()
+	result.Duration = time.Since(start).String()
+	if err != nil {
+		result.Valid = false
+		result.Error = err.Error()
+		slog.ErrorContext(ctx, "ledger audit failed", "error", err)
+	} else if !result.Valid {
+		slog.WarnContext(ctx, "ledger integrity check failed", "checked", result.Checked, "broken_segments", len(result.Broken))
+	}
+
+	a.mu.Lock()
+	a.last = result
+	a.mu.Unlock()
+
+	if alert && !result.Valid && a.onAlert != nil {
+		a.onAlert(result)
+	}
+	return result
+}
+
+// LastResult returns the most recent audit, or nil if none has run
+func (a *Auditor) LastResult() *Result {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.last
+}
+
+// Evaluate groups the broken links of a verification into segments of
+// adjacent entries
+func Evaluate(results []postgres.IntegrityResult) *Result {
+	result := &Result{Checked: len(results), Broken: []Segment{}}
+	var current *Segment
+	for _, r := range results {
+		if r.IsValid {
+			current = nil
+			continue
+		}
+		if current == nil {
+			result.Broken = append(result.Broken, Segment{FromSequence: r.SequenceNum})
+			current = &result.Broken[len(result.Broken)-1]
+		}
+		current.ToSequence = r.SequenceNum
+		current.Entries = append(current.Entries, r)
+	}
+	result.Valid = len(result.Broken) == 0
+	return result
+}
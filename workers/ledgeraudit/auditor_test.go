@@ -0,0 +1,80 @@
+package ledgeraudit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/plm/predictive-liquidity-mesh/storage/postgres"
+)
+
+// fakeVerifier returns canned verification results
+type fakeVerifier struct {
+	results []postgres.IntegrityResult
+	err     error
+}
+
+func (v *fakeVerifier) VerifyIntegrity(ctx context.Context) ([]postgres.IntegrityResult, error) {
+	return v.results, v.err
+}
+
+func chain(valid ...bool) []postgres.IntegrityResult {
+	results := make([]postgres.IntegrityResult, len(valid))
+	for i, ok := range valid {
+		results[i] = postgres.IntegrityResult{SequenceNum: int64(i + 1), IsValid: ok}
+	}
+	return results
+}
+
+func TestEvaluateGroupsBrokenSegments(t *testing.T) {
+	result := Evaluate(chain(true, false, false, true, false))
+	if result.Valid || result.Checked != 5 {
+		t.Fatalf("result = %+v, want 5 checked and invalid", result)
+	}
+	if len(result.Broken) != 2 {
+		t.Fatalf("broken segments = %d, want 2", len(result.Broken))
+	}
+	if s := result.Broken[0]; s.FromSequence != 2 || s.ToSequence != 3 || len(s.Entries) != 2 {
+		t.Errorf("first segment = %+v, want sequences 2-3", s)
+	}
+	if s := result.Broken[1]; s.FromSequence != 5 || s.ToSequence != 5 {
+		t.Errorf("second segment = %+v, want sequence 5", s)
+	}
+
+	if result := Evaluate(chain(true, true)); !result.Valid || len(result.Broken) != 0 {
+		t.Errorf("intact chain = %+v, want valid", result)
+	}
+}
+
+func TestRunRecordsAndAlerts(t *testing.T) {
+	verifier := &fakeVerifier{results: chain(true, true)}
+	auditor := NewAuditor(verifier, nil)
+	var alerts int
+	auditor.SetAlertCallback(func(result *Result) { alerts++ })
+
+	if auditor.LastResult() != nil {
+		t.Fatal("expected no result before the first audit")
+	}
+	if result := auditor.Run(context.Background(), true); !result.Valid {
+		t.Fatalf("intact chain reported invalid: %+v", result)
+	}
+
+	verifier.results = chain(true, false)
+	auditor.Run(context.Background(), false)
+	if alerts != 0 {
+		t.Errorf("alerts = %d, want none when alerting is off", alerts)
+	}
+	auditor.Run(context.Background(), true)
+	if alerts != 1 {
+		t.Errorf("alerts = %d, want 1 for a broken chain", alerts)
+	}
+
+	verifier.err = errors.New("connection refused")
+	last := auditor.Run(context.Background(), true)
+	if last.Valid || last.Error == "" || alerts != 2 {
+		t.Errorf("failed audit = %+v with %d alerts, want an alerted error", last, alerts)
+	}
+	if auditor.LastResult() != last {
+		t.Error("last result not recorded")
+	}
+}
@@ -0,0 +1,234 @@
+// Package recovery reconciles payments stuck in pending or processing: it
+// cross-checks their Stripe PaymentIntent and resumes mesh processing, fails
+// (and refunds) them, or escalates them to the admin review queue.
+package recovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+// Config configures the reconciler
+type Config struct {
+	Interval    time.Duration // Time between scans (0 = disabled)
+	StuckAfter  time.Duration // How long a transaction may go without progress before it is stuck
+	MaxAttempts int           // Processing attempts after which a paid transaction is no longer resumed
+}
+
+// DefaultConfig returns default configuration
+func DefaultConfig() *Config {
+	return &Config{
+		Interval:    time.Minute,
+		StuckAfter:  15 * time.Minute,
+		MaxAttempts: 3,
+	}
+}
+
+// Store lists stuck transactions; implemented by payments.TransactionStorer
+type Store interface {
+	StuckTransactions(before time.Time) []*payments.Transaction
+}
+
+// PaymentIntents looks up the state of a Stripe PaymentIntent; implemented
+// by payments.StripeClient
+type PaymentIntents interface {
+	GetPaymentIntent(paymentIntentID string) (*payments.PaymentIntentResponse, error)
+}
+
+// Settler carries out recovery actions under the transaction's processing
+// lock, returning payments.ErrTransactionLocked if it is being processed;
+// implemented by handlers.PaymentHandler
+type Settler interface {
+	ResumeStuck(ctx context.Context, txnID string) error
+	FailStuck(ctx context.Context, txnID, reason string, refund bool) error
+	EscalateStuck(ctx context.Context, txnID string, reasons []string) error
+}
+
+// Action is what the reconciler does with a stuck transaction
+type Action string
+
+const (
+	ActionResume   Action = "resume"   // Settle through the mesh again
+	ActionFail     Action = "fail"     // Fail it; nothing was charged
+	ActionRefund   Action = "refund"   // Fail it and refund the card
+	ActionEscalate Action = "escalate" // Hold it for an admin to decide
+)
+
+// Decision is the action chosen for a stuck transaction and why
+type Decision struct {
+	Action Action `json:"action"`
+	Reason string `json:"reason"`
+}
+
+// Decide chooses how to recover a stuck transaction. intent is the state of
+// its Stripe PaymentIntent, nil if it has none or it wasn't looked up; then
+// a payer who started settlement is assumed to have paid.
+func Decide(txn *payments.Transaction, intent *payments.PaymentIntentResponse, maxAttempts int) Decision {
+	attempts := len(txn.Attempts)
+	if txn.Status == payments.StatusProcessing {
+		attempts++ // The stalled run
+	}
+
+	paid := txn.SettlementStarted()
+	if intent != nil {
+		switch intent.Status {
+		case "succeeded":
+			paid = true
+		case "canceled", "requires_payment_method":
+			if paid {
+				return Decision{ActionEscalate, fmt.Sprintf("settlement started but the Stripe payment is %s", intent.Status)}
+			}
+			return Decision{ActionFail, "card payment not completed (" + intent.Status + ")"}
+		default: // processing, requires_action, requires_confirmation, requires_capture
+			return Decision{ActionEscalate, "Stripe payment is " + intent.Status}
+		}
+	}
+
+	switch {
+	case !paid:
+		return Decision{ActionFail, "payment not confirmed"}
+	case attempts < maxAttempts:
+		return Decision{ActionResume, fmt.Sprintf("resuming after %d attempts", attempts)}
+	case txn.PaymentIntentID != "":
+		return Decision{ActionRefund, fmt.Sprintf("not settled after %d attempts", attempts)}
+	default:
+		return Decision{ActionEscalate, fmt.Sprintf("not settled after %d attempts", attempts)}
+	}
+}
+
+// Outcome is the recovery of one stuck transaction
+type Outcome struct {
+	TransactionID string `json:"transaction_id"`
+	Decision
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the outcome of one scan
+type Result struct {
+	CheckedAt time.Time `json:"checked_at"`
+	Stuck     int       `json:"stuck"`    // Transactions found stuck
+	Skipped   int       `json:"skipped"`  // Found being processed, or their Stripe state unavailable
+	Outcomes  []Outcome `json:"outcomes"` // Actions taken, oldest transaction first
+}
+
+// Reconciler periodically recovers stuck transactions
+type Reconciler struct {
+	store       Store
+	settler     Settler
+	intents     PaymentIntents
+	interval    time.Duration
+	stuckAfter  time.Duration
+	maxAttempts int
+
+	mu   sync.RWMutex
+	last *Result
+}
+
+// NewReconciler creates a new stuck payment reconciler
+func NewReconciler(store Store, settler Settler, cfg *Config) *Reconciler {
+	if cfg == nil {
+		cfg = DefaultConfig()
+	}
+	return &Reconciler{
+		store:       store,
+		settler:     settler,
+		interval:    cfg.Interval,
+		stuckAfter:  cfg.StuckAfter,
+		maxAttempts: cfg.MaxAttempts,
+	}
+}
+
+// SetPaymentIntents enables cross-checking Stripe. Without it, payers who
+// started settlement are assumed to have paid.
+func (r *Reconciler) SetPaymentIntents(intents PaymentIntents) {
+	r.intents = intents
+}
+
+// Start scans every interval until ctx is done
+func (r *Reconciler) Start(ctx context.Context) {
+	if r.interval <= 0 {
+		return
+	}
+	slog.InfoContext(ctx, "stuck payment reconciler started", "interval", r.interval, "stuck_after", r.stuckAfter)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reconcile(ctx)
+		}
+	}
+}
+
+// Reconcile recovers the transactions stuck now and records the result as
+// the last scan
+func (r *Reconciler) Reconcile(ctx context.Context) *Result {
+	now := time.Now()
+	stuck := r.store.StuckTransactions(now.Add(-r.stuckAfter))
+	result := &Result{CheckedAt: now.UTC(), Stuck: len(stuck), Outcomes: []Outcome{}}
+
+	for _, txn := range stuck {
+		if ctx.Err() != nil {
+			break
+		}
+		var intent *payments.PaymentIntentResponse
+		if r.intents != nil && txn.PaymentIntentID != "" && txn.CollectedBy() == payments.ProviderStripe {
+			var err error
+			if intent, err = r.intents.GetPaymentIntent(txn.PaymentIntentID); err != nil {
+				slog.WarnContext(ctx, "stuck payment skipped, stripe unavailable", "transaction_id", txn.ID, "error", err)
+				result.Skipped++
+				continue
+			}
+		}
+
+		decision := Decide(txn, intent, r.maxAttempts)
+		err := r.apply(ctx, txn.ID, decision)
+		if errors.Is(err, payments.ErrTransactionLocked) || errors.Is(err, payments.ErrNotStuck) || errors.Is(err, payments.ErrShuttingDown) {
+			result.Skipped++ // Picked up by a processor since it was listed, or left for the next startup
+			continue
+		}
+
+		outcome := Outcome{TransactionID: txn.ID, Decision: decision}
+		if err != nil {
+			outcome.Error = err.Error()
+			slog.ErrorContext(ctx, "stuck payment recovery failed", "transaction_id", txn.ID, "action", decision.Action, "error", err)
+		} else {
+			slog.InfoContext(ctx, "stuck payment recovered", "transaction_id", txn.ID, "action", decision.Action, "reason", decision.Reason)
+		}
+		result.Outcomes = append(result.Outcomes, outcome)
+	}
+
+	r.mu.Lock()
+	r.last = result
+	r.mu.Unlock()
+	return result
+}
+
+func (r *Reconciler) apply(ctx context.Context, txnID string, d Decision) error {
+	switch d.Action {
+	case ActionResume:
+		return r.settler.ResumeStuck(ctx, txnID)
+	case ActionFail:
+		return r.settler.FailStuck(ctx, txnID, d.Reason, false)
+	case ActionRefund:
+		return r.settler.FailStuck(ctx, txnID, d.Reason, true)
+	default:
+		return r.settler.EscalateStuck(ctx, txnID, []string{"stuck payment: " + d.Reason})
+	}
+}
+
+// LastResult returns the most recent scan, or nil if none has run
+func (r *Reconciler) LastResult() *Result {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.last
+}
@@ -0,0 +1,121 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/plm/predictive-liquidity-mesh/payments"
+)
+
+func TestDecide(t *testing.T) {
+	now := time.Now()
+	unconfirmed := &payments.Transaction{ID: "t1", Status: payments.StatusPending}
+	card := &payments.Transaction{ID: "t2", Status: payments.StatusPending, PaymentIntentID: "pi_1"}
+	stalled := &payments.Transaction{ID: "t3", Status: payments.StatusProcessing, ProcessedAt: &now}
+	stalledCard := &payments.Transaction{ID: "t4", Status: payments.StatusProcessing, ProcessedAt: &now, PaymentIntentID: "pi_2",
+		Attempts: []payments.RouteAttempt{{Attempt: 1}, {Attempt: 2}}}
+	exhausted := &payments.Transaction{ID: "t5", Status: payments.StatusPending,
+		Attempts: []payments.RouteAttempt{{Attempt: 1}, {Attempt: 2}, {Attempt: 3}}}
+	intent := func(status string) *payments.PaymentIntentResponse {
+		return &payments.PaymentIntentResponse{Status: status}
+	}
+
+	for name, tc := range map[string]struct {
+		txn    *payments.Transaction
+		intent *payments.PaymentIntentResponse
+		want   Action
+	}{
+		"never confirmed":            {unconfirmed, nil, ActionFail},
+		"checkout abandoned":         {card, intent("requires_payment_method"), ActionFail},
+		"paid, webhook missed":       {card, intent("succeeded"), ActionResume},
+		"card still processing":      {card, intent("processing"), ActionEscalate},
+		"stalled mid-route":          {stalled, nil, ActionResume},
+		"paid, out of attempts":      {stalledCard, intent("succeeded"), ActionRefund},
+		"settling an unpaid intent":  {stalledCard, intent("canceled"), ActionEscalate},
+		"no card to refund":          {exhausted, nil, ActionEscalate},
+		"paid without intent lookup": {stalledCard, nil, ActionRefund},
+	} {
+		if got := Decide(tc.txn, tc.intent, 3); got.Action != tc.want {
+			t.Errorf("%s: got %s (%s), want %s", name, got.Action, got.Reason, tc.want)
+		}
+	}
+}
+
+// fakeStore lists a fixed set of stuck transactions
+type fakeStore struct {
+	stuck  []*payments.Transaction
+	before time.Time
+}
+
+func (s *fakeStore) StuckTransactions(before time.Time) []*payments.Transaction {
+	s.before = before
+	return s.stuck
+}
+
+// fakeSettler records the actions taken, refusing transactions in locked
+type fakeSettler struct {
+	locked  map[string]bool
+	actions map[string]string
+}
+
+func (s *fakeSettler) record(txnID, action string) error {
+	if s.locked[txnID] {
+		return payments.ErrTransactionLocked
+	}
+	s.actions[txnID] = action
+	return nil
+}
+
+func (s *fakeSettler) ResumeStuck(ctx context.Context, txnID string) error {
+	return s.record(txnID, "resume")
+}
+
+func (s *fakeSettler) FailStuck(ctx context.Context, txnID, reason string, refund bool) error {
+	if refund {
+		return s.record(txnID, "refund")
+	}
+	return s.record(txnID, "fail")
+}
+
+func (s *fakeSettler) EscalateStuck(ctx context.Context, txnID string, reasons []string) error {
+	return s.record(txnID, "escalate")
+}
+
+// fakeIntents reports a fixed status per PaymentIntent
+type fakeIntents map[string]string
+
+func (f fakeIntents) GetPaymentIntent(id string) (*payments.PaymentIntentResponse, error) {
+	status, ok := f[id]
+	if !ok {
+		return nil, errors.New("stripe unavailable")
+	}
+	return &payments.PaymentIntentResponse{ID: id, Status: status}, nil
+}
+
+func TestReconcileAppliesDecisions(t *testing.T) {
+	store := &fakeStore{stuck: []*payments.Transaction{
+		{ID: "paid", Status: payments.StatusPending, PaymentIntentID: "pi_paid"},
+		{ID: "abandoned", Status: payments.StatusPending, PaymentIntentID: "pi_abandoned"},
+		{ID: "busy", Status: payments.StatusPending, PaymentIntentID: "pi_paid"},
+		{ID: "no-stripe", Status: payments.StatusPending, PaymentIntentID: "pi_unknown"},
+	}}
+	settler := &fakeSettler{locked: map[string]bool{"busy": true}, actions: map[string]string{}}
+	r := NewReconciler(store, settler, &Config{StuckAfter: 10 * time.Minute, MaxAttempts: 3})
+	r.SetPaymentIntents(fakeIntents{"pi_paid": "succeeded", "pi_abandoned": "canceled"})
+
+	result := r.Reconcile(context.Background())
+	if time.Since(store.before) < 10*time.Minute {
+		t.Errorf("expected transactions idle for 10m listed, got cutoff %v", store.before)
+	}
+	if result.Stuck != 4 || result.Skipped != 2 || len(result.Outcomes) != 2 {
+		t.Fatalf("expected 2 recovered and 2 skipped of 4, got %+v", result)
+	}
+	if settler.actions["paid"] != "resume" || settler.actions["abandoned"] != "fail" {
+		t.Errorf("unexpected actions: %v", settler.actions)
+	}
+	if r.LastResult() != result {
+		t.Error("expected the scan recorded as the last result")
+	}
+}